@@ -21,6 +21,9 @@ type AggregateFunction interface {
 	ValueType() common.ColumnType
 	ArgExpression() *common.Expression
 	RequiresExtraState() bool
+	// RequiresIntermediateState returns true if the function keeps intermediate state in the AggState, which must be
+	// stored and forwarded along with its value
+	RequiresIntermediateState() bool
 }
 
 type aggregateFunctionBase struct {
@@ -74,6 +77,13 @@ const (
 	SumAggregateFunctionType AggFunctionType = iota
 	CountAggregateFunctionType
 	FirstRowAggregateFunctionType
+	MinAggregateFunctionType
+	MaxAggregateFunctionType
+	AvgAggregateFunctionType
+	VarPopAggregateFunctionType
+	VarSampAggregateFunctionType
+	StddevPopAggregateFunctionType
+	StddevSampAggregateFunctionType
 )
 
 func (b *aggregateFunctionBase) ValueType() common.ColumnType {
@@ -88,7 +98,16 @@ func (b *aggregateFunctionBase) RequiresExtraState() bool {
 	return false
 }
 
+func (b *aggregateFunctionBase) RequiresIntermediateState() bool {
+	return false
+}
+
 func NewAggregateFunction(argExpression *common.Expression, funcType AggFunctionType, valueType common.ColumnType) (AggregateFunction, error) {
+	if valueType.Type == common.TypeJSON {
+		// There is no aggregate state for JSON values. This also rules out grouping by JSON columns, as the values of
+		// the group by columns are the first row of the group
+		return nil, errors.NewInvalidStatementError("JSON values cannot be aggregated or grouped by")
+	}
 	base := aggregateFunctionBase{argExpression: argExpression, valueType: valueType}
 	switch funcType {
 	case SumAggregateFunctionType:
@@ -97,6 +116,20 @@ func NewAggregateFunction(argExpression *common.Expression, funcType AggFunction
 		return &CountAggregateFunction{aggregateFunctionBase: base}, nil
 	case FirstRowAggregateFunctionType:
 		return &FirstRowAggregateFunction{aggregateFunctionBase: base}, nil
+	case MinAggregateFunctionType:
+		return &MinAggregateFunction{extremeAggregateFunction{aggregateFunctionBase: base}}, nil
+	case MaxAggregateFunctionType:
+		return &MaxAggregateFunction{extremeAggregateFunction{aggregateFunctionBase: base, max: true}}, nil
+	case AvgAggregateFunctionType:
+		return &AvgAggregateFunction{aggregateFunctionBase: base}, nil
+	case VarPopAggregateFunctionType:
+		return &VarianceAggregateFunction{aggregateFunctionBase: base}, nil
+	case VarSampAggregateFunctionType:
+		return &VarianceAggregateFunction{aggregateFunctionBase: base, sample: true}, nil
+	case StddevPopAggregateFunctionType:
+		return &VarianceAggregateFunction{aggregateFunctionBase: base, stddev: true}, nil
+	case StddevSampAggregateFunctionType:
+		return &VarianceAggregateFunction{aggregateFunctionBase: base, sample: true, stddev: true}, nil
 	default:
 		return nil, errors.Errorf("unexpected aggregate function type %d", funcType)
 	}
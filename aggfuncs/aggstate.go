@@ -16,6 +16,9 @@ type AggState struct {
 	changed      bool
 	size         int
 	extraState   [][]byte
+	recalc       []bool
+	// intermediate state from which the value of the function is calculated, e.g. the count and sum for AVG
+	intermediateState [][]byte
 }
 
 func NewAggState(size int) *AggState {
@@ -40,6 +43,7 @@ func (as *AggState) IsNull(index int) bool {
 func (as *AggState) SetInt64(index int, val int64) {
 	as.set[index] = true
 	as.changed = true
+	as.null[index] = false
 	ptrInt64 := (*int64)(unsafe.Pointer(&as.state[index])) // nolint: gosec
 	*ptrInt64 = val
 }
@@ -52,6 +56,7 @@ func (as *AggState) GetInt64(index int) int64 {
 func (as *AggState) SetFloat64(index int, val float64) {
 	as.set[index] = true
 	as.changed = true
+	as.null[index] = false
 	ptrFloat64 := (*float64)(unsafe.Pointer(&as.state[index])) // nolint: gosec
 	*ptrFloat64 = val
 }
@@ -64,6 +69,7 @@ func (as *AggState) GetFloat64(index int) float64 {
 func (as *AggState) SetString(index int, val string) {
 	as.set[index] = true
 	as.changed = true
+	as.null[index] = false
 	as.checkCreateStrState()
 	as.strState[index] = val
 }
@@ -101,16 +107,33 @@ func (as *AggState) checkCreateExtraState() {
 	}
 }
 
+func (as *AggState) GetIntermediateState(index int) []byte {
+	if as.intermediateState == nil {
+		return nil
+	}
+	return as.intermediateState[index]
+}
+
+func (as *AggState) SetIntermediateState(index int, state []byte) {
+	as.changed = true
+	if as.intermediateState == nil {
+		as.intermediateState = make([][]byte, as.size)
+	}
+	as.intermediateState[index] = state
+}
+
 func (as *AggState) SetDecimal(index int, val common.Decimal) error {
 	as.set[index] = true
 	as.changed = true
+	as.null[index] = false
 	as.checkCreateDecimalState()
 	as.decimalState[index] = val
 	return nil
 }
 
 func (as *AggState) GetDecimal(index int) common.Decimal {
-	if as.decimalState == nil {
+	// The decimal state is shared by all the functions, so it might have been created without a value for this one
+	if as.decimalState == nil || !as.set[index] || as.null[index] {
 		return *common.ZeroDecimal()
 	}
 	return as.decimalState[index]
@@ -142,6 +165,23 @@ func (as *AggState) GetTimestamp(index int) (common.Timestamp, error) {
 	return ts, nil
 }
 
+// SetRecalc marks the value at the index as needing to be recalculated from the extra state, as the value can't be
+// calculated from the previous value alone
+func (as *AggState) SetRecalc(index int) {
+	as.changed = true
+	if as.recalc == nil {
+		as.recalc = make([]bool, as.size)
+	}
+	as.recalc[index] = true
+}
+
+func (as *AggState) NeedsRecalc(index int) bool {
+	if as.recalc == nil {
+		return false
+	}
+	return as.recalc[index]
+}
+
 func (as *AggState) IsSet(index int) bool {
 	return as.set[index]
 }
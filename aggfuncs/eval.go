@@ -0,0 +1,222 @@
+package aggfuncs
+
+import (
+	"github.com/squareup/pranadb/common"
+	"github.com/squareup/pranadb/errors"
+)
+
+// AggregateFunctionInfo describes an aggregate function of an aggregation
+type AggregateFunctionInfo struct {
+	FuncType   AggFunctionType
+	Distinct   bool
+	ArgExpr    *common.Expression
+	ArgType    common.ColumnType
+	ReturnType common.ColumnType
+	Hidden     bool // Hidden functions output the values of group by expressions which aren't selected
+}
+
+// AggFunctionTypeForName returns the type of the aggregate function with the name used by the planner
+func AggFunctionTypeForName(name string) (AggFunctionType, error) {
+	switch name {
+	case "sum":
+		return SumAggregateFunctionType, nil
+	case "count":
+		return CountAggregateFunctionType, nil
+	case "firstrow":
+		return FirstRowAggregateFunctionType, nil
+	case "min":
+		return MinAggregateFunctionType, nil
+	case "max":
+		return MaxAggregateFunctionType, nil
+	case "avg":
+		return AvgAggregateFunctionType, nil
+	case "var_pop":
+		return VarPopAggregateFunctionType, nil
+	case "var_samp":
+		return VarSampAggregateFunctionType, nil
+	case "stddev_pop":
+		return StddevPopAggregateFunctionType, nil
+	case "stddev_samp":
+		return StddevSampAggregateFunctionType, nil
+	default:
+		return 0, errors.Errorf("unexpected aggregate function %s", name)
+	}
+}
+
+// EvalArgExpr evaluates the argument of an aggregate function as its own type, which can differ from the type of the
+// function
+func EvalArgExpr(argExpr *common.Expression, argType common.ColumnType, row *common.Row) (interface{}, bool, error) {
+	switch argType.Type {
+	case common.TypeTinyInt, common.TypeInt, common.TypeBigInt, common.TypeBoolean:
+		return argExpr.EvalInt64(row)
+	case common.TypeDouble:
+		return argExpr.EvalFloat64(row)
+	case common.TypeVarchar, common.TypeVarbinary:
+		return argExpr.EvalString(row)
+	case common.TypeDecimal:
+		return argExpr.EvalDecimal(row)
+	case common.TypeTimestamp, common.TypeDate:
+		return argExpr.EvalTimestamp(row)
+	case common.TypeJSON:
+		return argExpr.EvalJSON(row)
+	default:
+		return nil, false, errors.Errorf("unexpected column type %d", argType.Type)
+	}
+}
+
+// EvalAggregateFunction evaluates the aggregate function on the row, and returns the value of its argument, or nil if
+// it is null
+func EvalAggregateFunction(aggFunc AggregateFunction, argType common.ColumnType, aggState *AggState, index int,
+	row *common.Row, reverse bool) (interface{}, error) {
+	if _, ok := aggFunc.(*CountAggregateFunction); ok && aggFunc.ArgExpression() != nil {
+		// The argument of COUNT can be of any type, all that matters is whether it is null
+		_, null, err := EvalArgExpr(aggFunc.ArgExpression(), argType, row)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		return nil, aggFunc.EvalInt64(0, null, aggState, index, reverse)
+	}
+	var value interface{}
+	switch aggFunc.ValueType().Type {
+	case common.TypeTinyInt, common.TypeInt, common.TypeBigInt, common.TypeBoolean:
+		arg, null, err := aggFunc.ArgExpression().EvalInt64(row)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		err = aggFunc.EvalInt64(arg, null, aggState, index, reverse)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		if !null {
+			value = arg
+		}
+	case common.TypeDecimal:
+		arg, null, err := aggFunc.ArgExpression().EvalDecimal(row)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		err = aggFunc.EvalDecimal(arg, null, aggState, index, reverse)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		if !null {
+			value = arg
+		}
+	case common.TypeDouble:
+		arg, null, err := aggFunc.ArgExpression().EvalFloat64(row)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		err = aggFunc.EvalFloat64(arg, null, aggState, index, reverse)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		if !null {
+			value = arg
+		}
+	case common.TypeVarchar, common.TypeVarbinary:
+		arg, null, err := aggFunc.ArgExpression().EvalString(row)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		err = aggFunc.EvalString(arg, null, aggState, index, reverse)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		if !null {
+			value = arg
+		}
+	case common.TypeTimestamp, common.TypeDate:
+		arg, null, err := aggFunc.ArgExpression().EvalTimestamp(row)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		err = aggFunc.EvalTimestamp(arg, null, aggState, index, reverse)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		if !null {
+			value = arg
+		}
+	default:
+		return nil, errors.Errorf("unexpected column type %d", aggFunc.ValueType())
+	}
+	return value, nil
+}
+
+// MergeAggregateFunction merges the value of the aggregate function in toMerge into the aggregate state
+func MergeAggregateFunction(aggFunc AggregateFunction, toMerge *AggState, aggState *AggState, index int, reverse bool) error {
+	switch aggFunc.ValueType().Type {
+	case common.TypeTinyInt, common.TypeInt, common.TypeBigInt, common.TypeBoolean:
+		return aggFunc.MergeInt64(toMerge, aggState, index, reverse)
+	case common.TypeDecimal:
+		return aggFunc.MergeDecimal(toMerge, aggState, index, reverse)
+	case common.TypeDouble:
+		return aggFunc.MergeFloat64(toMerge, aggState, index, reverse)
+	case common.TypeVarchar, common.TypeVarbinary:
+		return aggFunc.MergeString(toMerge, aggState, index, reverse)
+	case common.TypeTimestamp, common.TypeDate:
+		return aggFunc.MergeTimestamp(toMerge, aggState, index, reverse)
+	default:
+		return errors.Errorf("unexpected column type %d", aggFunc.ValueType())
+	}
+}
+
+// InitAggStateWithRow sets the values of the aggregate functions in the state to the first len(colTypes) columns of
+// the row
+func InitAggStateWithRow(row *common.Row, colTypes []common.ColumnType, aggState *AggState) error {
+	for i, colType := range colTypes {
+		if row.IsNull(i) {
+			aggState.SetNull(i)
+			continue
+		}
+		switch colType.Type {
+		case common.TypeTinyInt, common.TypeInt, common.TypeBigInt, common.TypeBoolean:
+			aggState.SetInt64(i, row.GetInt64(i))
+		case common.TypeDecimal:
+			if err := aggState.SetDecimal(i, row.GetDecimal(i)); err != nil {
+				return errors.WithStack(err)
+			}
+		case common.TypeDouble:
+			aggState.SetFloat64(i, row.GetFloat64(i))
+		case common.TypeVarchar, common.TypeVarbinary:
+			aggState.SetString(i, row.GetString(i))
+		case common.TypeTimestamp, common.TypeDate:
+			if err := aggState.SetTimestamp(i, row.GetTimestamp(i)); err != nil {
+				return errors.WithStack(err)
+			}
+		default:
+			return errors.Errorf("unexpected column type %d", colType)
+		}
+	}
+	return nil
+}
+
+// AppendAggState appends the values of the aggregate functions in the state to the rows
+func AppendAggState(aggState *AggState, colTypes []common.ColumnType, rows *common.Rows) error {
+	for i, colType := range colTypes {
+		if aggState.IsNull(i) {
+			rows.AppendNullToColumn(i)
+			continue
+		}
+		switch colType.Type {
+		case common.TypeTinyInt, common.TypeInt, common.TypeBigInt, common.TypeBoolean:
+			rows.AppendInt64ToColumn(i, aggState.GetInt64(i))
+		case common.TypeDecimal:
+			rows.AppendDecimalToColumn(i, aggState.GetDecimal(i))
+		case common.TypeDouble:
+			rows.AppendFloat64ToColumn(i, aggState.GetFloat64(i))
+		case common.TypeVarchar, common.TypeVarbinary:
+			rows.AppendStringToColumn(i, aggState.GetString(i))
+		case common.TypeTimestamp, common.TypeDate:
+			ts, err := aggState.GetTimestamp(i)
+			if err != nil {
+				return errors.WithStack(err)
+			}
+			rows.AppendTimestampToColumn(i, ts)
+		default:
+			return errors.Errorf("unexpected column type %d", colType)
+		}
+	}
+	return nil
+}
@@ -1,6 +1,9 @@
 package aggfuncs
 
 import (
+	"math"
+	"strings"
+
 	"github.com/squareup/pranadb/common"
 	"github.com/squareup/pranadb/errors"
 )
@@ -223,3 +226,425 @@ func (f *FirstRowAggregateFunction) MergeTimestamp(latestState *AggState, aggSta
 	}
 	return nil
 }
+
+// MIN and MAX
+// ===========
+
+type MinAggregateFunction struct {
+	extremeAggregateFunction
+}
+
+type MaxAggregateFunction struct {
+	extremeAggregateFunction
+}
+
+// extremeAggregateFunction keeps the min or max value. When the extreme value is removed the next one can't be known from
+// the state alone, so the state is marked to be recalculated from the extra state, which holds all the values.
+type extremeAggregateFunction struct {
+	aggregateFunctionBase
+	max bool
+}
+
+func (e *extremeAggregateFunction) RequiresExtraState() bool {
+	return true
+}
+
+// update updates the state given the comparison of the value with the current extreme value
+func (e *extremeAggregateFunction) update(aggState *AggState, index int, reverse bool, cmp func() int, set func() error) error {
+	if !aggState.IsSet(index) || aggState.IsNull(index) {
+		if reverse {
+			return nil
+		}
+		return set()
+	}
+	c := cmp()
+	if reverse {
+		if c == 0 {
+			aggState.SetRecalc(index)
+		}
+		return nil
+	}
+	if (e.max && c > 0) || (!e.max && c < 0) {
+		return set()
+	}
+	return nil
+}
+
+// evalNull handles a null value, which is ignored unless there are no other values
+func (e *extremeAggregateFunction) evalNull(aggState *AggState, index int) error {
+	if !aggState.IsSet(index) {
+		aggState.SetNull(index)
+	}
+	return nil
+}
+
+func (e *extremeAggregateFunction) EvalInt64(value int64, null bool, aggState *AggState, index int, reverse bool) error {
+	if null {
+		return e.evalNull(aggState, index)
+	}
+	return e.update(aggState, index, reverse, func() int {
+		curr := aggState.GetInt64(index)
+		if value < curr {
+			return -1
+		} else if value > curr {
+			return 1
+		}
+		return 0
+	}, func() error {
+		aggState.SetInt64(index, value)
+		return nil
+	})
+}
+
+func (e *extremeAggregateFunction) EvalFloat64(value float64, null bool, aggState *AggState, index int, reverse bool) error {
+	if null {
+		return e.evalNull(aggState, index)
+	}
+	return e.update(aggState, index, reverse, func() int {
+		curr := aggState.GetFloat64(index)
+		if value < curr {
+			return -1
+		} else if value > curr {
+			return 1
+		}
+		return 0
+	}, func() error {
+		aggState.SetFloat64(index, value)
+		return nil
+	})
+}
+
+func (e *extremeAggregateFunction) EvalString(value string, null bool, aggState *AggState, index int, reverse bool) error {
+	if null {
+		return e.evalNull(aggState, index)
+	}
+	return e.update(aggState, index, reverse, func() int {
+		return strings.Compare(value, aggState.GetString(index))
+	}, func() error {
+		aggState.SetString(index, value)
+		return nil
+	})
+}
+
+func (e *extremeAggregateFunction) EvalTimestamp(value common.Timestamp, null bool, aggState *AggState, index int, reverse bool) error {
+	if null {
+		return e.evalNull(aggState, index)
+	}
+	var curr common.Timestamp
+	if aggState.IsSet(index) && !aggState.IsNull(index) {
+		var err error
+		if curr, err = aggState.GetTimestamp(index); err != nil {
+			return err
+		}
+	}
+	return e.update(aggState, index, reverse, func() int {
+		return value.Compare(curr)
+	}, func() error {
+		return aggState.SetTimestamp(index, value)
+	})
+}
+
+func (e *extremeAggregateFunction) EvalDecimal(value common.Decimal, null bool, aggState *AggState, index int, reverse bool) error {
+	if null {
+		return e.evalNull(aggState, index)
+	}
+	return e.update(aggState, index, reverse, func() int {
+		curr := aggState.GetDecimal(index)
+		return value.CompareTo(&curr)
+	}, func() error {
+		return aggState.SetDecimal(index, value)
+	})
+}
+
+func (e *extremeAggregateFunction) MergeInt64(latestState *AggState, aggState *AggState, index int, reverse bool) error {
+	return e.EvalInt64(latestState.GetInt64(index), latestState.IsNull(index), aggState, index, reverse)
+}
+
+func (e *extremeAggregateFunction) MergeFloat64(latestState *AggState, aggState *AggState, index int, reverse bool) error {
+	return e.EvalFloat64(latestState.GetFloat64(index), latestState.IsNull(index), aggState, index, reverse)
+}
+
+func (e *extremeAggregateFunction) MergeString(latestState *AggState, aggState *AggState, index int, reverse bool) error {
+	return e.EvalString(latestState.GetString(index), latestState.IsNull(index), aggState, index, reverse)
+}
+
+func (e *extremeAggregateFunction) MergeTimestamp(latestState *AggState, aggState *AggState, index int, reverse bool) error {
+	if latestState.IsNull(index) {
+		return e.evalNull(aggState, index)
+	}
+	ts, err := latestState.GetTimestamp(index)
+	if err != nil {
+		return err
+	}
+	return e.EvalTimestamp(ts, false, aggState, index, reverse)
+}
+
+func (e *extremeAggregateFunction) MergeDecimal(latestState *AggState, aggState *AggState, index int, reverse bool) error {
+	return e.EvalDecimal(latestState.GetDecimal(index), latestState.IsNull(index), aggState, index, reverse)
+}
+
+// AVG
+// ===
+
+// AvgAggregateFunction keeps the count and sum of the values as intermediate state, so averages can be merged and
+// retracted. Averages of DECIMAL values are calculated exactly then rounded to the scale of the result.
+type AvgAggregateFunction struct {
+	aggregateFunctionBase
+}
+
+func (a *AvgAggregateFunction) RequiresIntermediateState() bool {
+	return true
+}
+
+func (a *AvgAggregateFunction) EvalFloat64(value float64, null bool, aggState *AggState, index int, reverse bool) error {
+	sums := decodeFloatSums(aggState.GetIntermediateState(index))
+	if !null {
+		sums.add(&floatSums{count: 1, sum: value}, reverse)
+	}
+	return a.setFloat64(sums, aggState, index)
+}
+
+func (a *AvgAggregateFunction) EvalDecimal(value common.Decimal, null bool, aggState *AggState, index int, reverse bool) error {
+	sums, err := decodeDecimalSums(aggState.GetIntermediateState(index))
+	if err != nil {
+		return err
+	}
+	if !null {
+		if err := sums.add(&decimalSums{count: 1, sum: value}, reverse); err != nil {
+			return err
+		}
+	}
+	return a.setDecimal(sums, aggState, index)
+}
+
+func (a *AvgAggregateFunction) MergeFloat64(latestState *AggState, aggState *AggState, index int, reverse bool) error {
+	sums := decodeFloatSums(aggState.GetIntermediateState(index))
+	sums.add(decodeFloatSums(latestState.GetIntermediateState(index)), reverse)
+	return a.setFloat64(sums, aggState, index)
+}
+
+func (a *AvgAggregateFunction) MergeDecimal(latestState *AggState, aggState *AggState, index int, reverse bool) error {
+	sums, err := decodeDecimalSums(aggState.GetIntermediateState(index))
+	if err != nil {
+		return err
+	}
+	latestSums, err := decodeDecimalSums(latestState.GetIntermediateState(index))
+	if err != nil {
+		return err
+	}
+	if err := sums.add(latestSums, reverse); err != nil {
+		return err
+	}
+	return a.setDecimal(sums, aggState, index)
+}
+
+func (a *AvgAggregateFunction) setFloat64(sums *floatSums, aggState *AggState, index int) error {
+	aggState.SetIntermediateState(index, sums.encode())
+	if sums.count == 0 {
+		aggState.SetNull(index)
+	} else {
+		aggState.SetFloat64(index, sums.sum/float64(sums.count))
+	}
+	return nil
+}
+
+func (a *AvgAggregateFunction) setDecimal(sums *decimalSums, aggState *AggState, index int) error {
+	aggState.SetIntermediateState(index, sums.encode())
+	if sums.count == 0 {
+		aggState.SetNull(index)
+		return nil
+	}
+	avg, err := sums.sum.Divide(common.NewDecFromInt64(sums.count), a.valueType.DecScale)
+	if err != nil {
+		return err
+	}
+	avg, err = avg.Round(a.valueType.DecScale)
+	if err != nil {
+		return err
+	}
+	return aggState.SetDecimal(index, *avg)
+}
+
+// VARIANCE and STDDEV
+// ===================
+
+// VarianceAggregateFunction calculates the population or sample variance, or standard deviation, of the values. It
+// keeps the count and mean of the values, and the sum of squared differences from the mean, as intermediate state, so
+// it can be merged and retracted. These are updated with Welford's method, which unlike the difference between the sum
+// of squares and the square of the sum, keeps its precision when the values are large compared to their spread.
+type VarianceAggregateFunction struct {
+	aggregateFunctionBase
+	sample bool
+	stddev bool
+}
+
+func (v *VarianceAggregateFunction) RequiresIntermediateState() bool {
+	return true
+}
+
+func (v *VarianceAggregateFunction) EvalFloat64(value float64, null bool, aggState *AggState, index int, reverse bool) error {
+	state := decodeMoments(aggState.GetIntermediateState(index))
+	if !null {
+		state.add(&moments{count: 1, mean: value}, reverse)
+	}
+	return v.set(state, aggState, index)
+}
+
+func (v *VarianceAggregateFunction) MergeFloat64(latestState *AggState, aggState *AggState, index int, reverse bool) error {
+	state := decodeMoments(aggState.GetIntermediateState(index))
+	state.add(decodeMoments(latestState.GetIntermediateState(index)), reverse)
+	return v.set(state, aggState, index)
+}
+
+func (v *VarianceAggregateFunction) set(state *moments, aggState *AggState, index int) error {
+	aggState.SetIntermediateState(index, state.encode())
+	n := state.count
+	if v.sample {
+		n--
+	}
+	if n <= 0 {
+		aggState.SetNull(index)
+		return nil
+	}
+	variance := state.m2 / float64(n)
+	if v.stddev {
+		aggState.SetFloat64(index, math.Sqrt(variance))
+	} else {
+		aggState.SetFloat64(index, variance)
+	}
+	return nil
+}
+
+// floatSums is the intermediate state of AVG of DOUBLE values
+type floatSums struct {
+	count int64
+	sum   float64
+}
+
+func decodeFloatSums(buff []byte) *floatSums {
+	sums := &floatSums{}
+	if len(buff) == 0 {
+		return sums
+	}
+	count, offset := common.ReadUint64FromBufferLE(buff, 0)
+	sums.count = int64(count)
+	sums.sum, _ = common.ReadFloat64FromBufferLE(buff, offset)
+	return sums
+}
+
+func (s *floatSums) encode() []byte {
+	buff := make([]byte, 0, 16)
+	buff = common.AppendUint64ToBufferLE(buff, uint64(s.count))
+	return common.AppendFloat64ToBufferLE(buff, s.sum)
+}
+
+func (s *floatSums) add(other *floatSums, reverse bool) {
+	if reverse {
+		s.count -= other.count
+		s.sum -= other.sum
+	} else {
+		s.count += other.count
+		s.sum += other.sum
+	}
+}
+
+// moments is the intermediate state of VARIANCE and STDDEV - the count and mean of the values, and m2, the sum of the
+// squared differences of the values from the mean
+type moments struct {
+	count int64
+	mean  float64
+	m2    float64
+}
+
+func decodeMoments(buff []byte) *moments {
+	m := &moments{}
+	if len(buff) == 0 {
+		return m
+	}
+	count, offset := common.ReadUint64FromBufferLE(buff, 0)
+	m.count = int64(count)
+	m.mean, offset = common.ReadFloat64FromBufferLE(buff, offset)
+	m.m2, _ = common.ReadFloat64FromBufferLE(buff, offset)
+	return m
+}
+
+func (m *moments) encode() []byte {
+	buff := make([]byte, 0, 24)
+	buff = common.AppendUint64ToBufferLE(buff, uint64(m.count))
+	buff = common.AppendFloat64ToBufferLE(buff, m.mean)
+	return common.AppendFloat64ToBufferLE(buff, m.m2)
+}
+
+// add combines the moments of another set of values with these, using the parallel algorithm of Chan et al. With
+// reverse the other values are removed, by solving the same equations for the moments of the remaining values.
+func (m *moments) add(other *moments, reverse bool) {
+	if other.count == 0 {
+		return
+	}
+	if !reverse {
+		count := m.count + other.count
+		delta := other.mean - m.mean
+		m.mean += delta * float64(other.count) / float64(count)
+		m.m2 += other.m2 + delta*delta*float64(m.count)*float64(other.count)/float64(count)
+		m.count = count
+		return
+	}
+	count := m.count - other.count
+	if count <= 0 {
+		*m = moments{}
+		return
+	}
+	mean := m.mean - (other.mean-m.mean)*float64(other.count)/float64(count)
+	delta := other.mean - mean
+	m.m2 -= other.m2 + delta*delta*float64(count)*float64(other.count)/float64(m.count)
+	if m.m2 < 0 {
+		// Rounding errors when values are retracted can leave a tiny negative m2
+		m.m2 = 0
+	}
+	m.mean = mean
+	m.count = count
+}
+
+// decimalSums is the intermediate state of AVG of DECIMAL values
+type decimalSums struct {
+	count int64
+	sum   common.Decimal
+}
+
+func decodeDecimalSums(buff []byte) (*decimalSums, error) {
+	sums := &decimalSums{sum: *common.ZeroDecimal()}
+	if len(buff) == 0 {
+		return sums, nil
+	}
+	count, offset := common.ReadUint64FromBufferLE(buff, 0)
+	sums.count = int64(count)
+	str, _ := common.ReadStringFromBufferLE(buff, offset)
+	sum, err := common.NewDecFromString(str)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	sums.sum = *sum
+	return sums, nil
+}
+
+func (s *decimalSums) encode() []byte {
+	buff := common.AppendUint64ToBufferLE(nil, uint64(s.count))
+	return common.AppendStringToBufferLE(buff, s.sum.String())
+}
+
+func (s *decimalSums) add(other *decimalSums, reverse bool) error {
+	var sum *common.Decimal
+	var err error
+	if reverse {
+		s.count -= other.count
+		sum, err = s.sum.Subtract(&other.sum)
+	} else {
+		s.count += other.count
+		sum, err = s.sum.Add(&other.sum)
+	}
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	s.sum = *sum
+	return nil
+}
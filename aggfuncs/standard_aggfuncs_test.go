@@ -0,0 +1,84 @@
+package aggfuncs
+
+import (
+	"testing"
+
+	"github.com/squareup/pranadb/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVarianceOfValuesWithLargeOffset(t *testing.T) {
+	// The sum of squares of these is around 3e18, so its difference from the square of the sum loses all the precision
+	values := []float64{1e9 + 1, 1e9 + 2, 1e9 + 3}
+	varPop := newVarianceFunction(t, VarPopAggregateFunctionType)
+	varSamp := newVarianceFunction(t, VarSampAggregateFunctionType)
+	aggState := NewAggState(2)
+	for _, value := range values {
+		require.NoError(t, varPop.EvalFloat64(value, false, aggState, 0, false))
+		require.NoError(t, varSamp.EvalFloat64(value, false, aggState, 1, false))
+	}
+	require.InDelta(t, 2.0/3, aggState.GetFloat64(0), 1e-9)
+	require.InDelta(t, 1.0, aggState.GetFloat64(1), 1e-9)
+
+	// Retracting a value
+	require.NoError(t, varPop.EvalFloat64(1e9+3, false, aggState, 0, true))
+	require.InDelta(t, 0.25, aggState.GetFloat64(0), 1e-9)
+	require.NoError(t, varPop.EvalFloat64(1e9+1, false, aggState, 0, true))
+	require.InDelta(t, 0.0, aggState.GetFloat64(0), 1e-9)
+	require.NoError(t, varPop.EvalFloat64(1e9+2, false, aggState, 0, true))
+	require.True(t, aggState.IsNull(0))
+}
+
+func TestVarianceMergeOfValuesWithLargeOffset(t *testing.T) {
+	varPop := newVarianceFunction(t, VarPopAggregateFunctionType)
+	// Partial aggregations of two shards, merged into the final one
+	partial1 := NewAggState(1)
+	for _, value := range []float64{1e9 + 1, 1e9 + 2} {
+		require.NoError(t, varPop.EvalFloat64(value, false, partial1, 0, false))
+	}
+	partial2 := NewAggState(1)
+	for _, value := range []float64{1e9 + 3, 1e9 + 4, 1e9 + 5} {
+		require.NoError(t, varPop.EvalFloat64(value, false, partial2, 0, false))
+	}
+	final := NewAggState(1)
+	require.NoError(t, varPop.MergeFloat64(partial1, final, 0, false))
+	require.NoError(t, varPop.MergeFloat64(partial2, final, 0, false))
+	require.InDelta(t, 2.0, final.GetFloat64(0), 1e-9)
+
+	// Retracting the state of a shard leaves the variance of the other
+	require.NoError(t, varPop.MergeFloat64(partial1, final, 0, true))
+	require.InDelta(t, 2.0/3, final.GetFloat64(0), 1e-9)
+	require.NoError(t, varPop.MergeFloat64(partial2, final, 0, true))
+	require.True(t, final.IsNull(0))
+}
+
+func TestExtremeOfOnlyNullValuesIsNull(t *testing.T) {
+	minFunc, err := NewAggregateFunction(common.NewColumnExpression(0, common.BigIntColumnType), MinAggregateFunctionType, common.BigIntColumnType)
+	require.NoError(t, err)
+	maxFunc, err := NewAggregateFunction(common.NewColumnExpression(0, common.BigIntColumnType), MaxAggregateFunctionType, common.BigIntColumnType)
+	require.NoError(t, err)
+	aggState := NewAggState(2)
+	require.NoError(t, minFunc.EvalInt64(0, true, aggState, 0, false))
+	require.NoError(t, maxFunc.EvalInt64(0, true, aggState, 1, false))
+	require.True(t, aggState.IsSet(0))
+	require.True(t, aggState.IsNull(0))
+	require.True(t, aggState.IsSet(1))
+	require.True(t, aggState.IsNull(1))
+
+	// A value replaces the null, and later nulls are ignored
+	require.NoError(t, minFunc.EvalInt64(10, false, aggState, 0, false))
+	require.NoError(t, maxFunc.EvalInt64(10, false, aggState, 1, false))
+	require.NoError(t, minFunc.EvalInt64(0, true, aggState, 0, false))
+	require.NoError(t, maxFunc.EvalInt64(0, true, aggState, 1, false))
+	require.False(t, aggState.IsNull(0))
+	require.Equal(t, int64(10), aggState.GetInt64(0))
+	require.False(t, aggState.IsNull(1))
+	require.Equal(t, int64(10), aggState.GetInt64(1))
+}
+
+func newVarianceFunction(t *testing.T, funcType AggFunctionType) AggregateFunction {
+	t.Helper()
+	aggFunc, err := NewAggregateFunction(common.NewColumnExpression(0, common.DoubleColumnType), funcType, common.DoubleColumnType)
+	require.NoError(t, err)
+	return aggFunc
+}
@@ -12,12 +12,15 @@ import (
 	"github.com/squareup/pranadb/meta"
 
 	log "github.com/sirupsen/logrus"
+	"github.com/squareup/pranadb/avrolib"
 	"github.com/squareup/pranadb/command"
 	"github.com/squareup/pranadb/common"
 	"github.com/squareup/pranadb/conf"
 	"github.com/squareup/pranadb/errors"
 	"github.com/squareup/pranadb/protolib"
 	"github.com/squareup/pranadb/protos/squareup/cash/pranadb/v1/service"
+	"github.com/squareup/pranadb/pull/exec"
+	"github.com/squareup/pranadb/push"
 	"google.golang.org/grpc"
 	_ "google.golang.org/grpc/encoding/gzip" // Registers gzip (de)-compressor
 	"google.golang.org/grpc/reflection"
@@ -34,14 +37,19 @@ type Server struct {
 	gsrv           *grpc.Server
 	errorSequence  int64
 	protoRegistry  *protolib.ProtoRegistry
+	avroRegistry   *avrolib.AvroRegistry
 	metaController *meta.Controller
+	pushEngine     *push.Engine
 }
 
-func NewAPIServer(metaController *meta.Controller, ce *command.Executor, protobufs *protolib.ProtoRegistry, cfg conf.Config) *Server {
+func NewAPIServer(metaController *meta.Controller, ce *command.Executor, pushEngine *push.Engine,
+	protobufs *protolib.ProtoRegistry, avroSchemas *avrolib.AvroRegistry, cfg conf.Config) *Server {
 	return &Server{
 		metaController: metaController,
 		ce:             ce,
+		pushEngine:     pushEngine,
 		protoRegistry:  protobufs,
+		avroRegistry:   avroSchemas,
 		serverAddress:  cfg.APIServerListenAddresses[cfg.NodeID],
 	}
 }
@@ -56,7 +64,7 @@ func (s *Server) Start() error {
 	if err != nil {
 		return errors.WithStack(err)
 	}
-	s.gsrv = grpc.NewServer()
+	s.gsrv = grpc.NewServer(grpc.StatsHandler(&sessionHandler{}))
 	reflection.Register(s.gsrv)
 	service.RegisterPranaDBServiceServer(s.gsrv, s)
 	s.started = true
@@ -96,89 +104,112 @@ func (s *Server) ExecuteSQLStatement(in *service.ExecuteSQLStatementRequest,
 	defer func() {
 		s.metaController.DeleteSchemaIfEmpty(schema)
 	}()
+	// Closing the query releases what it holds even if the client goes away before all the rows have been sent
+	defer execCtx.Close()
 
 	executor, err := s.ce.ExecuteSQLStatement(execCtx, in.Statement)
 	if err != nil {
 		log.Errorf("failed to execute statement %+v", err)
-		var perr errors.PranaError
-		if errors.As(err, &perr) {
-			return perr
-		}
-		// For internal errors we don't return internal error messages to the CLI as this would leak
-		// server implementation details. Instead, we generate a sequence number and add that to the message
-		// and log the internal error in the server logs with the sequence number so it can be looked up
-		seq := atomic.AddInt64(&s.errorSequence, 1)
-		perr = errors.NewInternalError(seq)
-		log.Errorf("internal error occurred with sequence number %d\n%v", seq, err)
-		return perr
+		return s.toClientError(err)
 	}
+	return sendResults(executor, int(in.PageSize), stream)
+}
 
-	// First send column definitions.
-	columns := &service.Columns{}
-	names := executor.ColNames()
-	for i, typ := range executor.ColTypes() {
-		name := names[i]
-		column := &service.Column{
-			Name: name,
-			Type: service.ColumnType(typ.Type),
-		}
-		if typ.Type == common.TypeDecimal {
-			column.DecimalParams = &service.DecimalParams{
-				DecimalPrecision: uint32(typ.DecPrecision),
-				DecimalScale:     uint32(typ.DecScale),
-			}
-		}
-		columns.Columns = append(columns.Columns, column)
+// PrepareStatement prepares a query so it can be executed many times with different args. The prepared statement can
+// only be executed on the connection it was prepared on.
+func (s *Server) PrepareStatement(ctx context.Context, in *service.PrepareStatementRequest) (*service.PrepareStatementResponse, error) {
+	defer common.PanicHandler()
+	sess, err := getSession(ctx)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if in.Schema == "" {
+		return nil, errors.NewSchemaNotInUseError()
+	}
+	schema := s.metaController.GetOrCreateSchema(in.Schema)
+	defer func() {
+		s.metaController.DeleteSchemaIfEmpty(schema)
+	}()
+	pq, err := s.ce.PrepareSQLStatement(schema, in.Statement)
+	if err != nil {
+		log.Errorf("failed to prepare statement %+v", err)
+		return nil, s.toClientError(err)
 	}
+	psID, err := sess.addPreparedStatement(pq)
+	if err != nil {
+		return nil, s.toClientError(err)
+	}
+	return &service.PrepareStatementResponse{PreparedStatementId: psID, NumParams: uint32(pq.NumParams())}, nil
+}
+
+func (s *Server) ExecutePreparedStatement(in *service.ExecutePreparedStatementRequest,
+	stream service.PranaDBService_ExecutePreparedStatementServer) error {
+	defer common.PanicHandler()
+	sess, err := getSession(stream.Context())
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	ps, err := sess.getPreparedStatement(in.PreparedStatementId)
+	if err != nil {
+		return s.toClientError(err)
+	}
+	args, err := toArgs(in.Args)
+	if err != nil {
+		return s.toClientError(err)
+	}
+	ps.lock.Lock()
+	defer ps.lock.Unlock()
+	defer func() {
+		s.metaController.DeleteSchemaIfEmpty(ps.query.Schema())
+	}()
+	defer ps.query.Close()
+	executor, err := s.ce.ExecutePreparedStatement(ps.query, args)
+	if err != nil {
+		log.Errorf("failed to execute prepared statement %+v", err)
+		return s.toClientError(err)
+	}
+	return sendResults(executor, int(in.PageSize), stream)
+}
+
+// ClosePreparedStatement discards a prepared statement, so it can no longer be executed
+func (s *Server) ClosePreparedStatement(ctx context.Context, in *service.ClosePreparedStatementRequest) (*emptypb.Empty, error) {
+	defer common.PanicHandler()
+	sess, err := getSession(ctx)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if err := sess.removePreparedStatement(in.PreparedStatementId); err != nil {
+		return nil, s.toClientError(err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+type resultsStream interface {
+	Send(*service.ExecuteSQLStatementResponse) error
+}
+
+// sendResults sends the column definitions followed by the rows of the executor, in pages of pageSize rows
+func sendResults(executor exec.PullExecutor, pageSize int, stream resultsStream) error {
+	// First send column definitions.
+	columns := toColumns(executor.ColNames(), executor.ColTypes(), nil)
 	if err := stream.Send(&service.ExecuteSQLStatementResponse{Result: &service.ExecuteSQLStatementResponse_Columns{Columns: columns}}); err != nil {
 		return errors.WithStack(err)
 	}
 
 	// Then start sending pages until complete.
-	numCols := len(executor.ColTypes())
 	for {
 		// Transcode rows.
-		rows, err := executor.GetRows(int(in.PageSize))
+		rows, err := executor.GetRows(pageSize)
 		if err != nil {
 			return errors.WithStack(err)
 		}
 		prows := make([]*service.Row, rows.RowCount())
 		for i := 0; i < rows.RowCount(); i++ {
 			row := rows.GetRow(i)
-			colVals := make([]*service.ColValue, numCols)
-			for colNum, colType := range executor.ColTypes() {
-				colVal := &service.ColValue{}
-				colVals[colNum] = colVal
-				if row.IsNull(colNum) {
-					colVal.Value = &service.ColValue_IsNull{IsNull: true}
-				} else {
-					switch colType.Type {
-					case common.TypeTinyInt, common.TypeInt, common.TypeBigInt:
-						colVal.Value = &service.ColValue_IntValue{IntValue: row.GetInt64(colNum)}
-					case common.TypeDouble:
-						colVal.Value = &service.ColValue_FloatValue{FloatValue: row.GetFloat64(colNum)}
-					case common.TypeVarchar:
-						colVal.Value = &service.ColValue_StringValue{StringValue: row.GetString(colNum)}
-					case common.TypeDecimal:
-						dec := row.GetDecimal(colNum)
-						// We encode the decimal as a string
-						colVal.Value = &service.ColValue_StringValue{StringValue: dec.String()}
-					case common.TypeTimestamp:
-						ts := row.GetTimestamp(colNum)
-						gt, err := ts.GoTime(time.UTC)
-						if err != nil {
-							return err
-						}
-						// We encode a datetime as *microseconds* past epoch
-						unixTime := gt.UnixNano() / 1000
-						colVal.Value = &service.ColValue_IntValue{IntValue: unixTime}
-					default:
-						panic(fmt.Sprintf("unexpected column type %d", colType.Type))
-					}
-				}
+			prows[i], err = toRow(&row, executor.ColTypes(), nil)
+			if err != nil {
+				return err
 			}
-			pRow := &service.Row{Values: colVals}
-			prows[i] = pRow
 		}
 		numRows := rows.RowCount()
 		results := &service.Page{
@@ -188,17 +219,226 @@ func (s *Server) ExecuteSQLStatement(in *service.ExecuteSQLStatementRequest,
 		if err = stream.Send(&service.ExecuteSQLStatementResponse{Result: &service.ExecuteSQLStatementResponse_Page{Page: results}}); err != nil {
 			return errors.WithStack(err)
 		}
-		if numRows < int(in.PageSize) {
+		if numRows < pageSize {
 			break
 		}
 	}
 	return nil
 }
 
+// toArgs converts the args of a prepared statement
+func toArgs(pArgs []*service.Arg) ([]interface{}, error) {
+	args := make([]interface{}, len(pArgs))
+	for i, pArg := range pArgs {
+		switch v := pArg.Value.(type) {
+		case *service.Arg_IsNull:
+			args[i] = nil
+		case *service.Arg_IntValue:
+			args[i] = v.IntValue
+		case *service.Arg_FloatValue:
+			args[i] = v.FloatValue
+		case *service.Arg_StringValue:
+			args[i] = v.StringValue
+		case *service.Arg_DecimalValue:
+			dec, err := common.NewDecFromString(v.DecimalValue)
+			if err != nil {
+				return nil, errors.NewInvalidStatementError(fmt.Sprintf("invalid decimal arg %s", v.DecimalValue))
+			}
+			args[i] = *dec
+		case *service.Arg_TimestampValue:
+			// Timestamps are sent as *microseconds* past epoch
+			gt := time.Unix(0, v.TimestampValue*1000)
+			args[i] = common.NewTimestampFromGoTime(gt)
+		default:
+			return nil, errors.NewInvalidStatementError(fmt.Sprintf("arg %d has no value", i))
+		}
+	}
+	return args, nil
+}
+
+// Subscribe sends the rows of a snapshot of a materialized view followed by the changes to it, until the client goes
+// away or the subscription fails
+func (s *Server) Subscribe(in *service.SubscribeRequest, stream service.PranaDBService_SubscribeServer) error {
+	defer common.PanicHandler()
+	sub, err := s.pushEngine.Subscribe(in.Schema, in.MaterializedView, in.ResumeToken)
+	if err != nil {
+		log.Errorf("failed to subscribe %+v", err)
+		return s.toClientError(err)
+	}
+	defer sub.Close()
+	go func() {
+		// Closing the subscription when the client goes away stops us waiting for the next event
+		<-stream.Context().Done()
+		sub.Close()
+	}()
+
+	// Only the visible columns are sent, as for a query
+	tableInfo := sub.MVInfo.TableInfo
+	var colIndexes []int
+	for i := range tableInfo.ColumnTypes {
+		if tableInfo.ColsVisible == nil || tableInfo.ColsVisible[i] {
+			colIndexes = append(colIndexes, i)
+		}
+	}
+	columns := toColumns(tableInfo.ColumnNames, tableInfo.ColumnTypes, colIndexes)
+	if err := stream.Send(&service.ChangeEvent{Event: &service.ChangeEvent_Columns{Columns: columns}}); err != nil {
+		return errors.WithStack(err)
+	}
+	for {
+		event, err := sub.Next()
+		if err != nil {
+			if stream.Context().Err() != nil {
+				// The client has gone away
+				return nil
+			}
+			log.Errorf("subscription failed %+v", err)
+			return s.toClientError(err)
+		}
+		changeEvent := &service.ChangeEvent{ResumeToken: event.ResumeToken}
+		if event.SnapshotComplete {
+			changeEvent.Event = &service.ChangeEvent_SnapshotComplete{SnapshotComplete: true}
+		} else {
+			change := &service.RowChange{}
+			switch {
+			case event.Snapshot:
+				change.Type = service.ChangeType_CHANGE_TYPE_SNAPSHOT
+			case event.PreviousRow == nil:
+				change.Type = service.ChangeType_CHANGE_TYPE_INSERT
+			case event.CurrentRow == nil:
+				change.Type = service.ChangeType_CHANGE_TYPE_DELETE
+			default:
+				change.Type = service.ChangeType_CHANGE_TYPE_UPDATE
+			}
+			if event.CurrentRow != nil {
+				if change.Row, err = toRow(event.CurrentRow, tableInfo.ColumnTypes, colIndexes); err != nil {
+					return errors.WithStack(err)
+				}
+			}
+			if event.PreviousRow != nil {
+				if change.PreviousRow, err = toRow(event.PreviousRow, tableInfo.ColumnTypes, colIndexes); err != nil {
+					return errors.WithStack(err)
+				}
+			}
+			changeEvent.Event = &service.ChangeEvent_Change{Change: change}
+		}
+		if err := stream.Send(changeEvent); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+}
+
+func (s *Server) toClientError(err error) error {
+	var perr errors.PranaError
+	if errors.As(err, &perr) {
+		return perr
+	}
+	// For internal errors we don't return internal error messages to the CLI as this would leak
+	// server implementation details. Instead, we generate a sequence number and add that to the message
+	// and log the internal error in the server logs with the sequence number so it can be looked up
+	seq := atomic.AddInt64(&s.errorSequence, 1)
+	perr = errors.NewInternalError(seq)
+	log.Errorf("internal error occurred with sequence number %d\n%v", seq, err)
+	return perr
+}
+
+// toColumns converts the columns with the indexes colIndexes, or all of them if colIndexes is nil
+func toColumns(names []string, colTypes []common.ColumnType, colIndexes []int) *service.Columns {
+	columns := &service.Columns{}
+	for i, typ := range colTypes {
+		if colIndexes != nil && !containsIndex(colIndexes, i) {
+			continue
+		}
+		column := &service.Column{
+			Name: names[i],
+			Type: service.ColumnType(typ.Type),
+		}
+		if typ.Type == common.TypeDecimal {
+			column.DecimalParams = &service.DecimalParams{
+				DecimalPrecision: uint32(typ.DecPrecision),
+				DecimalScale:     uint32(typ.DecScale),
+			}
+		}
+		columns.Columns = append(columns.Columns, column)
+	}
+	return columns
+}
+
+// toRow converts the values of the columns with the indexes colIndexes, or all of them if colIndexes is nil
+func toRow(row *common.Row, colTypes []common.ColumnType, colIndexes []int) (*service.Row, error) {
+	colVals := make([]*service.ColValue, 0, len(colTypes))
+	for colNum, colType := range colTypes {
+		if colIndexes != nil && !containsIndex(colIndexes, colNum) {
+			continue
+		}
+		colVal := &service.ColValue{}
+		colVals = append(colVals, colVal)
+		if row.IsNull(colNum) {
+			colVal.Value = &service.ColValue_IsNull{IsNull: true}
+		} else {
+			switch colType.Type {
+			case common.TypeTinyInt, common.TypeInt, common.TypeBigInt:
+				colVal.Value = &service.ColValue_IntValue{IntValue: row.GetInt64(colNum)}
+			case common.TypeDouble:
+				colVal.Value = &service.ColValue_FloatValue{FloatValue: row.GetFloat64(colNum)}
+			case common.TypeVarchar:
+				colVal.Value = &service.ColValue_StringValue{StringValue: row.GetString(colNum)}
+			case common.TypeDecimal:
+				dec := row.GetDecimal(colNum)
+				// We encode the decimal as a string
+				colVal.Value = &service.ColValue_StringValue{StringValue: dec.String()}
+			case common.TypeTimestamp:
+				ts := row.GetTimestamp(colNum)
+				gt, err := ts.GoTime(time.UTC)
+				if err != nil {
+					return nil, err
+				}
+				// We encode a datetime as *microseconds* past epoch
+				unixTime := gt.UnixNano() / 1000
+				colVal.Value = &service.ColValue_IntValue{IntValue: unixTime}
+			case common.TypeBoolean:
+				colVal.Value = &service.ColValue_IntValue{IntValue: row.GetInt64(colNum)}
+			case common.TypeDate:
+				ts := row.GetTimestamp(colNum)
+				gt, err := ts.GoTime(time.UTC)
+				if err != nil {
+					return nil, err
+				}
+				// We encode a date as *microseconds* past epoch of its midnight UTC
+				colVal.Value = &service.ColValue_IntValue{IntValue: gt.UnixNano() / 1000}
+			case common.TypeVarbinary:
+				colVal.Value = &service.ColValue_BytesValue{BytesValue: row.GetBytes(colNum)}
+			case common.TypeJSON:
+				// We encode JSON as its text representation
+				colVal.Value = &service.ColValue_StringValue{StringValue: row.GetJSON(colNum).String()}
+			default:
+				panic(fmt.Sprintf("unexpected column type %d", colType.Type))
+			}
+		}
+	}
+	return &service.Row{Values: colVals}, nil
+}
+
+func containsIndex(indexes []int, index int) bool {
+	for _, i := range indexes {
+		if i == index {
+			return true
+		}
+	}
+	return false
+}
+
 func (s *Server) RegisterProtobufs(ctx context.Context, request *service.RegisterProtobufsRequest) (*emptypb.Empty, error) {
 	return &emptypb.Empty{}, s.protoRegistry.RegisterFiles(request.GetDescriptors())
 }
 
+func (s *Server) RegisterAvroSchemas(ctx context.Context, request *service.RegisterAvroSchemasRequest) (*emptypb.Empty, error) {
+	schemas := make(map[int32]string, len(request.GetSchemas()))
+	for _, schema := range request.GetSchemas() {
+		schemas[schema.GetId()] = schema.GetSchema()
+	}
+	return &emptypb.Empty{}, s.avroRegistry.RegisterSchemas(schemas)
+}
+
 func (s *Server) GetListenAddress() string {
 	return s.serverAddress
 }
@@ -0,0 +1,110 @@
+package api
+
+import (
+	"context"
+	"sync"
+
+	"github.com/squareup/pranadb/errors"
+	"github.com/squareup/pranadb/pull"
+	"google.golang.org/grpc/stats"
+)
+
+type sessionKey struct{}
+
+// maxPreparedStatements is the maximum number of statements which can be prepared on a connection and not yet closed
+const maxPreparedStatements = 1000
+
+// session holds the statements prepared on a client connection. It is closed when the connection closes.
+type session struct {
+	lock               sync.Mutex
+	closed             bool
+	psSequence         int64
+	preparedStatements map[int64]*preparedStatement
+}
+
+type preparedStatement struct {
+	// A prepared query must not be executed concurrently, so this is held while it is executed
+	lock  sync.Mutex
+	query *pull.PreparedQuery
+}
+
+func (s *session) addPreparedStatement(query *pull.PreparedQuery) (int64, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.closed {
+		return 0, errors.Error("session is closed")
+	}
+	if len(s.preparedStatements) >= maxPreparedStatements {
+		return 0, errors.NewTooManyPreparedStatementsError(maxPreparedStatements)
+	}
+	s.psSequence++
+	s.preparedStatements[s.psSequence] = &preparedStatement{query: query}
+	return s.psSequence, nil
+}
+
+// removePreparedStatement removes a prepared statement from the session. An execution of it which is in progress is
+// not affected.
+func (s *session) removePreparedStatement(psID int64) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if _, ok := s.preparedStatements[psID]; !ok {
+		return errors.NewUnknownPreparedStatementError(psID)
+	}
+	delete(s.preparedStatements, psID)
+	return nil
+}
+
+func (s *session) getPreparedStatement(psID int64) (*preparedStatement, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	ps, ok := s.preparedStatements[psID]
+	if !ok {
+		return nil, errors.NewUnknownPreparedStatementError(psID)
+	}
+	return ps, nil
+}
+
+// close closes all the prepared statements of the session, waiting for any executions of them in progress to finish
+func (s *session) close() {
+	s.lock.Lock()
+	s.closed = true
+	preparedStatements := s.preparedStatements
+	s.preparedStatements = make(map[int64]*preparedStatement)
+	s.lock.Unlock()
+	for _, ps := range preparedStatements {
+		ps.lock.Lock()
+		ps.query.Close()
+		ps.lock.Unlock()
+	}
+}
+
+func getSession(ctx context.Context) (*session, error) {
+	sess, ok := ctx.Value(sessionKey{}).(*session)
+	if !ok {
+		return nil, errors.Error("no session for connection")
+	}
+	return sess, nil
+}
+
+// sessionHandler creates a session for each client connection, and closes it when the connection ends
+type sessionHandler struct{}
+
+func (h *sessionHandler) TagConn(ctx context.Context, _ *stats.ConnTagInfo) context.Context {
+	return context.WithValue(ctx, sessionKey{}, &session{preparedStatements: make(map[int64]*preparedStatement)})
+}
+
+func (h *sessionHandler) HandleConn(ctx context.Context, connStats stats.ConnStats) {
+	if _, ok := connStats.(*stats.ConnEnd); !ok {
+		return
+	}
+	if sess, err := getSession(ctx); err == nil {
+		sess.close()
+	}
+}
+
+func (h *sessionHandler) TagRPC(ctx context.Context, _ *stats.RPCTagInfo) context.Context {
+	return ctx
+}
+
+func (h *sessionHandler) HandleRPC(context.Context, stats.RPCStats) {
+}
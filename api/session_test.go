@@ -0,0 +1,65 @@
+package api
+
+import (
+	"context"
+	"testing"
+
+	"github.com/squareup/pranadb/common"
+	"github.com/squareup/pranadb/errors"
+	"github.com/squareup/pranadb/pull"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/stats"
+)
+
+func TestSessionLimitsPreparedStatements(t *testing.T) {
+	sess := &session{preparedStatements: make(map[int64]*preparedStatement)}
+	var psIDs []int64
+	for i := 0; i < maxPreparedStatements; i++ {
+		psID, err := sess.addPreparedStatement(&pull.PreparedQuery{})
+		require.NoError(t, err)
+		psIDs = append(psIDs, psID)
+	}
+	_, err := sess.addPreparedStatement(&pull.PreparedQuery{})
+	require.Error(t, err)
+	var perr errors.PranaError
+	require.True(t, errors.As(err, &perr))
+	require.Equal(t, errors.ErrorCode(errors.TooManyPreparedStatements), perr.Code)
+
+	// Closing a statement makes room for another
+	require.NoError(t, sess.removePreparedStatement(psIDs[0]))
+	_, err = sess.getPreparedStatement(psIDs[0])
+	require.Error(t, err)
+	psID, err := sess.addPreparedStatement(&pull.PreparedQuery{})
+	require.NoError(t, err)
+	require.NotEqual(t, psIDs[0], psID)
+}
+
+func TestSessionClosedWhenConnectionEnds(t *testing.T) {
+	handler := &sessionHandler{}
+	ctx := handler.TagConn(context.Background(), &stats.ConnTagInfo{})
+	sess, err := getSession(ctx)
+	require.NoError(t, err)
+	schema := common.NewSchema("test")
+	schema.PutTable("table1", &common.UserTableInfo{TableInfo: &common.TableInfo{
+		ID:             1000,
+		SchemaName:     "test",
+		Name:           "table1",
+		PrimaryKeyCols: []int{0},
+		ColumnNames:    []string{"col0"},
+		ColumnTypes:    []common.ColumnType{common.BigIntColumnType},
+	}})
+	pq, err := (&pull.Engine{}).PrepareQuery(schema, "select col0 from table1 where col0 = ?")
+	require.NoError(t, err)
+	psID, err := sess.addPreparedStatement(pq)
+	require.NoError(t, err)
+
+	handler.HandleConn(ctx, &stats.ConnBegin{})
+	_, err = sess.getPreparedStatement(psID)
+	require.NoError(t, err)
+
+	handler.HandleConn(ctx, &stats.ConnEnd{})
+	_, err = sess.getPreparedStatement(psID)
+	require.Error(t, err)
+	_, err = sess.addPreparedStatement(pq)
+	require.Error(t, err)
+}
@@ -0,0 +1,82 @@
+package avrolib
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/squareup/pranadb/errors"
+)
+
+// FakeSchemaRegistry is an in-memory schema registry for tests. It serves the part of the Confluent Schema Registry
+// API which Prana uses to fetch schemas.
+type FakeSchemaRegistry struct {
+	lock    sync.Mutex
+	server  *httptest.Server
+	schemas map[int32]*Schema
+}
+
+func NewFakeSchemaRegistry() *FakeSchemaRegistry {
+	fr := &FakeSchemaRegistry{schemas: map[int32]*Schema{}}
+	fr.server = httptest.NewServer(http.HandlerFunc(fr.handle))
+	return fr
+}
+
+// URL returns the URL the registry is listening at
+func (f *FakeSchemaRegistry) URL() string {
+	return f.server.URL
+}
+
+func (f *FakeSchemaRegistry) Close() {
+	f.server.Close()
+}
+
+// RegisterSchema registers the schema with the given id
+func (f *FakeSchemaRegistry) RegisterSchema(id int32, schema string) error {
+	s, err := NewSchema(id, schema)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.schemas[id] = s
+	return nil
+}
+
+func (f *FakeSchemaRegistry) FindSchemaByID(id int32) (*Schema, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	schema, ok := f.schemas[id]
+	if !ok {
+		return nil, errors.Errorf("avro schema %d not found", id)
+	}
+	return schema, nil
+}
+
+var _ Resolver = &FakeSchemaRegistry{}
+
+func (f *FakeSchemaRegistry) handle(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/vnd.schemaregistry.v1+json")
+	if r.Method != http.MethodGet || !strings.HasPrefix(r.URL.Path, "/schemas/ids/") {
+		writeJSON(w, http.StatusNotFound, map[string]interface{}{"error_code": 404, "message": "HTTP 404 Not Found"})
+		return
+	}
+	var schema *Schema
+	id, err := strconv.ParseInt(strings.TrimPrefix(r.URL.Path, "/schemas/ids/"), 10, 32)
+	if err == nil {
+		schema, err = f.FindSchemaByID(int32(id))
+	}
+	if err != nil {
+		writeJSON(w, http.StatusNotFound, map[string]interface{}{"error_code": 40403, "message": "Schema not found"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"schema": schema.String()})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,216 @@
+package avrolib
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/squareup/pranadb/cluster"
+	"github.com/squareup/pranadb/common"
+	"github.com/squareup/pranadb/errors"
+	"github.com/squareup/pranadb/meta"
+	"github.com/squareup/pranadb/protos/squareup/cash/pranadb/v1/notifications"
+	"github.com/squareup/pranadb/remoting"
+	"github.com/squareup/pranadb/table"
+)
+
+const (
+	schemaRegistryTimeout = 10 * time.Second
+	// A failed fetch is retried no sooner than this, so messages with an unknown schema id, or an unavailable schema
+	// registry, don't make every message wait for a request to the schema registry
+	schemaRegistryRetryInterval = 30 * time.Second
+)
+
+// Resolver looks up Avro schemas by the id given to them in the schema registry wire format.
+type Resolver interface {
+	FindSchemaByID(id int32) (*Schema, error)
+}
+
+// EmptyRegistry is a resolver that never finds a schema.
+var EmptyRegistry = emptyRegistry{}
+
+type emptyRegistry struct{}
+
+func (e emptyRegistry) FindSchemaByID(id int32) (*Schema, error) {
+	return nil, errors.Errorf("avro schema %d not found", id)
+}
+
+var _ Resolver = &emptyRegistry{}
+
+var schemaRowsFactory = common.NewRowsFactory(meta.AvroSchemaTableInfo.ColumnTypes)
+
+// AvroRegistry contains all Avro schemas registered with Prana. It first attempts to look up a schema registered in
+// the cluster storage. If not found, and a schema registry URL is configured, it fetches the schema from the schema
+// registry and caches it - a schema registry never changes the schema with a given id. A failed fetch is also cached
+// for a while, and the error returned until it is retried.
+type AvroRegistry struct {
+	mu struct {
+		sync.RWMutex
+		registered map[int32]*Schema
+		fetched    map[int32]*Schema
+		failed     map[int32]failedFetch
+	}
+	registryURL string
+	httpClient  *http.Client
+	cluster     cluster.Cluster
+	queryExec   common.SimpleQueryExec
+	notify      func(message remoting.ClusterMessage) error
+}
+
+// NewAvroRegistry creates a new schema store. "registryURL" is the optional URL of a schema registry to fetch schemas
+// which haven't been registered with Prana from.
+func NewAvroRegistry(clus cluster.Cluster, queryExec common.SimpleQueryExec, registryURL string) *AvroRegistry {
+	ar := &AvroRegistry{
+		registryURL: strings.TrimSuffix(registryURL, "/"),
+		httpClient:  &http.Client{Timeout: schemaRegistryTimeout},
+		cluster:     clus,
+		queryExec:   queryExec,
+	}
+	ar.mu.registered = map[int32]*Schema{}
+	ar.mu.fetched = map[int32]*Schema{}
+	ar.mu.failed = map[int32]failedFetch{}
+	return ar
+}
+
+type failedFetch struct {
+	err     error
+	retryAt time.Time
+}
+
+// Start the AvroRegistry, loading the registered schemas.
+func (a *AvroRegistry) Start() error {
+	return a.reloadSchemasFromTable()
+}
+
+// Stop the AvroRegistry
+func (a *AvroRegistry) Stop() error {
+	return nil
+}
+
+func (a *AvroRegistry) reloadSchemasFromTable() error {
+	rows, err := a.queryExec.ExecuteQuery(meta.SystemSchemaName,
+		"select id, avro_schema from "+meta.AvroSchemaTableName)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	registered := make(map[int32]*Schema, rows.RowCount())
+	for i := 0; i < rows.RowCount(); i++ {
+		row := rows.GetRow(i)
+		id := int32(row.GetInt64(0))
+		schema, err := NewSchema(id, row.GetString(1))
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		registered[id] = schema
+	}
+
+	a.mu.Lock()
+	a.mu.registered = registered
+	a.mu.Unlock()
+
+	return nil
+}
+
+// FindSchemaByID looks up a schema by its id.
+func (a *AvroRegistry) FindSchemaByID(id int32) (*Schema, error) {
+	a.mu.RLock()
+	schema, ok := a.mu.registered[id]
+	if !ok {
+		schema, ok = a.mu.fetched[id]
+	}
+	failed, hasFailed := a.mu.failed[id]
+	a.mu.RUnlock()
+	if ok {
+		return schema, nil
+	}
+	if a.registryURL == "" {
+		return nil, errors.Errorf("avro schema %d not found", id)
+	}
+	if hasFailed && time.Now().Before(failed.retryAt) {
+		return nil, failed.err
+	}
+	schema, err := a.fetchSchema(id)
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if err != nil {
+		err = errors.WithStack(err)
+		a.mu.failed[id] = failedFetch{err: err, retryAt: time.Now().Add(schemaRegistryRetryInterval)}
+		return nil, err
+	}
+	delete(a.mu.failed, id)
+	a.mu.fetched[id] = schema
+	return schema, nil
+}
+
+func (a *AvroRegistry) fetchSchema(id int32) (*Schema, error) {
+	url := fmt.Sprintf("%s/schemas/ids/%d", a.registryURL, id)
+	resp, err := a.httpClient.Get(url)
+	if err != nil {
+		return nil, errors.Errorf("failed to fetch avro schema %d from schema registry: %v", id, err)
+	}
+	defer resp.Body.Close() //nolint:errcheck
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Errorf("failed to fetch avro schema %d from schema registry: %v", id, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("failed to fetch avro schema %d from schema registry: %s %s", id, resp.Status,
+			string(body))
+	}
+	var schemaResp struct {
+		Schema     string `json:"schema"`
+		SchemaType string `json:"schemaType"`
+	}
+	if err := json.Unmarshal(body, &schemaResp); err != nil {
+		return nil, errors.Errorf("invalid response fetching avro schema %d from schema registry: %v", id, err)
+	}
+	if schemaResp.SchemaType != "" && schemaResp.SchemaType != "AVRO" {
+		return nil, errors.Errorf("schema %d in schema registry is not an avro schema: %s", id, schemaResp.SchemaType)
+	}
+	return NewSchema(id, schemaResp.Schema)
+}
+
+// RegisterSchemas registers the schemas, by id, with Prana. A registered schema replaces any existing schema with the
+// same id.
+func (a *AvroRegistry) RegisterSchemas(schemas map[int32]string) error {
+	wb := cluster.NewWriteBatch(cluster.SystemSchemaShardID)
+	for id, schemaStr := range schemas {
+		// Ensure the schema is valid
+		schema, err := NewSchema(id, schemaStr)
+		if err != nil {
+			return errors.NewPranaErrorf(errors.InvalidStatement, "%v", err)
+		}
+		if err := table.Upsert(meta.AvroSchemaTableInfo.TableInfo, encodeSchemaToRow(schema), wb); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	if err := a.cluster.WriteBatch(wb); err != nil {
+		return errors.WithStack(err)
+	}
+
+	if err := a.notify(&notifications.ReloadAvroSchemas{}); err != nil {
+		return errors.WithStack(err)
+	}
+
+	return nil
+}
+
+func (a *AvroRegistry) SetNotifier(notify func(message remoting.ClusterMessage) error) {
+	a.notify = notify
+}
+
+func (a *AvroRegistry) HandleMessage(n remoting.ClusterMessage) (remoting.ClusterMessage, error) {
+	return nil, a.reloadSchemasFromTable()
+}
+
+func encodeSchemaToRow(schema *Schema) *common.Row {
+	rows := schemaRowsFactory.NewRows(1)
+	rows.AppendInt64ToColumn(0, int64(schema.ID))
+	rows.AppendStringToColumn(1, schema.String())
+	row := rows.GetRow(0)
+	return &row
+}
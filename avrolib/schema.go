@@ -0,0 +1,362 @@
+package avrolib
+
+import (
+	"encoding/json"
+	"math/big"
+	"strings"
+
+	"github.com/linkedin/goavro/v2"
+	"github.com/squareup/pranadb/common"
+	"github.com/squareup/pranadb/errors"
+)
+
+// The schema registry wire format is a zero magic byte, then the id of the schema as a 4 byte big endian int, then
+// the Avro binary encoding of the datum.
+const (
+	wireFormatMagicByte  = 0
+	wireFormatHeaderSize = 5
+)
+
+// ReadWireFormatHeader returns the id of the schema and the Avro encoded datum of a message in the schema registry
+// wire format
+func ReadWireFormatHeader(bytes []byte) (int32, []byte, error) {
+	if len(bytes) < wireFormatHeaderSize || bytes[0] != wireFormatMagicByte {
+		return 0, nil, errors.Error("message is not in the schema registry wire format")
+	}
+	schemaID, _ := common.ReadUint32FromBufferBE(bytes, 1)
+	return int32(schemaID), bytes[wireFormatHeaderSize:], nil
+}
+
+// AppendWireFormatHeader appends the schema registry wire format header for the schema to the buffer
+func AppendWireFormatHeader(buff []byte, schemaID int32) []byte {
+	buff = append(buff, wireFormatMagicByte)
+	return common.AppendUint32ToBufferBE(buff, uint32(schemaID))
+}
+
+// Schema is a parsed Avro schema.
+//
+// goavro decodes a union as a map with the name of the type of the value as its only key, and a decimal as a *big.Rat.
+// Decode unwraps unions and converts decimals to strings, so that column selectors and type coercion work on decoded
+// records the same way as on decoded JSON. Encode does the reverse.
+type Schema struct {
+	ID     int32
+	schema string
+	codec  *goavro.Codec
+	root   *schemaNode
+}
+
+// schemaNode describes the parts of a type of the schema we need to convert between goavro native values and plain
+// ones
+type schemaNode struct {
+	kind     string                 // "record", "array", "map", "union", "decimal" or "other"
+	typeName string                 // The name goavro gives the type as a member of a union
+	scale    int                    // For a decimal
+	fields   map[string]*schemaNode // For a record
+	names    []string               // The names of the fields of a record, in order
+	elem     *schemaNode            // For an array or a map
+	members  []*schemaNode          // For a union
+}
+
+func NewSchema(id int32, schema string) (*Schema, error) {
+	codec, err := goavro.NewCodec(schema)
+	if err != nil {
+		return nil, errors.Errorf("invalid avro schema %d: %v", id, err)
+	}
+	var spec interface{}
+	if err := json.Unmarshal([]byte(schema), &spec); err != nil {
+		// A schema can be just the name of a primitive type, which isn't valid JSON
+		spec = schema
+	}
+	p := &schemaParser{named: map[string]*schemaNode{}}
+	root, err := p.parse(spec, "")
+	if err != nil {
+		return nil, errors.Errorf("invalid avro schema %d: %v", id, err)
+	}
+	return &Schema{ID: id, schema: schema, codec: codec, root: root}, nil
+}
+
+// String returns the schema as it was defined. Unlike the canonical form it keeps attributes such as logical types
+// which change how values are decoded.
+func (s *Schema) String() string {
+	return s.schema
+}
+
+// FieldNames returns the names of the fields, in order, of a record schema
+func (s *Schema) FieldNames() []string {
+	return s.root.names
+}
+
+// Decode decodes the Avro binary encoding of a datum
+func (s *Schema) Decode(bytes []byte) (interface{}, error) {
+	native, _, err := s.codec.NativeFromBinary(bytes)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return s.root.fromNative(native), nil
+}
+
+// Encode appends the Avro binary encoding of the value to the buffer
+func (s *Schema) Encode(buff []byte, val interface{}) ([]byte, error) {
+	native, err := s.root.toNative(val)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	buff, err = s.codec.BinaryFromNative(buff, native)
+	return buff, errors.WithStack(err)
+}
+
+func (n *schemaNode) fromNative(val interface{}) interface{} {
+	if val == nil {
+		return nil
+	}
+	switch n.kind {
+	case "record":
+		m, ok := val.(map[string]interface{})
+		if !ok {
+			return val
+		}
+		for name, field := range n.fields {
+			if v, ok := m[name]; ok {
+				m[name] = field.fromNative(v)
+			}
+		}
+	case "array":
+		if arr, ok := val.([]interface{}); ok {
+			for i, v := range arr {
+				arr[i] = n.elem.fromNative(v)
+			}
+		}
+	case "map":
+		if m, ok := val.(map[string]interface{}); ok {
+			for k, v := range m {
+				m[k] = n.elem.fromNative(v)
+			}
+		}
+	case "union":
+		m, ok := val.(map[string]interface{})
+		if !ok || len(m) != 1 {
+			return val
+		}
+		for typeName, v := range m {
+			for _, member := range n.members {
+				if member.typeName == typeName {
+					return member.fromNative(v)
+				}
+			}
+			return v
+		}
+	case "decimal":
+		if r, ok := val.(*big.Rat); ok {
+			return r.FloatString(n.scale)
+		}
+	}
+	return val
+}
+
+func (n *schemaNode) toNative(val interface{}) (interface{}, error) {
+	if val == nil {
+		return nil, nil
+	}
+	switch n.kind {
+	case "record":
+		m, ok := val.(map[string]interface{})
+		if !ok {
+			return val, nil
+		}
+		res := make(map[string]interface{}, len(m))
+		for name, v := range m {
+			field, ok := n.fields[name]
+			if !ok {
+				res[name] = v
+				continue
+			}
+			nv, err := field.toNative(v)
+			if err != nil {
+				return nil, err
+			}
+			res[name] = nv
+		}
+		return res, nil
+	case "array":
+		arr, ok := val.([]interface{})
+		if !ok {
+			return val, nil
+		}
+		res := make([]interface{}, len(arr))
+		for i, v := range arr {
+			nv, err := n.elem.toNative(v)
+			if err != nil {
+				return nil, err
+			}
+			res[i] = nv
+		}
+		return res, nil
+	case "map":
+		m, ok := val.(map[string]interface{})
+		if !ok {
+			return val, nil
+		}
+		res := make(map[string]interface{}, len(m))
+		for k, v := range m {
+			nv, err := n.elem.toNative(v)
+			if err != nil {
+				return nil, err
+			}
+			res[k] = nv
+		}
+		return res, nil
+	case "union":
+		// The value is given as the first member of the union which isn't null
+		for _, member := range n.members {
+			if member.typeName == "null" {
+				continue
+			}
+			nv, err := member.toNative(val)
+			if err != nil {
+				return nil, err
+			}
+			return goavro.Union(member.typeName, nv), nil
+		}
+		return nil, errors.Errorf("cannot encode %v as a union with no non null members", val)
+	case "decimal":
+		if s, ok := val.(string); ok {
+			r, ok := new(big.Rat).SetString(s)
+			if !ok {
+				return nil, errors.Errorf("cannot encode %q as a decimal", s)
+			}
+			return r, nil
+		}
+	}
+	return val, nil
+}
+
+type schemaParser struct {
+	named map[string]*schemaNode
+}
+
+func (p *schemaParser) parse(spec interface{}, namespace string) (*schemaNode, error) {
+	switch s := spec.(type) {
+	case string:
+		if n, ok := p.lookupNamed(s, namespace); ok {
+			return n, nil
+		}
+		return &schemaNode{kind: "other", typeName: s}, nil
+	case []interface{}:
+		n := &schemaNode{kind: "union"}
+		for _, memberSpec := range s {
+			member, err := p.parse(memberSpec, namespace)
+			if err != nil {
+				return nil, err
+			}
+			n.members = append(n.members, member)
+		}
+		return n, nil
+	case map[string]interface{}:
+		return p.parseComplex(s, namespace)
+	default:
+		return nil, errors.Errorf("unexpected type definition %v", spec)
+	}
+}
+
+func (p *schemaParser) parseComplex(spec map[string]interface{}, namespace string) (*schemaNode, error) {
+	typ, ok := spec["type"]
+	if !ok {
+		return nil, errors.Errorf("type definition has no type %v", spec)
+	}
+	typeStr, ok := typ.(string)
+	if !ok {
+		// e.g. {"type": {"type": "array", ...}}
+		return p.parse(typ, namespace)
+	}
+	switch typeStr {
+	case "record", "error", "enum", "fixed":
+		fullName, ns := fullNameOf(spec, namespace)
+		n := &schemaNode{kind: "other", typeName: fullName}
+		if typeStr == "fixed" {
+			if logicalType, _ := spec["logicalType"].(string); logicalType == "decimal" {
+				n.kind = "decimal"
+				n.scale = intProp(spec, "scale")
+			}
+		}
+		// Register the type before parsing its fields, as they can refer to it
+		p.named[fullName] = n
+		if typeStr != "record" && typeStr != "error" {
+			return n, nil
+		}
+		n.kind = "record"
+		n.fields = map[string]*schemaNode{}
+		fields, _ := spec["fields"].([]interface{})
+		for _, f := range fields {
+			field, ok := f.(map[string]interface{})
+			if !ok {
+				return nil, errors.Errorf("invalid field %v", f)
+			}
+			name, _ := field["name"].(string)
+			fieldNode, err := p.parse(field["type"], ns)
+			if err != nil {
+				return nil, err
+			}
+			n.fields[name] = fieldNode
+			n.names = append(n.names, name)
+		}
+		return n, nil
+	case "array":
+		elem, err := p.parse(spec["items"], namespace)
+		if err != nil {
+			return nil, err
+		}
+		return &schemaNode{kind: "array", typeName: "array", elem: elem}, nil
+	case "map":
+		elem, err := p.parse(spec["values"], namespace)
+		if err != nil {
+			return nil, err
+		}
+		return &schemaNode{kind: "map", typeName: "map", elem: elem}, nil
+	default:
+		if n, ok := p.lookupNamed(typeStr, namespace); ok {
+			return n, nil
+		}
+		// A primitive type, possibly with a logical type
+		logicalType, _ := spec["logicalType"].(string)
+		if logicalType == "" {
+			return &schemaNode{kind: "other", typeName: typeStr}, nil
+		}
+		n := &schemaNode{kind: "other", typeName: typeStr + "." + logicalType}
+		if logicalType == "decimal" {
+			n.kind = "decimal"
+			n.scale = intProp(spec, "scale")
+		}
+		return n, nil
+	}
+}
+
+func (p *schemaParser) lookupNamed(name string, namespace string) (*schemaNode, bool) {
+	if !strings.Contains(name, ".") && namespace != "" {
+		if n, ok := p.named[namespace+"."+name]; ok {
+			return n, true
+		}
+	}
+	n, ok := p.named[name]
+	return n, ok
+}
+
+// fullNameOf returns the full name of a named type, and the namespace of the types defined within it
+func fullNameOf(spec map[string]interface{}, enclosingNamespace string) (string, string) {
+	name, _ := spec["name"].(string)
+	if i := strings.LastIndex(name, "."); i != -1 {
+		return name, name[:i]
+	}
+	namespace := enclosingNamespace
+	if ns, ok := spec["namespace"].(string); ok {
+		namespace = ns
+	}
+	if namespace == "" {
+		return name, ""
+	}
+	return namespace + "." + name, namespace
+}
+
+func intProp(spec map[string]interface{}, prop string) int {
+	v, _ := spec[prop].(float64)
+	return int(v)
+}
@@ -0,0 +1,119 @@
+package avrolib
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testSchema = `{
+  "type": "record",
+  "name": "Payment",
+  "namespace": "test",
+  "fields": [
+    {"name": "id", "type": "string"},
+    {"name": "amount", "type": {"type": "bytes", "logicalType": "decimal", "precision": 10, "scale": 2}},
+    {"name": "created", "type": {"type": "long", "logicalType": "timestamp-millis"}},
+    {"name": "currency", "type": ["null", "string"]},
+    {"name": "meta", "type": ["null", {
+      "type": "record",
+      "name": "Meta",
+      "fields": [
+        {"name": "tags", "type": {"type": "array", "items": "string"}},
+        {"name": "fees", "type": {"type": "map", "values": {"type": "fixed", "name": "Fee", "size": 4, "logicalType": "decimal", "precision": 6, "scale": 3}}},
+        {"name": "parent", "type": ["null", "Payment"]}
+      ]
+    }]}
+  ]
+}`
+
+func TestEncodeDecode(t *testing.T) {
+	schema, err := NewSchema(23, testSchema)
+	require.NoError(t, err)
+	require.Equal(t, int32(23), schema.ID)
+	require.Equal(t, testSchema, schema.String())
+	require.Equal(t, []string{"id", "amount", "created", "currency", "meta"}, schema.FieldNames())
+
+	created := time.Date(2021, 5, 10, 10, 30, 0, 123000000, time.UTC)
+	record := map[string]interface{}{
+		"id":       "pay1",
+		"amount":   "12.50",
+		"created":  created,
+		"currency": "USD",
+		"meta": map[string]interface{}{
+			"tags": []interface{}{"card", "online"},
+			"fees": map[string]interface{}{"card": "0.125"},
+			"parent": map[string]interface{}{
+				"id":       "pay0",
+				"amount":   "-1.00",
+				"created":  created,
+				"currency": nil,
+				"meta":     nil,
+			},
+		},
+	}
+	bytes, err := schema.Encode(nil, record)
+	require.NoError(t, err)
+
+	decoded, err := schema.Decode(bytes)
+	require.NoError(t, err)
+	// Unions are unwrapped and decimals are decoded as strings
+	require.Equal(t, record, decoded)
+}
+
+func TestNewSchemaInvalid(t *testing.T) {
+	_, err := NewSchema(1, `{"type": "record", "name": "Foo", "fields": [{"name": "bar", "type": "unknown"}]}`)
+	require.Error(t, err)
+}
+
+func TestWireFormatHeader(t *testing.T) {
+	buff := AppendWireFormatHeader([]byte{}, 1234567)
+	require.Equal(t, []byte{0, 0, 0x12, 0xd6, 0x87}, buff)
+	buff = append(buff, 1, 2, 3)
+
+	id, datum, err := ReadWireFormatHeader(buff)
+	require.NoError(t, err)
+	require.Equal(t, int32(1234567), id)
+	require.Equal(t, []byte{1, 2, 3}, datum)
+
+	_, _, err = ReadWireFormatHeader([]byte{1, 0, 0, 0, 1})
+	require.Error(t, err)
+	_, _, err = ReadWireFormatHeader([]byte{0, 0, 0})
+	require.Error(t, err)
+}
+
+func TestFetchSchemaFromRegistry(t *testing.T) {
+	fakeRegistry := NewFakeSchemaRegistry()
+	defer fakeRegistry.Close()
+	require.NoError(t, fakeRegistry.RegisterSchema(7, testSchema))
+
+	registry := NewAvroRegistry(nil, nil, fakeRegistry.URL()+"/")
+	schema, err := registry.FindSchemaByID(7)
+	require.NoError(t, err)
+	require.Equal(t, int32(7), schema.ID)
+	require.Equal(t, testSchema, schema.String())
+
+	_, err = registry.FindSchemaByID(8)
+	require.Error(t, err)
+
+	// The failed fetch is cached until it can be retried
+	require.NoError(t, fakeRegistry.RegisterSchema(8, testSchema))
+	_, err = registry.FindSchemaByID(8)
+	require.Error(t, err)
+	registry.mu.failed[8] = failedFetch{retryAt: time.Now()}
+	_, err = registry.FindSchemaByID(8)
+	require.NoError(t, err)
+
+	// The fetched schema is cached
+	fakeRegistry.Close()
+	cached, err := registry.FindSchemaByID(7)
+	require.NoError(t, err)
+	require.Same(t, schema, cached)
+}
+
+func TestFindSchemaWithoutRegistry(t *testing.T) {
+	registry := NewAvroRegistry(nil, nil, "")
+	_, err := registry.FindSchemaByID(7)
+	require.Error(t, err)
+}
@@ -25,7 +25,11 @@ const (
 	maxLineWidthPropName = "max_line_width"
 )
 
-// Client is a simple client used for executing statements against PranaDB, it used by the CLI and elsewhere
+// Client is a simple client used for executing statements against PranaDB, it used by the CLI and elsewhere.
+//
+// Statements prepared with PrepareStatement belong to the connection to the server. If the connection is lost and
+// re-established, e.g. because the server restarted, executing a statement prepared before then fails with an
+// UnknownPreparedStatement error, and the statement must be prepared again.
 type Client struct {
 	lock             sync.Mutex
 	started          bool
@@ -248,6 +252,15 @@ func formatLine(values []*service.ColValue, colTypes []common.ColumnType, colWid
 				gt := time.UnixMicro(unixTime).In(time.UTC)
 				v = fmt.Sprintf("%d-%02d-%02d %02d:%02d:%02d.%06d",
 					gt.Year(), gt.Month(), gt.Day(), gt.Hour(), gt.Minute(), gt.Second(), gt.Nanosecond()/1000)
+			case common.TypeBoolean:
+				v = fmt.Sprintf("%t", value.GetIntValue() != 0)
+			case common.TypeDate:
+				gt := time.UnixMicro(value.GetIntValue()).In(time.UTC)
+				v = fmt.Sprintf("%d-%02d-%02d", gt.Year(), gt.Month(), gt.Day())
+			case common.TypeVarbinary:
+				v = string(value.GetBytesValue())
+			case common.TypeJSON:
+				v = value.GetStringValue()
 			case common.TypeUnknown:
 				v = "??"
 			}
@@ -293,7 +306,11 @@ func (c *Client) calcColumnWidths(colTypes []common.ColumnType, colNames []strin
 			w = 20
 		case common.TypeTimestamp:
 			w = 26
-		case common.TypeVarchar, common.TypeDecimal, common.TypeDouble:
+		case common.TypeBoolean:
+			w = 5
+		case common.TypeDate:
+			w = 10
+		case common.TypeVarchar, common.TypeDecimal, common.TypeDouble, common.TypeVarbinary, common.TypeJSON:
 			// We consider these free columns
 			freeCols = append(freeCols, i)
 		default:
@@ -363,6 +380,40 @@ func (c *Client) RegisterProtobufs(ctx context.Context, in *service.RegisterProt
 	return errors.WithStack(err)
 }
 
+func (c *Client) RegisterAvroSchemas(ctx context.Context, in *service.RegisterAvroSchemasRequest, option ...grpc.CallOption) error {
+	_, err := c.client.RegisterAvroSchemas(ctx, in, option...)
+	return errors.WithStack(err)
+}
+
+// Subscribe subscribes to the changes to a materialized view. The first event received has the column definitions.
+// Resuming a subscription with a resume token fails with a ResumeTokenExpired error if the changes after it are no
+// longer kept, see SubscribeRequest.
+func (c *Client) Subscribe(ctx context.Context, in *service.SubscribeRequest, option ...grpc.CallOption) (service.PranaDBService_SubscribeClient, error) {
+	stream, err := c.client.Subscribe(ctx, in, option...)
+	return stream, errors.WithStack(err)
+}
+
+// PrepareStatement prepares a query with param markers (?) so it can be executed many times with different args. The
+// prepared statement can only be executed by this client, and should be closed with ClosePreparedStatement when it's
+// no longer needed.
+func (c *Client) PrepareStatement(ctx context.Context, in *service.PrepareStatementRequest, option ...grpc.CallOption) (*service.PrepareStatementResponse, error) {
+	resp, err := c.client.PrepareStatement(ctx, in, option...)
+	return resp, errors.WithStack(err)
+}
+
+// ExecutePreparedStatement executes a prepared statement. The first response received has the column definitions,
+// followed by pages of rows.
+func (c *Client) ExecutePreparedStatement(ctx context.Context, in *service.ExecutePreparedStatementRequest, option ...grpc.CallOption) (service.PranaDBService_ExecutePreparedStatementClient, error) {
+	stream, err := c.client.ExecutePreparedStatement(ctx, in, option...)
+	return stream, errors.WithStack(err)
+}
+
+// ClosePreparedStatement discards a prepared statement on the server
+func (c *Client) ClosePreparedStatement(ctx context.Context, in *service.ClosePreparedStatementRequest, option ...grpc.CallOption) error {
+	_, err := c.client.ClosePreparedStatement(ctx, in, option...)
+	return errors.WithStack(err)
+}
+
 func stripgRPCPrefix(err error) error {
 	// Strip out the gRPC internal crap from the error message
 	ind := strings.Index(err.Error(), "PDB")
@@ -0,0 +1,182 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/squareup/pranadb/cluster"
+	"github.com/squareup/pranadb/common"
+	"github.com/squareup/pranadb/conf"
+	"github.com/squareup/pranadb/protos/squareup/cash/pranadb/v1/service"
+	"github.com/squareup/pranadb/push/util"
+	"github.com/squareup/pranadb/server"
+	"github.com/squareup/pranadb/sharder"
+	"github.com/squareup/pranadb/table"
+	"github.com/stretchr/testify/require"
+)
+
+// Concurrent updates of different columns of the same row are never lost - each statement reads and writes the row on
+// the scheduler of its shard, so it sees the changes of the statements before it.
+func TestConcurrentUpdatesOfSameRow(t *testing.T) {
+	_, serverAddress := startTestServer(t)
+	cli := startTestClient(t, serverAddress)
+	executeTestStatement(t, cli, "create table t(k bigint, a bigint, b bigint, primary key (k))")
+	executeTestStatement(t, cli, "create materialized view t_totals as select count(*) as num, sum(a) as total_a, sum(b) as total_b from t")
+	executeTestStatement(t, cli, "insert into t values (1, 0, 0)")
+
+	numUpdates := 20
+	var wg sync.WaitGroup
+	for _, col := range []string{"a", "b"} {
+		wg.Add(1)
+		go func(col string) {
+			defer wg.Done()
+			updater := startTestClient(t, serverAddress)
+			for i := 1; i <= numUpdates; i++ {
+				executeTestStatement(t, updater, fmt.Sprintf("update t set %s = %d where k = 1", col, i))
+			}
+		}(col)
+	}
+	wg.Wait()
+
+	// The changes are written by the time the statements complete
+	rows := queryTestRows(t, cli, "select a, b from t")
+	require.Equal(t, 1, len(rows))
+	require.Equal(t, int64(numUpdates), rows[0][0].GetIntValue())
+	require.Equal(t, int64(numUpdates), rows[0][1].GetIntValue())
+
+	// The materialized view sees each update as the retraction of the previous row and the addition of the new one
+	require.Eventually(t, func() bool {
+		rows = queryTestRows(t, cli, "select num, total_a, total_b from t_totals")
+		if len(rows) != 1 || rows[0][0].GetIntValue() != 1 {
+			return false
+		}
+		// The sum of a BIGINT column is a DECIMAL
+		for _, total := range rows[0][1:] {
+			value, err := strconv.ParseFloat(total.GetStringValue(), 64)
+			if err != nil || value != float64(numUpdates) {
+				return false
+			}
+		}
+		return true
+	}, 10*time.Second, 10*time.Millisecond)
+}
+
+// Updates and deletes see the rows of an insert which has returned, even if the shard the rows were forwarded to
+// hasn't handled them yet
+func TestChangeRowsForwardedButNotHandled(t *testing.T) {
+	s, serverAddress := startTestServer(t)
+	cli := startTestClient(t, serverAddress)
+	executeTestStatement(t, cli, "create table t(k bigint, a bigint, primary key (k))")
+	tableInfo, ok := s.GetMetaController().GetUserTable("test", "t")
+	require.True(t, ok)
+
+	// The rows are written to the receiver table of their shards without telling the shards, as if the insert had
+	// returned before the shards were told about its rows
+	for k := int64(1); k <= 2; k++ {
+		rows := common.NewRows(tableInfo.ColumnTypes, 1)
+		rows.AppendInt64ToColumn(0, k)
+		rows.AppendInt64ToColumn(1, 0)
+		row := rows.GetRow(0)
+		key, err := common.EncodeKeyCols(&row, tableInfo.PrimaryKeyCols, tableInfo.ColumnTypes, nil)
+		require.NoError(t, err)
+		shardID, err := s.GetSharder().CalculateShard(sharder.ShardTypeHash, key)
+		require.NoError(t, err)
+		encodedRow, err := common.EncodeRow(&row, tableInfo.ColumnTypes, nil)
+		require.NoError(t, err)
+		receiverKey := table.EncodeTableKeyPrefix(common.ReceiverTableID, shardID, 40)
+		receiverKey = common.AppendUint32ToBufferBE(receiverKey, math.MaxUint32)
+		receiverKey = common.AppendUint64ToBufferBE(receiverKey, uint64(k))
+		receiverKey = common.AppendUint64ToBufferBE(receiverKey, tableInfo.ID)
+		wb := cluster.NewWriteBatch(shardID)
+		wb.AddPut(receiverKey, util.EncodePrevAndCurrentRow(nil, encodedRow))
+		require.NoError(t, s.GetCluster().WriteBatch(wb))
+	}
+
+	executeTestStatement(t, cli, "update t set a = 10 where k = 1")
+	executeTestStatement(t, cli, "delete from t where k = 2")
+	rows := queryTestRows(t, cli, "select k, a from t")
+	require.Equal(t, 1, len(rows))
+	require.Equal(t, int64(1), rows[0][0].GetIntValue())
+	require.Equal(t, int64(10), rows[0][1].GetIntValue())
+}
+
+// startTestServer starts a server with a fake cluster, with its API server on a free port, and returns it and its
+// address
+func startTestServer(t *testing.T) (*server.Server, string) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	serverAddress := listener.Addr().String()
+	require.NoError(t, listener.Close())
+
+	cfg := conf.NewTestConfig(1)
+	cfg.EnableAPIServer = true
+	cfg.APIServerListenAddresses = []string{serverAddress}
+	s, err := server.NewServer(*cfg)
+	require.NoError(t, err)
+	require.NoError(t, s.Start())
+	t.Cleanup(func() {
+		require.NoError(t, s.Stop())
+	})
+	return s, serverAddress
+}
+
+func startTestClient(t *testing.T, serverAddress string) *Client {
+	t.Helper()
+	cli := NewClient(serverAddress)
+	require.NoError(t, cli.Start())
+	t.Cleanup(func() {
+		require.NoError(t, cli.Stop())
+	})
+	executeTestStatement(t, cli, "use test")
+	return cli
+}
+
+func executeTestStatement(t *testing.T, cli *Client, statement string) {
+	t.Helper()
+	ch, err := cli.ExecuteStatement(statement)
+	require.NoError(t, err)
+	var lines []string
+	for line := range ch {
+		lines = append(lines, line)
+	}
+	require.Equal(t, "0 rows returned", lines[len(lines)-1], strings.Join(lines, "\n"))
+}
+
+// queryTestRows executes a query as a prepared statement, so the values of the rows are returned as they are, rather
+// than formatted for display
+func queryTestRows(t *testing.T, cli *Client, query string) [][]*service.ColValue {
+	t.Helper()
+	ps, err := cli.PrepareStatement(context.Background(), &service.PrepareStatementRequest{Schema: "test", Statement: query})
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, cli.ClosePreparedStatement(context.Background(),
+			&service.ClosePreparedStatementRequest{PreparedStatementId: ps.PreparedStatementId}))
+	}()
+	stream, err := cli.ExecutePreparedStatement(context.Background(), &service.ExecutePreparedStatementRequest{
+		PreparedStatementId: ps.PreparedStatementId,
+		PageSize:            1000,
+	})
+	require.NoError(t, err)
+	var rows [][]*service.ColValue
+	for {
+		resp, err := stream.Recv()
+		if err == io.EOF {
+			return rows
+		}
+		require.NoError(t, err)
+		if page, ok := resp.Result.(*service.ExecuteSQLStatementResponse_Page); ok {
+			for _, row := range page.Page.Rows {
+				rows = append(rows, row.Values)
+			}
+		}
+	}
+}
@@ -2,6 +2,7 @@ package cluster
 
 import (
 	"github.com/squareup/pranadb/common"
+	"github.com/squareup/pranadb/errors"
 )
 
 const (
@@ -85,8 +86,25 @@ type QueryExecutionInfo struct {
 	Limit       uint32
 	ShardID     uint64
 	SystemQuery bool
+	// The position of the remote part of the query to execute, as a query has one for each side of a join
+	RemoteExecutorIndex uint32
+	// The serialized rows of keys to look up, for the inner side of an index lookup join
+	LookupKeys []byte
+	// The args of a prepared statement
+	PsArgs []interface{}
+	// Close asks the node to close the query, which has been abandoned before all its rows were fetched
+	Close bool
 }
 
+const (
+	psArgTypeNull byte = iota
+	psArgTypeInt
+	psArgTypeFloat
+	psArgTypeString
+	psArgTypeDecimal
+	psArgTypeTimestamp
+)
+
 func (q *QueryExecutionInfo) Serialize(buff []byte) ([]byte, error) {
 	buff = common.AppendStringToBufferLE(buff, q.ExecutionID)
 	buff = common.AppendStringToBufferLE(buff, q.SchemaName)
@@ -100,6 +118,41 @@ func (q *QueryExecutionInfo) Serialize(buff []byte) ([]byte, error) {
 		b = 0
 	}
 	buff = append(buff, b)
+	buff = common.AppendUint32ToBufferLE(buff, q.RemoteExecutorIndex)
+	if q.Close {
+		b = 1
+	} else {
+		b = 0
+	}
+	buff = append(buff, b)
+	buff = common.AppendStringToBufferLE(buff, string(q.LookupKeys))
+	buff = common.AppendUint32ToBufferLE(buff, uint32(len(q.PsArgs)))
+	for _, arg := range q.PsArgs {
+		switch v := arg.(type) {
+		case nil:
+			buff = append(buff, psArgTypeNull)
+		case int64:
+			buff = append(buff, psArgTypeInt)
+			buff = common.AppendUint64ToBufferLE(buff, uint64(v))
+		case float64:
+			buff = append(buff, psArgTypeFloat)
+			buff = common.AppendFloat64ToBufferLE(buff, v)
+		case string:
+			buff = append(buff, psArgTypeString)
+			buff = common.AppendStringToBufferLE(buff, v)
+		case common.Decimal:
+			buff = append(buff, psArgTypeDecimal)
+			buff = common.AppendStringToBufferLE(buff, v.String())
+		case common.Timestamp:
+			buff = append(buff, psArgTypeTimestamp, byte(v.Fsp()))
+			var err error
+			if buff, err = common.AppendTimestampToBuffer(buff, v); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, errors.Errorf("unexpected prepared statement arg type %T", arg)
+		}
+	}
 	return buff, nil
 }
 
@@ -111,6 +164,53 @@ func (q *QueryExecutionInfo) Deserialize(buff []byte) error {
 	q.Limit, offset = common.ReadUint32FromBufferLE(buff, offset)
 	q.ShardID, offset = common.ReadUint64FromBufferLE(buff, offset)
 	q.SystemQuery = buff[offset] == 1
+	offset++
+	q.RemoteExecutorIndex, offset = common.ReadUint32FromBufferLE(buff, offset)
+	q.Close = buff[offset] == 1
+	offset++
+	var lookupKeys string
+	lookupKeys, offset = common.ReadStringFromBufferLE(buff, offset)
+	if lookupKeys != "" {
+		q.LookupKeys = []byte(lookupKeys)
+	}
+	var numArgs uint32
+	numArgs, offset = common.ReadUint32FromBufferLE(buff, offset)
+	if numArgs == 0 {
+		return nil
+	}
+	q.PsArgs = make([]interface{}, numArgs)
+	for i := range q.PsArgs {
+		argType := buff[offset]
+		offset++
+		switch argType {
+		case psArgTypeNull:
+		case psArgTypeInt:
+			var v uint64
+			v, offset = common.ReadUint64FromBufferLE(buff, offset)
+			q.PsArgs[i] = int64(v)
+		case psArgTypeFloat:
+			q.PsArgs[i], offset = common.ReadFloat64FromBufferLE(buff, offset)
+		case psArgTypeString:
+			q.PsArgs[i], offset = common.ReadStringFromBufferLE(buff, offset)
+		case psArgTypeDecimal:
+			var s string
+			s, offset = common.ReadStringFromBufferLE(buff, offset)
+			dec, err := common.NewDecFromString(s)
+			if err != nil {
+				return err
+			}
+			q.PsArgs[i] = *dec
+		case psArgTypeTimestamp:
+			fsp := int8(buff[offset])
+			offset++
+			var err error
+			if q.PsArgs[i], offset, err = common.ReadTimestampFromBuffer(buff, offset, fsp); err != nil {
+				return err
+			}
+		default:
+			return errors.Errorf("unexpected prepared statement arg type %d", argType)
+		}
+	}
 	return nil
 }
 
@@ -0,0 +1,48 @@
+package cluster
+
+import (
+	"testing"
+
+	"github.com/squareup/pranadb/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSerializeDeserializeQueryExecutionInfo(t *testing.T) {
+	dec, err := common.NewDecFromString("12345.6789")
+	require.NoError(t, err)
+	ts := common.NewTimestampFromString("2021-06-15 12:30:00.123456")
+	info := &QueryExecutionInfo{
+		ExecutionID:         "1-23",
+		SchemaName:          "test",
+		Query:               "select * from foo where col0 = ? and col1 > ?",
+		Limit:               1000,
+		ShardID:             12,
+		SystemQuery:         true,
+		RemoteExecutorIndex: 2,
+		LookupKeys:          []byte("keys"),
+		Close:               true,
+		PsArgs:              []interface{}{nil, int64(-23), 1.5, "bar", *dec, ts},
+	}
+	buff, err := info.Serialize(nil)
+	require.NoError(t, err)
+	info2 := &QueryExecutionInfo{}
+	err = info2.Deserialize(buff)
+	require.NoError(t, err)
+	require.Equal(t, info, info2)
+}
+
+func TestSerializeDeserializeQueryExecutionInfoNoArgs(t *testing.T) {
+	info := &QueryExecutionInfo{
+		ExecutionID: "1-23",
+		SchemaName:  "test",
+		Query:       "select * from foo",
+		Limit:       1000,
+		ShardID:     12,
+	}
+	buff, err := info.Serialize(nil)
+	require.NoError(t, err)
+	info2 := &QueryExecutionInfo{}
+	err = info2.Deserialize(buff)
+	require.NoError(t, err)
+	require.Equal(t, info, info2)
+}
@@ -206,7 +206,9 @@ func (d *Dragon) ExecuteRemotePullQuery(queryInfo *cluster.QueryExecutionInfo, r
 	}
 
 	rows := rowsFactory.NewRows(1)
-	rows.Deserialize(bytes[1:])
+	if len(bytes) > 1 {
+		rows.Deserialize(bytes[1:])
+	}
 	return rows, nil
 }
 
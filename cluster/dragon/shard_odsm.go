@@ -308,7 +308,11 @@ func (s *ShardOnDiskStateMachine) Lookup(i interface{}) (interface{}, error) {
 			// for an unrecoverable error.
 			return buff, nil
 		}
-		b := rows.Serialize()
+		var b []byte
+		if rows != nil {
+			// There are no rows if the query was closed
+			b = rows.Serialize()
+		}
 		buff := make([]byte, 0, 1+len(b))
 		buff = append(buff, 1) // 1 signifies no error
 		buff = append(buff, b...)
@@ -0,0 +1,37 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/squareup/pranadb/client"
+	"github.com/squareup/pranadb/errors"
+	"github.com/squareup/pranadb/protos/squareup/cash/pranadb/v1/service"
+)
+
+type UploadAvroCommand struct {
+	ID       int32  `arg:"" help:"The id of the schema, as given in messages encoded with it"`
+	FilePath string `arg:"" type:"existingfile" help:"The Avro schema file to upload"`
+}
+
+func (cmd *UploadAvroCommand) Run(cl *client.Client) error {
+	data, err := os.ReadFile(cmd.FilePath)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	fmt.Printf("Registering Avro schema %s with id %d\n\n", cmd.FilePath, cmd.ID)
+	schema := &service.AvroSchema{Id: cmd.ID, Schema: string(data)}
+	if err := cl.RegisterAvroSchemas(context.Background(), &service.RegisterAvroSchemasRequest{Schemas: []*service.AvroSchema{schema}}); err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+func (cmd *UploadAvroCommand) Help() string {
+	return `
+Prana decodes Avro messages in the schema registry wire format, which gives the
+id of the schema the message was encoded with. Schemas which aren't uploaded
+are fetched from the schema registry configured with avro-schema-registry-url.
+`
+}
@@ -14,6 +14,7 @@ import (
 var CLI struct {
 	Shell       commands.ShellCommand       `cmd:"" help:"Start a SQL shell for Prana"`
 	UploadProto commands.UploadProtoCommand `cmd:"" help:"Upload a protobuf file descriptor set that can be used by Prana to decode sources"`
+	UploadAvro  commands.UploadAvroCommand  `cmd:"" help:"Upload an Avro schema that can be used by Prana to decode sources"`
 	Addr        string                      `help:"Address of PranaDB server to connect to." default:"127.0.0.1:6584"`
 }
 
@@ -87,5 +87,7 @@ func createConfigWithAllFields() conf.Config {
 		RaftRTTMs:                   100,
 		RaftElectionRTT:             300,
 		RaftHeartbeatRTT:            30,
+		SortMemoryBudgetMB:          16,
+		AvroSchemaRegistryURL:       "http://localhost:8081",
 	}
 }
@@ -58,3 +58,5 @@ global-ingest-limit-rows-per-sec = 5000
 raft-rtt-ms                       = 100
 raft-heartbeat-rtt                = 30
 raft-election-rtt                 = 300
+sort-memory-budget-mb             = 16
+avro-schema-registry-url          = "http://localhost:8081"
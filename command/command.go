@@ -15,6 +15,7 @@ import (
 	"github.com/squareup/pranadb/execctx"
 	"github.com/squareup/pranadb/meta"
 	"github.com/squareup/pranadb/protolib"
+	"github.com/squareup/pranadb/protos/squareup/cash/pranadb/v1/notifications"
 	"github.com/squareup/pranadb/pull"
 	"github.com/squareup/pranadb/pull/exec"
 	"github.com/squareup/pranadb/push"
@@ -52,6 +53,13 @@ func NewCommandExecutor(metaController *meta.Controller, pushEngine *push.Engine
 }
 
 func (e *Executor) HandleMessage(notification remoting.ClusterMessage) (remoting.ClusterMessage, error) {
+	if dml, ok := notification.(*notifications.DMLStatementInfo); ok {
+		resp, err := e.handleDMLStatement(dml)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		return resp, nil
+	}
 	return nil, e.ddlRunner.HandleNotification(notification)
 }
 
@@ -82,7 +90,7 @@ func (e *Executor) ExecuteSQLStatement(execCtx *execctx.ExecutionContext, sql st
 		dag, err := e.pullEngine.BuildPullQuery(execCtx, sql)
 		return dag, errors.WithStack(err)
 	case ast.Create != nil && ast.Create.Source != nil:
-		sequences, err := e.generateTableIDSequences(1)
+		sequences, err := e.generateTableIDSequences(numSourceTables(ast.Create.Source))
 		if err != nil {
 			return nil, errors.WithStack(err)
 		}
@@ -93,7 +101,13 @@ func (e *Executor) ExecuteSQLStatement(execCtx *execctx.ExecutionContext, sql st
 		}
 		return exec.Empty, nil
 	case ast.Create != nil && ast.Create.MaterializedView != nil:
-		sequences, err := e.generateTableIDSequences(3)
+		// We need one table id for the materialized view itself, plus one for each internal table
+		execCtx.Planner().RefreshInfoSchema()
+		numInternalTables, err := push.NumInternalTables(execCtx.Planner(), execCtx.Schema, ast.Create.MaterializedView.Query.String())
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		sequences, err := e.generateTableIDSequences(1 + numInternalTables)
 		if err != nil {
 			return nil, errors.WithStack(err)
 		}
@@ -114,6 +128,28 @@ func (e *Executor) ExecuteSQLStatement(execCtx *execctx.ExecutionContext, sql st
 			return nil, errors.WithStack(err)
 		}
 		return exec.Empty, nil
+	case ast.Create != nil && ast.Create.Sink != nil:
+		sequences, err := e.generateTableIDSequences(1)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		command := NewOriginatingCreateSinkCommand(e, execCtx.Schema.Name, sql, sequences, ast.Create.Sink)
+		err = e.ddlRunner.RunCommand(command)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		return exec.Empty, nil
+	case ast.Create != nil && ast.Create.Table != nil:
+		sequences, err := e.generateTableIDSequences(1)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		command := NewOriginatingCreateTableCommand(e, execCtx.Schema.Name, sql, sequences, ast.Create.Table)
+		err = e.ddlRunner.RunCommand(command)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		return exec.Empty, nil
 	case ast.Drop != nil && ast.Drop.Source:
 		command := NewOriginatingDropSourceCommand(e, execCtx.Schema.Name, sql, ast.Drop.Name)
 		err = e.ddlRunner.RunCommand(command)
@@ -135,6 +171,26 @@ func (e *Executor) ExecuteSQLStatement(execCtx *execctx.ExecutionContext, sql st
 			return nil, errors.WithStack(err)
 		}
 		return exec.Empty, nil
+	case ast.Drop != nil && ast.Drop.Sink:
+		command := NewOriginatingDropSinkCommand(e, execCtx.Schema.Name, sql, ast.Drop.Name)
+		err = e.ddlRunner.RunCommand(command)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		return exec.Empty, nil
+	case ast.Drop != nil && ast.Drop.Table:
+		command := NewOriginatingDropTableCommand(e, execCtx.Schema.Name, sql, ast.Drop.Name)
+		err = e.ddlRunner.RunCommand(command)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		return exec.Empty, nil
+	case ast.Insert != nil:
+		rows, err := e.execInsert(execCtx, ast.Insert)
+		return rows, errors.WithStack(err)
+	case ast.Update != nil, ast.Delete != nil:
+		rows, err := e.execDML(execCtx, sql, ast)
+		return rows, errors.WithStack(err)
 	case ast.Show != nil && ast.Show.Tables != "":
 		rows, err := e.execShowTables(execCtx)
 		if err != nil {
@@ -147,6 +203,16 @@ func (e *Executor) ExecuteSQLStatement(execCtx *execctx.ExecutionContext, sql st
 			return nil, errors.WithStack(err)
 		}
 		return rows, nil
+	case ast.Explain != nil && ast.Explain.Select != "":
+		execCtx.Planner().RefreshInfoSchema()
+		rows, err := e.pullEngine.ExplainQuery(execCtx, ast.Explain.Select, ast.Explain.Analyze)
+		return rows, errors.WithStack(err)
+	case ast.Explain != nil && ast.Explain.MaterializedView != "":
+		rows, err := e.execExplainMaterializedView(execCtx, ast.Explain.MaterializedView)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		return rows, nil
 	case ast.Describe != "":
 		rows, err := e.execDescribe(execCtx, ast.Describe)
 		if err != nil {
@@ -157,10 +223,38 @@ func (e *Executor) ExecuteSQLStatement(execCtx *execctx.ExecutionContext, sql st
 	return nil, errors.Errorf("invalid statement %s", sql)
 }
 
+// PrepareSQLStatement prepares a statement with param markers (?) so it can be executed many times with different
+// args. Only queries can be prepared.
+func (e *Executor) PrepareSQLStatement(schema *common.Schema, sql string) (*pull.PreparedQuery, error) {
+	ast, err := parser.Parse(sql)
+	if err != nil {
+		var perr participle.Error
+		if errors.As(err, &perr) {
+			return nil, errors.NewInvalidStatementError(err.Error())
+		}
+		return nil, errors.WithStack(err)
+	}
+	if ast.Select == "" {
+		return nil, errors.NewInvalidStatementError("only queries can be prepared")
+	}
+	pq, err := e.pullEngine.PrepareQuery(schema, sql)
+	return pq, errors.WithStack(err)
+}
+
+// ExecutePreparedStatement executes a prepared statement with the args. The statement must not be executed
+// concurrently.
+func (e *Executor) ExecutePreparedStatement(pq *pull.PreparedQuery, args []interface{}) (exec.PullExecutor, error) {
+	dag, err := e.pullEngine.BuildPreparedQuery(pq, e.nextExecutionID(), args)
+	return dag, errors.WithStack(err)
+}
+
 func (e *Executor) CreateExecutionContext(schema *common.Schema) *execctx.ExecutionContext {
+	return execctx.NewExecutionContext(e.nextExecutionID(), schema)
+}
+
+func (e *Executor) nextExecutionID() string {
 	seq := atomic.AddInt64(&e.execCtxIDSequence, 1)
-	ctxID := fmt.Sprintf("%d-%d", e.cluster.GetNodeID(), seq)
-	return execctx.NewExecutionContext(ctxID, schema)
+	return fmt.Sprintf("%d-%d", e.cluster.GetNodeID(), seq)
 }
 
 // GetPushEngine is only used in testing
@@ -207,6 +301,23 @@ func (e *Executor) execShowSchemas() (exec.PullExecutor, error) {
 	return staticRows, errors.WithStack(err)
 }
 
+func (e *Executor) execExplainMaterializedView(execCtx *execctx.ExecutionContext, mvName string) (exec.PullExecutor, error) {
+	table, ok := execCtx.Schema.GetTable(mvName)
+	if !ok {
+		return nil, errors.NewUnknownMaterializedViewError(execCtx.Schema.Name, mvName)
+	}
+	mvInfo, ok := table.(*common.MaterializedViewInfo)
+	if !ok {
+		return nil, errors.NewUnknownMaterializedViewError(execCtx.Schema.Name, mvName)
+	}
+	mv, err := e.pushEngine.GetMaterializedView(mvInfo.ID)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	staticRows, err := exec.NewStaticRows(push.ExplainColNames, mv.Explain())
+	return staticRows, errors.WithStack(err)
+}
+
 var describeRowsFactory = common.NewRowsFactory(
 	[]common.ColumnType{
 		{Type: common.TypeVarchar}, // field
@@ -251,6 +362,8 @@ func (e *Executor) execDescribe(execCtx *execctx.ExecutionContext, tableName str
 	switch kind {
 	case meta.TableKindSource:
 		tableInfo = meta.DecodeSourceInfoRow(&tableRow).TableInfo
+	case meta.TableKindUserTable:
+		tableInfo = meta.DecodeUserTableInfoRow(&tableRow).TableInfo
 	case meta.TableKindMaterializedView:
 		tableInfo = meta.DecodeMaterializedViewInfoRow(&tableRow).TableInfo
 	case meta.TableKindInternal:
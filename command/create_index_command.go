@@ -147,9 +147,12 @@ func (c *CreateIndexCommand) getIndexInfo(ast *parser.CreateIndex) (*common.Inde
 	tab, ok := c.e.metaController.GetSource(c.SchemaName(), ast.TableName)
 	if !ok {
 		tab, ok = c.e.metaController.GetMaterializedView(c.SchemaName(), ast.TableName)
-		if !ok {
-			return nil, errors.NewUnknownSourceOrMaterializedViewError(c.SchemaName(), ast.TableName)
-		}
+	}
+	if !ok {
+		tab, ok = c.e.metaController.GetUserTable(c.SchemaName(), ast.TableName)
+	}
+	if !ok {
+		return nil, errors.NewUnknownSourceOrMaterializedViewError(c.SchemaName(), ast.TableName)
 	}
 	tabInfo := tab.GetTableInfo()
 
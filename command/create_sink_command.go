@@ -0,0 +1,214 @@
+package command
+
+import (
+	"sync"
+
+	"github.com/squareup/pranadb/cluster"
+	"github.com/squareup/pranadb/command/parser"
+	"github.com/squareup/pranadb/common"
+	"github.com/squareup/pranadb/errors"
+	"github.com/squareup/pranadb/kafka"
+)
+
+type CreateSinkCommand struct {
+	lock           sync.Mutex
+	e              *Executor
+	schemaName     string
+	sql            string
+	tableSequences []uint64
+	ast            *parser.CreateSink
+	sinkInfo       *common.SinkInfo
+	toDeleteBatch  *cluster.ToDeleteBatch
+}
+
+func (c *CreateSinkCommand) CommandType() DDLCommandType {
+	return DDLCommandTypeCreateSink
+}
+
+func (c *CreateSinkCommand) SchemaName() string {
+	return c.schemaName
+}
+
+func (c *CreateSinkCommand) SQL() string {
+	return c.sql
+}
+
+func (c *CreateSinkCommand) TableSequences() []uint64 {
+	return c.tableSequences
+}
+
+func (c *CreateSinkCommand) LockName() string {
+	return c.schemaName + "/"
+}
+
+func NewOriginatingCreateSinkCommand(e *Executor, schemaName string, sql string, tableSequences []uint64, ast *parser.CreateSink) *CreateSinkCommand {
+	return &CreateSinkCommand{
+		e:              e,
+		schemaName:     schemaName,
+		sql:            sql,
+		tableSequences: tableSequences,
+		ast:            ast,
+	}
+}
+
+func NewCreateSinkCommand(e *Executor, schemaName string, sql string, tableSequences []uint64) *CreateSinkCommand {
+	return &CreateSinkCommand{
+		e:              e,
+		schemaName:     schemaName,
+		sql:            sql,
+		tableSequences: tableSequences,
+	}
+}
+
+func (c *CreateSinkCommand) Before() error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	var err error
+	c.sinkInfo, err = c.getSinkInfo(c.ast)
+	return errors.WithStack(err)
+}
+
+func (c *CreateSinkCommand) OnPhase(phase int32) error {
+	switch phase {
+	case 0:
+		return c.onPhase0()
+	case 1:
+		return c.onPhase1()
+	default:
+		panic("invalid phase")
+	}
+}
+
+func (c *CreateSinkCommand) NumPhases() int {
+	return 2
+}
+
+func (c *CreateSinkCommand) onPhase0() error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.sinkInfo == nil {
+		ast, err := parser.Parse(c.sql)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		if ast.Create == nil || ast.Create.Sink == nil {
+			return errors.Errorf("not a create sink %s", c.sql)
+		}
+		c.sinkInfo, err = c.getSinkInfo(ast.Create.Sink)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+
+	// We store rows in the to_delete table - if sink creation fails (e.g. node crashes) then on restart any messages
+	// in its outbox will be cleaned up
+	var err error
+	c.toDeleteBatch, err = storeToDeleteBatch(c.sinkInfo.ID, c.e.cluster)
+	if err != nil {
+		return err
+	}
+
+	// We create the sink, which sends the current contents of the materialized view, but we don't register it yet
+	return c.e.pushEngine.CreateSink(c.sinkInfo, true)
+}
+
+func (c *CreateSinkCommand) onPhase1() error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	// We can now remove from the to_delete table
+	if err := c.e.cluster.RemoveToDeleteBatch(c.toDeleteBatch); err != nil {
+		return err
+	}
+
+	return c.e.metaController.RegisterSink(c.sinkInfo)
+}
+
+func (c *CreateSinkCommand) AfterPhase(phase int32) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if phase == 0 {
+		// We persist the sink after it's been filled but *before* it's been registered
+		return c.e.metaController.PersistSink(c.sinkInfo)
+	}
+	return nil
+}
+
+func (c *CreateSinkCommand) getSinkInfo(ast *parser.CreateSink) (*common.SinkInfo, error) {
+	if _, ok := c.e.metaController.GetSink(c.schemaName, ast.Name); ok {
+		return nil, errors.NewSinkAlreadyExistsError(c.schemaName, ast.Name)
+	}
+	if schema, ok := c.e.metaController.GetSchema(c.schemaName); ok {
+		if _, ok := schema.GetTable(ast.Name); ok {
+			return nil, errors.NewPranaErrorf(errors.InvalidStatement,
+				"Cannot create sink %s.%s, a table with the same name already exists", c.schemaName, ast.Name)
+		}
+	}
+	mvInfo, ok := c.e.metaController.GetMaterializedView(c.schemaName, ast.MaterializedViewName)
+	if !ok {
+		return nil, errors.NewUnknownMaterializedViewError(c.schemaName, ast.MaterializedViewName)
+	}
+	var (
+		keyEncoding, valueEncoding common.KafkaEncoding
+		propsMap                   map[string]string
+		brokerName, topicName      string
+	)
+	for _, opt := range ast.TopicInformation {
+		switch {
+		case opt.KeyEncoding != "":
+			keyEncoding = common.KafkaEncodingFromString(opt.KeyEncoding)
+			if keyEncoding.Encoding == common.EncodingUnknown {
+				return nil, errors.NewPranaErrorf(errors.UnknownTopicEncoding, "Unknown topic encoding %s", opt.KeyEncoding)
+			}
+		case opt.ValueEncoding != "":
+			valueEncoding = common.KafkaEncodingFromString(opt.ValueEncoding)
+			if valueEncoding.Encoding == common.EncodingUnknown {
+				return nil, errors.NewPranaErrorf(errors.UnknownTopicEncoding, "Unknown topic encoding %s", opt.ValueEncoding)
+			}
+		case opt.Properties != nil:
+			propsMap = make(map[string]string, len(opt.Properties))
+			for _, prop := range opt.Properties {
+				propsMap[prop.Key] = prop.Value
+			}
+		case opt.BrokerName != "":
+			brokerName = opt.BrokerName
+		case opt.TopicName != "":
+			topicName = opt.TopicName
+		default:
+			return nil, errors.NewPranaError(errors.InvalidStatement,
+				"Only BrokerName, TopicName, KeyEncoding, ValueEncoding and Properties can be specified for a sink")
+		}
+	}
+	if keyEncoding == common.KafkaEncodingUnknown {
+		return nil, errors.NewPranaError(errors.InvalidStatement, "keyEncoding is required")
+	}
+	if valueEncoding == common.KafkaEncodingUnknown {
+		return nil, errors.NewPranaError(errors.InvalidStatement, "valueEncoding is required")
+	}
+	if brokerName == "" {
+		return nil, errors.NewPranaError(errors.InvalidStatement, "brokerName is required")
+	}
+	if topicName == "" {
+		return nil, errors.NewPranaError(errors.InvalidStatement, "topicName is required")
+	}
+	// Check the materialized view can be encoded with the encodings
+	tableInfo := mvInfo.TableInfo
+	if _, err := kafka.NewSinkMessageEncoder(keyEncoding, valueEncoding, tableInfo.ColumnTypes,
+		tableInfo.PrimaryKeyCols); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return &common.SinkInfo{
+		ID:                   c.tableSequences[0],
+		SchemaName:           c.schemaName,
+		Name:                 ast.Name,
+		MaterializedViewName: ast.MaterializedViewName,
+		TopicInfo: &common.TopicInfo{
+			BrokerName:    brokerName,
+			TopicName:     topicName,
+			KeyEncoding:   keyEncoding,
+			ValueEncoding: valueEncoding,
+			Properties:    propsMap,
+		},
+	}, nil
+}
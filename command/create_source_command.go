@@ -3,6 +3,7 @@ package command
 import (
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/alecthomas/repr"
 	"github.com/squareup/pranadb/command/parser"
@@ -14,6 +15,12 @@ import (
 	"google.golang.org/protobuf/reflect/protoreflect"
 )
 
+// The names of the hidden columns which key the rows of a source without a primary key
+const (
+	appendOnlyPartitionColName = "__gen_hid_partition"
+	appendOnlyOffsetColName    = "__gen_hid_offset"
+)
+
 type CreateSourceCommand struct {
 	lock           sync.Mutex
 	e              *Executor
@@ -107,13 +114,13 @@ func (c *CreateSourceCommand) validate() error {
 		}
 		if sel.MetaKey != nil {
 			f := *sel.MetaKey
-			if !(f == "header" || f == "key" || f == "timestamp") {
-				return errors.NewPranaErrorf(errors.InvalidSelector, `invalid metadata key in column selector %q. Valid values are "header", "key", "timestamp".`, sel)
+			if !(f == "header" || f == "key" || f == "value" || f == "timestamp") {
+				return errors.NewPranaErrorf(errors.InvalidSelector, `invalid metadata key in column selector %q. Valid values are "header", "key", "value", "timestamp".`, sel)
 			}
 		}
 	}
 
-	return nil
+	return source.ValidateTopicInfo(topicInfo, c.sourceInfo.ColumnTypes)
 }
 
 func (c *CreateSourceCommand) OnPhase(phase int32) error {
@@ -187,37 +194,9 @@ func (c *CreateSourceCommand) AfterPhase(phase int32) error {
 
 // nolint: gocyclo
 func (c *CreateSourceCommand) getSourceInfo(ast *parser.CreateSource) (*common.SourceInfo, error) {
-	var (
-		colNames []string
-		colTypes []common.ColumnType
-		colIndex = map[string]int{}
-		pkCols   []int
-	)
-	for i, option := range ast.Options {
-		switch {
-		case option.Column != nil:
-			// Convert AST column definition to a ColumnType.
-			col := option.Column
-			colIndex[col.Name] = i
-			colNames = append(colNames, col.Name)
-			colType, err := col.ToColumnType()
-			if err != nil {
-				return nil, errors.WithStack(err)
-			}
-			colTypes = append(colTypes, colType)
-
-		case len(option.PrimaryKey) > 0:
-			for _, pk := range option.PrimaryKey {
-				index, ok := colIndex[pk]
-				if !ok {
-					return nil, errors.Errorf("invalid primary key column %q", option.PrimaryKey)
-				}
-				pkCols = append(pkCols, index)
-			}
-
-		default:
-			panic(repr.String(option))
-		}
+	colNames, colTypes, colIndex, pkCols, err := getColumns(ast.Options)
+	if err != nil {
+		return nil, errors.WithStack(err)
 	}
 
 	var (
@@ -225,6 +204,7 @@ func (c *CreateSourceCommand) getSourceInfo(ast *parser.CreateSource) (*common.S
 		propsMap                                   map[string]string
 		colSelectors                               []selector.ColumnSelector
 		brokerName, topicName                      string
+		watermarkColumn, maxOutOfOrderness         string
 	)
 	for _, opt := range ast.TopicInformation {
 		switch {
@@ -258,6 +238,10 @@ func (c *CreateSourceCommand) getSourceInfo(ast *parser.CreateSource) (*common.S
 			brokerName = opt.BrokerName
 		case opt.TopicName != "":
 			topicName = opt.TopicName
+		case opt.WatermarkColumn != "":
+			watermarkColumn = opt.WatermarkColumn
+		case opt.MaxOutOfOrderness != "":
+			maxOutOfOrderness = opt.MaxOutOfOrderness
 		}
 	}
 	if headerEncoding == common.KafkaEncodingUnknown {
@@ -281,6 +265,11 @@ func (c *CreateSourceCommand) getSourceInfo(ast *parser.CreateSource) (*common.S
 			"Number of column selectors (%d) must match number of columns (%d)", lc, len(colTypes))
 	}
 
+	watermark, err := c.getWatermarkInfo(watermarkColumn, maxOutOfOrderness, colIndex, colTypes)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
 	topicInfo := &common.TopicInfo{
 		BrokerName:     brokerName,
 		TopicName:      topicName,
@@ -289,6 +278,21 @@ func (c *CreateSourceCommand) getSourceInfo(ast *parser.CreateSource) (*common.S
 		ValueEncoding:  valueEncoding,
 		ColSelectors:   colSelectors,
 		Properties:     propsMap,
+		Watermark:      watermark,
+	}
+	var colsVisible []bool
+	appendOnly := len(pkCols) == 0
+	if appendOnly {
+		// The source has no primary key, so we key its rows by the partition and offset of their messages, which we
+		// add as hidden columns
+		colsVisible = make([]bool, len(colTypes), len(colTypes)+2)
+		for i := range colsVisible {
+			colsVisible[i] = true
+		}
+		pkCols = []int{len(colTypes), len(colTypes) + 1}
+		colNames = append(colNames, appendOnlyPartitionColName, appendOnlyOffsetColName)
+		colTypes = append(colTypes, common.BigIntColumnType, common.BigIntColumnType)
+		colsVisible = append(colsVisible, false, false)
 	}
 	tableInfo := common.TableInfo{
 		ID:             c.tableSequences[0],
@@ -298,9 +302,89 @@ func (c *CreateSourceCommand) getSourceInfo(ast *parser.CreateSource) (*common.S
 		ColumnNames:    colNames,
 		ColumnTypes:    colTypes,
 		IndexInfos:     nil,
+		ColsVisible:    colsVisible,
+		AppendOnly:     appendOnly,
 	}
 	return &common.SourceInfo{
 		TableInfo: &tableInfo,
 		TopicInfo: topicInfo,
 	}, nil
 }
+
+func (c *CreateSourceCommand) getWatermarkInfo(watermarkColumn string, maxOutOfOrderness string, colIndex map[string]int,
+	colTypes []common.ColumnType) (*common.WatermarkInfo, error) {
+	if watermarkColumn == "" {
+		if maxOutOfOrderness != "" {
+			return nil, errors.NewPranaError(errors.InvalidStatement, "maxOutOfOrderness requires watermarkColumn")
+		}
+		return nil, nil
+	}
+	timeCol, ok := colIndex[watermarkColumn]
+	if !ok {
+		return nil, errors.NewPranaErrorf(errors.InvalidStatement, "Unknown watermark column %s", watermarkColumn)
+	}
+	if colTypes[timeCol].Type != common.TypeTimestamp {
+		return nil, errors.NewPranaErrorf(errors.InvalidStatement, "Watermark column %s must be a timestamp", watermarkColumn)
+	}
+	var delay time.Duration
+	if maxOutOfOrderness != "" {
+		var err error
+		delay, err = time.ParseDuration(maxOutOfOrderness)
+		if err != nil || delay < 0 {
+			return nil, errors.NewPranaErrorf(errors.InvalidStatement, "Invalid maxOutOfOrderness %s", maxOutOfOrderness)
+		}
+	}
+	return &common.WatermarkInfo{
+		TableID:           c.tableSequences[1],
+		TimeCol:           timeCol,
+		MaxOutOfOrderness: delay,
+	}, nil
+}
+
+// getColumns returns the columns and primary key defined by the options of a create source or create table statement
+func getColumns(options []*parser.TableOption) ([]string, []common.ColumnType, map[string]int, []int, error) {
+	var (
+		colNames []string
+		colTypes []common.ColumnType
+		colIndex = map[string]int{}
+		pkCols   []int
+	)
+	for i, option := range options {
+		switch {
+		case option.Column != nil:
+			// Convert AST column definition to a ColumnType.
+			col := option.Column
+			colIndex[col.Name] = i
+			colNames = append(colNames, col.Name)
+			colType, err := col.ToColumnType()
+			if err != nil {
+				return nil, nil, nil, nil, errors.WithStack(err)
+			}
+			colTypes = append(colTypes, colType)
+
+		case len(option.PrimaryKey) > 0:
+			for _, pk := range option.PrimaryKey {
+				index, ok := colIndex[pk]
+				if !ok {
+					return nil, nil, nil, nil, errors.Errorf("invalid primary key column %q", option.PrimaryKey)
+				}
+				pkCols = append(pkCols, index)
+			}
+
+		default:
+			panic(repr.String(option))
+		}
+	}
+	return colNames, colTypes, colIndex, pkCols, nil
+}
+
+// numSourceTables returns the number of table ids needed by a source. A source with a watermark needs one for the table
+// which stores the watermarks of its partitions.
+func numSourceTables(ast *parser.CreateSource) int {
+	for _, opt := range ast.TopicInformation {
+		if opt.WatermarkColumn != "" {
+			return 2
+		}
+	}
+	return 1
+}
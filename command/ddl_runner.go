@@ -50,6 +50,10 @@ const (
 	DDLCommandTypeDropMV
 	DDLCommandTypeCreateIndex
 	DDLCommandTypeDropIndex
+	DDLCommandTypeCreateSink
+	DDLCommandTypeDropSink
+	DDLCommandTypeCreateTable
+	DDLCommandTypeDropTable
 )
 
 func NewDDLCommandRunner(ce *Executor) *DDLCommandRunner {
@@ -74,6 +78,14 @@ func NewDDLCommand(e *Executor, commandType DDLCommandType, schemaName string, s
 		return NewCreateIndexCommand(e, schemaName, sql, tableSequences)
 	case DDLCommandTypeDropIndex:
 		return NewDropIndexCommand(e, schemaName, sql)
+	case DDLCommandTypeCreateSink:
+		return NewCreateSinkCommand(e, schemaName, sql, tableSequences)
+	case DDLCommandTypeDropSink:
+		return NewDropSinkCommand(e, schemaName, sql)
+	case DDLCommandTypeCreateTable:
+		return NewCreateTableCommand(e, schemaName, sql, tableSequences)
+	case DDLCommandTypeDropTable:
+		return NewDropTableCommand(e, schemaName, sql)
 	default:
 		panic("invalid ddl command")
 	}
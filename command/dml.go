@@ -0,0 +1,307 @@
+package command
+
+import (
+	"github.com/squareup/pranadb/command/parser"
+	"github.com/squareup/pranadb/common"
+	"github.com/squareup/pranadb/errors"
+	"github.com/squareup/pranadb/execctx"
+	"github.com/squareup/pranadb/protos/squareup/cash/pranadb/v1/notifications"
+	"github.com/squareup/pranadb/pull/exec"
+	pushexec "github.com/squareup/pranadb/push/exec"
+	"github.com/squareup/pranadb/push/source"
+)
+
+// execInsert writes the rows of an insert statement to a table. A row replaces any existing row with the same primary
+// key.
+func (e *Executor) execInsert(execCtx *execctx.ExecutionContext, ast *parser.Insert) (exec.PullExecutor, error) {
+	tableInfo, err := e.getUserTable(execCtx, ast.TableName)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	// The index of the value of each column in the rows of values, or -1 if there is no value for the column
+	valueIndexes := make([]int, len(tableInfo.ColumnNames))
+	if ast.ColumnNames == nil {
+		for i := range valueIndexes {
+			valueIndexes[i] = i
+		}
+	} else {
+		for i := range valueIndexes {
+			valueIndexes[i] = -1
+		}
+		for i, colName := range ast.ColumnNames {
+			colIndex, err := getColumnIndex(tableInfo, colName)
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+			if valueIndexes[colIndex] != -1 {
+				return nil, errors.NewPranaErrorf(errors.InvalidStatement, "Column %s is specified more than once", colName)
+			}
+			valueIndexes[colIndex] = i
+		}
+	}
+	numValues := len(valueIndexes)
+	if ast.ColumnNames != nil {
+		numValues = len(ast.ColumnNames)
+	}
+	rows := common.NewRowsFactory(tableInfo.ColumnTypes).NewRows(len(ast.Rows))
+	for _, values := range ast.Rows {
+		if len(values.Values) != numValues {
+			return nil, errors.NewPranaErrorf(errors.InvalidStatement, "Expected %d values in row but got %d",
+				numValues, len(values.Values))
+		}
+		for colIndex, valueIndex := range valueIndexes {
+			var value *parser.Value
+			if valueIndex != -1 {
+				value = values.Values[valueIndex]
+			}
+			if err := appendValue(tableInfo, colIndex, value, rows); err != nil {
+				return nil, errors.WithStack(err)
+			}
+		}
+	}
+	ut, err := e.pushEngine.GetUserTable(tableInfo.ID)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if err := ut.Upsert(rows); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return exec.Empty, nil
+}
+
+// execDML executes an update or delete statement. The statement is checked on this node, then sent to every node,
+// which changes the rows on the shards it processes, see handleDMLStatement. The nodes return the shards they changed.
+// If a shard moved between nodes while the statement was executing, it may have been changed by neither node or by
+// both of them, so the statement fails and must be executed again.
+func (e *Executor) execDML(execCtx *execctx.ExecutionContext, sql string, ast *parser.AST) (exec.PullExecutor, error) {
+	if _, err := e.newRowChanger(execCtx.Schema, sql, ast); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	// The where clause is planned here too, so an invalid statement fails before it's sent to the other nodes
+	checkCtx := execctx.NewExecutionContext("", execCtx.Schema)
+	defer checkCtx.Close()
+	if _, err := e.pullEngine.BuildMatchedRowsQuery(checkCtx, sql, e.cluster.GetAllShardIDs()[0]); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	resps, err := e.notifClient.BroadcastSyncWithResponses(&notifications.DMLStatementInfo{
+		SchemaName: execCtx.Schema.Name,
+		Sql:        sql,
+	})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	changeCounts := make(map[uint64]int)
+	for _, resp := range resps {
+		dmlResp, ok := resp.(*notifications.DMLStatementResponse)
+		if !ok {
+			return nil, errors.Errorf("unexpected response %T", resp)
+		}
+		for _, shardID := range dmlResp.ShardIds {
+			changeCounts[shardID]++
+		}
+	}
+	for _, shardID := range e.cluster.GetAllShardIDs() {
+		if changeCounts[shardID] != 1 {
+			return nil, errors.NewStatementNotAppliedToAllShardsError()
+		}
+	}
+	return exec.Empty, nil
+}
+
+// handleDMLStatement changes the rows of the shards this node processes for an update or delete statement, and returns
+// the ids of those shards
+func (e *Executor) handleDMLStatement(info *notifications.DMLStatementInfo) (*notifications.DMLStatementResponse, error) {
+	schema, ok := e.metaController.GetSchema(info.SchemaName)
+	if !ok {
+		return nil, errors.Errorf("no such schema %s", info.SchemaName)
+	}
+	ast, err := parser.Parse(info.Sql)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	changer, err := e.newRowChanger(schema, info.Sql, ast)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	ut, err := e.pushEngine.GetUserTable(changer.tableInfo.ID)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	shardIDs, err := ut.ChangeRows(changer.changes)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return &notifications.DMLStatementResponse{ShardIds: shardIDs}, nil
+}
+
+// rowChanger makes the changes of an update or delete statement to the rows of a shard. The rows the statement matches
+// are read and changed on the scheduler of the shard, so a concurrent write to a row is never lost.
+type rowChanger struct {
+	e         *Executor
+	schema    *common.Schema
+	sql       string
+	tableInfo *common.UserTableInfo
+	// The values an update statement sets the columns to, by column index. Nil for a delete statement.
+	assignments map[int]*parser.Value
+}
+
+func (e *Executor) newRowChanger(schema *common.Schema, sql string, ast *parser.AST) (*rowChanger, error) {
+	var tableName string
+	if ast.Update != nil {
+		tableName = ast.Update.TableName
+	} else {
+		tableName = ast.Delete.TableName
+	}
+	tableInfo, ok := e.metaController.GetUserTable(schema.Name, tableName)
+	if !ok {
+		return nil, errors.NewUnknownTableError(schema.Name, tableName)
+	}
+	changer := &rowChanger{e: e, schema: schema, sql: sql, tableInfo: tableInfo}
+	if ast.Update == nil {
+		return changer, nil
+	}
+	changer.assignments = make(map[int]*parser.Value, len(ast.Update.Assignments))
+	// The values are checked against the types of the columns before any rows are changed
+	checkRows := common.NewRowsFactory(tableInfo.ColumnTypes).NewRows(1)
+	for _, assignment := range ast.Update.Assignments {
+		colIndex, err := getColumnIndex(tableInfo, assignment.ColumnName)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		if tableInfo.IsPrimaryKeyCol(colIndex) {
+			return nil, errors.NewPranaErrorf(errors.InvalidStatement, "Cannot update primary key column %s",
+				assignment.ColumnName)
+		}
+		if _, ok := changer.assignments[colIndex]; ok {
+			return nil, errors.NewPranaErrorf(errors.InvalidStatement, "Column %s is specified more than once",
+				assignment.ColumnName)
+		}
+		if err := appendValue(tableInfo, colIndex, assignment.Value, checkRows); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		changer.assignments[colIndex] = assignment.Value
+	}
+	return changer, nil
+}
+
+// changes returns the new values of the rows on the shard which the statement matches, or the rows to delete
+func (r *rowChanger) changes(shardID uint64) (pushexec.RowsBatch, error) {
+	matched, err := r.matchedRows(shardID)
+	if err != nil {
+		return pushexec.RowsBatch{}, errors.WithStack(err)
+	}
+	if r.assignments == nil {
+		entries := make([]pushexec.RowsEntry, matched.RowCount())
+		for i := range entries {
+			entries[i] = pushexec.NewRowsEntry(i, -1)
+		}
+		return pushexec.NewRowsBatch(matched, entries), nil
+	}
+	rows := common.NewRowsFactory(r.tableInfo.ColumnTypes).NewRows(matched.RowCount())
+	for i := 0; i < matched.RowCount(); i++ {
+		row := matched.GetRow(i)
+		for colIndex, colType := range r.tableInfo.ColumnTypes {
+			if value, ok := r.assignments[colIndex]; ok {
+				err = appendValue(r.tableInfo, colIndex, value, rows)
+			} else {
+				err = appendCol(&row, colIndex, colType, rows)
+			}
+			if err != nil {
+				return pushexec.RowsBatch{}, errors.WithStack(err)
+			}
+		}
+	}
+	return pushexec.NewCurrentRowsBatch(rows), nil
+}
+
+// matchedRows returns the rows on the shard which match the where clause of the statement, or all its rows if there
+// isn't one
+func (r *rowChanger) matchedRows(shardID uint64) (*common.Rows, error) {
+	execCtx := execctx.NewExecutionContext("", r.schema)
+	defer execCtx.Close()
+	query, err := r.e.pullEngine.BuildMatchedRowsQuery(execCtx, r.sql, shardID)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	limit := 1000
+	var rows *common.Rows
+	for {
+		page, err := query.GetRows(limit)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		if rows == nil {
+			rows = page
+		} else {
+			rows.AppendAll(page)
+		}
+		if page.RowCount() < limit {
+			return rows, nil
+		}
+	}
+}
+
+func (e *Executor) getUserTable(execCtx *execctx.ExecutionContext, tableName string) (*common.UserTableInfo, error) {
+	tableInfo, ok := e.metaController.GetUserTable(execCtx.Schema.Name, tableName)
+	if !ok {
+		return nil, errors.NewUnknownTableError(execCtx.Schema.Name, tableName)
+	}
+	return tableInfo, nil
+}
+
+func getColumnIndex(tableInfo *common.UserTableInfo, colName string) (int, error) {
+	for i, name := range tableInfo.ColumnNames {
+		if name == colName {
+			return i, nil
+		}
+	}
+	return 0, errors.NewPranaErrorf(errors.InvalidStatement, "Unknown column %s in table %s.%s", colName,
+		tableInfo.SchemaName, tableInfo.Name)
+}
+
+// appendValue appends the value, converted to the type of the column, to the rows. A nil value is null.
+func appendValue(tableInfo *common.UserTableInfo, colIndex int, value *parser.Value, rows *common.Rows) error {
+	var val interface{}
+	switch {
+	case value == nil || value.Null:
+		if tableInfo.IsPrimaryKeyCol(colIndex) {
+			return errors.NewPranaErrorf(errors.InvalidStatement, "Primary key column %s cannot be null",
+				tableInfo.ColumnNames[colIndex])
+		}
+		rows.AppendNullToColumn(colIndex)
+		return nil
+	case value.Number != nil:
+		val = *value.Number
+	default:
+		val = *value.String
+	}
+	if err := source.AppendCoercedValue(rows, colIndex, tableInfo.ColumnTypes[colIndex], val); err != nil {
+		return errors.NewPranaErrorf(errors.InvalidStatement, "Invalid value %v for column %s: %v", val,
+			tableInfo.ColumnNames[colIndex], err)
+	}
+	return nil
+}
+
+func appendCol(row *common.Row, colIndex int, colType common.ColumnType, rows *common.Rows) error {
+	if row.IsNull(colIndex) {
+		rows.AppendNullToColumn(colIndex)
+		return nil
+	}
+	switch colType.Type {
+	case common.TypeTinyInt, common.TypeInt, common.TypeBigInt, common.TypeBoolean:
+		rows.AppendInt64ToColumn(colIndex, row.GetInt64(colIndex))
+	case common.TypeDouble:
+		rows.AppendFloat64ToColumn(colIndex, row.GetFloat64(colIndex))
+	case common.TypeVarchar, common.TypeVarbinary:
+		rows.AppendStringToColumn(colIndex, row.GetString(colIndex))
+	case common.TypeDecimal:
+		rows.AppendDecimalToColumn(colIndex, row.GetDecimal(colIndex))
+	case common.TypeTimestamp, common.TypeDate:
+		rows.AppendTimestampToColumn(colIndex, row.GetTimestamp(colIndex))
+	case common.TypeJSON:
+		rows.AppendJSONToColumn(colIndex, row.GetJSON(colIndex))
+	default:
+		return errors.Errorf("unexpected column type %v", colType)
+	}
+	return nil
+}
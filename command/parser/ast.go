@@ -58,8 +58,8 @@ type ColumnDef struct {
 
 	Name string `@Ident`
 
-	Type       common.Type `@(("VARCHAR"|"TINYINT"|"INT"|"BIGINT"|"TIMESTAMP"|"DOUBLE"|"DECIMAL"))` // Conversion done by common.Type.Capture()
-	Parameters []int       `("(" @Number ("," @Number)* ")")?`                                      // Optional parameters to the type(x [, x, ...])
+	Type       common.Type `@(("VARCHAR"|"TINYINT"|"INT"|"BIGINT"|"TIMESTAMP"|"DOUBLE"|"DECIMAL"|"BOOLEAN"|"DATE"|"VARBINARY"|"JSON"))` // Conversion done by common.Type.Capture()
+	Parameters []int       `("(" @Number ("," @Number)* ")")?`                                                                          // Optional parameters to the type(x [, x, ...])
 }
 
 func (c *ColumnDef) ToColumnType() (common.ColumnType, error) {
@@ -107,6 +107,9 @@ type TopicInformation struct {
 	ValueEncoding  string                        `|"ValueEncoding" "=" @String`
 	ColSelectors   []*selector.ColumnSelectorAST `|"ColumnSelectors" "=" "(" (@@ ("," @@)*)? ")"`
 	Properties     []*TopicInfoProperty          `|"Properties" "=" "(" (@@ ("," @@)*)? ")"`
+	// The watermark of the source is given by its event time column and the maximum out-of-orderness of its messages
+	WatermarkColumn   string `|"WatermarkColumn" "=" @String`
+	MaxOutOfOrderness string `|"MaxOutOfOrderness" "=" @String`
 }
 
 type ColSelector struct {
@@ -118,6 +121,12 @@ type TopicInfoProperty struct {
 	Value string `@String`
 }
 
+// CreateTable statement. Unlike a source, the rows of a table are written by statements.
+type CreateTable struct {
+	Name    string         `@Ident`
+	Options []*TableOption `"(" @@ ("," @@)* ")"`
+}
+
 type CreateIndex struct {
 	Name        string        `@Ident "ON"`
 	TableName   string        `@Ident`
@@ -128,34 +137,92 @@ type ColumnName struct {
 	Name string `@Ident`
 }
 
+type CreateSink struct {
+	Name                 string              `@Ident "FROM"`
+	MaterializedViewName string              `@Ident`
+	TopicInformation     []*TopicInformation `"WITH" "(" @@ ("," @@)* ")"`
+}
+
 // Create statement.
 type Create struct {
 	MaterializedView *CreateMaterializedView `  "MATERIALIZED" "VIEW" @@`
 	Source           *CreateSource           `| "SOURCE" @@`
 	Index            *CreateIndex            `| "INDEX" @@`
+	Sink             *CreateSink             `| "SINK" @@`
+	Table            *CreateTable            `| "TABLE" @@`
 }
 
 // Drop statement
 type Drop struct {
 	MaterializedView bool   `(   @"MATERIALIZED" "VIEW"`
 	Source           bool   `  | @"SOURCE"`
-	Index            bool   `  | @"INDEX" )`
+	Index            bool   `  | @"INDEX"`
+	Sink             bool   `  | @"SINK"`
+	Table            bool   `  | @"TABLE" )`
 	Name             string `@Ident `
 	TableName        string `("ON" @Ident)?`
 }
 
+// A Value in an insert or update statement
+type Value struct {
+	Null   bool    `  @"NULL"`
+	Number *string `| @Number`
+	String *string `| @String`
+}
+
+// Values of a row in an insert statement
+type Values struct {
+	Values []*Value `"(" @@ ("," @@)* ")"`
+}
+
+// Insert statement. The values are given for the columns in order unless the column names are given.
+type Insert struct {
+	TableName   string    `@Ident`
+	ColumnNames []string  `("(" @Ident ("," @Ident)* ")")?`
+	Rows        []*Values `"VALUES" @@ ("," @@)*`
+}
+
+type Assignment struct {
+	ColumnName string `@Ident "="`
+	Value      *Value `@@`
+}
+
+// Update statement. The where clause is passed through to the planner.
+type Update struct {
+	TableName   string        `@Ident "SET"`
+	Assignments []*Assignment `@@ ("," @@)*`
+	Where       *RawQuery     `("WHERE" @@)?`
+}
+
+// Delete statement. The where clause is passed through to the planner.
+type Delete struct {
+	TableName string    `@Ident`
+	Where     *RawQuery `("WHERE" @@)?`
+}
+
 // Show statement
 type Show struct {
 	Tables  string `  @"TABLES"`
 	Schemas string `| @"SCHEMAS"`
 }
 
+// Explain statement
+type Explain struct {
+	Analyze          bool   // Set if the query is run as well as planned. Only used with Select.
+	Select           string // Unaltered SELECT statement, if any.
+	MaterializedView string `"MATERIALIZED" "VIEW" @Ident`
+}
+
 // AST root.
 type AST struct {
-	Select   string  // Unaltered SELECT statement, if any.
-	Use      string  `(  "USE" @Ident`
-	Drop     *Drop   ` | "DROP" @@ `
-	Create   *Create ` | "CREATE" @@ `
-	Show     *Show   ` | "SHOW" @@ `
-	Describe string  ` | "DESCRIBE" @Ident ) ";"?`
+	Select   string   // Unaltered SELECT statement, if any.
+	Use      string   `(  "USE" @Ident`
+	Drop     *Drop    ` | "DROP" @@ `
+	Create   *Create  ` | "CREATE" @@ `
+	Show     *Show    ` | "SHOW" @@ `
+	Explain  *Explain ` | "EXPLAIN" @@ `
+	Insert   *Insert  ` | "INSERT" "INTO" @@ `
+	Update   *Update  ` | "UPDATE" @@ `
+	Delete   *Delete  ` | "DELETE" "FROM" @@ `
+	Describe string   ` | "DESCRIBE" @Ident ) ";"?`
 }
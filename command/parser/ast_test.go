@@ -91,6 +91,48 @@ func TestParse(t *testing.T) {
 				},
 			},
 		}}, ""},
+		{"CreateSourceWithWatermark", `create source payments(id bigint, ts timestamp, primary key (id)) with (
+			brokername = "testbroker",
+			topicname = "testtopic",
+			watermarkcolumn = "ts",
+			maxoutoforderness = "10s"
+		)`, &AST{Create: &Create{
+			Source: &CreateSource{
+				Name: "payments",
+				Options: []*TableOption{
+					{Column: &ColumnDef{Pos: lexer.Position{Offset: 23, Line: 1, Column: 24}, Name: "id", Type: common.Type(3)}},
+					{Column: &ColumnDef{Pos: lexer.Position{Offset: 34, Line: 1, Column: 35}, Name: "ts", Type: common.Type(7)}},
+					{PrimaryKey: []string{"id"}},
+				},
+				TopicInformation: []*TopicInformation{
+					{BrokerName: "testbroker"},
+					{TopicName: "testtopic"},
+					{WatermarkColumn: "ts"},
+					{MaxOutOfOrderness: "10s"},
+				},
+			},
+		}}, ""},
+		{"CreateSink", `create sink payment_totals_sink from payment_totals with (
+			brokername = "testbroker",
+			topicname = "totals",
+			keyencoding = "json",
+			valueencoding = "json"
+		)`, &AST{Create: &Create{
+			Sink: &CreateSink{
+				Name:                 "payment_totals_sink",
+				MaterializedViewName: "payment_totals",
+				TopicInformation: []*TopicInformation{
+					{BrokerName: "testbroker"},
+					{TopicName: "totals"},
+					{KeyEncoding: "json"},
+					{ValueEncoding: "json"},
+				},
+			},
+		}}, ""},
+		{
+			"DropSink", "DROP SINK payment_totals_sink",
+			&AST{Drop: &Drop{Sink: true, Name: "payment_totals_sink"}}, "",
+		},
 		{
 			"DropSource", "DROP SOURCE test_source_1",
 			&AST{Drop: &Drop{Source: true, Name: "test_source_1"}}, "",
@@ -99,10 +141,99 @@ func TestParse(t *testing.T) {
 			"DropMaterializedView", "DROP MATERIALIZED VIEW test_mv_1",
 			&AST{Drop: &Drop{MaterializedView: true, Name: "test_mv_1"}}, "",
 		},
+		{"CreateTable", `create table currencies(code varchar, rate bigint, primary key (code))`, &AST{Create: &Create{
+			Table: &CreateTable{
+				Name: "currencies",
+				Options: []*TableOption{
+					{Column: &ColumnDef{Pos: lexer.Position{Offset: 24, Line: 1, Column: 25}, Name: "code", Type: common.Type(6)}},
+					{Column: &ColumnDef{Pos: lexer.Position{Offset: 38, Line: 1, Column: 39}, Name: "rate", Type: common.Type(3)}},
+					{PrimaryKey: []string{"code"}},
+				},
+			},
+		}}, ""},
+		{"CreateTableWithColumnTypes", `create table docs(id bigint, active boolean, created date, content varbinary, attrs json, primary key (id))`, &AST{Create: &Create{
+			Table: &CreateTable{
+				Name: "docs",
+				Options: []*TableOption{
+					{Column: &ColumnDef{Pos: lexer.Position{Offset: 18, Line: 1, Column: 19}, Name: "id", Type: common.Type(3)}},
+					{Column: &ColumnDef{Pos: lexer.Position{Offset: 29, Line: 1, Column: 30}, Name: "active", Type: common.Type(8)}},
+					{Column: &ColumnDef{Pos: lexer.Position{Offset: 45, Line: 1, Column: 46}, Name: "created", Type: common.Type(9)}},
+					{Column: &ColumnDef{Pos: lexer.Position{Offset: 59, Line: 1, Column: 60}, Name: "content", Type: common.Type(10)}},
+					{Column: &ColumnDef{Pos: lexer.Position{Offset: 78, Line: 1, Column: 79}, Name: "attrs", Type: common.Type(11)}},
+					{PrimaryKey: []string{"id"}},
+				},
+			},
+		}}, ""},
+		{
+			"DropTable", "DROP TABLE currencies",
+			&AST{Drop: &Drop{Table: true, Name: "currencies"}}, "",
+		},
+		{
+			"Insert", `INSERT INTO currencies VALUES ('GBP', 1.5, NULL), ('USD', 2, 'foo')`,
+			&AST{Insert: &Insert{
+				TableName: "currencies",
+				Rows: []*Values{
+					{Values: []*Value{{String: stringRef("GBP")}, {Number: stringRef("1.5")}, {Null: true}}},
+					{Values: []*Value{{String: stringRef("USD")}, {Number: stringRef("2")}, {String: stringRef("foo")}}},
+				},
+			}}, "",
+		},
+		{
+			"InsertWithColumnNames", `insert into currencies (code, rate) values ('GBP', 1.5)`,
+			&AST{Insert: &Insert{
+				TableName:   "currencies",
+				ColumnNames: []string{"code", "rate"},
+				Rows: []*Values{
+					{Values: []*Value{{String: stringRef("GBP")}, {Number: stringRef("1.5")}}},
+				},
+			}}, "",
+		},
+		{
+			"Update", `UPDATE currencies SET rate = 1.5, name = NULL`,
+			&AST{Update: &Update{
+				TableName: "currencies",
+				Assignments: []*Assignment{
+					{ColumnName: "rate", Value: &Value{Number: stringRef("1.5")}},
+					{ColumnName: "name", Value: &Value{Null: true}},
+				},
+			}}, "",
+		},
+		{
+			"DeleteWithWhere", `DELETE FROM currencies WHERE rate > 1`,
+			&AST{Delete: &Delete{
+				TableName: "currencies",
+				Where: &RawQuery{
+					Tokens: []lexer.Token{
+						{Type: -6, Value: " ", Pos: lexer.Position{Offset: 28, Line: 1, Column: 29}},
+						{Type: -2, Value: "rate", Pos: lexer.Position{Offset: 29, Line: 1, Column: 30}},
+						{Type: -6, Value: " ", Pos: lexer.Position{Offset: 33, Line: 1, Column: 34}},
+						{Type: -5, Value: ">", Pos: lexer.Position{Offset: 34, Line: 1, Column: 35}},
+						{Type: -6, Value: " ", Pos: lexer.Position{Offset: 35, Line: 1, Column: 36}},
+						{Type: -3, Value: "1", Pos: lexer.Position{Offset: 36, Line: 1, Column: 37}},
+					},
+				},
+			}}, "",
+		},
+		{
+			"Delete", `DELETE FROM currencies`,
+			&AST{Delete: &Delete{TableName: "currencies"}}, "",
+		},
 		{
 			"Describe", `DESCRIBE foo`,
 			&AST{Describe: "foo"}, "",
 		},
+		{
+			"Explain", "EXPLAIN SELECT * FROM table WHERE foo = 1",
+			&AST{Explain: &Explain{Select: "SELECT * FROM table WHERE foo = 1"}}, "",
+		},
+		{
+			"ExplainAnalyze", "explain  analyze\nselect count(*) from table",
+			&AST{Explain: &Explain{Analyze: true, Select: "select count(*) from table"}}, "",
+		},
+		{
+			"ExplainMaterializedView", "EXPLAIN MATERIALIZED VIEW test_mv_1",
+			&AST{Explain: &Explain{MaterializedView: "test_mv_1"}}, "",
+		},
 		{
 			"ShowTables", `SHOW TABLES`,
 			&AST{Show: &Show{Tables: "TABLES"}}, "",
@@ -34,7 +34,8 @@ var (
 		// }, "Ident"),
 		participle.Unquote("String"),
 	)
-	selectPrefix = regexp.MustCompile(`(?i)^select\s+`)
+	selectPrefix        = regexp.MustCompile(`(?i)^select\s+`)
+	explainSelectPrefix = regexp.MustCompile(`(?i)^explain\s+(analyze\s+)?(select\s+)`)
 )
 
 // Parse an SQL statement.
@@ -42,6 +43,10 @@ func Parse(sql string) (*AST, error) {
 	if selectPrefix.MatchString(sql) {
 		return &AST{Select: sql}, nil
 	}
+	if loc := explainSelectPrefix.FindStringSubmatchIndex(sql); loc != nil {
+		// Like a SELECT, the query of an EXPLAIN is passed through to the planner unaltered
+		return &AST{Explain: &Explain{Analyze: loc[2] != -1, Select: sql[loc[4]:]}}, nil
+	}
 	ast := &AST{}
 	err := parser.ParseString("", sql, ast)
 	return ast, errors.WithStack(err)
@@ -1,4 +1,4 @@
-// Package selector contains a selector library for JSON and Protobuf.
+// Package selector contains a selector library for JSON, Protobuf and CSV.
 // It's in its own standalone package only to avoid circular dependencies
 //
 // nolint:govet
@@ -18,31 +18,30 @@ import (
 	"google.golang.org/protobuf/types/dynamicpb"
 )
 
+// ColumnSelectorAST is a column selector. It can start with an index, e.g. `[2]`, to select a field of a CSV record.
 type ColumnSelectorAST struct {
-	MetaKey *string      `( "meta" "(" @String ")" |`
-	Field   *string      `  @Ident )`
-	Index   []*Index     `( "[" @@ "]" )*`
-	Next    *SelectorAST `("." @@)?`
+	MetaKey    *string      `( "meta" "(" @String ")" |`
+	Field      *string      `  @Ident |`
+	FirstIndex *Index       `  "[" @@ "]" )`
+	Index      []*Index     `( "[" @@ "]" )*`
+	Next       *SelectorAST `("." @@)?`
 }
 
 func (s *ColumnSelectorAST) ToSelector() ColumnSelector {
-	if s.MetaKey != nil {
-		return ColumnSelector{
-			MetaKey:  s.MetaKey,
-			Selector: s.Next.ToSelector(),
-		}
-	}
-	f := ""
+	var sel Selector
 	if s.Field != nil {
-		f = *s.Field
+		sel = append(sel, Path{Field: s.Field})
+	}
+	if s.FirstIndex != nil {
+		sel = append(sel, s.FirstIndex.toPath())
+	}
+	for _, idx := range s.Index {
+		sel = append(sel, idx.toPath())
 	}
+	sel = append(sel, s.Next.ToSelector()...)
 	return ColumnSelector{
-		MetaKey: s.MetaKey,
-		Selector: (&SelectorAST{
-			Field: f,
-			Index: s.Index,
-			Next:  s.Next,
-		}).ToSelector(),
+		MetaKey:  s.MetaKey,
+		Selector: sel,
 	}
 }
 
@@ -67,10 +66,10 @@ func (s ColumnSelector) String() string {
 	if s.MetaKey != nil {
 		v += fmt.Sprintf(`meta("%s")`, *s.MetaKey)
 	}
-	if len(s.Selector) > 0 {
-		v += "." + s.Selector.String()
+	if s.MetaKey != nil && len(s.Selector) > 0 && s.Selector[0].Field != nil {
+		v += "."
 	}
-	return v
+	return v + s.Selector.String()
 }
 
 type SelectorAST struct {
@@ -88,19 +87,20 @@ func (a *SelectorAST) ToSelector() Selector {
 	var sel []Path
 	for ; a != nil; a = a.Next {
 		sel = append(sel, Path{Field: &a.Field})
-		if len(a.Index) > 0 {
-			for _, idx := range a.Index {
-				if idx.Number != nil {
-					sel = append(sel, Path{NumberIndex: idx.Number})
-				} else {
-					sel = append(sel, Path{Field: idx.String})
-				}
-			}
+		for _, idx := range a.Index {
+			sel = append(sel, idx.toPath())
 		}
 	}
 	return sel
 }
 
+func (i *Index) toPath() Path {
+	if i.Number != nil {
+		return Path{NumberIndex: i.Number}
+	}
+	return Path{Field: i.String}
+}
+
 // Selector is a protobuf path selector.
 type Selector []Path
 
@@ -150,10 +150,20 @@ func ParseSelector(str string) (Selector, error) {
 	return s.ToSelector(), errors.WithStack(err)
 }
 
+// Record is a decoded value whose fields can be selected by index, and by name if they have names. E.g. a CSV record.
+type Record interface {
+	// FieldByIndex returns the field at the index, or false if there is no such field
+	FieldByIndex(index int) (interface{}, bool)
+	// FieldByName returns the field with the name, or false if there is no such field
+	FieldByName(name string) (interface{}, bool)
+}
+
 // Select evaluates the selector expression against the given value. The only supported types are
-//     map[string]interface{}
-//     []interface{}
-//     google.golang.org/protobuf/reflect.Message
+//
+//	map[string]interface{}
+//	[]interface{}
+//	google.golang.org/protobuf/reflect.Message
+//	Record
 func (s Selector) Select(v interface{}) (interface{}, error) {
 	var ok bool
 	for i, token := range s {
@@ -176,6 +186,16 @@ func (s Selector) Select(v interface{}) (interface{}, error) {
 			v = vv[*token.NumberIndex]
 		case pref.Message:
 			return s[i:].SelectProto(vv)
+		case Record:
+			// As with a map, a missing field is null
+			if token.NumberIndex != nil {
+				v, ok = vv.FieldByIndex(*token.NumberIndex)
+			} else {
+				v, ok = vv.FieldByName(*token.Field)
+			}
+			if !ok {
+				return nil, nil
+			}
 		}
 	}
 	return v, nil
@@ -49,6 +49,31 @@ func TestParseColumnSelector(t *testing.T) {
 			selector: `meta("key").hello.world`,
 			want:     ColumnSelector{MetaKey: stringRef("key"), Selector: newSelector("hello", "world")},
 		},
+		{
+			name:     "meta only",
+			selector: `meta("value")`,
+			want:     ColumnSelector{MetaKey: stringRef("value")},
+		},
+		{
+			name:     "meta index",
+			selector: `meta("key")[1]`,
+			want:     ColumnSelector{MetaKey: stringRef("key"), Selector: newSelector(1)},
+		},
+		{
+			name:     "index",
+			selector: `[2]`,
+			want:     ColumnSelector{Selector: newSelector(2)},
+		},
+		{
+			name:     "index then field",
+			selector: `[2][3].hello`,
+			want:     ColumnSelector{Selector: newSelector(2, 3, "hello")},
+		},
+		{
+			name:     "empty",
+			selector: ``,
+			wantErr:  true,
+		},
 	}
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
@@ -169,6 +194,50 @@ func TestSelect(t *testing.T) {
 	}
 }
 
+type testRecord struct {
+	names  []string
+	fields []interface{}
+}
+
+func (r *testRecord) FieldByIndex(index int) (interface{}, bool) {
+	if index < 0 || index >= len(r.fields) {
+		return nil, false
+	}
+	return r.fields[index], true
+}
+
+func (r *testRecord) FieldByName(name string) (interface{}, bool) {
+	for i, n := range r.names {
+		if n == name {
+			return r.FieldByIndex(i)
+		}
+	}
+	return nil, false
+}
+
+func TestSelectRecord(t *testing.T) {
+	record := &testRecord{names: []string{"id", "name"}, fields: []interface{}{"123", "bob"}}
+	tests := []struct {
+		selector string
+		want     interface{}
+	}{
+		{selector: "[0]", want: "123"},
+		{selector: "[1]", want: "bob"},
+		{selector: "[2]", want: nil},
+		{selector: "name", want: "bob"},
+		{selector: "unknown", want: nil},
+	}
+	for _, test := range tests {
+		t.Run(test.selector, func(t *testing.T) {
+			sel, err := ParseColumnSelector(test.selector)
+			require.NoError(t, err)
+			v, err := sel.Select(nil, record)
+			require.NoError(t, err)
+			require.Equal(t, test.want, v)
+		})
+	}
+}
+
 func TestSelectProto(t *testing.T) {
 	data := &testproto.TestTypes{
 		DoubleField: 1.2,
@@ -46,7 +46,7 @@ func RowsEqual(t *testing.T, expected common.Row, actual common.Row, colTypes []
 		require.Equal(t, expectedNull, actualNull)
 		if !expectedNull {
 			switch colType.Type {
-			case common.TypeTinyInt, common.TypeInt, common.TypeBigInt:
+			case common.TypeTinyInt, common.TypeInt, common.TypeBigInt, common.TypeBoolean:
 				val1 := expected.GetInt64(colIndex)
 				val2 := actual.GetInt64(colIndex)
 				require.Equal(t, val1, val2)
@@ -62,10 +62,18 @@ func RowsEqual(t *testing.T, expected common.Row, actual common.Row, colTypes []
 				val1 := expected.GetString(colIndex)
 				val2 := actual.GetString(colIndex)
 				require.Equal(t, val1, val2)
-			case common.TypeTimestamp:
+			case common.TypeTimestamp, common.TypeDate:
 				val1 := expected.GetTimestamp(colIndex)
 				val2 := actual.GetTimestamp(colIndex)
 				require.Equalf(t, 0, val1.Compare(val2), "timestamps not equal: %v and %v", val1, val2)
+			case common.TypeVarbinary:
+				val1 := expected.GetBytes(colIndex)
+				val2 := actual.GetBytes(colIndex)
+				require.Equal(t, val1, val2)
+			case common.TypeJSON:
+				val1 := expected.GetJSON(colIndex)
+				val2 := actual.GetJSON(colIndex)
+				require.Equal(t, val1.String(), val2.String())
 			default:
 				t.Errorf("unexpected column type %d", colType)
 			}
@@ -96,6 +104,22 @@ func AppendRow(t *testing.T, rows *common.Rows, colTypes []common.ColumnType, co
 			case common.TypeTimestamp:
 				ts := common.NewTimestampFromString(colVal.(string))
 				rows.AppendTimestampToColumn(i, ts)
+			case common.TypeBoolean:
+				var val int64
+				if colVal.(bool) {
+					val = 1
+				}
+				rows.AppendInt64ToColumn(i, val)
+			case common.TypeDate:
+				date, err := common.ParseDate(colVal.(string))
+				require.NoError(t, err)
+				rows.AppendTimestampToColumn(i, date)
+			case common.TypeVarbinary:
+				rows.AppendBytesToColumn(i, colVal.([]byte))
+			case common.TypeJSON:
+				j, err := common.ParseJSON(colVal.(string))
+				require.NoError(t, err)
+				rows.AppendJSONToColumn(i, j)
 			default:
 				panic(colType.Type)
 			}
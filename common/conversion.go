@@ -27,6 +27,21 @@ func ConvertPranaTypeToTiDBType(columnType ColumnType) *types.FieldType {
 	case TypeTimestamp:
 		ft = types.NewFieldType(mysql.TypeTimestamp)
 		ft.Decimal = int(columnType.FSP)
+	case TypeBoolean:
+		// Like MySQL, a boolean is a tinyint(1)
+		ft = types.NewFieldType(mysql.TypeTiny)
+		ft.Flen = 1
+		ft.Flag |= mysql.IsBooleanFlag
+	case TypeDate:
+		ft = types.NewFieldType(mysql.TypeDate)
+	case TypeVarbinary:
+		ft = types.NewFieldType(mysql.TypeVarString)
+		types.SetBinChsClnFlag(ft)
+		return ft
+	case TypeJSON:
+		ft = types.NewFieldType(mysql.TypeJSON)
+		types.SetBinChsClnFlag(ft)
+		return ft
 	default:
 		panic(fmt.Sprintf("unknown column type %d", columnType))
 	}
@@ -37,6 +52,9 @@ func ConvertPranaTypeToTiDBType(columnType ColumnType) *types.FieldType {
 func ConvertTiDBTypeToPranaType(columnType *types.FieldType) ColumnType {
 	switch columnType.Tp {
 	case mysql.TypeTiny:
+		if mysql.HasIsBooleanFlag(columnType.Flag) {
+			return BooleanColumnType
+		}
 		return TinyIntColumnType
 	case mysql.TypeLong:
 		return IntColumnType
@@ -47,10 +65,20 @@ func ConvertTiDBTypeToPranaType(columnType *types.FieldType) ColumnType {
 	case mysql.TypeNewDecimal:
 		// The TiDB expression does not calculate the right precision and scale so we just use maximum
 		return NewDecimalColumnType(65, 30)
-	case mysql.TypeVarchar, mysql.TypeVarString:
+	case mysql.TypeVarchar, mysql.TypeVarString, mysql.TypeBlob, mysql.TypeTinyBlob, mysql.TypeMediumBlob,
+		mysql.TypeLongBlob:
+		// Functions such as json_unquote return blob types, which we treat the same as the string types
+		if types.IsBinaryStr(columnType) {
+			return VarbinaryColumnType
+		}
 		return VarcharColumnType
-	case mysql.TypeTimestamp:
+	case mysql.TypeTimestamp, mysql.TypeDatetime:
+		// Datetimes, e.g. the results of date functions, are represented as timestamps
 		return TimestampColumnType
+	case mysql.TypeDate:
+		return DateColumnType
+	case mysql.TypeJSON:
+		return JSONColumnType
 	default:
 		panic(fmt.Sprintf("unknown colum type %d", columnType.Tp))
 	}
@@ -63,3 +91,11 @@ func TiDBValueToPranaValue(tidbValue interface{}) interface{} {
 	}
 	return tidbValue
 }
+
+func PranaValueToTiDBValue(pranaValue interface{}) interface{} {
+	dec, ok := pranaValue.(Decimal)
+	if ok {
+		return dec.decimal
+	}
+	return pranaValue
+}
@@ -95,6 +95,24 @@ func (d *Decimal) Subtract(other *Decimal) (*Decimal, error) {
 	return NewDecimal(result), nil
 }
 
+// Divide divides the decimal by the other, increasing the scale of the result by fracIncr
+func (d *Decimal) Divide(other *Decimal, fracIncr int) (*Decimal, error) {
+	result := &types.MyDecimal{}
+	if err := types.DecimalDiv(d.decimal, other.decimal, result, fracIncr); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return NewDecimal(result), nil
+}
+
+// Round rounds the decimal half up to the given scale
+func (d *Decimal) Round(scale int) (*Decimal, error) {
+	result := &types.MyDecimal{}
+	if err := d.decimal.Round(result, scale, types.ModeHalfEven); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return NewDecimal(result), nil
+}
+
 func (d *Decimal) String() string {
 	return string(d.decimal.ToString())
 }
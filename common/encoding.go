@@ -55,6 +55,18 @@ func AppendStringToBufferLE(buffer []byte, value string) []byte {
 	return buffPtr
 }
 
+func AppendBytesToBufferLE(buffer []byte, value []byte) []byte {
+	buffPtr := AppendUint32ToBufferLE(buffer, uint32(len(value)))
+	buffPtr = append(buffPtr, value...)
+	return buffPtr
+}
+
+// AppendJSONToBufferLE appends the type code of the JSON document followed by its binary value
+func AppendJSONToBufferLE(buffer []byte, value JSON) []byte {
+	buffer = append(buffer, value.TypeCode)
+	return AppendBytesToBufferLE(buffer, value.Value)
+}
+
 func AppendTimestampToBuffer(buffer []byte, ts Timestamp) ([]byte, error) {
 	enc, err := ts.ToPackedUint()
 	if err != nil {
@@ -162,6 +174,42 @@ func ReadTimestampFromBufferBE(buffer []byte, offset int, fsp int8) (val Timesta
 	return ts, off, nil
 }
 
+// ReadDateFromBuffer reads a date written with AppendTimestampToBuffer
+func ReadDateFromBuffer(buffer []byte, offset int) (val Timestamp, off int, err error) {
+	ts := Timestamp{}
+	enc, off := ReadUint64FromBufferLE(buffer, offset)
+	if err := ts.FromPackedUint(enc); err != nil {
+		return Timestamp{}, 0, errors.WithStack(err)
+	}
+	ts.SetType(mysql.TypeDate)
+	return ts, off, nil
+}
+
+// ReadDateFromBufferBE reads a date written with KeyEncodeTimestamp
+func ReadDateFromBufferBE(buffer []byte, offset int) (val Timestamp, off int, err error) {
+	ts := Timestamp{}
+	enc, off := ReadUint64FromBufferBE(buffer, offset)
+	if err := ts.FromPackedUint(enc); err != nil {
+		return Timestamp{}, 0, errors.WithStack(err)
+	}
+	ts.SetType(mysql.TypeDate)
+	return ts, off, nil
+}
+
+func ReadBytesFromBufferLE(buffer []byte, offset int) (val []byte, off int) {
+	lu, offset := ReadUint32FromBufferLE(buffer, offset)
+	l := int(lu)
+	val = buffer[offset : offset+l]
+	offset += l
+	return val, offset
+}
+
+func ReadJSONFromBufferLE(buffer []byte, offset int) (val JSON, off int) {
+	val.TypeCode = buffer[offset]
+	val.Value, offset = ReadBytesFromBufferLE(buffer, offset+1)
+	return val, offset
+}
+
 func ReadStringFromBufferLE(buffer []byte, offset int) (val string, off int) {
 	lu, offset := ReadUint32FromBufferLE(buffer, offset)
 	l := int(lu)
@@ -150,3 +150,8 @@ func (e *Expression) EvalTimestamp(row *Row) (Timestamp, bool, error) {
 func (e *Expression) EvalString(row *Row) (val string, null bool, err error) {
 	return e.expression.EvalString(e.ctx, row.tRow)
 }
+
+func (e *Expression) EvalJSON(row *Row) (val JSON, null bool, err error) {
+	val, null, err = e.expression.EvalJSON(e.ctx, row.tRow)
+	return val, null, errors.WithStack(err)
+}
@@ -0,0 +1,32 @@
+package common
+
+import (
+	"github.com/squareup/pranadb/errors"
+	"github.com/squareup/pranadb/tidb/types/json"
+)
+
+// JSON is a JSON document, held in the binary format used by the JSON functions of the SQL engine
+type JSON = json.BinaryJSON
+
+// ParseJSON parses a JSON document from its text
+func ParseJSON(str string) (JSON, error) {
+	j, err := json.ParseBinaryFromString(str)
+	return j, errors.WithStack(err)
+}
+
+// NewJSONFromGoValue creates a JSON document from a value decoded by encoding/json, e.g. a map[string]interface{}
+func NewJSONFromGoValue(val interface{}) (j JSON, err error) {
+	defer func() {
+		// CreateBinary panics if the value contains a type which can't be represented in JSON
+		if r := recover(); r != nil {
+			err = errors.Errorf("cannot convert %v to json: %v", val, r)
+		}
+	}()
+	return json.CreateBinary(val), nil
+}
+
+// CompareJSON compares two JSON documents, returning a negative number if a sorts before b, zero if they are equal and
+// a positive number if a sorts after b
+func CompareJSON(a JSON, b JSON) int {
+	return json.CompareBinary(a, b)
+}
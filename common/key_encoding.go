@@ -60,6 +60,34 @@ func KeyDecodeString(buffer []byte, offset int) (string, int) {
 	return str, offset
 }
 
+func KeyEncodeBytes(buffer []byte, val []byte) []byte {
+	uval := uint32(len(val))
+	buffer = AppendUint32ToBufferBE(buffer, uval)
+	return append(buffer, val...)
+}
+
+func KeyDecodeBytes(buffer []byte, offset int) ([]byte, int) {
+	uval, offset := ReadUint32FromBufferBE(buffer, offset)
+	l := int(uval)
+	val := buffer[offset : offset+l]
+	offset += l
+	return val, offset
+}
+
+// KeyEncodeJSON encodes the type code of the JSON document followed by its binary value. Equal documents have the same
+// binary value, but the order of the keys has no meaning.
+func KeyEncodeJSON(buffer []byte, val JSON) []byte {
+	buffer = append(buffer, val.TypeCode)
+	return KeyEncodeBytes(buffer, val.Value)
+}
+
+func KeyDecodeJSON(buffer []byte, offset int) (JSON, int) {
+	var val JSON
+	val.TypeCode = buffer[offset]
+	val.Value, offset = KeyDecodeBytes(buffer, offset+1)
+	return val, offset
+}
+
 func KeyEncodeTimestamp(buffer []byte, val Timestamp) ([]byte, error) {
 	enc, err := val.ToPackedUint()
 	if err != nil {
@@ -83,7 +111,7 @@ func EncodeKey(key Key, colTypes []ColumnType, keyColIndexes []int, buffer []byt
 
 func EncodeKeyElement(value interface{}, colType ColumnType, buffer []byte) ([]byte, error) {
 	switch colType.Type {
-	case TypeTinyInt, TypeInt, TypeBigInt:
+	case TypeTinyInt, TypeInt, TypeBigInt, TypeBoolean:
 		valInt64, ok := value.(int64)
 		if !ok {
 			return nil, errors.Errorf("expected %v to be int64", value)
@@ -111,7 +139,7 @@ func EncodeKeyElement(value interface{}, colType ColumnType, buffer []byte) ([]b
 			return nil, errors.Errorf("expected %v to be string", value)
 		}
 		buffer = KeyEncodeString(buffer, valString)
-	case TypeTimestamp:
+	case TypeTimestamp, TypeDate:
 		valTime, ok := value.(Timestamp)
 		if !ok {
 			return nil, errors.Errorf("expected %v to be Timestamp", value)
@@ -121,6 +149,21 @@ func EncodeKeyElement(value interface{}, colType ColumnType, buffer []byte) ([]b
 		if err != nil {
 			return nil, errors.WithStack(err)
 		}
+	case TypeVarbinary:
+		switch valBytes := value.(type) {
+		case []byte:
+			buffer = KeyEncodeBytes(buffer, valBytes)
+		case string:
+			buffer = KeyEncodeString(buffer, valBytes)
+		default:
+			return nil, errors.Errorf("expected %v to be []byte", value)
+		}
+	case TypeJSON:
+		valJSON, ok := value.(JSON)
+		if !ok {
+			return nil, errors.Errorf("expected %v to be JSON", value)
+		}
+		buffer = KeyEncodeJSON(buffer, valJSON)
 	default:
 		return nil, errors.Errorf("unexpected column type %d", colType)
 	}
@@ -157,9 +200,14 @@ func EncodeIndexKeyCols(row *Row, colIndexes []int, colTypes []ColumnType, buffe
 }
 
 func EncodeKeyCol(row *Row, colIndex int, colType ColumnType, buffer []byte) ([]byte, error) {
+	if row.IsNull(colIndex) {
+		// Key columns can be null, e.g. the columns from the inner side of an outer join for a row with no match. The
+		// value of a null column is undefined, so we encode the zero value of the type instead
+		return encodeZeroKeyCol(colType, buffer)
+	}
 	// Key columns must be stored in big-endian so whole key can be compared byte-wise
 	switch colType.Type {
-	case TypeTinyInt, TypeInt, TypeBigInt:
+	case TypeTinyInt, TypeInt, TypeBigInt, TypeBoolean:
 		// We store as unsigned so convert signed to unsigned
 		valInt64 := row.GetInt64(colIndex)
 		buffer = KeyEncodeInt64(buffer, valInt64)
@@ -176,19 +224,46 @@ func EncodeKeyCol(row *Row, colIndex int, colType ColumnType, buffer []byte) ([]
 	case TypeVarchar:
 		valString := row.GetString(colIndex)
 		buffer = KeyEncodeString(buffer, valString)
-	case TypeTimestamp:
+	case TypeTimestamp, TypeDate:
 		valTime := row.GetTimestamp(colIndex)
 		var err error
 		buffer, err = KeyEncodeTimestamp(buffer, valTime)
 		if err != nil {
 			return nil, errors.WithStack(err)
 		}
+	case TypeVarbinary:
+		valBytes := row.GetBytes(colIndex)
+		buffer = KeyEncodeBytes(buffer, valBytes)
+	case TypeJSON:
+		valJSON := row.GetJSON(colIndex)
+		buffer = KeyEncodeJSON(buffer, valJSON)
 	default:
 		return nil, errors.Errorf("unexpected column type %d", colType)
 	}
 	return buffer, nil
 }
 
+func encodeZeroKeyCol(colType ColumnType, buffer []byte) ([]byte, error) {
+	switch colType.Type {
+	case TypeTinyInt, TypeInt, TypeBigInt, TypeBoolean:
+		return KeyEncodeInt64(buffer, 0), nil
+	case TypeDecimal:
+		return KeyEncodeDecimal(buffer, *NewDecFromInt64(0), colType.DecPrecision, colType.DecScale)
+	case TypeDouble:
+		return KeyEncodeFloat64(buffer, 0), nil
+	case TypeVarchar:
+		return KeyEncodeString(buffer, ""), nil
+	case TypeTimestamp, TypeDate:
+		return KeyEncodeTimestamp(buffer, Timestamp{})
+	case TypeVarbinary:
+		return KeyEncodeBytes(buffer, nil), nil
+	case TypeJSON:
+		return KeyEncodeJSON(buffer, JSON{}), nil
+	default:
+		return nil, errors.Errorf("unexpected column type %d", colType)
+	}
+}
+
 func DecodeIndexOrPKCols(buffer []byte, offset int, pk bool, indexOrPKColTypes []ColumnType, indexOrPKOutputCols []int, rows *Rows) (int, error) {
 	for i, outputCol := range indexOrPKOutputCols {
 		colType := indexOrPKColTypes[i]
@@ -213,7 +288,7 @@ func DecodeIndexOrPKCol(buffer []byte, offset int, colType ColumnType, outputCol
 		}
 	} else {
 		switch colType.Type {
-		case TypeTinyInt, TypeInt, TypeBigInt:
+		case TypeTinyInt, TypeInt, TypeBigInt, TypeBoolean:
 			var u uint64
 			u, offset = ReadUint64FromBufferBE(buffer, offset)
 			if outputColIndex != -1 {
@@ -260,6 +335,30 @@ func DecodeIndexOrPKCol(buffer []byte, offset int, colType ColumnType, outputCol
 			if outputColIndex != -1 {
 				rows.AppendTimestampToColumn(outputColIndex, val)
 			}
+		case TypeDate:
+			var (
+				val Timestamp
+				err error
+			)
+			val, offset, err = ReadDateFromBufferBE(buffer, offset)
+			if err != nil {
+				return 0, errors.WithStack(err)
+			}
+			if outputColIndex != -1 {
+				rows.AppendTimestampToColumn(outputColIndex, val)
+			}
+		case TypeVarbinary:
+			var val []byte
+			val, offset = KeyDecodeBytes(buffer, offset)
+			if outputColIndex != -1 {
+				rows.AppendBytesToColumn(outputColIndex, val)
+			}
+		case TypeJSON:
+			var val JSON
+			val, offset = KeyDecodeJSON(buffer, offset)
+			if outputColIndex != -1 {
+				rows.AppendJSONToColumn(outputColIndex, val)
+			}
 		default:
 			return 0, errors.Errorf("unexpected column type %d", colType)
 		}
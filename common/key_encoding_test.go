@@ -140,6 +140,35 @@ func TestKeyEncodeTimestamp(t *testing.T) {
 	}
 }
 
+func TestEncodeKeyColsNullIsDeterministic(t *testing.T) {
+	colTypes := []ColumnType{BigIntColumnType, VarcharColumnType, NewDecimalColumnType(10, 2), DoubleColumnType,
+		TimestampColumnType}
+	rf := NewRowsFactory(colTypes)
+	rows := rf.NewRows(2)
+	// Append a row of values first, so any garbage left behind would show up in the null row
+	rows.AppendInt64ToColumn(0, 23)
+	rows.AppendStringToColumn(1, "foo")
+	rows.AppendDecimalToColumn(2, *NewDecFromInt64(12))
+	rows.AppendFloat64ToColumn(3, 1.23)
+	rows.AppendTimestampToColumn(4, NewTimestampFromString("2021-01-02 12:34:56"))
+	for i := range colTypes {
+		rows.AppendNullToColumn(i)
+	}
+	nullRow := rows.GetRow(1)
+	keyCols := []int{0, 1, 2, 3, 4}
+	b1, err := EncodeKeyCols(&nullRow, keyCols, colTypes, nil)
+	require.NoError(t, err)
+
+	rows = rf.NewRows(1)
+	for i := range colTypes {
+		rows.AppendNullToColumn(i)
+	}
+	nullRow = rows.GetRow(0)
+	b2, err := EncodeKeyCols(&nullRow, keyCols, colTypes, nil)
+	require.NoError(t, err)
+	require.Equal(t, b1, b2)
+}
+
 func encodeInt64(val int64) []byte {
 	return KeyEncodeInt64([]byte{}, val)
 }
@@ -5,6 +5,7 @@ import (
 	"reflect"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/squareup/pranadb/command/parser/selector"
 	"github.com/squareup/pranadb/errors"
@@ -21,6 +22,10 @@ const (
 	TypeDecimal
 	TypeVarchar
 	TypeTimestamp
+	TypeBoolean
+	TypeDate
+	TypeVarbinary
+	TypeJSON
 )
 
 func (t *Type) Capture(tokens []string) error {
@@ -40,6 +45,14 @@ func (t *Type) Capture(tokens []string) error {
 		*t = TypeDouble
 	case "TIMESTAMP":
 		*t = TypeTimestamp
+	case "BOOLEAN":
+		*t = TypeBoolean
+	case "DATE":
+		*t = TypeDate
+	case "VARBINARY":
+		*t = TypeVarbinary
+	case "JSON":
+		*t = TypeJSON
 	default:
 		return errors.Errorf("unknown column type %s", text)
 	}
@@ -63,6 +76,14 @@ func (t Type) String() string {
 		return "varchar"
 	case TypeTimestamp:
 		return "timestamp"
+	case TypeBoolean:
+		return "boolean"
+	case TypeDate:
+		return "date"
+	case TypeVarbinary:
+		return "varbinary"
+	case TypeJSON:
+		return "json"
 	case TypeUnknown:
 	}
 	return "unknown"
@@ -75,15 +96,23 @@ var (
 	DoubleColumnType    = ColumnType{Type: TypeDouble}
 	VarcharColumnType   = ColumnType{Type: TypeVarchar}
 	TimestampColumnType = ColumnType{Type: TypeTimestamp}
+	BooleanColumnType   = ColumnType{Type: TypeBoolean}
+	DateColumnType      = ColumnType{Type: TypeDate}
+	VarbinaryColumnType = ColumnType{Type: TypeVarbinary}
+	JSONColumnType      = ColumnType{Type: TypeJSON}
 	UnknownColumnType   = ColumnType{Type: TypeUnknown}
 
 	// ColumnTypesByType allows lookup of non-parameterised ColumnType by Type.
 	ColumnTypesByType = map[Type]ColumnType{
-		TypeTinyInt: TinyIntColumnType,
-		TypeInt:     IntColumnType,
-		TypeBigInt:  BigIntColumnType,
-		TypeDouble:  DoubleColumnType,
-		TypeVarchar: VarcharColumnType,
+		TypeTinyInt:   TinyIntColumnType,
+		TypeInt:       IntColumnType,
+		TypeBigInt:    BigIntColumnType,
+		TypeDouble:    DoubleColumnType,
+		TypeVarchar:   VarcharColumnType,
+		TypeBoolean:   BooleanColumnType,
+		TypeDate:      DateColumnType,
+		TypeVarbinary: VarbinaryColumnType,
+		TypeJSON:      JSONColumnType,
 	}
 )
 
@@ -102,6 +131,12 @@ func InferColumnType(value interface{}) ColumnType {
 		return DoubleColumnType
 	case Timestamp:
 		return TimestampColumnType
+	case bool:
+		return BooleanColumnType
+	case []byte:
+		return VarbinaryColumnType
+	case JSON:
+		return JSONColumnType
 	default:
 		panic(fmt.Sprintf("can't infer column of type %T", value))
 	}
@@ -156,7 +191,10 @@ type TableInfo struct {
 	IndexInfos     map[string]*IndexInfo
 	ColsVisible    []bool
 	Internal       bool
-	pKColsSet      map[int]struct{}
+	// AppendOnly is true for a source without a primary key. Its rows are keyed by hidden columns which hold the
+	// partition and offset of their messages, so they are never updated or deleted
+	AppendOnly bool
+	pKColsSet  map[int]struct{}
 }
 
 func (t *TableInfo) calcPKColsSet() {
@@ -275,6 +313,25 @@ func (s *Schema) DeleteTable(name string) {
 	delete(s.tables, name)
 }
 
+func (s *Schema) GetSink(name string) (*SinkInfo, bool) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	sink, ok := s.sinks[name]
+	return sink, ok
+}
+
+func (s *Schema) PutSink(name string, sink *SinkInfo) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.sinks[name] = sink
+}
+
+func (s *Schema) DeleteSink(name string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	delete(s.sinks, name)
+}
+
 func (s *Schema) LenTables() int {
 	s.lock.RLock()
 	defer s.lock.RUnlock()
@@ -323,6 +380,16 @@ func (i *SourceInfo) String() string {
 	return "source_" + i.TableInfo.String()
 }
 
+// UserTableInfo describes a table whose rows are inserted, updated and deleted by statements, e.g. a table of
+// reference data
+type UserTableInfo struct {
+	*TableInfo
+}
+
+func (i *UserTableInfo) String() string {
+	return "table_" + i.TableInfo.String()
+}
+
 type Table interface {
 	GetTableInfo() *TableInfo
 }
@@ -344,6 +411,15 @@ type TopicInfo struct {
 	HeaderEncoding KafkaEncoding
 	ColSelectors   []selector.ColumnSelector
 	Properties     map[string]string
+	Watermark      *WatermarkInfo
+}
+
+// WatermarkInfo describes the watermark of a source. The watermark of each partition of the topic is the greatest event
+// time of its messages less the maximum out-of-orderness, and the watermark of the source is the least of these.
+type WatermarkInfo struct {
+	TableID           uint64 // The table which stores the watermarks of the partitions on each shard
+	TimeCol           int
+	MaxOutOfOrderness time.Duration
 }
 
 type KafkaEncoding struct {
@@ -362,6 +438,7 @@ var (
 	KafkaEncodingInt64BE     = KafkaEncoding{Encoding: EncodingInt64BE}
 	KafkaEncodingInt16BE     = KafkaEncoding{Encoding: EncodingInt16BE}
 	KafkaEncodingStringBytes = KafkaEncoding{Encoding: EncodingStringBytes}
+	KafkaEncodingAvro        = KafkaEncoding{Encoding: EncodingAvro}
 )
 
 type Encoding int
@@ -378,6 +455,7 @@ const (
 	EncodingInt64BE
 	EncodingInt16BE
 	EncodingStringBytes
+	EncodingAvro // Avro, in the schema registry wire format
 )
 
 // KafkaEncodingFromString decodes an encoding and an optional schema name from the string,
@@ -415,11 +493,42 @@ func EncodingFormatFromString(str string) Encoding {
 		return EncodingInt16BE
 	case "stringbytes":
 		return EncodingStringBytes
+	case "avro":
+		return EncodingAvro
 	default:
 		return EncodingUnknown
 	}
 }
 
+func (e Encoding) String() string {
+	switch e {
+	case EncodingJSON:
+		return "json"
+	case EncodingProtobuf:
+		return "protobuf"
+	case EncodingRaw:
+		return "raw"
+	case EncodingCSV:
+		return "csv"
+	case EncodingFloat32BE:
+		return "float32be"
+	case EncodingFloat64BE:
+		return "float64be"
+	case EncodingInt32BE:
+		return "int32be"
+	case EncodingInt64BE:
+		return "int64be"
+	case EncodingInt16BE:
+		return "int16be"
+	case EncodingStringBytes:
+		return "stringbytes"
+	case EncodingAvro:
+		return "avro"
+	default:
+		return "unknown"
+	}
+}
+
 type MaterializedViewInfo struct {
 	*TableInfo
 	Query string
@@ -435,8 +544,15 @@ func (i *MaterializedViewInfo) String() string {
 	return "mv_" + i.TableInfo.String()
 }
 
+// SinkInfo describes a sink, which publishes the changes to a materialized view to a Kafka topic
 type SinkInfo struct {
-	Name      string
-	Query     string
-	TopicInfo *TopicInfo
+	ID                   uint64 // The table which holds the messages which have not been delivered yet
+	SchemaName           string
+	Name                 string
+	MaterializedViewName string
+	TopicInfo            *TopicInfo
+}
+
+func (i *SinkInfo) String() string {
+	return fmt.Sprintf("sink[name=%s.%s,id=%d]", i.SchemaName, i.Name, i.ID)
 }
@@ -49,6 +49,26 @@ func TestColumnType_String(t *testing.T) {
 			fields: fields{Type: TypeTimestamp, FSP: 6},
 			want:   "timestamp(6)",
 		},
+		{
+			name:   "boolean",
+			fields: fields{Type: TypeBoolean},
+			want:   "boolean",
+		},
+		{
+			name:   "date",
+			fields: fields{Type: TypeDate},
+			want:   "date",
+		},
+		{
+			name:   "varbinary",
+			fields: fields{Type: TypeVarbinary},
+			want:   "varbinary",
+		},
+		{
+			name:   "json",
+			fields: fields{Type: TypeJSON},
+			want:   "json",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
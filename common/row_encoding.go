@@ -24,7 +24,7 @@ func encodeRowCol(row *Row, colIndex int, colType ColumnType, buffer []byte) ([]
 	} else {
 		buffer = append(buffer, 1)
 		switch colType.Type {
-		case TypeTinyInt, TypeInt, TypeBigInt:
+		case TypeTinyInt, TypeInt, TypeBigInt, TypeBoolean:
 			// We store as unsigned so convert signed to unsigned
 			valInt64 := row.GetInt64(colIndex)
 			buffer = AppendUint64ToBufferLE(buffer, uint64(valInt64))
@@ -41,13 +41,19 @@ func encodeRowCol(row *Row, colIndex int, colType ColumnType, buffer []byte) ([]
 		case TypeVarchar:
 			valString := row.GetString(colIndex)
 			buffer = AppendStringToBufferLE(buffer, valString)
-		case TypeTimestamp:
+		case TypeTimestamp, TypeDate:
 			valTimestamp := row.GetTimestamp(colIndex)
 			var err error
 			buffer, err = AppendTimestampToBuffer(buffer, valTimestamp)
 			if err != nil {
 				return nil, errors.WithStack(err)
 			}
+		case TypeVarbinary:
+			valBytes := row.GetBytes(colIndex)
+			buffer = AppendBytesToBufferLE(buffer, valBytes)
+		case TypeJSON:
+			valJSON := row.GetJSON(colIndex)
+			buffer = AppendJSONToBufferLE(buffer, valJSON)
 		default:
 			return nil, errors.Errorf("unexpected column type %d", colType)
 		}
@@ -72,7 +78,7 @@ func DecodeRowWithIgnoredCols(buffer []byte, colTypes []ColumnType, includeCol [
 		} else {
 			offset++
 			switch colType.Type {
-			case TypeTinyInt, TypeInt, TypeBigInt:
+			case TypeTinyInt, TypeInt, TypeBigInt, TypeBoolean:
 				var u uint64
 				u, offset = ReadUint64FromBufferLE(buffer, offset)
 				if include {
@@ -112,6 +118,30 @@ func DecodeRowWithIgnoredCols(buffer []byte, colTypes []ColumnType, includeCol [
 				if include {
 					rows.AppendTimestampToColumn(colIndex, val)
 				}
+			case TypeDate:
+				var (
+					val Timestamp
+					err error
+				)
+				val, offset, err = ReadDateFromBuffer(buffer, offset)
+				if err != nil {
+					return errors.WithStack(err)
+				}
+				if include {
+					rows.AppendTimestampToColumn(colIndex, val)
+				}
+			case TypeVarbinary:
+				var val []byte
+				val, offset = ReadBytesFromBufferLE(buffer, offset)
+				if include {
+					rows.AppendBytesToColumn(colIndex, val)
+				}
+			case TypeJSON:
+				var val JSON
+				val, offset = ReadJSONFromBufferLE(buffer, offset)
+				if include {
+					rows.AppendJSONToColumn(colIndex, val)
+				}
 			default:
 				return errors.Errorf("unexpected column type %d", colType)
 			}
@@ -92,6 +92,16 @@ func (r *Rows) AppendStringToColumn(colIndex int, val string) {
 	col.AppendString(val)
 }
 
+func (r *Rows) AppendBytesToColumn(colIndex int, val []byte) {
+	col := r.chunk.Column(colIndex)
+	col.AppendBytes(val)
+}
+
+func (r *Rows) AppendJSONToColumn(colIndex int, val JSON) {
+	col := r.chunk.Column(colIndex)
+	col.AppendJSON(val)
+}
+
 func (r *Rows) AppendTimestampToColumn(colIndex int, val Timestamp) {
 	r.chunk.AppendTime(colIndex, val)
 }
@@ -142,6 +152,14 @@ func (r *Row) GetTimestamp(colIndex int) Timestamp {
 	return r.tRow.GetTime(colIndex)
 }
 
+func (r *Row) GetBytes(colIndex int) []byte {
+	return r.tRow.GetBytes(colIndex)
+}
+
+func (r *Row) GetJSON(colIndex int) JSON {
+	return r.tRow.GetJSON(colIndex)
+}
+
 func (r *Row) ColCount() int {
 	return r.tRow.Len()
 }
@@ -156,6 +174,11 @@ func (r *Rows) checkCodec() {
 	}
 }
 
+// MemoryUsage returns the approximate number of bytes of memory used by the rows
+func (r *Rows) MemoryUsage() int64 {
+	return r.chunk.MemoryUsage()
+}
+
 func (r *Rows) AppendAll(other *Rows) {
 	for i := 0; i < other.RowCount(); i++ {
 		r.AppendRow(other.GetRow(i))
@@ -194,9 +217,18 @@ func (r *Row) String() string {
 			case TypeVarchar:
 				dec := r.GetString(j)
 				sb.WriteString(dec)
-			case TypeTimestamp:
+			case TypeTimestamp, TypeDate:
 				val := r.GetTimestamp(j)
 				sb.WriteString(val.String())
+			case TypeBoolean:
+				val := r.GetInt64(j)
+				sb.WriteString(strconv.FormatBool(val != 0))
+			case TypeVarbinary:
+				val := r.GetBytes(j)
+				sb.Write(val)
+			case TypeJSON:
+				val := r.GetJSON(j)
+				sb.WriteString(val.String())
 			default:
 				panic(fmt.Sprintf("unexpected col type %d", colType.Type))
 			}
@@ -13,5 +13,6 @@ const (
 	ToDeleteTableID             = 9
 	LocalConfigTableID          = 10
 	ForwardDedupTableID         = 11
+	AvroSchemaTableID           = 12
 	UserTableIDBase             = 1000
 )
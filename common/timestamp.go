@@ -14,15 +14,33 @@ type Timestamp = types.Time
 
 // NewTimestampFromString parses a Timestamp from a string in MySQL datetime format.
 func NewTimestampFromString(str string) Timestamp {
-	ts, err := types.ParseTimestamp(&stmtctx.StatementContext{
-		TimeZone: time.UTC,
-	}, str)
+	ts, err := ParseTimestamp(str)
 	if err != nil {
 		panic(err)
 	}
 	return ts
 }
 
+// ParseTimestamp parses a timestamp in UTC, returning an error if the string isn't a valid timestamp
+func ParseTimestamp(str string) (Timestamp, error) {
+	return types.ParseTimestamp(&stmtctx.StatementContext{
+		TimeZone: time.UTC,
+	}, str)
+}
+
+// ParseDate parses a date in MySQL date format. Dates are Timestamps of type date, with no time part.
+func ParseDate(str string) (Timestamp, error) {
+	return types.ParseDate(&stmtctx.StatementContext{
+		TimeZone: time.UTC,
+	}, str)
+}
+
+// NewDateFromGoTime returns the date of the time in UTC
+func NewDateFromGoTime(t time.Time) Timestamp {
+	t = t.UTC()
+	return types.NewTime(types.FromDate(t.Year(), int(t.Month()), t.Day(), 0, 0, 0, 0), mysql.TypeDate, 0)
+}
+
 func NewTimestampFromGoTime(t time.Time) Timestamp {
 	return types.NewTime(types.FromGoTime(t.UTC()), mysql.TypeTimestamp, 6)
 }
@@ -2,6 +2,7 @@ package conf
 
 import (
 	"fmt"
+	"net/url"
 	"time"
 
 	"github.com/squareup/pranadb/errors"
@@ -20,6 +21,7 @@ const (
 	DefaultRaftRTTMs                   = 100
 	DefaultRaftHeartbeatRTT            = 30
 	DefaultRaftElectionRTT             = 300
+	DefaultSortMemoryBudgetMB          = 64
 )
 
 type Config struct {
@@ -58,6 +60,8 @@ type Config struct {
 	RaftRTTMs                        int
 	RaftElectionRTT                  int
 	RaftHeartbeatRTT                 int
+	SortMemoryBudgetMB               int `help:"Approximate size in MB of the rows an ORDER BY in a pull query holds in memory before spilling them to disk" default:"64"`
+	AvroSchemaRegistryURL            string `help:"URL of the schema registry to fetch the Avro schemas used to decode Kafka messages from"`
 }
 
 func (c *Config) Validate() error { //nolint:gocyclo
@@ -166,6 +170,15 @@ func (c *Config) Validate() error { //nolint:gocyclo
 	if c.RaftElectionRTT < 2*c.RaftHeartbeatRTT {
 		return errors.NewInvalidConfigurationError("RaftElectionRTT must be > 2 * RaftHeartbeatRTT")
 	}
+	if c.SortMemoryBudgetMB < 1 {
+		return errors.NewInvalidConfigurationError("SortMemoryBudgetMB must be > 0")
+	}
+	if c.AvroSchemaRegistryURL != "" {
+		u, err := url.Parse(c.AvroSchemaRegistryURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return errors.NewInvalidConfigurationError("AvroSchemaRegistryURL must be an http or https URL")
+		}
+	}
 	return nil
 }
 
@@ -198,6 +211,7 @@ func NewDefaultConfig() *Config {
 		RaftRTTMs:                   DefaultRaftRTTMs,
 		RaftHeartbeatRTT:            DefaultRaftHeartbeatRTT,
 		RaftElectionRTT:             DefaultRaftElectionRTT,
+		SortMemoryBudgetMB:          DefaultSortMemoryBudgetMB,
 	}
 }
 
@@ -209,6 +223,7 @@ func NewTestConfig(fakeKafkaID int64) *Config {
 		RaftRTTMs:                   DefaultRaftRTTMs,
 		RaftHeartbeatRTT:            DefaultRaftHeartbeatRTT,
 		RaftElectionRTT:             DefaultRaftElectionRTT,
+		SortMemoryBudgetMB:          DefaultSortMemoryBudgetMB,
 		NodeID:                      0,
 		NumShards:                   10,
 		TestServer:                  true,
@@ -258,6 +258,24 @@ func invalidRaftElectionRTTTooSmall() Config {
 	return cnf
 }
 
+func invalidSortMemoryBudgetMBZero() Config {
+	cnf := confAllFields
+	cnf.SortMemoryBudgetMB = 0
+	return cnf
+}
+
+func invalidSortMemoryBudgetMBNegative() Config {
+	cnf := confAllFields
+	cnf.SortMemoryBudgetMB = -1
+	return cnf
+}
+
+func invalidAvroSchemaRegistryURL() Config {
+	cnf := confAllFields
+	cnf.AvroSchemaRegistryURL = "localhost:8081"
+	return cnf
+}
+
 var invalidConfigs = []configPair{
 	{"PDB0004 - Invalid configuration: NodeID must be >= 0", invalidNodeIDConf()},
 	{"PDB0004 - Invalid configuration: NumShards must be >= 1", invalidNumShardsConf()},
@@ -294,6 +312,9 @@ var invalidConfigs = []configPair{
 	{"PDB0004 - Invalid configuration: RaftElectionRTT must be > 0", invalidRaftElectionRTTZero()},
 	{"PDB0004 - Invalid configuration: RaftElectionRTT must be > 0", invalidRaftElectionRTTNegative()},
 	{"PDB0004 - Invalid configuration: RaftElectionRTT must be > 2 * RaftHeartbeatRTT", invalidRaftElectionRTTTooSmall()},
+	{"PDB0004 - Invalid configuration: SortMemoryBudgetMB must be > 0", invalidSortMemoryBudgetMBZero()},
+	{"PDB0004 - Invalid configuration: SortMemoryBudgetMB must be > 0", invalidSortMemoryBudgetMBNegative()},
+	{"PDB0004 - Invalid configuration: AvroSchemaRegistryURL must be an http or https URL", invalidAvroSchemaRegistryURL()},
 }
 
 func TestValidate(t *testing.T) {
@@ -338,4 +359,6 @@ var confAllFields = Config{
 	RaftRTTMs:                   100,
 	RaftHeartbeatRTT:            10,
 	RaftElectionRTT:             100,
+	SortMemoryBudgetMB:          16,
+	AvroSchemaRegistryURL:       "http://localhost:8081",
 }
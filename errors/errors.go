@@ -36,6 +36,22 @@ const (
 	UnknownPerfCommand
 
 	ValueOutOfRange
+
+	UnknownSink
+	SinkAlreadyExists
+
+	InvalidResumeToken
+	SubscriptionFailed
+
+	UnknownTable
+	TableAlreadyExists
+	TableHasChildren
+
+	TooManyPreparedStatements
+
+	ResumeTokenExpired
+
+	StatementNotAppliedToAllShards
 )
 
 func NewInternalError(seq int64) PranaError {
@@ -110,6 +126,46 @@ func NewValueOutOfRangeError(msg string) PranaError {
 	return NewPranaErrorf(ValueOutOfRange, "Value out of range. %s", msg)
 }
 
+func NewUnknownSinkError(schemaName string, sinkName string) PranaError {
+	return NewPranaErrorf(UnknownSink, "Unknown sink: %s.%s", schemaName, sinkName)
+}
+
+func NewSinkAlreadyExistsError(schemaName string, sinkName string) PranaError {
+	return NewPranaErrorf(SinkAlreadyExists, "Sink already exists: %s.%s", schemaName, sinkName)
+}
+
+func NewInvalidResumeTokenError() PranaError {
+	return NewPranaErrorf(InvalidResumeToken, "Cannot resume subscription, the resume token is invalid")
+}
+
+func NewResumeTokenExpiredError() PranaError {
+	return NewPranaErrorf(ResumeTokenExpired, "Cannot resume subscription, the changes after the resume token are no longer available. Subscribe again without a resume token")
+}
+
+func NewSubscriptionFailedError(msg string) PranaError {
+	return NewPranaErrorf(SubscriptionFailed, "Subscription failed. %s", msg)
+}
+
+func NewUnknownTableError(schemaName string, tableName string) PranaError {
+	return NewPranaErrorf(UnknownTable, "Unknown table: %s.%s", schemaName, tableName)
+}
+
+func NewTableAlreadyExistsError(schemaName string, tableName string) PranaError {
+	return NewPranaErrorf(TableAlreadyExists, "Table already exists: %s.%s", schemaName, tableName)
+}
+
+func NewTableHasChildrenError(schemaName string, tableName string, childMVs []string) PranaError {
+	return NewPranaErrorf(TableHasChildren, "Cannot drop table %s.%s it has the following children %s", schemaName, tableName, getChildString(schemaName, childMVs))
+}
+
+func NewTooManyPreparedStatementsError(maxPreparedStatements int) PranaError {
+	return NewPranaErrorf(TooManyPreparedStatements, "Too many prepared statements on connection, the maximum is %d. Close prepared statements which are no longer needed", maxPreparedStatements)
+}
+
+func NewStatementNotAppliedToAllShardsError() PranaError {
+	return NewPranaErrorf(StatementNotAppliedToAllShards, "The statement was not applied to every shard exactly once as shards moved between nodes while it was executing. Execute it again")
+}
+
 func getChildString(schemaName string, childMVs []string) string {
 	sort.Strings(childMVs) // Need to sort to give deterministic results
 	sb := strings.Builder{}
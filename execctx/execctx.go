@@ -1,6 +1,8 @@
 package execctx
 
 import (
+	"sync"
+
 	"github.com/squareup/pranadb/cluster"
 	"github.com/squareup/pranadb/common"
 	"github.com/squareup/pranadb/parplan"
@@ -12,6 +14,8 @@ type ExecutionContext struct {
 	planner      *parplan.Planner
 	QueryInfo    *cluster.QueryExecutionInfo
 	CurrentQuery interface{} // typed as interface{} to avoid circular dependency with pull
+	closersLock  sync.Mutex
+	closers      []func()
 }
 
 func NewExecutionContext(id string, schema *common.Schema) *ExecutionContext {
@@ -22,6 +26,26 @@ func NewExecutionContext(id string, schema *common.Schema) *ExecutionContext {
 	}
 }
 
+// AddCloser registers a function which releases a resource held by the execution of the query, e.g. the files a sort
+// has spilled to disk. It's called when the context is closed.
+func (s *ExecutionContext) AddCloser(closer func()) {
+	s.closersLock.Lock()
+	defer s.closersLock.Unlock()
+	s.closers = append(s.closers, closer)
+}
+
+// Close releases the resources held by the execution of the query. A query must be closed whether it completes, fails,
+// or is abandoned before all its rows have been fetched. The context can be used again for another execution.
+func (s *ExecutionContext) Close() {
+	s.closersLock.Lock()
+	closers := s.closers
+	s.closers = nil
+	s.closersLock.Unlock()
+	for i := len(closers) - 1; i >= 0; i-- {
+		closers[i]()
+	}
+}
+
 func (s *ExecutionContext) Planner() *parplan.Planner {
 	if s.planner == nil {
 		s.planner = parplan.NewPlanner(s.Schema)
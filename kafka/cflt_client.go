@@ -14,6 +14,8 @@ import (
 
 // Kafka Message Provider implementation that uses the standard Confluent golang client
 
+const metadataTimeoutMs = 10000
+
 func NewMessageProviderFactory(topicName string, props map[string]string, groupID string) MessageProviderFactory {
 	return &ConfluentMessageProviderFactory{
 		topicName: topicName,
@@ -120,6 +122,26 @@ func (cmp *ConfluentMessageProvider) CommitOffsets(offsetsMap map[int32]int64) e
 	return errors.WithStack(err)
 }
 
+func (cmp *ConfluentMessageProvider) NumPartitions() (int, error) {
+	cmp.lock.Lock()
+	defer cmp.lock.Unlock()
+	if cmp.consumer == nil {
+		return 0, errors.Error("consumer not started")
+	}
+	metadata, err := cmp.consumer.GetMetadata(&cmp.topicName, false, metadataTimeoutMs)
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+	topic, ok := metadata.Topics[cmp.topicName]
+	if !ok {
+		return 0, errors.Errorf("no metadata for topic %s", cmp.topicName)
+	}
+	if topic.Error.Code() != kafka.ErrNoError {
+		return 0, errors.WithStack(topic.Error)
+	}
+	return len(topic.Partitions), nil
+}
+
 func (cmp *ConfluentMessageProvider) Stop() error {
 	return nil
 }
@@ -158,3 +180,84 @@ func (cmp *ConfluentMessageProvider) Start() error {
 	cmp.consumer = consumer
 	return nil
 }
+
+// Kafka Message Producer implementation that uses the standard Confluent golang client
+
+func NewMessageProducer(topicName string, props map[string]string) MessageProducer {
+	return &ConfluentMessageProducer{
+		topicName: topicName,
+		props:     props,
+	}
+}
+
+type ConfluentMessageProducer struct {
+	lock      sync.Mutex
+	producer  *kafka.Producer
+	topicName string
+	props     map[string]string
+}
+
+var _ MessageProducer = &ConfluentMessageProducer{}
+
+func (cmp *ConfluentMessageProducer) SendMessages(messages []*Message) error {
+	cmp.lock.Lock()
+	defer cmp.lock.Unlock()
+	if cmp.producer == nil {
+		return errors.Error("producer not started")
+	}
+	deliveryChan := make(chan kafka.Event, len(messages))
+	for _, msg := range messages {
+		kmsg := &kafka.Message{
+			TopicPartition: kafka.TopicPartition{
+				Topic:     &cmp.topicName,
+				Partition: kafka.PartitionAny,
+			},
+			Key:   msg.Key,
+			Value: msg.Value,
+		}
+		if err := cmp.producer.Produce(kmsg, deliveryChan); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	for range messages {
+		ev := <-deliveryChan
+		kmsg, ok := ev.(*kafka.Message)
+		if !ok {
+			return errors.Errorf("unexpected delivery event %+v", ev)
+		}
+		if kmsg.TopicPartition.Error != nil {
+			return errors.WithStack(kmsg.TopicPartition.Error)
+		}
+	}
+	return nil
+}
+
+func (cmp *ConfluentMessageProducer) Start() error {
+	cmp.lock.Lock()
+	defer cmp.lock.Unlock()
+	cm := &kafka.ConfigMap{
+		"acks": "all",
+	}
+	for k, v := range cmp.props {
+		if err := cm.SetKey(k, v); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	producer, err := kafka.NewProducer(cm)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	cmp.producer = producer
+	return nil
+}
+
+func (cmp *ConfluentMessageProducer) Stop() error {
+	cmp.lock.Lock()
+	defer cmp.lock.Unlock()
+	if cmp.producer == nil {
+		return nil
+	}
+	cmp.producer.Close()
+	cmp.producer = nil
+	return nil
+}
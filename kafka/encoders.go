@@ -5,9 +5,11 @@ import (
 	"fmt"
 	"reflect"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/golang/protobuf/proto"
+	"github.com/squareup/pranadb/avrolib"
 	"github.com/squareup/pranadb/common"
 	"github.com/squareup/pranadb/errors"
 	"github.com/squareup/pranadb/protolib"
@@ -20,6 +22,43 @@ type MessageEncoder interface {
 	EncodeMessage(row *common.Row, colTypes []common.ColumnType, keyCols []int, timestamp time.Time) (*Message, error)
 }
 
+// NewSinkMessageEncoder returns the encoder for the messages of a sink of rows with the column types and key columns.
+// The value must be JSON. Key encodings other than JSON need a single key column of the type the encoder takes.
+func NewSinkMessageEncoder(keyEncoding common.KafkaEncoding, valueEncoding common.KafkaEncoding, colTypes []common.ColumnType, keyCols []int) (MessageEncoder, error) {
+	if valueEncoding.Encoding != common.EncodingJSON {
+		return nil, errors.NewPranaErrorf(errors.UnknownTopicEncoding, "Unsupported value encoding for a sink %s", valueEncoding.Encoding)
+	}
+	var encoder MessageEncoder
+	var keyColType common.ColumnType
+	switch keyEncoding.Encoding {
+	case common.EncodingJSON:
+		return &JSONKeyJSONValueEncoder{}, nil
+	case common.EncodingStringBytes:
+		encoder, keyColType = &StringKeyTLJSONValueEncoder{}, common.VarcharColumnType
+	case common.EncodingInt64BE:
+		encoder, keyColType = &Int64BEKeyTLJSONValueEncoder{}, common.BigIntColumnType
+	case common.EncodingInt32BE:
+		encoder, keyColType = &Int32BEKeyTLJSONValueEncoder{}, common.IntColumnType
+	case common.EncodingInt16BE:
+		encoder, keyColType = &Int16BEKeyTLJSONValueEncoder{}, common.IntColumnType
+	case common.EncodingFloat64BE:
+		encoder, keyColType = &Float64BEKeyTLJSONValueEncoder{}, common.DoubleColumnType
+	case common.EncodingFloat32BE:
+		encoder, keyColType = &Float32BEKeyTLJSONValueEncoder{}, common.DoubleColumnType
+	default:
+		return nil, errors.NewPranaErrorf(errors.UnknownTopicEncoding, "Unsupported key encoding for a sink %s", keyEncoding.Encoding)
+	}
+	if len(keyCols) != 1 {
+		return nil, errors.NewPranaErrorf(errors.UnknownTopicEncoding,
+			"Key encoding %s requires a single key column but there are %d", keyEncoding.Encoding, len(keyCols))
+	}
+	if colTypes[keyCols[0]] != keyColType {
+		return nil, errors.NewPranaErrorf(errors.UnknownTopicEncoding, "Key encoding %s requires a key column of type %s but it is %s",
+			keyEncoding.Encoding, keyColType.String(), colTypes[keyCols[0]].String())
+	}
+	return encoder, nil
+}
+
 // JSONKeyJSONValueEncoder encodes as top level JSON key, top level JSON value, no headers
 type JSONKeyJSONValueEncoder struct {
 }
@@ -56,6 +95,31 @@ func (s *JSONKeyJSONValueEncoder) EncodeMessage(row *common.Row, colTypes []comm
 	return message, nil
 }
 
+// JSONKeyTombstoneEncoder encodes as top level JSON key, null value, no headers
+type JSONKeyTombstoneEncoder struct {
+}
+
+func (s *JSONKeyTombstoneEncoder) Name() string {
+	return "JSONKeyTombstoneEncoder"
+}
+
+func (s *JSONKeyTombstoneEncoder) EncodeMessage(row *common.Row, colTypes []common.ColumnType, keyCols []int, timestamp time.Time) (*Message, error) {
+	keyMap := map[string]interface{}{}
+	for i, keyCol := range keyCols {
+		colType := colTypes[keyCol]
+		colVal := checkType(getColVal(keyCol, colType, row))
+		keyMap[fmt.Sprintf("k%d", i)] = colVal
+	}
+	keyBytes, err := json.Marshal(keyMap)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return &Message{
+		TimeStamp: timestamp,
+		Key:       keyBytes,
+	}, nil
+}
+
 // StringKeyTLJSONValueEncoder encodes as string key, top level JSON value, no headers
 type StringKeyTLJSONValueEncoder struct {
 }
@@ -86,6 +150,52 @@ func (s *StringKeyTLJSONValueEncoder) EncodeMessage(row *common.Row, colTypes []
 	}, nil
 }
 
+// StringKeyCSVValueEncoder encodes as string key, CSV value with a header, no headers. The fields of the value are all
+// the columns other than the key, named v0 to vN after the index of their column.
+type StringKeyCSVValueEncoder struct {
+}
+
+func (s *StringKeyCSVValueEncoder) Name() string {
+	return "StringKeyCSVValueEncoder"
+}
+
+func (s *StringKeyCSVValueEncoder) EncodeMessage(row *common.Row, colTypes []common.ColumnType, keyCols []int, timestamp time.Time) (*Message, error) {
+	if len(keyCols) != 1 {
+		return nil, errors.Error("must be only one pk col for binary key encoding")
+	}
+	keyColIndex := keyCols[0]
+	keyColType := colTypes[keyColIndex]
+	if keyColType != common.VarcharColumnType {
+		return nil, errors.Error("Key is not a varchar column")
+	}
+	keyBytes := []byte(row.GetString(keyColIndex))
+
+	var header, record []string
+	for i, colType := range colTypes {
+		if i == keyColIndex {
+			continue
+		}
+		header = append(header, fmt.Sprintf("v%d", i))
+		colVal := getColVal(i, colType, row)
+		if colVal == nil {
+			// An unquoted empty field is null
+			record = append(record, "")
+			continue
+		}
+		field := fmt.Sprintf("%v", colVal)
+		if field == "" || strings.ContainsAny(field, ",\"\r\n") {
+			field = `"` + strings.ReplaceAll(field, `"`, `""`) + `"`
+		}
+		record = append(record, field)
+	}
+	valBytes := []byte(strings.Join(header, ",") + "\n" + strings.Join(record, ",") + "\n")
+
+	return &Message{
+		Key:   keyBytes,
+		Value: valBytes,
+	}, nil
+}
+
 // Int64BEKeyTLJSONValueEncoder encodes as int64BE key, top level JSON value, no headers
 type Int64BEKeyTLJSONValueEncoder struct {
 }
@@ -401,6 +511,91 @@ func (e *StringKeyProtobufValueEncoder) encodeProtobufValue(colTypes []common.Co
 	return proto.Marshal(msg)
 }
 
+func NewStringKeyAvroValueEncoderFactory(registry avrolib.Resolver) func(options string) (MessageEncoder, error) {
+	return func(options string) (MessageEncoder, error) {
+		return NewStringKeyAvroValueEncoder(registry, options)
+	}
+}
+
+func NewStringKeyAvroValueEncoder(registry avrolib.Resolver, options string) (MessageEncoder, error) {
+	id, err := strconv.ParseInt(options, 10, 32)
+	if err != nil {
+		return nil, errors.Errorf("invalid avro schema id %q", options)
+	}
+	schema, err := registry.FindSchemaByID(int32(id))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return &StringKeyAvroValueEncoder{schema: schema}, nil
+}
+
+// StringKeyAvroValueEncoder is an encoder that translates each row to an Avro record in the schema registry wire
+// format. Columns 0 to N correspond to the fields of the record in order. Like StringKeyProtobufValueEncoder, the key
+// also ends up as a field in the value.
+type StringKeyAvroValueEncoder struct {
+	schema *avrolib.Schema
+}
+
+func (e *StringKeyAvroValueEncoder) Name() string {
+	return "StringKeyAvroValueEncoder"
+}
+
+func (e *StringKeyAvroValueEncoder) EncodeMessage(row *common.Row, colTypes []common.ColumnType, keyCols []int, timestamp time.Time) (*Message, error) {
+	if len(keyCols) != 1 {
+		return nil, errors.Error("must be only one pk col for binary key encoding")
+	}
+	keyColIndex := keyCols[0]
+	keyColType := colTypes[keyColIndex]
+	if keyColType != common.VarcharColumnType {
+		return nil, errors.Error("Key is not a varchar column")
+	}
+	keyBytes := []byte(row.GetString(keyColIndex))
+
+	fieldNames := e.schema.FieldNames()
+	if len(fieldNames) < len(colTypes) {
+		return nil, errors.Errorf("avro schema %d has %d fields but row has %d columns", e.schema.ID, len(fieldNames),
+			len(colTypes))
+	}
+	record := make(map[string]interface{}, len(colTypes))
+	for i, colType := range colTypes {
+		colVal, err := avroColVal(i, colType, row)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		record[fieldNames[i]] = colVal
+	}
+	valBytes := avrolib.AppendWireFormatHeader(nil, e.schema.ID)
+	valBytes, err := e.schema.Encode(valBytes, record)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return &Message{
+		Key:   keyBytes,
+		Value: valBytes,
+	}, nil
+}
+
+func avroColVal(colIndex int, colType common.ColumnType, row *common.Row) (interface{}, error) {
+	if row.IsNull(colIndex) {
+		return nil, nil
+	}
+	switch colType.Type {
+	case common.TypeTimestamp, common.TypeDate:
+		// Encoded with the timestamp and date logical types
+		ts := row.GetTimestamp(colIndex)
+		gt, err := ts.GoTime(time.UTC)
+		return gt, errors.WithStack(err)
+	case common.TypeJSON:
+		// Encoded as a record, array or map with the same fields or elements as the JSON document
+		var v interface{}
+		err := json.Unmarshal([]byte(row.GetJSON(colIndex).String()), &v)
+		return v, errors.WithStack(err)
+	default:
+		return getColVal(colIndex, colType, row), nil
+	}
+}
+
 func protoSet(msg *dynamicpb.Message, fd pref.FieldDescriptor, v interface{}) {
 	// coerce types when necessary and possible. If not possible, let the protoreflect library panic
 	// with a nice message
@@ -428,6 +623,8 @@ func protoSet(msg *dynamicpb.Message, fd pref.FieldDescriptor, v interface{}) {
 		if fd.Kind() == pref.BytesKind {
 			v = []byte(t)
 		}
+	case bool:
+		// set as is
 	default:
 		panic(fmt.Sprintf("unknown type %s", reflect.TypeOf(v)))
 	}
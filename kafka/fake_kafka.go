@@ -164,6 +164,17 @@ func (t *Topic) push(message *Message) error {
 	return nil
 }
 
+// GetMessages returns all the messages in the topic, ordered by partition then offset
+func (t *Topic) GetMessages() []*Message {
+	var messages []*Message
+	for _, part := range t.partitions {
+		part.lock.Lock()
+		messages = append(messages, part.messages...)
+		part.lock.Unlock()
+	}
+	return messages
+}
+
 func (t *Topic) getGroup(groupID string) (*Group, bool) {
 	v, ok := t.groups.Load(groupID)
 	if !ok {
@@ -375,9 +386,7 @@ func (g *Group) rebalance() error {
 	if len(g.subscribers) == 0 {
 		return nil
 	}
-	if len(g.subscribers) > len(g.topic.partitions) {
-		return errors.Error("too many subscribers")
-	}
+	// As with Kafka, any subscribers beyond the number of partitions are assigned none
 	for _, subscriber := range g.subscribers {
 		subscriber.partitions = []*Partition{}
 	}
@@ -580,6 +589,10 @@ func (f *FakeMessageProvider) CommitOffsets(offsets map[int32]int64) error {
 	return f.subscriber.commitOffsets(offsets)
 }
 
+func (f *FakeMessageProvider) NumPartitions() (int, error) {
+	return len(f.topic.partitions), nil
+}
+
 func (f *FakeMessageProvider) Start() error {
 	f.lock.Lock()
 	defer f.lock.Unlock()
@@ -599,3 +612,51 @@ func (f *FakeMessageProvider) Stop() error {
 func (f *FakeMessageProvider) Close() error {
 	return f.subscriber.Unsubscribe()
 }
+
+func NewFakeMessageProducer(topicName string, props map[string]string) (MessageProducer, error) {
+	sFakeKafkaID, ok := props[FakeKafkaIDPropName]
+	if !ok {
+		return nil, errors.Error("no fakeKafkaID property in broker configuration")
+	}
+	fakeKafkaID, err := strconv.ParseInt(sFakeKafkaID, 10, 64)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	fk, ok := GetFakeKafka(fakeKafkaID)
+	if !ok {
+		return nil, errors.Errorf("cannot find fake kafka with id %d", fakeKafkaID)
+	}
+	return &FakeMessageProducer{
+		fk:        fk,
+		topicName: topicName,
+	}, nil
+}
+
+type FakeMessageProducer struct {
+	fk        *FakeKafka
+	topicName string
+}
+
+func (f *FakeMessageProducer) SendMessages(messages []*Message) error {
+	for _, msg := range messages {
+		// We copy the message as the partition info is set on it when it's pushed
+		m := &Message{
+			TimeStamp: msg.TimeStamp,
+			Key:       msg.Key,
+			Value:     msg.Value,
+			Headers:   msg.Headers,
+		}
+		if err := f.fk.IngestMessage(f.topicName, m); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	return nil
+}
+
+func (f *FakeMessageProducer) Start() error {
+	return nil
+}
+
+func (f *FakeMessageProducer) Stop() error {
+	return nil
+}
@@ -74,6 +74,42 @@ func TestCreateDeleteTopic(t *testing.T) {
 	require.Equal(t, 0, len(names))
 }
 
+func TestFakeMessageProducer(t *testing.T) {
+	fk := NewFakeKafka()
+	topic, err := fk.CreateTopic("topic1", 10)
+	require.NoError(t, err)
+
+	producer, err := NewFakeMessageProducer("topic1", map[string]string{FakeKafkaIDPropName: fmt.Sprintf("%d", fk.ID)})
+	require.NoError(t, err)
+	require.NoError(t, producer.Start())
+
+	var messages []*Message
+	for i := 0; i < 10; i++ {
+		messages = append(messages, &Message{
+			Key:   []byte(fmt.Sprintf("key-%d", i%3)),
+			Value: []byte(fmt.Sprintf("value-%d", i)),
+		})
+	}
+	// A tombstone
+	messages = append(messages, &Message{Key: []byte("key-0")})
+	require.NoError(t, producer.SendMessages(messages))
+	require.NoError(t, producer.Stop())
+
+	received := topic.GetMessages()
+	require.Equal(t, len(messages), len(received))
+	// Messages with the same key go to the same partition, in the order they were sent
+	lastValues := map[string][]byte{}
+	for _, msg := range received {
+		lastValues[string(msg.Key)] = msg.Value
+	}
+	require.Nil(t, lastValues["key-0"])
+	require.Equal(t, "value-7", string(lastValues["key-1"]))
+	require.Equal(t, "value-8", string(lastValues["key-2"]))
+
+	_, err = NewFakeMessageProducer("topic1", map[string]string{})
+	require.Error(t, err)
+}
+
 func TestIngestConsumeOneSubscriber(t *testing.T) {
 	fk := NewFakeKafka()
 	parts := 10
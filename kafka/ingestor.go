@@ -19,11 +19,16 @@ func IngestRows(f *FakeKafka, sourceInfo *common.SourceInfo, colTypes []common.C
 	if !ok {
 		return errors.Errorf("cannot find topic %s", topicName)
 	}
+	keyCols := sourceInfo.PrimaryKeyCols
+	if sourceInfo.AppendOnly {
+		// The key columns of an append-only source are hidden and don't come from the message
+		keyCols = nil
+	}
 	timestamp := timestampBase
 	for i := 0; i < rows.RowCount(); i++ {
 		row := rows.GetRow(i)
 		// We give each
-		if err := IngestRow(topic, &row, colTypes, sourceInfo.PrimaryKeyCols, encoder, timestamp); err != nil {
+		if err := IngestRow(topic, &row, colTypes, keyCols, encoder, timestamp); err != nil {
 			return errors.WithStack(err)
 		}
 		timestamp = timestamp.Add(1 * time.Microsecond)
@@ -61,6 +66,17 @@ func getColVal(colIndex int, colType common.ColumnType, row *common.Row) interfa
 		// Get as ISO-8601 string
 		ct := ts.CoreTime()
 		colVal = fmt.Sprintf("%d-%02d-%02d %02d:%02d:%02d.%06d", ct.Year(), ct.Month(), ct.Day(), ct.Hour(), ct.Minute(), ct.Second(), ct.Microsecond())
+	case common.TypeBoolean:
+		colVal = row.GetInt64(colIndex) != 0
+	case common.TypeDate:
+		ct := row.GetTimestamp(colIndex).CoreTime()
+		colVal = fmt.Sprintf("%d-%02d-%02d", ct.Year(), ct.Month(), ct.Day())
+	case common.TypeVarbinary:
+		// Sources read a string value into a varbinary column as its bytes, so we encode it the same way
+		colVal = string(row.GetBytes(colIndex))
+	case common.TypeJSON:
+		// Encoded as the JSON document itself
+		colVal = row.GetJSON(colIndex)
 	case common.TypeUnknown:
 		panic("unknown type")
 	}
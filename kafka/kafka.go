@@ -15,6 +15,8 @@ type MessageProvider interface {
 	Start() error
 	Close() error
 	SetRebalanceCallback(callback RebalanceCallback)
+	// NumPartitions returns the number of partitions of the topic. The provider must have been started.
+	NumPartitions() (int, error)
 }
 
 type Message struct {
@@ -36,3 +38,11 @@ type PartInfo struct {
 	PartitionID int32
 	Offset      int64
 }
+
+// MessageProducer sends messages to a Kafka topic. SendMessages only returns once all the messages have been
+// acknowledged by the broker
+type MessageProducer interface {
+	SendMessages(messages []*Message) error
+	Start() error
+	Stop() error
+}
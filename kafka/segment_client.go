@@ -147,6 +147,25 @@ func (smp *SegmentKafkaMessageProvider) Start() error {
 	return nil
 }
 
+func (smp *SegmentKafkaMessageProvider) NumPartitions() (int, error) {
+	smp.lock.Lock()
+	brokers := smp.reader.Config().Brokers
+	smp.lock.Unlock()
+	if len(brokers) == 0 {
+		return 0, errors.Error("no brokers configured")
+	}
+	conn, err := kafka.Dial("tcp", brokers[0])
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+	defer conn.Close() //nolint:errcheck
+	partitions, err := conn.ReadPartitions(smp.topicName)
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+	return len(partitions), nil
+}
+
 func setProperty(cfg *kafka.ReaderConfig, k, v string) error {
 	switch k {
 	case "bootstrap.servers":
@@ -156,3 +175,68 @@ func setProperty(cfg *kafka.ReaderConfig, k, v string) error {
 	}
 	return nil
 }
+
+// Kafka Message Producer implementation that uses the SegmentIO golang client
+
+func NewMessageProducer(topicName string, props map[string]string) MessageProducer {
+	return &SegmentKafkaMessageProducer{
+		topicName: topicName,
+		props:     props,
+	}
+}
+
+type SegmentKafkaMessageProducer struct {
+	lock      sync.Mutex
+	writer    *kafka.Writer
+	topicName string
+	props     map[string]string
+}
+
+var _ MessageProducer = &SegmentKafkaMessageProducer{}
+
+func (smp *SegmentKafkaMessageProducer) SendMessages(messages []*Message) error {
+	smp.lock.Lock()
+	defer smp.lock.Unlock()
+	if smp.writer == nil {
+		return errors.Error("producer not started")
+	}
+	kmsgs := make([]kafka.Message, len(messages))
+	for i, msg := range messages {
+		kmsgs[i] = kafka.Message{
+			Key:   msg.Key,
+			Value: msg.Value,
+		}
+	}
+	return errors.WithStack(smp.writer.WriteMessages(context.Background(), kmsgs...))
+}
+
+func (smp *SegmentKafkaMessageProducer) Start() error {
+	smp.lock.Lock()
+	defer smp.lock.Unlock()
+	writer := &kafka.Writer{
+		Topic:        smp.topicName,
+		Balancer:     &kafka.Hash{},
+		RequiredAcks: kafka.RequireAll,
+	}
+	for k, v := range smp.props {
+		switch k {
+		case "bootstrap.servers":
+			writer.Addr = kafka.TCP(strings.Split(v, ",")...)
+		default:
+			return errors.NewInvalidConfigurationError(fmt.Sprintf("unsupported segmentio/kafka-go client option: %s", v))
+		}
+	}
+	smp.writer = writer
+	return nil
+}
+
+func (smp *SegmentKafkaMessageProducer) Stop() error {
+	smp.lock.Lock()
+	defer smp.lock.Unlock()
+	if smp.writer == nil {
+		return nil
+	}
+	err := smp.writer.Close()
+	smp.writer = nil
+	return errors.WithStack(err)
+}
@@ -16,9 +16,10 @@ const (
 	// SystemSchemaName is the name of the schema that houses system tables, similar to mysql's information_schema.
 	SystemSchemaName = "sys"
 	// TableDefTableName is the name of the table that holds all table definitions.
-	TableDefTableName = "tables"
-	IndexDefTableName = "indexes"
-	ProtobufTableName = "protos"
+	TableDefTableName   = "tables"
+	IndexDefTableName   = "indexes"
+	ProtobufTableName   = "protos"
+	AvroSchemaTableName = "avro_schemas"
 )
 
 // TableDefTableInfo is a static definition of the table schema for the table schema table.
@@ -68,6 +69,19 @@ var ProtobufTableInfo = &common.MetaTableInfo{TableInfo: &common.TableInfo{
 	},
 }}
 
+// AvroSchemaTableInfo is a static definition of the table schema for the registered Avro schemas table.
+var AvroSchemaTableInfo = &common.MetaTableInfo{TableInfo: &common.TableInfo{
+	ID:             common.AvroSchemaTableID,
+	SchemaName:     SystemSchemaName,
+	Name:           AvroSchemaTableName,
+	PrimaryKeyCols: []int{0},
+	ColumnNames:    []string{"id", "avro_schema"},
+	ColumnTypes: []common.ColumnType{
+		common.BigIntColumnType,
+		common.VarcharColumnType,
+	},
+}}
+
 type Controller struct {
 	lock     sync.RWMutex
 	schemas  map[string]*common.Schema
@@ -141,6 +155,21 @@ func (c *Controller) GetSource(schemaName string, name string) (*common.SourceIn
 	return source, ok
 }
 
+func (c *Controller) GetUserTable(schemaName string, name string) (*common.UserTableInfo, bool) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	schema, ok := c.schemas[schemaName]
+	if !ok {
+		return nil, false
+	}
+	tb, ok := schema.GetTable(name)
+	if !ok {
+		return nil, false
+	}
+	userTable, ok := tb.(*common.UserTableInfo)
+	return userTable, ok
+}
+
 func (c *Controller) GetIndex(schemaName string, tableName string, indexName string) (*common.IndexInfo, bool) {
 	c.lock.RLock()
 	defer c.lock.RUnlock()
@@ -160,6 +189,16 @@ func (c *Controller) GetIndex(schemaName string, tableName string, indexName str
 	return index, ok
 }
 
+func (c *Controller) GetSink(schemaName string, name string) (*common.SinkInfo, bool) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	schema, ok := c.schemas[schemaName]
+	if !ok {
+		return nil, false
+	}
+	return schema.GetSink(name)
+}
+
 func (c *Controller) GetSchemaNames() []string {
 	c.lock.RLock()
 	defer c.lock.RUnlock()
@@ -207,6 +246,9 @@ func (c *Controller) existsTable(schema *common.Schema, name string) error {
 	if _, ok := schema.GetTable(name); ok {
 		return errors.Errorf("table with Name %s already exists in Schema %s", name, schema.Name)
 	}
+	if _, ok := schema.GetSink(name); ok {
+		return errors.Errorf("sink with Name %s already exists in Schema %s", name, schema.Name)
+	}
 	return nil
 }
 
@@ -282,6 +324,34 @@ func (c *Controller) PersistSource(sourceInfo *common.SourceInfo) error {
 	return c.cluster.WriteBatch(wb)
 }
 
+// RegisterUserTable adds a user table to the metadata controller, making it active. It does not persist it
+func (c *Controller) RegisterUserTable(tableInfo *common.UserTableInfo) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	log.Debugf("Registering table %s with id %d", tableInfo.Name, tableInfo.ID)
+	if err := c.checkTableID(tableInfo.ID); err != nil {
+		return errors.WithStack(err)
+	}
+	schema := c.getOrCreateSchema(tableInfo.SchemaName)
+	err := c.existsTable(schema, tableInfo.Name)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	schema.PutTable(tableInfo.Name, tableInfo)
+	c.tableIDs[tableInfo.ID] = struct{}{}
+	return nil
+}
+
+func (c *Controller) PersistUserTable(tableInfo *common.UserTableInfo) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	wb := cluster.NewWriteBatch(cluster.SystemSchemaShardID)
+	if err := table.Upsert(TableDefTableInfo.TableInfo, EncodeUserTableInfoToRow(tableInfo), wb); err != nil {
+		return errors.WithStack(err)
+	}
+	return c.cluster.WriteBatch(wb)
+}
+
 func (c *Controller) PersistMaterializedView(mvInfo *common.MaterializedViewInfo, internalTables []*common.InternalTableInfo) error {
 	c.lock.Lock()
 	defer c.lock.Unlock()
@@ -373,6 +443,31 @@ func (c *Controller) DeleteSource(sourceID uint64) error {
 	return c.deleteTableWithID(sourceID)
 }
 
+// UnregisterUserTable removes the user table from memory but does not delete it from storage
+func (c *Controller) UnregisterUserTable(schemaName string, tableName string) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	schema, ok := c.schemas[schemaName]
+	if !ok {
+		return errors.Errorf("no such schema %s", schemaName)
+	}
+	tbl, ok := schema.GetTable(tableName)
+	if !ok {
+		return errors.Errorf("no such table %s", tableName)
+	}
+	if _, ok := tbl.(*common.UserTableInfo); !ok {
+		return errors.Errorf("%s is not a user table", tbl)
+	}
+	delete(c.tableIDs, tbl.GetTableInfo().ID)
+	schema.DeleteTable(tableName)
+	c.DeleteSchemaIfEmpty(schema)
+	return nil
+}
+
+func (c *Controller) DeleteUserTable(tableID uint64) error {
+	return c.deleteTableWithID(tableID)
+}
+
 func (c *Controller) DeleteMaterializedView(mvInfo *common.MaterializedViewInfo, internalTableIDs []*common.InternalTableInfo) error {
 	if err := c.deleteTableWithID(mvInfo.ID); err != nil {
 		return errors.WithStack(err)
@@ -416,6 +511,55 @@ func (c *Controller) UnregisterMaterializedView(schemaName string, mvName string
 	return nil
 }
 
+// RegisterSink adds a sink to the metadata controller, making it active. It does not persist it
+func (c *Controller) RegisterSink(sinkInfo *common.SinkInfo) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	log.Debugf("Registering sink %s with id %d", sinkInfo.Name, sinkInfo.ID)
+	if err := c.checkTableID(sinkInfo.ID); err != nil {
+		return errors.WithStack(err)
+	}
+	schema := c.getOrCreateSchema(sinkInfo.SchemaName)
+	err := c.existsTable(schema, sinkInfo.Name)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	schema.PutSink(sinkInfo.Name, sinkInfo)
+	c.tableIDs[sinkInfo.ID] = struct{}{}
+	return nil
+}
+
+func (c *Controller) PersistSink(sinkInfo *common.SinkInfo) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	wb := cluster.NewWriteBatch(cluster.SystemSchemaShardID)
+	if err := table.Upsert(TableDefTableInfo.TableInfo, EncodeSinkInfoToRow(sinkInfo), wb); err != nil {
+		return errors.WithStack(err)
+	}
+	return c.cluster.WriteBatch(wb)
+}
+
+// UnregisterSink removes the sink from memory but does not delete it from storage
+func (c *Controller) UnregisterSink(schemaName string, sinkName string) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	schema, ok := c.schemas[schemaName]
+	if !ok {
+		return errors.Errorf("no such schema %s", schemaName)
+	}
+	sinkInfo, ok := schema.GetSink(sinkName)
+	if !ok {
+		return errors.Errorf("no such sink %s", sinkName)
+	}
+	delete(c.tableIDs, sinkInfo.ID)
+	schema.DeleteSink(sinkName)
+	return nil
+}
+
+func (c *Controller) DeleteSink(sinkID uint64) error {
+	return c.deleteTableWithID(sinkID)
+}
+
 func (c *Controller) DeleteEntityWithID(tableID uint64) error {
 	c.lock.Lock()
 	defer c.lock.Unlock()
@@ -445,6 +589,7 @@ func (c *Controller) registerSystemSchema() {
 	schema.PutTable(TableDefTableInfo.Name, TableDefTableInfo)
 	schema.PutTable(IndexDefTableInfo.Name, IndexDefTableInfo)
 	schema.PutTable(ProtobufTableInfo.Name, ProtobufTableInfo)
+	schema.PutTable(AvroSchemaTableInfo.Name, AvroSchemaTableInfo)
 }
 
 // DeleteSchemaIfEmpty - Schema are removed once they have no more tables
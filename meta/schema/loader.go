@@ -47,6 +47,7 @@ func (l *Loader) Start() error { //nolint:gocyclo
 	// MVs must be started in the load order so we maintain a slice
 	var mvsToStart []tableKey
 	var srcsToStart []*source.Source
+	var sinks []*common.SinkInfo
 
 	for i := 0; i < tableRows.RowCount(); i++ {
 		tableRow := tableRows.GetRow(i)
@@ -63,6 +64,14 @@ func (l *Loader) Start() error { //nolint:gocyclo
 				return errors.WithStack(err)
 			}
 			srcsToStart = append(srcsToStart, src)
+		case meta.TableKindUserTable:
+			info := meta.DecodeUserTableInfoRow(&tableRow)
+			if err := l.meta.RegisterUserTable(info); err != nil {
+				return errors.WithStack(err)
+			}
+			if _, err := l.pushEngine.CreateUserTable(info); err != nil {
+				return errors.WithStack(err)
+			}
 		case meta.TableKindMaterializedView:
 			info := meta.DecodeMaterializedViewInfoRow(&tableRow)
 			tk := tableKey{info.SchemaName, info.Name}
@@ -83,6 +92,9 @@ func (l *Loader) Start() error { //nolint:gocyclo
 			}
 			mvt.sequences = append(mvt.sequences, info.ID)
 			mvt.internalTables = append(mvt.internalTables, info)
+		case meta.TableKindSink:
+			// Sinks are created once the materialized views they consume from have been created
+			sinks = append(sinks, meta.DecodeSinkInfoRow(&tableRow))
 		default:
 			return errors.Errorf("unknown table kind %s", kind)
 		}
@@ -131,6 +143,15 @@ func (l *Loader) Start() error { //nolint:gocyclo
 		}
 	}
 
+	for _, info := range sinks {
+		if err := l.pushEngine.CreateSink(info, false); err != nil {
+			return errors.WithStack(err)
+		}
+		if err := l.meta.RegisterSink(info); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+
 	log.Info("Starting sources")
 
 	for _, src := range srcsToStart {
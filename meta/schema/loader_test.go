@@ -1,6 +1,7 @@
 package schema
 
 import (
+	"github.com/squareup/pranadb/avrolib"
 	"github.com/squareup/pranadb/cluster/fake"
 	"github.com/squareup/pranadb/failinject"
 	"github.com/squareup/pranadb/remoting"
@@ -170,9 +171,9 @@ func runServer(t *testing.T, clus cluster.Cluster, notif *remoting.FakeServer) (
 	require.NoError(t, err)
 	metaController := meta.NewController(clus)
 	shardr := sharder.NewSharder(clus)
-	pullEngine := pull.NewPullEngine(clus, metaController, shardr)
 	config := conf.NewTestConfig(fakeKafka.ID)
-	pushEngine := push.NewPushEngine(clus, shardr, metaController, config, pullEngine, protolib.EmptyRegistry, failinject.NewDummyInjector())
+	pullEngine := pull.NewPullEngine(clus, metaController, shardr, config)
+	pushEngine := push.NewPushEngine(clus, shardr, metaController, config, pullEngine, protolib.EmptyRegistry, avrolib.EmptyRegistry, failinject.NewDummyInjector())
 	ce := command.NewCommandExecutor(metaController, pushEngine, pullEngine, clus, notif, protolib.EmptyRegistry, failinject.NewDummyInjector())
 	notif.RegisterMessageHandler(remoting.ClusterMessageDDLStatement, ce)
 	clus.SetRemoteQueryExecutionCallback(pullEngine)
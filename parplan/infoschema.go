@@ -73,8 +73,8 @@ func schemaToInfoSchema(schema *common.Schema) infoschema.InfoSchema {
 
 		// The TiDB planner doesn't seem to support PK with more than one column, so in this case we create a fake index
 		// which has all the PK cols in it, so the planner can generate an index scan with it, so we get fast lookups
-		// and table scans
-		if len(tableInfo.PrimaryKeyCols) > 1 {
+		// and table scans. We can only do this if all the PK cols are visible, the planner doesn't know about the others
+		if len(tableInfo.PrimaryKeyCols) > 1 && pkColsVisible(tableInfo) {
 			var indexCols []*model.IndexColumn
 			for _, columnIndex := range tableInfo.PrimaryKeyCols {
 				col := &model.IndexColumn{
@@ -178,3 +178,15 @@ func (pis *pranaInfoSchema) TableByID(id int64) (*model.TableInfo, bool) {
 func (pis *pranaInfoSchema) SchemaMetaVersion() int64 {
 	return 0
 }
+
+func pkColsVisible(tableInfo *common.TableInfo) bool {
+	if tableInfo.ColsVisible == nil {
+		return true
+	}
+	for _, pkCol := range tableInfo.PrimaryKeyCols {
+		if !tableInfo.ColsVisible[pkCol] {
+			return false
+		}
+	}
+	return true
+}
@@ -22,7 +22,7 @@ type Parser struct {
 }
 
 func (p *Parser) Parse(sql string) (stmt AstHandle, err error) {
-	stmtNodes, warns, err := p.parser.Parse(sql, charset.CharsetUTF8, "")
+	stmtNodes, warns, err := p.parser.Parse(rewriteWindowIntervals(sql), charset.CharsetUTF8, "")
 	if err != nil {
 		return AstHandle{}, errors.WithStack(err)
 	}
@@ -39,6 +39,12 @@ func (p *Parser) Parse(sql string) (stmt AstHandle, err error) {
 	// as they may be visited in a different order.
 	// We then set the order property on them
 	stmtNode := stmtNodes[0]
+	wv := &windowVisitor{}
+	stmtNode.Accept(wv)
+	if wv.err != nil {
+		return AstHandle{}, errors.WithStack(wv.err)
+	}
+
 	vis := &pmVisitor{}
 	stmtNode.Accept(vis)
 	pms := vis.pms
@@ -48,12 +54,46 @@ func (p *Parser) Parse(sql string) (stmt AstHandle, err error) {
 		pme.SetOrder(i)
 	}
 
-	return AstHandle{stmt: stmtNode}, nil
+	return AstHandle{stmt: stmtNode, numParams: len(pms)}, nil
 }
 
 // AstHandle wraps the underlying TiDB ast, to avoid leaking the TiDB too much into the rest of the code
 type AstHandle struct {
-	stmt ast.StmtNode
+	stmt      ast.StmtNode
+	numParams int
+}
+
+// NumParams returns the number of param markers (?) in the statement
+func (a AstHandle) NumParams() int {
+	return a.numParams
+}
+
+// MatchedRowsQuery returns a query of all the columns of the rows which an update or delete statement changes, built
+// from the table and where clause of the statement
+func (a AstHandle) MatchedRowsQuery() (AstHandle, error) {
+	var tableRefs *ast.TableRefsClause
+	var where ast.ExprNode
+	switch stmt := a.stmt.(type) {
+	case *ast.UpdateStmt:
+		if stmt.Order != nil || stmt.Limit != nil {
+			return AstHandle{}, errors.NewInvalidStatementError("Update statements cannot have an order by or limit")
+		}
+		tableRefs, where = stmt.TableRefs, stmt.Where
+	case *ast.DeleteStmt:
+		if stmt.Order != nil || stmt.Limit != nil {
+			return AstHandle{}, errors.NewInvalidStatementError("Delete statements cannot have an order by or limit")
+		}
+		tableRefs, where = stmt.TableRefs, stmt.Where
+	default:
+		return AstHandle{}, errors.Errorf("expected an update or delete statement but got %T", a.stmt)
+	}
+	query := &ast.SelectStmt{
+		Kind:   ast.SelectStmtKindSelect,
+		Fields: &ast.FieldList{Fields: []*ast.SelectField{{WildCard: &ast.WildCardField{}}}},
+		From:   tableRefs,
+		Where:  where,
+	}
+	return AstHandle{stmt: query, numParams: a.numParams}, nil
 }
 
 type pmVisitor struct {
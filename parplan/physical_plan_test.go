@@ -172,3 +172,63 @@ func TestSecondaryIndexMultiColumnRangeUsingIndexScanForPullQuery(t *testing.T)
 	require.Equal(t, "bar", is.Ranges[0].LowVal[2].GetString())
 	require.Equal(t, "", is.Ranges[0].HighVal[2].GetString())
 }
+
+func TestEquiJoinUsesHashJoinForPushQuery(t *testing.T) {
+	schema := createTestSchema()
+	planner := NewPlanner(schema)
+	physi, _, err := planner.QueryToPlan("select t1.col0, t2.col1 from table1 t1 join table2 t2 on t1.col2 = t2.col2 and t1.col1 < t2.col1", false, false)
+	require.NoError(t, err)
+	proj, ok := physi.(*planner2.PhysicalProjection)
+	require.True(t, ok)
+	join, ok := proj.Children()[0].(*planner2.PhysicalHashJoin)
+	require.True(t, ok)
+	require.Equal(t, planner2.InnerJoin, join.JoinType)
+	require.Equal(t, 1, len(join.LeftJoinKeys))
+	require.Equal(t, 2, join.LeftJoinKeys[0].Index)
+	require.Equal(t, 1, len(join.RightJoinKeys))
+	// col0 of table2 is not used, so it is pruned from the right side of the join
+	require.Equal(t, 2, join.Children()[1].Schema().Len())
+	require.Equal(t, 1, join.RightJoinKeys[0].Index)
+	require.Equal(t, 1, len(join.OtherConditions))
+}
+
+func TestRebuildRangesOfPreparedTableScan(t *testing.T) {
+	schema := createTestSchema()
+	planner := NewPlanner(schema)
+	planner.SetPSArgs([]interface{}{int64(123)})
+	physi, _, err := planner.QueryToPlan("select col0, col1, col2 from table1 where col0=?", true, true)
+	require.NoError(t, err)
+	ts, ok := physi.(*planner2.PhysicalTableScan)
+	require.True(t, ok)
+	require.Equal(t, 1, len(ts.Ranges))
+	require.Equal(t, int64(123), ts.Ranges[0].LowVal[0].GetInt64())
+
+	planner.SetPSArgs([]interface{}{int64(456)})
+	err = planner.RebuildRanges(physi)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(ts.Ranges))
+	require.Equal(t, int64(456), ts.Ranges[0].LowVal[0].GetInt64())
+	require.Equal(t, int64(456), ts.Ranges[0].HighVal[0].GetInt64())
+}
+
+func TestRebuildRangesOfPreparedIndexScan(t *testing.T) {
+	schema := createTestSchema()
+	schema, err := attachIndexToSchema(schema)
+	require.NoError(t, err)
+	planner := NewPlanner(schema)
+	planner.SetPSArgs([]interface{}{int64(1), int64(10)})
+	physi, _, err := planner.QueryToPlan("select col2 from table1 where col2 > ? and col2 < ?", true, true)
+	require.NoError(t, err)
+	is, ok := physi.(*planner2.PhysicalIndexScan)
+	require.True(t, ok)
+	require.Equal(t, 1, len(is.Ranges))
+	require.Equal(t, int64(1), is.Ranges[0].LowVal[0].GetInt64())
+	require.Equal(t, int64(10), is.Ranges[0].HighVal[0].GetInt64())
+
+	planner.SetPSArgs([]interface{}{int64(20), int64(30)})
+	err = planner.RebuildRanges(physi)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(is.Ranges))
+	require.Equal(t, int64(20), is.Ranges[0].LowVal[0].GetInt64())
+	require.Equal(t, int64(30), is.Ranges[0].HighVal[0].GetInt64())
+}
@@ -5,6 +5,7 @@ import (
 	"github.com/squareup/pranadb/tidb/planner"
 	"github.com/squareup/pranadb/tidb/sessionctx"
 	"github.com/squareup/pranadb/tidb/sessionctx/stmtctx"
+	"github.com/squareup/pranadb/tidb/util/ranger"
 
 	"github.com/squareup/pranadb/sessctx"
 
@@ -46,7 +47,11 @@ func (p *Planner) SessionContext() sessionctx.Context {
 }
 
 func (p *Planner) SetPSArgs(args []interface{}) {
-	p.sessionCtx.SetArgs(args)
+	tidbArgs := make([]interface{}, len(args))
+	for i, arg := range args {
+		tidbArgs[i] = common.PranaValueToTiDBValue(arg)
+	}
+	p.sessionCtx.SetArgs(tidbArgs)
 }
 
 func (p *Planner) RefreshInfoSchema() {
@@ -105,6 +110,40 @@ func (p *Planner) BuildPhysicalPlan(logicalPlan planner.LogicalPlan, pullQuery b
 	return physicalPlan, nil
 }
 
+// RebuildRanges recalculates the ranges of the scans in a plan which was built for a prepared statement, from the
+// current prepared statement args. This allows the plan to be executed again with different args without planning it
+// again.
+func (p *Planner) RebuildRanges(plan planner.PhysicalPlan) error {
+	switch op := plan.(type) {
+	case *planner.PhysicalTableScan:
+		pkCol := op.Table.GetPkColInfo()
+		if len(op.AccessCondition) > 0 && pkCol != nil {
+			ranges, err := ranger.BuildTableRange(op.AccessCondition, p.StatementContext(), &pkCol.FieldType)
+			if err != nil {
+				return errors.WithStack(err)
+			}
+			op.Ranges = ranges
+		}
+	case *planner.PhysicalIndexScan:
+		if len(op.AccessCondition) > 0 && len(op.IdxCols) > 0 {
+			res, err := ranger.DetachCondAndBuildRangeForIndex(p.sessionCtx, op.AccessCondition, op.IdxCols, op.IdxColLens)
+			if err != nil {
+				return errors.WithStack(err)
+			}
+			if len(res.AccessConds) != len(op.AccessCondition) {
+				return errors.Error("failed to rebuild ranges of prepared statement plan")
+			}
+			op.Ranges = res.Ranges
+		}
+	}
+	for _, child := range plan.Children() {
+		if err := p.RebuildRanges(child); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (p *Planner) preprocess(stmt ast.Node, prepare bool) error {
 	var err error
 	if prepare {
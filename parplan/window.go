@@ -0,0 +1,208 @@
+package parplan
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/pingcap/parser/ast"
+	"github.com/pingcap/parser/model"
+	"github.com/squareup/pranadb/errors"
+	"github.com/squareup/pranadb/tidb/expression"
+)
+
+// The columns that can be selected from an aggregation grouped by a window
+const (
+	windowStartColName = "window_start"
+	windowEndColName   = "window_end"
+)
+
+// windowEndFuncs are the functions which return the end of each kind of window, and the number of arguments of the
+// window function that they take
+var windowEndFuncs = map[string]struct {
+	name    string
+	numArgs int
+}{
+	expression.Tumble:  {name: expression.TumbleEnd, numArgs: 3},
+	expression.Hop:     {name: expression.HopEnd, numArgs: 5},
+	expression.Session: {name: expression.SessionEnd, numArgs: 3},
+}
+
+// windowFuncAliases are the names of window functions which the parser can't parse as function names
+var windowFuncAliases = map[string]string{
+	"session": expression.Session,
+}
+
+var windowIntervalRegex = regexp.MustCompile(`(?i)([(,]\s*)interval\s+('[^']*'|"[^"]*"|[-+]?\d+)\s+([a-z_]+)(\s*[,)])`)
+
+// rewriteWindowIntervals rewrites the INTERVAL arguments of the window functions, which the parser only supports for a
+// few built-in functions, into value and unit arguments, e.g. TUMBLE(ts, INTERVAL 5 MINUTE) becomes
+// TUMBLE(ts, 5, 'MINUTE'). Window functions called by an alias are renamed.
+func rewriteWindowIntervals(sql string) string {
+	var sb strings.Builder
+	pos := 0
+	for pos < len(sql) {
+		nameStart, nameEnd, start, end := nextWindowCall(sql, pos)
+		if start == -1 {
+			break
+		}
+		sb.WriteString(sql[pos:nameStart])
+		name := sql[nameStart:nameEnd]
+		if alias, ok := windowFuncAliases[strings.ToLower(name)]; ok {
+			name = alias
+		}
+		sb.WriteString(name)
+		sb.WriteString(sql[nameEnd:start])
+		call := sql[start:end]
+		// Matches can share a separator, so we replace until there are no more
+		for {
+			rewritten := windowIntervalRegex.ReplaceAllString(call, "$1$2, '$3'$4")
+			if rewritten == call {
+				break
+			}
+			call = rewritten
+		}
+		sb.WriteString(call)
+		pos = end
+	}
+	sb.WriteString(sql[pos:])
+	return sb.String()
+}
+
+// nextWindowCall returns the start and end of the name, and of the argument list, of the next call of a window function
+// at or after pos, ignoring quoted text, or -1 if there isn't one
+func nextWindowCall(sql string, pos int) (int, int, int, int) {
+	for i := pos; i < len(sql); i++ {
+		c := sql[i]
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			i = skipQuoted(sql, i)
+		case isIdentChar(c):
+			nameStart := i
+			j := i
+			for j < len(sql) && isIdentChar(sql[j]) {
+				j++
+			}
+			nameEnd := j
+			i = j - 1
+			if !isWindowFunc(sql[nameStart:nameEnd]) {
+				continue
+			}
+			for j < len(sql) && (sql[j] == ' ' || sql[j] == '\t' || sql[j] == '\n' || sql[j] == '\r') {
+				j++
+			}
+			if j == len(sql) || sql[j] != '(' {
+				continue
+			}
+			depth := 0
+			for k := j; k < len(sql); k++ {
+				switch sql[k] {
+				case '\'', '"', '`':
+					k = skipQuoted(sql, k)
+				case '(':
+					depth++
+				case ')':
+					depth--
+					if depth == 0 {
+						return nameStart, nameEnd, j, k + 1
+					}
+				}
+			}
+			return -1, -1, -1, -1
+		}
+	}
+	return -1, -1, -1, -1
+}
+
+func isWindowFunc(name string) bool {
+	name = strings.ToLower(name)
+	_, isAlias := windowFuncAliases[name]
+	_, ok := windowEndFuncs[name]
+	return ok || isAlias
+}
+
+// skipQuoted returns the index of the quote which closes the quoted text starting at pos
+func skipQuoted(sql string, pos int) int {
+	quote := sql[pos]
+	for i := pos + 1; i < len(sql); i++ {
+		if sql[i] == '\\' && quote != '`' {
+			i++
+		} else if sql[i] == quote {
+			return i
+		}
+	}
+	return len(sql)
+}
+
+func isIdentChar(c byte) bool {
+	return c == '_' || c == '$' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+// windowVisitor replaces the window_start and window_end columns selected from a query grouped by a window with the
+// start and end of the window of each group
+type windowVisitor struct {
+	err error
+}
+
+func (w *windowVisitor) Enter(in ast.Node) (ast.Node, bool) {
+	if sel, ok := in.(*ast.SelectStmt); ok && w.err == nil {
+		w.err = rewriteWindowColumns(sel)
+	}
+	return in, false
+}
+
+func (w *windowVisitor) Leave(in ast.Node) (ast.Node, bool) {
+	return in, true
+}
+
+func rewriteWindowColumns(sel *ast.SelectStmt) error {
+	if sel.GroupBy == nil || sel.Fields == nil {
+		return nil
+	}
+	var window *ast.FuncCallExpr
+	for _, item := range sel.GroupBy.Items {
+		if f, ok := item.Expr.(*ast.FuncCallExpr); ok {
+			if _, ok := windowEndFuncs[f.FnName.L]; !ok {
+				continue
+			}
+			if window != nil {
+				return errors.NewPranaErrorf(errors.InvalidStatement, "a query can only be grouped by one window")
+			}
+			window = f
+		}
+	}
+	if window == nil {
+		return nil
+	}
+	end := windowEndFuncs[window.FnName.L]
+	if len(window.Args) < end.numArgs {
+		return nil
+	}
+	for _, field := range sel.Fields.Fields {
+		col, ok := field.Expr.(*ast.ColumnNameExpr)
+		if !ok || col.Name.Table.L != "" {
+			continue
+		}
+		var fnName string
+		var args []ast.ExprNode
+		switch col.Name.Name.L {
+		case windowStartColName:
+			fnName = window.FnName.L
+			args = append(args, window.Args...)
+		case windowEndColName:
+			fnName = end.name
+			args = append(args, window.Args[:end.numArgs]...)
+		default:
+			continue
+		}
+		// The window of a row isn't necessarily the window it is aggregated into, so the column is the first row of the
+		// window function, which the aggregation sets to the window of the group
+		field.Expr = &ast.AggregateFuncExpr{
+			F:    ast.AggFuncFirstRow,
+			Args: []ast.ExprNode{&ast.FuncCallExpr{FnName: model.NewCIStr(fnName), Args: args}},
+		}
+		if field.AsName.L == "" {
+			field.AsName = col.Name.Name
+		}
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package parplan
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRewriteWindowIntervals(t *testing.T) {
+	testCases := []struct {
+		sql      string
+		expected string
+	}{
+		{
+			sql:      "select count(*) from t group by tumble(ts, interval 5 minute)",
+			expected: "select count(*) from t group by tumble(ts, 5, 'minute')",
+		},
+		{
+			sql:      "select count(*) from t group by TUMBLE (ts, INTERVAL '1' HOUR, INTERVAL 30 SECOND), a",
+			expected: "select count(*) from t group by TUMBLE (ts, '1', 'HOUR', 30, 'SECOND'), a",
+		},
+		{
+			sql:      "select count(*) from t group by hop(ts, interval 1 hour, interval 10 minute), session(ts, interval 5 minute)",
+			expected: "select count(*) from t group by hop(ts, 1, 'hour', 10, 'minute'), session_window(ts, 5, 'minute')",
+		},
+		{
+			sql:      "select 'tumble(ts, interval 1 day)', tumbles from t",
+			expected: "select 'tumble(ts, interval 1 day)', tumbles from t",
+		},
+		{
+			sql:      "select date_add(ts, interval 1 day) from t group by tumble(ts, 60, 'SECOND')",
+			expected: "select date_add(ts, interval 1 day) from t group by tumble(ts, 60, 'SECOND')",
+		},
+	}
+	for _, tc := range testCases {
+		require.Equal(t, tc.expected, rewriteWindowIntervals(tc.sql))
+	}
+}
+
+func TestWindowColumns(t *testing.T) {
+	parser := NewParser()
+	_, err := parser.Parse("select window_start, window_end, count(*) from t group by tumble(ts, interval 1 minute)")
+	require.NoError(t, err)
+	_, err = parser.Parse("select count(*) from t group by tumble(ts, interval 1 minute), tumble(ts, interval 1 hour)")
+	require.Error(t, err)
+	_, err = parser.Parse("select window_start, window_end, count(*) from t group by hop(ts, interval 1 hour, interval 10 minute)")
+	require.NoError(t, err)
+	_, err = parser.Parse("select count(*) from t group by session(ts, interval 1 minute), tumble(ts, interval 1 hour)")
+	require.Error(t, err)
+}
@@ -115,6 +115,110 @@ func (x *DDLStatementInfo) GetTableSequences() []uint64 {
 	return nil
 }
 
+// An update or delete statement, which each node applies to the rows of the shards it processes
+type DMLStatementInfo struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	SchemaName string `protobuf:"bytes,1,opt,name=schema_name,json=schemaName,proto3" json:"schema_name,omitempty"`
+	Sql        string `protobuf:"bytes,2,opt,name=sql,proto3" json:"sql,omitempty"`
+}
+
+func (x *DMLStatementInfo) Reset() {
+	*x = DMLStatementInfo{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_squareup_cash_pranadb_notifications_v1_notifications_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DMLStatementInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DMLStatementInfo) ProtoMessage() {}
+
+func (x *DMLStatementInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_squareup_cash_pranadb_notifications_v1_notifications_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DMLStatementInfo.ProtoReflect.Descriptor instead.
+func (*DMLStatementInfo) Descriptor() ([]byte, []int) {
+	return file_squareup_cash_pranadb_notifications_v1_notifications_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *DMLStatementInfo) GetSchemaName() string {
+	if x != nil {
+		return x.SchemaName
+	}
+	return ""
+}
+
+func (x *DMLStatementInfo) GetSql() string {
+	if x != nil {
+		return x.Sql
+	}
+	return ""
+}
+
+// The shards a node applied an update or delete statement to
+type DMLStatementResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ShardIds []uint64 `protobuf:"varint,1,rep,packed,name=shard_ids,json=shardIds,proto3" json:"shard_ids,omitempty"`
+}
+
+func (x *DMLStatementResponse) Reset() {
+	*x = DMLStatementResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_squareup_cash_pranadb_notifications_v1_notifications_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DMLStatementResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DMLStatementResponse) ProtoMessage() {}
+
+func (x *DMLStatementResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_squareup_cash_pranadb_notifications_v1_notifications_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DMLStatementResponse.ProtoReflect.Descriptor instead.
+func (*DMLStatementResponse) Descriptor() ([]byte, []int) {
+	return file_squareup_cash_pranadb_notifications_v1_notifications_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *DMLStatementResponse) GetShardIds() []uint64 {
+	if x != nil {
+		return x.ShardIds
+	}
+	return nil
+}
+
 type NotificationTestMessage struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -126,7 +230,7 @@ type NotificationTestMessage struct {
 func (x *NotificationTestMessage) Reset() {
 	*x = NotificationTestMessage{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_squareup_cash_pranadb_notifications_v1_notifications_proto_msgTypes[1]
+		mi := &file_squareup_cash_pranadb_notifications_v1_notifications_proto_msgTypes[3]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -139,7 +243,7 @@ func (x *NotificationTestMessage) String() string {
 func (*NotificationTestMessage) ProtoMessage() {}
 
 func (x *NotificationTestMessage) ProtoReflect() protoreflect.Message {
-	mi := &file_squareup_cash_pranadb_notifications_v1_notifications_proto_msgTypes[1]
+	mi := &file_squareup_cash_pranadb_notifications_v1_notifications_proto_msgTypes[3]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -152,7 +256,7 @@ func (x *NotificationTestMessage) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use NotificationTestMessage.ProtoReflect.Descriptor instead.
 func (*NotificationTestMessage) Descriptor() ([]byte, []int) {
-	return file_squareup_cash_pranadb_notifications_v1_notifications_proto_rawDescGZIP(), []int{1}
+	return file_squareup_cash_pranadb_notifications_v1_notifications_proto_rawDescGZIP(), []int{3}
 }
 
 func (x *NotificationTestMessage) GetSessionId() string {
@@ -171,7 +275,7 @@ type ReloadProtobuf struct {
 func (x *ReloadProtobuf) Reset() {
 	*x = ReloadProtobuf{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_squareup_cash_pranadb_notifications_v1_notifications_proto_msgTypes[2]
+		mi := &file_squareup_cash_pranadb_notifications_v1_notifications_proto_msgTypes[4]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -184,7 +288,7 @@ func (x *ReloadProtobuf) String() string {
 func (*ReloadProtobuf) ProtoMessage() {}
 
 func (x *ReloadProtobuf) ProtoReflect() protoreflect.Message {
-	mi := &file_squareup_cash_pranadb_notifications_v1_notifications_proto_msgTypes[2]
+	mi := &file_squareup_cash_pranadb_notifications_v1_notifications_proto_msgTypes[4]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -197,7 +301,45 @@ func (x *ReloadProtobuf) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ReloadProtobuf.ProtoReflect.Descriptor instead.
 func (*ReloadProtobuf) Descriptor() ([]byte, []int) {
-	return file_squareup_cash_pranadb_notifications_v1_notifications_proto_rawDescGZIP(), []int{2}
+	return file_squareup_cash_pranadb_notifications_v1_notifications_proto_rawDescGZIP(), []int{4}
+}
+
+type ReloadAvroSchemas struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *ReloadAvroSchemas) Reset() {
+	*x = ReloadAvroSchemas{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_squareup_cash_pranadb_notifications_v1_notifications_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ReloadAvroSchemas) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReloadAvroSchemas) ProtoMessage() {}
+
+func (x *ReloadAvroSchemas) ProtoReflect() protoreflect.Message {
+	mi := &file_squareup_cash_pranadb_notifications_v1_notifications_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReloadAvroSchemas.ProtoReflect.Descriptor instead.
+func (*ReloadAvroSchemas) Descriptor() ([]byte, []int) {
+	return file_squareup_cash_pranadb_notifications_v1_notifications_proto_rawDescGZIP(), []int{5}
 }
 
 type ClusterProposeRequest struct {
@@ -212,7 +354,7 @@ type ClusterProposeRequest struct {
 func (x *ClusterProposeRequest) Reset() {
 	*x = ClusterProposeRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_squareup_cash_pranadb_notifications_v1_notifications_proto_msgTypes[3]
+		mi := &file_squareup_cash_pranadb_notifications_v1_notifications_proto_msgTypes[6]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -225,7 +367,7 @@ func (x *ClusterProposeRequest) String() string {
 func (*ClusterProposeRequest) ProtoMessage() {}
 
 func (x *ClusterProposeRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_squareup_cash_pranadb_notifications_v1_notifications_proto_msgTypes[3]
+	mi := &file_squareup_cash_pranadb_notifications_v1_notifications_proto_msgTypes[6]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -238,7 +380,7 @@ func (x *ClusterProposeRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ClusterProposeRequest.ProtoReflect.Descriptor instead.
 func (*ClusterProposeRequest) Descriptor() ([]byte, []int) {
-	return file_squareup_cash_pranadb_notifications_v1_notifications_proto_rawDescGZIP(), []int{3}
+	return file_squareup_cash_pranadb_notifications_v1_notifications_proto_rawDescGZIP(), []int{6}
 }
 
 func (x *ClusterProposeRequest) GetShardId() int64 {
@@ -267,7 +409,7 @@ type ClusterProposeResponse struct {
 func (x *ClusterProposeResponse) Reset() {
 	*x = ClusterProposeResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_squareup_cash_pranadb_notifications_v1_notifications_proto_msgTypes[4]
+		mi := &file_squareup_cash_pranadb_notifications_v1_notifications_proto_msgTypes[7]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -280,7 +422,7 @@ func (x *ClusterProposeResponse) String() string {
 func (*ClusterProposeResponse) ProtoMessage() {}
 
 func (x *ClusterProposeResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_squareup_cash_pranadb_notifications_v1_notifications_proto_msgTypes[4]
+	mi := &file_squareup_cash_pranadb_notifications_v1_notifications_proto_msgTypes[7]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -293,7 +435,7 @@ func (x *ClusterProposeResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ClusterProposeResponse.ProtoReflect.Descriptor instead.
 func (*ClusterProposeResponse) Descriptor() ([]byte, []int) {
-	return file_squareup_cash_pranadb_notifications_v1_notifications_proto_rawDescGZIP(), []int{4}
+	return file_squareup_cash_pranadb_notifications_v1_notifications_proto_rawDescGZIP(), []int{7}
 }
 
 func (x *ClusterProposeResponse) GetRetVal() int64 {
@@ -322,7 +464,7 @@ type ClusterReadRequest struct {
 func (x *ClusterReadRequest) Reset() {
 	*x = ClusterReadRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_squareup_cash_pranadb_notifications_v1_notifications_proto_msgTypes[5]
+		mi := &file_squareup_cash_pranadb_notifications_v1_notifications_proto_msgTypes[8]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -335,7 +477,7 @@ func (x *ClusterReadRequest) String() string {
 func (*ClusterReadRequest) ProtoMessage() {}
 
 func (x *ClusterReadRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_squareup_cash_pranadb_notifications_v1_notifications_proto_msgTypes[5]
+	mi := &file_squareup_cash_pranadb_notifications_v1_notifications_proto_msgTypes[8]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -348,7 +490,7 @@ func (x *ClusterReadRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ClusterReadRequest.ProtoReflect.Descriptor instead.
 func (*ClusterReadRequest) Descriptor() ([]byte, []int) {
-	return file_squareup_cash_pranadb_notifications_v1_notifications_proto_rawDescGZIP(), []int{5}
+	return file_squareup_cash_pranadb_notifications_v1_notifications_proto_rawDescGZIP(), []int{8}
 }
 
 func (x *ClusterReadRequest) GetShardId() int64 {
@@ -376,7 +518,7 @@ type ClusterReadResponse struct {
 func (x *ClusterReadResponse) Reset() {
 	*x = ClusterReadResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_squareup_cash_pranadb_notifications_v1_notifications_proto_msgTypes[6]
+		mi := &file_squareup_cash_pranadb_notifications_v1_notifications_proto_msgTypes[9]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -389,7 +531,7 @@ func (x *ClusterReadResponse) String() string {
 func (*ClusterReadResponse) ProtoMessage() {}
 
 func (x *ClusterReadResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_squareup_cash_pranadb_notifications_v1_notifications_proto_msgTypes[6]
+	mi := &file_squareup_cash_pranadb_notifications_v1_notifications_proto_msgTypes[9]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -402,7 +544,7 @@ func (x *ClusterReadResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ClusterReadResponse.ProtoReflect.Descriptor instead.
 func (*ClusterReadResponse) Descriptor() ([]byte, []int) {
-	return file_squareup_cash_pranadb_notifications_v1_notifications_proto_rawDescGZIP(), []int{6}
+	return file_squareup_cash_pranadb_notifications_v1_notifications_proto_rawDescGZIP(), []int{9}
 }
 
 func (x *ClusterReadResponse) GetResponseBody() []byte {
@@ -412,6 +554,361 @@ func (x *ClusterReadResponse) GetResponseBody() []byte {
 	return nil
 }
 
+type ChangeFeedPosition struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ShardId  uint64 `protobuf:"varint,1,opt,name=shard_id,json=shardId,proto3" json:"shard_id,omitempty"`
+	Epoch    uint64 `protobuf:"varint,2,opt,name=epoch,proto3" json:"epoch,omitempty"`
+	Sequence uint64 `protobuf:"varint,3,opt,name=sequence,proto3" json:"sequence,omitempty"`
+}
+
+func (x *ChangeFeedPosition) Reset() {
+	*x = ChangeFeedPosition{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_squareup_cash_pranadb_notifications_v1_notifications_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ChangeFeedPosition) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChangeFeedPosition) ProtoMessage() {}
+
+func (x *ChangeFeedPosition) ProtoReflect() protoreflect.Message {
+	mi := &file_squareup_cash_pranadb_notifications_v1_notifications_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChangeFeedPosition.ProtoReflect.Descriptor instead.
+func (*ChangeFeedPosition) Descriptor() ([]byte, []int) {
+	return file_squareup_cash_pranadb_notifications_v1_notifications_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *ChangeFeedPosition) GetShardId() uint64 {
+	if x != nil {
+		return x.ShardId
+	}
+	return 0
+}
+
+func (x *ChangeFeedPosition) GetEpoch() uint64 {
+	if x != nil {
+		return x.Epoch
+	}
+	return 0
+}
+
+func (x *ChangeFeedPosition) GetSequence() uint64 {
+	if x != nil {
+		return x.Sequence
+	}
+	return 0
+}
+
+type ChangeFeedSubscribe struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	SubscriptionId  string                `protobuf:"bytes,1,opt,name=subscription_id,json=subscriptionId,proto3" json:"subscription_id,omitempty"`
+	NodeId          int64                 `protobuf:"varint,2,opt,name=node_id,json=nodeId,proto3" json:"node_id,omitempty"`
+	SchemaName      string                `protobuf:"bytes,3,opt,name=schema_name,json=schemaName,proto3" json:"schema_name,omitempty"`
+	MvName          string                `protobuf:"bytes,4,opt,name=mv_name,json=mvName,proto3" json:"mv_name,omitempty"`
+	ResumePositions []*ChangeFeedPosition `protobuf:"bytes,5,rep,name=resume_positions,json=resumePositions,proto3" json:"resume_positions,omitempty"`
+}
+
+func (x *ChangeFeedSubscribe) Reset() {
+	*x = ChangeFeedSubscribe{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_squareup_cash_pranadb_notifications_v1_notifications_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ChangeFeedSubscribe) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChangeFeedSubscribe) ProtoMessage() {}
+
+func (x *ChangeFeedSubscribe) ProtoReflect() protoreflect.Message {
+	mi := &file_squareup_cash_pranadb_notifications_v1_notifications_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChangeFeedSubscribe.ProtoReflect.Descriptor instead.
+func (*ChangeFeedSubscribe) Descriptor() ([]byte, []int) {
+	return file_squareup_cash_pranadb_notifications_v1_notifications_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *ChangeFeedSubscribe) GetSubscriptionId() string {
+	if x != nil {
+		return x.SubscriptionId
+	}
+	return ""
+}
+
+func (x *ChangeFeedSubscribe) GetNodeId() int64 {
+	if x != nil {
+		return x.NodeId
+	}
+	return 0
+}
+
+func (x *ChangeFeedSubscribe) GetSchemaName() string {
+	if x != nil {
+		return x.SchemaName
+	}
+	return ""
+}
+
+func (x *ChangeFeedSubscribe) GetMvName() string {
+	if x != nil {
+		return x.MvName
+	}
+	return ""
+}
+
+func (x *ChangeFeedSubscribe) GetResumePositions() []*ChangeFeedPosition {
+	if x != nil {
+		return x.ResumePositions
+	}
+	return nil
+}
+
+type ChangeFeedUnsubscribe struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	SubscriptionId string `protobuf:"bytes,1,opt,name=subscription_id,json=subscriptionId,proto3" json:"subscription_id,omitempty"`
+}
+
+func (x *ChangeFeedUnsubscribe) Reset() {
+	*x = ChangeFeedUnsubscribe{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_squareup_cash_pranadb_notifications_v1_notifications_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ChangeFeedUnsubscribe) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChangeFeedUnsubscribe) ProtoMessage() {}
+
+func (x *ChangeFeedUnsubscribe) ProtoReflect() protoreflect.Message {
+	mi := &file_squareup_cash_pranadb_notifications_v1_notifications_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChangeFeedUnsubscribe.ProtoReflect.Descriptor instead.
+func (*ChangeFeedUnsubscribe) Descriptor() ([]byte, []int) {
+	return file_squareup_cash_pranadb_notifications_v1_notifications_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *ChangeFeedUnsubscribe) GetSubscriptionId() string {
+	if x != nil {
+		return x.SubscriptionId
+	}
+	return ""
+}
+
+type ChangeFeedChange struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Sequence    uint64 `protobuf:"varint,1,opt,name=sequence,proto3" json:"sequence,omitempty"`
+	PreviousRow []byte `protobuf:"bytes,2,opt,name=previous_row,json=previousRow,proto3" json:"previous_row,omitempty"`
+	CurrentRow  []byte `protobuf:"bytes,3,opt,name=current_row,json=currentRow,proto3" json:"current_row,omitempty"`
+}
+
+func (x *ChangeFeedChange) Reset() {
+	*x = ChangeFeedChange{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_squareup_cash_pranadb_notifications_v1_notifications_proto_msgTypes[13]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ChangeFeedChange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChangeFeedChange) ProtoMessage() {}
+
+func (x *ChangeFeedChange) ProtoReflect() protoreflect.Message {
+	mi := &file_squareup_cash_pranadb_notifications_v1_notifications_proto_msgTypes[13]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChangeFeedChange.ProtoReflect.Descriptor instead.
+func (*ChangeFeedChange) Descriptor() ([]byte, []int) {
+	return file_squareup_cash_pranadb_notifications_v1_notifications_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *ChangeFeedChange) GetSequence() uint64 {
+	if x != nil {
+		return x.Sequence
+	}
+	return 0
+}
+
+func (x *ChangeFeedChange) GetPreviousRow() []byte {
+	if x != nil {
+		return x.PreviousRow
+	}
+	return nil
+}
+
+func (x *ChangeFeedChange) GetCurrentRow() []byte {
+	if x != nil {
+		return x.CurrentRow
+	}
+	return nil
+}
+
+type ChangeFeedEvents struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	SubscriptionId   string              `protobuf:"bytes,1,opt,name=subscription_id,json=subscriptionId,proto3" json:"subscription_id,omitempty"`
+	ShardId          uint64              `protobuf:"varint,2,opt,name=shard_id,json=shardId,proto3" json:"shard_id,omitempty"`
+	Epoch            uint64              `protobuf:"varint,3,opt,name=epoch,proto3" json:"epoch,omitempty"`
+	SnapshotRows     [][]byte            `protobuf:"bytes,4,rep,name=snapshot_rows,json=snapshotRows,proto3" json:"snapshot_rows,omitempty"`
+	SnapshotComplete bool                `protobuf:"varint,5,opt,name=snapshot_complete,json=snapshotComplete,proto3" json:"snapshot_complete,omitempty"`
+	SnapshotSequence uint64              `protobuf:"varint,6,opt,name=snapshot_sequence,json=snapshotSequence,proto3" json:"snapshot_sequence,omitempty"`
+	Changes          []*ChangeFeedChange `protobuf:"bytes,7,rep,name=changes,proto3" json:"changes,omitempty"`
+	Error            string              `protobuf:"bytes,8,opt,name=error,proto3" json:"error,omitempty"`
+}
+
+func (x *ChangeFeedEvents) Reset() {
+	*x = ChangeFeedEvents{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_squareup_cash_pranadb_notifications_v1_notifications_proto_msgTypes[14]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ChangeFeedEvents) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChangeFeedEvents) ProtoMessage() {}
+
+func (x *ChangeFeedEvents) ProtoReflect() protoreflect.Message {
+	mi := &file_squareup_cash_pranadb_notifications_v1_notifications_proto_msgTypes[14]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChangeFeedEvents.ProtoReflect.Descriptor instead.
+func (*ChangeFeedEvents) Descriptor() ([]byte, []int) {
+	return file_squareup_cash_pranadb_notifications_v1_notifications_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *ChangeFeedEvents) GetSubscriptionId() string {
+	if x != nil {
+		return x.SubscriptionId
+	}
+	return ""
+}
+
+func (x *ChangeFeedEvents) GetShardId() uint64 {
+	if x != nil {
+		return x.ShardId
+	}
+	return 0
+}
+
+func (x *ChangeFeedEvents) GetEpoch() uint64 {
+	if x != nil {
+		return x.Epoch
+	}
+	return 0
+}
+
+func (x *ChangeFeedEvents) GetSnapshotRows() [][]byte {
+	if x != nil {
+		return x.SnapshotRows
+	}
+	return nil
+}
+
+func (x *ChangeFeedEvents) GetSnapshotComplete() bool {
+	if x != nil {
+		return x.SnapshotComplete
+	}
+	return false
+}
+
+func (x *ChangeFeedEvents) GetSnapshotSequence() uint64 {
+	if x != nil {
+		return x.SnapshotSequence
+	}
+	return 0
+}
+
+func (x *ChangeFeedEvents) GetChanges() []*ChangeFeedChange {
+	if x != nil {
+		return x.Changes
+	}
+	return nil
+}
+
+func (x *ChangeFeedEvents) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
 var File_squareup_cash_pranadb_notifications_v1_notifications_proto protoreflect.FileDescriptor
 
 var file_squareup_cash_pranadb_notifications_v1_notifications_proto_rawDesc = []byte{
@@ -436,37 +933,101 @@ var file_squareup_cash_pranadb_notifications_v1_notifications_proto_rawDesc = []
 	0x6d, 0x65, 0x12, 0x10, 0x0a, 0x03, 0x73, 0x71, 0x6c, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52,
 	0x03, 0x73, 0x71, 0x6c, 0x12, 0x27, 0x0a, 0x0f, 0x74, 0x61, 0x62, 0x6c, 0x65, 0x5f, 0x73, 0x65,
 	0x71, 0x75, 0x65, 0x6e, 0x63, 0x65, 0x73, 0x18, 0x07, 0x20, 0x03, 0x28, 0x04, 0x52, 0x0e, 0x74,
-	0x61, 0x62, 0x6c, 0x65, 0x53, 0x65, 0x71, 0x75, 0x65, 0x6e, 0x63, 0x65, 0x73, 0x22, 0x38, 0x0a,
-	0x17, 0x4e, 0x6f, 0x74, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x54, 0x65, 0x73,
-	0x74, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x1d, 0x0a, 0x0a, 0x73, 0x65, 0x73, 0x73,
-	0x69, 0x6f, 0x6e, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x73, 0x65,
-	0x73, 0x73, 0x69, 0x6f, 0x6e, 0x49, 0x64, 0x22, 0x10, 0x0a, 0x0e, 0x52, 0x65, 0x6c, 0x6f, 0x61,
-	0x64, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x22, 0x55, 0x0a, 0x15, 0x43, 0x6c, 0x75,
-	0x73, 0x74, 0x65, 0x72, 0x50, 0x72, 0x6f, 0x70, 0x6f, 0x73, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65,
-	0x73, 0x74, 0x12, 0x19, 0x0a, 0x08, 0x73, 0x68, 0x61, 0x72, 0x64, 0x5f, 0x69, 0x64, 0x18, 0x01,
-	0x20, 0x01, 0x28, 0x03, 0x52, 0x07, 0x73, 0x68, 0x61, 0x72, 0x64, 0x49, 0x64, 0x12, 0x21, 0x0a,
-	0x0c, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x5f, 0x62, 0x6f, 0x64, 0x79, 0x18, 0x02, 0x20,
-	0x01, 0x28, 0x0c, 0x52, 0x0b, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x42, 0x6f, 0x64, 0x79,
-	0x22, 0x56, 0x0a, 0x16, 0x43, 0x6c, 0x75, 0x73, 0x74, 0x65, 0x72, 0x50, 0x72, 0x6f, 0x70, 0x6f,
-	0x73, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x17, 0x0a, 0x07, 0x72, 0x65,
-	0x74, 0x5f, 0x76, 0x61, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x06, 0x72, 0x65, 0x74,
-	0x56, 0x61, 0x6c, 0x12, 0x23, 0x0a, 0x0d, 0x72, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x5f,
-	0x62, 0x6f, 0x64, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0c, 0x72, 0x65, 0x73, 0x70,
-	0x6f, 0x6e, 0x73, 0x65, 0x42, 0x6f, 0x64, 0x79, 0x22, 0x52, 0x0a, 0x12, 0x43, 0x6c, 0x75, 0x73,
-	0x74, 0x65, 0x72, 0x52, 0x65, 0x61, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x19,
-	0x0a, 0x08, 0x73, 0x68, 0x61, 0x72, 0x64, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03,
-	0x52, 0x07, 0x73, 0x68, 0x61, 0x72, 0x64, 0x49, 0x64, 0x12, 0x21, 0x0a, 0x0c, 0x72, 0x65, 0x71,
-	0x75, 0x65, 0x73, 0x74, 0x5f, 0x62, 0x6f, 0x64, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52,
-	0x0b, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x42, 0x6f, 0x64, 0x79, 0x22, 0x3a, 0x0a, 0x13,
-	0x43, 0x6c, 0x75, 0x73, 0x74, 0x65, 0x72, 0x52, 0x65, 0x61, 0x64, 0x52, 0x65, 0x73, 0x70, 0x6f,
-	0x6e, 0x73, 0x65, 0x12, 0x23, 0x0a, 0x0d, 0x72, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x5f,
-	0x62, 0x6f, 0x64, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0c, 0x72, 0x65, 0x73, 0x70,
-	0x6f, 0x6e, 0x73, 0x65, 0x42, 0x6f, 0x64, 0x79, 0x42, 0x4b, 0x5a, 0x49, 0x67, 0x69, 0x74, 0x68,
-	0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x73, 0x71, 0x75, 0x61, 0x72, 0x65, 0x75, 0x70, 0x2f,
-	0x70, 0x72, 0x61, 0x6e, 0x61, 0x64, 0x62, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x73, 0x2f, 0x73,
-	0x71, 0x75, 0x61, 0x72, 0x65, 0x75, 0x70, 0x2f, 0x63, 0x61, 0x73, 0x68, 0x2f, 0x70, 0x72, 0x61,
-	0x6e, 0x61, 0x64, 0x62, 0x2f, 0x76, 0x31, 0x2f, 0x6e, 0x6f, 0x74, 0x69, 0x66, 0x69, 0x63, 0x61,
-	0x74, 0x69, 0x6f, 0x6e, 0x73, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x61, 0x62, 0x6c, 0x65, 0x53, 0x65, 0x71, 0x75, 0x65, 0x6e, 0x63, 0x65, 0x73, 0x22, 0x45, 0x0a,
+	0x10, 0x44, 0x4d, 0x4c, 0x53, 0x74, 0x61, 0x74, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x49, 0x6e, 0x66,
+	0x6f, 0x12, 0x1f, 0x0a, 0x0b, 0x73, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x5f, 0x6e, 0x61, 0x6d, 0x65,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x73, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x4e, 0x61,
+	0x6d, 0x65, 0x12, 0x10, 0x0a, 0x03, 0x73, 0x71, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x03, 0x73, 0x71, 0x6c, 0x22, 0x33, 0x0a, 0x14, 0x44, 0x4d, 0x4c, 0x53, 0x74, 0x61, 0x74, 0x65,
+	0x6d, 0x65, 0x6e, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x1b, 0x0a, 0x09,
+	0x73, 0x68, 0x61, 0x72, 0x64, 0x5f, 0x69, 0x64, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x04, 0x52,
+	0x08, 0x73, 0x68, 0x61, 0x72, 0x64, 0x49, 0x64, 0x73, 0x22, 0x38, 0x0a, 0x17, 0x4e, 0x6f, 0x74,
+	0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x54, 0x65, 0x73, 0x74, 0x4d, 0x65, 0x73,
+	0x73, 0x61, 0x67, 0x65, 0x12, 0x1d, 0x0a, 0x0a, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x5f,
+	0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f,
+	0x6e, 0x49, 0x64, 0x22, 0x10, 0x0a, 0x0e, 0x52, 0x65, 0x6c, 0x6f, 0x61, 0x64, 0x50, 0x72, 0x6f,
+	0x74, 0x6f, 0x62, 0x75, 0x66, 0x22, 0x13, 0x0a, 0x11, 0x52, 0x65, 0x6c, 0x6f, 0x61, 0x64, 0x41,
+	0x76, 0x72, 0x6f, 0x53, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x73, 0x22, 0x55, 0x0a, 0x15, 0x43, 0x6c,
+	0x75, 0x73, 0x74, 0x65, 0x72, 0x50, 0x72, 0x6f, 0x70, 0x6f, 0x73, 0x65, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x12, 0x19, 0x0a, 0x08, 0x73, 0x68, 0x61, 0x72, 0x64, 0x5f, 0x69, 0x64, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x07, 0x73, 0x68, 0x61, 0x72, 0x64, 0x49, 0x64, 0x12, 0x21,
+	0x0a, 0x0c, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x5f, 0x62, 0x6f, 0x64, 0x79, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x0c, 0x52, 0x0b, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x42, 0x6f, 0x64,
+	0x79, 0x22, 0x56, 0x0a, 0x16, 0x43, 0x6c, 0x75, 0x73, 0x74, 0x65, 0x72, 0x50, 0x72, 0x6f, 0x70,
+	0x6f, 0x73, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x17, 0x0a, 0x07, 0x72,
+	0x65, 0x74, 0x5f, 0x76, 0x61, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x06, 0x72, 0x65,
+	0x74, 0x56, 0x61, 0x6c, 0x12, 0x23, 0x0a, 0x0d, 0x72, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x5f, 0x62, 0x6f, 0x64, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0c, 0x72, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x42, 0x6f, 0x64, 0x79, 0x22, 0x52, 0x0a, 0x12, 0x43, 0x6c, 0x75,
+	0x73, 0x74, 0x65, 0x72, 0x52, 0x65, 0x61, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
+	0x19, 0x0a, 0x08, 0x73, 0x68, 0x61, 0x72, 0x64, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x07, 0x73, 0x68, 0x61, 0x72, 0x64, 0x49, 0x64, 0x12, 0x21, 0x0a, 0x0c, 0x72, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x5f, 0x62, 0x6f, 0x64, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c,
+	0x52, 0x0b, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x42, 0x6f, 0x64, 0x79, 0x22, 0x3a, 0x0a,
+	0x13, 0x43, 0x6c, 0x75, 0x73, 0x74, 0x65, 0x72, 0x52, 0x65, 0x61, 0x64, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x12, 0x23, 0x0a, 0x0d, 0x72, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x5f, 0x62, 0x6f, 0x64, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0c, 0x72, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x42, 0x6f, 0x64, 0x79, 0x22, 0x61, 0x0a, 0x12, 0x43, 0x68, 0x61,
+	0x6e, 0x67, 0x65, 0x46, 0x65, 0x65, 0x64, 0x50, 0x6f, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x12,
+	0x19, 0x0a, 0x08, 0x73, 0x68, 0x61, 0x72, 0x64, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x04, 0x52, 0x07, 0x73, 0x68, 0x61, 0x72, 0x64, 0x49, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x70,
+	0x6f, 0x63, 0x68, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x05, 0x65, 0x70, 0x6f, 0x63, 0x68,
+	0x12, 0x1a, 0x0a, 0x08, 0x73, 0x65, 0x71, 0x75, 0x65, 0x6e, 0x63, 0x65, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x04, 0x52, 0x08, 0x73, 0x65, 0x71, 0x75, 0x65, 0x6e, 0x63, 0x65, 0x22, 0xf8, 0x01, 0x0a,
+	0x13, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x46, 0x65, 0x65, 0x64, 0x53, 0x75, 0x62, 0x73, 0x63,
+	0x72, 0x69, 0x62, 0x65, 0x12, 0x27, 0x0a, 0x0f, 0x73, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x70,
+	0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e, 0x73,
+	0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x49, 0x64, 0x12, 0x17, 0x0a,
+	0x07, 0x6e, 0x6f, 0x64, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x06,
+	0x6e, 0x6f, 0x64, 0x65, 0x49, 0x64, 0x12, 0x1f, 0x0a, 0x0b, 0x73, 0x63, 0x68, 0x65, 0x6d, 0x61,
+	0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x73, 0x63, 0x68,
+	0x65, 0x6d, 0x61, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x17, 0x0a, 0x07, 0x6d, 0x76, 0x5f, 0x6e, 0x61,
+	0x6d, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x6d, 0x76, 0x4e, 0x61, 0x6d, 0x65,
+	0x12, 0x65, 0x0a, 0x10, 0x72, 0x65, 0x73, 0x75, 0x6d, 0x65, 0x5f, 0x70, 0x6f, 0x73, 0x69, 0x74,
+	0x69, 0x6f, 0x6e, 0x73, 0x18, 0x05, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x3a, 0x2e, 0x73, 0x71, 0x75,
+	0x61, 0x72, 0x65, 0x75, 0x70, 0x2e, 0x63, 0x61, 0x73, 0x68, 0x2e, 0x70, 0x72, 0x61, 0x6e, 0x61,
+	0x64, 0x62, 0x2e, 0x6e, 0x6f, 0x74, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73,
+	0x2e, 0x76, 0x31, 0x2e, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x46, 0x65, 0x65, 0x64, 0x50, 0x6f,
+	0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x0f, 0x72, 0x65, 0x73, 0x75, 0x6d, 0x65, 0x50, 0x6f,
+	0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x22, 0x40, 0x0a, 0x15, 0x43, 0x68, 0x61, 0x6e, 0x67,
+	0x65, 0x46, 0x65, 0x65, 0x64, 0x55, 0x6e, 0x73, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x62, 0x65,
+	0x12, 0x27, 0x0a, 0x0f, 0x73, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e,
+	0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e, 0x73, 0x75, 0x62, 0x73, 0x63,
+	0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x49, 0x64, 0x22, 0x72, 0x0a, 0x10, 0x43, 0x68, 0x61,
+	0x6e, 0x67, 0x65, 0x46, 0x65, 0x65, 0x64, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x12, 0x1a, 0x0a,
+	0x08, 0x73, 0x65, 0x71, 0x75, 0x65, 0x6e, 0x63, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52,
+	0x08, 0x73, 0x65, 0x71, 0x75, 0x65, 0x6e, 0x63, 0x65, 0x12, 0x21, 0x0a, 0x0c, 0x70, 0x72, 0x65,
+	0x76, 0x69, 0x6f, 0x75, 0x73, 0x5f, 0x72, 0x6f, 0x77, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52,
+	0x0b, 0x70, 0x72, 0x65, 0x76, 0x69, 0x6f, 0x75, 0x73, 0x52, 0x6f, 0x77, 0x12, 0x1f, 0x0a, 0x0b,
+	0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x74, 0x5f, 0x72, 0x6f, 0x77, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x0c, 0x52, 0x0a, 0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x74, 0x52, 0x6f, 0x77, 0x22, 0xd5, 0x02,
+	0x0a, 0x10, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x46, 0x65, 0x65, 0x64, 0x45, 0x76, 0x65, 0x6e,
+	0x74, 0x73, 0x12, 0x27, 0x0a, 0x0f, 0x73, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69,
+	0x6f, 0x6e, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e, 0x73, 0x75, 0x62,
+	0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x49, 0x64, 0x12, 0x19, 0x0a, 0x08, 0x73,
+	0x68, 0x61, 0x72, 0x64, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x07, 0x73,
+	0x68, 0x61, 0x72, 0x64, 0x49, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x70, 0x6f, 0x63, 0x68, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x05, 0x65, 0x70, 0x6f, 0x63, 0x68, 0x12, 0x23, 0x0a, 0x0d,
+	0x73, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x5f, 0x72, 0x6f, 0x77, 0x73, 0x18, 0x04, 0x20,
+	0x03, 0x28, 0x0c, 0x52, 0x0c, 0x73, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x52, 0x6f, 0x77,
+	0x73, 0x12, 0x2b, 0x0a, 0x11, 0x73, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x5f, 0x63, 0x6f,
+	0x6d, 0x70, 0x6c, 0x65, 0x74, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x52, 0x10, 0x73, 0x6e,
+	0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x43, 0x6f, 0x6d, 0x70, 0x6c, 0x65, 0x74, 0x65, 0x12, 0x2b,
+	0x0a, 0x11, 0x73, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x5f, 0x73, 0x65, 0x71, 0x75, 0x65,
+	0x6e, 0x63, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x04, 0x52, 0x10, 0x73, 0x6e, 0x61, 0x70, 0x73,
+	0x68, 0x6f, 0x74, 0x53, 0x65, 0x71, 0x75, 0x65, 0x6e, 0x63, 0x65, 0x12, 0x52, 0x0a, 0x07, 0x63,
+	0x68, 0x61, 0x6e, 0x67, 0x65, 0x73, 0x18, 0x07, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x38, 0x2e, 0x73,
+	0x71, 0x75, 0x61, 0x72, 0x65, 0x75, 0x70, 0x2e, 0x63, 0x61, 0x73, 0x68, 0x2e, 0x70, 0x72, 0x61,
+	0x6e, 0x61, 0x64, 0x62, 0x2e, 0x6e, 0x6f, 0x74, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x46, 0x65, 0x65, 0x64,
+	0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x52, 0x07, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x73, 0x12,
+	0x14, 0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x08, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05,
+	0x65, 0x72, 0x72, 0x6f, 0x72, 0x42, 0x4b, 0x5a, 0x49, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e,
+	0x63, 0x6f, 0x6d, 0x2f, 0x73, 0x71, 0x75, 0x61, 0x72, 0x65, 0x75, 0x70, 0x2f, 0x70, 0x72, 0x61,
+	0x6e, 0x61, 0x64, 0x62, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x73, 0x2f, 0x73, 0x71, 0x75, 0x61,
+	0x72, 0x65, 0x75, 0x70, 0x2f, 0x63, 0x61, 0x73, 0x68, 0x2f, 0x70, 0x72, 0x61, 0x6e, 0x61, 0x64,
+	0x62, 0x2f, 0x76, 0x31, 0x2f, 0x6e, 0x6f, 0x74, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x73, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -481,22 +1042,32 @@ func file_squareup_cash_pranadb_notifications_v1_notifications_proto_rawDescGZIP
 	return file_squareup_cash_pranadb_notifications_v1_notifications_proto_rawDescData
 }
 
-var file_squareup_cash_pranadb_notifications_v1_notifications_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
+var file_squareup_cash_pranadb_notifications_v1_notifications_proto_msgTypes = make([]protoimpl.MessageInfo, 15)
 var file_squareup_cash_pranadb_notifications_v1_notifications_proto_goTypes = []interface{}{
 	(*DDLStatementInfo)(nil),        // 0: squareup.cash.pranadb.notifications.v1.DDLStatementInfo
-	(*NotificationTestMessage)(nil), // 1: squareup.cash.pranadb.notifications.v1.NotificationTestMessage
-	(*ReloadProtobuf)(nil),          // 2: squareup.cash.pranadb.notifications.v1.ReloadProtobuf
-	(*ClusterProposeRequest)(nil),   // 3: squareup.cash.pranadb.notifications.v1.ClusterProposeRequest
-	(*ClusterProposeResponse)(nil),  // 4: squareup.cash.pranadb.notifications.v1.ClusterProposeResponse
-	(*ClusterReadRequest)(nil),      // 5: squareup.cash.pranadb.notifications.v1.ClusterReadRequest
-	(*ClusterReadResponse)(nil),     // 6: squareup.cash.pranadb.notifications.v1.ClusterReadResponse
+	(*DMLStatementInfo)(nil),        // 1: squareup.cash.pranadb.notifications.v1.DMLStatementInfo
+	(*DMLStatementResponse)(nil),    // 2: squareup.cash.pranadb.notifications.v1.DMLStatementResponse
+	(*NotificationTestMessage)(nil), // 3: squareup.cash.pranadb.notifications.v1.NotificationTestMessage
+	(*ReloadProtobuf)(nil),          // 4: squareup.cash.pranadb.notifications.v1.ReloadProtobuf
+	(*ReloadAvroSchemas)(nil),       // 5: squareup.cash.pranadb.notifications.v1.ReloadAvroSchemas
+	(*ClusterProposeRequest)(nil),   // 6: squareup.cash.pranadb.notifications.v1.ClusterProposeRequest
+	(*ClusterProposeResponse)(nil),  // 7: squareup.cash.pranadb.notifications.v1.ClusterProposeResponse
+	(*ClusterReadRequest)(nil),      // 8: squareup.cash.pranadb.notifications.v1.ClusterReadRequest
+	(*ClusterReadResponse)(nil),     // 9: squareup.cash.pranadb.notifications.v1.ClusterReadResponse
+	(*ChangeFeedPosition)(nil),      // 10: squareup.cash.pranadb.notifications.v1.ChangeFeedPosition
+	(*ChangeFeedSubscribe)(nil),     // 11: squareup.cash.pranadb.notifications.v1.ChangeFeedSubscribe
+	(*ChangeFeedUnsubscribe)(nil),   // 12: squareup.cash.pranadb.notifications.v1.ChangeFeedUnsubscribe
+	(*ChangeFeedChange)(nil),        // 13: squareup.cash.pranadb.notifications.v1.ChangeFeedChange
+	(*ChangeFeedEvents)(nil),        // 14: squareup.cash.pranadb.notifications.v1.ChangeFeedEvents
 }
 var file_squareup_cash_pranadb_notifications_v1_notifications_proto_depIdxs = []int32{
-	0, // [0:0] is the sub-list for method output_type
-	0, // [0:0] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	10, // 0: squareup.cash.pranadb.notifications.v1.ChangeFeedSubscribe.resume_positions:type_name -> squareup.cash.pranadb.notifications.v1.ChangeFeedPosition
+	13, // 1: squareup.cash.pranadb.notifications.v1.ChangeFeedEvents.changes:type_name -> squareup.cash.pranadb.notifications.v1.ChangeFeedChange
+	2,  // [2:2] is the sub-list for method output_type
+	2,  // [2:2] is the sub-list for method input_type
+	2,  // [2:2] is the sub-list for extension type_name
+	2,  // [2:2] is the sub-list for extension extendee
+	0,  // [0:2] is the sub-list for field type_name
 }
 
 func init() { file_squareup_cash_pranadb_notifications_v1_notifications_proto_init() }
@@ -518,7 +1089,7 @@ func file_squareup_cash_pranadb_notifications_v1_notifications_proto_init() {
 			}
 		}
 		file_squareup_cash_pranadb_notifications_v1_notifications_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*NotificationTestMessage); i {
+			switch v := v.(*DMLStatementInfo); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -530,7 +1101,7 @@ func file_squareup_cash_pranadb_notifications_v1_notifications_proto_init() {
 			}
 		}
 		file_squareup_cash_pranadb_notifications_v1_notifications_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*ReloadProtobuf); i {
+			switch v := v.(*DMLStatementResponse); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -542,7 +1113,7 @@ func file_squareup_cash_pranadb_notifications_v1_notifications_proto_init() {
 			}
 		}
 		file_squareup_cash_pranadb_notifications_v1_notifications_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*ClusterProposeRequest); i {
+			switch v := v.(*NotificationTestMessage); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -554,7 +1125,7 @@ func file_squareup_cash_pranadb_notifications_v1_notifications_proto_init() {
 			}
 		}
 		file_squareup_cash_pranadb_notifications_v1_notifications_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*ClusterProposeResponse); i {
+			switch v := v.(*ReloadProtobuf); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -566,7 +1137,7 @@ func file_squareup_cash_pranadb_notifications_v1_notifications_proto_init() {
 			}
 		}
 		file_squareup_cash_pranadb_notifications_v1_notifications_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*ClusterReadRequest); i {
+			switch v := v.(*ReloadAvroSchemas); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -578,6 +1149,42 @@ func file_squareup_cash_pranadb_notifications_v1_notifications_proto_init() {
 			}
 		}
 		file_squareup_cash_pranadb_notifications_v1_notifications_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ClusterProposeRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_squareup_cash_pranadb_notifications_v1_notifications_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ClusterProposeResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_squareup_cash_pranadb_notifications_v1_notifications_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ClusterReadRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_squareup_cash_pranadb_notifications_v1_notifications_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*ClusterReadResponse); i {
 			case 0:
 				return &v.state
@@ -589,6 +1196,66 @@ func file_squareup_cash_pranadb_notifications_v1_notifications_proto_init() {
 				return nil
 			}
 		}
+		file_squareup_cash_pranadb_notifications_v1_notifications_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ChangeFeedPosition); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_squareup_cash_pranadb_notifications_v1_notifications_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ChangeFeedSubscribe); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_squareup_cash_pranadb_notifications_v1_notifications_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ChangeFeedUnsubscribe); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_squareup_cash_pranadb_notifications_v1_notifications_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ChangeFeedChange); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_squareup_cash_pranadb_notifications_v1_notifications_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ChangeFeedEvents); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
@@ -596,7 +1263,7 @@ func file_squareup_cash_pranadb_notifications_v1_notifications_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_squareup_cash_pranadb_notifications_v1_notifications_proto_rawDesc,
 			NumEnums:      0,
-			NumMessages:   7,
+			NumMessages:   15,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
@@ -37,19 +37,27 @@ const (
 	ColumnType_COLUMN_TYPE_DECIMAL     ColumnType = 5
 	ColumnType_COLUMN_TYPE_VARCHAR     ColumnType = 6
 	ColumnType_COLUMN_TYPE_TIMESTAMP   ColumnType = 7
+	ColumnType_COLUMN_TYPE_BOOLEAN     ColumnType = 8
+	ColumnType_COLUMN_TYPE_DATE        ColumnType = 9
+	ColumnType_COLUMN_TYPE_VARBINARY   ColumnType = 10
+	ColumnType_COLUMN_TYPE_JSON        ColumnType = 11
 )
 
 // Enum value maps for ColumnType.
 var (
 	ColumnType_name = map[int32]string{
-		0: "COLUMN_TYPE_UNSPECIFIED",
-		1: "COLUMN_TYPE_TINY_INT",
-		2: "COLUMN_TYPE_INT",
-		3: "COLUMN_TYPE_BIG_INT",
-		4: "COLUMN_TYPE_DOUBLE",
-		5: "COLUMN_TYPE_DECIMAL",
-		6: "COLUMN_TYPE_VARCHAR",
-		7: "COLUMN_TYPE_TIMESTAMP",
+		0:  "COLUMN_TYPE_UNSPECIFIED",
+		1:  "COLUMN_TYPE_TINY_INT",
+		2:  "COLUMN_TYPE_INT",
+		3:  "COLUMN_TYPE_BIG_INT",
+		4:  "COLUMN_TYPE_DOUBLE",
+		5:  "COLUMN_TYPE_DECIMAL",
+		6:  "COLUMN_TYPE_VARCHAR",
+		7:  "COLUMN_TYPE_TIMESTAMP",
+		8:  "COLUMN_TYPE_BOOLEAN",
+		9:  "COLUMN_TYPE_DATE",
+		10: "COLUMN_TYPE_VARBINARY",
+		11: "COLUMN_TYPE_JSON",
 	}
 	ColumnType_value = map[string]int32{
 		"COLUMN_TYPE_UNSPECIFIED": 0,
@@ -60,6 +68,10 @@ var (
 		"COLUMN_TYPE_DECIMAL":     5,
 		"COLUMN_TYPE_VARCHAR":     6,
 		"COLUMN_TYPE_TIMESTAMP":   7,
+		"COLUMN_TYPE_BOOLEAN":     8,
+		"COLUMN_TYPE_DATE":        9,
+		"COLUMN_TYPE_VARBINARY":   10,
+		"COLUMN_TYPE_JSON":        11,
 	}
 )
 
@@ -90,6 +102,61 @@ func (ColumnType) EnumDescriptor() ([]byte, []int) {
 	return file_squareup_cash_pranadb_service_v1_service_proto_rawDescGZIP(), []int{0}
 }
 
+type ChangeType int32
+
+const (
+	ChangeType_CHANGE_TYPE_UNSPECIFIED ChangeType = 0
+	ChangeType_CHANGE_TYPE_SNAPSHOT    ChangeType = 1 // A row of the initial snapshot of the materialized view.
+	ChangeType_CHANGE_TYPE_INSERT      ChangeType = 2
+	ChangeType_CHANGE_TYPE_UPDATE      ChangeType = 3
+	ChangeType_CHANGE_TYPE_DELETE      ChangeType = 4
+)
+
+// Enum value maps for ChangeType.
+var (
+	ChangeType_name = map[int32]string{
+		0: "CHANGE_TYPE_UNSPECIFIED",
+		1: "CHANGE_TYPE_SNAPSHOT",
+		2: "CHANGE_TYPE_INSERT",
+		3: "CHANGE_TYPE_UPDATE",
+		4: "CHANGE_TYPE_DELETE",
+	}
+	ChangeType_value = map[string]int32{
+		"CHANGE_TYPE_UNSPECIFIED": 0,
+		"CHANGE_TYPE_SNAPSHOT":    1,
+		"CHANGE_TYPE_INSERT":      2,
+		"CHANGE_TYPE_UPDATE":      3,
+		"CHANGE_TYPE_DELETE":      4,
+	}
+)
+
+func (x ChangeType) Enum() *ChangeType {
+	p := new(ChangeType)
+	*p = x
+	return p
+}
+
+func (x ChangeType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ChangeType) Descriptor() protoreflect.EnumDescriptor {
+	return file_squareup_cash_pranadb_service_v1_service_proto_enumTypes[1].Descriptor()
+}
+
+func (ChangeType) Type() protoreflect.EnumType {
+	return &file_squareup_cash_pranadb_service_v1_service_proto_enumTypes[1]
+}
+
+func (x ChangeType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ChangeType.Descriptor instead.
+func (ChangeType) EnumDescriptor() ([]byte, []int) {
+	return file_squareup_cash_pranadb_service_v1_service_proto_rawDescGZIP(), []int{1}
+}
+
 type DecimalParams struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -378,6 +445,7 @@ type ColValue struct {
 	//	*ColValue_IntValue
 	//	*ColValue_FloatValue
 	//	*ColValue_StringValue
+	//	*ColValue_BytesValue
 	Value isColValue_Value `protobuf_oneof:"value"`
 }
 
@@ -448,6 +516,13 @@ func (x *ColValue) GetStringValue() string {
 	return ""
 }
 
+func (x *ColValue) GetBytesValue() []byte {
+	if x, ok := x.GetValue().(*ColValue_BytesValue); ok {
+		return x.BytesValue
+	}
+	return nil
+}
+
 type isColValue_Value interface {
 	isColValue_Value()
 }
@@ -468,6 +543,10 @@ type ColValue_StringValue struct {
 	StringValue string `protobuf:"bytes,4,opt,name=string_value,json=stringValue,proto3,oneof"`
 }
 
+type ColValue_BytesValue struct {
+	BytesValue []byte `protobuf:"bytes,5,opt,name=bytes_value,json=bytesValue,proto3,oneof"`
+}
+
 func (*ColValue_IsNull) isColValue_Value() {}
 
 func (*ColValue_IntValue) isColValue_Value() {}
@@ -476,6 +555,8 @@ func (*ColValue_FloatValue) isColValue_Value() {}
 
 func (*ColValue_StringValue) isColValue_Value() {}
 
+func (*ColValue_BytesValue) isColValue_Value() {}
+
 // Each query may return an arbitrary number of pages.
 type Page struct {
 	state         protoimpl.MessageState
@@ -659,252 +740,1154 @@ func (x *RegisterProtobufsRequest) GetDescriptors() *descriptorpb.FileDescriptor
 	return nil
 }
 
-var File_squareup_cash_pranadb_service_v1_service_proto protoreflect.FileDescriptor
+// An Avro schema, with the id which messages encoded with it give in the schema registry wire format.
+type AvroSchema struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
 
-var file_squareup_cash_pranadb_service_v1_service_proto_rawDesc = []byte{
-	0x0a, 0x2e, 0x73, 0x71, 0x75, 0x61, 0x72, 0x65, 0x75, 0x70, 0x2f, 0x63, 0x61, 0x73, 0x68, 0x2f,
-	0x70, 0x72, 0x61, 0x6e, 0x61, 0x64, 0x62, 0x2f, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2f,
-	0x76, 0x31, 0x2f, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
-	0x12, 0x20, 0x73, 0x71, 0x75, 0x61, 0x72, 0x65, 0x75, 0x70, 0x2e, 0x63, 0x61, 0x73, 0x68, 0x2e,
-	0x70, 0x72, 0x61, 0x6e, 0x61, 0x64, 0x62, 0x2e, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e,
-	0x76, 0x31, 0x1a, 0x1b, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f,
-	0x62, 0x75, 0x66, 0x2f, 0x65, 0x6d, 0x70, 0x74, 0x79, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a,
-	0x20, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66,
-	0x2f, 0x64, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x6f, 0x72, 0x2e, 0x70, 0x72, 0x6f, 0x74,
-	0x6f, 0x22, 0x61, 0x0a, 0x0d, 0x44, 0x65, 0x63, 0x69, 0x6d, 0x61, 0x6c, 0x50, 0x61, 0x72, 0x61,
-	0x6d, 0x73, 0x12, 0x2b, 0x0a, 0x11, 0x64, 0x65, 0x63, 0x69, 0x6d, 0x61, 0x6c, 0x5f, 0x70, 0x72,
-	0x65, 0x63, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x10, 0x64,
-	0x65, 0x63, 0x69, 0x6d, 0x61, 0x6c, 0x50, 0x72, 0x65, 0x63, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x12,
-	0x23, 0x0a, 0x0d, 0x64, 0x65, 0x63, 0x69, 0x6d, 0x61, 0x6c, 0x5f, 0x73, 0x63, 0x61, 0x6c, 0x65,
-	0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0c, 0x64, 0x65, 0x63, 0x69, 0x6d, 0x61, 0x6c, 0x53,
-	0x63, 0x61, 0x6c, 0x65, 0x22, 0xce, 0x01, 0x0a, 0x06, 0x43, 0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x12,
-	0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e,
-	0x61, 0x6d, 0x65, 0x12, 0x40, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28,
-	0x0e, 0x32, 0x2c, 0x2e, 0x73, 0x71, 0x75, 0x61, 0x72, 0x65, 0x75, 0x70, 0x2e, 0x63, 0x61, 0x73,
-	0x68, 0x2e, 0x70, 0x72, 0x61, 0x6e, 0x61, 0x64, 0x62, 0x2e, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63,
-	0x65, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x54, 0x79, 0x70, 0x65, 0x52,
-	0x04, 0x74, 0x79, 0x70, 0x65, 0x12, 0x5b, 0x0a, 0x0e, 0x64, 0x65, 0x63, 0x69, 0x6d, 0x61, 0x6c,
-	0x5f, 0x70, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x2f, 0x2e,
-	0x73, 0x71, 0x75, 0x61, 0x72, 0x65, 0x75, 0x70, 0x2e, 0x63, 0x61, 0x73, 0x68, 0x2e, 0x70, 0x72,
-	0x61, 0x6e, 0x61, 0x64, 0x62, 0x2e, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x76, 0x31,
-	0x2e, 0x44, 0x65, 0x63, 0x69, 0x6d, 0x61, 0x6c, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x48, 0x00,
-	0x52, 0x0d, 0x64, 0x65, 0x63, 0x69, 0x6d, 0x61, 0x6c, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x88,
-	0x01, 0x01, 0x42, 0x11, 0x0a, 0x0f, 0x5f, 0x64, 0x65, 0x63, 0x69, 0x6d, 0x61, 0x6c, 0x5f, 0x70,
-	0x61, 0x72, 0x61, 0x6d, 0x73, 0x22, 0x6f, 0x0a, 0x1a, 0x45, 0x78, 0x65, 0x63, 0x75, 0x74, 0x65,
-	0x53, 0x51, 0x4c, 0x53, 0x74, 0x61, 0x74, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x52, 0x65, 0x71, 0x75,
-	0x65, 0x73, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x18, 0x01, 0x20,
-	0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x12, 0x1c, 0x0a, 0x09, 0x73,
-	0x74, 0x61, 0x74, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09,
-	0x73, 0x74, 0x61, 0x74, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x1b, 0x0a, 0x09, 0x70, 0x61, 0x67,
-	0x65, 0x5f, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x70, 0x61,
-	0x67, 0x65, 0x53, 0x69, 0x7a, 0x65, 0x22, 0x4d, 0x0a, 0x07, 0x43, 0x6f, 0x6c, 0x75, 0x6d, 0x6e,
-	0x73, 0x12, 0x42, 0x0a, 0x07, 0x63, 0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x73, 0x18, 0x01, 0x20, 0x03,
-	0x28, 0x0b, 0x32, 0x28, 0x2e, 0x73, 0x71, 0x75, 0x61, 0x72, 0x65, 0x75, 0x70, 0x2e, 0x63, 0x61,
-	0x73, 0x68, 0x2e, 0x70, 0x72, 0x61, 0x6e, 0x61, 0x64, 0x62, 0x2e, 0x73, 0x65, 0x72, 0x76, 0x69,
-	0x63, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x52, 0x07, 0x63, 0x6f,
-	0x6c, 0x75, 0x6d, 0x6e, 0x73, 0x22, 0x49, 0x0a, 0x03, 0x52, 0x6f, 0x77, 0x12, 0x42, 0x0a, 0x06,
-	0x76, 0x61, 0x6c, 0x75, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x2a, 0x2e, 0x73,
-	0x71, 0x75, 0x61, 0x72, 0x65, 0x75, 0x70, 0x2e, 0x63, 0x61, 0x73, 0x68, 0x2e, 0x70, 0x72, 0x61,
-	0x6e, 0x61, 0x64, 0x62, 0x2e, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x76, 0x31, 0x2e,
-	0x43, 0x6f, 0x6c, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x52, 0x06, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x73,
-	0x22, 0x95, 0x01, 0x0a, 0x08, 0x43, 0x6f, 0x6c, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x12, 0x19, 0x0a,
-	0x07, 0x69, 0x73, 0x5f, 0x6e, 0x75, 0x6c, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x48, 0x00,
-	0x52, 0x06, 0x69, 0x73, 0x4e, 0x75, 0x6c, 0x6c, 0x12, 0x1d, 0x0a, 0x09, 0x69, 0x6e, 0x74, 0x5f,
-	0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x48, 0x00, 0x52, 0x08, 0x69,
-	0x6e, 0x74, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x12, 0x21, 0x0a, 0x0b, 0x66, 0x6c, 0x6f, 0x61, 0x74,
-	0x5f, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x48, 0x00, 0x52, 0x0a,
-	0x66, 0x6c, 0x6f, 0x61, 0x74, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x12, 0x23, 0x0a, 0x0c, 0x73, 0x74,
-	0x72, 0x69, 0x6e, 0x67, 0x5f, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09,
-	0x48, 0x00, 0x52, 0x0b, 0x73, 0x74, 0x72, 0x69, 0x6e, 0x67, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x42,
-	0x07, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x22, 0x57, 0x0a, 0x04, 0x50, 0x61, 0x67, 0x65,
-	0x12, 0x14, 0x0a, 0x05, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52,
-	0x05, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x39, 0x0a, 0x04, 0x72, 0x6f, 0x77, 0x73, 0x18, 0x02,
-	0x20, 0x03, 0x28, 0x0b, 0x32, 0x25, 0x2e, 0x73, 0x71, 0x75, 0x61, 0x72, 0x65, 0x75, 0x70, 0x2e,
-	0x63, 0x61, 0x73, 0x68, 0x2e, 0x70, 0x72, 0x61, 0x6e, 0x61, 0x64, 0x62, 0x2e, 0x73, 0x65, 0x72,
-	0x76, 0x69, 0x63, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x6f, 0x77, 0x52, 0x04, 0x72, 0x6f, 0x77,
-	0x73, 0x22, 0xac, 0x01, 0x0a, 0x1b, 0x45, 0x78, 0x65, 0x63, 0x75, 0x74, 0x65, 0x53, 0x51, 0x4c,
-	0x53, 0x74, 0x61, 0x74, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
-	0x65, 0x12, 0x45, 0x0a, 0x07, 0x63, 0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x73, 0x18, 0x01, 0x20, 0x01,
-	0x28, 0x0b, 0x32, 0x29, 0x2e, 0x73, 0x71, 0x75, 0x61, 0x72, 0x65, 0x75, 0x70, 0x2e, 0x63, 0x61,
-	0x73, 0x68, 0x2e, 0x70, 0x72, 0x61, 0x6e, 0x61, 0x64, 0x62, 0x2e, 0x73, 0x65, 0x72, 0x76, 0x69,
-	0x63, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x73, 0x48, 0x00, 0x52,
-	0x07, 0x63, 0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x73, 0x12, 0x3c, 0x0a, 0x04, 0x70, 0x61, 0x67, 0x65,
-	0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x26, 0x2e, 0x73, 0x71, 0x75, 0x61, 0x72, 0x65, 0x75,
-	0x70, 0x2e, 0x63, 0x61, 0x73, 0x68, 0x2e, 0x70, 0x72, 0x61, 0x6e, 0x61, 0x64, 0x62, 0x2e, 0x73,
-	0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x50, 0x61, 0x67, 0x65, 0x48, 0x00,
-	0x52, 0x04, 0x70, 0x61, 0x67, 0x65, 0x42, 0x08, 0x0a, 0x06, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74,
-	0x22, 0x60, 0x0a, 0x18, 0x52, 0x65, 0x67, 0x69, 0x73, 0x74, 0x65, 0x72, 0x50, 0x72, 0x6f, 0x74,
-	0x6f, 0x62, 0x75, 0x66, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x44, 0x0a, 0x0b,
-	0x64, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x6f, 0x72, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28,
-	0x0b, 0x32, 0x22, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
-	0x62, 0x75, 0x66, 0x2e, 0x46, 0x69, 0x6c, 0x65, 0x44, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74,
-	0x6f, 0x72, 0x53, 0x65, 0x74, 0x52, 0x0b, 0x64, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x6f,
-	0x72, 0x73, 0x2a, 0xd6, 0x01, 0x0a, 0x0a, 0x43, 0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x54, 0x79, 0x70,
-	0x65, 0x12, 0x1b, 0x0a, 0x17, 0x43, 0x4f, 0x4c, 0x55, 0x4d, 0x4e, 0x5f, 0x54, 0x59, 0x50, 0x45,
-	0x5f, 0x55, 0x4e, 0x53, 0x50, 0x45, 0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10, 0x00, 0x12, 0x18,
-	0x0a, 0x14, 0x43, 0x4f, 0x4c, 0x55, 0x4d, 0x4e, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x54, 0x49,
-	0x4e, 0x59, 0x5f, 0x49, 0x4e, 0x54, 0x10, 0x01, 0x12, 0x13, 0x0a, 0x0f, 0x43, 0x4f, 0x4c, 0x55,
-	0x4d, 0x4e, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x49, 0x4e, 0x54, 0x10, 0x02, 0x12, 0x17, 0x0a,
-	0x13, 0x43, 0x4f, 0x4c, 0x55, 0x4d, 0x4e, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x42, 0x49, 0x47,
-	0x5f, 0x49, 0x4e, 0x54, 0x10, 0x03, 0x12, 0x16, 0x0a, 0x12, 0x43, 0x4f, 0x4c, 0x55, 0x4d, 0x4e,
-	0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x44, 0x4f, 0x55, 0x42, 0x4c, 0x45, 0x10, 0x04, 0x12, 0x17,
-	0x0a, 0x13, 0x43, 0x4f, 0x4c, 0x55, 0x4d, 0x4e, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x44, 0x45,
-	0x43, 0x49, 0x4d, 0x41, 0x4c, 0x10, 0x05, 0x12, 0x17, 0x0a, 0x13, 0x43, 0x4f, 0x4c, 0x55, 0x4d,
-	0x4e, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x56, 0x41, 0x52, 0x43, 0x48, 0x41, 0x52, 0x10, 0x06,
-	0x12, 0x19, 0x0a, 0x15, 0x43, 0x4f, 0x4c, 0x55, 0x4d, 0x4e, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f,
-	0x54, 0x49, 0x4d, 0x45, 0x53, 0x54, 0x41, 0x4d, 0x50, 0x10, 0x07, 0x32, 0x90, 0x02, 0x0a, 0x0e,
-	0x50, 0x72, 0x61, 0x6e, 0x61, 0x44, 0x42, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x94,
-	0x01, 0x0a, 0x13, 0x45, 0x78, 0x65, 0x63, 0x75, 0x74, 0x65, 0x53, 0x51, 0x4c, 0x53, 0x74, 0x61,
-	0x74, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x3c, 0x2e, 0x73, 0x71, 0x75, 0x61, 0x72, 0x65, 0x75,
-	0x70, 0x2e, 0x63, 0x61, 0x73, 0x68, 0x2e, 0x70, 0x72, 0x61, 0x6e, 0x61, 0x64, 0x62, 0x2e, 0x73,
-	0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x45, 0x78, 0x65, 0x63, 0x75, 0x74,
-	0x65, 0x53, 0x51, 0x4c, 0x53, 0x74, 0x61, 0x74, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x52, 0x65, 0x71,
-	0x75, 0x65, 0x73, 0x74, 0x1a, 0x3d, 0x2e, 0x73, 0x71, 0x75, 0x61, 0x72, 0x65, 0x75, 0x70, 0x2e,
-	0x63, 0x61, 0x73, 0x68, 0x2e, 0x70, 0x72, 0x61, 0x6e, 0x61, 0x64, 0x62, 0x2e, 0x73, 0x65, 0x72,
-	0x76, 0x69, 0x63, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x45, 0x78, 0x65, 0x63, 0x75, 0x74, 0x65, 0x53,
-	0x51, 0x4c, 0x53, 0x74, 0x61, 0x74, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f,
-	0x6e, 0x73, 0x65, 0x30, 0x01, 0x12, 0x67, 0x0a, 0x11, 0x52, 0x65, 0x67, 0x69, 0x73, 0x74, 0x65,
-	0x72, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x73, 0x12, 0x3a, 0x2e, 0x73, 0x71, 0x75,
-	0x61, 0x72, 0x65, 0x75, 0x70, 0x2e, 0x63, 0x61, 0x73, 0x68, 0x2e, 0x70, 0x72, 0x61, 0x6e, 0x61,
-	0x64, 0x62, 0x2e, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65,
-	0x67, 0x69, 0x73, 0x74, 0x65, 0x72, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x73, 0x52,
-	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e,
-	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x42, 0x45,
-	0x5a, 0x43, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x73, 0x71, 0x75,
-	0x61, 0x72, 0x65, 0x75, 0x70, 0x2f, 0x70, 0x72, 0x61, 0x6e, 0x61, 0x64, 0x62, 0x2f, 0x70, 0x72,
-	0x6f, 0x74, 0x6f, 0x73, 0x2f, 0x73, 0x71, 0x75, 0x61, 0x72, 0x65, 0x75, 0x70, 0x2f, 0x63, 0x61,
-	0x73, 0x68, 0x2f, 0x70, 0x72, 0x61, 0x6e, 0x61, 0x64, 0x62, 0x2f, 0x76, 0x31, 0x2f, 0x73, 0x65,
-	0x72, 0x76, 0x69, 0x63, 0x65, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	Id     int32  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Schema string `protobuf:"bytes,2,opt,name=schema,proto3" json:"schema,omitempty"`
 }
 
-var (
-	file_squareup_cash_pranadb_service_v1_service_proto_rawDescOnce sync.Once
-	file_squareup_cash_pranadb_service_v1_service_proto_rawDescData = file_squareup_cash_pranadb_service_v1_service_proto_rawDesc
-)
+func (x *AvroSchema) Reset() {
+	*x = AvroSchema{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_squareup_cash_pranadb_service_v1_service_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
 
-func file_squareup_cash_pranadb_service_v1_service_proto_rawDescGZIP() []byte {
-	file_squareup_cash_pranadb_service_v1_service_proto_rawDescOnce.Do(func() {
-		file_squareup_cash_pranadb_service_v1_service_proto_rawDescData = protoimpl.X.CompressGZIP(file_squareup_cash_pranadb_service_v1_service_proto_rawDescData)
-	})
-	return file_squareup_cash_pranadb_service_v1_service_proto_rawDescData
+func (x *AvroSchema) String() string {
+	return protoimpl.X.MessageStringOf(x)
 }
 
-var file_squareup_cash_pranadb_service_v1_service_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_squareup_cash_pranadb_service_v1_service_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
-var file_squareup_cash_pranadb_service_v1_service_proto_goTypes = []interface{}{
-	(ColumnType)(0),                        // 0: squareup.cash.pranadb.service.v1.ColumnType
-	(*DecimalParams)(nil),                  // 1: squareup.cash.pranadb.service.v1.DecimalParams
-	(*Column)(nil),                         // 2: squareup.cash.pranadb.service.v1.Column
-	(*ExecuteSQLStatementRequest)(nil),     // 3: squareup.cash.pranadb.service.v1.ExecuteSQLStatementRequest
-	(*Columns)(nil),                        // 4: squareup.cash.pranadb.service.v1.Columns
-	(*Row)(nil),                            // 5: squareup.cash.pranadb.service.v1.Row
-	(*ColValue)(nil),                       // 6: squareup.cash.pranadb.service.v1.ColValue
-	(*Page)(nil),                           // 7: squareup.cash.pranadb.service.v1.Page
-	(*ExecuteSQLStatementResponse)(nil),    // 8: squareup.cash.pranadb.service.v1.ExecuteSQLStatementResponse
-	(*RegisterProtobufsRequest)(nil),       // 9: squareup.cash.pranadb.service.v1.RegisterProtobufsRequest
-	(*descriptorpb.FileDescriptorSet)(nil), // 10: google.protobuf.FileDescriptorSet
-	(*emptypb.Empty)(nil),                  // 11: google.protobuf.Empty
+func (*AvroSchema) ProtoMessage() {}
+
+func (x *AvroSchema) ProtoReflect() protoreflect.Message {
+	mi := &file_squareup_cash_pranadb_service_v1_service_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
 }
-var file_squareup_cash_pranadb_service_v1_service_proto_depIdxs = []int32{
-	0,  // 0: squareup.cash.pranadb.service.v1.Column.type:type_name -> squareup.cash.pranadb.service.v1.ColumnType
-	1,  // 1: squareup.cash.pranadb.service.v1.Column.decimal_params:type_name -> squareup.cash.pranadb.service.v1.DecimalParams
-	2,  // 2: squareup.cash.pranadb.service.v1.Columns.columns:type_name -> squareup.cash.pranadb.service.v1.Column
-	6,  // 3: squareup.cash.pranadb.service.v1.Row.values:type_name -> squareup.cash.pranadb.service.v1.ColValue
-	5,  // 4: squareup.cash.pranadb.service.v1.Page.rows:type_name -> squareup.cash.pranadb.service.v1.Row
-	4,  // 5: squareup.cash.pranadb.service.v1.ExecuteSQLStatementResponse.columns:type_name -> squareup.cash.pranadb.service.v1.Columns
-	7,  // 6: squareup.cash.pranadb.service.v1.ExecuteSQLStatementResponse.page:type_name -> squareup.cash.pranadb.service.v1.Page
-	10, // 7: squareup.cash.pranadb.service.v1.RegisterProtobufsRequest.descriptors:type_name -> google.protobuf.FileDescriptorSet
-	3,  // 8: squareup.cash.pranadb.service.v1.PranaDBService.ExecuteSQLStatement:input_type -> squareup.cash.pranadb.service.v1.ExecuteSQLStatementRequest
-	9,  // 9: squareup.cash.pranadb.service.v1.PranaDBService.RegisterProtobufs:input_type -> squareup.cash.pranadb.service.v1.RegisterProtobufsRequest
-	8,  // 10: squareup.cash.pranadb.service.v1.PranaDBService.ExecuteSQLStatement:output_type -> squareup.cash.pranadb.service.v1.ExecuteSQLStatementResponse
-	11, // 11: squareup.cash.pranadb.service.v1.PranaDBService.RegisterProtobufs:output_type -> google.protobuf.Empty
-	10, // [10:12] is the sub-list for method output_type
-	8,  // [8:10] is the sub-list for method input_type
-	8,  // [8:8] is the sub-list for extension type_name
-	8,  // [8:8] is the sub-list for extension extendee
-	0,  // [0:8] is the sub-list for field type_name
+
+// Deprecated: Use AvroSchema.ProtoReflect.Descriptor instead.
+func (*AvroSchema) Descriptor() ([]byte, []int) {
+	return file_squareup_cash_pranadb_service_v1_service_proto_rawDescGZIP(), []int{9}
 }
 
-func init() { file_squareup_cash_pranadb_service_v1_service_proto_init() }
-func file_squareup_cash_pranadb_service_v1_service_proto_init() {
-	if File_squareup_cash_pranadb_service_v1_service_proto != nil {
-		return
+func (x *AvroSchema) GetId() int32 {
+	if x != nil {
+		return x.Id
 	}
-	if !protoimpl.UnsafeEnabled {
-		file_squareup_cash_pranadb_service_v1_service_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*DecimalParams); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-		file_squareup_cash_pranadb_service_v1_service_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Column); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-		file_squareup_cash_pranadb_service_v1_service_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*ExecuteSQLStatementRequest); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-		file_squareup_cash_pranadb_service_v1_service_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Columns); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
+	return 0
+}
+
+func (x *AvroSchema) GetSchema() string {
+	if x != nil {
+		return x.Schema
+	}
+	return ""
+}
+
+type RegisterAvroSchemasRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Schemas []*AvroSchema `protobuf:"bytes,1,rep,name=schemas,proto3" json:"schemas,omitempty"`
+}
+
+func (x *RegisterAvroSchemasRequest) Reset() {
+	*x = RegisterAvroSchemasRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_squareup_cash_pranadb_service_v1_service_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RegisterAvroSchemasRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RegisterAvroSchemasRequest) ProtoMessage() {}
+
+func (x *RegisterAvroSchemasRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_squareup_cash_pranadb_service_v1_service_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
 		}
-		file_squareup_cash_pranadb_service_v1_service_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Row); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RegisterAvroSchemasRequest.ProtoReflect.Descriptor instead.
+func (*RegisterAvroSchemasRequest) Descriptor() ([]byte, []int) {
+	return file_squareup_cash_pranadb_service_v1_service_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *RegisterAvroSchemasRequest) GetSchemas() []*AvroSchema {
+	if x != nil {
+		return x.Schemas
+	}
+	return nil
+}
+
+// Subscribe to the changes to a materialized view.
+type SubscribeRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Schema           string `protobuf:"bytes,1,opt,name=schema,proto3" json:"schema,omitempty"`
+	MaterializedView string `protobuf:"bytes,2,opt,name=materialized_view,json=materializedView,proto3" json:"materialized_view,omitempty"`
+	// If set, the subscription carries on after the event the token was received with, instead of starting with a
+	// snapshot of the materialized view. The changes are only kept in memory, by the nodes which own the shards, so this
+	// fails with a ResumeTokenExpired error if any of them has been restarted or lost its shards to another node since,
+	// if the materialized view has had no subscriptions for 5 minutes, or if more than 10000 changes have been made to a
+	// shard since. The client must then subscribe again without a resume token.
+	ResumeToken []byte `protobuf:"bytes,3,opt,name=resume_token,json=resumeToken,proto3" json:"resume_token,omitempty"`
+}
+
+func (x *SubscribeRequest) Reset() {
+	*x = SubscribeRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_squareup_cash_pranadb_service_v1_service_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SubscribeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubscribeRequest) ProtoMessage() {}
+
+func (x *SubscribeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_squareup_cash_pranadb_service_v1_service_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
 		}
-		file_squareup_cash_pranadb_service_v1_service_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*ColValue); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubscribeRequest.ProtoReflect.Descriptor instead.
+func (*SubscribeRequest) Descriptor() ([]byte, []int) {
+	return file_squareup_cash_pranadb_service_v1_service_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *SubscribeRequest) GetSchema() string {
+	if x != nil {
+		return x.Schema
+	}
+	return ""
+}
+
+func (x *SubscribeRequest) GetMaterializedView() string {
+	if x != nil {
+		return x.MaterializedView
+	}
+	return ""
+}
+
+func (x *SubscribeRequest) GetResumeToken() []byte {
+	if x != nil {
+		return x.ResumeToken
+	}
+	return nil
+}
+
+type RowChange struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Type        ChangeType `protobuf:"varint,1,opt,name=type,proto3,enum=squareup.cash.pranadb.service.v1.ChangeType" json:"type,omitempty"`
+	Row         *Row       `protobuf:"bytes,2,opt,name=row,proto3" json:"row,omitempty"`                                    // The new row. Not present for a delete.
+	PreviousRow *Row       `protobuf:"bytes,3,opt,name=previous_row,json=previousRow,proto3" json:"previous_row,omitempty"` // The row before the change. Only present for an update or delete.
+}
+
+func (x *RowChange) Reset() {
+	*x = RowChange{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_squareup_cash_pranadb_service_v1_service_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RowChange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RowChange) ProtoMessage() {}
+
+func (x *RowChange) ProtoReflect() protoreflect.Message {
+	mi := &file_squareup_cash_pranadb_service_v1_service_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RowChange.ProtoReflect.Descriptor instead.
+func (*RowChange) Descriptor() ([]byte, []int) {
+	return file_squareup_cash_pranadb_service_v1_service_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *RowChange) GetType() ChangeType {
+	if x != nil {
+		return x.Type
+	}
+	return ChangeType_CHANGE_TYPE_UNSPECIFIED
+}
+
+func (x *RowChange) GetRow() *Row {
+	if x != nil {
+		return x.Row
+	}
+	return nil
+}
+
+func (x *RowChange) GetPreviousRow() *Row {
+	if x != nil {
+		return x.PreviousRow
+	}
+	return nil
+}
+
+type ChangeEvent struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Types that are assignable to Event:
+	//	*ChangeEvent_Columns
+	//	*ChangeEvent_Change
+	//	*ChangeEvent_SnapshotComplete
+	Event isChangeEvent_Event `protobuf_oneof:"event"`
+	// Can be passed in a SubscribeRequest to resume the subscription after this event. Present once the snapshot is
+	// complete.
+	ResumeToken []byte `protobuf:"bytes,4,opt,name=resume_token,json=resumeToken,proto3" json:"resume_token,omitempty"`
+}
+
+func (x *ChangeEvent) Reset() {
+	*x = ChangeEvent{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_squareup_cash_pranadb_service_v1_service_proto_msgTypes[13]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ChangeEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChangeEvent) ProtoMessage() {}
+
+func (x *ChangeEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_squareup_cash_pranadb_service_v1_service_proto_msgTypes[13]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChangeEvent.ProtoReflect.Descriptor instead.
+func (*ChangeEvent) Descriptor() ([]byte, []int) {
+	return file_squareup_cash_pranadb_service_v1_service_proto_rawDescGZIP(), []int{13}
+}
+
+func (m *ChangeEvent) GetEvent() isChangeEvent_Event {
+	if m != nil {
+		return m.Event
+	}
+	return nil
+}
+
+func (x *ChangeEvent) GetColumns() *Columns {
+	if x, ok := x.GetEvent().(*ChangeEvent_Columns); ok {
+		return x.Columns
+	}
+	return nil
+}
+
+func (x *ChangeEvent) GetChange() *RowChange {
+	if x, ok := x.GetEvent().(*ChangeEvent_Change); ok {
+		return x.Change
+	}
+	return nil
+}
+
+func (x *ChangeEvent) GetSnapshotComplete() bool {
+	if x, ok := x.GetEvent().(*ChangeEvent_SnapshotComplete); ok {
+		return x.SnapshotComplete
+	}
+	return false
+}
+
+func (x *ChangeEvent) GetResumeToken() []byte {
+	if x != nil {
+		return x.ResumeToken
+	}
+	return nil
+}
+
+type isChangeEvent_Event interface {
+	isChangeEvent_Event()
+}
+
+type ChangeEvent_Columns struct {
+	Columns *Columns `protobuf:"bytes,1,opt,name=columns,proto3,oneof"` // Present in first event.
+}
+
+type ChangeEvent_Change struct {
+	Change *RowChange `protobuf:"bytes,2,opt,name=change,proto3,oneof"`
+}
+
+type ChangeEvent_SnapshotComplete struct {
+	SnapshotComplete bool `protobuf:"varint,3,opt,name=snapshot_complete,json=snapshotComplete,proto3,oneof"` // Sent after the last row of the snapshot. Not sent when resuming.
+}
+
+func (*ChangeEvent_Columns) isChangeEvent_Event() {}
+
+func (*ChangeEvent_Change) isChangeEvent_Event() {}
+
+func (*ChangeEvent_SnapshotComplete) isChangeEvent_Event() {}
+
+// Prepare a query with param markers (?), which can then be executed many times with different args.
+type PrepareStatementRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Schema    string `protobuf:"bytes,1,opt,name=schema,proto3" json:"schema,omitempty"`
+	Statement string `protobuf:"bytes,2,opt,name=statement,proto3" json:"statement,omitempty"`
+}
+
+func (x *PrepareStatementRequest) Reset() {
+	*x = PrepareStatementRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_squareup_cash_pranadb_service_v1_service_proto_msgTypes[14]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PrepareStatementRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PrepareStatementRequest) ProtoMessage() {}
+
+func (x *PrepareStatementRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_squareup_cash_pranadb_service_v1_service_proto_msgTypes[14]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PrepareStatementRequest.ProtoReflect.Descriptor instead.
+func (*PrepareStatementRequest) Descriptor() ([]byte, []int) {
+	return file_squareup_cash_pranadb_service_v1_service_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *PrepareStatementRequest) GetSchema() string {
+	if x != nil {
+		return x.Schema
+	}
+	return ""
+}
+
+func (x *PrepareStatementRequest) GetStatement() string {
+	if x != nil {
+		return x.Statement
+	}
+	return ""
+}
+
+type PrepareStatementResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Identifies the prepared statement when executing it. Only valid on the connection it was prepared on, so if the
+	// client reconnects the statement must be prepared again.
+	PreparedStatementId int64 `protobuf:"varint,1,opt,name=prepared_statement_id,json=preparedStatementId,proto3" json:"prepared_statement_id,omitempty"`
+	// The number of args the statement must be executed with.
+	NumParams uint32 `protobuf:"varint,2,opt,name=num_params,json=numParams,proto3" json:"num_params,omitempty"`
+}
+
+func (x *PrepareStatementResponse) Reset() {
+	*x = PrepareStatementResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_squareup_cash_pranadb_service_v1_service_proto_msgTypes[15]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PrepareStatementResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PrepareStatementResponse) ProtoMessage() {}
+
+func (x *PrepareStatementResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_squareup_cash_pranadb_service_v1_service_proto_msgTypes[15]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PrepareStatementResponse.ProtoReflect.Descriptor instead.
+func (*PrepareStatementResponse) Descriptor() ([]byte, []int) {
+	return file_squareup_cash_pranadb_service_v1_service_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *PrepareStatementResponse) GetPreparedStatementId() int64 {
+	if x != nil {
+		return x.PreparedStatementId
+	}
+	return 0
+}
+
+func (x *PrepareStatementResponse) GetNumParams() uint32 {
+	if x != nil {
+		return x.NumParams
+	}
+	return 0
+}
+
+// The value of a param marker.
+type Arg struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Types that are assignable to Value:
+	//	*Arg_IsNull
+	//	*Arg_IntValue
+	//	*Arg_FloatValue
+	//	*Arg_StringValue
+	//	*Arg_DecimalValue
+	//	*Arg_TimestampValue
+	Value isArg_Value `protobuf_oneof:"value"`
+}
+
+func (x *Arg) Reset() {
+	*x = Arg{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_squareup_cash_pranadb_service_v1_service_proto_msgTypes[16]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Arg) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Arg) ProtoMessage() {}
+
+func (x *Arg) ProtoReflect() protoreflect.Message {
+	mi := &file_squareup_cash_pranadb_service_v1_service_proto_msgTypes[16]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Arg.ProtoReflect.Descriptor instead.
+func (*Arg) Descriptor() ([]byte, []int) {
+	return file_squareup_cash_pranadb_service_v1_service_proto_rawDescGZIP(), []int{16}
+}
+
+func (m *Arg) GetValue() isArg_Value {
+	if m != nil {
+		return m.Value
+	}
+	return nil
+}
+
+func (x *Arg) GetIsNull() bool {
+	if x, ok := x.GetValue().(*Arg_IsNull); ok {
+		return x.IsNull
+	}
+	return false
+}
+
+func (x *Arg) GetIntValue() int64 {
+	if x, ok := x.GetValue().(*Arg_IntValue); ok {
+		return x.IntValue
+	}
+	return 0
+}
+
+func (x *Arg) GetFloatValue() float64 {
+	if x, ok := x.GetValue().(*Arg_FloatValue); ok {
+		return x.FloatValue
+	}
+	return 0
+}
+
+func (x *Arg) GetStringValue() string {
+	if x, ok := x.GetValue().(*Arg_StringValue); ok {
+		return x.StringValue
+	}
+	return ""
+}
+
+func (x *Arg) GetDecimalValue() string {
+	if x, ok := x.GetValue().(*Arg_DecimalValue); ok {
+		return x.DecimalValue
+	}
+	return ""
+}
+
+func (x *Arg) GetTimestampValue() int64 {
+	if x, ok := x.GetValue().(*Arg_TimestampValue); ok {
+		return x.TimestampValue
+	}
+	return 0
+}
+
+type isArg_Value interface {
+	isArg_Value()
+}
+
+type Arg_IsNull struct {
+	IsNull bool `protobuf:"varint,1,opt,name=is_null,json=isNull,proto3,oneof"`
+}
+
+type Arg_IntValue struct {
+	IntValue int64 `protobuf:"varint,2,opt,name=int_value,json=intValue,proto3,oneof"`
+}
+
+type Arg_FloatValue struct {
+	FloatValue float64 `protobuf:"fixed64,3,opt,name=float_value,json=floatValue,proto3,oneof"`
+}
+
+type Arg_StringValue struct {
+	StringValue string `protobuf:"bytes,4,opt,name=string_value,json=stringValue,proto3,oneof"`
+}
+
+type Arg_DecimalValue struct {
+	DecimalValue string `protobuf:"bytes,5,opt,name=decimal_value,json=decimalValue,proto3,oneof"` // The decimal encoded as a string.
+}
+
+type Arg_TimestampValue struct {
+	TimestampValue int64 `protobuf:"varint,6,opt,name=timestamp_value,json=timestampValue,proto3,oneof"` // Microseconds past epoch.
+}
+
+func (*Arg_IsNull) isArg_Value() {}
+
+func (*Arg_IntValue) isArg_Value() {}
+
+func (*Arg_FloatValue) isArg_Value() {}
+
+func (*Arg_StringValue) isArg_Value() {}
+
+func (*Arg_DecimalValue) isArg_Value() {}
+
+func (*Arg_TimestampValue) isArg_Value() {}
+
+type ExecutePreparedStatementRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	PreparedStatementId int64 `protobuf:"varint,1,opt,name=prepared_statement_id,json=preparedStatementId,proto3" json:"prepared_statement_id,omitempty"`
+	// One for each param marker, in the order they appear in the statement.
+	Args []*Arg `protobuf:"bytes,2,rep,name=args,proto3" json:"args,omitempty"`
+	// Size of each page of results returned when paginating.
+	PageSize int32 `protobuf:"varint,3,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+}
+
+func (x *ExecutePreparedStatementRequest) Reset() {
+	*x = ExecutePreparedStatementRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_squareup_cash_pranadb_service_v1_service_proto_msgTypes[17]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ExecutePreparedStatementRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExecutePreparedStatementRequest) ProtoMessage() {}
+
+func (x *ExecutePreparedStatementRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_squareup_cash_pranadb_service_v1_service_proto_msgTypes[17]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExecutePreparedStatementRequest.ProtoReflect.Descriptor instead.
+func (*ExecutePreparedStatementRequest) Descriptor() ([]byte, []int) {
+	return file_squareup_cash_pranadb_service_v1_service_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *ExecutePreparedStatementRequest) GetPreparedStatementId() int64 {
+	if x != nil {
+		return x.PreparedStatementId
+	}
+	return 0
+}
+
+func (x *ExecutePreparedStatementRequest) GetArgs() []*Arg {
+	if x != nil {
+		return x.Args
+	}
+	return nil
+}
+
+func (x *ExecutePreparedStatementRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+// Close a prepared statement which is no longer needed. A connection can only hold a limited number of prepared
+// statements.
+type ClosePreparedStatementRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	PreparedStatementId int64 `protobuf:"varint,1,opt,name=prepared_statement_id,json=preparedStatementId,proto3" json:"prepared_statement_id,omitempty"`
+}
+
+func (x *ClosePreparedStatementRequest) Reset() {
+	*x = ClosePreparedStatementRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_squareup_cash_pranadb_service_v1_service_proto_msgTypes[18]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ClosePreparedStatementRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClosePreparedStatementRequest) ProtoMessage() {}
+
+func (x *ClosePreparedStatementRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_squareup_cash_pranadb_service_v1_service_proto_msgTypes[18]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClosePreparedStatementRequest.ProtoReflect.Descriptor instead.
+func (*ClosePreparedStatementRequest) Descriptor() ([]byte, []int) {
+	return file_squareup_cash_pranadb_service_v1_service_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *ClosePreparedStatementRequest) GetPreparedStatementId() int64 {
+	if x != nil {
+		return x.PreparedStatementId
+	}
+	return 0
+}
+
+var File_squareup_cash_pranadb_service_v1_service_proto protoreflect.FileDescriptor
+
+var file_squareup_cash_pranadb_service_v1_service_proto_rawDesc = []byte{
+	0x0a, 0x2e, 0x73, 0x71, 0x75, 0x61, 0x72, 0x65, 0x75, 0x70, 0x2f, 0x63, 0x61, 0x73, 0x68, 0x2f,
+	0x70, 0x72, 0x61, 0x6e, 0x61, 0x64, 0x62, 0x2f, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2f,
+	0x76, 0x31, 0x2f, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x12, 0x20, 0x73, 0x71, 0x75, 0x61, 0x72, 0x65, 0x75, 0x70, 0x2e, 0x63, 0x61, 0x73, 0x68, 0x2e,
+	0x70, 0x72, 0x61, 0x6e, 0x61, 0x64, 0x62, 0x2e, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e,
+	0x76, 0x31, 0x1a, 0x1b, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x62, 0x75, 0x66, 0x2f, 0x65, 0x6d, 0x70, 0x74, 0x79, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a,
+	0x20, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66,
+	0x2f, 0x64, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x6f, 0x72, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x22, 0x61, 0x0a, 0x0d, 0x44, 0x65, 0x63, 0x69, 0x6d, 0x61, 0x6c, 0x50, 0x61, 0x72, 0x61,
+	0x6d, 0x73, 0x12, 0x2b, 0x0a, 0x11, 0x64, 0x65, 0x63, 0x69, 0x6d, 0x61, 0x6c, 0x5f, 0x70, 0x72,
+	0x65, 0x63, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x10, 0x64,
+	0x65, 0x63, 0x69, 0x6d, 0x61, 0x6c, 0x50, 0x72, 0x65, 0x63, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x12,
+	0x23, 0x0a, 0x0d, 0x64, 0x65, 0x63, 0x69, 0x6d, 0x61, 0x6c, 0x5f, 0x73, 0x63, 0x61, 0x6c, 0x65,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0c, 0x64, 0x65, 0x63, 0x69, 0x6d, 0x61, 0x6c, 0x53,
+	0x63, 0x61, 0x6c, 0x65, 0x22, 0xce, 0x01, 0x0a, 0x06, 0x43, 0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x12,
+	0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e,
+	0x61, 0x6d, 0x65, 0x12, 0x40, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x0e, 0x32, 0x2c, 0x2e, 0x73, 0x71, 0x75, 0x61, 0x72, 0x65, 0x75, 0x70, 0x2e, 0x63, 0x61, 0x73,
+	0x68, 0x2e, 0x70, 0x72, 0x61, 0x6e, 0x61, 0x64, 0x62, 0x2e, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63,
+	0x65, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x54, 0x79, 0x70, 0x65, 0x52,
+	0x04, 0x74, 0x79, 0x70, 0x65, 0x12, 0x5b, 0x0a, 0x0e, 0x64, 0x65, 0x63, 0x69, 0x6d, 0x61, 0x6c,
+	0x5f, 0x70, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x2f, 0x2e,
+	0x73, 0x71, 0x75, 0x61, 0x72, 0x65, 0x75, 0x70, 0x2e, 0x63, 0x61, 0x73, 0x68, 0x2e, 0x70, 0x72,
+	0x61, 0x6e, 0x61, 0x64, 0x62, 0x2e, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x76, 0x31,
+	0x2e, 0x44, 0x65, 0x63, 0x69, 0x6d, 0x61, 0x6c, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x48, 0x00,
+	0x52, 0x0d, 0x64, 0x65, 0x63, 0x69, 0x6d, 0x61, 0x6c, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x88,
+	0x01, 0x01, 0x42, 0x11, 0x0a, 0x0f, 0x5f, 0x64, 0x65, 0x63, 0x69, 0x6d, 0x61, 0x6c, 0x5f, 0x70,
+	0x61, 0x72, 0x61, 0x6d, 0x73, 0x22, 0x6f, 0x0a, 0x1a, 0x45, 0x78, 0x65, 0x63, 0x75, 0x74, 0x65,
+	0x53, 0x51, 0x4c, 0x53, 0x74, 0x61, 0x74, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x12, 0x1c, 0x0a, 0x09, 0x73,
+	0x74, 0x61, 0x74, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09,
+	0x73, 0x74, 0x61, 0x74, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x1b, 0x0a, 0x09, 0x70, 0x61, 0x67,
+	0x65, 0x5f, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x70, 0x61,
+	0x67, 0x65, 0x53, 0x69, 0x7a, 0x65, 0x22, 0x4d, 0x0a, 0x07, 0x43, 0x6f, 0x6c, 0x75, 0x6d, 0x6e,
+	0x73, 0x12, 0x42, 0x0a, 0x07, 0x63, 0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x73, 0x18, 0x01, 0x20, 0x03,
+	0x28, 0x0b, 0x32, 0x28, 0x2e, 0x73, 0x71, 0x75, 0x61, 0x72, 0x65, 0x75, 0x70, 0x2e, 0x63, 0x61,
+	0x73, 0x68, 0x2e, 0x70, 0x72, 0x61, 0x6e, 0x61, 0x64, 0x62, 0x2e, 0x73, 0x65, 0x72, 0x76, 0x69,
+	0x63, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x52, 0x07, 0x63, 0x6f,
+	0x6c, 0x75, 0x6d, 0x6e, 0x73, 0x22, 0x49, 0x0a, 0x03, 0x52, 0x6f, 0x77, 0x12, 0x42, 0x0a, 0x06,
+	0x76, 0x61, 0x6c, 0x75, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x2a, 0x2e, 0x73,
+	0x71, 0x75, 0x61, 0x72, 0x65, 0x75, 0x70, 0x2e, 0x63, 0x61, 0x73, 0x68, 0x2e, 0x70, 0x72, 0x61,
+	0x6e, 0x61, 0x64, 0x62, 0x2e, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x76, 0x31, 0x2e,
+	0x43, 0x6f, 0x6c, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x52, 0x06, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x73,
+	0x22, 0xb8, 0x01, 0x0a, 0x08, 0x43, 0x6f, 0x6c, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x12, 0x19, 0x0a,
+	0x07, 0x69, 0x73, 0x5f, 0x6e, 0x75, 0x6c, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x48, 0x00,
+	0x52, 0x06, 0x69, 0x73, 0x4e, 0x75, 0x6c, 0x6c, 0x12, 0x1d, 0x0a, 0x09, 0x69, 0x6e, 0x74, 0x5f,
+	0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x48, 0x00, 0x52, 0x08, 0x69,
+	0x6e, 0x74, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x12, 0x21, 0x0a, 0x0b, 0x66, 0x6c, 0x6f, 0x61, 0x74,
+	0x5f, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x48, 0x00, 0x52, 0x0a,
+	0x66, 0x6c, 0x6f, 0x61, 0x74, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x12, 0x23, 0x0a, 0x0c, 0x73, 0x74,
+	0x72, 0x69, 0x6e, 0x67, 0x5f, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09,
+	0x48, 0x00, 0x52, 0x0b, 0x73, 0x74, 0x72, 0x69, 0x6e, 0x67, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x12,
+	0x21, 0x0a, 0x0b, 0x62, 0x79, 0x74, 0x65, 0x73, 0x5f, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x05,
+	0x20, 0x01, 0x28, 0x0c, 0x48, 0x00, 0x52, 0x0a, 0x62, 0x79, 0x74, 0x65, 0x73, 0x56, 0x61, 0x6c,
+	0x75, 0x65, 0x42, 0x07, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x22, 0x57, 0x0a, 0x04, 0x50,
+	0x61, 0x67, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x04, 0x52, 0x05, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x39, 0x0a, 0x04, 0x72, 0x6f, 0x77,
+	0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x25, 0x2e, 0x73, 0x71, 0x75, 0x61, 0x72, 0x65,
+	0x75, 0x70, 0x2e, 0x63, 0x61, 0x73, 0x68, 0x2e, 0x70, 0x72, 0x61, 0x6e, 0x61, 0x64, 0x62, 0x2e,
+	0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x6f, 0x77, 0x52, 0x04,
+	0x72, 0x6f, 0x77, 0x73, 0x22, 0xac, 0x01, 0x0a, 0x1b, 0x45, 0x78, 0x65, 0x63, 0x75, 0x74, 0x65,
+	0x53, 0x51, 0x4c, 0x53, 0x74, 0x61, 0x74, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x12, 0x45, 0x0a, 0x07, 0x63, 0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x73, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x29, 0x2e, 0x73, 0x71, 0x75, 0x61, 0x72, 0x65, 0x75, 0x70,
+	0x2e, 0x63, 0x61, 0x73, 0x68, 0x2e, 0x70, 0x72, 0x61, 0x6e, 0x61, 0x64, 0x62, 0x2e, 0x73, 0x65,
+	0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x73,
+	0x48, 0x00, 0x52, 0x07, 0x63, 0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x73, 0x12, 0x3c, 0x0a, 0x04, 0x70,
+	0x61, 0x67, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x26, 0x2e, 0x73, 0x71, 0x75, 0x61,
+	0x72, 0x65, 0x75, 0x70, 0x2e, 0x63, 0x61, 0x73, 0x68, 0x2e, 0x70, 0x72, 0x61, 0x6e, 0x61, 0x64,
+	0x62, 0x2e, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x50, 0x61, 0x67,
+	0x65, 0x48, 0x00, 0x52, 0x04, 0x70, 0x61, 0x67, 0x65, 0x42, 0x08, 0x0a, 0x06, 0x72, 0x65, 0x73,
+	0x75, 0x6c, 0x74, 0x22, 0x60, 0x0a, 0x18, 0x52, 0x65, 0x67, 0x69, 0x73, 0x74, 0x65, 0x72, 0x50,
+	0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
+	0x44, 0x0a, 0x0b, 0x64, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x6f, 0x72, 0x73, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x22, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x46, 0x69, 0x6c, 0x65, 0x44, 0x65, 0x73, 0x63, 0x72,
+	0x69, 0x70, 0x74, 0x6f, 0x72, 0x53, 0x65, 0x74, 0x52, 0x0b, 0x64, 0x65, 0x73, 0x63, 0x72, 0x69,
+	0x70, 0x74, 0x6f, 0x72, 0x73, 0x22, 0x34, 0x0a, 0x0a, 0x41, 0x76, 0x72, 0x6f, 0x53, 0x63, 0x68,
+	0x65, 0x6d, 0x61, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52,
+	0x02, 0x69, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x22, 0x64, 0x0a, 0x1a, 0x52,
+	0x65, 0x67, 0x69, 0x73, 0x74, 0x65, 0x72, 0x41, 0x76, 0x72, 0x6f, 0x53, 0x63, 0x68, 0x65, 0x6d,
+	0x61, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x46, 0x0a, 0x07, 0x73, 0x63, 0x68,
+	0x65, 0x6d, 0x61, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x2c, 0x2e, 0x73, 0x71, 0x75,
+	0x61, 0x72, 0x65, 0x75, 0x70, 0x2e, 0x63, 0x61, 0x73, 0x68, 0x2e, 0x70, 0x72, 0x61, 0x6e, 0x61,
+	0x64, 0x62, 0x2e, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x41, 0x76,
+	0x72, 0x6f, 0x53, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x52, 0x07, 0x73, 0x63, 0x68, 0x65, 0x6d, 0x61,
+	0x73, 0x22, 0x7a, 0x0a, 0x10, 0x53, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x62, 0x65, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x12, 0x2b, 0x0a,
+	0x11, 0x6d, 0x61, 0x74, 0x65, 0x72, 0x69, 0x61, 0x6c, 0x69, 0x7a, 0x65, 0x64, 0x5f, 0x76, 0x69,
+	0x65, 0x77, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x10, 0x6d, 0x61, 0x74, 0x65, 0x72, 0x69,
+	0x61, 0x6c, 0x69, 0x7a, 0x65, 0x64, 0x56, 0x69, 0x65, 0x77, 0x12, 0x21, 0x0a, 0x0c, 0x72, 0x65,
+	0x73, 0x75, 0x6d, 0x65, 0x5f, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c,
+	0x52, 0x0b, 0x72, 0x65, 0x73, 0x75, 0x6d, 0x65, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x22, 0xd0, 0x01,
+	0x0a, 0x09, 0x52, 0x6f, 0x77, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x12, 0x40, 0x0a, 0x04, 0x74,
+	0x79, 0x70, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x2c, 0x2e, 0x73, 0x71, 0x75, 0x61,
+	0x72, 0x65, 0x75, 0x70, 0x2e, 0x63, 0x61, 0x73, 0x68, 0x2e, 0x70, 0x72, 0x61, 0x6e, 0x61, 0x64,
+	0x62, 0x2e, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x68, 0x61,
+	0x6e, 0x67, 0x65, 0x54, 0x79, 0x70, 0x65, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x12, 0x37, 0x0a,
+	0x03, 0x72, 0x6f, 0x77, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x25, 0x2e, 0x73, 0x71, 0x75,
+	0x61, 0x72, 0x65, 0x75, 0x70, 0x2e, 0x63, 0x61, 0x73, 0x68, 0x2e, 0x70, 0x72, 0x61, 0x6e, 0x61,
+	0x64, 0x62, 0x2e, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x6f,
+	0x77, 0x52, 0x03, 0x72, 0x6f, 0x77, 0x12, 0x48, 0x0a, 0x0c, 0x70, 0x72, 0x65, 0x76, 0x69, 0x6f,
+	0x75, 0x73, 0x5f, 0x72, 0x6f, 0x77, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x25, 0x2e, 0x73,
+	0x71, 0x75, 0x61, 0x72, 0x65, 0x75, 0x70, 0x2e, 0x63, 0x61, 0x73, 0x68, 0x2e, 0x70, 0x72, 0x61,
+	0x6e, 0x61, 0x64, 0x62, 0x2e, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x76, 0x31, 0x2e,
+	0x52, 0x6f, 0x77, 0x52, 0x0b, 0x70, 0x72, 0x65, 0x76, 0x69, 0x6f, 0x75, 0x73, 0x52, 0x6f, 0x77,
+	0x22, 0xf6, 0x01, 0x0a, 0x0b, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x45, 0x76, 0x65, 0x6e, 0x74,
+	0x12, 0x45, 0x0a, 0x07, 0x63, 0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x29, 0x2e, 0x73, 0x71, 0x75, 0x61, 0x72, 0x65, 0x75, 0x70, 0x2e, 0x63, 0x61, 0x73,
+	0x68, 0x2e, 0x70, 0x72, 0x61, 0x6e, 0x61, 0x64, 0x62, 0x2e, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63,
+	0x65, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x73, 0x48, 0x00, 0x52, 0x07,
+	0x63, 0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x73, 0x12, 0x45, 0x0a, 0x06, 0x63, 0x68, 0x61, 0x6e, 0x67,
+	0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x2b, 0x2e, 0x73, 0x71, 0x75, 0x61, 0x72, 0x65,
+	0x75, 0x70, 0x2e, 0x63, 0x61, 0x73, 0x68, 0x2e, 0x70, 0x72, 0x61, 0x6e, 0x61, 0x64, 0x62, 0x2e,
+	0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x6f, 0x77, 0x43, 0x68,
+	0x61, 0x6e, 0x67, 0x65, 0x48, 0x00, 0x52, 0x06, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x12, 0x2d,
+	0x0a, 0x11, 0x73, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x5f, 0x63, 0x6f, 0x6d, 0x70, 0x6c,
+	0x65, 0x74, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x48, 0x00, 0x52, 0x10, 0x73, 0x6e, 0x61,
+	0x70, 0x73, 0x68, 0x6f, 0x74, 0x43, 0x6f, 0x6d, 0x70, 0x6c, 0x65, 0x74, 0x65, 0x12, 0x21, 0x0a,
+	0x0c, 0x72, 0x65, 0x73, 0x75, 0x6d, 0x65, 0x5f, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x0c, 0x52, 0x0b, 0x72, 0x65, 0x73, 0x75, 0x6d, 0x65, 0x54, 0x6f, 0x6b, 0x65, 0x6e,
+	0x42, 0x07, 0x0a, 0x05, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x22, 0x4f, 0x0a, 0x17, 0x50, 0x72, 0x65,
+	0x70, 0x61, 0x72, 0x65, 0x53, 0x74, 0x61, 0x74, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x12, 0x1c, 0x0a, 0x09,
+	0x73, 0x74, 0x61, 0x74, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x09, 0x73, 0x74, 0x61, 0x74, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x22, 0x6d, 0x0a, 0x18, 0x50, 0x72,
+	0x65, 0x70, 0x61, 0x72, 0x65, 0x53, 0x74, 0x61, 0x74, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x32, 0x0a, 0x15, 0x70, 0x72, 0x65, 0x70, 0x61, 0x72,
+	0x65, 0x64, 0x5f, 0x73, 0x74, 0x61, 0x74, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x5f, 0x69, 0x64, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x13, 0x70, 0x72, 0x65, 0x70, 0x61, 0x72, 0x65, 0x64, 0x53,
+	0x74, 0x61, 0x74, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x49, 0x64, 0x12, 0x1d, 0x0a, 0x0a, 0x6e, 0x75,
+	0x6d, 0x5f, 0x70, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x09,
+	0x6e, 0x75, 0x6d, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x22, 0xe2, 0x01, 0x0a, 0x03, 0x41, 0x72,
+	0x67, 0x12, 0x19, 0x0a, 0x07, 0x69, 0x73, 0x5f, 0x6e, 0x75, 0x6c, 0x6c, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x08, 0x48, 0x00, 0x52, 0x06, 0x69, 0x73, 0x4e, 0x75, 0x6c, 0x6c, 0x12, 0x1d, 0x0a, 0x09,
+	0x69, 0x6e, 0x74, 0x5f, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x48,
+	0x00, 0x52, 0x08, 0x69, 0x6e, 0x74, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x12, 0x21, 0x0a, 0x0b, 0x66,
+	0x6c, 0x6f, 0x61, 0x74, 0x5f, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01,
+	0x48, 0x00, 0x52, 0x0a, 0x66, 0x6c, 0x6f, 0x61, 0x74, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x12, 0x23,
+	0x0a, 0x0c, 0x73, 0x74, 0x72, 0x69, 0x6e, 0x67, 0x5f, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x0b, 0x73, 0x74, 0x72, 0x69, 0x6e, 0x67, 0x56, 0x61,
+	0x6c, 0x75, 0x65, 0x12, 0x25, 0x0a, 0x0d, 0x64, 0x65, 0x63, 0x69, 0x6d, 0x61, 0x6c, 0x5f, 0x76,
+	0x61, 0x6c, 0x75, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x0c, 0x64, 0x65,
+	0x63, 0x69, 0x6d, 0x61, 0x6c, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x12, 0x29, 0x0a, 0x0f, 0x74, 0x69,
+	0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x5f, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x06, 0x20,
+	0x01, 0x28, 0x03, 0x48, 0x00, 0x52, 0x0e, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70,
+	0x56, 0x61, 0x6c, 0x75, 0x65, 0x42, 0x07, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x22, 0xad,
+	0x01, 0x0a, 0x1f, 0x45, 0x78, 0x65, 0x63, 0x75, 0x74, 0x65, 0x50, 0x72, 0x65, 0x70, 0x61, 0x72,
+	0x65, 0x64, 0x53, 0x74, 0x61, 0x74, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x12, 0x32, 0x0a, 0x15, 0x70, 0x72, 0x65, 0x70, 0x61, 0x72, 0x65, 0x64, 0x5f, 0x73,
+	0x74, 0x61, 0x74, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x13, 0x70, 0x72, 0x65, 0x70, 0x61, 0x72, 0x65, 0x64, 0x53, 0x74, 0x61, 0x74, 0x65,
+	0x6d, 0x65, 0x6e, 0x74, 0x49, 0x64, 0x12, 0x39, 0x0a, 0x04, 0x61, 0x72, 0x67, 0x73, 0x18, 0x02,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x25, 0x2e, 0x73, 0x71, 0x75, 0x61, 0x72, 0x65, 0x75, 0x70, 0x2e,
+	0x63, 0x61, 0x73, 0x68, 0x2e, 0x70, 0x72, 0x61, 0x6e, 0x61, 0x64, 0x62, 0x2e, 0x73, 0x65, 0x72,
+	0x76, 0x69, 0x63, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x41, 0x72, 0x67, 0x52, 0x04, 0x61, 0x72, 0x67,
+	0x73, 0x12, 0x1b, 0x0a, 0x09, 0x70, 0x61, 0x67, 0x65, 0x5f, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x70, 0x61, 0x67, 0x65, 0x53, 0x69, 0x7a, 0x65, 0x22, 0x53,
+	0x0a, 0x1d, 0x43, 0x6c, 0x6f, 0x73, 0x65, 0x50, 0x72, 0x65, 0x70, 0x61, 0x72, 0x65, 0x64, 0x53,
+	0x74, 0x61, 0x74, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
+	0x32, 0x0a, 0x15, 0x70, 0x72, 0x65, 0x70, 0x61, 0x72, 0x65, 0x64, 0x5f, 0x73, 0x74, 0x61, 0x74,
+	0x65, 0x6d, 0x65, 0x6e, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x13,
+	0x70, 0x72, 0x65, 0x70, 0x61, 0x72, 0x65, 0x64, 0x53, 0x74, 0x61, 0x74, 0x65, 0x6d, 0x65, 0x6e,
+	0x74, 0x49, 0x64, 0x2a, 0xb6, 0x02, 0x0a, 0x0a, 0x43, 0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x54, 0x79,
+	0x70, 0x65, 0x12, 0x1b, 0x0a, 0x17, 0x43, 0x4f, 0x4c, 0x55, 0x4d, 0x4e, 0x5f, 0x54, 0x59, 0x50,
+	0x45, 0x5f, 0x55, 0x4e, 0x53, 0x50, 0x45, 0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10, 0x00, 0x12,
+	0x18, 0x0a, 0x14, 0x43, 0x4f, 0x4c, 0x55, 0x4d, 0x4e, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x54,
+	0x49, 0x4e, 0x59, 0x5f, 0x49, 0x4e, 0x54, 0x10, 0x01, 0x12, 0x13, 0x0a, 0x0f, 0x43, 0x4f, 0x4c,
+	0x55, 0x4d, 0x4e, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x49, 0x4e, 0x54, 0x10, 0x02, 0x12, 0x17,
+	0x0a, 0x13, 0x43, 0x4f, 0x4c, 0x55, 0x4d, 0x4e, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x42, 0x49,
+	0x47, 0x5f, 0x49, 0x4e, 0x54, 0x10, 0x03, 0x12, 0x16, 0x0a, 0x12, 0x43, 0x4f, 0x4c, 0x55, 0x4d,
+	0x4e, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x44, 0x4f, 0x55, 0x42, 0x4c, 0x45, 0x10, 0x04, 0x12,
+	0x17, 0x0a, 0x13, 0x43, 0x4f, 0x4c, 0x55, 0x4d, 0x4e, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x44,
+	0x45, 0x43, 0x49, 0x4d, 0x41, 0x4c, 0x10, 0x05, 0x12, 0x17, 0x0a, 0x13, 0x43, 0x4f, 0x4c, 0x55,
+	0x4d, 0x4e, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x56, 0x41, 0x52, 0x43, 0x48, 0x41, 0x52, 0x10,
+	0x06, 0x12, 0x19, 0x0a, 0x15, 0x43, 0x4f, 0x4c, 0x55, 0x4d, 0x4e, 0x5f, 0x54, 0x59, 0x50, 0x45,
+	0x5f, 0x54, 0x49, 0x4d, 0x45, 0x53, 0x54, 0x41, 0x4d, 0x50, 0x10, 0x07, 0x12, 0x17, 0x0a, 0x13,
+	0x43, 0x4f, 0x4c, 0x55, 0x4d, 0x4e, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x42, 0x4f, 0x4f, 0x4c,
+	0x45, 0x41, 0x4e, 0x10, 0x08, 0x12, 0x14, 0x0a, 0x10, 0x43, 0x4f, 0x4c, 0x55, 0x4d, 0x4e, 0x5f,
+	0x54, 0x59, 0x50, 0x45, 0x5f, 0x44, 0x41, 0x54, 0x45, 0x10, 0x09, 0x12, 0x19, 0x0a, 0x15, 0x43,
+	0x4f, 0x4c, 0x55, 0x4d, 0x4e, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x56, 0x41, 0x52, 0x42, 0x49,
+	0x4e, 0x41, 0x52, 0x59, 0x10, 0x0a, 0x12, 0x14, 0x0a, 0x10, 0x43, 0x4f, 0x4c, 0x55, 0x4d, 0x4e,
+	0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x4a, 0x53, 0x4f, 0x4e, 0x10, 0x0b, 0x2a, 0x8b, 0x01, 0x0a,
+	0x0a, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x54, 0x79, 0x70, 0x65, 0x12, 0x1b, 0x0a, 0x17, 0x43,
+	0x48, 0x41, 0x4e, 0x47, 0x45, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x55, 0x4e, 0x53, 0x50, 0x45,
+	0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10, 0x00, 0x12, 0x18, 0x0a, 0x14, 0x43, 0x48, 0x41, 0x4e,
+	0x47, 0x45, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x53, 0x4e, 0x41, 0x50, 0x53, 0x48, 0x4f, 0x54,
+	0x10, 0x01, 0x12, 0x16, 0x0a, 0x12, 0x43, 0x48, 0x41, 0x4e, 0x47, 0x45, 0x5f, 0x54, 0x59, 0x50,
+	0x45, 0x5f, 0x49, 0x4e, 0x53, 0x45, 0x52, 0x54, 0x10, 0x02, 0x12, 0x16, 0x0a, 0x12, 0x43, 0x48,
+	0x41, 0x4e, 0x47, 0x45, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x55, 0x50, 0x44, 0x41, 0x54, 0x45,
+	0x10, 0x03, 0x12, 0x16, 0x0a, 0x12, 0x43, 0x48, 0x41, 0x4e, 0x47, 0x45, 0x5f, 0x54, 0x59, 0x50,
+	0x45, 0x5f, 0x44, 0x45, 0x4c, 0x45, 0x54, 0x45, 0x10, 0x04, 0x32, 0x8f, 0x07, 0x0a, 0x0e, 0x50,
+	0x72, 0x61, 0x6e, 0x61, 0x44, 0x42, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x94, 0x01,
+	0x0a, 0x13, 0x45, 0x78, 0x65, 0x63, 0x75, 0x74, 0x65, 0x53, 0x51, 0x4c, 0x53, 0x74, 0x61, 0x74,
+	0x65, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x3c, 0x2e, 0x73, 0x71, 0x75, 0x61, 0x72, 0x65, 0x75, 0x70,
+	0x2e, 0x63, 0x61, 0x73, 0x68, 0x2e, 0x70, 0x72, 0x61, 0x6e, 0x61, 0x64, 0x62, 0x2e, 0x73, 0x65,
+	0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x45, 0x78, 0x65, 0x63, 0x75, 0x74, 0x65,
+	0x53, 0x51, 0x4c, 0x53, 0x74, 0x61, 0x74, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x1a, 0x3d, 0x2e, 0x73, 0x71, 0x75, 0x61, 0x72, 0x65, 0x75, 0x70, 0x2e, 0x63,
+	0x61, 0x73, 0x68, 0x2e, 0x70, 0x72, 0x61, 0x6e, 0x61, 0x64, 0x62, 0x2e, 0x73, 0x65, 0x72, 0x76,
+	0x69, 0x63, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x45, 0x78, 0x65, 0x63, 0x75, 0x74, 0x65, 0x53, 0x51,
+	0x4c, 0x53, 0x74, 0x61, 0x74, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x30, 0x01, 0x12, 0x67, 0x0a, 0x11, 0x52, 0x65, 0x67, 0x69, 0x73, 0x74, 0x65, 0x72,
+	0x50, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x73, 0x12, 0x3a, 0x2e, 0x73, 0x71, 0x75, 0x61,
+	0x72, 0x65, 0x75, 0x70, 0x2e, 0x63, 0x61, 0x73, 0x68, 0x2e, 0x70, 0x72, 0x61, 0x6e, 0x61, 0x64,
+	0x62, 0x2e, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65, 0x67,
+	0x69, 0x73, 0x74, 0x65, 0x72, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x73, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x12, 0x6b, 0x0a,
+	0x13, 0x52, 0x65, 0x67, 0x69, 0x73, 0x74, 0x65, 0x72, 0x41, 0x76, 0x72, 0x6f, 0x53, 0x63, 0x68,
+	0x65, 0x6d, 0x61, 0x73, 0x12, 0x3c, 0x2e, 0x73, 0x71, 0x75, 0x61, 0x72, 0x65, 0x75, 0x70, 0x2e,
+	0x63, 0x61, 0x73, 0x68, 0x2e, 0x70, 0x72, 0x61, 0x6e, 0x61, 0x64, 0x62, 0x2e, 0x73, 0x65, 0x72,
+	0x76, 0x69, 0x63, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65, 0x67, 0x69, 0x73, 0x74, 0x65, 0x72,
+	0x41, 0x76, 0x72, 0x6f, 0x53, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x12, 0x70, 0x0a, 0x09, 0x53, 0x75,
+	0x62, 0x73, 0x63, 0x72, 0x69, 0x62, 0x65, 0x12, 0x32, 0x2e, 0x73, 0x71, 0x75, 0x61, 0x72, 0x65,
+	0x75, 0x70, 0x2e, 0x63, 0x61, 0x73, 0x68, 0x2e, 0x70, 0x72, 0x61, 0x6e, 0x61, 0x64, 0x62, 0x2e,
+	0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x75, 0x62, 0x73, 0x63,
+	0x72, 0x69, 0x62, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x2d, 0x2e, 0x73, 0x71,
+	0x75, 0x61, 0x72, 0x65, 0x75, 0x70, 0x2e, 0x63, 0x61, 0x73, 0x68, 0x2e, 0x70, 0x72, 0x61, 0x6e,
+	0x61, 0x64, 0x62, 0x2e, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x43,
+	0x68, 0x61, 0x6e, 0x67, 0x65, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x30, 0x01, 0x12, 0x89, 0x01, 0x0a,
+	0x10, 0x50, 0x72, 0x65, 0x70, 0x61, 0x72, 0x65, 0x53, 0x74, 0x61, 0x74, 0x65, 0x6d, 0x65, 0x6e,
+	0x74, 0x12, 0x39, 0x2e, 0x73, 0x71, 0x75, 0x61, 0x72, 0x65, 0x75, 0x70, 0x2e, 0x63, 0x61, 0x73,
+	0x68, 0x2e, 0x70, 0x72, 0x61, 0x6e, 0x61, 0x64, 0x62, 0x2e, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63,
+	0x65, 0x2e, 0x76, 0x31, 0x2e, 0x50, 0x72, 0x65, 0x70, 0x61, 0x72, 0x65, 0x53, 0x74, 0x61, 0x74,
+	0x65, 0x6d, 0x65, 0x6e, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x3a, 0x2e, 0x73,
+	0x71, 0x75, 0x61, 0x72, 0x65, 0x75, 0x70, 0x2e, 0x63, 0x61, 0x73, 0x68, 0x2e, 0x70, 0x72, 0x61,
+	0x6e, 0x61, 0x64, 0x62, 0x2e, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x76, 0x31, 0x2e,
+	0x50, 0x72, 0x65, 0x70, 0x61, 0x72, 0x65, 0x53, 0x74, 0x61, 0x74, 0x65, 0x6d, 0x65, 0x6e, 0x74,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x9e, 0x01, 0x0a, 0x18, 0x45, 0x78, 0x65,
+	0x63, 0x75, 0x74, 0x65, 0x50, 0x72, 0x65, 0x70, 0x61, 0x72, 0x65, 0x64, 0x53, 0x74, 0x61, 0x74,
+	0x65, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x41, 0x2e, 0x73, 0x71, 0x75, 0x61, 0x72, 0x65, 0x75, 0x70,
+	0x2e, 0x63, 0x61, 0x73, 0x68, 0x2e, 0x70, 0x72, 0x61, 0x6e, 0x61, 0x64, 0x62, 0x2e, 0x73, 0x65,
+	0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x45, 0x78, 0x65, 0x63, 0x75, 0x74, 0x65,
+	0x50, 0x72, 0x65, 0x70, 0x61, 0x72, 0x65, 0x64, 0x53, 0x74, 0x61, 0x74, 0x65, 0x6d, 0x65, 0x6e,
+	0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x3d, 0x2e, 0x73, 0x71, 0x75, 0x61, 0x72,
+	0x65, 0x75, 0x70, 0x2e, 0x63, 0x61, 0x73, 0x68, 0x2e, 0x70, 0x72, 0x61, 0x6e, 0x61, 0x64, 0x62,
+	0x2e, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x45, 0x78, 0x65, 0x63,
+	0x75, 0x74, 0x65, 0x53, 0x51, 0x4c, 0x53, 0x74, 0x61, 0x74, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x30, 0x01, 0x12, 0x71, 0x0a, 0x16, 0x43, 0x6c, 0x6f,
+	0x73, 0x65, 0x50, 0x72, 0x65, 0x70, 0x61, 0x72, 0x65, 0x64, 0x53, 0x74, 0x61, 0x74, 0x65, 0x6d,
+	0x65, 0x6e, 0x74, 0x12, 0x3f, 0x2e, 0x73, 0x71, 0x75, 0x61, 0x72, 0x65, 0x75, 0x70, 0x2e, 0x63,
+	0x61, 0x73, 0x68, 0x2e, 0x70, 0x72, 0x61, 0x6e, 0x61, 0x64, 0x62, 0x2e, 0x73, 0x65, 0x72, 0x76,
+	0x69, 0x63, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x6c, 0x6f, 0x73, 0x65, 0x50, 0x72, 0x65, 0x70,
+	0x61, 0x72, 0x65, 0x64, 0x53, 0x74, 0x61, 0x74, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x42, 0x45, 0x5a, 0x43,
+	0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x73, 0x71, 0x75, 0x61, 0x72,
+	0x65, 0x75, 0x70, 0x2f, 0x70, 0x72, 0x61, 0x6e, 0x61, 0x64, 0x62, 0x2f, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x73, 0x2f, 0x73, 0x71, 0x75, 0x61, 0x72, 0x65, 0x75, 0x70, 0x2f, 0x63, 0x61, 0x73, 0x68,
+	0x2f, 0x70, 0x72, 0x61, 0x6e, 0x61, 0x64, 0x62, 0x2f, 0x76, 0x31, 0x2f, 0x73, 0x65, 0x72, 0x76,
+	0x69, 0x63, 0x65, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_squareup_cash_pranadb_service_v1_service_proto_rawDescOnce sync.Once
+	file_squareup_cash_pranadb_service_v1_service_proto_rawDescData = file_squareup_cash_pranadb_service_v1_service_proto_rawDesc
+)
+
+func file_squareup_cash_pranadb_service_v1_service_proto_rawDescGZIP() []byte {
+	file_squareup_cash_pranadb_service_v1_service_proto_rawDescOnce.Do(func() {
+		file_squareup_cash_pranadb_service_v1_service_proto_rawDescData = protoimpl.X.CompressGZIP(file_squareup_cash_pranadb_service_v1_service_proto_rawDescData)
+	})
+	return file_squareup_cash_pranadb_service_v1_service_proto_rawDescData
+}
+
+var file_squareup_cash_pranadb_service_v1_service_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_squareup_cash_pranadb_service_v1_service_proto_msgTypes = make([]protoimpl.MessageInfo, 19)
+var file_squareup_cash_pranadb_service_v1_service_proto_goTypes = []interface{}{
+	(ColumnType)(0),                         // 0: squareup.cash.pranadb.service.v1.ColumnType
+	(ChangeType)(0),                         // 1: squareup.cash.pranadb.service.v1.ChangeType
+	(*DecimalParams)(nil),                   // 2: squareup.cash.pranadb.service.v1.DecimalParams
+	(*Column)(nil),                          // 3: squareup.cash.pranadb.service.v1.Column
+	(*ExecuteSQLStatementRequest)(nil),      // 4: squareup.cash.pranadb.service.v1.ExecuteSQLStatementRequest
+	(*Columns)(nil),                         // 5: squareup.cash.pranadb.service.v1.Columns
+	(*Row)(nil),                             // 6: squareup.cash.pranadb.service.v1.Row
+	(*ColValue)(nil),                        // 7: squareup.cash.pranadb.service.v1.ColValue
+	(*Page)(nil),                            // 8: squareup.cash.pranadb.service.v1.Page
+	(*ExecuteSQLStatementResponse)(nil),     // 9: squareup.cash.pranadb.service.v1.ExecuteSQLStatementResponse
+	(*RegisterProtobufsRequest)(nil),        // 10: squareup.cash.pranadb.service.v1.RegisterProtobufsRequest
+	(*AvroSchema)(nil),                      // 11: squareup.cash.pranadb.service.v1.AvroSchema
+	(*RegisterAvroSchemasRequest)(nil),      // 12: squareup.cash.pranadb.service.v1.RegisterAvroSchemasRequest
+	(*SubscribeRequest)(nil),                // 13: squareup.cash.pranadb.service.v1.SubscribeRequest
+	(*RowChange)(nil),                       // 14: squareup.cash.pranadb.service.v1.RowChange
+	(*ChangeEvent)(nil),                     // 15: squareup.cash.pranadb.service.v1.ChangeEvent
+	(*PrepareStatementRequest)(nil),         // 16: squareup.cash.pranadb.service.v1.PrepareStatementRequest
+	(*PrepareStatementResponse)(nil),        // 17: squareup.cash.pranadb.service.v1.PrepareStatementResponse
+	(*Arg)(nil),                             // 18: squareup.cash.pranadb.service.v1.Arg
+	(*ExecutePreparedStatementRequest)(nil), // 19: squareup.cash.pranadb.service.v1.ExecutePreparedStatementRequest
+	(*ClosePreparedStatementRequest)(nil),   // 20: squareup.cash.pranadb.service.v1.ClosePreparedStatementRequest
+	(*descriptorpb.FileDescriptorSet)(nil),  // 21: google.protobuf.FileDescriptorSet
+	(*emptypb.Empty)(nil),                   // 22: google.protobuf.Empty
+}
+var file_squareup_cash_pranadb_service_v1_service_proto_depIdxs = []int32{
+	0,  // 0: squareup.cash.pranadb.service.v1.Column.type:type_name -> squareup.cash.pranadb.service.v1.ColumnType
+	2,  // 1: squareup.cash.pranadb.service.v1.Column.decimal_params:type_name -> squareup.cash.pranadb.service.v1.DecimalParams
+	3,  // 2: squareup.cash.pranadb.service.v1.Columns.columns:type_name -> squareup.cash.pranadb.service.v1.Column
+	7,  // 3: squareup.cash.pranadb.service.v1.Row.values:type_name -> squareup.cash.pranadb.service.v1.ColValue
+	6,  // 4: squareup.cash.pranadb.service.v1.Page.rows:type_name -> squareup.cash.pranadb.service.v1.Row
+	5,  // 5: squareup.cash.pranadb.service.v1.ExecuteSQLStatementResponse.columns:type_name -> squareup.cash.pranadb.service.v1.Columns
+	8,  // 6: squareup.cash.pranadb.service.v1.ExecuteSQLStatementResponse.page:type_name -> squareup.cash.pranadb.service.v1.Page
+	21, // 7: squareup.cash.pranadb.service.v1.RegisterProtobufsRequest.descriptors:type_name -> google.protobuf.FileDescriptorSet
+	11, // 8: squareup.cash.pranadb.service.v1.RegisterAvroSchemasRequest.schemas:type_name -> squareup.cash.pranadb.service.v1.AvroSchema
+	1,  // 9: squareup.cash.pranadb.service.v1.RowChange.type:type_name -> squareup.cash.pranadb.service.v1.ChangeType
+	6,  // 10: squareup.cash.pranadb.service.v1.RowChange.row:type_name -> squareup.cash.pranadb.service.v1.Row
+	6,  // 11: squareup.cash.pranadb.service.v1.RowChange.previous_row:type_name -> squareup.cash.pranadb.service.v1.Row
+	5,  // 12: squareup.cash.pranadb.service.v1.ChangeEvent.columns:type_name -> squareup.cash.pranadb.service.v1.Columns
+	14, // 13: squareup.cash.pranadb.service.v1.ChangeEvent.change:type_name -> squareup.cash.pranadb.service.v1.RowChange
+	18, // 14: squareup.cash.pranadb.service.v1.ExecutePreparedStatementRequest.args:type_name -> squareup.cash.pranadb.service.v1.Arg
+	4,  // 15: squareup.cash.pranadb.service.v1.PranaDBService.ExecuteSQLStatement:input_type -> squareup.cash.pranadb.service.v1.ExecuteSQLStatementRequest
+	10, // 16: squareup.cash.pranadb.service.v1.PranaDBService.RegisterProtobufs:input_type -> squareup.cash.pranadb.service.v1.RegisterProtobufsRequest
+	12, // 17: squareup.cash.pranadb.service.v1.PranaDBService.RegisterAvroSchemas:input_type -> squareup.cash.pranadb.service.v1.RegisterAvroSchemasRequest
+	13, // 18: squareup.cash.pranadb.service.v1.PranaDBService.Subscribe:input_type -> squareup.cash.pranadb.service.v1.SubscribeRequest
+	16, // 19: squareup.cash.pranadb.service.v1.PranaDBService.PrepareStatement:input_type -> squareup.cash.pranadb.service.v1.PrepareStatementRequest
+	19, // 20: squareup.cash.pranadb.service.v1.PranaDBService.ExecutePreparedStatement:input_type -> squareup.cash.pranadb.service.v1.ExecutePreparedStatementRequest
+	20, // 21: squareup.cash.pranadb.service.v1.PranaDBService.ClosePreparedStatement:input_type -> squareup.cash.pranadb.service.v1.ClosePreparedStatementRequest
+	9,  // 22: squareup.cash.pranadb.service.v1.PranaDBService.ExecuteSQLStatement:output_type -> squareup.cash.pranadb.service.v1.ExecuteSQLStatementResponse
+	22, // 23: squareup.cash.pranadb.service.v1.PranaDBService.RegisterProtobufs:output_type -> google.protobuf.Empty
+	22, // 24: squareup.cash.pranadb.service.v1.PranaDBService.RegisterAvroSchemas:output_type -> google.protobuf.Empty
+	15, // 25: squareup.cash.pranadb.service.v1.PranaDBService.Subscribe:output_type -> squareup.cash.pranadb.service.v1.ChangeEvent
+	17, // 26: squareup.cash.pranadb.service.v1.PranaDBService.PrepareStatement:output_type -> squareup.cash.pranadb.service.v1.PrepareStatementResponse
+	9,  // 27: squareup.cash.pranadb.service.v1.PranaDBService.ExecutePreparedStatement:output_type -> squareup.cash.pranadb.service.v1.ExecuteSQLStatementResponse
+	22, // 28: squareup.cash.pranadb.service.v1.PranaDBService.ClosePreparedStatement:output_type -> google.protobuf.Empty
+	22, // [22:29] is the sub-list for method output_type
+	15, // [15:22] is the sub-list for method input_type
+	15, // [15:15] is the sub-list for extension type_name
+	15, // [15:15] is the sub-list for extension extendee
+	0,  // [0:15] is the sub-list for field type_name
+}
+
+func init() { file_squareup_cash_pranadb_service_v1_service_proto_init() }
+func file_squareup_cash_pranadb_service_v1_service_proto_init() {
+	if File_squareup_cash_pranadb_service_v1_service_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_squareup_cash_pranadb_service_v1_service_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DecimalParams); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_squareup_cash_pranadb_service_v1_service_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Column); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_squareup_cash_pranadb_service_v1_service_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ExecuteSQLStatementRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_squareup_cash_pranadb_service_v1_service_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Columns); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_squareup_cash_pranadb_service_v1_service_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Row); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_squareup_cash_pranadb_service_v1_service_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ColValue); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_squareup_cash_pranadb_service_v1_service_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Page); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_squareup_cash_pranadb_service_v1_service_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ExecuteSQLStatementResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
 			default:
 				return nil
 			}
 		}
-		file_squareup_cash_pranadb_service_v1_service_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Page); i {
+		file_squareup_cash_pranadb_service_v1_service_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RegisterProtobufsRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -915,8 +1898,8 @@ func file_squareup_cash_pranadb_service_v1_service_proto_init() {
 				return nil
 			}
 		}
-		file_squareup_cash_pranadb_service_v1_service_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*ExecuteSQLStatementResponse); i {
+		file_squareup_cash_pranadb_service_v1_service_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AvroSchema); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -927,8 +1910,104 @@ func file_squareup_cash_pranadb_service_v1_service_proto_init() {
 				return nil
 			}
 		}
-		file_squareup_cash_pranadb_service_v1_service_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*RegisterProtobufsRequest); i {
+		file_squareup_cash_pranadb_service_v1_service_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RegisterAvroSchemasRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_squareup_cash_pranadb_service_v1_service_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SubscribeRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_squareup_cash_pranadb_service_v1_service_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RowChange); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_squareup_cash_pranadb_service_v1_service_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ChangeEvent); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_squareup_cash_pranadb_service_v1_service_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PrepareStatementRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_squareup_cash_pranadb_service_v1_service_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PrepareStatementResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_squareup_cash_pranadb_service_v1_service_proto_msgTypes[16].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Arg); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_squareup_cash_pranadb_service_v1_service_proto_msgTypes[17].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ExecutePreparedStatementRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_squareup_cash_pranadb_service_v1_service_proto_msgTypes[18].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ClosePreparedStatementRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -946,18 +2025,32 @@ func file_squareup_cash_pranadb_service_v1_service_proto_init() {
 		(*ColValue_IntValue)(nil),
 		(*ColValue_FloatValue)(nil),
 		(*ColValue_StringValue)(nil),
+		(*ColValue_BytesValue)(nil),
 	}
 	file_squareup_cash_pranadb_service_v1_service_proto_msgTypes[7].OneofWrappers = []interface{}{
 		(*ExecuteSQLStatementResponse_Columns)(nil),
 		(*ExecuteSQLStatementResponse_Page)(nil),
 	}
+	file_squareup_cash_pranadb_service_v1_service_proto_msgTypes[13].OneofWrappers = []interface{}{
+		(*ChangeEvent_Columns)(nil),
+		(*ChangeEvent_Change)(nil),
+		(*ChangeEvent_SnapshotComplete)(nil),
+	}
+	file_squareup_cash_pranadb_service_v1_service_proto_msgTypes[16].OneofWrappers = []interface{}{
+		(*Arg_IsNull)(nil),
+		(*Arg_IntValue)(nil),
+		(*Arg_FloatValue)(nil),
+		(*Arg_StringValue)(nil),
+		(*Arg_DecimalValue)(nil),
+		(*Arg_TimestampValue)(nil),
+	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_squareup_cash_pranadb_service_v1_service_proto_rawDesc,
-			NumEnums:      1,
-			NumMessages:   9,
+			NumEnums:      2,
+			NumMessages:   19,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -986,6 +2079,11 @@ const _ = grpc.SupportPackageIsVersion6
 type PranaDBServiceClient interface {
 	ExecuteSQLStatement(ctx context.Context, in *ExecuteSQLStatementRequest, opts ...grpc.CallOption) (PranaDBService_ExecuteSQLStatementClient, error)
 	RegisterProtobufs(ctx context.Context, in *RegisterProtobufsRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	RegisterAvroSchemas(ctx context.Context, in *RegisterAvroSchemasRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	Subscribe(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (PranaDBService_SubscribeClient, error)
+	PrepareStatement(ctx context.Context, in *PrepareStatementRequest, opts ...grpc.CallOption) (*PrepareStatementResponse, error)
+	ExecutePreparedStatement(ctx context.Context, in *ExecutePreparedStatementRequest, opts ...grpc.CallOption) (PranaDBService_ExecutePreparedStatementClient, error)
+	ClosePreparedStatement(ctx context.Context, in *ClosePreparedStatementRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
 }
 
 type pranaDBServiceClient struct {
@@ -1037,10 +2135,106 @@ func (c *pranaDBServiceClient) RegisterProtobufs(ctx context.Context, in *Regist
 	return out, nil
 }
 
+func (c *pranaDBServiceClient) RegisterAvroSchemas(ctx context.Context, in *RegisterAvroSchemasRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/squareup.cash.pranadb.service.v1.PranaDBService/RegisterAvroSchemas", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pranaDBServiceClient) Subscribe(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (PranaDBService_SubscribeClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_PranaDBService_serviceDesc.Streams[1], "/squareup.cash.pranadb.service.v1.PranaDBService/Subscribe", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &pranaDBServiceSubscribeClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type PranaDBService_SubscribeClient interface {
+	Recv() (*ChangeEvent, error)
+	grpc.ClientStream
+}
+
+type pranaDBServiceSubscribeClient struct {
+	grpc.ClientStream
+}
+
+func (x *pranaDBServiceSubscribeClient) Recv() (*ChangeEvent, error) {
+	m := new(ChangeEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *pranaDBServiceClient) PrepareStatement(ctx context.Context, in *PrepareStatementRequest, opts ...grpc.CallOption) (*PrepareStatementResponse, error) {
+	out := new(PrepareStatementResponse)
+	err := c.cc.Invoke(ctx, "/squareup.cash.pranadb.service.v1.PranaDBService/PrepareStatement", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pranaDBServiceClient) ExecutePreparedStatement(ctx context.Context, in *ExecutePreparedStatementRequest, opts ...grpc.CallOption) (PranaDBService_ExecutePreparedStatementClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_PranaDBService_serviceDesc.Streams[2], "/squareup.cash.pranadb.service.v1.PranaDBService/ExecutePreparedStatement", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &pranaDBServiceExecutePreparedStatementClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type PranaDBService_ExecutePreparedStatementClient interface {
+	Recv() (*ExecuteSQLStatementResponse, error)
+	grpc.ClientStream
+}
+
+type pranaDBServiceExecutePreparedStatementClient struct {
+	grpc.ClientStream
+}
+
+func (x *pranaDBServiceExecutePreparedStatementClient) Recv() (*ExecuteSQLStatementResponse, error) {
+	m := new(ExecuteSQLStatementResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *pranaDBServiceClient) ClosePreparedStatement(ctx context.Context, in *ClosePreparedStatementRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/squareup.cash.pranadb.service.v1.PranaDBService/ClosePreparedStatement", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // PranaDBServiceServer is the server API for PranaDBService service.
 type PranaDBServiceServer interface {
 	ExecuteSQLStatement(*ExecuteSQLStatementRequest, PranaDBService_ExecuteSQLStatementServer) error
 	RegisterProtobufs(context.Context, *RegisterProtobufsRequest) (*emptypb.Empty, error)
+	RegisterAvroSchemas(context.Context, *RegisterAvroSchemasRequest) (*emptypb.Empty, error)
+	Subscribe(*SubscribeRequest, PranaDBService_SubscribeServer) error
+	PrepareStatement(context.Context, *PrepareStatementRequest) (*PrepareStatementResponse, error)
+	ExecutePreparedStatement(*ExecutePreparedStatementRequest, PranaDBService_ExecutePreparedStatementServer) error
+	ClosePreparedStatement(context.Context, *ClosePreparedStatementRequest) (*emptypb.Empty, error)
 }
 
 // UnimplementedPranaDBServiceServer can be embedded to have forward compatible implementations.
@@ -1053,6 +2247,21 @@ func (*UnimplementedPranaDBServiceServer) ExecuteSQLStatement(*ExecuteSQLStateme
 func (*UnimplementedPranaDBServiceServer) RegisterProtobufs(context.Context, *RegisterProtobufsRequest) (*emptypb.Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method RegisterProtobufs not implemented")
 }
+func (*UnimplementedPranaDBServiceServer) RegisterAvroSchemas(context.Context, *RegisterAvroSchemasRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RegisterAvroSchemas not implemented")
+}
+func (*UnimplementedPranaDBServiceServer) Subscribe(*SubscribeRequest, PranaDBService_SubscribeServer) error {
+	return status.Errorf(codes.Unimplemented, "method Subscribe not implemented")
+}
+func (*UnimplementedPranaDBServiceServer) PrepareStatement(context.Context, *PrepareStatementRequest) (*PrepareStatementResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PrepareStatement not implemented")
+}
+func (*UnimplementedPranaDBServiceServer) ExecutePreparedStatement(*ExecutePreparedStatementRequest, PranaDBService_ExecutePreparedStatementServer) error {
+	return status.Errorf(codes.Unimplemented, "method ExecutePreparedStatement not implemented")
+}
+func (*UnimplementedPranaDBServiceServer) ClosePreparedStatement(context.Context, *ClosePreparedStatementRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ClosePreparedStatement not implemented")
+}
 
 func RegisterPranaDBServiceServer(s *grpc.Server, srv PranaDBServiceServer) {
 	s.RegisterService(&_PranaDBService_serviceDesc, srv)
@@ -1097,6 +2306,102 @@ func _PranaDBService_RegisterProtobufs_Handler(srv interface{}, ctx context.Cont
 	return interceptor(ctx, in, info, handler)
 }
 
+func _PranaDBService_RegisterAvroSchemas_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RegisterAvroSchemasRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PranaDBServiceServer).RegisterAvroSchemas(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/squareup.cash.pranadb.service.v1.PranaDBService/RegisterAvroSchemas",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PranaDBServiceServer).RegisterAvroSchemas(ctx, req.(*RegisterAvroSchemasRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PranaDBService_Subscribe_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribeRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(PranaDBServiceServer).Subscribe(m, &pranaDBServiceSubscribeServer{stream})
+}
+
+type PranaDBService_SubscribeServer interface {
+	Send(*ChangeEvent) error
+	grpc.ServerStream
+}
+
+type pranaDBServiceSubscribeServer struct {
+	grpc.ServerStream
+}
+
+func (x *pranaDBServiceSubscribeServer) Send(m *ChangeEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _PranaDBService_PrepareStatement_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PrepareStatementRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PranaDBServiceServer).PrepareStatement(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/squareup.cash.pranadb.service.v1.PranaDBService/PrepareStatement",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PranaDBServiceServer).PrepareStatement(ctx, req.(*PrepareStatementRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PranaDBService_ExecutePreparedStatement_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ExecutePreparedStatementRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(PranaDBServiceServer).ExecutePreparedStatement(m, &pranaDBServiceExecutePreparedStatementServer{stream})
+}
+
+type PranaDBService_ExecutePreparedStatementServer interface {
+	Send(*ExecuteSQLStatementResponse) error
+	grpc.ServerStream
+}
+
+type pranaDBServiceExecutePreparedStatementServer struct {
+	grpc.ServerStream
+}
+
+func (x *pranaDBServiceExecutePreparedStatementServer) Send(m *ExecuteSQLStatementResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _PranaDBService_ClosePreparedStatement_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ClosePreparedStatementRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PranaDBServiceServer).ClosePreparedStatement(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/squareup.cash.pranadb.service.v1.PranaDBService/ClosePreparedStatement",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PranaDBServiceServer).ClosePreparedStatement(ctx, req.(*ClosePreparedStatementRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _PranaDBService_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "squareup.cash.pranadb.service.v1.PranaDBService",
 	HandlerType: (*PranaDBServiceServer)(nil),
@@ -1105,6 +2410,18 @@ var _PranaDBService_serviceDesc = grpc.ServiceDesc{
 			MethodName: "RegisterProtobufs",
 			Handler:    _PranaDBService_RegisterProtobufs_Handler,
 		},
+		{
+			MethodName: "RegisterAvroSchemas",
+			Handler:    _PranaDBService_RegisterAvroSchemas_Handler,
+		},
+		{
+			MethodName: "PrepareStatement",
+			Handler:    _PranaDBService_PrepareStatement_Handler,
+		},
+		{
+			MethodName: "ClosePreparedStatement",
+			Handler:    _PranaDBService_ClosePreparedStatement_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -1112,6 +2429,16 @@ var _PranaDBService_serviceDesc = grpc.ServiceDesc{
 			Handler:       _PranaDBService_ExecuteSQLStatement_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "Subscribe",
+			Handler:       _PranaDBService_Subscribe_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "ExecutePreparedStatement",
+			Handler:       _PranaDBService_ExecutePreparedStatement_Handler,
+			ServerStreams: true,
+		},
 	},
 	Metadata: "squareup/cash/pranadb/service/v1/service.proto",
 }
@@ -3,16 +3,21 @@ package pull
 import (
 	"fmt"
 	"github.com/squareup/pranadb/sharder"
+	"os"
+	"path/filepath"
 	"strings"
 	"sync"
 	"sync/atomic"
 
+	log "github.com/sirupsen/logrus"
 	"github.com/squareup/pranadb/cluster"
 	"github.com/squareup/pranadb/common"
+	"github.com/squareup/pranadb/conf"
 	"github.com/squareup/pranadb/errors"
 	"github.com/squareup/pranadb/execctx"
 	"github.com/squareup/pranadb/meta"
 	"github.com/squareup/pranadb/pull/exec"
+	"github.com/squareup/pranadb/tidb/planner"
 )
 
 type Engine struct {
@@ -24,14 +29,17 @@ type Engine struct {
 	nodeID            int
 	shrder            *sharder.Sharder
 	available         common.AtomicBool
+	cnf               *conf.Config
+	sortSpillConf     *exec.SortSpillConfig
 }
 
-func NewPullEngine(cluster cluster.Cluster, metaController *meta.Controller, shrder *sharder.Sharder) *Engine {
+func NewPullEngine(cluster cluster.Cluster, metaController *meta.Controller, shrder *sharder.Sharder, cnf *conf.Config) *Engine {
 	engine := Engine{
 		cluster:        cluster,
 		metaController: metaController,
 		nodeID:         cluster.GetNodeID(),
 		shrder:         shrder,
+		cnf:            cnf,
 	}
 	engine.queryExecCtxCache.Store(new(sync.Map))
 	return &engine
@@ -43,17 +51,44 @@ func (p *Engine) Start() error {
 	if p.started {
 		return nil
 	}
+	if p.cnf.DataDir != "" {
+		// Test servers don't have a data dir, so their sorts are done in memory
+		spillDir, err := p.createSortSpillDir()
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		p.sortSpillConf = &exec.SortSpillConfig{Dir: spillDir, MemoryBudget: int64(p.cnf.SortMemoryBudgetMB) * 1024 * 1024}
+	}
 	p.started = true
 	return nil
 }
 
+// createSortSpillDir creates the directory under which sorts spill rows to disk. Any files left in it by queries which
+// were running when the node last stopped are removed.
+func (p *Engine) createSortSpillDir() (string, error) {
+	dir := filepath.Join(p.cnf.DataDir, fmt.Sprintf("node-%d", p.nodeID), "sort")
+	if err := os.RemoveAll(dir); err != nil {
+		return "", errors.WithStack(err)
+	}
+	return dir, nil
+}
+
 func (p *Engine) Stop() error {
 	p.lock.Lock()
 	defer p.lock.Unlock()
 	if !p.started {
 		return nil
 	}
+	p.execCtxCache().Range(func(key, value interface{}) bool {
+		value.(*execctx.ExecutionContext).Close() //nolint: forcetypeassert
+		return true
+	})
 	p.queryExecCtxCache.Store(new(sync.Map)) // Clear the internal state
+	if p.sortSpillConf != nil {
+		if err := os.RemoveAll(p.sortSpillConf.Dir); err != nil {
+			log.Warnf("failed to remove sort spill directory %v", err)
+		}
+	}
 	p.available.Set(false)
 	p.started = false
 	return nil
@@ -64,15 +99,46 @@ func (p *Engine) SetAvailable() {
 }
 
 func (p *Engine) BuildPullQuery(execCtx *execctx.ExecutionContext, query string) (exec.PullExecutor, error) {
+	logicalPlan, physicalPlan, err := p.planPullQuery(execCtx, query)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return p.buildPullDAGWithOutputNames(execCtx, logicalPlan, physicalPlan, false)
+}
+
+func (p *Engine) planPullQuery(execCtx *execctx.ExecutionContext, query string) (planner.LogicalPlan, planner.PhysicalPlan, error) {
 	qi := execCtx.QueryInfo
 	qi.ExecutionID = execCtx.ID
 	qi.SchemaName = execCtx.Schema.Name
 	qi.Query = query
 	ast, err := execCtx.Planner().Parse(query)
 	if err != nil {
-		return nil, errors.WithStack(err)
+		return nil, nil, errors.WithStack(err)
 	}
 	logicalPlan, err := execCtx.Planner().BuildLogicalPlan(ast, false)
+	if err != nil {
+		return nil, nil, errors.WithStack(err)
+	}
+	physicalPlan, err := execCtx.Planner().BuildPhysicalPlan(logicalPlan, true)
+	if err != nil {
+		return nil, nil, errors.WithStack(err)
+	}
+	return logicalPlan, physicalPlan, nil
+}
+
+// BuildMatchedRowsQuery builds a query of the rows on the shard which an update or delete statement changes, from the
+// table and where clause of the statement. The query reads the rows stored on this node rather than asking the node
+// which processes the shard, so it must be executed by that node.
+func (p *Engine) BuildMatchedRowsQuery(execCtx *execctx.ExecutionContext, statement string, shardID uint64) (exec.PullExecutor, error) {
+	ast, err := execCtx.Planner().Parse(statement)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	query, err := ast.MatchedRowsQuery()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	logicalPlan, err := execCtx.Planner().BuildLogicalPlan(query, false)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
@@ -80,7 +146,12 @@ func (p *Engine) BuildPullQuery(execCtx *execctx.ExecutionContext, query string)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
-	return p.buildPullDAGWithOutputNames(execCtx, logicalPlan, physicalPlan, false)
+	if pushDownScan(physicalPlan) == nil {
+		// All of the query must be executed on the shard
+		return nil, errors.NewInvalidStatementError("Unsupported where clause in update or delete statement")
+	}
+	execCtx.QueryInfo.ShardID = shardID
+	return p.buildPullDAG(execCtx, physicalPlan, true)
 }
 
 // ExecuteRemotePullQuery - executes a pull query received from another node
@@ -97,6 +168,14 @@ func (p *Engine) ExecuteRemotePullQuery(queryInfo *cluster.QueryExecutionInfo) (
 		panic("empty execution id")
 	}
 	s, ok := p.getCachedExecCtx(queryInfo.ExecutionID)
+	if queryInfo.Close {
+		// The originating node has abandoned the query before fetching all its rows
+		if ok {
+			p.execCtxCache().Delete(queryInfo.ExecutionID)
+			s.Close()
+		}
+		return nil, nil
+	}
 	newExecution := false
 	if !ok {
 		schema := p.metaController.GetOrCreateSchema(queryInfo.SchemaName)
@@ -107,6 +186,9 @@ func (p *Engine) ExecuteRemotePullQuery(queryInfo *cluster.QueryExecutionInfo) (
 		if err != nil {
 			return nil, errors.WithStack(err)
 		}
+		if queryInfo.PsArgs != nil {
+			s.Planner().SetPSArgs(queryInfo.PsArgs)
+		}
 		logic, err := s.Planner().BuildLogicalPlan(ast, true)
 		if err != nil {
 			return nil, errors.WithStack(err)
@@ -117,13 +199,15 @@ func (p *Engine) ExecuteRemotePullQuery(queryInfo *cluster.QueryExecutionInfo) (
 		}
 		dag, err := p.buildPullDAG(s, physicalPlan, false)
 		if err != nil {
+			s.Close()
 			return nil, errors.WithStack(err)
 		}
-		remExecutor := p.findRemoteExecutor(dag)
-		if remExecutor == nil {
+		remExecutors := findRemoteExecutors(dag, nil)
+		if int(queryInfo.RemoteExecutorIndex) >= len(remExecutors) {
+			s.Close()
 			return nil, errors.Error("cannot find remote executor")
 		}
-		s.CurrentQuery = remExecutor.RemoteDag
+		s.CurrentQuery = remExecutors[queryInfo.RemoteExecutorIndex].RemoteDag
 	} else if s.QueryInfo.Query != queryInfo.Query {
 		// Sanity check
 		panic(fmt.Sprintf("Already executing query is %s but passed in query is %s", s.QueryInfo.Query, queryInfo.Query))
@@ -132,6 +216,8 @@ func (p *Engine) ExecuteRemotePullQuery(queryInfo *cluster.QueryExecutionInfo) (
 	if err != nil {
 		// Make sure we remove current query in case of error
 		s.CurrentQuery = nil
+		p.execCtxCache().Delete(queryInfo.ExecutionID)
+		s.Close()
 		return nil, errors.WithStack(err)
 	}
 	if newExecution && s.CurrentQuery != nil {
@@ -140,6 +226,7 @@ func (p *Engine) ExecuteRemotePullQuery(queryInfo *cluster.QueryExecutionInfo) (
 	} else if s.CurrentQuery == nil {
 		// We can delete the exec ctx if current query is complete
 		p.execCtxCache().Delete(queryInfo.ExecutionID)
+		s.Close()
 	}
 	return rows, errors.WithStack(err)
 }
@@ -168,19 +255,18 @@ func (p *Engine) getCachedExecCtx(ctxID string) (*execctx.ExecutionContext, bool
 	return s, true
 }
 
-func (p *Engine) findRemoteExecutor(executor exec.PullExecutor) *exec.RemoteExecutor {
+// findRemoteExecutors appends the remote executors in the dag to found, in the order they are found in a depth first
+// walk of the dag. The remote node builds the same dag, and uses the order to find the remote part it is asked to execute.
+func findRemoteExecutors(executor exec.PullExecutor, found []*exec.RemoteExecutor) []*exec.RemoteExecutor {
 	// We only execute the part of the dag beyond the table reader - this is the remote part
 	remExecutor, ok := executor.(*exec.RemoteExecutor)
 	if ok {
-		return remExecutor
+		return append(found, remExecutor)
 	}
 	for _, child := range executor.GetChildren() {
-		remExecutor := p.findRemoteExecutor(child)
-		if remExecutor != nil {
-			return remExecutor
-		}
+		found = findRemoteExecutors(child, found)
 	}
-	return nil
+	return found
 }
 
 func (p *Engine) NumCachedExecCtxs() (int, error) {
@@ -231,6 +317,9 @@ func (p *Engine) clearExecCtxsForNode(nodeID int) {
 		return true
 	})
 	for _, ctxID := range idsToRemove {
+		if s, ok := p.getCachedExecCtx(ctxID); ok {
+			s.Close()
+		}
 		p.execCtxCache().Delete(ctxID)
 	}
 }
@@ -242,6 +331,7 @@ func (p *Engine) ExecuteQuery(schemaName string, query string) (rows *common.Row
 		return nil, errors.Errorf("no such schema %s", schemaName)
 	}
 	execCtx := execctx.NewExecutionContext("", schema)
+	defer execCtx.Close()
 	executor, err := p.BuildPullQuery(execCtx, query)
 	if err != nil {
 		return nil, errors.WithStack(err)
@@ -261,7 +351,6 @@ func (p *Engine) ExecuteQuery(schemaName string, query string) (rows *common.Row
 			break
 		}
 	}
-	// No need to close execCtx as no prepared statements
 	return rows, nil
 }
 
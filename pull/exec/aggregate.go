@@ -0,0 +1,330 @@
+package exec
+
+import (
+	"github.com/cznic/mathutil"
+	"github.com/squareup/pranadb/aggfuncs"
+	"github.com/squareup/pranadb/common"
+	"github.com/squareup/pranadb/errors"
+)
+
+type AggregationPhase int
+
+const (
+	// AggregationPhaseSingle aggregates the child rows and returns the results
+	AggregationPhaseSingle AggregationPhase = iota
+	// AggregationPhasePartial aggregates the child rows on a shard and returns the aggregate state, to be merged by an
+	// aggregation in AggregationPhaseFinal
+	AggregationPhasePartial
+	// AggregationPhaseFinal merges the aggregate state returned by the shards and returns the results
+	AggregationPhaseFinal
+)
+
+// PullAggregate calculates an aggregation in a pull query. The aggregation is usually calculated in two phases - each
+// shard calculates a partial aggregation of its rows, and the partial aggregations are merged on the node executing the
+// query, so only one row per group is sent from each shard. Distinct values can't be combined from partial aggregations,
+// so if there are any distinct functions the rows are all aggregated on the node executing the query instead.
+//
+// The aggregate state rows have a column for each aggregate function, including a hidden first row function for each
+// group by expression, followed by a column for the intermediate state of each function which needs it. The results
+// only have the columns of the functions which aren't hidden.
+type PullAggregate struct {
+	pullExecutorBase
+	phase            AggregationPhase
+	aggFuncs         []aggfuncs.AggregateFunction
+	argTypes         []common.ColumnType
+	distinct         []bool
+	funcColTypes     []common.ColumnType
+	stateColTypes    []common.ColumnType
+	intermediateCols []int // The intermediate state column index for each aggregate function, or -1
+	groupByExprs     []*common.Expression
+	groupByTypes     []common.ColumnType
+	rows             *common.Rows
+	rowIndex         int
+}
+
+type pullAggGroup struct {
+	aggState       *aggfuncs.AggState
+	distinctValues []map[string]struct{}
+}
+
+// NewPullAggregate creates a PullAggregate. The group by expressions are evaluated on the child rows, so they aren't
+// needed in AggregationPhaseFinal, where the groups are given by the hidden functions.
+func NewPullAggregate(colNames []string, aggFunctions []*aggfuncs.AggregateFunctionInfo, groupByExprs []*common.Expression,
+	phase AggregationPhase) (*PullAggregate, error) {
+	numGroupBy := 0
+	funcColTypes := make([]common.ColumnType, len(aggFunctions))
+	argTypes := make([]common.ColumnType, len(aggFunctions))
+	aggFuncs := make([]aggfuncs.AggregateFunction, len(aggFunctions))
+	distinct := make([]bool, len(aggFunctions))
+	var visibleColTypes []common.ColumnType
+	for i, funcInfo := range aggFunctions {
+		funcColTypes[i] = funcInfo.ReturnType
+		argTypes[i] = funcInfo.ArgType
+		if funcInfo.Hidden {
+			numGroupBy++
+		} else {
+			visibleColTypes = append(visibleColTypes, funcInfo.ReturnType)
+		}
+		aggFunc, err := aggfuncs.NewAggregateFunction(funcInfo.ArgExpr, funcInfo.FuncType, funcInfo.ReturnType)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		aggFuncs[i] = aggFunc
+		switch funcInfo.FuncType {
+		case aggfuncs.FirstRowAggregateFunctionType, aggfuncs.MinAggregateFunctionType, aggfuncs.MaxAggregateFunctionType:
+			// The extreme value is the same whether or not values are distinct
+		default:
+			if funcInfo.Distinct {
+				if phase != AggregationPhaseSingle {
+					return nil, errors.Error("distinct aggregate functions can only be calculated in a single phase")
+				}
+				distinct[i] = true
+			}
+		}
+	}
+	if phase != AggregationPhaseFinal && len(groupByExprs) != numGroupBy {
+		return nil, errors.Errorf("aggregation has %d group by expressions but %d hidden functions", len(groupByExprs), numGroupBy)
+	}
+	stateColTypes := funcColTypes
+	intermediateCols := make([]int, len(aggFuncs))
+	for i, aggFunc := range aggFuncs {
+		intermediateCols[i] = -1
+		if aggFunc.RequiresIntermediateState() {
+			if len(stateColTypes) == len(funcColTypes) {
+				stateColTypes = append([]common.ColumnType{}, funcColTypes...)
+			}
+			intermediateCols[i] = len(stateColTypes)
+			stateColTypes = append(stateColTypes, common.VarcharColumnType)
+		}
+	}
+	// The hidden functions come last, and are the key columns of the state
+	keyCols := make([]int, numGroupBy)
+	groupByTypes := make([]common.ColumnType, numGroupBy)
+	for i := range keyCols {
+		keyCols[i] = len(visibleColTypes) + i
+		groupByTypes[i] = funcColTypes[keyCols[i]]
+	}
+	colTypes := visibleColTypes
+	if phase == AggregationPhasePartial {
+		colTypes = stateColTypes
+	}
+	return &PullAggregate{
+		pullExecutorBase: pullExecutorBase{
+			colNames:    colNames,
+			colTypes:    colTypes,
+			keyCols:     keyCols,
+			rowsFactory: common.NewRowsFactory(colTypes),
+		},
+		phase:            phase,
+		aggFuncs:         aggFuncs,
+		argTypes:         argTypes,
+		distinct:         distinct,
+		funcColTypes:     funcColTypes,
+		stateColTypes:    stateColTypes,
+		intermediateCols: intermediateCols,
+		groupByExprs:     groupByExprs,
+		groupByTypes:     groupByTypes,
+	}, nil
+}
+
+// StateColTypes returns the column types of the aggregate state returned in AggregationPhasePartial
+func (p *PullAggregate) StateColTypes() []common.ColumnType {
+	return p.stateColTypes
+}
+
+func (p *PullAggregate) GetRows(limit int) (*common.Rows, error) {
+	if limit < 1 {
+		return nil, errors.Errorf("invalid limit %d", limit)
+	}
+	if p.rows == nil {
+		rows, err := p.aggregate()
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		p.rows = rows
+	}
+	rowsLeft := p.rows.RowCount() - p.rowIndex
+	rowsToGet := mathutil.Min(rowsLeft, limit)
+	res := p.rowsFactory.NewRows(rowsToGet)
+	for i := p.rowIndex; i < p.rowIndex+rowsToGet; i++ {
+		res.AppendRow(p.rows.GetRow(i))
+	}
+	p.rowIndex += rowsToGet
+	return res, nil
+}
+
+// aggregate gets all the rows from the child and aggregates them
+func (p *PullAggregate) aggregate() (*common.Rows, error) {
+	groups := make(map[string]*pullAggGroup)
+	var groupKeys []string // So the groups are returned in the order they were first seen
+	for {
+		batch, err := p.GetChildren()[0].GetRows(queryBatchSize)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		for i := 0; i < batch.RowCount(); i++ {
+			row := batch.GetRow(i)
+			key, err := p.groupKey(&row)
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+			group, ok := groups[key]
+			if !ok {
+				group = &pullAggGroup{aggState: aggfuncs.NewAggState(len(p.aggFuncs))}
+				groups[key] = group
+				groupKeys = append(groupKeys, key)
+			}
+			if p.phase == AggregationPhaseFinal {
+				err = p.mergeState(&row, group)
+			} else {
+				err = p.evaluateAggFunctions(&row, group)
+			}
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+		}
+		if batch.RowCount() < queryBatchSize {
+			break
+		}
+	}
+	if len(groupKeys) == 0 && len(p.keyCols) == 0 && p.phase != AggregationPhasePartial {
+		// An aggregation without group by returns a row even when there are no rows
+		return p.emptyResult(), nil
+	}
+	if p.phase != AggregationPhasePartial {
+		// The results are the visible functions, which come first, without the intermediate state
+		results := p.rowsFactory.NewRows(len(groupKeys))
+		for _, key := range groupKeys {
+			if err := aggfuncs.AppendAggState(groups[key].aggState, p.colTypes, results); err != nil {
+				return nil, errors.WithStack(err)
+			}
+		}
+		return results, nil
+	}
+	stateRows := p.rowsFactory.NewRows(len(groupKeys))
+	for _, key := range groupKeys {
+		aggState := groups[key].aggState
+		if err := aggfuncs.AppendAggState(aggState, p.funcColTypes, stateRows); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		for i, col := range p.intermediateCols {
+			if col != -1 {
+				stateRows.AppendStringToColumn(col, common.ByteSliceToStringZeroCopy(aggState.GetIntermediateState(i)))
+			}
+		}
+	}
+	return stateRows, nil
+}
+
+// groupKey returns the key of the group of the row. Null values are a group of their own.
+func (p *PullAggregate) groupKey(row *common.Row) (string, error) {
+	var key []byte
+	for i, colType := range p.groupByTypes {
+		if p.phase == AggregationPhaseFinal {
+			col := p.keyCols[i]
+			if row.IsNull(col) {
+				key = append(key, 0)
+				continue
+			}
+			key = append(key, 1)
+			var err error
+			if key, err = common.EncodeKeyCol(row, col, colType, key); err != nil {
+				return "", errors.WithStack(err)
+			}
+			continue
+		}
+		value, null, err := aggfuncs.EvalArgExpr(p.groupByExprs[i], colType, row)
+		if err != nil {
+			return "", errors.WithStack(err)
+		}
+		if null {
+			key = append(key, 0)
+			continue
+		}
+		key = append(key, 1)
+		if key, err = common.EncodeKeyElement(value, colType, key); err != nil {
+			return "", errors.WithStack(err)
+		}
+	}
+	return string(key), nil
+}
+
+func (p *PullAggregate) evaluateAggFunctions(row *common.Row, group *pullAggGroup) error {
+	for index, aggFunc := range p.aggFuncs {
+		if p.distinct[index] {
+			counted, err := p.countDistinctValue(row, group, index)
+			if err != nil {
+				return errors.WithStack(err)
+			}
+			if !counted {
+				continue
+			}
+		}
+		if _, err := aggfuncs.EvalAggregateFunction(aggFunc, p.argTypes[index], group.aggState, index, row, false); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	return nil
+}
+
+// countDistinctValue returns true if the value of the argument of the function hasn't been seen before in the group
+func (p *PullAggregate) countDistinctValue(row *common.Row, group *pullAggGroup, index int) (bool, error) {
+	value, null, err := aggfuncs.EvalArgExpr(p.aggFuncs[index].ArgExpression(), p.argTypes[index], row)
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+	if null {
+		// Nulls are ignored by the functions anyway
+		return true, nil
+	}
+	encoded, err := common.EncodeKeyElement(value, p.argTypes[index], nil)
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+	if group.distinctValues == nil {
+		group.distinctValues = make([]map[string]struct{}, len(p.aggFuncs))
+	}
+	values := group.distinctValues[index]
+	if values == nil {
+		values = make(map[string]struct{})
+		group.distinctValues[index] = values
+	}
+	if _, ok := values[string(encoded)]; ok {
+		return false, nil
+	}
+	values[string(encoded)] = struct{}{}
+	return true, nil
+}
+
+// mergeState merges a row of aggregate state from a shard into the group
+func (p *PullAggregate) mergeState(row *common.Row, group *pullAggGroup) error {
+	toMerge := aggfuncs.NewAggState(len(p.aggFuncs))
+	if err := aggfuncs.InitAggStateWithRow(row, p.funcColTypes, toMerge); err != nil {
+		return errors.WithStack(err)
+	}
+	for i, col := range p.intermediateCols {
+		if col != -1 && !row.IsNull(col) {
+			toMerge.SetIntermediateState(i, []byte(row.GetString(col)))
+		}
+	}
+	for index, aggFunc := range p.aggFuncs {
+		if err := aggfuncs.MergeAggregateFunction(aggFunc, toMerge, group.aggState, index, false); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	return nil
+}
+
+// emptyResult returns the result of an aggregation without group by of no rows - COUNT is zero and the other functions
+// are null
+func (p *PullAggregate) emptyResult() *common.Rows {
+	rows := p.rowsFactory.NewRows(1)
+	for i, aggFunc := range p.aggFuncs[:len(p.colTypes)] {
+		if _, ok := aggFunc.(*aggfuncs.CountAggregateFunction); ok {
+			rows.AppendInt64ToColumn(i, 0)
+		} else {
+			rows.AppendNullToColumn(i)
+		}
+	}
+	return rows
+}
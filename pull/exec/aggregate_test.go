@@ -0,0 +1,140 @@
+package exec
+
+import (
+	"testing"
+
+	"github.com/squareup/pranadb/aggfuncs"
+	"github.com/squareup/pranadb/common"
+	"github.com/squareup/pranadb/common/commontest"
+	"github.com/stretchr/testify/require"
+)
+
+var aggInputRows = [][]interface{}{
+	{1, "london", 20.5, "10.25"},
+	{2, "paris", 25.0, "5.50"},
+	{3, "london", 22.5, "7.75"},
+	{4, nil, 15.0, "1.00"},
+	{5, "paris", 27.0, nil},
+	{6, "london", 18.0, "2.00"},
+	{7, nil, 16.0, "3.00"},
+}
+
+var sumColType = common.NewDecimalColumnType(32, 2)
+
+func aggTestFunctions(distinct bool) []*aggfuncs.AggregateFunctionInfo {
+	return []*aggfuncs.AggregateFunctionInfo{
+		{FuncType: aggfuncs.FirstRowAggregateFunctionType, ArgExpr: colExpression(1), ArgType: colTypes[1], ReturnType: colTypes[1]},
+		{FuncType: aggfuncs.CountAggregateFunctionType, ArgExpr: colExpression(3), ArgType: colTypes[3], ReturnType: common.BigIntColumnType, Distinct: distinct},
+		{FuncType: aggfuncs.SumAggregateFunctionType, ArgExpr: colExpression(3), ArgType: colTypes[3], ReturnType: sumColType, Distinct: distinct},
+		{FuncType: aggfuncs.AvgAggregateFunctionType, ArgExpr: colExpression(2), ArgType: colTypes[2], ReturnType: common.DoubleColumnType},
+		{FuncType: aggfuncs.MaxAggregateFunctionType, ArgExpr: colExpression(2), ArgType: colTypes[2], ReturnType: common.DoubleColumnType},
+		// The hidden function for the group by expression
+		{FuncType: aggfuncs.FirstRowAggregateFunctionType, ArgExpr: colExpression(1), ArgType: colTypes[1], ReturnType: colTypes[1], Hidden: true},
+	}
+}
+
+var aggResultColTypes = []common.ColumnType{colTypes[1], common.BigIntColumnType, sumColType, common.DoubleColumnType, common.DoubleColumnType}
+
+var aggResultColNames = []string{"location", "count", "sum", "avg", "max"}
+
+func TestPullAggregateSinglePhase(t *testing.T) {
+	agg, err := NewPullAggregate(aggResultColNames, aggTestFunctions(false), []*common.Expression{colExpression(1)}, AggregationPhaseSingle)
+	require.NoError(t, err)
+	agg.AddChild(newRowProvider(t, aggInputRows, colTypes))
+	expected := [][]interface{}{
+		{"london", 3, "20.00", 20.333333333333332, 22.5},
+		{"paris", 1, "5.50", 26.0, 27.0},
+		{nil, 2, "4.00", 15.5, 16.0},
+	}
+	requireRows(t, agg, expected, aggResultColTypes)
+}
+
+func TestPullAggregateTwoPhases(t *testing.T) {
+	// Each shard calculates a partial aggregation of its rows, which are then merged
+	aggFuncs := aggTestFunctions(false)
+	groupByExprs := []*common.Expression{colExpression(1)}
+	var stateColTypes []common.ColumnType
+	var states *common.Rows
+	for _, shardRows := range [][][]interface{}{aggInputRows[:3], aggInputRows[3:]} {
+		partialAgg, err := NewPullAggregate(nil, aggFuncs, groupByExprs, AggregationPhasePartial)
+		require.NoError(t, err)
+		partialAgg.AddChild(newRowProvider(t, shardRows, colTypes))
+		stateColTypes = partialAgg.StateColTypes()
+		require.Equal(t, stateColTypes, partialAgg.ColTypes())
+		rows, err := partialAgg.GetRows(1000)
+		require.NoError(t, err)
+		if states == nil {
+			states = rows
+		} else {
+			states.AppendAll(rows)
+		}
+	}
+	// One row per group from each shard
+	require.Equal(t, 5, states.RowCount())
+
+	finalAgg, err := NewPullAggregate(aggResultColNames, aggFuncs, nil, AggregationPhaseFinal)
+	require.NoError(t, err)
+	finalAgg.AddChild(&rowProvider{rowsFactory: common.NewRowsFactory(stateColTypes), rows: states})
+	expected := [][]interface{}{
+		{"london", 3, "20.00", 20.333333333333332, 22.5},
+		{"paris", 1, "5.50", 26.0, 27.0},
+		{nil, 2, "4.00", 15.5, 16.0},
+	}
+	requireRows(t, finalAgg, expected, aggResultColTypes)
+}
+
+func TestPullAggregateDistinct(t *testing.T) {
+	inputRows := append([][]interface{}{
+		{8, "london", 30.0, "10.25"},
+		{9, "paris", 10.0, "5.50"},
+	}, aggInputRows...)
+	agg, err := NewPullAggregate(aggResultColNames, aggTestFunctions(true), []*common.Expression{colExpression(1)}, AggregationPhaseSingle)
+	require.NoError(t, err)
+	agg.AddChild(newRowProvider(t, inputRows, colTypes))
+	expected := [][]interface{}{
+		{"london", 3, "20.00", 22.75, 30.0},
+		{"paris", 1, "5.50", 20.666666666666668, 27.0},
+		{nil, 2, "4.00", 15.5, 16.0},
+	}
+	requireRows(t, agg, expected, aggResultColTypes)
+
+	_, err = NewPullAggregate(aggResultColNames, aggTestFunctions(true), []*common.Expression{colExpression(1)}, AggregationPhasePartial)
+	require.Error(t, err)
+}
+
+func TestPullAggregateNoGroupByNoRows(t *testing.T) {
+	aggFuncs := aggTestFunctions(false)
+	aggFuncs = aggFuncs[1 : len(aggFuncs)-1]
+	agg, err := NewPullAggregate(aggResultColNames[1:], aggFuncs, nil, AggregationPhaseSingle)
+	require.NoError(t, err)
+	agg.AddChild(newRowProvider(t, nil, colTypes))
+	requireRows(t, agg, [][]interface{}{{0, nil, nil, nil}}, aggResultColTypes[1:])
+}
+
+func TestPullAggregateGetRowsInBatches(t *testing.T) {
+	agg, err := NewPullAggregate(aggResultColNames, aggTestFunctions(false), []*common.Expression{colExpression(1)}, AggregationPhaseSingle)
+	require.NoError(t, err)
+	agg.AddChild(newRowProvider(t, aggInputRows, colTypes))
+	rows, err := agg.GetRows(2)
+	require.NoError(t, err)
+	require.Equal(t, 2, rows.RowCount())
+	rows, err = agg.GetRows(2)
+	require.NoError(t, err)
+	require.Equal(t, 1, rows.RowCount())
+}
+
+func newRowProvider(t *testing.T, rows [][]interface{}, colTypes []common.ColumnType) *rowProvider {
+	t.Helper()
+	return &rowProvider{
+		rowsFactory: common.NewRowsFactory(colTypes),
+		rows:        toRows(t, rows, colTypes),
+	}
+}
+
+func requireRows(t *testing.T, executor PullExecutor, expected [][]interface{}, colTypes []common.ColumnType) {
+	t.Helper()
+	rows, err := executor.GetRows(1000)
+	require.NoError(t, err)
+	require.Equal(t, len(expected), rows.RowCount())
+	commontest.AllRowsEqual(t, toRows(t, expected, colTypes), rows, colTypes)
+}
@@ -7,7 +7,7 @@ import (
 type ExecutorType uint32
 
 const (
-	orderByMaxRows = 50000
+	limitMaxRows   = 50000
 	queryBatchSize = 10000
 )
 
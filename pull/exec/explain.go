@@ -0,0 +1,197 @@
+package exec
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/squareup/pranadb/common"
+)
+
+// ExecutorStats are the number of rows returned by an executor, and the time spent getting them, while a query is
+// analyzed. The time includes the time spent in the children of the executor.
+type ExecutorStats struct {
+	Rows int64
+	Time time.Duration
+}
+
+// AnalyzedExecutor records the stats of the executor it wraps
+type AnalyzedExecutor struct {
+	PullExecutor
+	Stats ExecutorStats
+}
+
+var _ PullExecutor = &AnalyzedExecutor{}
+
+func (a *AnalyzedExecutor) GetRows(limit int) (*common.Rows, error) {
+	start := time.Now()
+	rows, err := a.PullExecutor.GetRows(limit)
+	a.Stats.Time += time.Since(start)
+	if rows != nil {
+		a.Stats.Rows += int64(rows.RowCount())
+	}
+	return rows, err
+}
+
+// shardStats are the stats of the remote part of a query on each shard. The remote executors created for the lookups
+// of an index lookup join share the stats of the executor they were created from.
+type shardStats struct {
+	lock  sync.Mutex
+	stats map[uint64]*ExecutorStats
+}
+
+func (s *shardStats) add(shardID uint64, rows *common.Rows, duration time.Duration) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	stats, ok := s.stats[shardID]
+	if !ok {
+		stats = &ExecutorStats{}
+		s.stats[shardID] = stats
+	}
+	stats.Time += duration
+	if rows != nil {
+		stats.Rows += int64(rows.RowCount())
+	}
+}
+
+// Analyze wraps the executors of a DAG so that the rows each returns and the time spent in it are recorded while the
+// DAG is executed. Remote executors also record the rows and time of each shard, as seen from this node. The
+// executors of the remote parts of the DAG run on other nodes, so their stats are not recorded.
+func Analyze(dag PullExecutor) *AnalyzedExecutor {
+	analyzeChildren(dag)
+	return &AnalyzedExecutor{PullExecutor: dag}
+}
+
+func analyzeChildren(executor PullExecutor) {
+	switch e := executor.(type) {
+	case *PullChain:
+		// The executors of the chain are connected as parent and child
+		analyzeChildren(e.first())
+		return
+	case *RemoteExecutor:
+		e.shardStats = &shardStats{stats: make(map[uint64]*ExecutorStats)}
+		return
+	}
+	innerIndex := -1
+	if lookupJoin, ok := executor.(*PullIndexLookupJoin); ok {
+		// The inner side must remain a remote executor, so it isn't wrapped. Its stats are those of its shards.
+		innerIndex = 1
+		if !lookupJoin.joiner.probeLeft {
+			innerIndex = 0
+		}
+	}
+	children := executor.GetChildren()
+	for i, child := range children {
+		analyzeChildren(child)
+		if i != innerIndex {
+			children[i] = &AnalyzedExecutor{PullExecutor: child}
+		}
+	}
+}
+
+// ExplainedExecutor describes an executor of a pull DAG
+type ExplainedExecutor struct {
+	Level    int // The depth of the executor in the DAG
+	Operator string
+	Info     string
+	Stats    *ExecutorStats // Only set if the DAG was analyzed and the stats of the executor were recorded
+}
+
+// Explain describes the executors of a DAG in the order of a depth first walk. The remote part of the DAG is shown
+// below the remote executor which executes it, after the stats of each shard if the DAG was analyzed.
+func Explain(dag PullExecutor) []ExplainedExecutor {
+	return explain(dag, 0, nil, nil)
+}
+
+func explain(executor PullExecutor, level int, stats *ExecutorStats, explained []ExplainedExecutor) []ExplainedExecutor {
+	switch e := executor.(type) {
+	case *AnalyzedExecutor:
+		return explain(e.PullExecutor, level, &e.Stats, explained)
+	case *PullChain:
+		return explain(e.first(), level, stats, explained)
+	}
+	explained = append(explained, ExplainedExecutor{
+		Level:    level,
+		Operator: reflect.TypeOf(executor).Elem().Name(),
+		Info:     explainInfo(executor),
+		Stats:    stats,
+	})
+	if re, ok := executor.(*RemoteExecutor); ok {
+		if re.shardStats != nil {
+			explained = append(explained, re.explainShards(level+1)...)
+		}
+		explained = explain(re.RemoteDag, level+1, nil, explained)
+	}
+	for _, child := range executor.GetChildren() {
+		explained = explain(child, level+1, nil, explained)
+	}
+	return explained
+}
+
+func (re *RemoteExecutor) explainShards(level int) []ExplainedExecutor {
+	re.shardStats.lock.Lock()
+	defer re.shardStats.lock.Unlock()
+	shardIDs := make([]uint64, 0, len(re.shardStats.stats))
+	for shardID := range re.shardStats.stats {
+		shardIDs = append(shardIDs, shardID)
+	}
+	sort.Slice(shardIDs, func(i, j int) bool { return shardIDs[i] < shardIDs[j] })
+	explained := make([]ExplainedExecutor, len(shardIDs))
+	for i, shardID := range shardIDs {
+		stats := *re.shardStats.stats[shardID]
+		explained[i] = ExplainedExecutor{
+			Level:    level,
+			Operator: fmt.Sprintf("shard %d", shardID),
+			Stats:    &stats,
+		}
+	}
+	return explained
+}
+
+var aggregationPhaseNames = map[AggregationPhase]string{
+	AggregationPhaseSingle:  "single",
+	AggregationPhasePartial: "partial",
+	AggregationPhaseFinal:   "final",
+}
+
+var joinTypeNames = map[JoinType]string{
+	InnerJoin:      "inner",
+	LeftOuterJoin:  "left outer",
+	RightOuterJoin: "right outer",
+}
+
+func explainInfo(executor PullExecutor) string {
+	switch e := executor.(type) {
+	case *PullTableScan:
+		return fmt.Sprintf("table:%s", e.tableInfo.Name)
+	case *PullIndexReader:
+		info := fmt.Sprintf("table:%s, index:%s", e.tableInfo.Name, e.indexInfo.Name)
+		if e.covers {
+			info += ", covering"
+		}
+		return info
+	case *PullLimit:
+		return fmt.Sprintf("count:%d, offset:%d", e.count, e.offset)
+	case *PullAggregate:
+		return fmt.Sprintf("phase:%s", aggregationPhaseNames[e.phase])
+	case *PullHashJoin:
+		return fmt.Sprintf("type:%s", joinTypeNames[e.joiner.joinType])
+	case *PullIndexLookupJoin:
+		return fmt.Sprintf("type:%s", joinTypeNames[e.joiner.joinType])
+	case *RemoteExecutor:
+		var info []string
+		if e.pointGetQueryInfo != nil {
+			info = append(info, "point get")
+		} else {
+			info = append(info, "all shards")
+		}
+		if e.order != nil {
+			info = append(info, "merge sorted")
+		}
+		return strings.Join(info, ", ")
+	}
+	return ""
+}
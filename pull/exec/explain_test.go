@@ -0,0 +1,59 @@
+package exec
+
+import (
+	"testing"
+
+	"github.com/squareup/pranadb/common"
+	"github.com/stretchr/testify/require"
+)
+
+func createExplainTestDAG(t *testing.T) PullExecutor {
+	t.Helper()
+	rows := toRows(t, [][]interface{}{
+		{3, "london", 21.3, "3.20"},
+		{1, "paris", 19.1, "1.10"},
+		{5, "rome", 25.4, "5.70"},
+		{2, "madrid", 28.9, "2.30"},
+		{4, "berlin", 17.2, "4.40"},
+	}, colTypes)
+	static, err := NewStaticRows(colNames, rows)
+	require.NoError(t, err)
+	limit := NewPullLimit(colNames, colTypes, 2, 0)
+	sort := NewPullSort(colNames, colTypes, []bool{false}, []*common.Expression{colExpression(0)}, nil)
+	chain := NewPullChain(limit, sort)
+	ConnectPullExecutors([]PullExecutor{static}, chain)
+	return chain
+}
+
+func TestExplain(t *testing.T) {
+	explained := Explain(createExplainTestDAG(t))
+	require.Equal(t, []ExplainedExecutor{
+		{Level: 0, Operator: "PullLimit", Info: "count:2, offset:0"},
+		{Level: 1, Operator: "PullSort"},
+		{Level: 2, Operator: "StaticRows"},
+	}, explained)
+}
+
+func TestExplainAnalyzed(t *testing.T) {
+	analyzed := Analyze(createExplainTestDAG(t))
+	rows, err := analyzed.GetRows(100)
+	require.NoError(t, err)
+	require.Equal(t, 2, rows.RowCount())
+	for i := 0; i < rows.RowCount(); i++ {
+		row := rows.GetRow(i)
+		require.Equal(t, int64(i+1), row.GetInt64(0))
+	}
+
+	explained := Explain(analyzed)
+	require.Equal(t, 3, len(explained))
+	// The limit drains its child, so all the rows pass through the sort
+	expectedRows := []int64{2, 5, 5}
+	for i, ex := range explained {
+		require.Equal(t, i, ex.Level)
+		require.NotNil(t, ex.Stats)
+		require.Equal(t, expectedRows[i], ex.Stats.Rows)
+	}
+	// The time of an executor includes the time of its children
+	require.GreaterOrEqual(t, explained[0].Stats.Time, explained[1].Stats.Time)
+	require.GreaterOrEqual(t, explained[1].Stats.Time, explained[2].Stats.Time)
+}
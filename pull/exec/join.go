@@ -0,0 +1,323 @@
+package exec
+
+import (
+	"github.com/squareup/pranadb/common"
+	"github.com/squareup/pranadb/errors"
+)
+
+type JoinType int
+
+const (
+	InnerJoin JoinType = iota
+	LeftOuterJoin
+	RightOuterJoin
+)
+
+// JoinSide describes one of the children of a join
+type JoinSide struct {
+	ColTypes []common.ColumnType
+	JoinCols []int                // The join key column indexes in the child
+	Conds    []*common.Expression // The conditions which only use columns of this child
+}
+
+// PullHashJoin joins the rows of its two children. All the rows of the build side are read into a hash table by join
+// key, and then the rows of the probe side are streamed past it. For outer joins the outer side is the probe side.
+type PullHashJoin struct {
+	pullExecutorBase
+	joiner        *joiner
+	built         bool
+	moreProbeRows bool
+	availRows     *common.Rows
+}
+
+var _ PullExecutor = &PullHashJoin{}
+
+func NewPullHashJoin(colNames []string, colTypes []common.ColumnType, joinType JoinType, left *JoinSide, right *JoinSide,
+	otherConds []*common.Expression, childCols []int) (*PullHashJoin, error) {
+	joiner, err := newJoiner(joinType, left, right, otherConds, childCols, joinType != RightOuterJoin)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	rf := common.NewRowsFactory(colTypes)
+	base := pullExecutorBase{
+		colNames:    colNames,
+		colTypes:    colTypes,
+		rowsFactory: rf,
+	}
+	return &PullHashJoin{
+		pullExecutorBase: base,
+		joiner:           joiner,
+		moreProbeRows:    true,
+	}, nil
+}
+
+func (p *PullHashJoin) GetRows(limit int) (*common.Rows, error) {
+	if limit < 1 {
+		return nil, errors.Errorf("invalid limit %d", limit)
+	}
+	probeChild, buildChild := p.children[0], p.children[1]
+	if !p.joiner.probeLeft {
+		probeChild, buildChild = buildChild, probeChild
+	}
+	if !p.built {
+		for {
+			rows, err := buildChild.GetRows(queryBatchSize)
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+			if err := p.joiner.addBuildRows(rows); err != nil {
+				return nil, errors.WithStack(err)
+			}
+			if rows.RowCount() < queryBatchSize {
+				break
+			}
+		}
+		p.built = true
+	}
+	if p.availRows == nil {
+		p.availRows = p.rowsFactory.NewRows(limit)
+	}
+	for p.moreProbeRows && p.availRows.RowCount() < limit {
+		rows, err := probeChild.GetRows(queryBatchSize)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		for i := 0; i < rows.RowCount(); i++ {
+			row := rows.GetRow(i)
+			if err := p.joiner.probe(&row, p.availRows); err != nil {
+				return nil, errors.WithStack(err)
+			}
+		}
+		if rows.RowCount() < queryBatchSize {
+			p.moreProbeRows = false
+		}
+	}
+	var rows *common.Rows
+	rows, p.availRows = takeRows(p.rowsFactory, p.availRows, limit)
+	return rows, nil
+}
+
+// joiner matches rows from the probe side of a join against the rows of the build side, which it holds in a hash table
+// by join key, and assembles the joined rows
+type joiner struct {
+	joinType   JoinType
+	left       *JoinSide
+	right      *JoinSide
+	probeLeft  bool
+	otherConds []*common.Expression
+	// The index of each output column in the columns of the left child followed by those of the right child
+	childCols         []int
+	joinedRowsFactory *common.RowsFactory
+	table             map[string][]common.Row
+}
+
+func newJoiner(joinType JoinType, left *JoinSide, right *JoinSide, otherConds []*common.Expression, childCols []int,
+	probeLeft bool) (*joiner, error) {
+	if len(left.JoinCols) != len(right.JoinCols) {
+		return nil, errors.Error("join must have the same number of key columns on each side")
+	}
+	for i, leftJoinCol := range left.JoinCols {
+		leftType := left.ColTypes[leftJoinCol]
+		rightType := right.ColTypes[right.JoinCols[i]]
+		if !keyTypesCompatible(leftType, rightType) {
+			return nil, errors.NewPranaErrorf(errors.InvalidStatement, "cannot join column of type %s with column of type %s",
+				leftType.String(), rightType.String())
+		}
+	}
+	if (joinType == LeftOuterJoin && !probeLeft) || (joinType == RightOuterJoin && probeLeft) {
+		return nil, errors.Error("the outer side of a join must be the probe side")
+	}
+	joinedColTypes := append(append([]common.ColumnType{}, left.ColTypes...), right.ColTypes...)
+	return &joiner{
+		joinType:          joinType,
+		left:              left,
+		right:             right,
+		probeLeft:         probeLeft,
+		otherConds:        otherConds,
+		childCols:         childCols,
+		joinedRowsFactory: common.NewRowsFactory(joinedColTypes),
+		table:             map[string][]common.Row{},
+	}, nil
+}
+
+func (j *joiner) sides() (*JoinSide, *JoinSide) {
+	if j.probeLeft {
+		return j.left, j.right
+	}
+	return j.right, j.left
+}
+
+// addBuildRows adds rows from the build side to the hash table
+func (j *joiner) addBuildRows(rows *common.Rows) error {
+	_, buildSide := j.sides()
+	for i := 0; i < rows.RowCount(); i++ {
+		row := rows.GetRow(i)
+		key, err := joinKey(buildSide, &row)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		if key != nil {
+			j.table[string(key)] = append(j.table[string(key)], row)
+		}
+	}
+	return nil
+}
+
+func (j *joiner) clear() {
+	j.table = map[string][]common.Row{}
+}
+
+// probe appends the joined rows for a row from the probe side to out. For outer joins, a row with no matches is
+// appended once, padded with nulls.
+func (j *joiner) probe(row *common.Row, out *common.Rows) error {
+	probeSide, _ := j.sides()
+	key, err := joinKey(probeSide, row)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	matched := false
+	if key != nil {
+		matches := j.table[string(key)]
+		for i := range matches {
+			ok, err := j.appendJoinedRow(row, &matches[i], out)
+			if err != nil {
+				return errors.WithStack(err)
+			}
+			matched = matched || ok
+		}
+	}
+	if !matched && j.joinType != InnerJoin {
+		_, err := j.appendJoinedRow(row, nil, out)
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// appendJoinedRow appends the row joined with its match to out if they satisfy the other conditions of the join, and
+// returns whether it did. If the match is nil the row is padded with nulls, and always appended.
+func (j *joiner) appendJoinedRow(row *common.Row, match *common.Row, out *common.Rows) (bool, error) {
+	leftRow, rightRow := row, match
+	if !j.probeLeft {
+		leftRow, rightRow = match, row
+	}
+	joinedRows := j.joinedRowsFactory.NewRows(1)
+	if err := appendCols(leftRow, j.left.ColTypes, 0, joinedRows); err != nil {
+		return false, errors.WithStack(err)
+	}
+	if err := appendCols(rightRow, j.right.ColTypes, len(j.left.ColTypes), joinedRows); err != nil {
+		return false, errors.WithStack(err)
+	}
+	joinedRow := joinedRows.GetRow(0)
+	if match != nil {
+		ok, err := evalConditions(j.otherConds, &joinedRow)
+		if err != nil || !ok {
+			return false, errors.WithStack(err)
+		}
+	}
+	joinedColTypes := joinedRows.ColumnTypes()
+	for i, childCol := range j.childCols {
+		if err := appendCol(&joinedRow, childCol, joinedColTypes[childCol], i, out); err != nil {
+			return false, errors.WithStack(err)
+		}
+	}
+	return true, nil
+}
+
+// joinKey returns the encoded join key of a row from the side, or nil if the row can't match any rows from the other
+// side, because one of its join key columns is null or it doesn't satisfy the conditions for its side
+func joinKey(side *JoinSide, row *common.Row) ([]byte, error) {
+	for _, joinCol := range side.JoinCols {
+		if row.IsNull(joinCol) {
+			return nil, nil
+		}
+	}
+	ok, err := evalConditions(side.Conds, row)
+	if err != nil || !ok {
+		return nil, errors.WithStack(err)
+	}
+	// A join without key columns matches every pair of rows, so the key is empty but not nil
+	return common.EncodeKeyCols(row, side.JoinCols, side.ColTypes, []byte{})
+}
+
+// appendCols appends the columns of the row to out, starting at the column offset. If the row is nil, nulls are
+// appended instead.
+func appendCols(row *common.Row, colTypes []common.ColumnType, offset int, out *common.Rows) error {
+	for i, colType := range colTypes {
+		if row == nil {
+			out.AppendNullToColumn(offset + i)
+			continue
+		}
+		if err := appendCol(row, i, colType, offset+i, out); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	return nil
+}
+
+func appendCol(row *common.Row, col int, colType common.ColumnType, outCol int, out *common.Rows) error {
+	if row.IsNull(col) {
+		out.AppendNullToColumn(outCol)
+		return nil
+	}
+	switch colType.Type {
+	case common.TypeTinyInt, common.TypeInt, common.TypeBigInt, common.TypeBoolean:
+		out.AppendInt64ToColumn(outCol, row.GetInt64(col))
+	case common.TypeDouble:
+		out.AppendFloat64ToColumn(outCol, row.GetFloat64(col))
+	case common.TypeVarchar, common.TypeVarbinary:
+		out.AppendStringToColumn(outCol, row.GetString(col))
+	case common.TypeDecimal:
+		out.AppendDecimalToColumn(outCol, row.GetDecimal(col))
+	case common.TypeTimestamp, common.TypeDate:
+		out.AppendTimestampToColumn(outCol, row.GetTimestamp(col))
+	case common.TypeJSON:
+		out.AppendJSONToColumn(outCol, row.GetJSON(col))
+	default:
+		return errors.Errorf("unexpected column type %v", colType)
+	}
+	return nil
+}
+
+// takeRows returns up to limit of the available rows, and the rest, which are kept for the next call to GetRows
+func takeRows(rowsFactory *common.RowsFactory, availRows *common.Rows, limit int) (*common.Rows, *common.Rows) {
+	if availRows.RowCount() <= limit {
+		return availRows, nil
+	}
+	out := rowsFactory.NewRows(limit)
+	rest := rowsFactory.NewRows(availRows.RowCount() - limit)
+	for i := 0; i < availRows.RowCount(); i++ {
+		if i < limit {
+			out.AppendRow(availRows.GetRow(i))
+		} else {
+			rest.AppendRow(availRows.GetRow(i))
+		}
+	}
+	return out, rest
+}
+
+// evalConditions returns true if the row satisfies all of the conditions. A condition which evaluates to null is not
+// satisfied.
+func evalConditions(conds []*common.Expression, row *common.Row) (bool, error) {
+	for _, cond := range conds {
+		accept, isNull, err := cond.EvalBoolean(row)
+		if err != nil {
+			return false, errors.WithStack(err)
+		}
+		if isNull || !accept {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// keyTypesCompatible returns true if values of the two types have the same key encoding
+func keyTypesCompatible(type1 common.ColumnType, type2 common.ColumnType) bool {
+	switch type1.Type {
+	case common.TypeTinyInt, common.TypeInt, common.TypeBigInt:
+		return type2.Type == common.TypeTinyInt || type2.Type == common.TypeInt || type2.Type == common.TypeBigInt
+	case common.TypeDecimal:
+		return type2.Type == common.TypeDecimal && type1.DecPrecision == type2.DecPrecision && type1.DecScale == type2.DecScale
+	default:
+		return type1.Type == type2.Type
+	}
+}
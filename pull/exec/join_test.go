@@ -0,0 +1,160 @@
+package exec
+
+import (
+	"testing"
+
+	"github.com/squareup/pranadb/cluster"
+	"github.com/squareup/pranadb/common"
+	"github.com/squareup/pranadb/common/commontest"
+	"github.com/stretchr/testify/require"
+)
+
+var paymentColTypes = []common.ColumnType{common.BigIntColumnType, common.IntColumnType, common.DoubleColumnType}
+var customerColTypes = []common.ColumnType{common.BigIntColumnType, common.VarcharColumnType}
+
+var paymentRows = [][]interface{}{
+	{1, 10, 100.0},
+	{2, 20, 75.5},
+	{3, 10, 250.0},
+	{4, 50, 12.0},
+	{5, nil, 30.0},
+}
+
+var customerRows = [][]interface{}{
+	{10, "alice"},
+	{20, "bob"},
+	{30, "carol"},
+}
+
+// The output is payment_id, amount, name
+var joinChildCols = []int{0, 2, 4}
+var joinColTypes = []common.ColumnType{common.BigIntColumnType, common.DoubleColumnType, common.VarcharColumnType}
+var joinColNames = []string{"payment_id", "amount", "name"}
+
+func TestPullHashJoinInner(t *testing.T) {
+	join := setupHashJoin(t, InnerJoin, []int{1}, []int{0})
+	expected := [][]interface{}{
+		{1, 100.0, "alice"},
+		{2, 75.5, "bob"},
+		{3, 250.0, "alice"},
+	}
+	requireJoinRows(t, join, expected)
+}
+
+func TestPullHashJoinLeftOuter(t *testing.T) {
+	join := setupHashJoin(t, LeftOuterJoin, []int{1}, []int{0})
+	expected := [][]interface{}{
+		{1, 100.0, "alice"},
+		{2, 75.5, "bob"},
+		{3, 250.0, "alice"},
+		{4, 12.0, nil},
+		{5, 30.0, nil},
+	}
+	requireJoinRows(t, join, expected)
+}
+
+func TestPullHashJoinRightOuter(t *testing.T) {
+	join := setupHashJoin(t, RightOuterJoin, []int{1}, []int{0})
+	expected := [][]interface{}{
+		{1, 100.0, "alice"},
+		{3, 250.0, "alice"},
+		{2, 75.5, "bob"},
+		{nil, nil, "carol"},
+	}
+	requireJoinRows(t, join, expected)
+}
+
+func TestPullHashJoinNoJoinKey(t *testing.T) {
+	join := setupHashJoin(t, InnerJoin, nil, nil)
+	rows, err := join.GetRows(1000)
+	require.NoError(t, err)
+	require.Equal(t, len(paymentRows)*len(customerRows), rows.RowCount())
+}
+
+func TestPullHashJoinGetRowsInBatches(t *testing.T) {
+	join := setupHashJoin(t, LeftOuterJoin, []int{1}, []int{0})
+	rows, err := join.GetRows(2)
+	require.NoError(t, err)
+	require.Equal(t, 2, rows.RowCount())
+	rows, err = join.GetRows(2)
+	require.NoError(t, err)
+	require.Equal(t, 2, rows.RowCount())
+	rows, err = join.GetRows(2)
+	require.NoError(t, err)
+	require.Equal(t, 1, rows.RowCount())
+}
+
+func TestPullHashJoinIncompatibleKeyTypes(t *testing.T) {
+	left := &JoinSide{ColTypes: paymentColTypes, JoinCols: []int{2}}
+	right := &JoinSide{ColTypes: customerColTypes, JoinCols: []int{1}}
+	_, err := NewPullHashJoin(joinColNames, joinColTypes, InnerJoin, left, right, nil, joinChildCols)
+	require.Error(t, err)
+}
+
+func TestPullLookup(t *testing.T) {
+	keyTypes := []common.ColumnType{common.BigIntColumnType, common.VarcharColumnType}
+	keys := toRows(t, [][]interface{}{{10, "uk"}, {20, "us"}}, keyTypes)
+	queryInfo := &cluster.QueryExecutionInfo{LookupKeys: keys.Serialize()}
+	var scanRanges []*ScanRange
+	createScan := func(ranges []*ScanRange) (PullExecutor, error) {
+		scanRanges = ranges
+		return &rowProvider{
+			rowsFactory: common.NewRowsFactory(customerColTypes),
+			rows:        toRows(t, customerRows[:2], customerColTypes),
+		}, nil
+	}
+	lookup := NewPullLookup(nil, customerColTypes, queryInfo, keyTypes, createScan)
+	rows, err := lookup.GetRows(1000)
+	require.NoError(t, err)
+	require.Equal(t, 2, rows.RowCount())
+	require.Equal(t, []*ScanRange{
+		{LowVals: []interface{}{int64(10), "uk"}, HighVals: []interface{}{int64(10), "uk"}},
+		{LowVals: []interface{}{int64(20), "us"}, HighVals: []interface{}{int64(20), "us"}},
+	}, scanRanges)
+}
+
+func TestRemoteExecutorLookup(t *testing.T) {
+	tc := &testCluster{allShardIds: []uint64{1, 2, 3}}
+	tc.rowsByShardOrig = map[uint64]*common.Rows{
+		1: toRows(t, customerRows[:1], customerColTypes),
+		2: toRows(t, customerRows[1:], customerColTypes),
+		3: toRows(t, nil, customerColTypes),
+	}
+	tc.reset()
+	queryInfo := &cluster.QueryExecutionInfo{ExecutionID: "1-abc"}
+	re := NewRemoteExecutor(nil, queryInfo, []string{"customer_id", "name"}, customerColTypes, "test-schema", tc, -1)
+	re.SetIndex(1)
+	lookup := re.Lookup(3, map[uint64][]byte{1: []byte("keys1"), 2: []byte("keys2")})
+	require.Equal(t, []uint64{1, 2}, lookup.ShardIDs)
+	for i, getter := range lookup.clusterGetters {
+		require.Equal(t, uint32(1), getter.queryExecInfo.RemoteExecutorIndex)
+		require.Equal(t, lookup.ShardIDs[i], getter.queryExecInfo.ShardID)
+		require.Equal(t, []byte("keys"+string(rune('0'+lookup.ShardIDs[i]))), getter.queryExecInfo.LookupKeys)
+	}
+	require.Equal(t, "1-abc-1-3-2", lookup.clusterGetters[1].queryExecInfo.ExecutionID)
+	rows, err := lookup.GetRows(1000)
+	require.NoError(t, err)
+	require.Equal(t, len(customerRows), rows.RowCount())
+	// The executor's own queries are not changed by the lookup
+	require.Equal(t, 3, len(re.clusterGetters))
+	require.Nil(t, re.clusterGetters[0].queryExecInfo.LookupKeys)
+}
+
+func setupHashJoin(t *testing.T, joinType JoinType, leftJoinCols []int, rightJoinCols []int) *PullHashJoin {
+	t.Helper()
+	left := &JoinSide{ColTypes: paymentColTypes, JoinCols: leftJoinCols}
+	right := &JoinSide{ColTypes: customerColTypes, JoinCols: rightJoinCols}
+	join, err := NewPullHashJoin(joinColNames, joinColTypes, joinType, left, right, nil, joinChildCols)
+	require.NoError(t, err)
+	join.AddChild(newRowProvider(t, paymentRows, paymentColTypes))
+	join.AddChild(newRowProvider(t, customerRows, customerColTypes))
+	return join
+}
+
+func requireJoinRows(t *testing.T, join PullExecutor, expected [][]interface{}) {
+	t.Helper()
+	rows, err := join.GetRows(1000)
+	require.NoError(t, err)
+	require.Equal(t, len(expected), rows.RowCount())
+	commontest.AllRowsEqual(t, toRows(t, expected, joinColTypes), rows, joinColTypes)
+}
@@ -38,11 +38,10 @@ func (l *PullLimit) GetRows(maxRowsToReturn int) (*common.Rows, error) {
 	if l.offset != 0 {
 		return nil, errors.NewInvalidStatementError("offset must be zero")
 	}
-	// Because LIMIT is often used together with ORDER BY which is limited to orderByMaxRows rows,
-	// we impose the same max on LIMIT.
-	if l.count > orderByMaxRows {
+	// The rows of the LIMIT are held in memory, so we impose a max on the count
+	if l.count > limitMaxRows {
 		return nil, errors.NewInvalidStatementError(
-			fmt.Sprintf("limit count cannot be larger than %d", orderByMaxRows),
+			fmt.Sprintf("limit count cannot be larger than %d", limitMaxRows),
 		)
 	}
 	if l.count == 0 {
@@ -0,0 +1,259 @@
+package exec
+
+import (
+	"github.com/squareup/pranadb/cluster"
+	"github.com/squareup/pranadb/common"
+	"github.com/squareup/pranadb/errors"
+	"github.com/squareup/pranadb/sharder"
+)
+
+const lookupBatchSize = 1000
+
+// PullIndexLookupJoin joins the rows of its outer child with the rows of a table which have the same primary key, or
+// the same values for a prefix of the columns of an index. The rows of the outer child are read in batches, and the
+// matching table rows for each batch are fetched from the shards by sending them the join keys of the batch.
+type PullIndexLookupJoin struct {
+	pullExecutorBase
+	joiner *joiner
+	// The columns of the outer child which make up the lookup key, in the order of the primary key or index columns
+	lookupCols     []int
+	lookupKeyTypes []common.ColumnType
+	keysFactory    *common.RowsFactory
+	shardByKey     bool // If true each key is only sent to the shard which owns it, otherwise to all shards
+	sharder        *sharder.Sharder
+	allShardIDs    []uint64
+	lookups        int
+	moreOuterRows  bool
+	availRows      *common.Rows
+}
+
+var _ PullExecutor = &PullIndexLookupJoin{}
+
+// NewPullIndexLookupJoin creates a join whose children are the outer side and a RemoteExecutor for the table on the
+// inner side, in the order of the sides of the join. The remote executor is only used to make lookups.
+func NewPullIndexLookupJoin(colNames []string, colTypes []common.ColumnType, joinType JoinType, left *JoinSide,
+	right *JoinSide, otherConds []*common.Expression, childCols []int, outerLeft bool, lookupCols []int,
+	lookupKeyTypes []common.ColumnType, shardByKey bool, sharder *sharder.Sharder,
+	allShardIDs []uint64) (*PullIndexLookupJoin, error) {
+	joiner, err := newJoiner(joinType, left, right, otherConds, childCols, outerLeft)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	rf := common.NewRowsFactory(colTypes)
+	base := pullExecutorBase{
+		colNames:    colNames,
+		colTypes:    colTypes,
+		rowsFactory: rf,
+	}
+	return &PullIndexLookupJoin{
+		pullExecutorBase: base,
+		joiner:           joiner,
+		lookupCols:       lookupCols,
+		lookupKeyTypes:   lookupKeyTypes,
+		keysFactory:      common.NewRowsFactory(lookupKeyTypes),
+		shardByKey:       shardByKey,
+		sharder:          sharder,
+		allShardIDs:      allShardIDs,
+		moreOuterRows:    true,
+	}, nil
+}
+
+func (p *PullIndexLookupJoin) GetRows(limit int) (*common.Rows, error) {
+	if limit < 1 {
+		return nil, errors.Errorf("invalid limit %d", limit)
+	}
+	outerChild, innerChild := p.children[0], p.children[1]
+	if !p.joiner.probeLeft {
+		outerChild, innerChild = innerChild, outerChild
+	}
+	inner, ok := innerChild.(*RemoteExecutor)
+	if !ok {
+		return nil, errors.Errorf("inner side of index lookup join must be a remote executor, is %T", innerChild)
+	}
+	if p.availRows == nil {
+		p.availRows = p.rowsFactory.NewRows(limit)
+	}
+	for p.moreOuterRows && p.availRows.RowCount() < limit {
+		rows, err := outerChild.GetRows(lookupBatchSize)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		if err := p.joinBatch(rows, inner); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		if rows.RowCount() < lookupBatchSize {
+			p.moreOuterRows = false
+		}
+	}
+	var rows *common.Rows
+	rows, p.availRows = takeRows(p.rowsFactory, p.availRows, limit)
+	return rows, nil
+}
+
+// joinBatch looks up the table rows which match a batch of rows from the outer side, and joins them
+func (p *PullIndexLookupJoin) joinBatch(outerRows *common.Rows, inner *RemoteExecutor) error {
+	outerSide, _ := p.joiner.sides()
+	keys := map[uint64]*common.Rows{}
+	seen := map[string]struct{}{}
+	for i := 0; i < outerRows.RowCount(); i++ {
+		row := outerRows.GetRow(i)
+		joinKey, err := joinKey(outerSide, &row)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		if joinKey == nil {
+			continue
+		}
+		if _, ok := seen[string(joinKey)]; ok {
+			continue
+		}
+		seen[string(joinKey)] = struct{}{}
+		shardID, err := p.lookupShard(&row)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		shardKeys, ok := keys[shardID]
+		if !ok {
+			shardKeys = p.keysFactory.NewRows(outerRows.RowCount())
+			keys[shardID] = shardKeys
+		}
+		for j, lookupCol := range p.lookupCols {
+			if err := appendCol(&row, lookupCol, p.lookupKeyTypes[j], j, shardKeys); err != nil {
+				return errors.WithStack(err)
+			}
+		}
+	}
+	if len(keys) > 0 {
+		serializedKeys := map[uint64][]byte{}
+		for shardID, shardKeys := range keys {
+			serializedKeys[shardID] = shardKeys.Serialize()
+		}
+		if !p.shardByKey {
+			// The keys were all added under shard 0, and they are sent to every shard
+			for _, shardID := range p.allShardIDs {
+				serializedKeys[shardID] = serializedKeys[0]
+			}
+			delete(serializedKeys, 0)
+		}
+		lookup := inner.Lookup(p.lookups, serializedKeys)
+		p.lookups++
+		for {
+			rows, err := lookup.GetRows(queryBatchSize)
+			if err != nil {
+				return errors.WithStack(err)
+			}
+			if err := p.joiner.addBuildRows(rows); err != nil {
+				return errors.WithStack(err)
+			}
+			if rows.RowCount() < queryBatchSize {
+				break
+			}
+		}
+	}
+	for i := 0; i < outerRows.RowCount(); i++ {
+		row := outerRows.GetRow(i)
+		if err := p.joiner.probe(&row, p.availRows); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	p.joiner.clear()
+	return nil
+}
+
+// lookupShard returns the shard which owns the table row with the lookup key of the outer row
+func (p *PullIndexLookupJoin) lookupShard(row *common.Row) (uint64, error) {
+	if !p.shardByKey {
+		return 0, nil
+	}
+	var key []byte
+	for i, lookupCol := range p.lookupCols {
+		var err error
+		key, err = common.EncodeKeyElement(colValue(row, lookupCol, p.lookupKeyTypes[i]), p.lookupKeyTypes[i], key)
+		if err != nil {
+			return 0, errors.WithStack(err)
+		}
+	}
+	return p.sharder.CalculateShard(sharder.ShardTypeHash, key)
+}
+
+// PullLookup reads the rows of a table with the keys sent with the query by an index lookup join. The keys are values
+// of the primary key, or of a prefix of the columns of an index.
+type PullLookup struct {
+	pullExecutorBase
+	queryInfo   *cluster.QueryExecutionInfo
+	keysFactory *common.RowsFactory
+	keyTypes    []common.ColumnType
+	createScan  func(scanRanges []*ScanRange) (PullExecutor, error)
+	scan        PullExecutor
+}
+
+var _ PullExecutor = &PullLookup{}
+
+// NewPullLookup creates a lookup which reads the keys from the query info when it is first executed. The keys are
+// converted into point ranges, and createScan creates the scan of the table or index for them.
+func NewPullLookup(colNames []string, colTypes []common.ColumnType, queryInfo *cluster.QueryExecutionInfo,
+	keyTypes []common.ColumnType, createScan func(scanRanges []*ScanRange) (PullExecutor, error)) *PullLookup {
+	rf := common.NewRowsFactory(colTypes)
+	base := pullExecutorBase{
+		colNames:    colNames,
+		colTypes:    colTypes,
+		rowsFactory: rf,
+	}
+	return &PullLookup{
+		pullExecutorBase: base,
+		queryInfo:        queryInfo,
+		keysFactory:      common.NewRowsFactory(keyTypes),
+		keyTypes:         keyTypes,
+		createScan:       createScan,
+	}
+}
+
+func (p *PullLookup) GetRows(limit int) (*common.Rows, error) {
+	if limit < 1 {
+		return nil, errors.Errorf("invalid limit %d", limit)
+	}
+	if p.scan == nil {
+		keys := p.keysFactory.NewRows(1)
+		if len(p.queryInfo.LookupKeys) > 0 {
+			keys.Deserialize(p.queryInfo.LookupKeys)
+		}
+		scanRanges := make([]*ScanRange, keys.RowCount())
+		for i := 0; i < keys.RowCount(); i++ {
+			row := keys.GetRow(i)
+			vals := make([]interface{}, len(p.keyTypes))
+			for j, keyType := range p.keyTypes {
+				vals[j] = colValue(&row, j, keyType)
+			}
+			scanRanges[i] = &ScanRange{LowVals: vals, HighVals: vals}
+		}
+		scan, err := p.createScan(scanRanges)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		p.scan = scan
+	}
+	return p.scan.GetRows(limit)
+}
+
+// colValue returns the value of a column of the row in the form used by scan ranges, or nil if it is null
+func colValue(row *common.Row, col int, colType common.ColumnType) interface{} {
+	if row.IsNull(col) {
+		return nil
+	}
+	switch colType.Type {
+	case common.TypeTinyInt, common.TypeInt, common.TypeBigInt, common.TypeBoolean:
+		return row.GetInt64(col)
+	case common.TypeDouble:
+		return row.GetFloat64(col)
+	case common.TypeVarchar, common.TypeVarbinary:
+		return row.GetString(col)
+	case common.TypeDecimal:
+		return row.GetDecimal(col)
+	case common.TypeTimestamp, common.TypeDate:
+		return row.GetTimestamp(col)
+	case common.TypeJSON:
+		return row.GetJSON(col)
+	default:
+		return nil
+	}
+}
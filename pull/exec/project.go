@@ -44,7 +44,7 @@ func (p *PullProjection) GetRows(limit int) (rows *common.Rows, err error) { //
 		for j, projColumn := range p.projColumns {
 			colType := p.colTypes[j]
 			switch colType.Type {
-			case common.TypeTinyInt, common.TypeInt, common.TypeBigInt:
+			case common.TypeTinyInt, common.TypeInt, common.TypeBigInt, common.TypeBoolean:
 				val, null, err := projColumn.EvalInt64(&row)
 				if err != nil {
 					return nil, errors.WithStack(err)
@@ -64,7 +64,7 @@ func (p *PullProjection) GetRows(limit int) (rows *common.Rows, err error) { //
 				} else {
 					result.AppendDecimalToColumn(j, val)
 				}
-			case common.TypeVarchar:
+			case common.TypeVarchar, common.TypeVarbinary:
 				val, null, err := projColumn.EvalString(&row)
 				if err != nil {
 					return nil, errors.WithStack(err)
@@ -84,7 +84,7 @@ func (p *PullProjection) GetRows(limit int) (rows *common.Rows, err error) { //
 				} else {
 					result.AppendFloat64ToColumn(j, val)
 				}
-			case common.TypeTimestamp:
+			case common.TypeTimestamp, common.TypeDate:
 				val, null, err := projColumn.EvalTimestamp(&row)
 				if err != nil {
 					return nil, errors.WithStack(err)
@@ -94,6 +94,16 @@ func (p *PullProjection) GetRows(limit int) (rows *common.Rows, err error) { //
 				} else {
 					result.AppendTimestampToColumn(j, val)
 				}
+			case common.TypeJSON:
+				val, null, err := projColumn.EvalJSON(&row)
+				if err != nil {
+					return nil, errors.WithStack(err)
+				}
+				if null {
+					result.AppendNullToColumn(j)
+				} else {
+					result.AppendJSONToColumn(j, val)
+				}
 			default:
 				return nil, errors.Errorf("unexpected column type %d", colType)
 			}
@@ -3,9 +3,12 @@ package exec
 import (
 	"fmt"
 	"github.com/squareup/pranadb/meta"
+	"sort"
 	"strings"
 	"sync/atomic"
+	"time"
 
+	log "github.com/sirupsen/logrus"
 	"github.com/squareup/pranadb/errors"
 
 	"github.com/squareup/pranadb/cluster"
@@ -22,6 +25,11 @@ type RemoteExecutor struct {
 	RemoteDag         PullExecutor
 	ShardIDs          []uint64
 	pointGetQueryInfo *cluster.QueryExecutionInfo
+	index             uint32
+	order             *sortOrder
+	merger            *sortMerger
+	fetchSize         int
+	shardStats        *shardStats // Only set if the query is analyzed
 }
 
 func NewRemoteExecutor(remoteDAG PullExecutor, queryInfo *cluster.QueryExecutionInfo, colNames []string,
@@ -62,23 +70,76 @@ func NewRemoteExecutor(remoteDAG PullExecutor, queryInfo *cluster.QueryExecution
 	return &re
 }
 
+// SetIndex sets the position of the executor among the remote executors of the query. A query has a remote part for
+// each side of a join, and the index tells the remote node which one to execute.
+func (re *RemoteExecutor) SetIndex(index uint32) {
+	re.index = index
+	for _, getter := range re.clusterGetters {
+		getter.queryExecInfo.RemoteExecutorIndex = index
+	}
+	if re.pointGetQueryInfo != nil {
+		re.pointGetQueryInfo.RemoteExecutorIndex = index
+	}
+}
+
+// Lookup returns an executor which executes the remote part of the query on each shard in the map, sending it the
+// serialized rows of keys to look up. Each lookup made by the executor must have a different id.
+func (re *RemoteExecutor) Lookup(lookupID int, keys map[uint64][]byte) *RemoteExecutor {
+	queryInfo := *re.queryInfo
+	queryInfo.ExecutionID = fmt.Sprintf("%s-%d-%d", re.queryInfo.ExecutionID, re.index, lookupID)
+	queryInfo.RemoteExecutorIndex = re.index
+	lookup := &RemoteExecutor{
+		pullExecutorBase: pullExecutorBase{
+			colNames:    re.colNames,
+			colTypes:    re.colTypes,
+			rowsFactory: re.rowsFactory,
+		},
+		schemaName: re.schemaName,
+		cluster:    re.cluster,
+		queryInfo:  &queryInfo,
+		RemoteDag:  re.RemoteDag,
+		index:      re.index,
+		shardStats: re.shardStats,
+	}
+	for shardID := range keys {
+		lookup.ShardIDs = append(lookup.ShardIDs, shardID)
+	}
+	sort.Slice(lookup.ShardIDs, func(i, j int) bool { return lookup.ShardIDs[i] < lookup.ShardIDs[j] })
+	lookup.createGetters()
+	for _, getter := range lookup.clusterGetters {
+		getter.queryExecInfo.LookupKeys = keys[getter.shardID]
+	}
+	return lookup
+}
+
+// MergeSorted makes the executor merge the rows from the shards in the order of the sort by expressions, rather than
+// returning them in whatever order they arrive. The remote part of the query must return the rows of each shard in
+// that order.
+func (re *RemoteExecutor) MergeSorted(desc []bool, sortByExprs []*common.Expression) {
+	re.order = newSortOrder(re.colTypes, desc, sortByExprs)
+}
+
 type clusterGetter struct {
 	shardID       uint64
 	re            *RemoteExecutor
 	complete      atomic.Value
+	open          atomic.Value // Whether the remote node holds the query open as it has more rows to return
 	queryExecInfo *cluster.QueryExecutionInfo
 }
 
 func (c *clusterGetter) GetRows(limit int) (resultChan chan cluster.RemoteQueryResult) {
-	ch := make(chan cluster.RemoteQueryResult)
+	// The channel is buffered so the goroutine doesn't leak if the result is never received
+	ch := make(chan cluster.RemoteQueryResult, 1)
 	go func() {
 		var rows *common.Rows
 		var err error
 		c.queryExecInfo.Limit = uint32(limit)
-		rows, err = c.re.cluster.ExecuteRemotePullQuery(c.queryExecInfo, c.re.rowsFactory)
+		rows, err = c.re.executeRemotePullQuery(c.queryExecInfo)
 		if err == nil {
 			c.complete.Store(rows.RowCount() < limit)
 		}
+		// The remote node closes the query itself when it completes or fails
+		c.open.Store(err == nil && rows.RowCount() == limit)
 		ch <- cluster.RemoteQueryResult{
 			Rows: rows,
 			Err:  err,
@@ -87,6 +148,11 @@ func (c *clusterGetter) GetRows(limit int) (resultChan chan cluster.RemoteQueryR
 	return ch
 }
 
+func (c *clusterGetter) isOpen() bool {
+	open, ok := c.open.Load().(bool)
+	return ok && open
+}
+
 func (c *clusterGetter) isComplete() bool {
 	complete, ok := c.complete.Load().(bool)
 	if !ok {
@@ -103,7 +169,11 @@ func (re *RemoteExecutor) GetRows(limit int) (rows *common.Rows, err error) {
 	if re.pointGetQueryInfo != nil {
 		// It's a point get so we only talk to one shard
 		re.pointGetQueryInfo.Limit = uint32(limit)
-		return re.cluster.ExecuteRemotePullQuery(re.pointGetQueryInfo, re.rowsFactory)
+		return re.executeRemotePullQuery(re.pointGetQueryInfo)
+	}
+
+	if re.order != nil {
+		return re.getMergedRows(limit)
 	}
 
 	numGetters := len(re.clusterGetters)
@@ -163,6 +233,83 @@ func (re *RemoteExecutor) GetRows(limit int) (rows *common.Rows, err error) {
 	return rows, nil
 }
 
+// Close closes the remote part of the query on the nodes which are holding it open, as they have rows which haven't
+// been fetched yet
+func (re *RemoteExecutor) Close() {
+	for _, getter := range re.clusterGetters {
+		if !getter.isOpen() {
+			continue
+		}
+		queryInfo := *getter.queryExecInfo
+		queryInfo.Close = true
+		if _, err := re.cluster.ExecuteRemotePullQuery(&queryInfo, re.rowsFactory); err != nil {
+			log.Warnf("failed to close remote query %s on shard %d %v", queryInfo.ExecutionID, getter.shardID, err)
+		}
+		getter.open.Store(false)
+	}
+}
+
+func (re *RemoteExecutor) executeRemotePullQuery(queryInfo *cluster.QueryExecutionInfo) (*common.Rows, error) {
+	if re.shardStats == nil {
+		return re.cluster.ExecuteRemotePullQuery(queryInfo, re.rowsFactory)
+	}
+	start := time.Now()
+	rows, err := re.cluster.ExecuteRemotePullQuery(queryInfo, re.rowsFactory)
+	re.shardStats.add(queryInfo.ShardID, rows, time.Since(start))
+	return rows, err
+}
+
+// getMergedRows returns the next rows of a k-way merge of the sorted rows from the shards. Only the current batch of
+// rows from each shard is held in memory.
+func (re *RemoteExecutor) getMergedRows(limit int) (*common.Rows, error) {
+	// Any further rows are fetched from each shard in batches of the size most recently asked for
+	re.fetchSize = limit
+	if re.merger == nil {
+		runs := make([]sortRun, len(re.clusterGetters))
+		for i, getter := range re.clusterGetters {
+			run := &shardRun{getter: getter, re: re}
+			// We fetch the first batch from all the shards in parallel
+			run.fetch()
+			runs[i] = run
+		}
+		merger, err := newSortMerger(runs, re.order.compareRows)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		re.merger = merger
+	}
+	return re.merger.getRows(re.rowsFactory, limit)
+}
+
+// shardRun is the run of sorted rows from one shard
+type shardRun struct {
+	getter  *clusterGetter
+	re      *RemoteExecutor
+	pending chan cluster.RemoteQueryResult
+}
+
+func (s *shardRun) fetch() {
+	s.pending = s.getter.GetRows(s.re.fetchSize)
+}
+
+func (s *shardRun) nextBlock() (*common.Rows, error) {
+	if s.pending == nil {
+		if s.getter.isComplete() {
+			return nil, nil
+		}
+		s.fetch()
+	}
+	res := <-s.pending
+	s.pending = nil
+	if res.Err != nil {
+		return nil, res.Err
+	}
+	return res.Rows, nil
+}
+
+func (s *shardRun) close() {
+}
+
 func (re *RemoteExecutor) createGetters() {
 	shardIDs := re.ShardIDs
 	re.clusterGetters = make([]*clusterGetter, len(shardIDs))
@@ -91,6 +91,55 @@ func TestRemoteExecutorGetInBatches(t *testing.T) {
 	}
 }
 
+func TestRemoteExecutorMergeSortedAsc(t *testing.T) {
+	testRemoteExecutorMergeSorted(t, false)
+}
+
+func TestRemoteExecutorMergeSortedDesc(t *testing.T) {
+	testRemoteExecutorMergeSorted(t, true)
+}
+
+func testRemoteExecutorMergeSorted(t *testing.T, desc bool) {
+	t.Helper()
+	numRows := 100
+	rf := common.NewRowsFactory(colTypes)
+	pe, _, tc := setupRowExecutor(t, numRows, rf)
+	if desc {
+		// The rows of each shard must be returned in sort order
+		for shardID, rows := range tc.rowsByShardOrig {
+			reversed := rf.NewRows(rows.RowCount())
+			for i := rows.RowCount() - 1; i >= 0; i-- {
+				reversed.AppendRow(rows.GetRow(i))
+			}
+			tc.rowsByShardOrig[shardID] = reversed
+		}
+		tc.reset()
+	}
+	re, ok := pe.(*RemoteExecutor)
+	require.True(t, ok)
+	re.MergeSorted([]bool{desc}, []*common.Expression{common.NewColumnExpression(0, colTypes[0])})
+
+	batchSize := 7
+	allReceived := rf.NewRows(numRows)
+	for {
+		provided, err := re.GetRows(batchSize)
+		require.NoError(t, err)
+		allReceived.AppendAll(provided)
+		if provided.RowCount() < batchSize {
+			break
+		}
+	}
+	require.Equal(t, numRows, allReceived.RowCount())
+	for i := 0; i < numRows; i++ {
+		row := allReceived.GetRow(i)
+		expected := int64(i)
+		if desc {
+			expected = int64(numRows - 1 - i)
+		}
+		require.Equal(t, expected, row.GetInt64(0))
+	}
+}
+
 func TestRemoteExecutorSystemTablesTableDoesNotFanout(t *testing.T) {
 	allShardsIds := make([]uint64, 10)
 	for i := 0; i < 10; i++ {
@@ -2,27 +2,45 @@ package exec
 
 import (
 	"fmt"
+	"os"
 	"sort"
 	"strings"
+	"sync"
 
 	"github.com/cznic/mathutil"
+	log "github.com/sirupsen/logrus"
 	"github.com/squareup/pranadb/errors"
 
 	"github.com/squareup/pranadb/common"
 )
 
-// PullSort - a simple in memory sort executor
-// TODO this won't work for large resultsets - we need to store in chunks on disk and use a multi-way merge sort in
-// that case
+// SortSpillConfig determines when and where a sort spills rows to disk
+type SortSpillConfig struct {
+	Dir          string // Each sort which spills creates its own temporary directory under this directory
+	MemoryBudget int64  // The approximate number of bytes of rows a sort holds in memory before spilling them to disk
+}
+
+// PullSort sorts the rows of its child. The rows are sorted in memory unless they don't fit in the memory budget. In
+// that case each chunk of rows which fits in the budget is sorted and written to a run file on local disk, and the
+// runs are then merged as the rows are fetched.
 type PullSort struct {
 	pullExecutorBase
-	sortByExpressions []*common.Expression
-	rows              *common.Rows
-	descending        []bool
-	rowIndex          int
+	order     *sortOrder
+	spillConf *SortSpillConfig
+	loaded    bool
+	rows      *common.Rows
+	rowIndex  int
+	spillDir  string
+	runs      []sortRun
+	merger    *sortMerger
+	// The sort can be closed while it's being read, e.g. if the query is cancelled, so the lock protects the runs
+	lock   sync.Mutex
+	closed bool
 }
 
-func NewPullSort(colNames []string, colTypes []common.ColumnType, desc []bool, sortByExpressions []*common.Expression) *PullSort {
+// NewPullSort creates a sort. If spillConf is nil all rows are sorted in memory.
+func NewPullSort(colNames []string, colTypes []common.ColumnType, desc []bool, sortByExpressions []*common.Expression,
+	spillConf *SortSpillConfig) *PullSort {
 	rf := common.NewRowsFactory(colTypes)
 	base := pullExecutorBase{
 		colNames:    colNames,
@@ -30,48 +48,40 @@ func NewPullSort(colNames []string, colTypes []common.ColumnType, desc []bool, s
 		rowsFactory: rf,
 	}
 	return &PullSort{
-		pullExecutorBase:  base,
-		sortByExpressions: sortByExpressions,
-		descending:        desc,
+		pullExecutorBase: base,
+		order:            newSortOrder(colTypes, desc, sortByExpressions),
+		spillConf:        spillConf,
 	}
 }
 
-func (p *PullSort) GetRows(limit int) (*common.Rows, error) { //nolint: gocyclo
+func (p *PullSort) GetRows(limit int) (*common.Rows, error) {
 	if limit < 1 {
 		return nil, errors.Errorf("invalid limit %d", limit)
 	}
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.closed {
+		return nil, errors.Error("sort has been closed")
+	}
 
-	if p.rows == nil {
-		unsorted := p.rowsFactory.NewRows(queryBatchSize)
-		for {
-			// We call getRows on the child until there are no more rows to get
-			batch, err := p.GetChildren()[0].GetRows(queryBatchSize)
-			if err != nil {
-				return nil, errors.WithStack(err)
-			}
-			rc := batch.RowCount()
-			if rc == 0 {
-				break
-			}
-			if unsorted.RowCount()+rc > orderByMaxRows {
-				// TODO needs test
-				return nil, errors.Errorf("query with order by cannot return more than %d rows", orderByMaxRows)
-			}
-			unsorted.AppendAll(batch)
-			if rc < queryBatchSize {
-				break
-			}
-		}
-
-		if unsorted.RowCount() < 2 {
-			return unsorted, nil
+	if !p.loaded {
+		if err := p.load(); err != nil {
+			p.removeSpillDir()
+			return nil, errors.WithStack(err)
 		}
+		p.loaded = true
+	}
 
-		rows, err := p.sortRows(unsorted)
+	if p.merger != nil {
+		rows, err := p.merger.getRows(p.rowsFactory, limit)
 		if err != nil {
+			p.removeSpillDir()
 			return nil, errors.WithStack(err)
 		}
-		p.rows = rows
+		if rows.RowCount() < limit {
+			p.removeSpillDir()
+		}
+		return rows, nil
 	}
 
 	// TODO we should implement a Slice() method on rows so we don't need to copy
@@ -86,7 +96,87 @@ func (p *PullSort) GetRows(limit int) (*common.Rows, error) { //nolint: gocyclo
 	return res, nil
 }
 
-//nolint:gocyclo
+// load reads all the rows from the child. If any runs were spilled, the rows still in memory are added as a final run
+// and a merger is created for the runs. Otherwise the rows are sorted in memory.
+func (p *PullSort) load() error {
+	unsorted := p.rowsFactory.NewRows(queryBatchSize)
+	for {
+		// We call getRows on the child until there are no more rows to get
+		batch, err := p.GetChildren()[0].GetRows(queryBatchSize)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		unsorted.AppendAll(batch)
+		if p.spillConf != nil && unsorted.MemoryUsage() > p.spillConf.MemoryBudget {
+			if err := p.spill(unsorted); err != nil {
+				return errors.WithStack(err)
+			}
+			unsorted = p.rowsFactory.NewRows(queryBatchSize)
+		}
+		if batch.RowCount() < queryBatchSize {
+			break
+		}
+	}
+	sorted, err := p.sortRows(unsorted)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if len(p.runs) == 0 {
+		p.rows = sorted
+		return nil
+	}
+	runs := append(p.runs, &memoryRun{rows: sorted})
+	p.merger, err = newSortMerger(runs, p.order.compareRows)
+	return errors.WithStack(err)
+}
+
+// spill sorts the rows and writes them to a new run file
+func (p *PullSort) spill(unsorted *common.Rows) error {
+	sorted, err := p.sortRows(unsorted)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if p.spillDir == "" {
+		if err := os.MkdirAll(p.spillConf.Dir, 0700); err != nil {
+			return errors.WithStack(err)
+		}
+		p.spillDir, err = os.MkdirTemp(p.spillConf.Dir, "sort-")
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	run, err := writeFileRun(p.spillDir, len(p.runs), sorted, p.rowsFactory)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	p.runs = append(p.runs, run)
+	return nil
+}
+
+// Close removes any rows the sort has spilled to disk. It's called when the query is closed, which can be before all
+// the rows have been fetched.
+func (p *PullSort) Close() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.closed = true
+	p.removeSpillDir()
+}
+
+// removeSpillDir closes the run files and deletes the directory they are in
+func (p *PullSort) removeSpillDir() {
+	if p.spillDir == "" {
+		return
+	}
+	for _, run := range p.runs {
+		run.close()
+	}
+	if err := os.RemoveAll(p.spillDir); err != nil {
+		log.Warnf("failed to remove sort spill directory %s %v", p.spillDir, err)
+	}
+	p.spillDir = ""
+	p.runs = nil
+}
+
 func (p *PullSort) sortRows(unsorted *common.Rows) (*common.Rows, error) {
 	numRows := unsorted.RowCount()
 	indexes := make([]int, numRows)
@@ -100,150 +190,9 @@ func (p *PullSort) sortRows(unsorted *common.Rows) (*common.Rows, error) {
 		}
 		row1 := unsorted.GetRow(indexes[i])
 		row2 := unsorted.GetRow(indexes[j])
-		for sortColIndex, sortbyExpr := range p.sortByExpressions {
-			descending := p.descending[sortColIndex]
-			colType, errType := sortbyExpr.ReturnType(p.colTypes)
-			if errType != nil {
-				err = errType
-				return false
-			}
-			switch colType.Type {
-			case common.TypeTinyInt, common.TypeInt, common.TypeBigInt:
-				val1, null1, err1 := sortbyExpr.EvalInt64(&row1)
-				if err1 != nil {
-					err = err1
-					return false
-				}
-				val2, null2, err2 := sortbyExpr.EvalInt64(&row2)
-				if err2 != nil {
-					err = err2
-					return false
-				}
-				if null1 && !null2 {
-					return !descending
-				}
-				if null2 && !null1 {
-					return descending
-				}
-				if !null1 && !null2 {
-					if val1 < val2 {
-						return !descending
-					}
-					if val2 < val1 {
-						return descending
-					}
-				}
-			case common.TypeDouble:
-				val1, null1, err1 := sortbyExpr.EvalFloat64(&row1)
-				if err1 != nil {
-					err = err1
-					return false
-				}
-				val2, null2, err2 := sortbyExpr.EvalFloat64(&row2)
-				if err2 != nil {
-					err = err2
-					return false
-				}
-				if null1 && !null2 {
-					return !descending
-				}
-				if null2 && !null1 {
-					return descending
-				}
-				if !null1 && !null2 {
-					if val1 < val2 {
-						return !descending
-					}
-					if val2 < val1 {
-						return descending
-					}
-				}
-			case common.TypeDecimal:
-				val1, null1, err1 := sortbyExpr.EvalDecimal(&row1)
-				if err1 != nil {
-					err = err1
-					return false
-				}
-				val2, null2, err2 := sortbyExpr.EvalDecimal(&row2)
-				if err2 != nil {
-					err = err2
-					return false
-				}
-				if null1 && !null2 {
-					return !descending
-				}
-				if null2 && !null1 {
-					return descending
-				}
-				if !null1 && !null2 {
-					diff := val1.CompareTo(&val2)
-					if diff < 0 {
-						return !descending
-					}
-					if diff > 0 {
-						return descending
-					}
-				}
-			case common.TypeVarchar:
-				val1, null1, err1 := sortbyExpr.EvalString(&row1)
-				if err1 != nil {
-					err = err1
-					return false
-				}
-				val2, null2, err2 := sortbyExpr.EvalString(&row2)
-				if err2 != nil {
-					err = err2
-					return false
-				}
-				if null1 && !null2 {
-					return !descending
-				}
-				if null2 && !null1 {
-					return descending
-				}
-				if !null1 && !null2 {
-					diff := strings.Compare(val1, val2)
-					if diff < 0 {
-						return !descending
-					}
-					if diff > 0 {
-						return descending
-					}
-				}
-			case common.TypeTimestamp:
-				val1, null1, err1 := sortbyExpr.EvalTimestamp(&row1)
-				if err1 != nil {
-					err = err1
-					return false
-				}
-				val2, null2, err2 := sortbyExpr.EvalTimestamp(&row2)
-				if err2 != nil {
-					err = err2
-					return false
-				}
-				if null1 && !null2 {
-					return !descending
-				}
-				if null2 && !null1 {
-					return descending
-				}
-				if !null1 && !null2 {
-					diff := val1.Compare(val2)
-					if diff < 0 {
-						return !descending
-					}
-					if diff > 0 {
-						return descending
-					}
-				}
-			default:
-				panic(fmt.Sprintf("unexpected type %d", colType.Type))
-			}
-			if err != nil {
-				break
-			}
-		}
-		return false
+		var diff int
+		diff, err = p.order.compareRows(&row1, &row2)
+		return diff < 0
 	})
 
 	if err != nil {
@@ -256,3 +205,120 @@ func (p *PullSort) sortRows(unsorted *common.Rows) (*common.Rows, error) {
 	}
 	return rows, nil
 }
+
+// sortOrder is the order of an ORDER BY, which is given by the values of the sort by expressions
+type sortOrder struct {
+	colTypes          []common.ColumnType
+	sortByExpressions []*common.Expression
+	descending        []bool
+}
+
+func newSortOrder(colTypes []common.ColumnType, desc []bool, sortByExpressions []*common.Expression) *sortOrder {
+	return &sortOrder{
+		colTypes:          colTypes,
+		sortByExpressions: sortByExpressions,
+		descending:        desc,
+	}
+}
+
+// compareRows returns a negative number if row1 sorts before row2, a positive number if it sorts after, and zero if
+// they have the same values for the sort by expressions. Nulls sort before other values.
+func (s *sortOrder) compareRows(row1 *common.Row, row2 *common.Row) (int, error) { //nolint: gocyclo
+	for sortColIndex, sortbyExpr := range s.sortByExpressions {
+		colType, err := sortbyExpr.ReturnType(s.colTypes)
+		if err != nil {
+			return 0, errors.WithStack(err)
+		}
+		var diff int
+		var null1, null2 bool
+		switch colType.Type {
+		case common.TypeTinyInt, common.TypeInt, common.TypeBigInt, common.TypeBoolean:
+			var val1, val2 int64
+			if val1, null1, err = sortbyExpr.EvalInt64(row1); err != nil {
+				return 0, errors.WithStack(err)
+			}
+			if val2, null2, err = sortbyExpr.EvalInt64(row2); err != nil {
+				return 0, errors.WithStack(err)
+			}
+			if val1 < val2 {
+				diff = -1
+			} else if val1 > val2 {
+				diff = 1
+			}
+		case common.TypeDouble:
+			var val1, val2 float64
+			if val1, null1, err = sortbyExpr.EvalFloat64(row1); err != nil {
+				return 0, errors.WithStack(err)
+			}
+			if val2, null2, err = sortbyExpr.EvalFloat64(row2); err != nil {
+				return 0, errors.WithStack(err)
+			}
+			if val1 < val2 {
+				diff = -1
+			} else if val1 > val2 {
+				diff = 1
+			}
+		case common.TypeDecimal:
+			var val1, val2 common.Decimal
+			if val1, null1, err = sortbyExpr.EvalDecimal(row1); err != nil {
+				return 0, errors.WithStack(err)
+			}
+			if val2, null2, err = sortbyExpr.EvalDecimal(row2); err != nil {
+				return 0, errors.WithStack(err)
+			}
+			if !null1 && !null2 {
+				diff = val1.CompareTo(&val2)
+			}
+		case common.TypeVarchar, common.TypeVarbinary:
+			var val1, val2 string
+			if val1, null1, err = sortbyExpr.EvalString(row1); err != nil {
+				return 0, errors.WithStack(err)
+			}
+			if val2, null2, err = sortbyExpr.EvalString(row2); err != nil {
+				return 0, errors.WithStack(err)
+			}
+			diff = strings.Compare(val1, val2)
+		case common.TypeTimestamp, common.TypeDate:
+			var val1, val2 common.Timestamp
+			if val1, null1, err = sortbyExpr.EvalTimestamp(row1); err != nil {
+				return 0, errors.WithStack(err)
+			}
+			if val2, null2, err = sortbyExpr.EvalTimestamp(row2); err != nil {
+				return 0, errors.WithStack(err)
+			}
+			if !null1 && !null2 {
+				diff = val1.Compare(val2)
+			}
+		case common.TypeJSON:
+			var val1, val2 common.JSON
+			if val1, null1, err = sortbyExpr.EvalJSON(row1); err != nil {
+				return 0, errors.WithStack(err)
+			}
+			if val2, null2, err = sortbyExpr.EvalJSON(row2); err != nil {
+				return 0, errors.WithStack(err)
+			}
+			if !null1 && !null2 {
+				diff = common.CompareJSON(val1, val2)
+			}
+		default:
+			panic(fmt.Sprintf("unexpected type %d", colType.Type))
+		}
+		if null1 || null2 {
+			switch {
+			case null1 && null2:
+				diff = 0
+			case null1:
+				diff = -1
+			default:
+				diff = 1
+			}
+		}
+		if s.descending[sortColIndex] {
+			diff = -diff
+		}
+		if diff != 0 {
+			return diff, nil
+		}
+	}
+	return 0, nil
+}
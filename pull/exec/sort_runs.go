@@ -0,0 +1,226 @@
+package exec
+
+import (
+	"bufio"
+	"container/heap"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/squareup/pranadb/common"
+	"github.com/squareup/pranadb/errors"
+)
+
+// The number of rows in each block of a run file. Only the current block of each run is held in memory while merging.
+const sortRunBlockRows = 1000
+
+// sortRun is a sequence of sorted rows, which is read a block at a time
+type sortRun interface {
+	// nextBlock returns the next block of rows, or nil if there are no more
+	nextBlock() (*common.Rows, error)
+	close()
+}
+
+// fileRun is a run which has been spilled to a file. The file is a sequence of blocks, each of which is a length
+// prefixed serialized Rows.
+type fileRun struct {
+	path        string
+	rowsFactory *common.RowsFactory
+	file        *os.File
+	reader      *bufio.Reader
+}
+
+func writeFileRun(dir string, runNum int, rows *common.Rows, rowsFactory *common.RowsFactory) (*fileRun, error) {
+	path := filepath.Join(dir, fmt.Sprintf("run-%d", runNum))
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	writer := bufio.NewWriter(file)
+	for start := 0; start < rows.RowCount(); start += sortRunBlockRows {
+		block := rowsFactory.NewRows(sortRunBlockRows)
+		for i := start; i < start+sortRunBlockRows && i < rows.RowCount(); i++ {
+			block.AppendRow(rows.GetRow(i))
+		}
+		buff := block.Serialize()
+		if _, err := writer.Write(common.AppendUint32ToBufferLE(nil, uint32(len(buff)))); err != nil {
+			_ = file.Close()
+			return nil, errors.WithStack(err)
+		}
+		if _, err := writer.Write(buff); err != nil {
+			_ = file.Close()
+			return nil, errors.WithStack(err)
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		_ = file.Close()
+		return nil, errors.WithStack(err)
+	}
+	if err := file.Close(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return &fileRun{path: path, rowsFactory: rowsFactory}, nil
+}
+
+func (f *fileRun) nextBlock() (*common.Rows, error) {
+	if f.file == nil {
+		file, err := os.Open(f.path)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		f.file = file
+		f.reader = bufio.NewReader(file)
+	}
+	lenBuff := make([]byte, 4)
+	if _, err := io.ReadFull(f.reader, lenBuff); err != nil {
+		if err == io.EOF {
+			return nil, nil
+		}
+		return nil, errors.WithStack(err)
+	}
+	blockLen, _ := common.ReadUint32FromBufferLE(lenBuff, 0)
+	buff := make([]byte, blockLen)
+	if _, err := io.ReadFull(f.reader, buff); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	block := f.rowsFactory.NewRows(sortRunBlockRows)
+	block.Deserialize(buff)
+	return block, nil
+}
+
+func (f *fileRun) close() {
+	if f.file != nil {
+		_ = f.file.Close()
+		f.file = nil
+	}
+}
+
+// memoryRun is a run of rows which are held in memory, and returned as a single block
+type memoryRun struct {
+	rows *common.Rows
+}
+
+func (m *memoryRun) nextBlock() (*common.Rows, error) {
+	rows := m.rows
+	m.rows = nil
+	return rows, nil
+}
+
+func (m *memoryRun) close() {
+}
+
+// runCursor is the position of the merge in a run
+type runCursor struct {
+	run    sortRun
+	runNum int
+	block  *common.Rows
+	pos    int
+}
+
+// sortMerger merges sorted runs. It is a heap of the cursors of the runs which still have rows, ordered by the row
+// at each cursor. Rows which compare equal are taken from the earlier run first, so the sort is stable.
+type sortMerger struct {
+	cursors []*runCursor
+	compare func(row1 *common.Row, row2 *common.Row) (int, error)
+	err     error
+}
+
+func newSortMerger(runs []sortRun, compare func(row1 *common.Row, row2 *common.Row) (int, error)) (*sortMerger, error) {
+	m := &sortMerger{compare: compare}
+	for i, run := range runs {
+		cursor := &runCursor{run: run, runNum: i}
+		ok, err := cursor.nextBlock()
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		if ok {
+			m.cursors = append(m.cursors, cursor)
+		}
+	}
+	heap.Init(m)
+	return m, errors.WithStack(m.err)
+}
+
+// getRows returns up to limit of the next rows in sort order
+func (m *sortMerger) getRows(rowsFactory *common.RowsFactory, limit int) (*common.Rows, error) {
+	rows := rowsFactory.NewRows(limit)
+	for rows.RowCount() < limit && len(m.cursors) > 0 {
+		cursor := m.cursors[0]
+		rows.AppendRow(cursor.block.GetRow(cursor.pos))
+		cursor.pos++
+		if cursor.pos == cursor.block.RowCount() {
+			ok, err := cursor.nextBlock()
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+			if !ok {
+				heap.Pop(m)
+				continue
+			}
+		}
+		heap.Fix(m, 0)
+		if m.err != nil {
+			return nil, errors.WithStack(m.err)
+		}
+	}
+	return rows, nil
+}
+
+// nextBlock moves the cursor to the start of the next non-empty block of the run, and returns false if there isn't one
+func (c *runCursor) nextBlock() (bool, error) {
+	for {
+		block, err := c.run.nextBlock()
+		if err != nil {
+			return false, errors.WithStack(err)
+		}
+		if block == nil {
+			return false, nil
+		}
+		if block.RowCount() > 0 {
+			c.block = block
+			c.pos = 0
+			return true, nil
+		}
+	}
+}
+
+func (m *sortMerger) Len() int {
+	return len(m.cursors)
+}
+
+func (m *sortMerger) Less(i, j int) bool {
+	if m.err != nil {
+		return false
+	}
+	cursor1, cursor2 := m.cursors[i], m.cursors[j]
+	row1 := cursor1.block.GetRow(cursor1.pos)
+	row2 := cursor2.block.GetRow(cursor2.pos)
+	diff, err := m.compare(&row1, &row2)
+	if err != nil {
+		m.err = err
+		return false
+	}
+	if diff == 0 {
+		return cursor1.runNum < cursor2.runNum
+	}
+	return diff < 0
+}
+
+func (m *sortMerger) Swap(i, j int) {
+	m.cursors[i], m.cursors[j] = m.cursors[j], m.cursors[i]
+}
+
+func (m *sortMerger) Push(x interface{}) {
+	cursor, ok := x.(*runCursor)
+	if !ok {
+		panic("not a run cursor")
+	}
+	m.cursors = append(m.cursors, cursor)
+}
+
+func (m *sortMerger) Pop() interface{} {
+	last := m.cursors[len(m.cursors)-1]
+	m.cursors = m.cursors[:len(m.cursors)-1]
+	return last
+}
@@ -1,10 +1,13 @@
 package exec
 
 import (
+	"fmt"
+	"os"
 	"testing"
 
 	"github.com/squareup/pranadb/common"
 	"github.com/squareup/pranadb/common/commontest"
+	"github.com/squareup/pranadb/execctx"
 	"github.com/stretchr/testify/require"
 )
 
@@ -511,6 +514,98 @@ func TestSortNonColExpression(t *testing.T) {
 	testSort(t, inpRows, expRows, []string{"a", "b", "c"}, []common.ColumnType{common.IntColumnType, common.IntColumnType, common.IntColumnType}, descending, f)
 }
 
+func TestSortSpillsToDisk(t *testing.T) {
+	// Enough rows for several batches from the child, with duplicate sort keys and nulls
+	var inpRows [][]interface{}
+	for i := 0; i < 25000; i++ {
+		var key interface{}
+		if i%97 != 0 {
+			key = (i * 7919) % 1000
+		}
+		inpRows = append(inpRows, []interface{}{i, key, fmt.Sprintf("str%d", i%13)})
+	}
+	colTypes := []common.ColumnType{common.BigIntColumnType, common.IntColumnType, common.VarcharColumnType}
+	colNames := []string{"a", "b", "c"}
+	descending := []bool{true, false}
+	sortByExprs := []*common.Expression{common.NewColumnExpression(2, colTypes[2]), common.NewColumnExpression(1, colTypes[1])}
+
+	inMemorySort := setupSort(t, inpRows, colNames, colTypes, descending, sortByExprs...)
+	expected, err := inMemorySort.GetRows(len(inpRows) + 1)
+	require.NoError(t, err)
+	require.Equal(t, len(inpRows), expected.RowCount())
+
+	spillDir := t.TempDir()
+	// A budget of one byte means every batch from the child is spilled
+	spillConf := &SortSpillConfig{Dir: spillDir, MemoryBudget: 1}
+	sort := NewPullSort(colNames, colTypes, descending, sortByExprs, spillConf)
+	sort.AddChild(newRowProvider(t, inpRows, colTypes))
+
+	actual := common.NewRows(colTypes, len(inpRows))
+	for {
+		rows, err := sort.GetRows(3000)
+		require.NoError(t, err)
+		actual.AppendAll(rows)
+		if rows.RowCount() < 3000 {
+			break
+		}
+		// The runs are kept on disk until all the rows have been fetched
+		require.Equal(t, 1, numEntries(t, spillDir))
+		require.Equal(t, 3, len(sort.runs))
+	}
+	commontest.AllRowsEqual(t, expected, actual, colTypes)
+	require.Equal(t, 0, numEntries(t, spillDir))
+}
+
+func TestSortSpillRemovedWhenQueryClosed(t *testing.T) {
+	var inpRows [][]interface{}
+	for i := 0; i < 10000; i++ {
+		inpRows = append(inpRows, []interface{}{i, i % 100, fmt.Sprintf("str%d", i)})
+	}
+	colTypes := []common.ColumnType{common.BigIntColumnType, common.IntColumnType, common.VarcharColumnType}
+	colNames := []string{"a", "b", "c"}
+	sortByExprs := []*common.Expression{common.NewColumnExpression(1, colTypes[1])}
+
+	spillDir := t.TempDir()
+	spillConf := &SortSpillConfig{Dir: spillDir, MemoryBudget: 1}
+	sort := NewPullSort(colNames, colTypes, []bool{false}, sortByExprs, spillConf)
+	sort.AddChild(newRowProvider(t, inpRows, colTypes))
+	execCtx := execctx.NewExecutionContext("", nil)
+	execCtx.AddCloser(sort.Close)
+
+	// Only the first page is fetched before the query is abandoned
+	rows, err := sort.GetRows(100)
+	require.NoError(t, err)
+	require.Equal(t, 100, rows.RowCount())
+	require.Equal(t, 1, numEntries(t, spillDir))
+
+	execCtx.Close()
+	require.Equal(t, 0, numEntries(t, spillDir))
+	_, err = sort.GetRows(100)
+	require.Error(t, err)
+}
+
+func TestSortDoesNotSpillWithinBudget(t *testing.T) {
+	spillDir := t.TempDir()
+	spillConf := &SortSpillConfig{Dir: spillDir, MemoryBudget: 1024 * 1024}
+	sort := NewPullSort(sortColNames, sortColTypes, []bool{false}, []*common.Expression{common.NewColumnExpression(0, sortColTypes[0])}, spillConf)
+	sort.AddChild(newRowProvider(t, [][]interface{}{
+		{2, 1, 10, 100, 10.01, "str2", "1000.01"},
+		{1, 2, 20, 200, 20.01, "str1", "2000.01"},
+	}, sortColTypes))
+	rows, err := sort.GetRows(1000)
+	require.NoError(t, err)
+	require.Equal(t, 2, rows.RowCount())
+	require.Equal(t, 0, numEntries(t, spillDir))
+	require.Nil(t, sort.merger)
+}
+
+func numEntries(t *testing.T, dir string) int {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	return len(entries)
+}
+
 func testSort(t *testing.T, inpRows [][]interface{}, expRows [][]interface{}, sortColNames []string, sortColTypes []common.ColumnType, descending []bool, sortByExprs ...*common.Expression) {
 	t.Helper()
 	sort := setupSort(t, inpRows, sortColNames, sortColTypes, descending, sortByExprs...)
@@ -524,7 +619,7 @@ func testSort(t *testing.T, inpRows [][]interface{}, expRows [][]interface{}, so
 func setupSort(t *testing.T, inputRows [][]interface{}, colNames []string, colTypes []common.ColumnType, descending []bool, sortByExprs ...*common.Expression) PullExecutor {
 	t.Helper()
 
-	sort := NewPullSort(colNames, colTypes, descending, sortByExprs)
+	sort := NewPullSort(colNames, colTypes, descending, sortByExprs, nil)
 	inpRows := toRows(t, inputRows, colTypes)
 	rf := common.NewRowsFactory(colTypes)
 	rowsProvider := rowProvider{
@@ -1,11 +1,11 @@
 package pull
 
 import (
-	"fmt"
 	"github.com/squareup/pranadb/tidb/sessionctx"
 	"strings"
 
 	"github.com/pingcap/parser/model"
+	"github.com/squareup/pranadb/aggfuncs"
 	"github.com/squareup/pranadb/common"
 	"github.com/squareup/pranadb/errors"
 	"github.com/squareup/pranadb/execctx"
@@ -27,19 +27,23 @@ func (p *Engine) buildPullDAGWithOutputNames(ctx *execctx.ExecutionContext, logi
 		colNames = append(colNames, colName.ColName.L)
 	}
 	dag.SetColNames(colNames)
+	for i, remoteExecutor := range findRemoteExecutors(dag, nil) {
+		remoteExecutor.SetIndex(uint32(i))
+		// Closing the query closes the remote parts which haven't returned all their rows
+		ctx.AddCloser(remoteExecutor.Close)
+	}
 	return dag, nil
 }
 
 // nolint: gocyclo
 func (p *Engine) buildPullDAG(ctx *execctx.ExecutionContext, plan planner.PhysicalPlan, remote bool) (exec.PullExecutor, error) {
-	cols := plan.Schema().Columns
-	colTypes := make([]common.ColumnType, 0, len(cols))
-	colNames := make([]string, 0, len(cols))
-	for _, col := range cols {
-		colType := col.GetType()
-		pranaType := common.ConvertTiDBTypeToPranaType(colType)
-		colTypes = append(colTypes, pranaType)
-		colNames = append(colNames, col.OrigName)
+	colNames, colTypes := planColumns(plan)
+	if !remote {
+		if scan := pushDownScan(plan); scan != nil {
+			// The scan, and any selections and projections above it, are executed on the shards, so only the matching
+			// rows and the needed columns are sent back to this node
+			return p.buildRemoteExecutor(ctx, plan, scan, colNames, colTypes)
+		}
 	}
 	var executor exec.PullExecutor
 	var err error
@@ -60,59 +64,67 @@ func (p *Engine) buildPullDAG(ctx *execctx.ExecutionContext, plan planner.Physic
 		}
 		executor = exec.NewPullSelect(colNames, colTypes, exprs)
 	case *planner.PhysicalTableScan:
-		if remote {
-			tableName := op.Table.Name.L
+		tableName := op.Table.Name.L
+		executor, err = p.createPullTableScan(ctx.Schema, tableName, op.Ranges, op.Columns, ctx.QueryInfo.ShardID)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+	case *planner.PhysicalIndexScan:
+		tableName := op.Table.Name.L
+		if op.Index.Primary {
+			// This is a fake index we created because the table has a composite PK and TiDB planner doesn't
+			// support this case well. Having a fake index allows the planner to create multiple ranges for fast
+			// scans and lookup for the composite PK case
 			executor, err = p.createPullTableScan(ctx.Schema, tableName, op.Ranges, op.Columns, ctx.QueryInfo.ShardID)
 			if err != nil {
 				return nil, errors.WithStack(err)
 			}
 		} else {
-			remoteDag, err := p.buildPullDAG(ctx, op, true)
+			indexName := op.Index.Name.L
+			executor, err = p.createPullIndexScan(ctx.Schema, tableName, indexName, op.Ranges, op.Columns, ctx.QueryInfo.ShardID)
 			if err != nil {
 				return nil, errors.WithStack(err)
 			}
-			pointGetShardID, err := p.getPointGetShardID(ctx, op.Ranges, op.Table.Name.L)
-			if err != nil {
-				return nil, err
-			}
-			executor = exec.NewRemoteExecutor(remoteDag, ctx.QueryInfo, colNames, colTypes, ctx.Schema.Name, p.cluster,
-				pointGetShardID)
-		}
-	case *planner.PhysicalIndexScan:
-		if remote {
-			tableName := op.Table.Name.L
-			if op.Index.Primary {
-				// This is a fake index we created because the table has a composite PK and TiDB planner doesn't
-				// support this case well. Having a fake index allows the planner to create multiple ranges for fast
-				// scans and lookup for the composite PK case
-				executor, err = p.createPullTableScan(ctx.Schema, tableName, op.Ranges, op.Columns, ctx.QueryInfo.ShardID)
-				if err != nil {
-					return nil, errors.WithStack(err)
-				}
-			} else {
-				indexName := op.Index.Name.L
-				executor, err = p.createPullIndexScan(ctx.Schema, tableName, indexName, op.Ranges, op.Columns, ctx.QueryInfo.ShardID)
-				if err != nil {
-					return nil, errors.WithStack(err)
-				}
-			}
-		} else {
-			remoteDag, err := p.buildPullDAG(ctx, op, true)
-			if err != nil {
-				return nil, err
-			}
-			executor = exec.NewRemoteExecutor(remoteDag, ctx.QueryInfo, colNames, colTypes, ctx.Schema.Name, p.cluster,
-				-1)
 		}
+	case *planner.PhysicalHashAgg:
+		// The aggregation consumes the remote part of the query itself, so its children are already connected
+		return p.buildPullAggregate(ctx, op, colNames, remote)
+	case *planner.PhysicalHashJoin:
+		// Like the aggregation, the join connects its own children, as each side has its own remote part
+		return p.buildPullJoin(ctx, op, colNames, colTypes, remote)
 	case *planner.PhysicalSort:
 		desc, sortByExprs := p.byItemsToDescAndSortExpression(op.ByItems, ctx.Planner().SessionContext())
-		executor = exec.NewPullSort(colNames, colTypes, desc, sortByExprs)
+		if scan := pushDownScan(op.Children()[0]); scan != nil && !remote {
+			// Each shard sorts its own rows, and the sorted rows from the shards are merged on this node
+			return p.buildMergingRemoteExecutor(ctx, op, scan, colNames, colTypes, desc, sortByExprs)
+		}
+		sort := exec.NewPullSort(colNames, colTypes, desc, sortByExprs, p.sortSpillConf)
+		// The sort may have spilled rows to disk which must be removed even if the query is abandoned
+		ctx.AddCloser(sort.Close)
+		executor = sort
 	case *planner.PhysicalLimit:
 		executor = exec.NewPullLimit(colNames, colTypes, op.Count, op.Offset)
 	case *planner.PhysicalTopN:
-		limit := exec.NewPullLimit(colNames, colTypes, op.Count, op.Offset)
 		desc, sortByExprs := p.byItemsToDescAndSortExpression(op.ByItems, ctx.Planner().SessionContext())
-		sort := exec.NewPullSort(colNames, colTypes, desc, sortByExprs)
+		if scan := pushDownScan(op.Children()[0]); scan != nil && !remote {
+			// Each shard only returns its own top rows. These are merged on this node and the limit is applied again.
+			remoteExecutor, err := p.buildMergingRemoteExecutor(ctx, op, scan, colNames, colTypes, desc, sortByExprs)
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+			limit := exec.NewPullLimit(colNames, colTypes, op.Count, op.Offset)
+			exec.ConnectPullExecutors([]exec.PullExecutor{remoteExecutor}, limit)
+			return limit, nil
+		}
+		count, offset := op.Count, op.Offset
+		if remote {
+			// The rows skipped by the offset on one shard are not necessarily skipped overall, so the offset is only
+			// applied after the rows from the shards have been merged
+			count, offset = count+offset, 0
+		}
+		limit := exec.NewPullLimit(colNames, colTypes, count, offset)
+		sort := exec.NewPullSort(colNames, colTypes, desc, sortByExprs, p.sortSpillConf)
+		ctx.AddCloser(sort.Close)
 		executor = exec.NewPullChain(limit, sort)
 	default:
 		return nil, errors.Errorf("unexpected plan type %T", plan)
@@ -133,6 +145,150 @@ func (p *Engine) buildPullDAG(ctx *execctx.ExecutionContext, plan planner.Physic
 	return executor, nil
 }
 
+// buildRemoteExecutor builds a RemoteExecutor which executes the plan on each shard, or just on the shard which owns
+// the key if the scan is a point get
+func (p *Engine) buildRemoteExecutor(ctx *execctx.ExecutionContext, plan planner.PhysicalPlan, scan planner.PhysicalPlan,
+	colNames []string, colTypes []common.ColumnType) (*exec.RemoteExecutor, error) {
+	remoteDag, err := p.buildPullDAG(ctx, plan, true)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	var pointGetShardID int64 = -1
+	if tableScan, ok := scan.(*planner.PhysicalTableScan); ok {
+		pointGetShardID, err = p.getPointGetShardID(ctx, tableScan.Ranges, tableScan.Table.Name.L)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return exec.NewRemoteExecutor(remoteDag, ctx.QueryInfo, colNames, colTypes, ctx.Schema.Name, p.cluster,
+		pointGetShardID), nil
+}
+
+// buildMergingRemoteExecutor builds a RemoteExecutor which executes a sort, and the scan below it, on each shard. The
+// sorted rows from the shards are merged as they are fetched, so the sort is never repeated on this node.
+func (p *Engine) buildMergingRemoteExecutor(ctx *execctx.ExecutionContext, plan planner.PhysicalPlan,
+	scan planner.PhysicalPlan, colNames []string, colTypes []common.ColumnType, desc []bool,
+	sortByExprs []*common.Expression) (*exec.RemoteExecutor, error) {
+	remoteExecutor, err := p.buildRemoteExecutor(ctx, plan, scan, colNames, colTypes)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	remoteExecutor.MergeSorted(desc, sortByExprs)
+	return remoteExecutor, nil
+}
+
+// pushDownScan returns the scan at the bottom of the plan if the plan can be executed on the shards, which it can if
+// it is a scan with only selections and projections above it. Otherwise it returns nil.
+func pushDownScan(plan planner.PhysicalPlan) planner.PhysicalPlan {
+	switch op := plan.(type) {
+	case *planner.PhysicalSelection, *planner.PhysicalProjection:
+		return pushDownScan(op.Children()[0])
+	case *planner.PhysicalTableScan, *planner.PhysicalIndexScan:
+		return op
+	default:
+		return nil
+	}
+}
+
+// buildPullAggregate builds an aggregation which is partially calculated on each shard, and then merged on this node.
+// If there are distinct aggregate functions the rows are sent from the shards and aggregated on this node instead. If
+// the rows to aggregate can't be produced on the shards, e.g. they are limited, joined or aggregated first, they are
+// produced on this node and aggregated in a single phase here.
+func (p *Engine) buildPullAggregate(ctx *execctx.ExecutionContext, op *planner.PhysicalHashAgg, colNames []string,
+	remote bool) (exec.PullExecutor, error) {
+	if remote {
+		return nil, errors.Error("aggregation cannot be executed remotely")
+	}
+	sessCtx := ctx.Planner().SessionContext()
+	var aggFuncs []*aggfuncs.AggregateFunctionInfo
+	singlePhase := false
+	for _, aggFunc := range op.AggFuncs {
+		if len(aggFunc.Args) > 1 {
+			return nil, errors.Error("more than one aggregate function arg")
+		}
+		funcType, err := aggfuncs.AggFunctionTypeForName(aggFunc.Name)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		af := &aggfuncs.AggregateFunctionInfo{
+			FuncType:   funcType,
+			Distinct:   aggFunc.HasDistinct,
+			ReturnType: common.ConvertTiDBTypeToPranaType(aggFunc.RetTp),
+		}
+		if len(aggFunc.Args) == 1 {
+			af.ArgExpr = common.NewExpression(aggFunc.Args[0], sessCtx)
+			af.ArgType = common.ConvertTiDBTypeToPranaType(aggFunc.Args[0].GetType())
+		}
+		if af.Distinct && funcType != aggfuncs.FirstRowAggregateFunctionType &&
+			funcType != aggfuncs.MinAggregateFunctionType && funcType != aggfuncs.MaxAggregateFunctionType {
+			singlePhase = true
+		}
+		aggFuncs = append(aggFuncs, af)
+	}
+	// Each group by value is output by a hidden function, so the partial aggregations can be merged by group
+	groupByExprs := make([]*common.Expression, len(op.GroupByItems))
+	for i, expr := range op.GroupByItems {
+		groupByExprs[i] = common.NewExpression(expr, sessCtx)
+		colType := common.ConvertTiDBTypeToPranaType(expr.GetType())
+		aggFuncs = append(aggFuncs, &aggfuncs.AggregateFunctionInfo{
+			FuncType:   aggfuncs.FirstRowAggregateFunctionType,
+			ArgExpr:    groupByExprs[i],
+			ArgType:    colType,
+			ReturnType: colType,
+			Hidden:     true,
+		})
+	}
+	if pushDownScan(op.Children()[0]) == nil {
+		// The rows to aggregate are produced on this node, so they are aggregated here too
+		child, err := p.buildPullDAG(ctx, op.Children()[0], false)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		agg, err := exec.NewPullAggregate(colNames, aggFuncs, groupByExprs, exec.AggregationPhaseSingle)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		exec.ConnectPullExecutors([]exec.PullExecutor{child}, agg)
+		return agg, nil
+	}
+	remoteDag, err := p.buildPullDAG(ctx, op.Children()[0], true)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	var phase exec.AggregationPhase
+	if singlePhase {
+		phase = exec.AggregationPhaseSingle
+	} else {
+		partialAgg, err := exec.NewPullAggregate(nil, aggFuncs, groupByExprs, exec.AggregationPhasePartial)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		exec.ConnectPullExecutors([]exec.PullExecutor{remoteDag}, partialAgg)
+		remoteDag = partialAgg
+		phase = exec.AggregationPhaseFinal
+	}
+	remoteExecutor := exec.NewRemoteExecutor(remoteDag, ctx.QueryInfo, remoteDag.ColNames(), remoteDag.ColTypes(),
+		ctx.Schema.Name, p.cluster, -1)
+	agg, err := exec.NewPullAggregate(colNames, aggFuncs, groupByExprs, phase)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	exec.ConnectPullExecutors([]exec.PullExecutor{remoteExecutor}, agg)
+	return agg, nil
+}
+
+// planColumns returns the names and types of the output columns of the plan
+func planColumns(plan planner.PhysicalPlan) ([]string, []common.ColumnType) {
+	cols := plan.Schema().Columns
+	colTypes := make([]common.ColumnType, 0, len(cols))
+	colNames := make([]string, 0, len(cols))
+	for _, col := range cols {
+		colTypes = append(colTypes, common.ConvertTiDBTypeToPranaType(col.GetType()))
+		colNames = append(colNames, col.OrigName)
+	}
+	return colNames, colTypes
+}
+
 func (p *Engine) getPointGetShardID(ctx *execctx.ExecutionContext, ranges []*ranger.Range, tableName string) (int64, error) {
 	var pointGetShardID int64 = -1
 	if len(ranges) == 1 {
@@ -232,41 +388,3 @@ func (p *Engine) byItemsToDescAndSortExpression(byItems []*util.ByItems, ctx ses
 	}
 	return desc, sortByExprs
 }
-
-func dumpPhysicalPlan(plan planner.PhysicalPlan) string { // nolint: deadcode
-	builder := &strings.Builder{}
-	dumpPhysicalPlanRec(plan, 0, builder)
-	return builder.String()
-}
-
-func dumpPhysicalPlanRec(plan planner.PhysicalPlan, level int, builder *strings.Builder) {
-	for i := 0; i < level-1; i++ {
-		builder.WriteString("   |")
-	}
-	if level > 0 {
-		builder.WriteString("   > ")
-	}
-	builder.WriteString(fmt.Sprintf("%T\n", plan))
-	for _, child := range plan.Children() {
-		dumpPhysicalPlanRec(child, level+1, builder)
-	}
-}
-
-func dumpPullDAG(pullDAG exec.PullExecutor) string { // nolint: deadcode
-	builder := &strings.Builder{}
-	dumpPullDAGRec(pullDAG, 0, builder)
-	return builder.String()
-}
-
-func dumpPullDAGRec(pullDAG exec.PullExecutor, level int, builder *strings.Builder) {
-	for i := 0; i < level-1; i++ {
-		builder.WriteString("   |")
-	}
-	if level > 0 {
-		builder.WriteString("   > ")
-	}
-	builder.WriteString(fmt.Sprintf("%T\n", pullDAG))
-	for _, child := range pullDAG.GetChildren() {
-		dumpPullDAGRec(child, level+1, builder)
-	}
-}
@@ -0,0 +1,210 @@
+package pull
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/squareup/pranadb/common"
+	"github.com/squareup/pranadb/errors"
+	"github.com/squareup/pranadb/execctx"
+	"github.com/squareup/pranadb/pull/exec"
+	"github.com/squareup/pranadb/tidb/expression"
+	"github.com/squareup/pranadb/tidb/planner"
+	"github.com/squareup/pranadb/tidb/planner/util"
+	"github.com/squareup/pranadb/tidb/util/ranger"
+)
+
+const (
+	explainPlanPhysical = "physical"
+	explainPlanPull     = "pull"
+
+	// The batch size in which the rows of an analyzed query are read and discarded
+	analyzeBatchSize = 1000
+)
+
+var explainColNames = []string{"plan", "operator", "info"}
+
+var explainRowsFactory = common.NewRowsFactory(
+	[]common.ColumnType{
+		common.VarcharColumnType, // plan
+		common.VarcharColumnType, // operator
+		common.VarcharColumnType, // info
+	},
+)
+
+var explainAnalyzeColNames = []string{"plan", "operator", "info", "rows", "time"}
+
+var explainAnalyzeRowsFactory = common.NewRowsFactory(
+	[]common.ColumnType{
+		common.VarcharColumnType, // plan
+		common.VarcharColumnType, // operator
+		common.VarcharColumnType, // info
+		common.BigIntColumnType,  // rows
+		common.VarcharColumnType, // time
+	},
+)
+
+// ExplainQuery returns rows which describe the physical plan of a pull query, followed by the DAG which executes it.
+// If analyze is true the query is also executed, and the rows returned by each executor and the time spent in it are
+// included. The rows of the query itself are discarded.
+func (p *Engine) ExplainQuery(execCtx *execctx.ExecutionContext, query string, analyze bool) (exec.PullExecutor, error) {
+	logicalPlan, physicalPlan, err := p.planPullQuery(execCtx, query)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	dag, err := p.buildPullDAGWithOutputNames(execCtx, logicalPlan, physicalPlan, false)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	colNames, rowsFactory := explainColNames, explainRowsFactory
+	if analyze {
+		analyzed := exec.Analyze(dag)
+		for {
+			r, err := analyzed.GetRows(analyzeBatchSize)
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+			if r.RowCount() < analyzeBatchSize {
+				break
+			}
+		}
+		dag = analyzed
+		colNames, rowsFactory = explainAnalyzeColNames, explainAnalyzeRowsFactory
+	}
+	rows := rowsFactory.NewRows(10)
+	for _, op := range explainPhysicalPlan(physicalPlan, 0, nil) {
+		rows.AppendStringToColumn(0, explainPlanPhysical)
+		rows.AppendStringToColumn(1, op.operator)
+		rows.AppendStringToColumn(2, op.info)
+		if analyze {
+			rows.AppendNullToColumn(3)
+			rows.AppendNullToColumn(4)
+		}
+	}
+	for _, explained := range exec.Explain(dag) {
+		rows.AppendStringToColumn(0, explainPlanPull)
+		rows.AppendStringToColumn(1, explainOperator(explained.Operator, explained.Level))
+		rows.AppendStringToColumn(2, explained.Info)
+		if !analyze {
+			continue
+		}
+		if explained.Stats != nil {
+			rows.AppendInt64ToColumn(3, explained.Stats.Rows)
+			rows.AppendStringToColumn(4, explained.Stats.Time.String())
+		} else {
+			rows.AppendNullToColumn(3)
+			rows.AppendNullToColumn(4)
+		}
+	}
+	staticRows, err := exec.NewStaticRows(colNames, rows)
+	return staticRows, errors.WithStack(err)
+}
+
+type explainedOperator struct {
+	operator string
+	info     string
+}
+
+func explainPhysicalPlan(plan planner.PhysicalPlan, level int, explained []explainedOperator) []explainedOperator {
+	explained = append(explained, explainedOperator{
+		operator: explainOperator(reflect.TypeOf(plan).Elem().Name(), level),
+		info:     physicalPlanInfo(plan),
+	})
+	for _, child := range plan.Children() {
+		explained = explainPhysicalPlan(child, level+1, explained)
+	}
+	return explained
+}
+
+// explainOperator indents the name of an operator to show its depth in the plan
+func explainOperator(name string, level int) string {
+	builder := &strings.Builder{}
+	for i := 0; i < level-1; i++ {
+		builder.WriteString("   |")
+	}
+	if level > 0 {
+		builder.WriteString("   > ")
+	}
+	builder.WriteString(name)
+	return builder.String()
+}
+
+func physicalPlanInfo(plan planner.PhysicalPlan) string {
+	var info []string
+	switch op := plan.(type) {
+	case *planner.PhysicalTableScan:
+		info = append(info, fmt.Sprintf("table:%s", op.Table.Name.L), fmt.Sprintf("ranges:%s", rangesString(op.Ranges)))
+	case *planner.PhysicalIndexScan:
+		tableName := op.Table.Name.L
+		info = append(info, fmt.Sprintf("table:%s", tableName))
+		if !op.Index.Primary {
+			// The planner knows the index by a name which is prefixed with the name of its table
+			info = append(info, fmt.Sprintf("index:%s", strings.Replace(op.Index.Name.L, tableName+"_u", "", 1)))
+		}
+		info = append(info, fmt.Sprintf("ranges:%s", rangesString(op.Ranges)))
+	case *planner.PhysicalSelection:
+		info = append(info, fmt.Sprintf("conditions:%s", exprsString(op.Conditions)))
+	case *planner.PhysicalProjection:
+		info = append(info, fmt.Sprintf("exprs:%s", exprsString(op.Exprs)))
+	case *planner.PhysicalSort:
+		info = append(info, fmt.Sprintf("by:%s", byItemsString(op.ByItems)))
+	case *planner.PhysicalTopN:
+		info = append(info, fmt.Sprintf("by:%s", byItemsString(op.ByItems)), fmt.Sprintf("count:%d", op.Count),
+			fmt.Sprintf("offset:%d", op.Offset))
+	case *planner.PhysicalLimit:
+		info = append(info, fmt.Sprintf("count:%d", op.Count), fmt.Sprintf("offset:%d", op.Offset))
+	case *planner.PhysicalHashAgg:
+		if len(op.GroupByItems) > 0 {
+			info = append(info, fmt.Sprintf("group by:%s", exprsString(op.GroupByItems)))
+		}
+		aggFuncs := make([]string, len(op.AggFuncs))
+		for i, aggFunc := range op.AggFuncs {
+			aggFuncs[i] = aggFunc.String()
+		}
+		info = append(info, fmt.Sprintf("funcs:%s", strings.Join(aggFuncs, ", ")))
+	case *planner.PhysicalHashJoin:
+		info = append(info, fmt.Sprintf("type:%s", op.JoinType))
+		keys := make([]string, len(op.LeftJoinKeys))
+		for i := range op.LeftJoinKeys {
+			keys[i] = fmt.Sprintf("eq(%s, %s)", op.LeftJoinKeys[i], op.RightJoinKeys[i])
+		}
+		if len(keys) > 0 {
+			info = append(info, fmt.Sprintf("keys:%s", strings.Join(keys, ", ")))
+		}
+		if len(op.LeftConditions) > 0 {
+			info = append(info, fmt.Sprintf("left conditions:%s", exprsString(op.LeftConditions)))
+		}
+		if len(op.RightConditions) > 0 {
+			info = append(info, fmt.Sprintf("right conditions:%s", exprsString(op.RightConditions)))
+		}
+		if len(op.OtherConditions) > 0 {
+			info = append(info, fmt.Sprintf("other conditions:%s", exprsString(op.OtherConditions)))
+		}
+	}
+	return strings.Join(info, ", ")
+}
+
+func exprsString(exprs []expression.Expression) string {
+	strs := make([]string, len(exprs))
+	for i, expr := range exprs {
+		strs[i] = expr.String()
+	}
+	return "[" + strings.Join(strs, ", ") + "]"
+}
+
+func byItemsString(byItems []*util.ByItems) string {
+	strs := make([]string, len(byItems))
+	for i, byItem := range byItems {
+		strs[i] = byItem.String()
+	}
+	return "[" + strings.Join(strs, ", ") + "]"
+}
+
+func rangesString(ranges []*ranger.Range) string {
+	strs := make([]string, len(ranges))
+	for i, rng := range ranges {
+		strs[i] = rng.String()
+	}
+	return "[" + strings.Join(strs, ", ") + "]"
+}
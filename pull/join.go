@@ -0,0 +1,271 @@
+package pull
+
+import (
+	"sort"
+
+	"github.com/pingcap/parser/model"
+	"github.com/squareup/pranadb/common"
+	"github.com/squareup/pranadb/errors"
+	"github.com/squareup/pranadb/execctx"
+	"github.com/squareup/pranadb/pull/exec"
+	"github.com/squareup/pranadb/tidb/expression"
+	"github.com/squareup/pranadb/tidb/planner"
+	"github.com/squareup/pranadb/tidb/util/ranger"
+)
+
+// indexLookup describes how the rows of the inner side of an index lookup join are looked up
+type indexLookup struct {
+	tableInfo *common.TableInfo
+	indexInfo *common.IndexInfo // Nil if the rows are looked up by primary key
+	columns   []*model.ColumnInfo
+	// The position in the join key of each of the primary key or index columns which are looked up
+	keyPositions []int
+}
+
+// buildPullJoin builds a join which is executed on this node. If the inner side of the join is a scan of a table whose
+// primary key, or the start of one of its indexes, is the join key, the matching rows of the table are looked up for
+// each batch of rows from the outer side. Otherwise the rows of both sides are fetched from the shards and hash joined.
+func (p *Engine) buildPullJoin(ctx *execctx.ExecutionContext, op *planner.PhysicalHashJoin, colNames []string,
+	colTypes []common.ColumnType, remote bool) (exec.PullExecutor, error) {
+	if remote {
+		return nil, errors.Error("join cannot be executed remotely")
+	}
+	var joinType exec.JoinType
+	var innerSides []int
+	switch op.JoinType {
+	case planner.InnerJoin:
+		joinType = exec.InnerJoin
+		innerSides = []int{1, 0}
+	case planner.LeftOuterJoin:
+		joinType = exec.LeftOuterJoin
+		innerSides = []int{1}
+	case planner.RightOuterJoin:
+		joinType = exec.RightOuterJoin
+		innerSides = []int{0}
+	default:
+		return nil, errors.NewPranaErrorf(errors.InvalidStatement, "%s is not supported in pull queries", op.JoinType.String())
+	}
+	sides := make([]*exec.JoinSide, 2)
+	for i, joinKeys := range [][]*expression.Column{op.LeftJoinKeys, op.RightJoinKeys} {
+		_, childColTypes := planColumns(op.Children()[i])
+		joinCols := make([]int, len(joinKeys))
+		for j, col := range joinKeys {
+			joinCols[j] = col.Index
+		}
+		conds := op.LeftConditions
+		if i == 1 {
+			conds = op.RightConditions
+		}
+		sides[i] = &exec.JoinSide{
+			ColTypes: childColTypes,
+			JoinCols: joinCols,
+			Conds:    toExpressions(conds, ctx),
+		}
+	}
+	otherConds := toExpressions(op.OtherConditions, ctx)
+
+	// The planner may have pruned columns of the children from the output of the join
+	childSchema := expression.MergeSchema(op.Children()[0].Schema(), op.Children()[1].Schema())
+	childCols := make([]int, op.Schema().Len())
+	for i, col := range op.Schema().Columns {
+		childCols[i] = childSchema.ColumnIndex(col)
+		if childCols[i] == -1 {
+			return nil, errors.Errorf("cannot find join column %s in children", col.String())
+		}
+	}
+
+	for _, innerSide := range innerSides {
+		lookup, err := p.indexLookup(ctx, op.Children()[innerSide], sides[innerSide].JoinCols)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		if lookup != nil {
+			return p.buildIndexLookupJoin(ctx, op, colNames, colTypes, joinType, sides, otherConds, childCols, innerSide,
+				lookup)
+		}
+	}
+
+	join, err := exec.NewPullHashJoin(colNames, colTypes, joinType, sides[0], sides[1], otherConds, childCols)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	var children []exec.PullExecutor
+	for _, child := range op.Children() {
+		childExecutor, err := p.buildPullDAG(ctx, child, false)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		children = append(children, childExecutor)
+	}
+	exec.ConnectPullExecutors(children, join)
+	return join, nil
+}
+
+func (p *Engine) buildIndexLookupJoin(ctx *execctx.ExecutionContext, op *planner.PhysicalHashJoin, colNames []string,
+	colTypes []common.ColumnType, joinType exec.JoinType, sides []*exec.JoinSide, otherConds []*common.Expression,
+	childCols []int, innerSide int, lookup *indexLookup) (exec.PullExecutor, error) {
+	outerSide := 1 - innerSide
+	outer, err := p.buildPullDAG(ctx, op.Children()[outerSide], false)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	innerPlan := op.Children()[innerSide]
+	innerDag, err := p.buildLookupDAG(ctx, innerPlan, lookup)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	innerColNames, innerColTypes := planColumns(innerPlan)
+	inner := exec.NewRemoteExecutor(innerDag, ctx.QueryInfo, innerColNames, innerColTypes, ctx.Schema.Name, p.cluster, -1)
+
+	lookupCols := make([]int, len(lookup.keyPositions))
+	for i, pos := range lookup.keyPositions {
+		lookupCols[i] = sides[outerSide].JoinCols[pos]
+	}
+	// A primary key lookup only goes to the shard which owns the key, but index entries are stored on the shard of
+	// the row they point to, so index lookups go to every shard. As with point gets, we don't calculate the shard of
+	// keys of type Decimal.
+	shardByKey := lookup.indexInfo == nil
+	for _, keyType := range lookup.keyTypes() {
+		if keyType.Type == common.TypeDecimal {
+			shardByKey = false
+		}
+	}
+	join, err := exec.NewPullIndexLookupJoin(colNames, colTypes, joinType, sides[0], sides[1], otherConds, childCols,
+		outerSide == 0, lookupCols, lookup.keyTypes(), shardByKey, p.shrder, p.cluster.GetAllShardIDs())
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	children := []exec.PullExecutor{outer, inner}
+	if innerSide == 0 {
+		children = []exec.PullExecutor{inner, outer}
+	}
+	exec.ConnectPullExecutors(children, join)
+	return join, nil
+}
+
+// indexLookup returns how to look up the rows of the inner side of a join by join key, or nil if they can't be looked
+// up. The inner side must be a scan of all the rows of a table, optionally filtered, where the join key columns are the
+// primary key or the first columns of an index.
+func (p *Engine) indexLookup(ctx *execctx.ExecutionContext, plan planner.PhysicalPlan, joinCols []int) (*indexLookup, error) {
+	if len(joinCols) == 0 {
+		return nil, nil
+	}
+	for {
+		sel, ok := plan.(*planner.PhysicalSelection)
+		if !ok {
+			break
+		}
+		plan = sel.Children()[0]
+	}
+	var tableName string
+	var ranges []*ranger.Range
+	var columns []*model.ColumnInfo
+	switch op := plan.(type) {
+	case *planner.PhysicalTableScan:
+		tableName, ranges, columns = op.Table.Name.L, op.Ranges, op.Columns
+	case *planner.PhysicalIndexScan:
+		tableName, ranges, columns = op.Table.Name.L, op.Ranges, op.Columns
+	default:
+		return nil, nil
+	}
+	for _, rng := range ranges {
+		if !rng.IsFullRange() {
+			return nil, nil
+		}
+	}
+	table, ok := ctx.Schema.GetTable(tableName)
+	if !ok {
+		return nil, errors.Errorf("unknown source or materialized view %s", tableName)
+	}
+	tableInfo := table.GetTableInfo()
+	keyTableCols := make([]int, len(joinCols))
+	for i, joinCol := range joinCols {
+		keyTableCols[i] = columns[joinCol].Offset
+	}
+	if len(keyTableCols) == len(tableInfo.PrimaryKeyCols) {
+		if keyPositions := lookupKeyPositions(tableInfo.PrimaryKeyCols, keyTableCols); keyPositions != nil {
+			return &indexLookup{tableInfo: tableInfo, columns: columns, keyPositions: keyPositions}, nil
+		}
+	}
+	indexNames := make([]string, 0, len(tableInfo.IndexInfos))
+	for indexName := range tableInfo.IndexInfos {
+		indexNames = append(indexNames, indexName)
+	}
+	sort.Strings(indexNames)
+	for _, indexName := range indexNames {
+		indexInfo := tableInfo.IndexInfos[indexName]
+		if keyPositions := lookupKeyPositions(indexInfo.IndexCols, keyTableCols); keyPositions != nil {
+			return &indexLookup{tableInfo: tableInfo, indexInfo: indexInfo, columns: columns, keyPositions: keyPositions}, nil
+		}
+	}
+	return nil, nil
+}
+
+// lookupKeyPositions returns the position in the join key of each of the first len(keyTableCols) of the key columns, or
+// nil if they aren't the columns of the join key
+func lookupKeyPositions(keyCols []int, keyTableCols []int) []int {
+	if len(keyTableCols) > len(keyCols) {
+		return nil
+	}
+	positions := make([]int, len(keyTableCols))
+	for i, keyCol := range keyCols[:len(keyTableCols)] {
+		positions[i] = -1
+		for j, keyTableCol := range keyTableCols {
+			if keyTableCol == keyCol {
+				positions[i] = j
+				break
+			}
+		}
+		if positions[i] == -1 {
+			return nil
+		}
+	}
+	return positions
+}
+
+func (l *indexLookup) keyTypes() []common.ColumnType {
+	keyCols := l.tableInfo.PrimaryKeyCols
+	if l.indexInfo != nil {
+		keyCols = l.indexInfo.IndexCols
+	}
+	keyTypes := make([]common.ColumnType, len(l.keyPositions))
+	for i := range keyTypes {
+		keyTypes[i] = l.tableInfo.ColumnTypes[keyCols[i]]
+	}
+	return keyTypes
+}
+
+// buildLookupDAG builds the remote part of the inner side of an index lookup join, which scans the table for the keys
+// sent with the query
+func (p *Engine) buildLookupDAG(ctx *execctx.ExecutionContext, plan planner.PhysicalPlan, lookup *indexLookup) (exec.PullExecutor, error) {
+	colNames, colTypes := planColumns(plan)
+	if sel, ok := plan.(*planner.PhysicalSelection); ok {
+		executor := exec.NewPullSelect(colNames, colTypes, toExpressions(sel.Conditions, ctx))
+		child, err := p.buildLookupDAG(ctx, sel.Children()[0], lookup)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		exec.ConnectPullExecutors([]exec.PullExecutor{child}, executor)
+		return executor, nil
+	}
+	colIndexes := make([]int, len(lookup.columns))
+	for i, col := range lookup.columns {
+		colIndexes[i] = col.Offset
+	}
+	createScan := func(scanRanges []*exec.ScanRange) (exec.PullExecutor, error) {
+		if lookup.indexInfo == nil {
+			return exec.NewPullTableScan(lookup.tableInfo, colIndexes, p.cluster, ctx.QueryInfo.ShardID, scanRanges)
+		}
+		return exec.NewPullIndexReader(lookup.tableInfo, lookup.indexInfo, colIndexes, p.cluster, ctx.QueryInfo.ShardID,
+			scanRanges)
+	}
+	return exec.NewPullLookup(colNames, colTypes, ctx.QueryInfo, lookup.keyTypes(), createScan), nil
+}
+
+func toExpressions(exprs []expression.Expression, ctx *execctx.ExecutionContext) []*common.Expression {
+	res := make([]*common.Expression, len(exprs))
+	for i, expr := range exprs {
+		res[i] = common.NewExpression(expr, ctx.Planner().SessionContext())
+	}
+	return res
+}
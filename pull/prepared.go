@@ -0,0 +1,184 @@
+package pull
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/squareup/pranadb/cluster"
+	"github.com/squareup/pranadb/common"
+	"github.com/squareup/pranadb/errors"
+	"github.com/squareup/pranadb/execctx"
+	"github.com/squareup/pranadb/parplan"
+	"github.com/squareup/pranadb/pull/exec"
+	"github.com/squareup/pranadb/tidb/planner"
+)
+
+// PreparedQuery is a pull query with param markers (?) which can be executed many times with different args. The plan
+// is cached for each combination of arg types, so the query is only planned the first time it is executed with them.
+// A PreparedQuery must not be executed concurrently.
+type PreparedQuery struct {
+	schemaName string
+	query      string
+	ast        parplan.AstHandle
+	execCtx    *execctx.ExecutionContext
+	plans      map[string]*preparedPlan
+}
+
+type preparedPlan struct {
+	logicalPlan  planner.LogicalPlan
+	physicalPlan planner.PhysicalPlan
+	// The ids of the tables the plan reads, by name, so we can tell if a table has been recreated since it was planned
+	tableIDs map[string]uint64
+}
+
+// Close releases the resources held by the last execution of the query, e.g. the rows a sort spilled to disk. The
+// query can still be executed again.
+func (pq *PreparedQuery) Close() {
+	pq.execCtx.Close()
+}
+
+// NumParams returns the number of args the query must be executed with
+func (pq *PreparedQuery) NumParams() int {
+	return pq.ast.NumParams()
+}
+
+// Schema returns the schema the query was last planned against
+func (pq *PreparedQuery) Schema() *common.Schema {
+	return pq.execCtx.Schema
+}
+
+// PrepareQuery parses a pull query and checks it against the schema, so it can be executed later
+func (p *Engine) PrepareQuery(schema *common.Schema, query string) (*PreparedQuery, error) {
+	pq := &PreparedQuery{
+		schemaName: schema.Name,
+		query:      query,
+	}
+	pq.setSchema(schema)
+	pq.execCtx.Planner().RefreshInfoSchema()
+	ast, err := pq.execCtx.Planner().Parse(query)
+	if err != nil {
+		return nil, errors.NewInvalidStatementError(err.Error())
+	}
+	pq.ast = ast
+	// We can't plan the query until we know the types of the args, but we build the logical plan with null args so
+	// that unknown tables and columns are reported now
+	pq.execCtx.Planner().SetPSArgs(make([]interface{}, ast.NumParams()))
+	if _, err := pq.execCtx.Planner().BuildLogicalPlan(ast, true); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return pq, nil
+}
+
+// BuildPreparedQuery builds a DAG which executes a prepared query with the args. A cached plan is used if there is one
+// for args of the same types, with the ranges of its scans recalculated for the args.
+func (p *Engine) BuildPreparedQuery(pq *PreparedQuery, executionID string, args []interface{}) (exec.PullExecutor, error) {
+	if len(args) != pq.NumParams() {
+		return nil, errors.NewInvalidStatementError(fmt.Sprintf("prepared statement has %d parameters but %d args were provided",
+			pq.NumParams(), len(args)))
+	}
+	schema := p.metaController.GetOrCreateSchema(pq.schemaName)
+	if schema != pq.execCtx.Schema {
+		// The schema has been deleted and created again since the query was planned
+		pq.setSchema(schema)
+	}
+	execCtx := pq.execCtx
+	execCtx.ID = executionID
+	execCtx.QueryInfo = &cluster.QueryExecutionInfo{
+		ExecutionID: executionID,
+		SchemaName:  pq.schemaName,
+		Query:       pq.query,
+		PsArgs:      args,
+	}
+	execCtx.Planner().RefreshInfoSchema()
+	execCtx.Planner().SetPSArgs(args)
+
+	key := argTypesKey(args)
+	if plan, ok := pq.plans[key]; ok {
+		if plan.tablesUnchanged(schema) {
+			dag, err := p.buildCachedPlan(execCtx, plan)
+			if err == nil {
+				return dag, nil
+			}
+		}
+		// The plan is stale, e.g. an index it reads has been dropped, so we plan the query again
+		delete(pq.plans, key)
+	}
+	logicalPlan, err := execCtx.Planner().BuildLogicalPlan(pq.ast, true)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	physicalPlan, err := execCtx.Planner().BuildPhysicalPlan(logicalPlan, true)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	plan := &preparedPlan{
+		logicalPlan:  logicalPlan,
+		physicalPlan: physicalPlan,
+		tableIDs:     make(map[string]uint64),
+	}
+	if err := plan.addTableIDs(schema, physicalPlan); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	dag, err := p.buildPullDAGWithOutputNames(execCtx, logicalPlan, physicalPlan, false)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	pq.plans[key] = plan
+	return dag, nil
+}
+
+func (p *Engine) buildCachedPlan(execCtx *execctx.ExecutionContext, plan *preparedPlan) (exec.PullExecutor, error) {
+	if err := execCtx.Planner().RebuildRanges(plan.physicalPlan); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return p.buildPullDAGWithOutputNames(execCtx, plan.logicalPlan, plan.physicalPlan, false)
+}
+
+// setSchema sets the schema the query is planned against, which discards any cached plans
+func (pq *PreparedQuery) setSchema(schema *common.Schema) {
+	pq.execCtx = execctx.NewExecutionContext("", schema)
+	pq.plans = make(map[string]*preparedPlan)
+}
+
+func (pp *preparedPlan) addTableIDs(schema *common.Schema, plan planner.PhysicalPlan) error {
+	var tableName string
+	switch op := plan.(type) {
+	case *planner.PhysicalTableScan:
+		tableName = op.Table.Name.L
+	case *planner.PhysicalIndexScan:
+		tableName = op.Table.Name.L
+	}
+	if tableName != "" {
+		table, ok := schema.GetTable(tableName)
+		if !ok {
+			return errors.NewUnknownSourceOrMaterializedViewError(schema.Name, tableName)
+		}
+		pp.tableIDs[tableName] = table.GetTableInfo().ID
+	}
+	for _, child := range plan.Children() {
+		if err := pp.addTableIDs(schema, child); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (pp *preparedPlan) tablesUnchanged(schema *common.Schema) bool {
+	for tableName, id := range pp.tableIDs {
+		table, ok := schema.GetTable(tableName)
+		if !ok || table.GetTableInfo().ID != id {
+			return false
+		}
+	}
+	return true
+}
+
+// argTypesKey returns the key of the cached plan for args of these types. A plan built for args of some types can't
+// be used for args of other types, as the types of the expressions in the plan depend on them.
+func argTypesKey(args []interface{}) string {
+	var sb strings.Builder
+	for _, arg := range args {
+		sb.WriteString(fmt.Sprintf("%T,", arg))
+	}
+	return sb.String()
+}
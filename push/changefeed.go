@@ -0,0 +1,466 @@
+package push
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/squareup/pranadb/cluster"
+	"github.com/squareup/pranadb/common"
+	"github.com/squareup/pranadb/errors"
+	"github.com/squareup/pranadb/protos/squareup/cash/pranadb/v1/notifications"
+	"github.com/squareup/pranadb/push/exec"
+	"github.com/squareup/pranadb/remoting"
+	"github.com/squareup/pranadb/table"
+)
+
+const (
+	// The name the change feed is registered with as a consuming node of the materialized view
+	changeFeedConsumerName = "changefeed"
+
+	// How long a change feed carries on recording changes after its last subscription has closed, so that clients can
+	// resume
+	changeFeedRetention = 5 * time.Minute
+
+	// The maximum number of rows or changes sent to the subscribing node in one message
+	changeFeedMaxBatchSize = 1000
+
+	changeFeedSendTimeout = 10 * time.Second
+)
+
+// changeFeed records the changes to a materialized view on the shards of this node and sends them to the subscriptions
+// to the materialized view. There is at most one change feed for a materialized view on each node.
+type changeFeed struct {
+	p              *Engine
+	mvInfo         *common.MaterializedViewInfo
+	tableExecutor  *exec.TableExecutor
+	feedExecutor   *exec.ChangeFeedExecutor
+	shardIDs       []uint64
+	lock           sync.Mutex
+	subscriptions  map[string]*feedSubscription
+	retentionTimer *time.Timer
+	closed         bool
+}
+
+// feedSubscription sends the snapshot and changes of the shards of a change feed to the node the client of the
+// subscription is connected to
+type feedSubscription struct {
+	id        string
+	nodeID    int
+	feed      *changeFeed
+	snapshot  cluster.Snapshot // nil when resuming
+	positions map[uint64]uint64
+	notifyCh  chan struct{}
+	closeCh   chan struct{}
+	closeOnce sync.Once
+	errLock   sync.Mutex
+	errMsg    string
+}
+
+// handleChangeFeedSubscribe starts sending the changes to a materialized view on the shards of this node to the node
+// which originated the subscription
+func (p *Engine) handleChangeFeedSubscribe(msg *notifications.ChangeFeedSubscribe) error {
+	mvInfo, ok := p.meta.GetMaterializedView(msg.SchemaName, msg.MvName)
+	if !ok {
+		return errors.NewUnknownMaterializedViewError(msg.SchemaName, msg.MvName)
+	}
+	feed, err := p.getOrCreateChangeFeed(mvInfo)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	sub := &feedSubscription{
+		id:        msg.SubscriptionId,
+		nodeID:    int(msg.NodeId),
+		feed:      feed,
+		positions: make(map[uint64]uint64, len(feed.shardIDs)),
+		notifyCh:  make(chan struct{}, 1),
+		closeCh:   make(chan struct{}),
+	}
+	if len(msg.ResumePositions) > 0 {
+		// The positions are for all the shards, we only care about the ones on this node
+		resumePositions := make(map[uint64]*notifications.ChangeFeedPosition, len(msg.ResumePositions))
+		for _, pos := range msg.ResumePositions {
+			resumePositions[pos.ShardId] = pos
+		}
+		for _, shardID := range feed.shardIDs {
+			pos, ok := resumePositions[shardID]
+			if !ok {
+				return errors.NewInvalidResumeTokenError()
+			}
+			if pos.Epoch != feed.feedExecutor.Epoch {
+				// The positions are from a different feed, e.g. the node has been restarted, or the feed was removed
+				// after it had no subscriptions for the retention time
+				return errors.NewResumeTokenExpiredError()
+			}
+			if _, ok := feed.feedExecutor.GetChanges(shardID, pos.Sequence, 0); !ok {
+				// The changes from the position have been discarded
+				return errors.NewResumeTokenExpiredError()
+			}
+			sub.positions[shardID] = pos.Sequence
+		}
+	} else {
+		// We take a snapshot of the materialized view with nothing being processed, so the snapshot contains exactly
+		// the changes before the sequences we start at
+		err := feed.tableExecutor.RunLocked(func() error {
+			if err := feed.feedExecutor.WaitForNoUncommittedBatches(); err != nil {
+				return errors.WithStack(err)
+			}
+			snapshot, err := p.cluster.CreateSnapshot()
+			if err != nil {
+				return errors.WithStack(err)
+			}
+			sub.snapshot = snapshot
+			for _, shardID := range feed.shardIDs {
+				sub.positions[shardID] = feed.feedExecutor.NextSequence(shardID)
+			}
+			return nil
+		})
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	if !feed.addSubscription(sub) {
+		if sub.snapshot != nil {
+			sub.snapshot.Close()
+		}
+		return errors.NewUnknownMaterializedViewError(msg.SchemaName, msg.MvName)
+	}
+	p.feedSubscriptions.Store(sub.id, sub)
+	// Any changes which have been committed since the positions need to be sent
+	sub.notify()
+	go sub.run()
+	return nil
+}
+
+func (p *Engine) handleChangeFeedUnsubscribe(msg *notifications.ChangeFeedUnsubscribe) {
+	s, ok := p.feedSubscriptions.Load(msg.SubscriptionId)
+	if !ok {
+		return
+	}
+	sub, ok := s.(*feedSubscription)
+	if !ok {
+		panic("not a *feedSubscription")
+	}
+	sub.close("")
+}
+
+func (p *Engine) getOrCreateChangeFeed(mvInfo *common.MaterializedViewInfo) (*changeFeed, error) {
+	p.changeFeedsLock.Lock()
+	defer p.changeFeedsLock.Unlock()
+	mvID := mvInfo.TableInfo.ID
+	if feed, ok := p.changeFeeds[mvID]; ok {
+		return feed, nil
+	}
+	mv, err := p.GetMaterializedView(mvID)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	schedulers, err := p.GetLocalLeaderSchedulers()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	shardIDs := make([]uint64, 0, len(schedulers))
+	for shardID := range schedulers {
+		shardIDs = append(shardIDs, shardID)
+	}
+	sort.Slice(shardIDs, func(i, j int) bool { return shardIDs[i] < shardIDs[j] })
+	feed := &changeFeed{
+		p:             p,
+		mvInfo:        mvInfo,
+		tableExecutor: mv.TableExecutor(),
+		shardIDs:      shardIDs,
+		subscriptions: make(map[string]*feedSubscription),
+	}
+	// The epoch distinguishes the sequences of this feed from those of any previous feed for the materialized view
+	epoch := uint64(time.Now().UTC().UnixNano())
+	feed.feedExecutor = exec.NewChangeFeedExecutor(mvInfo.TableInfo, epoch, feed.changesCommitted)
+	feed.tableExecutor.AddConsumingNode(changeFeedConsumerName, feed.feedExecutor)
+	feed.startRetentionTimer()
+	p.changeFeeds[mvID] = feed
+	return feed, nil
+}
+
+// removeChangeFeed closes the change feed of a materialized view, if there is one, and fails its subscriptions
+func (p *Engine) removeChangeFeed(mvID uint64, errMsg string) {
+	p.changeFeedsLock.Lock()
+	feed, ok := p.changeFeeds[mvID]
+	delete(p.changeFeeds, mvID)
+	p.changeFeedsLock.Unlock()
+	if ok {
+		feed.close(errMsg)
+	}
+}
+
+// closeChangeFeeds closes all the change feeds on this node, when it is stopped
+func (p *Engine) closeChangeFeeds() {
+	p.changeFeedsLock.Lock()
+	feeds := p.changeFeeds
+	clients := p.subscriptionClients
+	p.changeFeeds = make(map[uint64]*changeFeed)
+	p.subscriptionClients = make(map[int]remoting.Client)
+	p.changeFeedsLock.Unlock()
+	for _, feed := range feeds {
+		feed.close("The node has been stopped")
+	}
+	for _, client := range clients {
+		if err := client.Stop(); err != nil {
+			log.Warnf("failed to stop subscription client %v", err)
+		}
+	}
+}
+
+func (c *changeFeed) addSubscription(sub *feedSubscription) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.closed {
+		return false
+	}
+	c.subscriptions[sub.id] = sub
+	if c.retentionTimer != nil {
+		c.retentionTimer.Stop()
+		c.retentionTimer = nil
+	}
+	return true
+}
+
+func (c *changeFeed) removeSubscription(sub *feedSubscription) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	delete(c.subscriptions, sub.id)
+	if len(c.subscriptions) == 0 && !c.closed {
+		c.startRetentionTimer()
+	}
+}
+
+// startRetentionTimer removes the feed if it still has no subscriptions once the retention time is up
+func (c *changeFeed) startRetentionTimer() {
+	c.retentionTimer = time.AfterFunc(changeFeedRetention, func() {
+		c.p.changeFeedsLock.Lock()
+		defer c.p.changeFeedsLock.Unlock()
+		c.lock.Lock()
+		if len(c.subscriptions) > 0 || c.closed {
+			c.lock.Unlock()
+			return
+		}
+		c.closed = true
+		c.lock.Unlock()
+		c.tableExecutor.RemoveConsumingNode(changeFeedConsumerName)
+		if c.p.changeFeeds[c.mvInfo.TableInfo.ID] == c {
+			delete(c.p.changeFeeds, c.mvInfo.TableInfo.ID)
+		}
+	})
+}
+
+func (c *changeFeed) close(errMsg string) {
+	c.lock.Lock()
+	c.closed = true
+	if c.retentionTimer != nil {
+		c.retentionTimer.Stop()
+		c.retentionTimer = nil
+	}
+	subs := make([]*feedSubscription, 0, len(c.subscriptions))
+	for _, sub := range c.subscriptions {
+		subs = append(subs, sub)
+	}
+	c.lock.Unlock()
+	c.tableExecutor.RemoveConsumingNode(changeFeedConsumerName)
+	for _, sub := range subs {
+		sub.close(errMsg)
+	}
+}
+
+// changesCommitted is called from the committed callback of a batch so it must not block
+func (c *changeFeed) changesCommitted(shardID uint64) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for _, sub := range c.subscriptions {
+		sub.notify()
+	}
+}
+
+func (s *feedSubscription) notify() {
+	select {
+	case s.notifyCh <- struct{}{}:
+	default:
+		// Already notified
+	}
+}
+
+// close stops the subscription. If errMsg is not empty it is sent to the subscribing node.
+func (s *feedSubscription) close(errMsg string) {
+	s.closeOnce.Do(func() {
+		s.errLock.Lock()
+		s.errMsg = errMsg
+		s.errLock.Unlock()
+		close(s.closeCh)
+	})
+}
+
+func (s *feedSubscription) run() {
+	defer func() {
+		s.feed.removeSubscription(s)
+		s.feed.p.feedSubscriptions.Delete(s.id)
+	}()
+	if s.snapshot != nil {
+		err := s.sendSnapshot()
+		s.snapshot.Close()
+		if err != nil {
+			s.fail(err)
+			return
+		}
+	}
+	for {
+		select {
+		case <-s.closeCh:
+			s.errLock.Lock()
+			errMsg := s.errMsg
+			s.errLock.Unlock()
+			if errMsg != "" {
+				s.sendError(errMsg)
+			}
+			return
+		case <-s.notifyCh:
+		}
+		if err := s.sendChanges(); err != nil {
+			s.fail(err)
+			return
+		}
+	}
+}
+
+func (s *feedSubscription) fail(err error) {
+	log.Warnf("change feed subscription %s failed %v", s.id, err)
+	var perr errors.PranaError
+	if errors.As(err, &perr) {
+		s.sendError(perr.Msg)
+	} else {
+		s.sendError("failed to send changes")
+	}
+	s.close("")
+}
+
+func (s *feedSubscription) sendError(errMsg string) {
+	if err := s.send(&notifications.ChangeFeedEvents{Error: errMsg}); err != nil {
+		// Best effort - the subscription might have already been closed on the subscribing node
+		log.Debugf("failed to send error to change feed subscription %s %v", s.id, err)
+	}
+}
+
+// sendSnapshot sends the rows of the materialized view in the snapshot, for each shard
+func (s *feedSubscription) sendSnapshot() error {
+	tableID := s.feed.mvInfo.TableInfo.ID
+	for _, shardID := range s.feed.shardIDs {
+		startPrefix := table.EncodeTableKeyPrefix(tableID, shardID, 16)
+		endPrefix := table.EncodeTableKeyPrefix(tableID+1, shardID, 16)
+		for {
+			kvPairs, err := s.feed.p.cluster.LocalScanWithSnapshot(s.snapshot, startPrefix, endPrefix, changeFeedMaxBatchSize)
+			if err != nil {
+				return errors.WithStack(err)
+			}
+			complete := len(kvPairs) < changeFeedMaxBatchSize
+			events := &notifications.ChangeFeedEvents{
+				ShardId:          shardID,
+				Epoch:            s.feed.feedExecutor.Epoch,
+				SnapshotRows:     make([][]byte, len(kvPairs)),
+				SnapshotComplete: complete,
+				SnapshotSequence: s.positions[shardID],
+			}
+			for i, kvPair := range kvPairs {
+				events.SnapshotRows[i] = kvPair.Value
+			}
+			if err := s.send(events); err != nil {
+				return errors.WithStack(err)
+			}
+			if complete {
+				break
+			}
+			startPrefix = common.IncrementBytesBigEndian(kvPairs[len(kvPairs)-1].Key)
+		}
+	}
+	return nil
+}
+
+// sendChanges sends the changes that have been committed since the ones that were last sent, for each shard
+func (s *feedSubscription) sendChanges() error {
+	for _, shardID := range s.feed.shardIDs {
+		for {
+			changes, ok := s.feed.feedExecutor.GetChanges(shardID, s.positions[shardID], changeFeedMaxBatchSize)
+			if !ok {
+				return errors.NewSubscriptionFailedError("The subscriber has fallen too far behind")
+			}
+			if len(changes) == 0 {
+				break
+			}
+			events := &notifications.ChangeFeedEvents{
+				ShardId: shardID,
+				Epoch:   s.feed.feedExecutor.Epoch,
+				Changes: make([]*notifications.ChangeFeedChange, len(changes)),
+			}
+			for i, change := range changes {
+				events.Changes[i] = &notifications.ChangeFeedChange{
+					Sequence:    change.Sequence,
+					PreviousRow: change.PreviousRow,
+					CurrentRow:  change.CurrentRow,
+				}
+			}
+			if err := s.send(events); err != nil {
+				return errors.WithStack(err)
+			}
+			s.positions[shardID] = changes[len(changes)-1].Sequence + 1
+		}
+	}
+	return nil
+}
+
+func (s *feedSubscription) send(events *notifications.ChangeFeedEvents) error {
+	events.SubscriptionId = s.id
+	p := s.feed.p
+	if s.nodeID == p.cluster.GetNodeID() {
+		return p.handleChangeFeedEvents(events)
+	}
+	client, err := p.getSubscriptionClient(s.nodeID)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	_, err = client.SendRequest(events, changeFeedSendTimeout)
+	return errors.WithStack(err)
+}
+
+// getSubscriptionClient returns the client used to send events to subscriptions on another node
+func (p *Engine) getSubscriptionClient(nodeID int) (remoting.Client, error) {
+	p.changeFeedsLock.Lock()
+	defer p.changeFeedsLock.Unlock()
+	client, ok := p.subscriptionClients[nodeID]
+	if !ok {
+		client = remoting.NewClient(p.cfg.NotifListenAddresses[nodeID])
+		if err := client.Start(); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		p.subscriptionClients[nodeID] = client
+	}
+	return client, nil
+}
+
+type changeFeedMessageHandler struct {
+	p *Engine
+}
+
+// GetChangeFeedMessageHandler returns the handler for the messages which nodes send each other for subscriptions
+func (p *Engine) GetChangeFeedMessageHandler() remoting.ClusterMessageHandler {
+	return &changeFeedMessageHandler{p: p}
+}
+
+func (c *changeFeedMessageHandler) HandleMessage(notification remoting.ClusterMessage) (remoting.ClusterMessage, error) {
+	switch msg := notification.(type) {
+	case *notifications.ChangeFeedSubscribe:
+		return nil, c.p.handleChangeFeedSubscribe(msg)
+	case *notifications.ChangeFeedUnsubscribe:
+		c.p.handleChangeFeedUnsubscribe(msg)
+		return nil, nil
+	case *notifications.ChangeFeedEvents:
+		return nil, c.p.handleChangeFeedEvents(msg)
+	default:
+		return nil, errors.Errorf("unexpected change feed message %v", notification)
+	}
+}
@@ -4,10 +4,12 @@ import (
 	"fmt"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/squareup/pranadb/avrolib"
 	"github.com/squareup/pranadb/failinject"
 	"github.com/squareup/pranadb/push/util"
 	"go.uber.org/ratelimit"
 	"math/rand"
+	"sort"
 	"sync"
 	"time"
 
@@ -20,6 +22,7 @@ import (
 	"github.com/squareup/pranadb/protolib"
 	"github.com/squareup/pranadb/push/sched"
 	"github.com/squareup/pranadb/push/source"
+	"github.com/squareup/pranadb/remoting"
 
 	"github.com/squareup/pranadb/meta"
 	"github.com/squareup/pranadb/table"
@@ -38,7 +41,9 @@ type Engine struct {
 	started                   bool
 	schedulers                map[uint64]*sched.ShardScheduler
 	sources                   map[uint64]*source.Source
+	userTables                map[uint64]*UserTable
 	materializedViews         map[uint64]*MaterializedView
+	sinks                     map[uint64]*exec.SinkExecutor
 	remoteConsumers           sync.Map
 	localLeaderShards         []uint64
 	cluster                   cluster.Cluster
@@ -48,10 +53,17 @@ type Engine struct {
 	cfg                       *conf.Config
 	queryExec                 common.SimpleQueryExec
 	protoRegistry             protolib.Resolver
+	avroRegistry              avrolib.Resolver
 	readyToReceive            common.AtomicBool
 	processBatchTimeHistogram metrics.Observer
 	globalRateLimiter         ratelimit.Limiter
 	failInject                failinject.Injector
+	notifClient               remoting.Client
+	changeFeedsLock           sync.Mutex
+	changeFeeds               map[uint64]*changeFeed
+	subscriptionClients       map[int]remoting.Client
+	feedSubscriptions         sync.Map
+	subscriptions             sync.Map
 }
 
 var (
@@ -80,7 +92,8 @@ type remoteRowsHandler interface {
 }
 
 func NewPushEngine(cluster cluster.Cluster, sharder *sharder.Sharder, meta *meta.Controller, cfg *conf.Config,
-	queryExec common.SimpleQueryExec, registry protolib.Resolver, failInject failinject.Injector) *Engine {
+	queryExec common.SimpleQueryExec, registry protolib.Resolver, avroRegistry avrolib.Resolver,
+	failInject failinject.Injector) *Engine {
 	// We limit the ingest rate of the source to this value - this prevents the node getting overloaded which can result
 	// in unstable behaviour
 	var rl ratelimit.Limiter
@@ -97,6 +110,7 @@ func NewPushEngine(cluster cluster.Cluster, sharder *sharder.Sharder, meta *meta
 		cfg:                       cfg,
 		queryExec:                 queryExec,
 		protoRegistry:             registry,
+		avroRegistry:              avroRegistry,
 		processBatchTimeHistogram: processBatchVec.WithLabelValues(fmt.Sprintf("node-%d", cluster.GetNodeID())),
 		globalRateLimiter:         rl,
 		failInject:                failInject,
@@ -116,6 +130,11 @@ func (p *Engine) Start() error {
 	return nil
 }
 
+// SetNotifClient sets the client used to tell the other nodes about subscriptions
+func (p *Engine) SetNotifClient(notifClient remoting.Client) {
+	p.notifClient = notifClient
+}
+
 // Ready signals that the push engine is now ready to receive any incoming data
 func (p *Engine) Ready() error {
 	p.readyToReceive.Set(true)
@@ -123,6 +142,8 @@ func (p *Engine) Ready() error {
 }
 
 func (p *Engine) Stop() error {
+	p.closeSubscriptions(-1, errors.NewSubscriptionFailedError("The node has been stopped"))
+	p.closeChangeFeeds()
 	p.lock.Lock()
 	defer p.lock.Unlock()
 	if !p.started {
@@ -137,6 +158,11 @@ func (p *Engine) Stop() error {
 	for _, sh := range p.schedulers {
 		sh.Stop()
 	}
+	for _, sink := range p.sinks {
+		if err := sink.Producer().Stop(); err != nil {
+			return errors.WithStack(err)
+		}
+	}
 	p.createMaps() // Clear the internal state
 	p.started = false
 	return nil
@@ -166,6 +192,9 @@ func (p *Engine) RemoveSource(sourceInfo *common.SourceInfo) (*source.Source, er
 
 	delete(p.sources, sourceInfo.ID)
 	p.remoteConsumers.Delete(sourceInfo.ID)
+	if watermark := sourceInfo.TopicInfo.Watermark; watermark != nil {
+		p.remoteConsumers.Delete(watermark.TableID)
+	}
 
 	return src, nil
 }
@@ -204,6 +233,10 @@ func (p *Engine) GetMaterializedView(mvID uint64) (*MaterializedView, error) {
 }
 
 func (p *Engine) RemoveMV(mvID uint64) error {
+	// Any subscriptions to the materialized view are closed
+	errMsg := "The materialized view has been dropped"
+	p.closeSubscriptions(int64(mvID), errors.NewSubscriptionFailedError(errMsg))
+	p.removeChangeFeed(mvID, errMsg)
 	p.lock.Lock()
 	defer p.lock.Unlock()
 	_, ok := p.materializedViews[mvID]
@@ -264,8 +297,15 @@ func (p *Engine) RemoveIndex(indexInfo *common.IndexInfo) error {
 }
 
 func (p *Engine) getTableExecutorForIndex(indexInfo *common.IndexInfo) (*exec.TableExecutor, error) {
-	// Find the table executor for the source / mv that we are creating the index on
+	// Find the table executor for the source / table / mv that we are creating the index on
 	var te *exec.TableExecutor
+	if tableInfo, ok := p.meta.GetUserTable(indexInfo.SchemaName, indexInfo.TableName); ok {
+		ut, err := p.GetUserTable(tableInfo.ID)
+		if err != nil {
+			return nil, err
+		}
+		return ut.TableExecutor(), nil
+	}
 	srcInfo, ok := p.meta.GetSource(indexInfo.SchemaName, indexInfo.TableName)
 	if !ok {
 		mvInfo, ok := p.meta.GetMaterializedView(indexInfo.SchemaName, indexInfo.TableName)
@@ -296,6 +336,12 @@ func (p *Engine) CreateShardListener(shardID uint64) cluster.ShardListener {
 	sh.Start()
 	p.schedulers[shardID] = sh
 	p.localLeaderShards = append(p.localLeaderShards, shardID)
+	// Another node might have processed the shard since this node last did, so the sinks must read the sequences of
+	// their outboxes again, and send any messages the other node didn't
+	for sinkID, sinkExec := range p.sinks {
+		sinkExec.ResetSequences(shardID)
+		go p.scheduleSinkDelivery(sinkID, shardID)
+	}
 	return &shardListener{
 		shardID: shardID,
 		p:       p,
@@ -405,7 +451,15 @@ func (p *Engine) HandleReceivedRows(receivingShardID uint64) error {
 func (p *Engine) processReceiveBatch(batch *receiveBatch) error {
 	ctx := exec.NewExecutionContext(batch.writeBatch, true)
 	ctx.BatchSequence = batch.batchSequence
-	for entityID, rawRows := range batch.rawRows {
+	// The rows for each entity are handled in a deterministic order so the same forward sequences are generated if the
+	// batch is processed again
+	entityIDs := make([]uint64, 0, len(batch.rawRows))
+	for entityID := range batch.rawRows {
+		entityIDs = append(entityIDs, entityID)
+	}
+	sort.Slice(entityIDs, func(i, j int) bool { return entityIDs[i] < entityIDs[j] })
+	for _, entityID := range entityIDs {
+		rawRows := batch.rawRows[entityID]
 		rcVal, ok := p.remoteConsumers.Load(entityID)
 		if !ok {
 			// Does the entity exist in storage?
@@ -479,6 +533,12 @@ func (p *Engine) checkForPendingData() error {
 	for _, scheduler := range p.schedulers {
 		p.MaybeHandleRemoteBatch(scheduler)
 	}
+	// And there could be messages in the outboxes of sinks which haven't been delivered
+	for sinkID := range p.sinks {
+		for shardID := range p.schedulers {
+			go p.scheduleSinkDelivery(sinkID, shardID)
+		}
+	}
 	return nil
 }
 
@@ -571,7 +631,7 @@ func (p *Engine) CreateSource(sourceInfo *common.SourceInfo) (*source.Source, er
 	p.lock.Lock()
 	defer p.lock.Unlock()
 
-	tableExecutor := exec.NewTableExecutor(sourceInfo.TableInfo, p.cluster)
+	tableExecutor := exec.NewSourceTableExecutor(sourceInfo.TableInfo, p.cluster)
 
 	src, err := source.NewSource(
 		sourceInfo,
@@ -581,6 +641,7 @@ func (p *Engine) CreateSource(sourceInfo *common.SourceInfo) (*source.Source, er
 		p.cfg,
 		p.queryExec,
 		p.protoRegistry,
+		p.avroRegistry,
 		p,
 	)
 	if err != nil {
@@ -595,6 +656,14 @@ func (p *Engine) CreateSource(sourceInfo *common.SourceInfo) (*source.Source, er
 		RowsHandler: src.TableExecutor(),
 	}
 	p.remoteConsumers.Store(sourceInfo.TableInfo.ID, rc)
+	if watermark := sourceInfo.TopicInfo.Watermark; watermark != nil {
+		// The watermarks of the partitions of the source are forwarded to every shard
+		p.remoteConsumers.Store(watermark.TableID, &RemoteConsumer{
+			RowsFactory: common.NewRowsFactory(source.WatermarkColTypes),
+			ColTypes:    source.WatermarkColTypes,
+			RowsHandler: src.WatermarkReceiver(),
+		})
+	}
 	p.sources[sourceInfo.TableInfo.ID] = src
 	return src, nil
 }
@@ -602,8 +671,12 @@ func (p *Engine) CreateSource(sourceInfo *common.SourceInfo) (*source.Source, er
 func (p *Engine) createMaps() {
 	p.remoteConsumers = sync.Map{}
 	p.sources = make(map[uint64]*source.Source)
+	p.userTables = make(map[uint64]*UserTable)
 	p.materializedViews = make(map[uint64]*MaterializedView)
+	p.sinks = make(map[uint64]*exec.SinkExecutor)
 	p.schedulers = make(map[uint64]*sched.ShardScheduler)
+	p.changeFeeds = make(map[uint64]*changeFeed)
+	p.subscriptionClients = make(map[int]remoting.Client)
 }
 
 func (p *Engine) GetLocalLeaderSchedulers() (map[uint64]*sched.ShardScheduler, error) {
@@ -628,7 +701,8 @@ func (p *Engine) IsEmpty() bool {
 		numRecs++
 		return true
 	})
-	return len(p.sources) == 0 && len(p.materializedViews) == 0 && numRecs == 0
+	return len(p.sources) == 0 && len(p.userTables) == 0 && len(p.materializedViews) == 0 && len(p.sinks) == 0 &&
+		numRecs == 0
 }
 
 func (p *Engine) Limit() {
@@ -0,0 +1,299 @@
+package exec
+
+import (
+	"bytes"
+
+	"github.com/squareup/pranadb/aggfuncs"
+	"github.com/squareup/pranadb/cluster"
+	"github.com/squareup/pranadb/common"
+	"github.com/squareup/pranadb/errors"
+	"github.com/squareup/pranadb/table"
+)
+
+// Aggregate functions such as MIN and MAX can't be retracted using the aggregate state alone, so the aggregator keeps
+// extra state for them: the multiset of values seen for each group. This is stored in an internal table with a key of
+// group key|function index|value and a value of the count of the value. Values are encoded so the extreme value of the
+// function sorts first, so it can be found with a short scan.
+
+// RequiresExtraState returns true if any of the aggregate functions need extra state to be stored
+func (a *Aggregator) RequiresExtraState() bool {
+	for index := range a.aggFuncs {
+		if a.requiresExtraState(index) {
+			return true
+		}
+	}
+	return false
+}
+
+func (a *Aggregator) requiresExtraState(index int) bool {
+	return a.aggFuncs[index].RequiresExtraState() || a.distinct[index]
+}
+
+// extraStateType returns the type of the values in the extra state of the aggregate function, which for a distinct
+// function is the type of its argument
+func (a *Aggregator) extraStateType(index int) common.ColumnType {
+	if a.distinct[index] {
+		return a.argTypes[index]
+	}
+	return a.aggFuncs[index].ValueType()
+}
+
+// countDistinctValue updates the count of the argument value of a distinct aggregate function, and returns true if the
+// value should be aggregated, i.e. the value was not already counted, or is no longer counted if reverse is true
+func (a *Aggregator) countDistinctValue(stateHolder *aggStateHolder, index int, row *common.Row, reverse bool) (bool, error) {
+	value, null, err := a.evalArg(index, row)
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+	if null {
+		// Null values aren't counted but the function still needs to see them
+		return true, nil
+	}
+	return a.updateExtraState(stateHolder, index, value, reverse)
+}
+
+// SetExtraStateTables sets the internal tables that store the extra state for the partial and full aggregations
+func (a *Aggregator) SetExtraStateTables(partialExtraStateTableInfo *common.TableInfo, fullExtraStateTableInfo *common.TableInfo) {
+	a.PartialExtraStateTableInfo = partialExtraStateTableInfo
+	a.FullExtraStateTableInfo = fullExtraStateTableInfo
+}
+
+// updateExtraState adds the value to, or removes it from, the multiset of values of the aggregate function for the
+// group. It returns true if the value was added for the first time, or the last instance of it was removed.
+func (a *Aggregator) updateExtraState(stateHolder *aggStateHolder, index int, value interface{}, reverse bool) (bool, error) {
+	if stateHolder.extraStatePrefix == nil || value == nil || !a.requiresExtraState(index) {
+		return false, nil
+	}
+	key, err := a.encodeExtraStateValue(a.extraStateFuncPrefix(stateHolder, index), index, value)
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+	if stateHolder.valueCounts == nil {
+		stateHolder.valueCounts = make([]map[string]int64, len(a.aggFuncs))
+	}
+	counts := stateHolder.valueCounts[index]
+	if counts == nil {
+		counts = make(map[string]int64)
+		stateHolder.valueCounts[index] = counts
+	}
+	sKey := string(key)
+	count, ok := counts[sKey]
+	if !ok {
+		countBytes, err := a.storage.LocalGet(key)
+		if err != nil {
+			return false, errors.WithStack(err)
+		}
+		if countBytes != nil {
+			c, _ := common.ReadUint64FromBufferLE(countBytes, 0)
+			count = int64(c)
+		}
+	}
+	if reverse {
+		count--
+	} else {
+		count++
+	}
+	counts[sKey] = count
+	return (reverse && count == 0) || (!reverse && count == 1), nil
+}
+
+// storeExtraState recalculates the values of the aggregate functions which need it from their extra state, and writes
+// the changes to the extra state
+func (a *Aggregator) storeExtraState(stateHolders *stateHolders, writeBatch *cluster.WriteBatch) error {
+	for _, stateHolder := range stateHolders.holders {
+		if stateHolder.extraStatePrefix == nil {
+			continue
+		}
+		for index := range a.aggFuncs {
+			if !a.requiresExtraState(index) {
+				continue
+			}
+			if stateHolder.aggState.NeedsRecalc(index) {
+				if err := a.recalcFromExtraState(stateHolder, index); err != nil {
+					return errors.WithStack(err)
+				}
+			}
+		}
+		for _, counts := range stateHolder.valueCounts {
+			for sKey, count := range counts {
+				if count > 0 {
+					writeBatch.AddPut([]byte(sKey), common.AppendUint64ToBufferLE(nil, uint64(count)))
+				} else {
+					writeBatch.AddDelete([]byte(sKey))
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// recalcFromExtraState sets the value of the aggregate function to the first value in its multiset, taking into
+// account the changes to the multiset which haven't been written yet
+func (a *Aggregator) recalcFromExtraState(stateHolder *aggStateHolder, index int) error {
+	var counts map[string]int64
+	if stateHolder.valueCounts != nil {
+		counts = stateHolder.valueCounts[index]
+	}
+	// At most the removed values can be skipped in storage, any values after those come after the first remaining one
+	removed := 0
+	for _, count := range counts {
+		if count <= 0 {
+			removed++
+		}
+	}
+	prefix := a.extraStateFuncPrefix(stateHolder, index)
+	pairs, err := a.storage.LocalScan(prefix, common.IncrementBytesBigEndian(prefix), removed+1)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	var first []byte
+	for _, pair := range pairs {
+		if count, ok := counts[string(pair.Key)]; ok && count <= 0 {
+			continue
+		}
+		first = pair.Key
+		break
+	}
+	for sKey, count := range counts {
+		key := common.StringToByteSliceZeroCopy(sKey)
+		if count > 0 && (first == nil || bytes.Compare(key, first) < 0) {
+			first = key
+		}
+	}
+	aggState := stateHolder.aggState
+	if first == nil {
+		aggState.SetNull(index)
+		return nil
+	}
+	return a.decodeExtraStateValue(first[len(prefix):], index, stateHolder)
+}
+
+func (a *Aggregator) extraStateFuncPrefix(stateHolder *aggStateHolder, index int) []byte {
+	prefix := make([]byte, 0, len(stateHolder.extraStatePrefix)+2+8)
+	prefix = append(prefix, stateHolder.extraStatePrefix...)
+	return common.AppendUint16ToBufferBE(prefix, uint16(index))
+}
+
+// extraStatePrefix returns the prefix of the keys of the extra state for the group with the given key
+func extraStatePrefix(tableInfo *common.TableInfo, shardID uint64, keyBytes []byte) []byte {
+	prefix := table.EncodeTableKeyPrefix(tableInfo.ID, shardID, len(keyBytes)+2)
+	// We ignore the first 16 bytes of the group key as this is shard-id|table-id
+	return append(prefix, keyBytes[16:]...)
+}
+
+// encodeExtraStateValue encodes the value so the values of the function sort in order, with the greatest values first
+// for MAX. Strings are escaped and terminated so that no encoded value is a prefix of another.
+func (a *Aggregator) encodeExtraStateValue(buffer []byte, index int, value interface{}) ([]byte, error) {
+	start := len(buffer)
+	colType := a.extraStateType(index)
+	switch colType.Type {
+	case common.TypeTinyInt, common.TypeInt, common.TypeBigInt, common.TypeBoolean:
+		buffer = common.KeyEncodeInt64(buffer, value.(int64))
+	case common.TypeDouble:
+		buffer = common.KeyEncodeFloat64(buffer, value.(float64))
+	case common.TypeVarchar, common.TypeVarbinary:
+		str := value.(string)
+		for i := 0; i < len(str); i++ {
+			buffer = append(buffer, str[i])
+			if str[i] == 0 {
+				buffer = append(buffer, 0xFF)
+			}
+		}
+		buffer = append(buffer, 0, 1)
+	case common.TypeDecimal:
+		var err error
+		buffer, err = common.KeyEncodeDecimal(buffer, value.(common.Decimal), colType.DecPrecision, colType.DecScale)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+	case common.TypeTimestamp, common.TypeDate:
+		var err error
+		buffer, err = common.KeyEncodeTimestamp(buffer, value.(common.Timestamp))
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+	default:
+		return nil, errors.Errorf("unexpected column type %d", colType.Type)
+	}
+	if a.isDescending(index) {
+		for i := start; i < len(buffer); i++ {
+			buffer[i] = ^buffer[i]
+		}
+	}
+	return buffer, nil
+}
+
+func (a *Aggregator) decodeExtraStateValue(encoded []byte, index int, stateHolder *aggStateHolder) error {
+	buffer := encoded
+	if a.isDescending(index) {
+		buffer = make([]byte, len(encoded))
+		for i, b := range encoded {
+			buffer[i] = ^b
+		}
+	}
+	aggState := stateHolder.aggState
+	colType := a.aggFuncs[index].ValueType()
+	switch colType.Type {
+	case common.TypeTinyInt, common.TypeInt, common.TypeBigInt, common.TypeBoolean:
+		u, _ := common.ReadUint64FromBufferBE(buffer, 0)
+		aggState.SetInt64(index, int64(u^common.SignBitMask))
+	case common.TypeDouble:
+		f, _ := common.KeyDecodeFloat64(buffer, 0)
+		aggState.SetFloat64(index, f)
+	case common.TypeVarchar, common.TypeVarbinary:
+		str := make([]byte, 0, len(buffer))
+		for i := 0; i < len(buffer)-2; i++ {
+			str = append(str, buffer[i])
+			if buffer[i] == 0 {
+				// Skip the escape byte
+				i++
+			}
+		}
+		aggState.SetString(index, string(str))
+	case common.TypeDecimal:
+		dec, _, err := common.ReadDecimalFromBuffer(buffer, 0, colType.DecPrecision, colType.DecScale)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		return aggState.SetDecimal(index, dec)
+	case common.TypeTimestamp, common.TypeDate:
+		ts, _, err := common.ReadTimestampFromBufferBE(buffer, 0, colType.FSP)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		return aggState.SetTimestamp(index, ts)
+	default:
+		return errors.Errorf("unexpected column type %d", colType.Type)
+	}
+	return nil
+}
+
+func (a *Aggregator) isDescending(index int) bool {
+	_, ok := a.aggFuncs[index].(*aggfuncs.MaxAggregateFunction)
+	return ok
+}
+
+// extraStateValue returns the value of the aggregate function in the state, or nil if it is null
+func (a *Aggregator) extraStateValue(aggState *aggfuncs.AggState, index int) (interface{}, error) {
+	if aggState.IsNull(index) {
+		return nil, nil
+	}
+	switch colType := a.aggFuncs[index].ValueType(); colType.Type {
+	case common.TypeTinyInt, common.TypeInt, common.TypeBigInt, common.TypeBoolean:
+		return aggState.GetInt64(index), nil
+	case common.TypeDouble:
+		return aggState.GetFloat64(index), nil
+	case common.TypeVarchar, common.TypeVarbinary:
+		return aggState.GetString(index), nil
+	case common.TypeDecimal:
+		return aggState.GetDecimal(index), nil
+	case common.TypeTimestamp, common.TypeDate:
+		ts, err := aggState.GetTimestamp(index)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		return ts, nil
+	default:
+		return nil, errors.Errorf("unexpected column type %d", colType.Type)
+	}
+}
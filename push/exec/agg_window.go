@@ -0,0 +1,153 @@
+package exec
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/squareup/pranadb/cluster"
+	"github.com/squareup/pranadb/common"
+	"github.com/squareup/pranadb/errors"
+	"github.com/squareup/pranadb/metrics"
+	"github.com/squareup/pranadb/table"
+)
+
+// TumblingWindow describes an aggregation grouped by a tumbling window of event time, where each row falls in the
+// window of the given size which contains its time.
+//
+// The windows are closed by the watermark of the source the aggregation reads from, which every shard receives and
+// stores, so windows can only be used on a source with a watermark column. Once the watermark passes the end of a
+// window plus the allowed lateness the window is closed: its aggregate state is expired, and any rows arriving later
+// for it are dropped. The output rows of closed windows are not affected. Rows with a null time don't fall in any
+// window and are also dropped.
+type TumblingWindow struct {
+	TimeCol  int // The index of the time column in the child
+	Size     time.Duration
+	Lateness time.Duration
+
+	lateRows metrics.Counter
+}
+
+var lateRowsVec = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "pranadb_late_rows_total",
+	Help: "counter for number of rows dropped by windowed aggregations as their windows had closed, segmented by table",
+}, []string{"table"})
+
+// SetWindow makes the aggregation a windowed aggregation. The window must be the first group by expression, so the
+// keys of the aggregate state are ordered by window. The watermark each shard has expired its state to is stored in an
+// internal table.
+func (a *Aggregator) SetWindow(window *TumblingWindow, watermarkTableInfo *common.TableInfo) error {
+	if len(a.groupByExprs) == 0 {
+		return errors.Errorf("windowed aggregation has no group by expressions")
+	}
+	if a.groupByRowsFactory.ColumnTypes[0].Type != common.TypeTimestamp {
+		return errors.Errorf("window must be the first group by expression")
+	}
+	a.window = window
+	a.window.lateRows = lateRowsVec.WithLabelValues(a.FullAggTableInfo.Name)
+	a.WatermarkTableInfo = watermarkTableInfo
+	// Late rows can only be dropped, and the aggregate state of a window expired, by the shard which owns the window, so
+	// the rows are aggregated in a single phase
+	a.singlePhase = true
+	return nil
+}
+
+// windowWatermark is the watermark a shard has expired its state to. It is zero if the shard hasn't received a
+// watermark from the source.
+type windowWatermark struct {
+	key       []byte
+	watermark time.Time
+}
+
+// loadWatermark loads the watermark the shard has expired its state to
+func loadWatermark(storage cluster.Cluster, watermarkTableInfo *common.TableInfo, shardID uint64) (*windowWatermark, error) {
+	key := table.EncodeTableKeyPrefix(watermarkTableInfo.ID, shardID, 16)
+	wm := &windowWatermark{key: key}
+	value, err := storage.LocalGet(key)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if value == nil {
+		return wm, nil
+	}
+	ts, _, err := common.ReadTimestampFromBufferBE(value, 0, 6)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if wm.watermark, err = ts.GoTime(time.UTC); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return wm, nil
+}
+
+// advance stores the watermark of the source as the watermark the shard has expired its state to. It returns false if
+// the shard's state is already expired to it.
+func (wm *windowWatermark) advance(watermark time.Time, writeBatch *cluster.WriteBatch) (bool, error) {
+	if !watermark.After(wm.watermark) {
+		return false, nil
+	}
+	value, err := common.KeyEncodeTimestamp(nil, common.NewTimestampFromGoTime(watermark))
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+	writeBatch.AddPut(wm.key, value)
+	wm.watermark = watermark
+	return true, nil
+}
+
+// acceptRow returns false if the row has no time or its window has closed
+func (a *Aggregator) acceptRow(row *common.Row, wm *windowWatermark) (bool, error) {
+	if row == nil || row.IsNull(a.window.TimeCol) {
+		return false, nil
+	}
+	ts := row.GetTimestamp(a.window.TimeCol)
+	gt, err := ts.GoTime(time.UTC)
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+	if wm.watermark.IsZero() {
+		return true, nil
+	}
+	// The window is closed if the watermark has reached its end plus the allowed lateness
+	windowEnd := gt.Truncate(a.window.Size).Add(a.window.Size)
+	if !windowEnd.Add(a.window.Lateness).After(wm.watermark) {
+		a.window.lateRows.Inc()
+		return false, nil
+	}
+	return true, nil
+}
+
+// closeWindows expires the state of the shard for the windows the watermark of the source has closed
+func (a *Aggregator) closeWindows(sourceWatermark time.Time, writeBatch *cluster.WriteBatch) error {
+	wm, err := loadWatermark(a.storage, a.WatermarkTableInfo, writeBatch.ShardID)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if advanced, err := wm.advance(sourceWatermark, writeBatch); err != nil || !advanced {
+		return err
+	}
+
+	// Windows which started up to here are closed
+	lastClosed := common.NewTimestampFromGoTime(wm.watermark.Add(-a.window.Size - a.window.Lateness))
+	tableInfos := []*common.TableInfo{a.FullAggTableInfo}
+	if a.FullExtraStateTableInfo != nil {
+		tableInfos = append(tableInfos, a.FullExtraStateTableInfo)
+	}
+	for _, tableInfo := range tableInfos {
+		prefix := table.EncodeTableKeyPrefix(tableInfo.ID, writeBatch.ShardID, 24)
+		end, err := common.KeyEncodeTimestamp(append([]byte{}, prefix...), lastClosed)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		end = common.IncrementBytesBigEndian(end)
+		pairs, err := a.storage.LocalScan(prefix, end, -1)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		for _, pair := range pairs {
+			writeBatch.AddDelete(pair.Key)
+		}
+	}
+	return nil
+}
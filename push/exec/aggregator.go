@@ -1,6 +1,8 @@
 package exec
 
 import (
+	"bytes"
+
 	"github.com/squareup/pranadb/aggfuncs"
 	"github.com/squareup/pranadb/cluster"
 	"github.com/squareup/pranadb/common"
@@ -15,16 +17,29 @@ type Aggregator struct {
 	aggFuncs            []aggfuncs.AggregateFunction
 	PartialAggTableInfo *common.TableInfo
 	FullAggTableInfo    *common.TableInfo
-	groupByCols         []int // The group by column indexes in the child
-	storage             cluster.Cluster
-	sharder             *sharder.Sharder
-}
-
-type AggregateFunctionInfo struct {
-	FuncType   aggfuncs.AggFunctionType
-	Distinct   bool
-	ArgExpr    *common.Expression
-	ReturnType common.ColumnType
+	// The tables for the extra state of the aggregate functions, which are nil unless a function requires extra state
+	PartialExtraStateTableInfo *common.TableInfo
+	FullExtraStateTableInfo    *common.TableInfo
+	// The group by expressions are evaluated on the child rows to get the group key, which is encoded the same way as
+	// the key columns of the rows of the aggregation
+	groupByExprs       []*common.Expression
+	groupByRowsFactory *common.RowsFactory
+	// The rows stored in the aggregate tables have a column for each aggregate function, followed by a column for the
+	// intermediate state of each function which needs it
+	stateColTypes    []common.ColumnType
+	stateRowsFactory *common.RowsFactory
+	intermediateCols []int // The intermediate state column index for each aggregate function, or -1
+	// Distinct aggregate functions keep a count of each value in the extra state, and only aggregate a value when it is
+	// first added or last removed. Distinct values can't be combined from partial aggregations on different shards, so
+	// if there are any the child rows are forwarded to the shard which owns the group and aggregated there instead.
+	distinct    []bool
+	argTypes    []common.ColumnType
+	singlePhase bool
+	// The window of a windowed aggregation, and the table which stores how far each shard has expired it, or nil
+	window             *TumblingWindow
+	WatermarkTableInfo *common.TableInfo
+	storage            cluster.Cluster
+	sharder            *sharder.Sharder
 }
 
 type aggStateHolder struct {
@@ -34,64 +49,141 @@ type aggStateHolder struct {
 	rowBytes        []byte
 	initialRow      *common.Row
 	row             *common.Row
+	// The prefix of the keys of the extra state of the group, and the changed counts of the values in it, by function
+	extraStatePrefix []byte
+	valueCounts      []map[string]int64
 }
 
-func NewAggregator(pkCols []int, aggFunctions []*AggregateFunctionInfo, partialAggTableInfo *common.TableInfo,
-	fullAggTableInfo *common.TableInfo, groupByCols []int, storage cluster.Cluster, sharder *sharder.Sharder) (*Aggregator, error) {
+func NewAggregator(pkCols []int, aggFunctions []*aggfuncs.AggregateFunctionInfo, partialAggTableInfo *common.TableInfo,
+	fullAggTableInfo *common.TableInfo, groupByExprs []*common.Expression, storage cluster.Cluster, sharder *sharder.Sharder) (*Aggregator, error) {
 
 	colTypes := make([]common.ColumnType, len(aggFunctions))
 	for i, aggFunc := range aggFunctions {
 		colTypes[i] = aggFunc.ReturnType
 	}
-	partialAggTableInfo.ColumnTypes = colTypes
-	fullAggTableInfo.ColumnTypes = colTypes
+	aggFuncs, err := createAggFunctions(aggFunctions, colTypes)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	stateColTypes := colTypes
+	intermediateCols := make([]int, len(aggFuncs))
+	for i, aggFunc := range aggFuncs {
+		intermediateCols[i] = -1
+		if aggFunc.RequiresIntermediateState() {
+			if len(stateColTypes) == len(colTypes) {
+				stateColTypes = append([]common.ColumnType{}, colTypes...)
+			}
+			intermediateCols[i] = len(stateColTypes)
+			stateColTypes = append(stateColTypes, common.VarcharColumnType)
+		}
+	}
+	partialAggTableInfo.ColumnTypes = stateColTypes
+	fullAggTableInfo.ColumnTypes = stateColTypes
+	distinct := make([]bool, len(aggFuncs))
+	argTypes := make([]common.ColumnType, len(aggFuncs))
+	singlePhase := false
+	for i, aggFunc := range aggFunctions {
+		argTypes[i] = aggFunc.ArgType
+		// The extreme value is the same whether or not values are distinct
+		switch aggFunc.FuncType {
+		case aggfuncs.FirstRowAggregateFunctionType, aggfuncs.MinAggregateFunctionType, aggfuncs.MaxAggregateFunctionType:
+			continue
+		}
+		if aggFunc.Distinct {
+			if aggFunc.ArgExpr == nil {
+				return nil, errors.Errorf("distinct aggregate function has no argument")
+			}
+			distinct[i] = true
+			singlePhase = true
+		}
+	}
+	groupByTypes := make([]common.ColumnType, len(groupByExprs))
+	for i, pkCol := range pkCols {
+		groupByTypes[i] = colTypes[pkCol]
+	}
 	rf := common.NewRowsFactory(colTypes)
 	pushBase := pushExecutorBase{
 		colTypes:    colTypes,
 		keyCols:     pkCols,
+		colsVisible: aggColsVisible(aggFunctions),
 		rowsFactory: rf,
 	}
-	aggFuncs, err := createAggFunctions(aggFunctions, colTypes)
-	if err != nil {
-		return nil, errors.WithStack(err)
-	}
 	return &Aggregator{
 		pushExecutorBase:    pushBase,
 		aggFuncs:            aggFuncs,
 		PartialAggTableInfo: partialAggTableInfo,
 		FullAggTableInfo:    fullAggTableInfo,
-		groupByCols:         groupByCols,
+		groupByExprs:        groupByExprs,
+		groupByRowsFactory:  common.NewRowsFactory(groupByTypes),
+		stateColTypes:       stateColTypes,
+		stateRowsFactory:    common.NewRowsFactory(stateColTypes),
+		intermediateCols:    intermediateCols,
+		distinct:            distinct,
+		argTypes:            argTypes,
+		singlePhase:         singlePhase,
 		storage:             storage,
 		sharder:             sharder,
 	}, nil
 }
 
+// aggColsVisible returns which output columns of the aggregate functions are visible, or nil if they all are. Any hidden
+// functions come after the visible ones.
+func aggColsVisible(aggFunctions []*aggfuncs.AggregateFunctionInfo) []bool {
+	var colsVisible []bool
+	for i, aggFunc := range aggFunctions {
+		if aggFunc.Hidden && colsVisible == nil {
+			colsVisible = make([]bool, len(aggFunctions))
+			for j := 0; j < i; j++ {
+				colsVisible[j] = true
+			}
+		}
+	}
+	return colsVisible
+}
+
 type stateHolders struct {
-	holdersMap map[string]*aggStateHolder
-	holders    []*aggStateHolder
+	holdersMap      map[string]*aggStateHolder
+	holders         []*aggStateHolder
+	extraStateTable *common.TableInfo
+	shardID         uint64
 }
 
 func (a *Aggregator) HandleRows(rowsBatch RowsBatch, ctx *ExecutionContext) error {
+	if a.window != nil && !ctx.Watermark.IsZero() {
+		if err := a.closeWindows(ctx.Watermark, ctx.WriteBatch); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	if a.singlePhase {
+		return a.forwardRows(rowsBatch, ctx)
+	}
 
 	// We first calculate the partial aggregations locally
-	holders := &stateHolders{holdersMap: make(map[string]*aggStateHolder)}
+	holders := &stateHolders{
+		holdersMap:      make(map[string]*aggStateHolder),
+		extraStateTable: a.PartialExtraStateTableInfo,
+		shardID:         ctx.WriteBatch.ShardID,
+	}
 	numRows := rowsBatch.Len()
-	readRows := a.rowsFactory.NewRows(numRows)
+	readRows := a.stateRowsFactory.NewRows(numRows)
 	for i := 0; i < numRows; i++ {
 		prevRow := rowsBatch.PreviousRow(i)
 		currentRow := rowsBatch.CurrentRow(i)
-		if err := a.calcPartialAggregations(prevRow, currentRow, readRows, holders, ctx.WriteBatch.ShardID); err != nil {
+		if err := a.calcPartialAggregations(prevRow, currentRow, readRows, holders, ctx.WriteBatch.ShardID, a.PartialAggTableInfo.ID); err != nil {
 			return err
 		}
 	}
 
 	// Store the results locally
+	if err := a.storeExtraState(holders, ctx.WriteBatch); err != nil {
+		return errors.WithStack(err)
+	}
 	if err := a.storeAggregateResults(holders, ctx.WriteBatch); err != nil {
 		return errors.WithStack(err)
 	}
 
 	// We send the partial aggregation results to the shard that owns the key
-	for i, stateHolder := range holders.holders {
+	for _, stateHolder := range holders.holders {
 		if stateHolder.aggState.IsChanged() {
 			// We ignore the first 16 bytes as this is shard-id|table-id
 			remoteShardID, err := a.sharder.CalculateShard(sharder.ShardTypeHash, stateHolder.keyBytes[16:])
@@ -99,12 +191,7 @@ func (a *Aggregator) HandleRows(rowsBatch RowsBatch, ctx *ExecutionContext) erro
 				return errors.WithStack(err)
 			}
 
-			// The batch sequence is a uint32 that is incremented for each batch written
-			// The state holder id is generated using a sequence deterministically for each batch processed
-			// We create the sequence value for the dedup key by combining both into a uint64
-			// The main thing here is that the same dup key is generated if the same batch is processed again
-			dupSeq := uint64(ctx.BatchSequence)<<32 | uint64(i)
-
+			dupSeq := ctx.NextForwardSequence(a.PartialAggTableInfo.ID)
 			forwardKey := util.EncodeKeyForForwardAggregation(ctx.EnableDuplicateDetection, a.PartialAggTableInfo.ID,
 				ctx.WriteBatch.ShardID, dupSeq, a.FullAggTableInfo.ID)
 			value := util.EncodePrevAndCurrentRow(stateHolder.initialRowBytes, stateHolder.rowBytes)
@@ -115,22 +202,128 @@ func (a *Aggregator) HandleRows(rowsBatch RowsBatch, ctx *ExecutionContext) erro
 	return nil
 }
 
-// HandleRemoteRows is called when partial aggregation is forwarded from another shard
+// forwardRows forwards the rows to the shards which own their groups, for an aggregation which is calculated in a
+// single phase
+func (a *Aggregator) forwardRows(rowsBatch RowsBatch, ctx *ExecutionContext) error {
+	return forwardRowsToGroups(rowsBatch, ctx, a.GetChildren()[0].ColTypes(), a.PartialAggTableInfo.ID,
+		a.FullAggTableInfo.ID, func(row *common.Row) ([]byte, error) {
+			return a.createGroupKey(row, ctx.WriteBatch.ShardID, a.FullAggTableInfo.ID)
+		}, a.sharder)
+}
+
+// forwardRowsToGroups forwards the child rows to the remote consumer on the shards which own their groups, given by the
+// hash of the group key of each row
+func forwardRowsToGroups(rowsBatch RowsBatch, ctx *ExecutionContext, childColTypes []common.ColumnType,
+	originatorID uint64, remoteConsumerID uint64, groupKey func(row *common.Row) ([]byte, error), groupSharder *sharder.Sharder) error {
+	forward := func(remoteShardID uint64, prevRow *common.Row, currRow *common.Row) error {
+		return forwardRow(ctx, childColTypes, originatorID, remoteConsumerID, remoteShardID, prevRow, currRow)
+	}
+	groupShard := func(row *common.Row) (uint64, error) {
+		keyBytes, err := groupKey(row)
+		if err != nil {
+			return 0, errors.WithStack(err)
+		}
+		// We ignore the first 16 bytes as this is shard-id|table-id
+		return groupSharder.CalculateShard(sharder.ShardTypeHash, keyBytes[16:])
+	}
+
+	numRows := rowsBatch.Len()
+	for i := 0; i < numRows; i++ {
+		prevRow := rowsBatch.PreviousRow(i)
+		currRow := rowsBatch.CurrentRow(i)
+		var prevShardID, currShardID uint64
+		var err error
+		if prevRow != nil {
+			if prevShardID, err = groupShard(prevRow); err != nil {
+				return errors.WithStack(err)
+			}
+		}
+		if currRow != nil {
+			if currShardID, err = groupShard(currRow); err != nil {
+				return errors.WithStack(err)
+			}
+		}
+		if prevRow != nil && currRow != nil && prevShardID != currShardID {
+			// The row has moved to a group owned by another shard
+			if err := forward(prevShardID, prevRow, nil); err != nil {
+				return err
+			}
+			if err := forward(currShardID, nil, currRow); err != nil {
+				return err
+			}
+			continue
+		}
+		remoteShardID := currShardID
+		if currRow == nil {
+			remoteShardID = prevShardID
+		}
+		if err := forward(remoteShardID, prevRow, currRow); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ForwardedColTypes returns the column types of the rows forwarded to the shards which own the groups. These are the
+// partial aggregations, or the child rows if the aggregation is calculated in a single phase.
+func (a *Aggregator) ForwardedColTypes() []common.ColumnType {
+	if a.singlePhase {
+		return a.GetChildren()[0].ColTypes()
+	}
+	return a.FullAggTableInfo.ColumnTypes
+}
+
+// HandleRemoteRows is called when partial aggregation is forwarded from another shard, or child rows are forwarded for
+// an aggregation which is calculated in a single phase
 func (a *Aggregator) HandleRemoteRows(rowsBatch RowsBatch, ctx *ExecutionContext) error {
 
 	numRows := rowsBatch.Len()
-	stateHolders := &stateHolders{holdersMap: make(map[string]*aggStateHolder)}
-	readRows := a.rowsFactory.NewRows(numRows)
+	stateHolders := &stateHolders{
+		holdersMap:      make(map[string]*aggStateHolder),
+		extraStateTable: a.FullExtraStateTableInfo,
+		shardID:         ctx.WriteBatch.ShardID,
+	}
+	readRows := a.stateRowsFactory.NewRows(numRows)
 	numCols := len(a.colTypes)
+	var wm *windowWatermark
+	if a.window != nil {
+		var err error
+		if wm, err = loadWatermark(a.storage, a.WatermarkTableInfo, ctx.WriteBatch.ShardID); err != nil {
+			return errors.WithStack(err)
+		}
+	}
 	for i := 0; i < numRows; i++ {
 		prevRow := rowsBatch.PreviousRow(i)
 		currRow := rowsBatch.CurrentRow(i)
-		if err := a.calcFullAggregation(prevRow, currRow, readRows, stateHolders, ctx.WriteBatch.ShardID, numCols); err != nil {
+		if wm != nil {
+			// Rows for windows which have closed are dropped
+			if accepted, err := a.acceptRow(prevRow, wm); err != nil {
+				return errors.WithStack(err)
+			} else if !accepted {
+				prevRow = nil
+			}
+			if accepted, err := a.acceptRow(currRow, wm); err != nil {
+				return errors.WithStack(err)
+			} else if !accepted {
+				currRow = nil
+			}
+			if prevRow == nil && currRow == nil {
+				continue
+			}
+		}
+		if a.singlePhase {
+			if err := a.calcPartialAggregations(prevRow, currRow, readRows, stateHolders, ctx.WriteBatch.ShardID, a.FullAggTableInfo.ID); err != nil {
+				return errors.WithStack(err)
+			}
+		} else if err := a.calcFullAggregation(prevRow, currRow, readRows, stateHolders, ctx.WriteBatch.ShardID, numCols); err != nil {
 			return errors.WithStack(err)
 		}
 	}
 
 	// Store the results
+	if err := a.storeExtraState(stateHolders, ctx.WriteBatch); err != nil {
+		return errors.WithStack(err)
+	}
 	if err := a.storeAggregateResults(stateHolders, ctx.WriteBatch); err != nil {
 		return errors.WithStack(err)
 	}
@@ -146,13 +339,17 @@ func (a *Aggregator) HandleRemoteRows(rowsBatch RowsBatch, ctx *ExecutionContext
 			currRow := stateHolder.row
 			pi := -1
 			if prevRow != nil {
-				resultRows.AppendRow(*prevRow)
+				if err := a.appendResultRow(prevRow, resultRows); err != nil {
+					return errors.WithStack(err)
+				}
 				pi = rc
 				rc++
 			}
 			ci := -1
 			if currRow != nil {
-				resultRows.AppendRow(*currRow)
+				if err := a.appendResultRow(currRow, resultRows); err != nil {
+					return errors.WithStack(err)
+				}
 				ci = rc
 				rc++
 			}
@@ -164,10 +361,32 @@ func (a *Aggregator) HandleRemoteRows(rowsBatch RowsBatch, ctx *ExecutionContext
 }
 
 func (a *Aggregator) calcPartialAggregations(prevRow *common.Row, currRow *common.Row, readRows *common.Rows,
-	aggStateHolders *stateHolders, shardID uint64) error {
+	aggStateHolders *stateHolders, shardID uint64, tableID uint64) error {
+
+	if prevRow != nil && currRow != nil {
+		// If the group by columns have changed then the row has moved from one group to another
+		prevKeyBytes, err := a.createGroupKey(prevRow, shardID, tableID)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		currKeyBytes, err := a.createGroupKey(currRow, shardID, tableID)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		if !bytes.Equal(prevKeyBytes, currKeyBytes) {
+			if err := a.calcPartialAggregations(prevRow, nil, readRows, aggStateHolders, shardID, tableID); err != nil {
+				return err
+			}
+			return a.calcPartialAggregations(nil, currRow, readRows, aggStateHolders, shardID, tableID)
+		}
+	}
 
 	// Create the key
-	keyBytes, err := a.createKeyFromPrevOrCurrRow(prevRow, currRow, shardID, a.GetChildren()[0].ColTypes(), a.groupByCols, a.PartialAggTableInfo.ID)
+	row := currRow
+	if row == nil {
+		row = prevRow
+	}
+	keyBytes, err := a.createGroupKey(row, shardID, tableID)
 	if err != nil {
 		return errors.WithStack(err)
 	}
@@ -180,12 +399,12 @@ func (a *Aggregator) calcPartialAggregations(prevRow *common.Row, currRow *commo
 
 	// Evaluate the agg functions on the state
 	if prevRow != nil {
-		if err := a.evaluateAggFunctions(stateHolder.aggState, prevRow, true); err != nil {
+		if err := a.evaluateAggFunctions(stateHolder, prevRow, true); err != nil {
 			return err
 		}
 	}
 	if currRow != nil {
-		if err := a.evaluateAggFunctions(stateHolder.aggState, currRow, false); err != nil {
+		if err := a.evaluateAggFunctions(stateHolder, currRow, false); err != nil {
 			return err
 		}
 	}
@@ -203,7 +422,6 @@ func (a *Aggregator) calcFullAggregation(prevRow *common.Row, currRow *common.Ro
 	if err != nil {
 		return errors.WithStack(err)
 	}
-	currAggState := stateHolder.aggState
 
 	var prevMergeState *aggfuncs.AggState
 	if prevRow != nil {
@@ -211,7 +429,7 @@ func (a *Aggregator) calcFullAggregation(prevRow *common.Row, currRow *common.Ro
 		if err := a.initAggStateWithRow(prevRow, prevMergeState, numCols); err != nil {
 			return errors.WithStack(err)
 		}
-		if err := a.mergeState(prevMergeState, currAggState, true); err != nil {
+		if err := a.mergeState(prevMergeState, stateHolder, true); err != nil {
 			return err
 		}
 	}
@@ -221,38 +439,27 @@ func (a *Aggregator) calcFullAggregation(prevRow *common.Row, currRow *common.Ro
 		if err := a.initAggStateWithRow(currRow, currMergeState, numCols); err != nil {
 			return errors.WithStack(err)
 		}
-		if err := a.mergeState(currMergeState, currAggState, false); err != nil {
+		if err := a.mergeState(currMergeState, stateHolder, false); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
-func (a *Aggregator) mergeState(toMerge *aggfuncs.AggState, currState *aggfuncs.AggState, reverse bool) error {
+func (a *Aggregator) mergeState(toMerge *aggfuncs.AggState, stateHolder *aggStateHolder, reverse bool) error {
+	currState := stateHolder.aggState
 	for index, aggFunc := range a.aggFuncs {
-		switch aggFunc.ValueType().Type {
-		case common.TypeTinyInt, common.TypeInt, common.TypeBigInt:
-			if err := aggFunc.MergeInt64(toMerge, currState, index, reverse); err != nil {
-				return err
-			}
-		case common.TypeDecimal:
-			if err := aggFunc.MergeDecimal(toMerge, currState, index, reverse); err != nil {
-				return err
-			}
-		case common.TypeDouble:
-			if err := aggFunc.MergeFloat64(toMerge, currState, index, reverse); err != nil {
-				return err
-			}
-		case common.TypeVarchar:
-			if err := aggFunc.MergeString(toMerge, currState, index, reverse); err != nil {
-				return err
+		if aggFunc.RequiresExtraState() {
+			value, err := a.extraStateValue(toMerge, index)
+			if err != nil {
+				return errors.WithStack(err)
 			}
-		case common.TypeTimestamp:
-			if err := aggFunc.MergeTimestamp(toMerge, currState, index, reverse); err != nil {
-				return err
+			if _, err := a.updateExtraState(stateHolder, index, value, reverse); err != nil {
+				return errors.WithStack(err)
 			}
-		default:
-			return errors.Errorf("unexpected column type %d", aggFunc.ValueType())
+		}
+		if err := aggfuncs.MergeAggregateFunction(aggFunc, toMerge, currState, index, reverse); err != nil {
+			return err
 		}
 	}
 	return nil
@@ -269,8 +476,7 @@ func (a *Aggregator) loadAggregateState(keyBytes []byte, readRows *common.Rows,
 		}
 		var currRow *common.Row
 		if rowBytes != nil {
-			// Doesn't matter if we use partial or full col types here as they are the same
-			if err := common.DecodeRow(rowBytes, a.PartialAggTableInfo.ColumnTypes, readRows); err != nil {
+			if err := common.DecodeRow(rowBytes, a.stateColTypes, readRows); err != nil {
 				return nil, errors.WithStack(err)
 			}
 			r := readRows.GetRow(readRows.RowCount() - 1)
@@ -282,6 +488,9 @@ func (a *Aggregator) loadAggregateState(keyBytes []byte, readRows *common.Rows,
 			aggState: aggState,
 		}
 		stateHolder.keyBytes = keyBytes
+		if aggStateHolders.extraStateTable != nil {
+			stateHolder.extraStatePrefix = extraStatePrefix(aggStateHolders.extraStateTable, aggStateHolders.shardID, keyBytes)
+		}
 		aggStateHolders.holdersMap[sKey] = stateHolder
 		aggStateHolders.holders = append(aggStateHolders.holders, stateHolder)
 		if currRow != nil {
@@ -299,41 +508,22 @@ func (a *Aggregator) loadAggregateState(keyBytes []byte, readRows *common.Rows,
 }
 
 func (a *Aggregator) storeAggregateResults(stateHolders *stateHolders, writeBatch *cluster.WriteBatch) error {
-	resultRows := a.rowsFactory.NewRows(len(stateHolders.holders))
+	resultRows := a.stateRowsFactory.NewRows(len(stateHolders.holders))
 	rowCount := 0
 	for _, stateHolder := range stateHolders.holders {
 		aggState := stateHolder.aggState
 		if aggState.IsChanged() {
-			for i, colType := range a.colTypes {
-				if aggState.IsNull(i) {
-					resultRows.AppendNullToColumn(i)
-				} else {
-					switch colType.Type {
-					case common.TypeTinyInt, common.TypeInt, common.TypeBigInt:
-						resultRows.AppendInt64ToColumn(i, aggState.GetInt64(i))
-					case common.TypeDecimal:
-						resultRows.AppendDecimalToColumn(i, aggState.GetDecimal(i))
-					case common.TypeDouble:
-						resultRows.AppendFloat64ToColumn(i, aggState.GetFloat64(i))
-					case common.TypeVarchar:
-						str := aggState.GetString(i)
-						resultRows.AppendStringToColumn(i, str)
-					case common.TypeTimestamp:
-						ts, err := aggState.GetTimestamp(i)
-						if err != nil {
-							return errors.WithStack(err)
-						}
-						resultRows.AppendTimestampToColumn(i, ts)
-					default:
-						return errors.Errorf("unexpected column type %d", colType)
-					}
-					// TODO!! store extra data
+			if err := aggfuncs.AppendAggState(aggState, a.colTypes, resultRows); err != nil {
+				return errors.WithStack(err)
+			}
+			for i, col := range a.intermediateCols {
+				if col != -1 {
+					resultRows.AppendStringToColumn(col, common.ByteSliceToStringZeroCopy(aggState.GetIntermediateState(i)))
 				}
 			}
 			row := resultRows.GetRow(rowCount)
 			stateHolder.row = &row
-			// Doesn't matter if we use partial or full col types here as they are the same
-			valueBuff, err := common.EncodeRow(&row, a.PartialAggTableInfo.ColumnTypes, make([]byte, 0))
+			valueBuff, err := common.EncodeRow(&row, a.stateColTypes, make([]byte, 0))
 			if err != nil {
 				return errors.WithStack(err)
 			}
@@ -346,85 +536,70 @@ func (a *Aggregator) storeAggregateResults(stateHolders *stateHolders, writeBatc
 }
 
 func (a *Aggregator) initAggStateWithRow(currRow *common.Row, aggState *aggfuncs.AggState, numCols int) error {
-	for i := 0; i < numCols; i++ {
-		colType := a.colTypes[i]
-		if currRow.IsNull(i) {
-			aggState.SetNull(i)
-		} else {
-			switch colType.Type {
-			case common.TypeTinyInt, common.TypeInt, common.TypeBigInt:
-				aggState.SetInt64(i, currRow.GetInt64(i))
-			case common.TypeDecimal:
-				if err := aggState.SetDecimal(i, currRow.GetDecimal(i)); err != nil {
-					return errors.WithStack(err)
-				}
-			case common.TypeDouble:
-				aggState.SetFloat64(i, currRow.GetFloat64(i))
-			case common.TypeVarchar:
-				strVal := currRow.GetString(i)
-				aggState.SetString(i, strVal)
-			case common.TypeTimestamp:
-				if err := aggState.SetTimestamp(i, currRow.GetTimestamp(i)); err != nil {
-					return errors.WithStack(err)
-				}
-			default:
-				return errors.Errorf("unexpected column type %d", colType)
-			}
+	if err := aggfuncs.InitAggStateWithRow(currRow, a.colTypes[:numCols], aggState); err != nil {
+		return errors.WithStack(err)
+	}
+	for i, col := range a.intermediateCols {
+		if col != -1 && !currRow.IsNull(col) {
+			aggState.SetIntermediateState(i, []byte(currRow.GetString(col)))
 		}
 	}
 	return nil
 }
 
-func (a *Aggregator) evaluateAggFunctions(aggState *aggfuncs.AggState, row *common.Row, reverse bool) error {
-	for index, aggFunc := range a.aggFuncs {
-		switch aggFunc.ValueType().Type {
-		case common.TypeTinyInt, common.TypeInt, common.TypeBigInt:
-			arg, null, err := aggFunc.ArgExpression().EvalInt64(row)
-			if err != nil {
-				return errors.WithStack(err)
-			}
-			err = aggFunc.EvalInt64(arg, null, aggState, index, reverse)
-			if err != nil {
-				return errors.WithStack(err)
-			}
+// appendResultRow appends the values of the aggregate functions in the stored row, without their intermediate state
+func (a *Aggregator) appendResultRow(stateRow *common.Row, resultRows *common.Rows) error {
+	for i, colType := range a.colTypes {
+		if stateRow.IsNull(i) {
+			resultRows.AppendNullToColumn(i)
+			continue
+		}
+		switch colType.Type {
+		case common.TypeTinyInt, common.TypeInt, common.TypeBigInt, common.TypeBoolean:
+			resultRows.AppendInt64ToColumn(i, stateRow.GetInt64(i))
 		case common.TypeDecimal:
-			arg, null, err := aggFunc.ArgExpression().EvalDecimal(row)
-			if err != nil {
-				return errors.WithStack(err)
-			}
-			err = aggFunc.EvalDecimal(arg, null, aggState, index, reverse)
-			if err != nil {
-				return errors.WithStack(err)
-			}
+			resultRows.AppendDecimalToColumn(i, stateRow.GetDecimal(i))
 		case common.TypeDouble:
-			arg, null, err := aggFunc.ArgExpression().EvalFloat64(row)
-			if err != nil {
-				return errors.WithStack(err)
-			}
-			err = aggFunc.EvalFloat64(arg, null, aggState, index, reverse)
-			if err != nil {
-				return errors.WithStack(err)
-			}
-		case common.TypeVarchar:
-			arg, null, err := aggFunc.ArgExpression().EvalString(row)
-			if err != nil {
-				return errors.WithStack(err)
-			}
-			err = aggFunc.EvalString(arg, null, aggState, index, reverse)
+			resultRows.AppendFloat64ToColumn(i, stateRow.GetFloat64(i))
+		case common.TypeVarchar, common.TypeVarbinary:
+			resultRows.AppendStringToColumn(i, stateRow.GetString(i))
+		case common.TypeTimestamp, common.TypeDate:
+			resultRows.AppendTimestampToColumn(i, stateRow.GetTimestamp(i))
+		case common.TypeJSON:
+			resultRows.AppendJSONToColumn(i, stateRow.GetJSON(i))
+		default:
+			return errors.Errorf("unexpected column type %d", colType)
+		}
+	}
+	return nil
+}
+
+// evalArg evaluates the argument of the aggregate function as its own type, which can differ from the type of the
+// function
+func (a *Aggregator) evalArg(index int, row *common.Row) (interface{}, bool, error) {
+	return aggfuncs.EvalArgExpr(a.aggFuncs[index].ArgExpression(), a.argTypes[index], row)
+}
+
+func (a *Aggregator) evaluateAggFunctions(stateHolder *aggStateHolder, row *common.Row, reverse bool) error {
+	aggState := stateHolder.aggState
+	for index, aggFunc := range a.aggFuncs {
+		if a.distinct[index] {
+			counted, err := a.countDistinctValue(stateHolder, index, row, reverse)
 			if err != nil {
 				return errors.WithStack(err)
 			}
-		case common.TypeTimestamp:
-			arg, null, err := aggFunc.ArgExpression().EvalTimestamp(row)
-			if err != nil {
-				return errors.WithStack(err)
+			if !counted {
+				continue
 			}
-			err = aggFunc.EvalTimestamp(arg, null, aggState, index, reverse)
-			if err != nil {
+		}
+		value, err := aggfuncs.EvalAggregateFunction(aggFunc, a.argTypes[index], aggState, index, row, reverse)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		if aggFunc.RequiresExtraState() {
+			if _, err := a.updateExtraState(stateHolder, index, value, reverse); err != nil {
 				return errors.WithStack(err)
 			}
-		default:
-			return errors.Errorf("unexpected column type %d", aggFunc.ValueType())
 		}
 	}
 	return nil
@@ -441,12 +616,79 @@ func (a *Aggregator) createKeyFromPrevOrCurrRow(prevRow *common.Row, currRow *co
 	return common.EncodeKeyCols(row, keyCols, colTypes, keyBytes)
 }
 
-func (a *Aggregator) createKey(row *common.Row, shardID uint64, colTypes []common.ColumnType, keyCols []int, tableID uint64) ([]byte, error) {
+// createGroupKey evaluates the group by expressions on the child row and encodes the key of its group
+func (a *Aggregator) createGroupKey(row *common.Row, shardID uint64, tableID uint64) ([]byte, error) {
+	return encodeGroupKey(a.groupByExprs, a.groupByRowsFactory, row, shardID, tableID)
+}
+
+func encodeGroupKey(groupByExprs []*common.Expression, groupByRowsFactory *common.RowsFactory, row *common.Row,
+	shardID uint64, tableID uint64) ([]byte, error) {
+	groupByTypes := groupByRowsFactory.ColumnTypes
+	groupByRows := groupByRowsFactory.NewRows(1)
+	for i, expr := range groupByExprs {
+		if err := appendExprValue(expr, groupByTypes[i], row, groupByRows, i); err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+	groupByRow := groupByRows.GetRow(0)
 	keyBytes := table.EncodeTableKeyPrefix(tableID, shardID, 25)
-	return common.EncodeKeyCols(row, keyCols, colTypes, keyBytes)
+	for i, colType := range groupByTypes {
+		var err error
+		if keyBytes, err = common.EncodeKeyCol(&groupByRow, i, colType, keyBytes); err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+	return keyBytes, nil
+}
+
+// appendExprValue evaluates the expression on the row as the given type and appends the value to the column
+func appendExprValue(expr *common.Expression, colType common.ColumnType, row *common.Row, rows *common.Rows, colIndex int) error {
+	var null bool
+	var err error
+	switch colType.Type {
+	case common.TypeTinyInt, common.TypeInt, common.TypeBigInt, common.TypeBoolean:
+		var val int64
+		if val, null, err = expr.EvalInt64(row); err == nil && !null {
+			rows.AppendInt64ToColumn(colIndex, val)
+		}
+	case common.TypeDecimal:
+		var val common.Decimal
+		if val, null, err = expr.EvalDecimal(row); err == nil && !null {
+			rows.AppendDecimalToColumn(colIndex, val)
+		}
+	case common.TypeDouble:
+		var val float64
+		if val, null, err = expr.EvalFloat64(row); err == nil && !null {
+			rows.AppendFloat64ToColumn(colIndex, val)
+		}
+	case common.TypeVarchar, common.TypeVarbinary:
+		var val string
+		if val, null, err = expr.EvalString(row); err == nil && !null {
+			rows.AppendStringToColumn(colIndex, val)
+		}
+	case common.TypeTimestamp, common.TypeDate:
+		var val common.Timestamp
+		if val, null, err = expr.EvalTimestamp(row); err == nil && !null {
+			rows.AppendTimestampToColumn(colIndex, val)
+		}
+	case common.TypeJSON:
+		var val common.JSON
+		if val, null, err = expr.EvalJSON(row); err == nil && !null {
+			rows.AppendJSONToColumn(colIndex, val)
+		}
+	default:
+		return errors.Errorf("unexpected column type %d", colType.Type)
+	}
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if null {
+		rows.AppendNullToColumn(colIndex)
+	}
+	return nil
 }
 
-func createAggFunctions(aggFunctionInfos []*AggregateFunctionInfo, colTypes []common.ColumnType) ([]aggfuncs.AggregateFunction, error) {
+func createAggFunctions(aggFunctionInfos []*aggfuncs.AggregateFunctionInfo, colTypes []common.ColumnType) ([]aggfuncs.AggregateFunction, error) {
 	aggFuncs := make([]aggfuncs.AggregateFunction, len(aggFunctionInfos))
 	for index, funcInfo := range aggFunctionInfos {
 		argExpr := funcInfo.ArgExpr
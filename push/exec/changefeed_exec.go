@@ -0,0 +1,177 @@
+package exec
+
+import (
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/squareup/pranadb/common"
+	"github.com/squareup/pranadb/errors"
+)
+
+// The minimum number of changes kept in memory for each shard. A subscriber which falls further behind than this, or
+// which tries to resume from further back, can't carry on from where it was.
+const changeFeedMaxChanges = 10000
+
+// ChangeFeedExecutor keeps the most recent changes to a table on the shards of this node, so they can be sent to
+// subscribers. The changes of each shard are given consecutive sequence numbers in the order they are committed. The
+// sequence numbers only mean something for the same Epoch - a new executor has a different one.
+type ChangeFeedExecutor struct {
+	pushExecutorBase
+	TableInfo          *common.TableInfo
+	Epoch              uint64
+	lock               sync.Mutex
+	changeLogs         map[uint64]*changeLog
+	uncommittedBatches sync.Map
+	changesCommitted   func(shardID uint64)
+}
+
+// Change is a committed change to a row. PreviousRow is nil for an insert and CurrentRow is nil for a delete. The rows
+// are encoded with common.EncodeRow.
+type Change struct {
+	Sequence    uint64
+	PreviousRow []byte
+	CurrentRow  []byte
+}
+
+type changeLog struct {
+	firstSequence uint64 // The sequence of changes[0]
+	changes       []*Change
+}
+
+func (c *changeLog) nextSequence() uint64 {
+	return c.firstSequence + uint64(len(c.changes))
+}
+
+// NewChangeFeedExecutor creates a ChangeFeedExecutor. changesCommitted is called when changes to a shard have been
+// committed - it is called from the committed callback of the batch so must not block.
+func NewChangeFeedExecutor(tableInfo *common.TableInfo, epoch uint64, changesCommitted func(shardID uint64)) *ChangeFeedExecutor {
+	return &ChangeFeedExecutor{
+		pushExecutorBase: pushExecutorBase{
+			colNames:    tableInfo.ColumnNames,
+			colTypes:    tableInfo.ColumnTypes,
+			keyCols:     tableInfo.PrimaryKeyCols,
+			colsVisible: tableInfo.ColsVisible,
+			rowsFactory: common.NewRowsFactory(tableInfo.ColumnTypes),
+		},
+		TableInfo:        tableInfo,
+		Epoch:            epoch,
+		changeLogs:       make(map[uint64]*changeLog),
+		changesCommitted: changesCommitted,
+	}
+}
+
+func (c *ChangeFeedExecutor) ReCalcSchemaFromChildren() error {
+	return nil
+}
+
+func (c *ChangeFeedExecutor) HandleRows(rowsBatch RowsBatch, ctx *ExecutionContext) error {
+	numEntries := rowsBatch.Len()
+	if numEntries == 0 {
+		return nil
+	}
+	changes := make([]*Change, numEntries)
+	for i := 0; i < numEntries; i++ {
+		change := &Change{}
+		if prevRow := rowsBatch.PreviousRow(i); prevRow != nil {
+			buff, err := common.EncodeRow(prevRow, c.colTypes, nil)
+			if err != nil {
+				return errors.WithStack(err)
+			}
+			change.PreviousRow = buff
+		}
+		if currentRow := rowsBatch.CurrentRow(i); currentRow != nil {
+			buff, err := common.EncodeRow(currentRow, c.colTypes, nil)
+			if err != nil {
+				return errors.WithStack(err)
+			}
+			change.CurrentRow = buff
+		}
+		changes[i] = change
+	}
+	// The changes are only recorded once they have been committed
+	shardID := ctx.WriteBatch.ShardID
+	c.uncommittedBatches.Store(shardID, struct{}{})
+	ctx.WriteBatch.AddCommittedCallback(func() error {
+		c.appendChanges(shardID, changes)
+		c.uncommittedBatches.Delete(shardID)
+		c.changesCommitted(shardID)
+		return nil
+	})
+	return nil
+}
+
+func (c *ChangeFeedExecutor) appendChanges(shardID uint64, changes []*Change) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	cl := c.getChangeLog(shardID)
+	seq := cl.nextSequence()
+	for _, change := range changes {
+		change.Sequence = seq
+		seq++
+	}
+	cl.changes = append(cl.changes, changes...)
+	if len(cl.changes) >= 2*changeFeedMaxChanges {
+		// We copy the ones we keep, otherwise the backing array won't be gc'd
+		numRemoved := len(cl.changes) - changeFeedMaxChanges
+		cl.changes = append([]*Change(nil), cl.changes[numRemoved:]...)
+		cl.firstSequence += uint64(numRemoved)
+	}
+}
+
+func (c *ChangeFeedExecutor) getChangeLog(shardID uint64) *changeLog {
+	cl, ok := c.changeLogs[shardID]
+	if !ok {
+		cl = &changeLog{}
+		c.changeLogs[shardID] = cl
+	}
+	return cl
+}
+
+// NextSequence returns the sequence that the next change committed for the shard will have
+func (c *ChangeFeedExecutor) NextSequence(shardID uint64) uint64 {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.getChangeLog(shardID).nextSequence()
+}
+
+// GetChanges returns up to limit changes for the shard, starting at the change with sequence fromSequence. It returns
+// false if the changes from that sequence aren't available, either because they have already been discarded, or because
+// the sequence is from the future.
+func (c *ChangeFeedExecutor) GetChanges(shardID uint64, fromSequence uint64, limit int) ([]*Change, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	cl := c.getChangeLog(shardID)
+	if fromSequence < cl.firstSequence || fromSequence > cl.nextSequence() {
+		return nil, false
+	}
+	start := int(fromSequence - cl.firstSequence)
+	end := len(cl.changes)
+	if end-start > limit {
+		end = start + limit
+	}
+	changes := make([]*Change, end-start)
+	copy(changes, cl.changes[start:end])
+	return changes, true
+}
+
+// WaitForNoUncommittedBatches waits until all the batches which have been handled have been committed, so the changes
+// are consistent with what is in storage
+func (c *ChangeFeedExecutor) WaitForNoUncommittedBatches() error {
+	start := time.Now()
+	for {
+		l := 0
+		c.uncommittedBatches.Range(func(key, value interface{}) bool {
+			l++
+			return true
+		})
+		if l == 0 {
+			return nil
+		}
+		log.Trace("waiting for no uncommitted batches")
+		time.Sleep(10 * time.Millisecond)
+		if time.Now().Sub(start) > 10*time.Second {
+			return errors.Error("timed out waiting for no uncommitted batches")
+		}
+	}
+}
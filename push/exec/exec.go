@@ -1,8 +1,12 @@
 package exec
 
 import (
+	"time"
+
 	"github.com/squareup/pranadb/cluster"
 	"github.com/squareup/pranadb/common"
+	"github.com/squareup/pranadb/errors"
+	"github.com/squareup/pranadb/push/util"
 )
 
 type PushExecutor interface {
@@ -33,6 +37,25 @@ type ExecutionContext struct {
 	RemoteBatches            map[uint64]*cluster.WriteBatch
 	BatchSequence            uint32
 	EnableDuplicateDetection bool
+	// The watermark of the source of the rows, when it has advanced. Executors which only transform rows pass it on.
+	Watermark time.Time
+	// The number of rows each originator has forwarded while processing the batch
+	forwardSeqs map[uint64]uint32
+}
+
+// NextForwardSequence returns the sequence for the dedup key of the next row the originator forwards to another shard.
+// It combines the batch sequence with the number of rows the originator has already forwarded while processing the
+// batch. An executor can handle rows more than once in a batch - e.g. the parent of a join handles the rows of each
+// side of the join - so the count must not restart for each call to HandleRows, or the rows forwarded by the later
+// calls would be dropped as duplicates.
+func (e *ExecutionContext) NextForwardSequence(originatorID uint64) uint64 {
+	if e.forwardSeqs == nil {
+		e.forwardSeqs = make(map[uint64]uint32)
+	}
+	seq := e.forwardSeqs[originatorID]
+	e.forwardSeqs[originatorID] = seq + 1
+	// The same sequence is generated if the same batch is processed again
+	return uint64(e.BatchSequence)<<32 | uint64(seq)
 }
 
 func (e *ExecutionContext) AddToForwardBatch(shardID uint64, key []byte, value []byte) {
@@ -47,6 +70,27 @@ func (e *ExecutionContext) AddToForwardBatch(shardID uint64, key []byte, value [
 	remoteBatch.AddPut(key, value)
 }
 
+// forwardRow forwards the previous and current values of a row to the remote consumer on the remote shard
+func forwardRow(ctx *ExecutionContext, colTypes []common.ColumnType, originatorID uint64, remoteConsumerID uint64,
+	remoteShardID uint64, prevRow *common.Row, currRow *common.Row) error {
+	var prevBytes, currBytes []byte
+	var err error
+	if prevRow != nil {
+		if prevBytes, err = common.EncodeRow(prevRow, colTypes, nil); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	if currRow != nil {
+		if currBytes, err = common.EncodeRow(currRow, colTypes, nil); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	forwardKey := util.EncodeKeyForForwardAggregation(ctx.EnableDuplicateDetection, originatorID,
+		ctx.WriteBatch.ShardID, ctx.NextForwardSequence(originatorID), remoteConsumerID)
+	ctx.AddToForwardBatch(remoteShardID, forwardKey, util.EncodePrevAndCurrentRow(prevBytes, currBytes))
+	return nil
+}
+
 type pushExecutorBase struct {
 	colNames []string
 	colTypes []common.ColumnType
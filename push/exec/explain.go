@@ -0,0 +1,93 @@
+package exec
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ExplainedExecutor describes an executor of a push DAG
+type ExplainedExecutor struct {
+	Level    int // The depth of the executor in the DAG
+	Operator string
+	Info     string
+}
+
+// Explain describes the executors of a push DAG in the order of a depth first walk. The info of an executor names the
+// internal tables which hold its state, and says which rows it forwards to the shards that own them.
+func Explain(dag PushExecutor) []ExplainedExecutor {
+	return explain(dag, 0, nil)
+}
+
+func explain(executor PushExecutor, level int, explained []ExplainedExecutor) []ExplainedExecutor {
+	explained = append(explained, ExplainedExecutor{
+		Level:    level,
+		Operator: reflect.TypeOf(executor).Elem().Name(),
+		Info:     explainInfo(executor),
+	})
+	for _, child := range executor.GetChildren() {
+		explained = explain(child, level+1, explained)
+	}
+	return explained
+}
+
+var joinTypeNames = map[JoinType]string{
+	InnerJoin:      "inner",
+	LeftOuterJoin:  "left outer",
+	RightOuterJoin: "right outer",
+}
+
+func explainInfo(executor PushExecutor) string {
+	var info []string
+	switch e := executor.(type) {
+	case *TableExecutor:
+		info = append(info, fmt.Sprintf("table:%s", e.TableInfo.Name))
+	case *Scan:
+		info = append(info, fmt.Sprintf("table:%s", e.TableName))
+	case *Aggregator:
+		info = append(info, fmt.Sprintf("partial agg table:%s", e.PartialAggTableInfo.Name),
+			fmt.Sprintf("full agg table:%s", e.FullAggTableInfo.Name))
+		if e.PartialExtraStateTableInfo != nil {
+			info = append(info, fmt.Sprintf("partial extra state table:%s", e.PartialExtraStateTableInfo.Name),
+				fmt.Sprintf("full extra state table:%s", e.FullExtraStateTableInfo.Name))
+		}
+		if e.window != nil {
+			info = append(info, fmt.Sprintf("tumbling window:%s", e.window.Size))
+		}
+		if e.WatermarkTableInfo != nil {
+			info = append(info, fmt.Sprintf("watermark table:%s", e.WatermarkTableInfo.Name))
+		}
+		if e.singlePhase {
+			info = append(info, "forwards child rows by group key")
+		} else {
+			info = append(info, "forwards partial aggregations by group key")
+		}
+	case *WindowAggregator:
+		if e.hop != nil {
+			info = append(info, fmt.Sprintf("hopping window:%s slide %s", e.hop.Size, e.hop.Slide))
+		} else if e.session != nil {
+			info = append(info, fmt.Sprintf("session window:gap %s", e.session.Gap))
+		}
+		info = append(info, fmt.Sprintf("event table:%s", e.EventTableInfo.Name),
+			fmt.Sprintf("window table:%s", e.WindowTableInfo.Name),
+			fmt.Sprintf("expiry table:%s", e.ExpiryTableInfo.Name),
+			fmt.Sprintf("watermark table:%s", e.WatermarkTableInfo.Name),
+			"forwards child rows by group key")
+	case *Join:
+		info = append(info, fmt.Sprintf("type:%s", joinTypeNames[e.JoinType]))
+		if e.interval != nil {
+			info = append(info, fmt.Sprintf("interval:%s to %s", e.interval.Lower, e.interval.Upper))
+		}
+	case *JoinInput:
+		side := "right"
+		if e == e.join.Left {
+			side = "left"
+		}
+		info = append(info, fmt.Sprintf("side:%s", side), fmt.Sprintf("table:%s", e.TableInfo.Name))
+		if e.ExpiryTableInfo != nil {
+			info = append(info, fmt.Sprintf("expiry table:%s", e.ExpiryTableInfo.Name))
+		}
+		info = append(info, "forwards child rows by join key")
+	}
+	return strings.Join(info, ", ")
+}
@@ -0,0 +1,85 @@
+package exec
+
+import (
+	"time"
+
+	"github.com/squareup/pranadb/common"
+	"github.com/squareup/pranadb/errors"
+	"github.com/squareup/pranadb/table"
+)
+
+// IntervalJoinBounds describes an interval join, where a row from the right side only matches a row from the left side
+// if its time is in the range [left time + Lower, left time + Upper].
+//
+// As event time advances rows can no longer match any rows which arrive later, so their state is expired. Event time is
+// taken to be the greatest time in each batch of rows received for a side on the shard, so rows arriving later than
+// that might not match.
+type IntervalJoinBounds struct {
+	LeftTimeCol  int // The index of the time column in the left child
+	RightTimeCol int // The index of the time column in the right child
+	Lower        time.Duration
+	Upper        time.Duration
+}
+
+// SetInterval makes the join an interval join. Each side needs an internal table to index its stored rows by time.
+func (j *Join) SetInterval(bounds *IntervalJoinBounds, leftExpiryTableInfo *common.TableInfo,
+	rightExpiryTableInfo *common.TableInfo) error {
+	if j.JoinType != InnerJoin {
+		return errors.NewPranaErrorf(errors.InvalidStatement, "interval joins must be inner joins")
+	}
+	if bounds.Lower > bounds.Upper {
+		return errors.NewPranaErrorf(errors.InvalidStatement, "interval join lower bound %s is after upper bound %s",
+			bounds.Lower, bounds.Upper)
+	}
+	j.interval = bounds
+	j.Left.timeCol = bounds.LeftTimeCol
+	j.Left.ExpiryTableInfo = leftExpiryTableInfo
+	j.Right.timeCol = bounds.RightTimeCol
+	j.Right.ExpiryTableInfo = rightExpiryTableInfo
+	return nil
+}
+
+// expireRows deletes the stored rows on the other side of the join which can't match any rows arriving on this side
+// after the given event time. The output rows they have already been joined into are not affected.
+func (j *Join) expireRows(b *joinBatch, eventTime common.Timestamp) error {
+	var expireBefore time.Time
+	gt, err := eventTime.GoTime(time.UTC)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if b.input == j.Right {
+		// A left row can't match right rows with a time after left time + Upper
+		expireBefore = gt.Add(-j.interval.Upper)
+	} else {
+		// A right row can't match left rows with a time after right time - Lower
+		expireBefore = gt.Add(j.interval.Lower)
+	}
+	other := b.other
+	prefix := table.EncodeTableKeyPrefix(other.ExpiryTableInfo.ID, b.shardID, 24)
+	end, err := common.KeyEncodeTimestamp(append([]byte{}, prefix...), common.NewTimestampFromGoTime(expireBefore))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	pairs, err := j.storage.LocalScan(prefix, end, -1)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	rowPrefix := table.EncodeTableKeyPrefix(other.TableInfo.ID, b.shardID, 64)
+	for _, pair := range pairs {
+		// The expiry key is the time followed by the key of the row, without its table prefix
+		rowKey := append(rowPrefix[:16:16], pair.Key[len(prefix)+8:]...)
+		b.writes.delete(pair.Key)
+		b.writes.delete(rowKey)
+	}
+	return nil
+}
+
+// encodeExpiryKey encodes the key of the row in the expiry table, given the key of the row in the internal table
+func (j *JoinInput) encodeExpiryKey(row *common.Row, storageKey []byte, shardID uint64) ([]byte, error) {
+	keyBytes := table.EncodeTableKeyPrefix(j.ExpiryTableInfo.ID, shardID, len(storageKey)+8)
+	keyBytes, err := common.KeyEncodeTimestamp(keyBytes, row.GetTimestamp(j.timeCol))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return append(keyBytes, storageKey[16:]...), nil
+}
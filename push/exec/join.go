@@ -0,0 +1,903 @@
+package exec
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/squareup/pranadb/cluster"
+	"github.com/squareup/pranadb/common"
+	"github.com/squareup/pranadb/errors"
+	"github.com/squareup/pranadb/sharder"
+	"github.com/squareup/pranadb/table"
+)
+
+type JoinType int
+
+const (
+	InnerJoin JoinType = iota
+	LeftOuterJoin
+	RightOuterJoin
+)
+
+// Join incrementally maintains an equi-join between its two children.
+//
+// Rows arriving from either child are forwarded to the shard that owns their join key, so all rows with the same join
+// key, from both sides, meet on the same shard. There, each side's rows are stored in an internal table keyed by
+// [join_key, child_key]. When a row arrives from one side it is stored and then matched against the rows of the other
+// side with the same join key, which are found with a prefix scan.
+//
+// For outer joins, rows from the outer side which have no matches are output padded with nulls. When the first match
+// for such a row arrives the padded row is retracted, and when its last match goes away the padded row is re-emitted.
+type Join struct {
+	pushExecutorBase
+	JoinType   JoinType
+	Left       *JoinInput
+	Right      *JoinInput
+	otherConds []*common.Expression
+	storage    cluster.Cluster
+	sharder    *sharder.Sharder
+	// The index of each column the planner knows about in the output, in the columns of the left child followed by
+	// those of the right child
+	childCols       []int
+	condRowsFactory *common.RowsFactory
+	interval        *IntervalJoinBounds // Set for interval joins, whose state is expired as event time advances
+}
+
+// JoinInput sits between the Join and one of its children. It receives rows from the child and forwards them to the
+// shard that owns the join key, and it receives the forwarded rows on that shard.
+type JoinInput struct {
+	pushExecutorBase
+	TableInfo  *common.TableInfo // The internal table that holds the rows of this side of the join
+	join       *Join
+	joinCols   []int // The join key column indexes in the child
+	conds      []*common.Expression
+	numCols    int   // The number of columns of the child that the planner knows about
+	outputCols []int // The index in the output of the join of each column of the child, or -1 if not output
+	condCols   []int // The index in the row the other conditions are evaluated against, or -1 if not used
+	// For interval joins, the internal table that indexes the stored rows by time, so they can be expired
+	ExpiryTableInfo *common.TableInfo
+	timeCol         int
+}
+
+func NewJoin(joinType JoinType, leftJoinCols []int, rightJoinCols []int, leftConds []*common.Expression,
+	rightConds []*common.Expression, otherConds []*common.Expression, childCols []int, numLeftCols int, numRightCols int,
+	leftTableInfo *common.TableInfo, rightTableInfo *common.TableInfo, storage cluster.Cluster,
+	sharder *sharder.Sharder) (*Join, error) {
+	if len(leftJoinCols) == 0 || len(leftJoinCols) != len(rightJoinCols) {
+		return nil, errors.Error("join must have at least one equality condition between columns")
+	}
+	join := &Join{
+		pushExecutorBase: pushExecutorBase{},
+		JoinType:         joinType,
+		otherConds:       otherConds,
+		storage:          storage,
+		sharder:          sharder,
+		childCols:        childCols,
+	}
+	join.Left = &JoinInput{
+		TableInfo: leftTableInfo,
+		join:      join,
+		joinCols:  leftJoinCols,
+		conds:     leftConds,
+		numCols:   numLeftCols,
+	}
+	join.Right = &JoinInput{
+		TableInfo: rightTableInfo,
+		join:      join,
+		joinCols:  rightJoinCols,
+		conds:     rightConds,
+		numCols:   numRightCols,
+	}
+	return join, nil
+}
+
+func (j *Join) ReCalcSchemaFromChildren() error {
+	if len(j.children) != 2 {
+		return errors.Errorf("join must have two children, has %d", len(j.children))
+	}
+	inputs := []*JoinInput{j.Left, j.Right}
+	for i, input := range inputs {
+		if err := input.setChild(j.children[i]); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	for i, leftJoinCol := range j.Left.joinCols {
+		leftType := j.Left.colTypes[leftJoinCol]
+		rightType := j.Right.colTypes[j.Right.joinCols[i]]
+		if !keyTypesCompatible(leftType, rightType) {
+			return errors.NewPranaErrorf(errors.InvalidStatement, "cannot join column of type %s with column of type %s",
+				leftType.String(), rightType.String())
+		}
+	}
+
+	// The output starts with the columns the planner expects, which can be any subset of the columns of the children.
+	// The key columns of the children, and any other hidden columns they have, go on the end so that column indexes
+	// calculated by the planner for the executors above us remain valid
+	for _, input := range inputs {
+		input.outputCols = make([]int, len(input.colTypes))
+		for i := range input.outputCols {
+			input.outputCols[i] = -1
+		}
+	}
+	for i, childCol := range j.childCols {
+		if childCol < j.Left.numCols {
+			j.Left.outputCols[childCol] = i
+		} else {
+			j.Right.outputCols[childCol-j.Left.numCols] = i
+		}
+	}
+	numCols := len(j.childCols)
+	for _, input := range inputs {
+		for i := range input.colTypes {
+			hidden := input.colsVisible != nil && !input.colsVisible[i]
+			if input.outputCols[i] == -1 && (hidden || containsInt(input.keyCols, i)) {
+				input.outputCols[i] = numCols
+				numCols++
+			}
+		}
+	}
+	j.colTypes = make([]common.ColumnType, numCols)
+	j.colsVisible = make([]bool, numCols)
+	j.keyCols = nil
+	for _, input := range inputs {
+		for i, outCol := range input.outputCols {
+			if outCol == -1 {
+				continue
+			}
+			j.colTypes[outCol] = input.colTypes[i]
+			j.colsVisible[outCol] = outCol < len(j.childCols)
+		}
+		for _, keyCol := range input.keyCols {
+			j.keyCols = append(j.keyCols, input.outputCols[keyCol])
+		}
+	}
+	j.rowsFactory = common.NewRowsFactory(j.colTypes)
+
+	var condColTypes []common.ColumnType
+	for _, input := range inputs {
+		input.condCols = make([]int, len(input.colTypes))
+		for i := range input.condCols {
+			if i < input.numCols {
+				input.condCols[i] = len(condColTypes)
+				condColTypes = append(condColTypes, input.colTypes[i])
+			} else {
+				input.condCols[i] = -1
+			}
+		}
+	}
+	j.condRowsFactory = common.NewRowsFactory(condColTypes)
+
+	// Rows from each child are passed to the join via a JoinInput, which knows which side of the join they came from
+	for i, input := range inputs {
+		child := j.children[i]
+		child.SetParent(input)
+		input.SetParent(j)
+		input.ClearChildren()
+		input.AddChild(child)
+	}
+	j.children = []PushExecutor{j.Left, j.Right}
+	return nil
+}
+
+func (j *Join) HandleRows(rowsBatch RowsBatch, ctx *ExecutionContext) error {
+	panic("should not be called")
+}
+
+func (j *Join) otherInput(input *JoinInput) *JoinInput {
+	if input == j.Left {
+		return j.Right
+	}
+	return j.Left
+}
+
+// preserves returns true if rows from the input are output even when they have no matches, i.e. if the input is the
+// outer side of an outer join
+func (j *Join) preserves(input *JoinInput) bool {
+	switch j.JoinType {
+	case LeftOuterJoin:
+		return input == j.Left
+	case RightOuterJoin:
+		return input == j.Right
+	default:
+		return false
+	}
+}
+
+// joinBatch holds the state used while handling a batch of rows forwarded from one side of the join
+type joinBatch struct {
+	input        *JoinInput
+	other        *JoinInput
+	shardID      uint64
+	inputPending *joinPendingWrites
+	otherPending *joinPendingWrites
+	writes       *joinWrites
+	inputRows    *common.Rows
+	otherRows    *common.Rows
+	results      *common.Rows
+	entries      []RowsEntry
+}
+
+// handleRemoteRows is called on the shard that owns the join key, with rows that have been forwarded from one side of
+// the join
+func (j *Join) handleRemoteRows(input *JoinInput, rowsBatch RowsBatch, ctx *ExecutionContext) error {
+	other := j.otherInput(input)
+	numRows := rowsBatch.Len()
+	b := &joinBatch{
+		input:     input,
+		other:     other,
+		shardID:   ctx.WriteBatch.ShardID,
+		writes:    newJoinWrites(),
+		inputRows: input.rowsFactory.NewRows(numRows),
+		otherRows: other.rowsFactory.NewRows(numRows),
+		results:   j.rowsFactory.NewRows(numRows),
+	}
+
+	// Rows for the other side might have been received in the same batch as this one, in which case they are in the
+	// write batch but not yet in storage, so we need to take them into account too. The rows for this side change as
+	// we go, and outer joins need to see them.
+	var err error
+	if b.otherPending, err = other.pendingWrites(ctx.WriteBatch); err != nil {
+		return errors.WithStack(err)
+	}
+	if b.inputPending, err = input.pendingWrites(ctx.WriteBatch); err != nil {
+		return errors.WithStack(err)
+	}
+
+	var eventTime *common.Timestamp
+	for i := 0; i < numRows; i++ {
+		prevRow := rowsBatch.PreviousRow(i)
+		currRow := rowsBatch.CurrentRow(i)
+		if j.interval != nil && currRow != nil && !currRow.IsNull(input.timeCol) {
+			ts := currRow.GetTimestamp(input.timeCol)
+			if eventTime == nil || ts.Compare(*eventTime) > 0 {
+				eventTime = &ts
+			}
+		}
+		prevJoinKey, err := input.joinKeyIfCanMatch(prevRow)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		currJoinKey, err := input.joinKeyIfCanMatch(currRow)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		if err := j.handleChange(b, prevRow, currRow, prevJoinKey, currJoinKey); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	if eventTime != nil {
+		if err := j.expireRows(b, *eventTime); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	b.writes.addToBatch(ctx.WriteBatch)
+
+	if len(b.entries) == 0 {
+		return nil
+	}
+	return j.parent.HandleRows(NewRowsBatch(b.results, b.entries), ctx)
+}
+
+// handleChange handles a change to a row from one side of the join. Either the previous or current version of the row
+// can be nil, and their join keys are nil if they can't match any rows.
+//
+// The previous and current versions are handled together, so that if the output for them has the same key it becomes
+// an update. This matters as a write batch applies deletes after puts, so a delete and an insert of the same key in
+// one batch would leave the key deleted.
+func (j *Join) handleChange(b *joinBatch, prevRow *common.Row, currRow *common.Row, prevJoinKey []byte,
+	currJoinKey []byte) error {
+	sameKey := prevJoinKey != nil && bytes.Equal(prevJoinKey, currJoinKey)
+	var prevMatches, currMatches []*common.Row
+	var err error
+	if prevRow != nil && prevJoinKey != nil {
+		if prevMatches, err = b.other.lookupRows(b.shardID, prevJoinKey, b.otherPending, b.otherRows); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	if sameKey {
+		currMatches = prevMatches
+	} else if currRow != nil && currJoinKey != nil {
+		if currMatches, err = b.other.lookupRows(b.shardID, currJoinKey, b.otherPending, b.otherRows); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+
+	// If the other side is the outer side of an outer join, the rows there which had no matches before this change are
+	// currently output padded with nulls
+	otherPreserved := j.preserves(b.other)
+	var prevMatchedBefore, currMatchedBefore []bool
+	if otherPreserved {
+		if prevMatchedBefore, err = j.haveMatches(b, prevMatches, prevJoinKey); err != nil {
+			return errors.WithStack(err)
+		}
+		if !sameKey {
+			if currMatchedBefore, err = j.haveMatches(b, currMatches, currJoinKey); err != nil {
+				return errors.WithStack(err)
+			}
+		}
+	}
+
+	// Update the stored rows for this side. Rows which can't match are not stored as they will never be looked up.
+	if prevRow != nil && prevJoinKey != nil {
+		if err := b.input.deleteRow(b, prevRow, prevJoinKey); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	if currRow != nil && currJoinKey != nil {
+		if err := b.input.storeRow(b, currRow, currJoinKey); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+
+	prevMatched, currMatched := false, false
+	if sameKey {
+		// The join key hasn't changed, so the same rows match as before and each of them becomes an update
+		for _, match := range prevMatches {
+			pm, cm, err := j.appendResult(b, prevRow, currRow, match)
+			if err != nil {
+				return errors.WithStack(err)
+			}
+			prevMatched = prevMatched || pm
+			currMatched = currMatched || cm
+		}
+	} else {
+		for _, match := range prevMatches {
+			pm, _, err := j.appendResult(b, prevRow, nil, match)
+			if err != nil {
+				return errors.WithStack(err)
+			}
+			prevMatched = prevMatched || pm
+		}
+		for _, match := range currMatches {
+			_, cm, err := j.appendResult(b, nil, currRow, match)
+			if err != nil {
+				return errors.WithStack(err)
+			}
+			currMatched = currMatched || cm
+		}
+	}
+	if j.preserves(b.input) {
+		// Rows from the outer side of an outer join which don't match anything are output once, padded with nulls
+		var prevPadded, currPadded *common.Row
+		if !prevMatched {
+			prevPadded = prevRow
+		}
+		if !currMatched {
+			currPadded = currRow
+		}
+		if err := j.appendPaddedResult(b, b.input, prevPadded, currPadded); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	if otherPreserved {
+		if err := j.updatePaddedRows(b, prevMatches, prevMatchedBefore, prevJoinKey); err != nil {
+			return errors.WithStack(err)
+		}
+		if !sameKey {
+			if err := j.updatePaddedRows(b, currMatches, currMatchedBefore, currJoinKey); err != nil {
+				return errors.WithStack(err)
+			}
+		}
+	}
+	return nil
+}
+
+// updatePaddedRows is called after a change to the inner side of an outer join. Rows on the outer side which have
+// gained their first match lose their null padded row, and those which have lost their last match get it back.
+func (j *Join) updatePaddedRows(b *joinBatch, otherRows []*common.Row, matchedBefore []bool, joinKey []byte) error {
+	matchedAfter, err := j.haveMatches(b, otherRows, joinKey)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	for i, otherRow := range otherRows {
+		if matchedBefore[i] && !matchedAfter[i] {
+			err = j.appendPaddedResult(b, b.other, nil, otherRow)
+		} else if !matchedBefore[i] && matchedAfter[i] {
+			err = j.appendPaddedResult(b, b.other, otherRow, nil)
+		}
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	return nil
+}
+
+// haveMatches returns, for each of the given rows from the other side, whether it matches any of the rows currently
+// stored for this side with the join key
+func (j *Join) haveMatches(b *joinBatch, otherRows []*common.Row, joinKey []byte) ([]bool, error) {
+	res := make([]bool, len(otherRows))
+	if len(otherRows) == 0 {
+		return res, nil
+	}
+	inputRows, err := b.input.lookupRows(b.shardID, joinKey, b.inputPending, b.inputRows)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	for i, otherRow := range otherRows {
+		for _, inputRow := range inputRows {
+			ok, err := j.satisfiesOtherConds(b.input, inputRow, otherRow)
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+			if ok {
+				res[i] = true
+				break
+			}
+		}
+	}
+	return res, nil
+}
+
+// appendResult joins the previous and current versions of a row from one side with a matching row from the other
+// side, and appends the resulting change to the results. It returns whether the previous and current versions
+// satisfied the join's other conditions.
+func (j *Join) appendResult(b *joinBatch, prevRow *common.Row, currRow *common.Row, match *common.Row) (bool, bool, error) {
+	pi := -1
+	if prevRow != nil {
+		ok, err := j.appendJoinedRow(b.input, prevRow, match, b.results)
+		if err != nil {
+			return false, false, errors.WithStack(err)
+		}
+		if ok {
+			pi = b.results.RowCount() - 1
+		}
+	}
+	ci := -1
+	if currRow != nil {
+		ok, err := j.appendJoinedRow(b.input, currRow, match, b.results)
+		if err != nil {
+			return false, false, errors.WithStack(err)
+		}
+		if ok {
+			ci = b.results.RowCount() - 1
+		}
+	}
+	if pi != -1 || ci != -1 {
+		b.entries = append(b.entries, NewRowsEntry(pi, ci))
+	}
+	return pi != -1, ci != -1, nil
+}
+
+// appendPaddedResult appends a change to a row from the outer side of an outer join that is output without a match.
+// Either row can be nil.
+func (j *Join) appendPaddedResult(b *joinBatch, input *JoinInput, prevRow *common.Row, currRow *common.Row) error {
+	pi := -1
+	if prevRow != nil {
+		if err := j.appendPaddedRow(input, prevRow, b.results); err != nil {
+			return errors.WithStack(err)
+		}
+		pi = b.results.RowCount() - 1
+	}
+	ci := -1
+	if currRow != nil {
+		if err := j.appendPaddedRow(input, currRow, b.results); err != nil {
+			return errors.WithStack(err)
+		}
+		ci = b.results.RowCount() - 1
+	}
+	if pi != -1 || ci != -1 {
+		b.entries = append(b.entries, NewRowsEntry(pi, ci))
+	}
+	return nil
+}
+
+// appendJoinedRow appends the joined row to the results if it satisfies the join's other conditions, and returns
+// whether it did
+func (j *Join) appendJoinedRow(input *JoinInput, row *common.Row, match *common.Row, results *common.Rows) (bool, error) {
+	ok, err := j.satisfiesOtherConds(input, row, match)
+	if err != nil || !ok {
+		return false, errors.WithStack(err)
+	}
+	if err := input.appendCols(row, input.outputCols, results); err != nil {
+		return false, errors.WithStack(err)
+	}
+	if err := j.otherInput(input).appendCols(match, j.otherInput(input).outputCols, results); err != nil {
+		return false, errors.WithStack(err)
+	}
+	return true, nil
+}
+
+func (j *Join) appendPaddedRow(input *JoinInput, row *common.Row, results *common.Rows) error {
+	if err := input.appendCols(row, input.outputCols, results); err != nil {
+		return errors.WithStack(err)
+	}
+	return j.otherInput(input).appendCols(nil, j.otherInput(input).outputCols, results)
+}
+
+// satisfiesOtherConds returns whether a row from the input and a row from the other side satisfy the join's other
+// conditions. These are evaluated against all the columns of both children that the planner knows about, some of which
+// may not be in the output.
+func (j *Join) satisfiesOtherConds(input *JoinInput, row *common.Row, match *common.Row) (bool, error) {
+	if len(j.otherConds) == 0 {
+		return true, nil
+	}
+	condRows := j.condRowsFactory.NewRows(1)
+	if err := input.appendCols(row, input.condCols, condRows); err != nil {
+		return false, errors.WithStack(err)
+	}
+	other := j.otherInput(input)
+	if err := other.appendCols(match, other.condCols, condRows); err != nil {
+		return false, errors.WithStack(err)
+	}
+	condRow := condRows.GetRow(0)
+	return evalConditions(j.otherConds, &condRow)
+}
+
+func (j *JoinInput) setChild(child PushExecutor) error {
+	j.colTypes = child.ColTypes()
+	j.colsVisible = child.ColsVisible()
+	j.keyCols = child.KeyCols()
+	j.rowsFactory = common.NewRowsFactory(j.colTypes)
+	if len(j.colTypes) < j.numCols {
+		return errors.Errorf("join child has %d columns, expected at least %d", len(j.colTypes), j.numCols)
+	}
+
+	// The key of the internal table starts with the join key so we can find the matching rows with a prefix scan, it
+	// is followed by the key of the child so each row is stored separately
+	pkCols := append([]int{}, j.joinCols...)
+	for _, keyCol := range j.keyCols {
+		if !containsInt(pkCols, keyCol) {
+			pkCols = append(pkCols, keyCol)
+		}
+	}
+	j.TableInfo.PrimaryKeyCols = pkCols
+	j.TableInfo.ColumnTypes = j.colTypes
+	return nil
+}
+
+func (j *JoinInput) ReCalcSchemaFromChildren() error {
+	// NOOP
+	return nil
+}
+
+// HandleRows is called on the shard where the rows were produced; it forwards them to the shards that own their join
+// keys
+func (j *JoinInput) HandleRows(rowsBatch RowsBatch, ctx *ExecutionContext) error {
+	forward := func(remoteShardID uint64, prevRow *common.Row, currRow *common.Row) error {
+		return forwardRow(ctx, j.colTypes, j.TableInfo.ID, j.TableInfo.ID, remoteShardID, prevRow, currRow)
+	}
+
+	numRows := rowsBatch.Len()
+	for i := 0; i < numRows; i++ {
+		prevRow := rowsBatch.PreviousRow(i)
+		currRow := rowsBatch.CurrentRow(i)
+		prevShardID, prevOk, err := j.forwardShard(prevRow)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		currShardID, currOk, err := j.forwardShard(currRow)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		if prevOk && currOk && prevShardID == currShardID {
+			// The previous and current rows must be handled together if they end up on the same shard
+			if err := forward(currShardID, prevRow, currRow); err != nil {
+				return err
+			}
+			continue
+		}
+		if prevOk {
+			if err := forward(prevShardID, prevRow, nil); err != nil {
+				return err
+			}
+		}
+		if currOk {
+			if err := forward(currShardID, nil, currRow); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// HandleRemoteRows is called on the shard that owns the join key
+func (j *JoinInput) HandleRemoteRows(rowsBatch RowsBatch, ctx *ExecutionContext) error {
+	return j.join.handleRemoteRows(j, rowsBatch, ctx)
+}
+
+// forwardShard returns the shard that owns the join key of the row. It returns false if the row does not need to be
+// forwarded as it can't contribute to the output.
+func (j *JoinInput) forwardShard(row *common.Row) (uint64, bool, error) {
+	if row == nil {
+		return 0, false, nil
+	}
+	var joinKey []byte
+	var err error
+	if j.join.preserves(j) {
+		// Rows from the outer side of an outer join are always output, even if they can't match anything
+		joinKey, err = j.encodeJoinKey(row)
+	} else {
+		joinKey, err = j.joinKeyIfCanMatch(row)
+	}
+	if err != nil || joinKey == nil {
+		return 0, false, errors.WithStack(err)
+	}
+	shardID, err := j.join.sharder.CalculateShard(sharder.ShardTypeHash, joinKey)
+	if err != nil {
+		return 0, false, errors.WithStack(err)
+	}
+	return shardID, true, nil
+}
+
+// joinKeyIfCanMatch returns the encoded join key for the row, or nil if the row can never match any row from the other
+// side, i.e. if any of the join key columns are null or the row does not satisfy the conditions for this side
+func (j *JoinInput) joinKeyIfCanMatch(row *common.Row) ([]byte, error) {
+	if row == nil {
+		return nil, nil
+	}
+	for _, joinCol := range j.joinCols {
+		if row.IsNull(joinCol) {
+			return nil, nil
+		}
+	}
+	if j.join.interval != nil && row.IsNull(j.timeCol) {
+		return nil, nil
+	}
+	ok, err := evalConditions(j.conds, row)
+	if err != nil || !ok {
+		return nil, err
+	}
+	return j.encodeJoinKey(row)
+}
+
+// encodeJoinKey encodes the join key of the row. Rows from the outer side of an outer join can have null join key
+// columns, so the encoding needs to handle them.
+func (j *JoinInput) encodeJoinKey(row *common.Row) ([]byte, error) {
+	return common.EncodeIndexKeyCols(row, j.joinCols, j.colTypes, nil)
+}
+
+func (j *JoinInput) storeRow(b *joinBatch, row *common.Row, joinKey []byte) error {
+	key, err := j.encodeStorageKey(row, b.shardID)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	value, err := common.EncodeRow(row, j.colTypes, nil)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	b.writes.put(key, value)
+	b.inputPending.put(joinKey, key, value)
+	if j.join.interval != nil {
+		expiryKey, err := j.encodeExpiryKey(row, key, b.shardID)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		b.writes.put(expiryKey, []byte{})
+	}
+	return nil
+}
+
+func (j *JoinInput) deleteRow(b *joinBatch, row *common.Row, joinKey []byte) error {
+	key, err := j.encodeStorageKey(row, b.shardID)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	b.writes.delete(key)
+	b.inputPending.delete(joinKey, key)
+	if j.join.interval != nil {
+		expiryKey, err := j.encodeExpiryKey(row, key, b.shardID)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		b.writes.delete(expiryKey)
+	}
+	return nil
+}
+
+// encodeStorageKey encodes the key of the row in the internal table. The primary key starts with the join key columns,
+// which are encoded the same way as the join key so that rows with a join key can be found with a prefix scan.
+func (j *JoinInput) encodeStorageKey(row *common.Row, shardID uint64) ([]byte, error) {
+	keyBytes := table.EncodeTableKeyPrefix(j.TableInfo.ID, shardID, 32)
+	keyBytes, err := common.EncodeIndexKeyCols(row, j.joinCols, j.colTypes, keyBytes)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return common.EncodeKeyCols(row, j.TableInfo.PrimaryKeyCols[len(j.joinCols):], j.colTypes, keyBytes)
+}
+
+// lookupRows returns the stored rows of this side of the join with the given join key
+func (j *JoinInput) lookupRows(shardID uint64, joinKey []byte, pending *joinPendingWrites, rows *common.Rows) ([]*common.Row, error) {
+	prefix := table.EncodeTableKeyPrefix(j.TableInfo.ID, shardID, 16+len(joinKey))
+	prefix = append(prefix, joinKey...)
+	pairs, err := j.join.storage.LocalScan(prefix, common.IncrementBytesBigEndian(prefix), -1)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	values := make(map[string][]byte, len(pairs))
+	for _, pair := range pairs {
+		values[string(pair.Key)] = pair.Value
+	}
+	for k, v := range pending.puts[string(joinKey)] {
+		values[k] = v
+	}
+	for k := range values {
+		if _, ok := pending.deletes[k]; ok {
+			delete(values, k)
+		}
+	}
+	if len(values) == 0 {
+		return nil, nil
+	}
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	matches := make([]*common.Row, len(keys))
+	for i, k := range keys {
+		if err := common.DecodeRow(values[k], j.colTypes, rows); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		row := rows.GetRow(rows.RowCount() - 1)
+		matches[i] = &row
+	}
+	return matches, nil
+}
+
+// joinPendingWrites holds the writes to a join internal table that are in a write batch that has not been committed
+// yet. Puts are grouped by join key.
+type joinPendingWrites struct {
+	puts    map[string]map[string][]byte
+	deletes map[string]struct{}
+}
+
+func (j *JoinInput) pendingWrites(writeBatch *cluster.WriteBatch) (*joinPendingWrites, error) {
+	pending := &joinPendingWrites{
+		puts:    map[string]map[string][]byte{},
+		deletes: map[string]struct{}{},
+	}
+	if !writeBatch.HasWrites() {
+		return pending, nil
+	}
+	prefix := table.EncodeTableKeyPrefix(j.TableInfo.ID, writeBatch.ShardID, 16)
+	rows := j.rowsFactory.NewRows(1)
+	if err := writeBatch.ForEachPut(func(k []byte, v []byte) error {
+		if !bytes.HasPrefix(k, prefix) {
+			return nil
+		}
+		if err := common.DecodeRow(v, j.colTypes, rows); err != nil {
+			return errors.WithStack(err)
+		}
+		row := rows.GetRow(rows.RowCount() - 1)
+		joinKey, err := j.encodeJoinKey(&row)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		pending.addPut(joinKey, k, v)
+		return nil
+	}); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if err := writeBatch.ForEachDelete(func(k []byte) error {
+		if bytes.HasPrefix(k, prefix) {
+			pending.deletes[string(k)] = struct{}{}
+		}
+		return nil
+	}); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return pending, nil
+}
+
+func (p *joinPendingWrites) addPut(joinKey []byte, key []byte, value []byte) {
+	sJoinKey := string(joinKey)
+	puts, ok := p.puts[sJoinKey]
+	if !ok {
+		puts = map[string][]byte{}
+		p.puts[sJoinKey] = puts
+	}
+	puts[string(key)] = value
+}
+
+// put records a put made while handling a batch. Unlike a write batch, a put after a delete of the same key wins.
+func (p *joinPendingWrites) put(joinKey []byte, key []byte, value []byte) {
+	p.addPut(joinKey, key, value)
+	delete(p.deletes, string(key))
+}
+
+func (p *joinPendingWrites) delete(joinKey []byte, key []byte) {
+	delete(p.puts[string(joinKey)], string(key))
+	p.deletes[string(key)] = struct{}{}
+}
+
+// appendCols appends the columns of the row into the positions given by cols, skipping any which are -1. If the row
+// is nil, nulls are appended instead.
+func (j *JoinInput) appendCols(row *common.Row, cols []int, out *common.Rows) error {
+	for i, colType := range j.colTypes {
+		outCol := cols[i]
+		if outCol == -1 {
+			continue
+		}
+		if row == nil || row.IsNull(i) {
+			out.AppendNullToColumn(outCol)
+			continue
+		}
+		switch colType.Type {
+		case common.TypeTinyInt, common.TypeInt, common.TypeBigInt, common.TypeBoolean:
+			out.AppendInt64ToColumn(outCol, row.GetInt64(i))
+		case common.TypeDouble:
+			out.AppendFloat64ToColumn(outCol, row.GetFloat64(i))
+		case common.TypeVarchar, common.TypeVarbinary:
+			out.AppendStringToColumn(outCol, row.GetString(i))
+		case common.TypeDecimal:
+			out.AppendDecimalToColumn(outCol, row.GetDecimal(i))
+		case common.TypeTimestamp, common.TypeDate:
+			out.AppendTimestampToColumn(outCol, row.GetTimestamp(i))
+		case common.TypeJSON:
+			out.AppendJSONToColumn(outCol, row.GetJSON(i))
+		default:
+			return errors.Errorf("unexpected column type %v", colType)
+		}
+	}
+	return nil
+}
+
+// joinWrites collects the writes to a join internal table made while handling a batch. A key can be written more than
+// once in a batch, but a write batch applies all puts before all deletes, so only the last write to each key is added.
+type joinWrites struct {
+	keys   []string
+	values map[string][]byte
+}
+
+func newJoinWrites() *joinWrites {
+	return &joinWrites{values: map[string][]byte{}}
+}
+
+func (w *joinWrites) put(key []byte, value []byte) {
+	sKey := string(key)
+	if _, ok := w.values[sKey]; !ok {
+		w.keys = append(w.keys, sKey)
+	}
+	w.values[sKey] = value
+}
+
+func (w *joinWrites) delete(key []byte) {
+	w.put(key, nil)
+}
+
+func (w *joinWrites) addToBatch(writeBatch *cluster.WriteBatch) {
+	for _, key := range w.keys {
+		value := w.values[key]
+		if value == nil {
+			writeBatch.AddDelete([]byte(key))
+		} else {
+			writeBatch.AddPut([]byte(key), value)
+		}
+	}
+}
+
+// evalConditions returns true if the row satisfies all of the conditions. A condition which evaluates to null is not
+// satisfied.
+func evalConditions(conds []*common.Expression, row *common.Row) (bool, error) {
+	for _, cond := range conds {
+		accept, isNull, err := cond.EvalBoolean(row)
+		if err != nil {
+			return false, errors.WithStack(err)
+		}
+		if isNull || !accept {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// keyTypesCompatible returns true if values of the two types have the same key encoding
+func keyTypesCompatible(type1 common.ColumnType, type2 common.ColumnType) bool {
+	switch type1.Type {
+	case common.TypeTinyInt, common.TypeInt, common.TypeBigInt:
+		return type2.Type == common.TypeTinyInt || type2.Type == common.TypeInt || type2.Type == common.TypeBigInt
+	case common.TypeDecimal:
+		return type2.Type == common.TypeDecimal && type1.DecPrecision == type2.DecPrecision && type1.DecScale == type2.DecScale
+	default:
+		return type1.Type == type2.Type
+	}
+}
+
+func containsInt(vals []int, val int) bool {
+	for _, v := range vals {
+		if v == val {
+			return true
+		}
+	}
+	return false
+}
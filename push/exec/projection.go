@@ -105,7 +105,7 @@ func (p *PushProjection) calcProjection(row *common.Row, result *common.Rows) er
 	for j, projColumn := range p.projColumns {
 		colType := p.colTypes[j]
 		switch colType.Type {
-		case common.TypeTinyInt, common.TypeInt, common.TypeBigInt:
+		case common.TypeTinyInt, common.TypeInt, common.TypeBigInt, common.TypeBoolean:
 			val, null, err := projColumn.EvalInt64(row)
 			if err != nil {
 				return errors.WithStack(err)
@@ -125,7 +125,7 @@ func (p *PushProjection) calcProjection(row *common.Row, result *common.Rows) er
 			} else {
 				result.AppendDecimalToColumn(j, val)
 			}
-		case common.TypeVarchar:
+		case common.TypeVarchar, common.TypeVarbinary:
 			val, null, err := projColumn.EvalString(row)
 			if err != nil {
 				return errors.WithStack(err)
@@ -145,7 +145,7 @@ func (p *PushProjection) calcProjection(row *common.Row, result *common.Rows) er
 			} else {
 				result.AppendFloat64ToColumn(j, val)
 			}
-		case common.TypeTimestamp:
+		case common.TypeTimestamp, common.TypeDate:
 			val, null, err := projColumn.EvalTimestamp(row)
 			if err != nil {
 				return errors.WithStack(err)
@@ -155,6 +155,16 @@ func (p *PushProjection) calcProjection(row *common.Row, result *common.Rows) er
 			} else {
 				result.AppendTimestampToColumn(j, val)
 			}
+		case common.TypeJSON:
+			val, null, err := projColumn.EvalJSON(row)
+			if err != nil {
+				return errors.WithStack(err)
+			}
+			if null {
+				result.AppendNullToColumn(j)
+			} else {
+				result.AppendJSONToColumn(j, val)
+			}
 		default:
 			return errors.Errorf("unexpected column type %d", colType)
 		}
@@ -168,18 +178,24 @@ func (p *PushProjection) calcProjection(row *common.Row, result *common.Rows) er
 		j := appendStart + index
 		colType := p.colTypes[j]
 		switch colType.Type {
-		case common.TypeTinyInt, common.TypeInt, common.TypeBigInt:
+		case common.TypeTinyInt, common.TypeInt, common.TypeBigInt, common.TypeBoolean:
 			val := row.GetInt64(colNumber)
 			result.AppendInt64ToColumn(j, val)
 		case common.TypeDecimal:
 			val := row.GetDecimal(colNumber)
 			result.AppendDecimalToColumn(j, val)
-		case common.TypeVarchar:
+		case common.TypeVarchar, common.TypeVarbinary:
 			val := row.GetString(colNumber)
 			result.AppendStringToColumn(j, val)
 		case common.TypeDouble:
 			val := row.GetFloat64(colNumber)
 			result.AppendFloat64ToColumn(j, val)
+		case common.TypeTimestamp, common.TypeDate:
+			val := row.GetTimestamp(colNumber)
+			result.AppendTimestampToColumn(j, val)
+		case common.TypeJSON:
+			val := row.GetJSON(colNumber)
+			result.AppendJSONToColumn(j, val)
 		default:
 			return errors.Errorf("unexpected column type %d", colType)
 		}
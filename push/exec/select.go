@@ -91,10 +91,8 @@ func (p *PushSelect) evalPredicates(row *common.Row) (bool, error) {
 		if err != nil {
 			return false, errors.WithStack(err)
 		}
-		if isNull {
-			return false, errors.Error("null returned from evaluating select predicate")
-		}
-		if !accept {
+		// As in SQL, a predicate which evaluates to null does not match the row
+		if isNull || !accept {
 			return false, nil
 		}
 	}
@@ -0,0 +1,212 @@
+package exec
+
+import (
+	"sync"
+	"time"
+
+	"github.com/squareup/pranadb/cluster"
+	"github.com/squareup/pranadb/common"
+	"github.com/squareup/pranadb/errors"
+	"github.com/squareup/pranadb/kafka"
+	"github.com/squareup/pranadb/table"
+)
+
+const deliverMaxBatchSize = 1000
+
+// SinkExecutor publishes the changes to a materialized view to a Kafka topic. Each change is encoded as a message and
+// written to an outbox table in the same batch as the change to the materialized view itself. Once the batch is
+// committed the messages in the outbox are sent to Kafka and then deleted. If the node fails before they are deleted
+// they are sent again, so delivery is at-least-once.
+// Inserts and updates are sent as a message with the key and the new value of the row, and deletes are sent as a
+// tombstone - a message with the key and no value - so the topic can be compacted.
+type SinkExecutor struct {
+	pushExecutorBase
+	SinkInfo         *common.SinkInfo
+	TableInfo        *common.TableInfo // The table info of the materialized view that the sink consumes from
+	encoder          kafka.MessageEncoder
+	visibleCols      *Scan // Projects the rows to their visible columns, if the materialized view has hidden ones
+	producer         kafka.MessageProducer
+	store            cluster.Cluster
+	scheduleDelivery func(shardID uint64)
+	seqLock          sync.Mutex
+	nextSequences    map[uint64]uint64
+}
+
+func NewSinkExecutor(sinkInfo *common.SinkInfo, tableInfo *common.TableInfo, encoder kafka.MessageEncoder,
+	producer kafka.MessageProducer, store cluster.Cluster, scheduleDelivery func(shardID uint64)) (*SinkExecutor, error) {
+	sinkExec := &SinkExecutor{
+		pushExecutorBase: pushExecutorBase{
+			colTypes:    tableInfo.ColumnTypes,
+			keyCols:     tableInfo.PrimaryKeyCols,
+			rowsFactory: common.NewRowsFactory(tableInfo.ColumnTypes),
+		},
+		SinkInfo:         sinkInfo,
+		TableInfo:        tableInfo,
+		encoder:          encoder,
+		producer:         producer,
+		store:            store,
+		scheduleDelivery: scheduleDelivery,
+		nextSequences:    make(map[uint64]uint64),
+	}
+	var visibleCols []int
+	for i, visible := range tableInfo.ColsVisible {
+		if visible {
+			visibleCols = append(visibleCols, i)
+		}
+	}
+	if len(visibleCols) < len(tableInfo.ColsVisible) {
+		// Hidden key columns are kept after the visible columns, as the key is encoded from them
+		scan, err := NewScan(tableInfo.Name, visibleCols)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		scan.SetSchema(tableInfo)
+		sinkExec.visibleCols = scan
+		sinkExec.colTypes = scan.colTypes
+		sinkExec.keyCols = scan.keyCols
+	}
+	return sinkExec, nil
+}
+
+func (s *SinkExecutor) ReCalcSchemaFromChildren() error {
+	return nil
+}
+
+func (s *SinkExecutor) HandleRows(rowsBatch RowsBatch, ctx *ExecutionContext) error {
+	numEntries := rowsBatch.Len()
+	if numEntries == 0 {
+		return nil
+	}
+	shardID := ctx.WriteBatch.ShardID
+	seq, err := s.reserveSequences(shardID, numEntries)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	var visibleRows *common.Rows
+	if s.visibleCols != nil {
+		visibleRows = s.visibleCols.rowsFactory.NewRows(numEntries)
+	}
+	for i := 0; i < numEntries; i++ {
+		row := rowsBatch.CurrentRow(i)
+		tombstone := row == nil
+		if tombstone {
+			// It's a delete - we send a tombstone, with the key of the deleted row
+			row = rowsBatch.PreviousRow(i)
+		}
+		if s.visibleCols != nil {
+			if err := s.visibleCols.appendResultRow(row, visibleRows); err != nil {
+				return errors.WithStack(err)
+			}
+			visibleRow := visibleRows.GetRow(visibleRows.RowCount() - 1)
+			row = &visibleRow
+		}
+		// The outbox only keeps the key and value of the message
+		message, err := s.encoder.EncodeMessage(row, s.colTypes, s.keyCols, time.Time{})
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		var value []byte
+		if !tombstone {
+			value = message.Value
+		}
+		outboxKey := table.EncodeTableKeyPrefix(s.SinkInfo.ID, shardID, 24)
+		outboxKey = common.AppendUint64ToBufferBE(outboxKey, seq)
+		seq++
+		ctx.WriteBatch.AddPut(outboxKey, encodeOutboxMessage(message.Key, value))
+	}
+	ctx.WriteBatch.AddCommittedCallback(func() error {
+		s.scheduleDelivery(shardID)
+		return nil
+	})
+	return nil
+}
+
+// Deliver sends the messages in the outbox of the shard to Kafka and deletes them once they have been acknowledged.
+// It must be called on the scheduler of the shard.
+func (s *SinkExecutor) Deliver(shardID uint64) error {
+	startPrefix := table.EncodeTableKeyPrefix(s.SinkInfo.ID, shardID, 16)
+	endPrefix := table.EncodeTableKeyPrefix(s.SinkInfo.ID+1, shardID, 16)
+	for {
+		kvPairs, err := s.store.LocalScan(startPrefix, endPrefix, deliverMaxBatchSize)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		if len(kvPairs) == 0 {
+			return nil
+		}
+		messages := make([]*kafka.Message, len(kvPairs))
+		wb := cluster.NewWriteBatch(shardID)
+		for i, kvPair := range kvPairs {
+			messages[i] = decodeOutboxMessage(kvPair.Value)
+			wb.AddDelete(kvPair.Key)
+		}
+		if err := s.producer.SendMessages(messages); err != nil {
+			return errors.WithStack(err)
+		}
+		if err := s.store.WriteBatch(wb); err != nil {
+			return errors.WithStack(err)
+		}
+		if len(kvPairs) < deliverMaxBatchSize {
+			return nil
+		}
+		startPrefix = common.IncrementBytesBigEndian(kvPairs[len(kvPairs)-1].Key)
+	}
+}
+
+func (s *SinkExecutor) Producer() kafka.MessageProducer {
+	return s.producer
+}
+
+// ResetSequences forgets the next sequence of the outbox of the shard, so it's read from the outbox again. It must be
+// called when this node becomes the processor of the shard, as another node might have added messages to the outbox
+// since this node last processed it.
+func (s *SinkExecutor) ResetSequences(shardID uint64) {
+	s.seqLock.Lock()
+	defer s.seqLock.Unlock()
+	delete(s.nextSequences, shardID)
+}
+
+// reserveSequences reserves numSequences consecutive sequences for outbox messages in the shard and returns the first
+// one. Messages are delivered in sequence order so changes to the same key are sent in the order they were made.
+func (s *SinkExecutor) reserveSequences(shardID uint64, numSequences int) (uint64, error) {
+	s.seqLock.Lock()
+	defer s.seqLock.Unlock()
+	seq, ok := s.nextSequences[shardID]
+	if !ok {
+		// There might be messages left in the outbox which weren't delivered before the node was stopped, or before
+		// another node processing the shard lost it, we must carry on after them
+		startPrefix := table.EncodeTableKeyPrefix(s.SinkInfo.ID, shardID, 16)
+		endPrefix := table.EncodeTableKeyPrefix(s.SinkInfo.ID+1, shardID, 16)
+		kvPairs, err := s.store.LocalScan(startPrefix, endPrefix, -1)
+		if err != nil {
+			return 0, errors.WithStack(err)
+		}
+		if len(kvPairs) > 0 {
+			lastSeq, _ := common.ReadUint64FromBufferBE(kvPairs[len(kvPairs)-1].Key, 16)
+			seq = lastSeq + 1
+		}
+	}
+	s.nextSequences[shardID] = seq + uint64(numSequences)
+	return seq, nil
+}
+
+// The format of an outbox message is key length (uint32), key, then value, if it's not a tombstone
+func encodeOutboxMessage(key []byte, value []byte) []byte {
+	buff := make([]byte, 0, 4+len(key)+len(value))
+	buff = common.AppendUint32ToBufferLE(buff, uint32(len(key)))
+	buff = append(buff, key...)
+	return append(buff, value...)
+}
+
+func decodeOutboxMessage(buff []byte) *kafka.Message {
+	keyLen, offset := common.ReadUint32FromBufferLE(buff, 0)
+	key := buff[offset : offset+int(keyLen)]
+	var value []byte
+	if len(buff) > offset+int(keyLen) {
+		value = buff[offset+int(keyLen):]
+	}
+	return &kafka.Message{
+		Key:   key,
+		Value: value,
+	}
+}
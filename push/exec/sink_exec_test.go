@@ -0,0 +1,72 @@
+package exec
+
+import (
+	"testing"
+
+	"github.com/squareup/pranadb/cluster"
+	"github.com/squareup/pranadb/cluster/fake"
+	"github.com/squareup/pranadb/common"
+	"github.com/squareup/pranadb/kafka"
+	"github.com/squareup/pranadb/table"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSinkContinuesAfterOutboxMessagesOfOtherNode(t *testing.T) {
+	store := fake.NewFakeCluster(0, 1)
+	shardID := store.GetAllShardIDs()[0]
+	sinkExec := newTestSinkExecutor(t, store)
+
+	handleSinkRows(t, sinkExec, store, shardID, []interface{}{1, "a"}, []interface{}{2, "b"})
+
+	// Another node processes the shard and adds messages to the outbox, which haven't been delivered when this node
+	// processes the shard again
+	wb := cluster.NewWriteBatch(shardID)
+	for seq := uint64(2); seq < 4; seq++ {
+		outboxKey := common.AppendUint64ToBufferBE(table.EncodeTableKeyPrefix(sinkExec.SinkInfo.ID, shardID, 24), seq)
+		wb.AddPut(outboxKey, encodeOutboxMessage([]byte("other"), []byte("node")))
+	}
+	require.NoError(t, store.WriteBatch(wb))
+	sinkExec.ResetSequences(shardID)
+
+	handleSinkRows(t, sinkExec, store, shardID, []interface{}{3, "c"})
+
+	kvPairs, err := store.LocalScan(table.EncodeTableKeyPrefix(sinkExec.SinkInfo.ID, shardID, 16),
+		table.EncodeTableKeyPrefix(sinkExec.SinkInfo.ID+1, shardID, 16), -1)
+	require.NoError(t, err)
+	require.Equal(t, 5, len(kvPairs))
+	for i, kvPair := range kvPairs {
+		seq, _ := common.ReadUint64FromBufferBE(kvPair.Key, 16)
+		require.Equal(t, uint64(i), seq)
+		message := decodeOutboxMessage(kvPair.Value)
+		if i == 2 || i == 3 {
+			require.Equal(t, "other", string(message.Key))
+		} else {
+			require.NotEqual(t, "other", string(message.Key))
+		}
+	}
+}
+
+func newTestSinkExecutor(t *testing.T, store cluster.Cluster) *SinkExecutor {
+	t.Helper()
+	colTypes := []common.ColumnType{common.BigIntColumnType, common.VarcharColumnType}
+	tableInfo := &common.TableInfo{
+		ID:             common.UserTableIDBase,
+		Name:           "test_mv",
+		PrimaryKeyCols: []int{0},
+		ColumnNames:    []string{"id", "name"},
+		ColumnTypes:    colTypes,
+	}
+	sinkInfo := &common.SinkInfo{ID: common.UserTableIDBase + 1, Name: "test_sink"}
+	encoder, err := kafka.NewSinkMessageEncoder(common.KafkaEncodingJSON, common.KafkaEncodingJSON, colTypes, []int{0})
+	require.NoError(t, err)
+	sinkExec, err := NewSinkExecutor(sinkInfo, tableInfo, encoder, nil, store, func(shardID uint64) {})
+	require.NoError(t, err)
+	return sinkExec
+}
+
+func handleSinkRows(t *testing.T, sinkExec *SinkExecutor, store cluster.Cluster, shardID uint64, rows ...[]interface{}) {
+	t.Helper()
+	ctx := &ExecutionContext{WriteBatch: cluster.NewWriteBatch(shardID)}
+	require.NoError(t, sinkExec.HandleRows(NewCurrentRowsBatch(toRows(t, rows, sinkExec.TableInfo.ColumnTypes)), ctx))
+	require.NoError(t, store.WriteBatch(ctx.WriteBatch))
+}
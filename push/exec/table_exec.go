@@ -28,6 +28,7 @@ type TableExecutor struct {
 	lastSequences      sync.Map
 	fillTableID        uint64
 	uncommittedBatches sync.Map
+	keyOnlyDeletes     bool
 }
 
 func NewTableExecutor(tableInfo *common.TableInfo, store cluster.Cluster) *TableExecutor {
@@ -45,6 +46,14 @@ func NewTableExecutor(tableInfo *common.TableInfo, store cluster.Cluster) *Table
 	}
 }
 
+// NewSourceTableExecutor creates a TableExecutor for a source. The deletes of a source come from Kafka tombstones, so
+// their previous row only has the key, and the stored row is retracted instead.
+func NewSourceTableExecutor(tableInfo *common.TableInfo, store cluster.Cluster) *TableExecutor {
+	te := NewTableExecutor(tableInfo, store)
+	te.keyOnlyDeletes = true
+	return te
+}
+
 func (t *TableExecutor) ReCalcSchemaFromChildren() error {
 	return nil
 }
@@ -102,7 +111,9 @@ func (t *TableExecutor) HandleRows(rowsBatch RowsBatch, ctx *ExecutionContext) e
 	numEntries := rowsBatch.Len()
 	outRows := t.rowsFactory.NewRows(numEntries)
 	rc := 0
-	entries := make([]RowsEntry, numEntries)
+	entries := make([]RowsEntry, 0, numEntries)
+	// The rows already written by the batch, which can have more than one row for a key. A nil value is a delete.
+	written := make(map[string][]byte)
 	for i := 0; i < numEntries; i++ {
 		prevRow := rowsBatch.PreviousRow(i)
 		currentRow := rowsBatch.CurrentRow(i)
@@ -113,9 +124,13 @@ func (t *TableExecutor) HandleRows(rowsBatch RowsBatch, ctx *ExecutionContext) e
 			if err != nil {
 				return errors.WithStack(err)
 			}
-			v, err := t.store.LocalGet(keyBuff)
-			if err != nil {
-				return errors.WithStack(err)
+			var v []byte
+			if !t.TableInfo.AppendOnly {
+				// The rows of an append-only source are never overwritten, so we don't need to look for a previous row
+				v, err = t.getRow(keyBuff, written)
+				if err != nil {
+					return errors.WithStack(err)
+				}
 			}
 			pi := -1
 			if v != nil {
@@ -130,14 +145,14 @@ func (t *TableExecutor) HandleRows(rowsBatch RowsBatch, ctx *ExecutionContext) e
 			outRows.AppendRow(*currentRow)
 			ci := rc
 			rc++
-			entries[i].prevIndex = pi
-			entries[i].currIndex = ci
+			entries = append(entries, NewRowsEntry(pi, ci))
 			var valueBuff []byte
 			valueBuff, err = common.EncodeRow(currentRow, t.colTypes, valueBuff)
 			if err != nil {
 				return errors.WithStack(err)
 			}
 			ctx.WriteBatch.AddPut(keyBuff, valueBuff)
+			written[string(keyBuff)] = valueBuff
 		} else {
 			// It's a delete
 			keyBuff := table.EncodeTableKeyPrefix(t.TableInfo.ID, ctx.WriteBatch.ShardID, 32)
@@ -145,11 +160,25 @@ func (t *TableExecutor) HandleRows(rowsBatch RowsBatch, ctx *ExecutionContext) e
 			if err != nil {
 				return errors.WithStack(err)
 			}
-			outRows.AppendRow(*prevRow)
-			entries[i].prevIndex = rc
-			entries[i].currIndex = -1
+			if t.keyOnlyDeletes {
+				// The previous row of a Kafka tombstone only has the key, so we retract the stored row, if there is one
+				v, err := t.getRow(keyBuff, written)
+				if err != nil {
+					return errors.WithStack(err)
+				}
+				if v == nil {
+					continue
+				}
+				if err := common.DecodeRow(v, t.colTypes, outRows); err != nil {
+					return errors.WithStack(err)
+				}
+			} else {
+				outRows.AppendRow(*prevRow)
+			}
+			entries = append(entries, NewRowsEntry(rc, -1))
 			rc++
 			ctx.WriteBatch.AddDelete(keyBuff)
+			written[string(keyBuff)] = nil
 		}
 	}
 	err := t.handleForwardAndCapture(NewRowsBatch(outRows, entries), ctx)
@@ -157,6 +186,16 @@ func (t *TableExecutor) HandleRows(rowsBatch RowsBatch, ctx *ExecutionContext) e
 	return errors.WithStack(err)
 }
 
+// getRow returns the encoded row with the key, from the rows written by the batch if it has written the key, or nil if
+// there isn't one
+func (t *TableExecutor) getRow(key []byte, written map[string][]byte) ([]byte, error) {
+	if v, ok := written[string(key)]; ok {
+		return v, nil
+	}
+	v, err := t.store.LocalGet(key)
+	return v, errors.WithStack(err)
+}
+
 func (t *TableExecutor) handleForwardAndCapture(rowsBatch RowsBatch, ctx *ExecutionContext) error {
 	if err := t.ForwardToConsumingNodes(rowsBatch, ctx); err != nil {
 		return errors.WithStack(err)
@@ -526,8 +565,24 @@ func (t *TableExecutor) replayChanges(startSeqs map[uint64]int64, endSeqs map[ui
 
 func (t *TableExecutor) GetConsumingMvNames() []string {
 	var mvNames []string
-	for mvName := range t.consumingNodes {
+	for mvName, node := range t.consumingNodes {
+		if _, ok := node.(*ChangeFeedExecutor); ok {
+			// A change feed doesn't stop the table from being dropped - its subscriptions are closed instead
+			continue
+		}
 		mvNames = append(mvNames, mvName)
 	}
 	return mvNames
 }
+
+// RunLocked locks the executor so no rows can be handled, waits for the batches it has already handled to be
+// committed, and then calls f. This lets f take a snapshot of the table which is consistent with the rows its consuming
+// nodes have seen.
+func (t *TableExecutor) RunLocked(f func() error) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if err := t.waitForNoUncommittedBatches(); err != nil {
+		return errors.WithStack(err)
+	}
+	return f()
+}
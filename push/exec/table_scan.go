@@ -120,21 +120,24 @@ func (t *Scan) appendResultRow(row *common.Row, outRows *common.Rows) error {
 		} else {
 			colType := t.colTypes[i]
 			switch colType.Type {
-			case common.TypeTinyInt, common.TypeInt, common.TypeBigInt:
+			case common.TypeTinyInt, common.TypeInt, common.TypeBigInt, common.TypeBoolean:
 				val := row.GetInt64(incomingColIndex)
 				outRows.AppendInt64ToColumn(i, val)
 			case common.TypeDouble:
 				val := row.GetFloat64(incomingColIndex)
 				outRows.AppendFloat64ToColumn(i, val)
-			case common.TypeVarchar:
+			case common.TypeVarchar, common.TypeVarbinary:
 				val := row.GetString(incomingColIndex)
 				outRows.AppendStringToColumn(i, val)
 			case common.TypeDecimal:
 				val := row.GetDecimal(incomingColIndex)
 				outRows.AppendDecimalToColumn(i, val)
-			case common.TypeTimestamp:
+			case common.TypeTimestamp, common.TypeDate:
 				val := row.GetTimestamp(incomingColIndex)
 				outRows.AppendTimestampToColumn(i, val)
+			case common.TypeJSON:
+				val := row.GetJSON(incomingColIndex)
+				outRows.AppendJSONToColumn(i, val)
 			default:
 				return errors.Errorf("unexpected column type %v", colType)
 			}
@@ -94,14 +94,16 @@ func (u *UnionAll) appendRow(index int, inRow *common.Row, out *common.Rows) err
 		} else {
 			colType := u.colTypes[i]
 			switch colType.Type {
-			case common.TypeTinyInt, common.TypeInt, common.TypeBigInt:
+			case common.TypeTinyInt, common.TypeInt, common.TypeBigInt, common.TypeBoolean:
 				out.AppendInt64ToColumn(i, inRow.GetInt64(i))
 			case common.TypeDouble:
 				out.AppendFloat64ToColumn(i, inRow.GetFloat64(i))
-			case common.TypeVarchar:
+			case common.TypeVarchar, common.TypeVarbinary:
 				out.AppendStringToColumn(i, inRow.GetString(i))
-			case common.TypeTimestamp:
+			case common.TypeTimestamp, common.TypeDate:
 				out.AppendTimestampToColumn(i, inRow.GetTimestamp(i))
+			case common.TypeJSON:
+				out.AppendJSONToColumn(i, inRow.GetJSON(i))
 			case common.TypeDecimal:
 				out.AppendDecimalToColumn(i, inRow.GetDecimal(i))
 			default:
@@ -0,0 +1,702 @@
+package exec
+
+import (
+	"bytes"
+	"sort"
+	"time"
+
+	"github.com/squareup/pranadb/aggfuncs"
+	"github.com/squareup/pranadb/cluster"
+	"github.com/squareup/pranadb/common"
+	"github.com/squareup/pranadb/errors"
+	"github.com/squareup/pranadb/metrics"
+	"github.com/squareup/pranadb/sharder"
+	"github.com/squareup/pranadb/table"
+)
+
+// HopWindow describes an aggregation grouped by hopping windows of event time. Windows of the given size start every
+// slide, so each row is aggregated into all of the windows which contain its time.
+type HopWindow struct {
+	TimeCol  int // The index of the time column in the child
+	Size     time.Duration
+	Slide    time.Duration
+	Lateness time.Duration
+}
+
+// SessionWindow describes an aggregation grouped by session windows of event time. A session holds the rows of a group
+// which are less than the gap apart, and lasts from the time of its first row until the gap after its last row, so
+// sessions merge when a row arrives between them.
+type SessionWindow struct {
+	TimeCol  int // The index of the time column in the child
+	Gap      time.Duration
+	Lateness time.Duration
+}
+
+// WindowTables are the internal tables which store the state of a WindowAggregator
+type WindowTables struct {
+	// The child rows of each group by group key|time|child key
+	EventTableInfo *common.TableInfo
+	// The end and the output row of each window by group key|window start
+	WindowTableInfo *common.TableInfo
+	// The windows by the time they close|group key|window start
+	ExpiryTableInfo *common.TableInfo
+	// The watermark each shard has expired its state to
+	WatermarkTableInfo *common.TableInfo
+}
+
+// WindowAggregator is an aggregation grouped by hopping or session windows. Unlike a tumbling window, the window of
+// these isn't a function of a single row: a row can be in several hopping windows, and a row can merge or split
+// sessions. So the aggregator keeps the rows of each group, and recalculates the windows affected by a change from
+// them, outputting the changes to the windows.
+//
+// The child rows are forwarded to the shard which owns their group. As for tumbling windows, the windows are closed by
+// the watermark of the source, so the source must have a watermark column. Once a window is closed its state is
+// deleted, and changes to rows which would only affect closed windows are dropped.
+type WindowAggregator struct {
+	pushExecutorBase
+	WindowTables
+	aggFuncs           []aggfuncs.AggregateFunction
+	argTypes           []common.ColumnType
+	hop                *HopWindow
+	session            *SessionWindow
+	windowCol          int   // The output key column of the window start
+	windowStartCols    []int // The output columns of the window start
+	windowEndCols      []int // The output columns of the window end
+	groupByExprs       []*common.Expression
+	groupByRowsFactory *common.RowsFactory
+	lateRows           metrics.Counter
+	storage            cluster.Cluster
+	sharder            *sharder.Sharder
+}
+
+// NewWindowAggregator creates an aggregation grouped by a window, given its output key columns, one of which is the
+// window start, and the group by expressions other than the window. Any other columns of the window start or end are
+// set to those of each window. The window must then be set.
+func NewWindowAggregator(pkCols []int, aggFunctions []*aggfuncs.AggregateFunctionInfo, groupByExprs []*common.Expression,
+	windowCol int, windowStartCols []int, windowEndCols []int, tables WindowTables, storage cluster.Cluster, sharder *sharder.Sharder) (*WindowAggregator, error) {
+	colTypes := make([]common.ColumnType, len(aggFunctions))
+	argTypes := make([]common.ColumnType, len(aggFunctions))
+	for i, aggFunc := range aggFunctions {
+		if aggFunc.Distinct {
+			return nil, errors.NewPranaErrorf(errors.InvalidStatement, "distinct aggregate functions are not supported with hop or session windows")
+		}
+		colTypes[i] = aggFunc.ReturnType
+		argTypes[i] = aggFunc.ArgType
+	}
+	aggFuncs, err := createAggFunctions(aggFunctions, colTypes)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	var groupByTypes []common.ColumnType
+	for _, pkCol := range pkCols {
+		if pkCol != windowCol {
+			groupByTypes = append(groupByTypes, colTypes[pkCol])
+		}
+	}
+	if len(groupByTypes) != len(groupByExprs) {
+		return nil, errors.Errorf("window aggregation has %d group by expressions but %d key columns", len(groupByExprs), len(pkCols))
+	}
+	return &WindowAggregator{
+		pushExecutorBase: pushExecutorBase{
+			colTypes:    colTypes,
+			keyCols:     pkCols,
+			colsVisible: aggColsVisible(aggFunctions),
+			rowsFactory: common.NewRowsFactory(colTypes),
+		},
+		WindowTables:       tables,
+		aggFuncs:           aggFuncs,
+		argTypes:           argTypes,
+		windowCol:          windowCol,
+		windowStartCols:    append([]int{windowCol}, windowStartCols...),
+		windowEndCols:      windowEndCols,
+		groupByExprs:       groupByExprs,
+		groupByRowsFactory: common.NewRowsFactory(groupByTypes),
+		lateRows:           lateRowsVec.WithLabelValues(tables.WindowTableInfo.Name),
+		storage:            storage,
+		sharder:            sharder,
+	}, nil
+}
+
+// SetHopWindow groups the aggregation by hopping windows
+func (w *WindowAggregator) SetHopWindow(window *HopWindow) {
+	w.hop = window
+}
+
+// SetSessionWindow groups the aggregation by session windows
+func (w *WindowAggregator) SetSessionWindow(window *SessionWindow) {
+	w.session = window
+}
+
+func (w *WindowAggregator) timeCol() int {
+	if w.hop != nil {
+		return w.hop.TimeCol
+	}
+	return w.session.TimeCol
+}
+
+func (w *WindowAggregator) lateness() time.Duration {
+	if w.hop != nil {
+		return w.hop.Lateness
+	}
+	return w.session.Lateness
+}
+
+// HandleRows forwards the child rows to the shards which own their groups
+func (w *WindowAggregator) HandleRows(rowsBatch RowsBatch, ctx *ExecutionContext) error {
+	if !ctx.Watermark.IsZero() {
+		if err := w.closeWindows(ctx.Watermark, ctx.WriteBatch); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	return forwardRowsToGroups(rowsBatch, ctx, w.GetChildren()[0].ColTypes(), w.WindowTableInfo.ID,
+		w.EventTableInfo.ID, func(row *common.Row) ([]byte, error) {
+			return encodeGroupKey(w.groupByExprs, w.groupByRowsFactory, row, ctx.WriteBatch.ShardID, w.EventTableInfo.ID)
+		}, w.sharder)
+}
+
+// ForwardedColTypes returns the column types of the child rows forwarded to the shards which own their groups
+func (w *WindowAggregator) ForwardedColTypes() []common.ColumnType {
+	return w.GetChildren()[0].ColTypes()
+}
+
+// windowBatch holds the state used while handling a batch of child rows on the shard which owns their groups
+type windowBatch struct {
+	shardID uint64
+	wm      *windowWatermark
+	writes  *joinWrites
+	// The groups changed in the batch, by group key without its table prefix, and for hop windows the times of the
+	// changed rows
+	groups     []string
+	groupTimes map[string][]time.Time
+	eventRows  *common.Rows
+	results    *common.Rows
+	entries    []RowsEntry
+}
+
+// HandleRemoteRows is called with the child rows forwarded from other shards
+func (w *WindowAggregator) HandleRemoteRows(rowsBatch RowsBatch, ctx *ExecutionContext) error {
+	numRows := rowsBatch.Len()
+	b := &windowBatch{
+		shardID:    ctx.WriteBatch.ShardID,
+		writes:     newJoinWrites(),
+		groupTimes: map[string][]time.Time{},
+		eventRows:  common.NewRowsFactory(w.GetChildren()[0].ColTypes()).NewRows(numRows),
+		results:    w.rowsFactory.NewRows(numRows),
+	}
+	var err error
+	if b.wm, err = loadWatermark(w.storage, w.WatermarkTableInfo, b.shardID); err != nil {
+		return errors.WithStack(err)
+	}
+	for i := 0; i < numRows; i++ {
+		if err := w.removeEvent(b, rowsBatch.PreviousRow(i)); err != nil {
+			return errors.WithStack(err)
+		}
+		if err := w.addEvent(b, rowsBatch.CurrentRow(i)); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	for _, groupKey := range b.groups {
+		if w.hop != nil {
+			err = w.recalcHopWindows(b, []byte(groupKey), b.groupTimes[groupKey])
+		} else {
+			err = w.recalcSessions(b, []byte(groupKey))
+		}
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	b.writes.addToBatch(ctx.WriteBatch)
+
+	if len(b.entries) == 0 {
+		return nil
+	}
+	return w.parent.HandleRows(NewRowsBatch(b.results, b.entries), ctx)
+}
+
+// closeWindows expires the state of the shard for the windows the watermark of the source has closed
+func (w *WindowAggregator) closeWindows(sourceWatermark time.Time, writeBatch *cluster.WriteBatch) error {
+	b := &windowBatch{shardID: writeBatch.ShardID, writes: newJoinWrites()}
+	var err error
+	if b.wm, err = loadWatermark(w.storage, w.WatermarkTableInfo, b.shardID); err != nil {
+		return errors.WithStack(err)
+	}
+	if advanced, err := b.wm.advance(sourceWatermark, writeBatch); err != nil || !advanced {
+		return err
+	}
+	if err := w.expireWindows(b, b.wm.watermark); err != nil {
+		return errors.WithStack(err)
+	}
+	b.writes.addToBatch(writeBatch)
+	return nil
+}
+
+// eventTime returns the time of the child row, or false if it has none
+func (w *WindowAggregator) eventTime(row *common.Row) (time.Time, bool, error) {
+	if row == nil || row.IsNull(w.timeCol()) {
+		return time.Time{}, false, nil
+	}
+	gt, err := row.GetTimestamp(w.timeCol()).GoTime(time.UTC)
+	if err != nil {
+		return time.Time{}, false, errors.WithStack(err)
+	}
+	return gt, true, nil
+}
+
+// acceptEvent returns true if a row with the time can change any windows which are still open, and counts the row as
+// late if its windows have closed
+func (w *WindowAggregator) acceptEvent(t time.Time, watermark time.Time) bool {
+	if w.hop != nil {
+		// The row is in the windows which start up to a size before it, and the latest of them closes last
+		latest := t.Truncate(w.hop.Slide)
+		if t.Sub(latest) >= w.hop.Size {
+			// The slide is longer than the size, and the row falls between windows
+			return false
+		}
+		if watermark.IsZero() || latest.Add(w.hop.Size+w.hop.Lateness).After(watermark) {
+			return true
+		}
+	} else if watermark.IsZero() || !t.Add(w.session.Lateness).Before(watermark) {
+		// A closed session ends at most the lateness before the watermark, so a row after that can't join one
+		return true
+	}
+	w.lateRows.Inc()
+	return false
+}
+
+func (w *WindowAggregator) eventKey(b *windowBatch, row *common.Row, groupKey []byte) ([]byte, error) {
+	key := table.EncodeTableKeyPrefix(w.EventTableInfo.ID, b.shardID, 64)
+	key = append(key, groupKey...)
+	key, err := common.KeyEncodeTimestamp(key, row.GetTimestamp(w.timeCol()))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	child := w.GetChildren()[0]
+	return common.EncodeKeyCols(row, child.KeyCols(), child.ColTypes(), key)
+}
+
+func (w *WindowAggregator) groupKey(b *windowBatch, row *common.Row) ([]byte, error) {
+	key, err := encodeGroupKey(w.groupByExprs, w.groupByRowsFactory, row, b.shardID, w.EventTableInfo.ID)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	// We ignore the first 16 bytes as this is shard-id|table-id
+	return key[16:], nil
+}
+
+func (w *WindowAggregator) addEvent(b *windowBatch, row *common.Row) error {
+	t, ok, err := w.eventTime(row)
+	if err != nil || !ok {
+		return err
+	}
+	if !w.acceptEvent(t, b.wm.watermark) {
+		return nil
+	}
+	groupKey, err := w.groupKey(b, row)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	key, err := w.eventKey(b, row, groupKey)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	value, err := common.EncodeRow(row, w.GetChildren()[0].ColTypes(), nil)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	b.writes.put(key, value)
+	b.changed(groupKey, t)
+	return nil
+}
+
+func (w *WindowAggregator) removeEvent(b *windowBatch, row *common.Row) error {
+	t, ok, err := w.eventTime(row)
+	if err != nil || !ok {
+		return err
+	}
+	if !w.acceptEvent(t, b.wm.watermark) {
+		return nil
+	}
+	groupKey, err := w.groupKey(b, row)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	key, err := w.eventKey(b, row, groupKey)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	value, err := w.get(b, key)
+	if err != nil || value == nil {
+		return err
+	}
+	b.writes.delete(key)
+	b.changed(groupKey, t)
+	return nil
+}
+
+func (b *windowBatch) changed(groupKey []byte, t time.Time) {
+	sKey := string(groupKey)
+	times, ok := b.groupTimes[sKey]
+	if !ok {
+		b.groups = append(b.groups, sKey)
+	}
+	b.groupTimes[sKey] = append(times, t)
+}
+
+// recalcHopWindows recalculates the open hopping windows of the group which contain any of the times
+func (w *WindowAggregator) recalcHopWindows(b *windowBatch, groupKey []byte, times []time.Time) error {
+	startsMap := map[time.Time]struct{}{}
+	var starts []time.Time
+	for _, t := range times {
+		for start := t.Truncate(w.hop.Slide); start.Add(w.hop.Size).After(t); start = start.Add(-w.hop.Slide) {
+			if _, ok := startsMap[start]; ok || !w.windowOpen(start.Add(w.hop.Size), b.wm.watermark) {
+				continue
+			}
+			startsMap[start] = struct{}{}
+			starts = append(starts, start)
+		}
+	}
+	sort.Slice(starts, func(i, j int) bool { return starts[i].Before(starts[j]) })
+	for _, start := range starts {
+		end := start.Add(w.hop.Size)
+		events, err := w.scanEvents(b, groupKey, start, end)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		oldValue, err := w.get(b, w.windowKey(b, groupKey, start))
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		var newWindow *window
+		if len(events) > 0 {
+			newWindow = &window{start: start, end: end, events: events}
+		}
+		if err := w.updateWindow(b, groupKey, start, oldValue, newWindow); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	return nil
+}
+
+// recalcSessions recalculates the sessions of the group from all of its rows
+func (w *WindowAggregator) recalcSessions(b *windowBatch, groupKey []byte) error {
+	events, err := w.scanEvents(b, groupKey, time.Time{}, time.Time{})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	var sessions []*window
+	for _, event := range events {
+		if len(sessions) == 0 || !event.t.Before(sessions[len(sessions)-1].end) {
+			sessions = append(sessions, &window{start: event.t})
+		}
+		session := sessions[len(sessions)-1]
+		session.end = event.t.Add(w.session.Gap)
+		session.events = append(session.events, event)
+	}
+
+	prefix := w.windowKey(b, groupKey, time.Time{})
+	prefix = prefix[:len(prefix)-8]
+	pairs, err := w.scan(b, prefix, common.IncrementBytesBigEndian(prefix))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	oldValues := make(map[time.Time][]byte, len(pairs))
+	for _, pair := range pairs {
+		start, err := decodeTime(pair.Key, len(prefix))
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		oldValues[start] = pair.Value
+	}
+	for _, session := range sessions {
+		oldValue := oldValues[session.start]
+		delete(oldValues, session.start)
+		if err := w.updateWindow(b, groupKey, session.start, oldValue, session); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	// Any other sessions have merged into new ones, or their rows have gone
+	for _, pair := range pairs {
+		start, err := decodeTime(pair.Key, len(prefix))
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		if oldValue, ok := oldValues[start]; ok {
+			if err := w.updateWindow(b, groupKey, start, oldValue, nil); err != nil {
+				return errors.WithStack(err)
+			}
+		}
+	}
+	return nil
+}
+
+// windowOpen returns true if a window with the end is still open
+func (w *WindowAggregator) windowOpen(end time.Time, watermark time.Time) bool {
+	return watermark.IsZero() || end.Add(w.lateness()).After(watermark)
+}
+
+type windowEvent struct {
+	t   time.Time
+	row *common.Row
+}
+
+type window struct {
+	start  time.Time
+	end    time.Time
+	events []windowEvent
+}
+
+// scanEvents returns the rows of the group with a time in [start, end) in time order, or all of them if start and end
+// are zero
+func (w *WindowAggregator) scanEvents(b *windowBatch, groupKey []byte, start time.Time, end time.Time) ([]windowEvent, error) {
+	prefix := table.EncodeTableKeyPrefix(w.EventTableInfo.ID, b.shardID, len(groupKey)+16)
+	prefix = append(prefix, groupKey...)
+	startKey, endKey := prefix, common.IncrementBytesBigEndian(prefix)
+	if !start.IsZero() {
+		var err error
+		if startKey, err = encodeTime(prefix, start); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		if endKey, err = encodeTime(prefix, end); err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+	pairs, err := w.scan(b, startKey, endKey)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	events := make([]windowEvent, len(pairs))
+	for i, pair := range pairs {
+		if events[i].t, err = decodeTime(pair.Key, len(prefix)); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		if err := common.DecodeRow(pair.Value, b.eventRows.ColumnTypes(), b.eventRows); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		row := b.eventRows.GetRow(b.eventRows.RowCount() - 1)
+		events[i].row = &row
+	}
+	return events, nil
+}
+
+// updateWindow stores the new state of a window, which is nil if it no longer has any rows, and outputs the change to
+// its row. The stored value of a window is its end followed by its row.
+func (w *WindowAggregator) updateWindow(b *windowBatch, groupKey []byte, start time.Time, oldValue []byte,
+	newWindow *window) error {
+	key := w.windowKey(b, groupKey, start)
+	pi, ci := -1, -1
+	var oldEnd time.Time
+	if oldValue != nil {
+		var err error
+		if oldEnd, err = decodeTime(oldValue, 0); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	var newValue []byte
+	var newRow common.Row
+	if newWindow != nil {
+		aggState, err := w.aggregate(newWindow)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		rows := w.rowsFactory.NewRows(1)
+		if err := aggfuncs.AppendAggState(aggState, w.colTypes, rows); err != nil {
+			return errors.WithStack(err)
+		}
+		newRow = rows.GetRow(0)
+		if newValue, err = encodeTime(nil, newWindow.end); err != nil {
+			return errors.WithStack(err)
+		}
+		if newValue, err = common.EncodeRow(&newRow, w.colTypes, newValue); err != nil {
+			return errors.WithStack(err)
+		}
+		if bytes.Equal(oldValue, newValue) {
+			// The window hasn't changed
+			return nil
+		}
+	}
+	if oldValue != nil {
+		if err := common.DecodeRow(oldValue[8:], w.colTypes, b.results); err != nil {
+			return errors.WithStack(err)
+		}
+		pi = b.results.RowCount() - 1
+		if newWindow == nil || !oldEnd.Equal(newWindow.end) {
+			expiryKey, err := w.expiryKey(b, groupKey, start, oldEnd)
+			if err != nil {
+				return errors.WithStack(err)
+			}
+			b.writes.delete(expiryKey)
+		}
+	}
+	if newWindow != nil {
+		b.results.AppendRow(newRow)
+		ci = b.results.RowCount() - 1
+		expiryKey, err := w.expiryKey(b, groupKey, start, newWindow.end)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		b.writes.put(expiryKey, []byte{})
+		b.writes.put(key, newValue)
+	} else {
+		b.writes.delete(key)
+	}
+	b.entries = append(b.entries, NewRowsEntry(pi, ci))
+	return nil
+}
+
+// aggregate evaluates the aggregate functions on the rows of the window
+func (w *WindowAggregator) aggregate(win *window) (*aggfuncs.AggState, error) {
+	aggState := aggfuncs.NewAggState(len(w.aggFuncs))
+	for _, event := range win.events {
+		for index, aggFunc := range w.aggFuncs {
+			if _, err := aggfuncs.EvalAggregateFunction(aggFunc, w.argTypes[index], aggState, index, event.row, false); err != nil {
+				return nil, errors.WithStack(err)
+			}
+		}
+	}
+	// The window functions evaluate to the window of the first row alone, so we set the actual window
+	for _, col := range w.windowStartCols {
+		if err := aggState.SetTimestamp(col, common.NewTimestampFromGoTime(win.start)); err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+	for _, col := range w.windowEndCols {
+		if err := aggState.SetTimestamp(col, common.NewTimestampFromGoTime(win.end)); err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+	return aggState, nil
+}
+
+// expireWindows deletes the state of the windows which have closed by the watermark, and the rows which are no longer in
+// any open window. The output rows of the windows are not affected.
+func (w *WindowAggregator) expireWindows(b *windowBatch, watermark time.Time) error {
+	prefix := table.EncodeTableKeyPrefix(w.ExpiryTableInfo.ID, b.shardID, 24)
+	end, err := encodeTime(append([]byte{}, prefix...), watermark)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	pairs, err := w.scan(b, prefix, common.IncrementBytesBigEndian(end))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	for _, pair := range pairs {
+		// The expiry key is the time the window closes followed by the key of the window, without its table prefix
+		suffix := pair.Key[len(prefix)+8:]
+		groupKey := suffix[:len(suffix)-8]
+		start, err := decodeTime(suffix, len(suffix)-8)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		windowKey := w.windowKey(b, groupKey, start)
+		var eventsEnd time.Time
+		if w.hop != nil {
+			// The rows before the start of the next window are in no other open window
+			eventsEnd = start.Add(w.hop.Slide)
+		} else {
+			value, err := w.get(b, windowKey)
+			if err != nil {
+				return errors.WithStack(err)
+			}
+			if value == nil {
+				return errors.Errorf("no session for expiry key")
+			}
+			if eventsEnd, err = decodeTime(value, 0); err != nil {
+				return errors.WithStack(err)
+			}
+		}
+		eventsPrefix := table.EncodeTableKeyPrefix(w.EventTableInfo.ID, b.shardID, len(groupKey)+16)
+		eventsPrefix = append(eventsPrefix, groupKey...)
+		eventsStartKey, err := encodeTime(eventsPrefix, start)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		eventsEndKey, err := encodeTime(eventsPrefix, eventsEnd)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		events, err := w.scan(b, eventsStartKey, eventsEndKey)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		for _, event := range events {
+			b.writes.delete(event.Key)
+		}
+		b.writes.delete(windowKey)
+		b.writes.delete(pair.Key)
+	}
+	return nil
+}
+
+func (w *WindowAggregator) windowKey(b *windowBatch, groupKey []byte, start time.Time) []byte {
+	key := table.EncodeTableKeyPrefix(w.WindowTableInfo.ID, b.shardID, len(groupKey)+24)
+	key = append(key, groupKey...)
+	key, _ = encodeTime(key, start)
+	return key
+}
+
+func (w *WindowAggregator) expiryKey(b *windowBatch, groupKey []byte, start time.Time, end time.Time) ([]byte, error) {
+	key := table.EncodeTableKeyPrefix(w.ExpiryTableInfo.ID, b.shardID, len(groupKey)+32)
+	key, err := encodeTime(key, end.Add(w.lateness()))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	key = append(key, groupKey...)
+	return encodeTime(key, start)
+}
+
+// get returns the value of the key, taking into account the writes made in the batch
+func (w *WindowAggregator) get(b *windowBatch, key []byte) ([]byte, error) {
+	if value, ok := b.writes.values[string(key)]; ok {
+		return value, nil
+	}
+	value, err := w.storage.LocalGet(key)
+	return value, errors.WithStack(err)
+}
+
+// scan returns the pairs with keys in [startKey, endKey) in key order, taking into account the writes made in the batch
+func (w *WindowAggregator) scan(b *windowBatch, startKey []byte, endKey []byte) ([]cluster.KVPair, error) {
+	pairs, err := w.storage.LocalScan(startKey, endKey, -1)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	written := false
+	for _, key := range b.writes.keys {
+		if key >= string(startKey) && key < string(endKey) {
+			written = true
+			break
+		}
+	}
+	if !written {
+		return pairs, nil
+	}
+	merged := make([]cluster.KVPair, 0, len(pairs))
+	for _, pair := range pairs {
+		if _, ok := b.writes.values[string(pair.Key)]; !ok {
+			merged = append(merged, pair)
+		}
+	}
+	for _, key := range b.writes.keys {
+		if value := b.writes.values[key]; value != nil && key >= string(startKey) && key < string(endKey) {
+			merged = append(merged, cluster.KVPair{Key: []byte(key), Value: value})
+		}
+	}
+	sort.Slice(merged, func(i, j int) bool { return bytes.Compare(merged[i].Key, merged[j].Key) < 0 })
+	return merged, nil
+}
+
+func encodeTime(buffer []byte, t time.Time) ([]byte, error) {
+	return common.KeyEncodeTimestamp(append([]byte{}, buffer...), common.NewTimestampFromGoTime(t))
+}
+
+func decodeTime(buffer []byte, offset int) (time.Time, error) {
+	ts, _, err := common.ReadTimestampFromBufferBE(buffer, offset, 6)
+	if err != nil {
+		return time.Time{}, errors.WithStack(err)
+	}
+	return ts.GoTime(time.UTC)
+}
+
+func (w *WindowAggregator) ReCalcSchemaFromChildren() error {
+	// NOOP
+	return nil
+}
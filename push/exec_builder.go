@@ -11,6 +11,7 @@ import (
 	"github.com/squareup/pranadb/parplan"
 	"github.com/squareup/pranadb/push/exec"
 	"github.com/squareup/pranadb/tidb/expression"
+	"github.com/squareup/pranadb/tidb/sessionctx"
 )
 
 // Builds the push DAG but does not register anything in memory
@@ -23,10 +24,14 @@ func (m *MaterializedView) buildPushQueryExecution(pl *parplan.Planner, schema *
 		return nil, nil, errors.WithStack(err)
 	}
 	// Build initial dag from the plan
-	dag, internalTables, err := m.buildPushDAG(physicalPlan, 0, schema, mvName, seqGenerator)
+	aggSequence := 0
+	dag, internalTables, err := m.buildPushDAG(physicalPlan, &aggSequence, schema, mvName, seqGenerator)
 	if err != nil {
 		return nil, nil, errors.WithStack(err)
 	}
+	if err := checkNoDuplicateScans(dag, map[string]struct{}{}); err != nil {
+		return nil, nil, errors.WithStack(err)
+	}
 	// Update schemas to the form we need
 	err = m.updateSchemas(dag, schema)
 	if err != nil {
@@ -38,13 +43,38 @@ func (m *MaterializedView) buildPushQueryExecution(pl *parplan.Planner, schema *
 	for _, colName := range logicalPlan.OutputNames() {
 		colNames = append(colNames, colName.ColName.L)
 	}
+	// Any hidden columns, e.g. key columns which were not selected, come after the visible ones and need names too
+	numVisible := len(colNames)
+	for i := numVisible; i < len(dag.ColTypes()); i++ {
+		colNames = append(colNames, fmt.Sprintf("__gen_hid_id%d", i-numVisible))
+	}
 	dag.SetColNames(colNames)
 	return dag, internalTables, nil
 }
 
+// NumInternalTables returns the number of internal tables that a materialized view with the given query needs, so that
+// enough table ids can be reserved before the materialized view is created
+func NumInternalTables(pl *parplan.Planner, schema *common.Schema, query string) (int, error) {
+	mv := MaterializedView{schema: schema}
+	_, internalTables, err := mv.buildPushQueryExecution(pl, schema, query, "", &countingSeqGenerator{})
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+	return len(internalTables), nil
+}
+
+type countingSeqGenerator struct {
+	count uint64
+}
+
+func (c *countingSeqGenerator) GenerateSequence() uint64 {
+	c.count++
+	return c.count
+}
+
 // TODO: extract functions and break apart giant switch
 // nolint: gocyclo
-func (m *MaterializedView) buildPushDAG(plan planner.PhysicalPlan, aggSequence int, schema *common.Schema, mvName string,
+func (m *MaterializedView) buildPushDAG(plan planner.PhysicalPlan, aggSequence *int, schema *common.Schema, mvName string,
 	seqGenerator common.SeqGenerator) (exec.PushExecutor, []*common.InternalTableInfo, error) {
 	var internalTables []*common.InternalTableInfo
 	var executor exec.PushExecutor
@@ -63,9 +93,8 @@ func (m *MaterializedView) buildPushDAG(plan planner.PhysicalPlan, aggSequence i
 		}
 		executor = exec.NewPushSelect(exprs)
 	case *planner.PhysicalHashAgg:
-		var aggFuncs []*exec.AggregateFunctionInfo
+		var aggFuncs []*aggfuncs.AggregateFunctionInfo
 
-		firstRowFuncs := 0
 		for _, aggFunc := range op.AggFuncs {
 			argExprs := aggFunc.Args
 			if len(argExprs) > 1 {
@@ -76,48 +105,86 @@ func (m *MaterializedView) buildPushDAG(plan planner.PhysicalPlan, aggSequence i
 				argExpr = common.NewExpression(argExprs[0], plan.SCtx())
 			}
 
-			var funcType aggfuncs.AggFunctionType
-			switch aggFunc.Name {
-			case "sum":
-				funcType = aggfuncs.SumAggregateFunctionType
-			case "count":
-				funcType = aggfuncs.CountAggregateFunctionType
-			case "firstrow":
-				funcType = aggfuncs.FirstRowAggregateFunctionType
-				firstRowFuncs++
-			default:
-				return nil, nil, errors.Errorf("unexpected aggregate function %s", aggFunc.Name)
+			funcType, err := aggfuncs.AggFunctionTypeForName(aggFunc.Name)
+			if err != nil {
+				return nil, nil, errors.WithStack(err)
 			}
 			colType := common.ConvertTiDBTypeToPranaType(aggFunc.RetTp)
-			af := &exec.AggregateFunctionInfo{
+			af := &aggfuncs.AggregateFunctionInfo{
 				FuncType:   funcType,
 				Distinct:   aggFunc.HasDistinct,
 				ArgExpr:    argExpr,
 				ReturnType: colType,
 			}
+			if len(argExprs) == 1 {
+				af.ArgType = common.ConvertTiDBTypeToPranaType(argExprs[0].GetType())
+			}
 			aggFuncs = append(aggFuncs, af)
 		}
 
-		nonFirstRowFuncs := len(aggFuncs) - firstRowFuncs
-
 		// These are the indexes of the group by cols in the output of the aggregation
 		pkCols := make([]int, len(op.GroupByItems))
 
-		// These are the indexes of the group by cols in the input of the aggregation
-		groupByCols := make([]int, len(op.GroupByItems))
+		// These are evaluated on the input of the aggregation to get the group key
+		groupByExprs := make([]*common.Expression, len(op.GroupByItems))
 
 		for i, expr := range op.GroupByItems {
-			col, ok := expr.(*expression.Column)
+			groupByExprs[i] = common.NewExpression(expr, plan.SCtx())
+			pkCols[i] = firstRowOfColumn(op, expr)
+			if pkCols[i] == -1 {
+				// The group by value isn't output by the aggregation, so we add a hidden column for it which becomes part
+				// of the key
+				colType := common.ConvertTiDBTypeToPranaType(expr.GetType())
+				pkCols[i] = len(aggFuncs)
+				aggFuncs = append(aggFuncs, &aggfuncs.AggregateFunctionInfo{
+					FuncType:   aggfuncs.FirstRowAggregateFunctionType,
+					ArgExpr:    groupByExprs[i],
+					ArgType:    colType,
+					ReturnType: colType,
+					Hidden:     true,
+				})
+			}
+		}
+
+		var window *exec.TumblingWindow
+		for i, expr := range op.GroupByItems {
+			windowExpr, timeCol, err := groupByWindow(op, expr)
+			if err != nil {
+				return nil, nil, errors.WithStack(err)
+			}
+			if windowExpr == nil {
+				continue
+			}
+			if !closedBySourceWatermark(op, schema) {
+				return nil, nil, errors.NewPranaErrorf(errors.InvalidStatement,
+					"a window can only be used on a source with a watermark column")
+			}
+			size, lateness, ok := expression.TumbleWindow(windowExpr)
 			if !ok {
-				return nil, nil, errors.Error("group by expression not a column")
+				executor, internalTables, err = m.buildWindowAggregator(op, aggFuncs, pkCols, groupByExprs, i,
+					windowExpr, timeCol, aggSequence, schema, mvName, seqGenerator)
+				if err != nil {
+					return nil, nil, errors.WithStack(err)
+				}
+				break
 			}
-			groupByCols[i] = col.Index
-			pkCols[i] = i + nonFirstRowFuncs
+			// A tumbling window must be the first group by expression, so the aggregate state is ordered by window
+			window = &exec.TumblingWindow{
+				TimeCol:  timeCol,
+				Size:     size,
+				Lateness: lateness,
+			}
+			groupByExprs[0], groupByExprs[i] = groupByExprs[i], groupByExprs[0]
+			pkCols[0], pkCols[i] = pkCols[i], pkCols[0]
+			break
+		}
+		if executor != nil {
+			break
 		}
 
 		partialTableID := seqGenerator.GenerateSequence()
-		partialTableName := fmt.Sprintf("%s-partial-aggtable-%d", mvName, aggSequence)
-		aggSequence++
+		partialTableName := fmt.Sprintf("%s-partial-aggtable-%d", mvName, *aggSequence)
+		*aggSequence++
 		partialTableInfo := &common.TableInfo{
 			ID:             partialTableID,
 			SchemaName:     schema.Name,
@@ -127,8 +194,8 @@ func (m *MaterializedView) buildPushDAG(plan planner.PhysicalPlan, aggSequence i
 			Internal:       true,
 		}
 		fullTableID := seqGenerator.GenerateSequence()
-		fullTableName := fmt.Sprintf("%s-full-aggtable-%d", mvName, aggSequence)
-		aggSequence++
+		fullTableName := fmt.Sprintf("%s-full-aggtable-%d", mvName, *aggSequence)
+		*aggSequence++
 		fullTableInfo := &common.TableInfo{
 			ID:             fullTableID,
 			SchemaName:     schema.Name,
@@ -147,7 +214,52 @@ func (m *MaterializedView) buildPushDAG(plan planner.PhysicalPlan, aggSequence i
 			MaterializedViewName: mvName,
 		}
 		internalTables = append(internalTables, fullAggInfo)
-		executor, err = exec.NewAggregator(pkCols, aggFuncs, partialTableInfo, fullTableInfo, groupByCols, m.cluster, m.sharder)
+		aggregator, err := exec.NewAggregator(pkCols, aggFuncs, partialTableInfo, fullTableInfo, groupByExprs, m.cluster, m.sharder)
+		if err != nil {
+			return nil, nil, errors.WithStack(err)
+		}
+		if aggregator.RequiresExtraState() {
+			// Functions such as MIN and MAX need the values of each group to be stored so they can be retracted
+			var extraStateTableInfos []*common.TableInfo
+			for _, level := range []string{"partial", "full"} {
+				tableInfo := &common.TableInfo{
+					ID:             seqGenerator.GenerateSequence(),
+					SchemaName:     schema.Name,
+					Name:           fmt.Sprintf("%s-%s-aggextrastate-%d", mvName, level, *aggSequence),
+					PrimaryKeyCols: nil,
+					IndexInfos:     nil,
+					Internal:       true,
+				}
+				*aggSequence++
+				extraStateTableInfos = append(extraStateTableInfos, tableInfo)
+				internalTables = append(internalTables, &common.InternalTableInfo{
+					TableInfo:            tableInfo,
+					MaterializedViewName: mvName,
+				})
+			}
+			aggregator.SetExtraStateTables(extraStateTableInfos[0], extraStateTableInfos[1])
+		}
+		if window != nil {
+			watermarkTableInfo := &common.TableInfo{
+				ID:             seqGenerator.GenerateSequence(),
+				SchemaName:     schema.Name,
+				Name:           fmt.Sprintf("%s-aggwatermark-%d", mvName, *aggSequence),
+				PrimaryKeyCols: nil,
+				IndexInfos:     nil,
+				Internal:       true,
+			}
+			*aggSequence++
+			internalTables = append(internalTables, &common.InternalTableInfo{
+				TableInfo:            watermarkTableInfo,
+				MaterializedViewName: mvName,
+			})
+			if err := aggregator.SetWindow(window, watermarkTableInfo); err != nil {
+				return nil, nil, errors.WithStack(err)
+			}
+		}
+		executor = aggregator
+	case *planner.PhysicalHashJoin:
+		executor, internalTables, err = m.buildJoin(op, aggSequence, schema, mvName, seqGenerator)
 		if err != nil {
 			return nil, nil, errors.WithStack(err)
 		}
@@ -170,12 +282,11 @@ func (m *MaterializedView) buildPushDAG(plan planner.PhysicalPlan, aggSequence i
 		// If we create an MV that only selects on index fields the TiDB planner will give us an index reader.
 		// As this is a push query we won't use an index but we'll use a push Scan specifying which columns we want
 		tableName := op.Table.Name
-		table, ok := schema.GetTable(tableName.L)
-		if !ok {
-			return nil, nil, errors.Errorf("cannot find table %s", tableName.L)
+		var scanCols []int
+		for _, col := range op.Columns {
+			scanCols = append(scanCols, col.Offset)
 		}
-		info := table.GetTableInfo()
-		executor, err = exec.NewScan(tableName.L, info.PrimaryKeyCols)
+		executor, err = exec.NewScan(tableName.L, scanCols)
 		if err != nil {
 			return nil, nil, errors.WithStack(err)
 		}
@@ -198,6 +309,260 @@ func (m *MaterializedView) buildPushDAG(plan planner.PhysicalPlan, aggSequence i
 	return executor, internalTables, nil
 }
 
+func (m *MaterializedView) buildJoin(op *planner.PhysicalHashJoin, aggSequence *int, schema *common.Schema, mvName string,
+	seqGenerator common.SeqGenerator) (*exec.Join, []*common.InternalTableInfo, error) {
+	var joinType exec.JoinType
+	switch op.JoinType {
+	case planner.InnerJoin:
+		joinType = exec.InnerJoin
+	case planner.LeftOuterJoin:
+		joinType = exec.LeftOuterJoin
+	case planner.RightOuterJoin:
+		joinType = exec.RightOuterJoin
+	default:
+		return nil, nil, errors.NewPranaErrorf(errors.InvalidStatement, "%s is not supported in materialized views", op.JoinType.String())
+	}
+	leftJoinCols := make([]int, len(op.LeftJoinKeys))
+	for i, col := range op.LeftJoinKeys {
+		leftJoinCols[i] = col.Index
+	}
+	rightJoinCols := make([]int, len(op.RightJoinKeys))
+	for i, col := range op.RightJoinKeys {
+		rightJoinCols[i] = col.Index
+	}
+	leftConds := m.toExpressions(op.LeftConditions, op.SCtx())
+	rightConds := m.toExpressions(op.RightConditions, op.SCtx())
+	otherConds := m.toExpressions(op.OtherConditions, op.SCtx())
+
+	// Each side of the join has its own internal table, we fill in the columns once we know the schema of the children
+	var internalTables []*common.InternalTableInfo
+	var tableInfos []*common.TableInfo
+	for _, side := range []string{"left", "right"} {
+		tableInfo := &common.TableInfo{
+			ID:         seqGenerator.GenerateSequence(),
+			SchemaName: schema.Name,
+			Name:       fmt.Sprintf("%s-%s-jointable-%d", mvName, side, *aggSequence),
+			IndexInfos: nil,
+			Internal:   true,
+		}
+		*aggSequence++
+		tableInfos = append(tableInfos, tableInfo)
+		internalTables = append(internalTables, &common.InternalTableInfo{
+			TableInfo:            tableInfo,
+			MaterializedViewName: mvName,
+		})
+	}
+	// The planner may have pruned columns of the children from the output of the join
+	childSchema := expression.MergeSchema(op.Children()[0].Schema(), op.Children()[1].Schema())
+	childCols := make([]int, op.Schema().Len())
+	for i, col := range op.Schema().Columns {
+		childCols[i] = childSchema.ColumnIndex(col)
+		if childCols[i] == -1 {
+			return nil, nil, errors.Errorf("cannot find join column %s in children", col.String())
+		}
+	}
+	numLeftCols := op.Children()[0].Schema().Len()
+	numRightCols := op.Children()[1].Schema().Len()
+	join, err := exec.NewJoin(joinType, leftJoinCols, rightJoinCols, leftConds, rightConds, otherConds, childCols,
+		numLeftCols, numRightCols, tableInfos[0], tableInfos[1], m.cluster, m.sharder)
+	if err != nil {
+		return nil, nil, errors.WithStack(err)
+	}
+
+	// If the join bounds the times of the rows on each side relative to each other, it's an interval join and state can
+	// be expired, which needs another internal table for each side
+	bounds := intervalJoinBounds(op.OtherConditions, numLeftCols)
+	if bounds != nil && joinType == exec.InnerJoin {
+		var expiryTableInfos []*common.TableInfo
+		for _, side := range []string{"left", "right"} {
+			tableInfo := &common.TableInfo{
+				ID:         seqGenerator.GenerateSequence(),
+				SchemaName: schema.Name,
+				Name:       fmt.Sprintf("%s-%s-joinexpiry-%d", mvName, side, *aggSequence),
+				IndexInfos: nil,
+				Internal:   true,
+			}
+			*aggSequence++
+			expiryTableInfos = append(expiryTableInfos, tableInfo)
+			internalTables = append(internalTables, &common.InternalTableInfo{
+				TableInfo:            tableInfo,
+				MaterializedViewName: mvName,
+			})
+		}
+		if err := join.SetInterval(bounds, expiryTableInfos[0], expiryTableInfos[1]); err != nil {
+			return nil, nil, errors.WithStack(err)
+		}
+	}
+	return join, internalTables, nil
+}
+
+// groupByWindow returns the window function and the index of its time column in the input of the aggregation if the
+// group by expression is a window, which may have been moved into a projection below the aggregation, or nil otherwise
+func groupByWindow(op *planner.PhysicalHashAgg, groupByExpr expression.Expression) (expression.Expression, int, error) {
+	windowExpr, proj := projectedExpr(op, groupByExpr)
+	if !expression.IsWindow(windowExpr) {
+		return nil, 0, nil
+	}
+	timeCol, ok := windowExpr.(*expression.ScalarFunction).GetArgs()[0].(*expression.Column)
+	if !ok {
+		return nil, 0, errors.NewPranaErrorf(errors.InvalidStatement, "the time of a window must be a column")
+	}
+	timeColIndex := timeCol.Index
+	if proj != nil {
+		// The time column is passed through the projection
+		timeColIndex = proj.Schema().ColumnIndex(timeCol)
+		if timeColIndex == -1 {
+			return nil, 0, errors.Errorf("time column of window not found")
+		}
+	}
+	return windowExpr, timeColIndex, nil
+}
+
+// closedBySourceWatermark returns true if the windows of the aggregation can be closed by the watermark of its source,
+// which is the case when it aggregates a source with a watermark through executors which pass the watermark on. Windows
+// are only closed by the watermark of a source, as it is the same on every shard.
+func closedBySourceWatermark(op *planner.PhysicalHashAgg, schema *common.Schema) bool {
+	plan := op.Children()[0]
+	for {
+		switch p := plan.(type) {
+		case *planner.PhysicalProjection, *planner.PhysicalSelection:
+			plan = p.Children()[0]
+		case *planner.PhysicalTableScan:
+			tab, ok := schema.GetTable(p.Table.Name.L)
+			if !ok {
+				return false
+			}
+			sourceInfo, ok := tab.(*common.SourceInfo)
+			return ok && sourceInfo.TopicInfo.Watermark != nil
+		default:
+			return false
+		}
+	}
+}
+
+// projectedExpr returns the expression that a column of the input of the aggregation is projected from, and the
+// projection, or the expression itself if it isn't such a column
+func projectedExpr(op *planner.PhysicalHashAgg, expr expression.Expression) (expression.Expression, *planner.PhysicalProjection) {
+	proj, isProj := op.Children()[0].(*planner.PhysicalProjection)
+	if col, ok := expr.(*expression.Column); ok && isProj && col.Index < len(proj.Exprs) {
+		return proj.Exprs[col.Index], proj
+	}
+	return expr, nil
+}
+
+// buildWindowAggregator builds the aggregation for a query grouped by a hop or session window
+func (m *MaterializedView) buildWindowAggregator(op *planner.PhysicalHashAgg, aggFuncs []*aggfuncs.AggregateFunctionInfo,
+	pkCols []int, groupByExprs []*common.Expression, windowIndex int, windowExpr expression.Expression, timeCol int,
+	aggSequence *int, schema *common.Schema, mvName string, seqGenerator common.SeqGenerator) (*exec.WindowAggregator, []*common.InternalTableInfo, error) {
+	var internalTables []*common.InternalTableInfo
+	var tableInfos []*common.TableInfo
+	for _, kind := range []string{"events", "windows", "expiry", "watermark"} {
+		tableInfo := &common.TableInfo{
+			ID:             seqGenerator.GenerateSequence(),
+			SchemaName:     schema.Name,
+			Name:           fmt.Sprintf("%s-aggwindow-%s-%d", mvName, kind, *aggSequence),
+			PrimaryKeyCols: nil,
+			IndexInfos:     nil,
+			Internal:       true,
+		}
+		*aggSequence++
+		tableInfos = append(tableInfos, tableInfo)
+		internalTables = append(internalTables, &common.InternalTableInfo{
+			TableInfo:            tableInfo,
+			MaterializedViewName: mvName,
+		})
+	}
+	tables := exec.WindowTables{
+		EventTableInfo:     tableInfos[0],
+		WindowTableInfo:    tableInfos[1],
+		ExpiryTableInfo:    tableInfos[2],
+		WatermarkTableInfo: tableInfos[3],
+	}
+	// The start and end of the window are selected as firstrow functions of the window functions
+	var windowStartCols, windowEndCols []int
+	for i, aggFunc := range op.AggFuncs {
+		if aggFunc.Name != "firstrow" || len(aggFunc.Args) != 1 || i == pkCols[windowIndex] {
+			continue
+		}
+		argExpr, _ := projectedExpr(op, aggFunc.Args[0])
+		if expression.IsWindow(argExpr) {
+			windowStartCols = append(windowStartCols, i)
+		} else if expression.IsWindowEnd(argExpr) {
+			windowEndCols = append(windowEndCols, i)
+		}
+	}
+	var otherGroupByExprs []*common.Expression
+	otherGroupByExprs = append(otherGroupByExprs, groupByExprs[:windowIndex]...)
+	otherGroupByExprs = append(otherGroupByExprs, groupByExprs[windowIndex+1:]...)
+	aggregator, err := exec.NewWindowAggregator(pkCols, aggFuncs, otherGroupByExprs, pkCols[windowIndex], windowStartCols,
+		windowEndCols,
+		tables, m.cluster, m.sharder)
+	if err != nil {
+		return nil, nil, errors.WithStack(err)
+	}
+	if size, slide, lateness, ok := expression.HopWindow(windowExpr); ok {
+		aggregator.SetHopWindow(&exec.HopWindow{
+			TimeCol:  timeCol,
+			Size:     size,
+			Slide:    slide,
+			Lateness: lateness,
+		})
+	} else if gap, lateness, ok := expression.SessionWindow(windowExpr); ok {
+		aggregator.SetSessionWindow(&exec.SessionWindow{
+			TimeCol:  timeCol,
+			Gap:      gap,
+			Lateness: lateness,
+		})
+	} else {
+		return nil, nil, errors.Errorf("unexpected window %s", windowExpr)
+	}
+	return aggregator, internalTables, nil
+}
+
+// firstRowOfColumn returns the index of the firstrow aggregate function which outputs the group by expression, or -1 if
+// the expression isn't a column or there is no such function
+func firstRowOfColumn(op *planner.PhysicalHashAgg, groupByExpr expression.Expression) int {
+	col, ok := groupByExpr.(*expression.Column)
+	if !ok {
+		return -1
+	}
+	for i, aggFunc := range op.AggFuncs {
+		if aggFunc.Name != "firstrow" || len(aggFunc.Args) != 1 {
+			continue
+		}
+		if argCol, ok := aggFunc.Args[0].(*expression.Column); ok && argCol.Index == col.Index {
+			return i
+		}
+	}
+	return -1
+}
+
+func (m *MaterializedView) toExpressions(exprs []expression.Expression, ctx sessionctx.Context) []*common.Expression {
+	var res []*common.Expression
+	for _, expr := range exprs {
+		res = append(res, common.NewExpression(expr, ctx))
+	}
+	return res
+}
+
+// A source or materialized view can only feed a materialized view once, as its consumers are keyed by the name of the
+// materialized view
+func checkNoDuplicateScans(executor exec.PushExecutor, tableNames map[string]struct{}) error {
+	if scan, ok := executor.(*exec.Scan); ok {
+		if _, exists := tableNames[scan.TableName]; exists {
+			return errors.NewPranaErrorf(errors.InvalidStatement,
+				"%s cannot be used more than once in a materialized view", scan.TableName)
+		}
+		tableNames[scan.TableName] = struct{}{}
+	}
+	for _, child := range executor.GetChildren() {
+		if err := checkNoDuplicateScans(child, tableNames); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // The schema provided by the planner may not be the ones we need. We need to provide information
 // on key cols, which the planner does not provide, also we need to propagate keys through
 // projections which don't include the key columns. These are needed when subsequently
@@ -0,0 +1,37 @@
+package push
+
+import (
+	"strings"
+
+	"github.com/squareup/pranadb/common"
+	"github.com/squareup/pranadb/push/exec"
+)
+
+var ExplainColNames = []string{"operator", "info"}
+
+var explainRowsFactory = common.NewRowsFactory(
+	[]common.ColumnType{
+		common.VarcharColumnType, // operator
+		common.VarcharColumnType, // info
+	},
+)
+
+// Explain returns rows which describe the DAG which maintains the materialized view, from the table executor of the
+// view down to the scans of the sources and materialized views it consumes
+func (m *MaterializedView) Explain() *common.Rows {
+	explained := exec.Explain(m.tableExecutor)
+	rows := explainRowsFactory.NewRows(len(explained))
+	for _, ex := range explained {
+		builder := &strings.Builder{}
+		for i := 0; i < ex.Level-1; i++ {
+			builder.WriteString("   |")
+		}
+		if ex.Level > 0 {
+			builder.WriteString("   > ")
+		}
+		builder.WriteString(ex.Operator)
+		rows.AppendStringToColumn(0, builder.String())
+		rows.AppendStringToColumn(1, ex.Info)
+	}
+	return rows
+}
@@ -0,0 +1,156 @@
+package push
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/pingcap/parser/ast"
+	"github.com/pingcap/parser/mysql"
+	"github.com/squareup/pranadb/push/exec"
+	"github.com/squareup/pranadb/tidb/expression"
+	"github.com/squareup/pranadb/tidb/types"
+)
+
+// intervalJoinBounds looks for conditions of the join which bound the time of the rows on the right side relative to
+// the time of the rows on the left side, e.g. `r.ts BETWEEN l.ts AND l.ts + INTERVAL 1 HOUR`. It returns nil unless
+// there are both lower and upper bounds for the same pair of columns.
+func intervalJoinBounds(conds []expression.Expression, numLeftCols int) *exec.IntervalJoinBounds {
+	leftTimeCol, rightTimeCol := -1, -1
+	var lower, upper *time.Duration
+	for _, cond := range conds {
+		sf, ok := cond.(*expression.ScalarFunction)
+		if !ok {
+			continue
+		}
+		op := sf.FuncName.L
+		switch op {
+		case ast.GE, ast.GT, ast.LE, ast.LT:
+		default:
+			continue
+		}
+		args := sf.GetArgs()
+		col1, offset1, ok := timeColumnAndOffset(args[0])
+		if !ok {
+			continue
+		}
+		col2, offset2, ok := timeColumnAndOffset(args[1])
+		if !ok {
+			continue
+		}
+		// The condition is col1 + offset1 op col2 + offset2, we want it in the form right op left + offset
+		var leftCol, rightCol int
+		var offset time.Duration
+		if col1 >= numLeftCols && col2 < numLeftCols {
+			leftCol, rightCol = col2, col1
+			offset = offset2 - offset1
+		} else if col1 < numLeftCols && col2 >= numLeftCols {
+			leftCol, rightCol = col1, col2
+			offset = offset1 - offset2
+			op = reverseComparison(op)
+		} else {
+			continue
+		}
+		if leftTimeCol == -1 {
+			leftTimeCol, rightTimeCol = leftCol, rightCol
+		} else if leftCol != leftTimeCol || rightCol != rightTimeCol {
+			continue
+		}
+		switch op {
+		case ast.GE, ast.GT:
+			if lower == nil || offset > *lower {
+				lower = &offset
+			}
+		default:
+			if upper == nil || offset < *upper {
+				upper = &offset
+			}
+		}
+	}
+	if lower == nil || upper == nil {
+		return nil
+	}
+	return &exec.IntervalJoinBounds{
+		LeftTimeCol:  leftTimeCol,
+		RightTimeCol: rightTimeCol - numLeftCols,
+		Lower:        *lower,
+		Upper:        *upper,
+	}
+}
+
+// timeColumnAndOffset returns the index of the time column in the expression and the fixed interval added to it,
+// if the expression is a time column, optionally with DATE_ADD or DATE_SUB applied
+func timeColumnAndOffset(expr expression.Expression) (int, time.Duration, bool) {
+	switch e := expr.(type) {
+	case *expression.Column:
+		if e.RetType.Tp != mysql.TypeTimestamp && e.RetType.Tp != mysql.TypeDatetime {
+			return 0, 0, false
+		}
+		return e.Index, 0, true
+	case *expression.ScalarFunction:
+		name := e.FuncName.L
+		if name != ast.DateAdd && name != ast.AddDate && name != ast.DateSub && name != ast.SubDate {
+			return 0, 0, false
+		}
+		args := e.GetArgs()
+		col, ok := args[0].(*expression.Column)
+		if !ok {
+			return 0, 0, false
+		}
+		index, _, ok := timeColumnAndOffset(col)
+		if !ok {
+			return 0, 0, false
+		}
+		interval, ok := constantInterval(args[1], args[2])
+		if !ok {
+			return 0, 0, false
+		}
+		if name == ast.DateSub || name == ast.SubDate {
+			interval = -interval
+		}
+		return index, interval, true
+	default:
+		return 0, 0, false
+	}
+}
+
+// constantInterval returns the duration of an interval with a constant value. Intervals of months or years don't have a
+// fixed duration.
+func constantInterval(valueExpr expression.Expression, unitExpr expression.Expression) (time.Duration, bool) {
+	valueConst, ok := valueExpr.(*expression.Constant)
+	if !ok {
+		return 0, false
+	}
+	unitConst, ok := unitExpr.(*expression.Constant)
+	if !ok || unitConst.Value.Kind() != types.KindString {
+		return 0, false
+	}
+	var value int64
+	switch valueConst.Value.Kind() {
+	case types.KindInt64:
+		value = valueConst.Value.GetInt64()
+	case types.KindUint64:
+		value = int64(valueConst.Value.GetUint64())
+	case types.KindString:
+		var err error
+		value, err = strconv.ParseInt(valueConst.Value.GetString(), 10, 64)
+		if err != nil {
+			return 0, false
+		}
+	default:
+		return 0, false
+	}
+	return expression.IntervalDuration(value, unitConst.Value.GetString())
+}
+
+func reverseComparison(op string) string {
+	switch op {
+	case ast.GE:
+		return ast.LE
+	case ast.GT:
+		return ast.LT
+	case ast.LE:
+		return ast.GE
+	default:
+		return ast.GT
+	}
+}
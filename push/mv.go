@@ -92,6 +92,14 @@ func (m *MaterializedView) disconnectOrDeleteDataForMV(schema *common.Schema, no
 				}
 				source.RemoveConsumingExecutor(m.Info.Name)
 			}
+		case *common.UserTableInfo:
+			if disconnect {
+				ut, err := m.pe.GetUserTable(tbl.ID)
+				if err != nil {
+					return errors.WithStack(err)
+				}
+				ut.RemoveConsumingExecutor(m.Info.Name)
+			}
 		case *common.MaterializedViewInfo:
 			if disconnect {
 				mv, err := m.pe.GetMaterializedView(tbl.ID)
@@ -119,6 +127,50 @@ func (m *MaterializedView) disconnectOrDeleteDataForMV(schema *common.Schema, no
 			if err != nil {
 				return errors.WithStack(err)
 			}
+			if op.PartialExtraStateTableInfo != nil {
+				if err := m.deleteTableData(op.PartialExtraStateTableInfo.ID); err != nil {
+					return errors.WithStack(err)
+				}
+				if err := m.deleteTableData(op.FullExtraStateTableInfo.ID); err != nil {
+					return errors.WithStack(err)
+				}
+			}
+			if op.WatermarkTableInfo != nil {
+				if err := m.deleteTableData(op.WatermarkTableInfo.ID); err != nil {
+					return errors.WithStack(err)
+				}
+			}
+		}
+	case *exec.WindowAggregator:
+		if disconnect {
+			if err := m.pe.UnregisterRemoteConsumer(op.EventTableInfo.ID); err != nil {
+				return errors.WithStack(err)
+			}
+		}
+		if deleteData {
+			for _, tableInfo := range []*common.TableInfo{op.EventTableInfo, op.WindowTableInfo, op.ExpiryTableInfo, op.WatermarkTableInfo} {
+				if err := m.deleteTableData(tableInfo.ID); err != nil {
+					return errors.WithStack(err)
+				}
+			}
+		}
+	case *exec.Join:
+		for _, input := range []*exec.JoinInput{op.Left, op.Right} {
+			if disconnect {
+				if err := m.pe.UnregisterRemoteConsumer(input.TableInfo.ID); err != nil {
+					return errors.WithStack(err)
+				}
+			}
+			if deleteData {
+				if err := m.deleteTableData(input.TableInfo.ID); err != nil {
+					return errors.WithStack(err)
+				}
+				if input.ExpiryTableInfo != nil {
+					if err := m.deleteTableData(input.ExpiryTableInfo.ID); err != nil {
+						return errors.WithStack(err)
+					}
+				}
+			}
 		}
 	}
 
@@ -171,6 +223,12 @@ func (m *MaterializedView) connect(executor exec.PushExecutor, addConsuming bool
 					return errors.WithStack(err)
 				}
 				source.AddConsumingExecutor(m.Info.Name, executor)
+			case *common.UserTableInfo:
+				ut, err := m.pe.GetUserTable(tbl.ID)
+				if err != nil {
+					return errors.WithStack(err)
+				}
+				ut.AddConsumingExecutor(m.Info.Name, executor)
 			case *common.MaterializedViewInfo:
 				mv, err := m.pe.GetMaterializedView(tbl.ID)
 				if err != nil {
@@ -183,7 +241,7 @@ func (m *MaterializedView) connect(executor exec.PushExecutor, addConsuming bool
 		}
 	case *exec.Aggregator:
 		if registerRemote {
-			colTypes := op.FullAggTableInfo.ColumnTypes
+			colTypes := op.ForwardedColTypes()
 			rf := common.NewRowsFactory(colTypes)
 			rc := &RemoteConsumer{
 				RowsFactory: rf,
@@ -195,6 +253,33 @@ func (m *MaterializedView) connect(executor exec.PushExecutor, addConsuming bool
 				return errors.WithStack(err)
 			}
 		}
+	case *exec.WindowAggregator:
+		if registerRemote {
+			colTypes := op.ForwardedColTypes()
+			rc := &RemoteConsumer{
+				RowsFactory: common.NewRowsFactory(colTypes),
+				ColTypes:    colTypes,
+				RowsHandler: op,
+			}
+			if err := m.pe.RegisterRemoteConsumer(op.EventTableInfo.ID, rc); err != nil {
+				return errors.WithStack(err)
+			}
+		}
+	case *exec.Join:
+		if registerRemote {
+			// Each side of the join receives the rows forwarded to it from the other shards
+			for _, input := range []*exec.JoinInput{op.Left, op.Right} {
+				colTypes := input.TableInfo.ColumnTypes
+				rc := &RemoteConsumer{
+					RowsFactory: common.NewRowsFactory(colTypes),
+					ColTypes:    colTypes,
+					RowsHandler: input,
+				}
+				if err := m.pe.RegisterRemoteConsumer(input.TableInfo.ID, rc); err != nil {
+					return errors.WithStack(err)
+				}
+			}
+		}
 	}
 	return nil
 }
@@ -253,6 +338,12 @@ func (m *MaterializedView) getFeedingExecutors(ex exec.PushExecutor) ([]*exec.Ta
 				return nil, nil, errors.WithStack(err)
 			}
 			tes = append(tes, source.TableExecutor())
+		case *common.UserTableInfo:
+			ut, err := m.pe.GetUserTable(tbl.ID)
+			if err != nil {
+				return nil, nil, errors.WithStack(err)
+			}
+			tes = append(tes, ut.TableExecutor())
 		case *common.MaterializedViewInfo:
 			mv, err := m.pe.GetMaterializedView(tbl.ID)
 			if err != nil {
@@ -0,0 +1,159 @@
+package push
+
+import (
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/squareup/pranadb/common"
+	"github.com/squareup/pranadb/conf"
+	"github.com/squareup/pranadb/errors"
+	"github.com/squareup/pranadb/kafka"
+	"github.com/squareup/pranadb/push/exec"
+)
+
+// If a sink fails to deliver messages, e.g. because the broker is unavailable, it tries again after this delay
+const sinkRedeliveryDelay = 5 * time.Second
+
+// CreateSink creates a sink and attaches it to the materialized view it consumes from. If fill is true the current
+// contents of the materialized view are sent to the topic first.
+func (p *Engine) CreateSink(sinkInfo *common.SinkInfo, fill bool) error {
+	mvInfo, ok := p.meta.GetMaterializedView(sinkInfo.SchemaName, sinkInfo.MaterializedViewName)
+	if !ok {
+		return errors.NewUnknownMaterializedViewError(sinkInfo.SchemaName, sinkInfo.MaterializedViewName)
+	}
+	mv, err := p.GetMaterializedView(mvInfo.ID)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	te := mv.TableExecutor()
+	tableInfo := mvInfo.TableInfo
+	ti := sinkInfo.TopicInfo
+	encoder, err := kafka.NewSinkMessageEncoder(ti.KeyEncoding, ti.ValueEncoding, tableInfo.ColumnTypes,
+		tableInfo.PrimaryKeyCols)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	producer, err := p.newMessageProducer(ti)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if err := producer.Start(); err != nil {
+		return errors.WithStack(err)
+	}
+	sinkID := sinkInfo.ID
+	sinkExec, err := exec.NewSinkExecutor(sinkInfo, tableInfo, encoder, producer, p.cluster, func(shardID uint64) {
+		// This is called when a batch is committed, so we must not block here
+		go p.scheduleSinkDelivery(sinkID, shardID)
+	})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	schedulers, err := p.GetLocalLeaderSchedulers()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	p.lock.Lock()
+	p.sinks[sinkID] = sinkExec
+	p.lock.Unlock()
+	if fill {
+		err = te.FillTo(sinkExec, sinkInfo.Name, sinkID, schedulers, p.failInject)
+	} else {
+		te.AddConsumingNode(sinkInfo.Name, sinkExec)
+		// There might be messages which weren't delivered before the node was stopped
+		for shardID := range schedulers {
+			go p.scheduleSinkDelivery(sinkID, shardID)
+		}
+	}
+	if err != nil {
+		p.lock.Lock()
+		delete(p.sinks, sinkID)
+		p.lock.Unlock()
+		if err2 := producer.Stop(); err2 != nil {
+			log.Warnf("failed to stop producer for sink %s %v", sinkInfo.Name, err2)
+		}
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// RemoveSink detaches the sink from its materialized view and deletes any messages which haven't been delivered yet
+func (p *Engine) RemoveSink(sinkInfo *common.SinkInfo) error {
+	p.lock.Lock()
+	sinkExec, ok := p.sinks[sinkInfo.ID]
+	if !ok {
+		p.lock.Unlock()
+		return errors.Errorf("no such sink %d", sinkInfo.ID)
+	}
+	delete(p.sinks, sinkInfo.ID)
+	p.lock.Unlock()
+
+	mvInfo, ok := p.meta.GetMaterializedView(sinkInfo.SchemaName, sinkInfo.MaterializedViewName)
+	if ok {
+		mv, err := p.GetMaterializedView(mvInfo.ID)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		mv.TableExecutor().RemoveConsumingNode(sinkInfo.Name)
+	}
+	if err := sinkExec.Producer().Stop(); err != nil {
+		return errors.WithStack(err)
+	}
+
+	// Delete the outbox data
+	tableStartPrefix := common.AppendUint64ToBufferBE(nil, sinkInfo.ID)
+	tableEndPrefix := common.AppendUint64ToBufferBE(nil, sinkInfo.ID+1)
+	return p.cluster.DeleteAllDataInRangeForAllShardsLocally(tableStartPrefix, tableEndPrefix)
+}
+
+func (p *Engine) newMessageProducer(ti *common.TopicInfo) (kafka.MessageProducer, error) {
+	if p.cfg.KafkaBrokers == nil {
+		return nil, errors.NewPranaError(errors.MissingKafkaBrokers, "No Kafka brokers configured")
+	}
+	brokerConf, ok := p.cfg.KafkaBrokers[ti.BrokerName]
+	if !ok {
+		return nil, errors.NewPranaErrorf(errors.UnknownBrokerName, "Unknown broker. Name: %s", ti.BrokerName)
+	}
+	props := make(map[string]string, len(brokerConf.Properties)+len(ti.Properties))
+	for k, v := range ti.Properties {
+		props[k] = v
+	}
+	// Broker properties override topic properties
+	for k, v := range brokerConf.Properties {
+		props[k] = v
+	}
+	switch brokerConf.ClientType {
+	case conf.BrokerClientFake:
+		return kafka.NewFakeMessageProducer(ti.TopicName, props)
+	case conf.BrokerClientDefault:
+		return kafka.NewMessageProducer(ti.TopicName, props), nil
+	default:
+		return nil, errors.NewPranaErrorf(errors.UnsupportedBrokerClientType, "Unsupported broker client type %d", brokerConf.ClientType)
+	}
+}
+
+// scheduleSinkDelivery delivers the messages in the outbox of the sink for the shard, on the scheduler of the shard
+func (p *Engine) scheduleSinkDelivery(sinkID uint64, shardID uint64) {
+	p.lock.RLock()
+	sinkExec, ok := p.sinks[sinkID]
+	scheduler, ok2 := p.schedulers[shardID]
+	p.lock.RUnlock()
+	if !ok || !ok2 {
+		// The sink has been dropped or the shard is no longer local
+		return
+	}
+	scheduler.ScheduleActionFireAndForget(func() error {
+		if err := sinkExec.Deliver(shardID); err != nil {
+			log.Warnf("failed to deliver messages for sink %s, will retry. %v", sinkExec.SinkInfo.Name, err)
+			time.AfterFunc(sinkRedeliveryDelay, func() {
+				p.scheduleSinkDelivery(sinkID, shardID)
+			})
+		}
+		return nil
+	})
+}
+
+// WaitForSinkDelivery is used in tests to wait for all the messages of a sink to be delivered
+func (p *Engine) WaitForSinkDelivery(sinkID uint64) error {
+	return p.waitForNoRowsInTable(sinkID)
+}
@@ -19,11 +19,13 @@ type MessageConsumer struct {
 	messageParser   *MessageParser
 	msgBatch        []*kafka.Message
 	offsetsToCommit map[int32]int64
+	eventTimes      map[int32]time.Time // The greatest event time of the messages of each partition, for the watermark
+	numPartitions   int                 // The number of partitions of the topic, for the watermark, or 0 if not known
 }
 
 func NewMessageConsumer(msgProvider kafka.MessageProvider, pollTimeout time.Duration, maxMessages int,
 	source *Source) (*MessageConsumer, error) {
-	messageParser, err := NewMessageParser(source.sourceInfo, source.protoRegistry)
+	messageParser, err := NewMessageParser(source.sourceInfo, source.protoRegistry, source.avroRegistry)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
@@ -35,6 +37,7 @@ func NewMessageConsumer(msgProvider kafka.MessageProvider, pollTimeout time.Dura
 		loopCh:          make(chan struct{}, 1),
 		messageParser:   messageParser,
 		offsetsToCommit: make(map[int32]int64),
+		eventTimes:      make(map[int32]time.Time),
 	}
 
 	msgProvider.SetRebalanceCallback(mc.rebalanceOccurring)
@@ -80,6 +83,8 @@ func (m *MessageConsumer) rebalanceOccurring() error {
 	// the current unprocessed batch of messages
 	m.msgBatch = nil
 	m.offsetsToCommit = make(map[int32]int64)
+	// Partitions might have been added to the topic
+	m.numPartitions = 0
 	return nil
 }
 
@@ -95,8 +100,14 @@ func (m *MessageConsumer) pollLoop() {
 		}
 
 		if len(messages) != 0 {
+			if m.source.sourceInfo.TopicInfo.Watermark != nil && m.numPartitions == 0 {
+				if m.numPartitions, err = m.msgProvider.NumPartitions(); err != nil {
+					m.consumerError(err, true)
+					return
+				}
+			}
 			// This blocks until messages were actually ingested
-			if err := m.source.ingestMessages(messages, m.messageParser); err != nil {
+			if err := m.source.ingestMessages(messages, m.messageParser, m.eventTimes, m.numPartitions); err != nil {
 				m.consumerError(err, false)
 				return
 			}
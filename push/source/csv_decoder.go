@@ -0,0 +1,183 @@
+package source
+
+import (
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/squareup/pranadb/command/parser/selector"
+	"github.com/squareup/pranadb/errors"
+)
+
+const (
+	csvDelimiterPropName = "prana.source.csv.delimiter"
+	csvQuotePropName     = "prana.source.csv.quote"
+	csvHeaderPropName    = "prana.source.csv.header"
+	defaultCSVDelimiter  = ','
+	defaultCSVQuote      = '"'
+)
+
+// CSVDecoder decodes a message containing a single CSV record. If the message has a header, the first record of the
+// message is the header and it must be followed by exactly one record.
+//
+// Quotes in a quoted field are escaped by doubling them. An unquoted empty field is null, while a quoted empty field
+// is the empty string.
+type CSVDecoder struct {
+	delimiter rune
+	quote     rune
+	header    bool
+}
+
+// newCSVDecoder creates a decoder configured by the properties of the topic
+func newCSVDecoder(props map[string]string) (*CSVDecoder, error) {
+	delimiter, err := getOrDefaultRuneValue(csvDelimiterPropName, props, defaultCSVDelimiter)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	quote, err := getOrDefaultRuneValue(csvQuotePropName, props, defaultCSVQuote)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if delimiter == quote {
+		return nil, errors.NewPranaErrorf(errors.InvalidStatement, "%s and %s must be different",
+			csvDelimiterPropName, csvQuotePropName)
+	}
+	header := false
+	if h, ok := props[csvHeaderPropName]; ok {
+		header, err = strconv.ParseBool(h)
+		if err != nil {
+			return nil, errors.NewPranaErrorf(errors.InvalidStatement, "invalid value for %s %q, must be true or false",
+				csvHeaderPropName, h)
+		}
+	}
+	return &CSVDecoder{delimiter: delimiter, quote: quote, header: header}, nil
+}
+
+func getOrDefaultRuneValue(propName string, props map[string]string, def rune) (rune, error) {
+	v, ok := props[propName]
+	if !ok {
+		return def, nil
+	}
+	r, size := utf8.DecodeRuneInString(v)
+	if size == 0 || size != len(v) || r == utf8.RuneError || r == '\r' || r == '\n' {
+		return 0, errors.NewPranaErrorf(errors.InvalidStatement, "invalid value for %s %q, must be a single character",
+			propName, v)
+	}
+	return r, nil
+}
+
+func (c *CSVDecoder) Decode(bytes []byte) (interface{}, error) {
+	record, rest, err := c.readRecord(bytes)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	res := &CSVRecord{fields: record}
+	if c.header {
+		res.names = make([]string, len(record))
+		for i, name := range record {
+			if name != nil {
+				res.names[i] = *name
+			}
+		}
+		res.fields, rest, err = c.readRecord(rest)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+	if len(rest) != 0 {
+		return nil, errors.Error("csv message contains more than one record")
+	}
+	return res, nil
+}
+
+// readRecord reads a record, returning the bytes after it. A record ends at the end of a line or the end of the input.
+func (c *CSVDecoder) readRecord(bytes []byte) ([]*string, []byte, error) {
+	if len(bytes) == 0 {
+		return nil, nil, errors.Error("csv message is missing a record")
+	}
+	var (
+		fields  []*string
+		field   []byte
+		quoted  bool // whether the current field is quoted
+		inQuote bool // whether we're within the quotes of the current field
+	)
+	endField := func() {
+		if len(field) == 0 && !quoted {
+			fields = append(fields, nil)
+		} else {
+			f := string(field)
+			fields = append(fields, &f)
+		}
+		field = field[:0]
+		quoted = false
+	}
+	for i := 0; i < len(bytes); {
+		r, size := utf8.DecodeRune(bytes[i:])
+		switch {
+		case inQuote:
+			if r == c.quote {
+				next, nextSize := utf8.DecodeRune(bytes[i+size:])
+				if nextSize > 0 && next == c.quote {
+					// An escaped quote
+					field = append(field, bytes[i:i+size]...)
+					size += nextSize
+				} else {
+					inQuote = false
+				}
+			} else {
+				field = append(field, bytes[i:i+size]...)
+			}
+		case r == c.quote:
+			if len(field) != 0 || quoted {
+				return nil, nil, errors.Errorf("unexpected quote in csv field at offset %d", i)
+			}
+			quoted = true
+			inQuote = true
+		case r == c.delimiter:
+			endField()
+		case r == '\n' || (r == '\r' && i+1 < len(bytes) && bytes[i+1] == '\n'):
+			if r == '\r' {
+				size++
+			}
+			endField()
+			return fields, bytes[i+size:], nil
+		default:
+			if quoted {
+				return nil, nil, errors.Errorf("unexpected character after quoted csv field at offset %d", i)
+			}
+			field = append(field, bytes[i:i+size]...)
+		}
+		i += size
+	}
+	if inQuote {
+		return nil, nil, errors.Error("unterminated quoted csv field")
+	}
+	endField()
+	return fields, nil, nil
+}
+
+// CSVRecord is a decoded CSV record. Its fields can be selected by index, and by name if the message had a header.
+type CSVRecord struct {
+	names  []string
+	fields []*string
+}
+
+func (c *CSVRecord) FieldByIndex(index int) (interface{}, bool) {
+	if index < 0 || index >= len(c.fields) {
+		return nil, false
+	}
+	if c.fields[index] == nil {
+		return nil, true
+	}
+	return *c.fields[index], true
+}
+
+func (c *CSVRecord) FieldByName(name string) (interface{}, bool) {
+	for i, n := range c.names {
+		if n == name {
+			return c.FieldByIndex(i)
+		}
+	}
+	return nil, false
+}
+
+var _ selector.Record = &CSVRecord{}
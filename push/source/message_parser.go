@@ -2,8 +2,8 @@ package source
 
 import (
 	"encoding/json"
-	"fmt"
 	"github.com/golang/protobuf/proto"
+	"github.com/squareup/pranadb/avrolib"
 	"github.com/squareup/pranadb/common"
 	"github.com/squareup/pranadb/errors"
 	"github.com/squareup/pranadb/kafka"
@@ -15,6 +15,7 @@ import (
 
 var (
 	jsonDecoder         = &JSONDecoder{}
+	rawDecoder          = &RawDecoder{}
 	kafkaDecoderFloat   = newKafkaDecoder(common.KafkaEncodingFloat32BE)
 	kafkaDecoderDouble  = newKafkaDecoder(common.KafkaEncodingFloat64BE)
 	kafkaDecoderInteger = newKafkaDecoder(common.KafkaEncodingInt32BE)
@@ -32,9 +33,11 @@ type MessageParser struct {
 	valueDecoder     Decoder
 	evalContext      *evalContext
 	protobufRegistry protolib.Resolver
+	avroRegistry     avrolib.Resolver
 }
 
-func NewMessageParser(sourceInfo *common.SourceInfo, registry protolib.Resolver) (*MessageParser, error) {
+func NewMessageParser(sourceInfo *common.SourceInfo, registry protolib.Resolver,
+	avroRegistry avrolib.Resolver) (*MessageParser, error) {
 	selectors := sourceInfo.TopicInfo.ColSelectors
 	selectEvals := make([]evaluable, len(selectors))
 	// We pre-compute whether the selectors need headers, key and value so we don't unnecessary parse them if they
@@ -59,13 +62,12 @@ func NewMessageParser(sourceInfo *common.SourceInfo, registry protolib.Resolver)
 				decodeHeader = true
 			case "key":
 				decodeKey = true
+			case "value":
+				decodeValue = true
 			case "timestamp":
 				// timestamp selector, no decoding required
 			default:
-				panic(fmt.Sprintf("invalid selector %q", selector))
-			}
-			if err != nil {
-				return nil, errors.WithStack(err)
+				return nil, errors.NewPranaErrorf(errors.InvalidSelector, "invalid column selector %q", selector)
 			}
 		}
 	}
@@ -73,6 +75,7 @@ func NewMessageParser(sourceInfo *common.SourceInfo, registry protolib.Resolver)
 	mp := &MessageParser{
 		rowsFactory:      common.NewRowsFactory(sourceInfo.ColumnTypes),
 		protobufRegistry: registry,
+		avroRegistry:     avroRegistry,
 		sourceInfo:       sourceInfo,
 		colEvals:         selectEvals,
 		headerDecoder:    headerDecoder,
@@ -83,19 +86,19 @@ func NewMessageParser(sourceInfo *common.SourceInfo, registry protolib.Resolver)
 		},
 	}
 	if decodeHeader {
-		mp.headerDecoder, err = getDecoder(registry, topic.HeaderEncoding)
+		mp.headerDecoder, err = getDecoder(registry, avroRegistry, topic.HeaderEncoding, topic.Properties)
 		if err != nil {
 			return nil, errors.WithStack(err)
 		}
 	}
 	if decodeKey {
-		mp.keyDecoder, err = getDecoder(registry, topic.KeyEncoding)
+		mp.keyDecoder, err = getDecoder(registry, avroRegistry, topic.KeyEncoding, topic.Properties)
 		if err != nil {
 			return nil, errors.WithStack(err)
 		}
 	}
 	if decodeValue {
-		mp.valueDecoder, err = getDecoder(registry, topic.ValueEncoding)
+		mp.valueDecoder, err = getDecoder(registry, avroRegistry, topic.ValueEncoding, topic.Properties)
 		if err != nil {
 			return nil, errors.WithStack(err)
 		}
@@ -112,6 +115,11 @@ func (m *MessageParser) ParseMessages(messages []*kafka.Message) (*common.Rows,
 		if err := m.evalColumns(rows); err != nil {
 			return nil, errors.WithStack(err)
 		}
+		if m.sourceInfo.AppendOnly {
+			// The hidden key columns come after the selected columns
+			rows.AppendInt64ToColumn(len(m.colEvals), int64(msg.PartInfo.PartitionID))
+			rows.AppendInt64ToColumn(len(m.colEvals)+1, msg.PartInfo.Offset)
+		}
 	}
 	return rows, nil
 }
@@ -154,6 +162,7 @@ func (m *MessageParser) decodeMessage(message *kafka.Message) error {
 	m.evalContext.meta["header"] = hdrs
 	m.evalContext.meta["key"] = km
 	m.evalContext.meta["timestamp"] = message.TimeStamp
+	m.evalContext.meta["value"] = vm
 	m.evalContext.value = vm
 
 	return nil
@@ -170,53 +179,89 @@ func (m *MessageParser) evalColumns(rows *common.Rows) error {
 			rows.AppendNullToColumn(i)
 			continue
 		}
-		switch colType.Type {
-		case common.TypeTinyInt, common.TypeInt, common.TypeBigInt:
-			ival, err := CoerceInt64(val)
-			if err != nil {
-				return errors.WithStack(err)
+		if err := AppendCoercedValue(rows, i, colType, val); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	return nil
+}
+
+// ValidateTopicInfo checks that the encodings used by the column selectors of a source are supported, that their
+// properties are valid, and that the selectors are valid for the encodings.
+func ValidateTopicInfo(topicInfo *common.TopicInfo, colTypes []common.ColumnType) error {
+	for i, sel := range topicInfo.ColSelectors {
+		var (
+			encoding common.KafkaEncoding
+			path     = sel.Selector
+		)
+		metaKey := "value"
+		if sel.MetaKey != nil {
+			metaKey = *sel.MetaKey
+		}
+		switch metaKey {
+		case "header":
+			encoding = topicInfo.HeaderEncoding
+			// The first token of a header selector is the name of the header
+			if len(path) == 0 || path[0].Field == nil {
+				return errors.NewPranaErrorf(errors.InvalidSelector,
+					"invalid column selector %q, a header selector must start with the name of the header", sel)
 			}
-			rows.AppendInt64ToColumn(i, ival)
-		case common.TypeDouble:
-			fval, err := CoerceFloat64(val)
-			if err != nil {
-				return errors.WithStack(err)
+			path = path[1:]
+		case "key":
+			encoding = topicInfo.KeyEncoding
+		case "value":
+			encoding = topicInfo.ValueEncoding
+		default:
+			continue
+		}
+		switch encoding.Encoding {
+		case common.EncodingRaw:
+			if len(path) != 0 {
+				return errors.NewPranaErrorf(errors.InvalidSelector,
+					"invalid column selector %q, a raw encoded %s cannot be selected into", sel, metaKey)
 			}
-			rows.AppendFloat64ToColumn(i, fval)
-		case common.TypeVarchar:
-			sval, err := CoerceString(val)
-			if err != nil {
-				return errors.WithStack(err)
+			if i < len(colTypes) && colTypes[i].Type != common.TypeVarchar && colTypes[i].Type != common.TypeVarbinary {
+				return errors.NewPranaErrorf(errors.InvalidSelector,
+					"invalid column selector %q, a raw encoded %s can only be selected into a varchar or varbinary column",
+					sel, metaKey)
 			}
-			rows.AppendStringToColumn(i, sval)
-		case common.TypeDecimal:
-			dval, err := CoerceDecimal(val)
-			if err != nil {
-				return errors.WithStack(err)
+		case common.EncodingCSV:
+			if len(path) != 1 {
+				return errors.NewPranaErrorf(errors.InvalidSelector,
+					"invalid column selector %q, a csv encoded %s must be selected by a single field index or name",
+					sel, metaKey)
 			}
-			rows.AppendDecimalToColumn(i, *dval)
-		case common.TypeTimestamp:
-			tsVal, err := CoerceTimestamp(val)
+			csvDecoder, err := newCSVDecoder(topicInfo.Properties)
 			if err != nil {
 				return errors.WithStack(err)
 			}
-			tsVal.SetFsp(colType.FSP)
-			if err := common.RoundTimestampToFSP(&tsVal, colType.FSP); err != nil {
-				return err
+			if path[0].Field != nil && !csvDecoder.header {
+				// Without a header there are no field names, so the column would always be null
+				return errors.NewPranaErrorf(errors.InvalidStatement,
+					"invalid column selector %q, a csv field can only be selected by name if %s is true", sel,
+					csvHeaderPropName)
 			}
-			rows.AppendTimestampToColumn(i, tsVal)
-		default:
-			return errors.Errorf("unsupported col type %d", colType.Type)
+		case common.EncodingUnknown:
+			return errors.NewPranaErrorf(errors.UnknownTopicEncoding, "unsupported %s encoding", metaKey)
 		}
 	}
 	return nil
 }
 
-func getDecoder(registry protolib.Resolver, encoding common.KafkaEncoding) (Decoder, error) {
+func getDecoder(registry protolib.Resolver, avroRegistry avrolib.Resolver, encoding common.KafkaEncoding,
+	props map[string]string) (Decoder, error) {
 	var decoder Decoder
 	switch encoding.Encoding {
 	case common.EncodingJSON:
 		decoder = jsonDecoder
+	case common.EncodingRaw:
+		decoder = rawDecoder
+	case common.EncodingCSV:
+		csvDecoder, err := newCSVDecoder(props)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		decoder = csvDecoder
 	case common.EncodingFloat64BE:
 		decoder = kafkaDecoderDouble
 	case common.EncodingFloat32BE:
@@ -239,8 +284,10 @@ func getDecoder(registry protolib.Resolver, encoding common.KafkaEncoding) (Deco
 			return nil, errors.Errorf("expected to find MessageDescriptor at %q, but was %q", encoding.SchemaName, reflect.TypeOf(msgDesc))
 		}
 		decoder = &ProtobufDecoder{desc: msgDesc}
+	case common.EncodingAvro:
+		decoder = &AvroDecoder{registry: avroRegistry}
 	default:
-		panic(fmt.Sprintf("unsupported encoding %+v", encoding))
+		return nil, errors.NewPranaErrorf(errors.UnknownTopicEncoding, "unsupported encoding %+v", encoding)
 	}
 	return decoder, nil
 }
@@ -268,6 +315,32 @@ func (j *JSONDecoder) Decode(bytes []byte) (interface{}, error) {
 	return m, nil
 }
 
+// RawDecoder decodes a message as its bytes
+type RawDecoder struct {
+}
+
+func (r *RawDecoder) Decode(bytes []byte) (interface{}, error) {
+	return bytes, nil
+}
+
+// AvroDecoder decodes Avro messages in the schema registry wire format, looking up the schema they were encoded with
+// by the id in the message
+type AvroDecoder struct {
+	registry avrolib.Resolver
+}
+
+func (a *AvroDecoder) Decode(bytes []byte) (interface{}, error) {
+	schemaID, datum, err := avrolib.ReadWireFormatHeader(bytes)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	schema, err := a.registry.FindSchemaByID(schemaID)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return schema.Decode(datum)
+}
+
 func newKafkaDecoder(encoding common.KafkaEncoding) *KafkaDecoder {
 	return &KafkaDecoder{encoding: encoding}
 }
@@ -4,6 +4,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/squareup/pranadb/avrolib"
 	"github.com/squareup/pranadb/common"
 	"github.com/squareup/pranadb/kafka"
 	"github.com/squareup/pranadb/protolib"
@@ -40,7 +41,7 @@ func setupMessageParserJSON() *MessageParser {
 		TableInfo: tableInfo,
 		TopicInfo: topicInfo,
 	}
-	mp, err := NewMessageParser(sourceInfo, protolib.EmptyRegistry)
+	mp, err := NewMessageParser(sourceInfo, protolib.EmptyRegistry, avrolib.EmptyRegistry)
 	if err != nil {
 		panic(err)
 	}
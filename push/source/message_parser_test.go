@@ -5,6 +5,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/squareup/pranadb/avrolib"
 	"github.com/squareup/pranadb/command/parser/selector"
 	"github.com/squareup/pranadb/common"
 	"github.com/squareup/pranadb/errors"
@@ -165,6 +166,234 @@ func TestParseMessageNilJsonKeyAndNilJsonVals(t *testing.T) {
 		[]string{"meta(\"key\").kf1", "vf1", "vf2", "vf3", "vf4"}, time.Now(), vf)
 }
 
+func TestParseMessageNewColumnTypes(t *testing.T) {
+	theColTypes := []common.ColumnType{common.BigIntColumnType, common.BooleanColumnType, common.DateColumnType,
+		common.VarbinaryColumnType, common.JSONColumnType}
+	vf := func(t *testing.T, row *common.Row) {
+		t.Helper()
+		require.Equal(t, int64(1234), row.GetInt64(0))
+		require.Equal(t, int64(1), row.GetInt64(1))
+		require.Equal(t, "2021-03-04", row.GetTimestamp(2).String())
+		require.Equal(t, []byte("foo"), row.GetBytes(3))
+		require.Equal(t, `{"a": [1, 2.5, "x", null], "b": true}`, row.GetJSON(4).String())
+	}
+
+	testParseMessage(t, colNames, theColTypes,
+		common.KafkaEncodingJSON, common.KafkaEncodingJSON, common.KafkaEncodingJSON,
+		nil, []byte(`{"kf1":1234}`), []byte(`{"vf1":true,"vf2":"2021-03-04","vf3":"foo","vf4":{"a":[1,2.5,"x",null],"b":true}}`),
+		[]string{"meta(\"key\").kf1", "vf1", "vf2", "vf3", "vf4"}, time.Now(), vf)
+}
+
+func TestParseMessageAvro(t *testing.T) {
+	registry := avrolib.NewFakeSchemaRegistry()
+	defer registry.Close()
+	require.NoError(t, registry.RegisterSchema(1, `{"type": "record", "name": "Key", "fields": [{"name": "kf1", "type": "long"}]}`))
+	require.NoError(t, registry.RegisterSchema(2, `{"type": "record", "name": "Value", "fields": [
+		{"name": "vf1", "type": "int"},
+		{"name": "nested", "type": ["null", {"type": "record", "name": "Nested", "fields": [
+			{"name": "vf2", "type": "float"},
+			{"name": "vf3", "type": {"type": "array", "items": "string"}}
+		]}]},
+		{"name": "vf4", "type": {"type": "bytes", "logicalType": "decimal", "precision": 10, "scale": 2}}
+	]}`))
+	keySchema, err := registry.FindSchemaByID(1)
+	require.NoError(t, err)
+	valueSchema, err := registry.FindSchemaByID(2)
+	require.NoError(t, err)
+	keyBytes, err := keySchema.Encode(avrolib.AppendWireFormatHeader(nil, 1), map[string]interface{}{"kf1": int64(1234)})
+	require.NoError(t, err)
+	valueBytes, err := valueSchema.Encode(avrolib.AppendWireFormatHeader(nil, 2), map[string]interface{}{
+		"vf1":    int32(4321),
+		"nested": map[string]interface{}{"vf2": float32(23.125), "vf3": []interface{}{"bar", "foo"}},
+		"vf4":    "12345678.99",
+	})
+	require.NoError(t, err)
+
+	vf := func(t *testing.T, row *common.Row) {
+		t.Helper()
+		require.Equal(t, int64(1234), row.GetInt64(0))
+		require.Equal(t, int64(4321), row.GetInt64(1))
+		require.Equal(t, 23.125, row.GetFloat64(2))
+		require.Equal(t, "foo", row.GetString(3))
+		dec := row.GetDecimal(4)
+		require.Equal(t, "12345678.99", dec.String())
+	}
+	testParseMessageWithAvroRegistry(t, registry, colNames, colTypes,
+		common.KafkaEncodingJSON, common.KafkaEncodingAvro, common.KafkaEncodingAvro,
+		nil, keyBytes, valueBytes,
+		[]string{"meta(\"key\").kf1", "vf1", "nested.vf2", "nested.vf3[1]", "vf4"}, time.Now(), vf)
+}
+
+func TestParseMessageRaw(t *testing.T) {
+	theColTypes := []common.ColumnType{common.VarcharColumnType, common.VarbinaryColumnType, common.VarcharColumnType}
+	vf := func(t *testing.T, row *common.Row) {
+		t.Helper()
+		require.Equal(t, "key1", row.GetString(0))
+		require.Equal(t, []byte{0, 1, 0xff}, row.GetBytes(1))
+		require.Equal(t, "hval", row.GetString(2))
+	}
+	testParseMessage(t, colNames[:3], theColTypes,
+		common.KafkaEncodingRaw, common.KafkaEncodingRaw, common.KafkaEncodingRaw,
+		[]kafka.MessageHeader{{Key: "h1", Value: []byte("hval")}}, []byte("key1"), []byte{0, 1, 0xff},
+		[]string{`meta("key")`, `meta("value")`, `meta("header").h1`}, time.Now(), vf)
+}
+
+func TestParseMessageCSV(t *testing.T) {
+	theColTypes := []common.ColumnType{common.BigIntColumnType, common.VarcharColumnType, common.DoubleColumnType,
+		common.VarcharColumnType}
+	tests := []struct {
+		name       string
+		props      map[string]string
+		key        string
+		value      string
+		selectors  []string
+		wantString string
+		wantNull   bool
+	}{
+		{
+			name:       "by index",
+			key:        "1234",
+			value:      `abc,23.5,"x, ""y"""`,
+			selectors:  []string{`meta("key")[0]`, "[0]", "[1]", "[2]"},
+			wantString: `x, "y"`,
+		},
+		{
+			name:       "delimiter and quote",
+			props:      map[string]string{"prana.source.csv.delimiter": "|", "prana.source.csv.quote": "'"},
+			key:        "1234",
+			value:      "abc|23.5|'x| ''y'''\r\n",
+			selectors:  []string{`meta("key")[0]`, "[0]", "[1]", "[2]"},
+			wantString: `x| 'y'`,
+		},
+		{
+			name:       "by name",
+			props:      map[string]string{"prana.source.csv.header": "true"},
+			key:        "k\n1234\n",
+			value:      "f1,f2,f3\nabc,23.5,\"\"",
+			selectors:  []string{`meta("key").k`, "f1", "f2", "f3"},
+			wantString: "",
+		},
+		{
+			name:      "null",
+			key:       "1234",
+			value:     "abc,23.5,",
+			selectors: []string{`meta("key")[0]`, "[0]", "[1]", "[2]"},
+			wantNull:  true,
+		},
+		{
+			name:      "missing field",
+			key:       "1234",
+			value:     "abc,23.5",
+			selectors: []string{`meta("key")[0]`, "[0]", "[1]", "[2]"},
+			wantNull:  true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			selectors, err := compileSelectors(test.selectors)
+			require.NoError(t, err)
+			sourceInfo := &common.SourceInfo{
+				TableInfo: &common.TableInfo{
+					Name:           "test_table",
+					PrimaryKeyCols: []int{0},
+					ColumnNames:    colNames[:4],
+					ColumnTypes:    theColTypes,
+				},
+				TopicInfo: &common.TopicInfo{
+					HeaderEncoding: common.KafkaEncodingJSON,
+					KeyEncoding:    common.KafkaEncodingCSV,
+					ValueEncoding:  common.KafkaEncodingCSV,
+					ColSelectors:   selectors,
+					Properties:     test.props,
+				},
+			}
+			require.NoError(t, ValidateTopicInfo(sourceInfo.TopicInfo, theColTypes))
+			mp, err := NewMessageParser(sourceInfo, protolib.EmptyRegistry, avrolib.EmptyRegistry)
+			require.NoError(t, err)
+			rows, err := mp.ParseMessages([]*kafka.Message{{Key: []byte(test.key), Value: []byte(test.value)}})
+			require.NoError(t, err)
+			require.Equal(t, 1, rows.RowCount())
+			row := rows.GetRow(0)
+			require.Equal(t, int64(1234), row.GetInt64(0))
+			require.Equal(t, "abc", row.GetString(1))
+			require.Equal(t, 23.5, row.GetFloat64(2))
+			if test.wantNull {
+				require.True(t, row.IsNull(3))
+			} else {
+				require.Equal(t, test.wantString, row.GetString(3))
+			}
+		})
+	}
+}
+
+func TestDecodeCSVInvalid(t *testing.T) {
+	decoder, err := newCSVDecoder(nil)
+	require.NoError(t, err)
+	for _, value := range []string{"", "a,b\nc,d", "a,\"b", "a,b\"c\"", "a,\"b\"c"} {
+		_, err := decoder.Decode([]byte(value))
+		require.Error(t, err, value)
+	}
+	headerDecoder, err := newCSVDecoder(map[string]string{"prana.source.csv.header": "true"})
+	require.NoError(t, err)
+	_, err = headerDecoder.Decode([]byte("a,b\n"))
+	require.Error(t, err)
+}
+
+func TestValidateTopicInfo(t *testing.T) {
+	tests := []struct {
+		name      string
+		encoding  common.KafkaEncoding
+		props     map[string]string
+		selector  string
+		colType   common.ColumnType
+		wantError string
+	}{
+		{name: "raw", encoding: common.KafkaEncodingRaw, selector: `meta("value")`, colType: common.VarbinaryColumnType},
+		{name: "raw header", encoding: common.KafkaEncodingRaw, selector: `meta("header").h1`, colType: common.VarcharColumnType},
+		{name: "raw path", encoding: common.KafkaEncodingRaw, selector: `meta("value")[0]`, colType: common.VarcharColumnType,
+			wantError: "cannot be selected into"},
+		{name: "raw column type", encoding: common.KafkaEncodingRaw, selector: `meta("key")`, colType: common.BigIntColumnType,
+			wantError: "varchar or varbinary"},
+		{name: "header name", encoding: common.KafkaEncodingRaw, selector: `meta("header")`, colType: common.VarcharColumnType,
+			wantError: "must start with the name of the header"},
+		{name: "csv", encoding: common.KafkaEncodingCSV, selector: "[1]", colType: common.BigIntColumnType},
+		{name: "csv name", encoding: common.KafkaEncodingCSV, props: map[string]string{"prana.source.csv.header": "true"},
+			selector: "f1", colType: common.BigIntColumnType},
+		{name: "csv name without header", encoding: common.KafkaEncodingCSV, selector: "f1", colType: common.BigIntColumnType,
+			wantError: "can only be selected by name if prana.source.csv.header is true"},
+		{name: "csv path", encoding: common.KafkaEncodingCSV, selector: "[1][2]", colType: common.BigIntColumnType,
+			wantError: "single field index or name"},
+		{name: "csv delimiter", encoding: common.KafkaEncodingCSV, props: map[string]string{"prana.source.csv.delimiter": "ab"},
+			selector: "[1]", colType: common.BigIntColumnType, wantError: "must be a single character"},
+		{name: "csv quote", encoding: common.KafkaEncodingCSV, props: map[string]string{"prana.source.csv.quote": ","},
+			selector: "[1]", colType: common.BigIntColumnType, wantError: "must be different"},
+		{name: "csv header", encoding: common.KafkaEncodingCSV, props: map[string]string{"prana.source.csv.header": "maybe"},
+			selector: "[1]", colType: common.BigIntColumnType, wantError: "must be true or false"},
+		{name: "unknown", encoding: common.KafkaEncodingUnknown, selector: "f1", colType: common.BigIntColumnType,
+			wantError: "unsupported value encoding"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			selectors, err := compileSelectors([]string{test.selector})
+			require.NoError(t, err)
+			topicInfo := &common.TopicInfo{
+				HeaderEncoding: test.encoding,
+				KeyEncoding:    test.encoding,
+				ValueEncoding:  test.encoding,
+				ColSelectors:   selectors,
+				Properties:     test.props,
+			}
+			err = ValidateTopicInfo(topicInfo, []common.ColumnType{test.colType})
+			if test.wantError == "" {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), test.wantError)
+			}
+		})
+	}
+}
+
 func verifyJSONExpectedValues(t *testing.T, row *common.Row) {
 	t.Helper()
 	require.Equal(t, int64(1234), row.GetInt64(0))
@@ -407,6 +636,15 @@ func testParseMessage(t *testing.T, colNames []string, colTypes []common.ColumnT
 	valueEncoding common.KafkaEncoding, headers []kafka.MessageHeader, keyBytes []byte, valueBytes []byte, colSelectors []string, timestamp time.Time,
 	vf verifyExpectedValuesFunc) {
 	t.Helper()
+	testParseMessageWithAvroRegistry(t, avrolib.EmptyRegistry, colNames, colTypes, headerEncoding, keyEncoding, valueEncoding,
+		headers, keyBytes, valueBytes, colSelectors, timestamp, vf)
+}
+
+func testParseMessageWithAvroRegistry(t *testing.T, avroRegistry avrolib.Resolver, colNames []string, colTypes []common.ColumnType,
+	headerEncoding common.KafkaEncoding, keyEncoding common.KafkaEncoding, valueEncoding common.KafkaEncoding,
+	headers []kafka.MessageHeader, keyBytes []byte, valueBytes []byte, colSelectors []string, timestamp time.Time,
+	vf verifyExpectedValuesFunc) {
+	t.Helper()
 	tableInfo := &common.TableInfo{
 		ID:             0,
 		SchemaName:     "test",
@@ -431,7 +669,7 @@ func testParseMessage(t *testing.T, colNames []string, colTypes []common.ColumnT
 		TableInfo: tableInfo,
 		TopicInfo: topicInfo,
 	}
-	mp, err := NewMessageParser(sourceInfo, protolib.EmptyRegistry)
+	mp, err := NewMessageParser(sourceInfo, protolib.EmptyRegistry, avroRegistry)
 	require.NoError(t, err)
 
 	msg := &kafka.Message{
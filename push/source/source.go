@@ -2,6 +2,7 @@ package source
 
 import (
 	"fmt"
+	"github.com/squareup/pranadb/avrolib"
 	"github.com/squareup/pranadb/push/util"
 	"strconv"
 	"sync"
@@ -48,6 +49,7 @@ type Source struct {
 	sharder                 *sharder.Sharder
 	cluster                 cluster.Cluster
 	protoRegistry           protolib.Resolver
+	avroRegistry            avrolib.Resolver
 	msgProvFact             kafka.MessageProviderFactory
 	msgConsumers            []*MessageConsumer
 	queryExec               common.SimpleQueryExec
@@ -93,7 +95,7 @@ var (
 
 func NewSource(sourceInfo *common.SourceInfo, tableExec *exec.TableExecutor, sharder *sharder.Sharder,
 	cluster cluster.Cluster, cfg *conf.Config, queryExec common.SimpleQueryExec, registry protolib.Resolver,
-	globalRateLimiter IngestLimiter) (*Source, error) {
+	avroRegistry avrolib.Resolver, globalRateLimiter IngestLimiter) (*Source, error) {
 	// TODO we should validate the sourceinfo - e.g. check that number of col selectors, column names and column types are the same
 	var msgProvFact kafka.MessageProviderFactory
 	ti := sourceInfo.TopicInfo
@@ -145,6 +147,7 @@ func NewSource(sourceInfo *common.SourceInfo, tableExec *exec.TableExecutor, sha
 		sharder:                 sharder,
 		cluster:                 cluster,
 		protoRegistry:           registry,
+		avroRegistry:            avroRegistry,
 		msgProvFact:             msgProvFact,
 		queryExec:               queryExec,
 		numConsumersPerSource:   numConsumers,
@@ -205,18 +208,29 @@ func (s *Source) IsRunning() bool {
 }
 
 func (s *Source) Drop() error {
-	// Delete the deduplication ids for the source
 	log.Printf("dropping source %s %d", s.sourceInfo.Name, s.sourceInfo.ID)
+	if err := s.dropTable(s.sourceInfo.ID); err != nil {
+		return errors.WithStack(err)
+	}
+	if watermark := s.sourceInfo.TopicInfo.Watermark; watermark != nil {
+		// The watermarks are forwarded like the rows, so they have deduplication ids too
+		return s.dropTable(watermark.TableID)
+	}
+	return nil
+}
+
+func (s *Source) dropTable(tableID uint64) error {
+	// Delete the deduplication ids for the table
 	startPrefix := common.AppendUint64ToBufferBE(nil, common.ForwardDedupTableID)
-	startPrefix = common.AppendUint64ToBufferBE(startPrefix, s.sourceInfo.ID)
+	startPrefix = common.AppendUint64ToBufferBE(startPrefix, tableID)
 	endPrefix := common.IncrementBytesBigEndian(startPrefix)
 	if err := s.cluster.DeleteAllDataInRangeForAllShardsLocally(startPrefix, endPrefix); err != nil {
 		return errors.WithStack(err)
 	}
 
 	// Delete the table data
-	tableStartPrefix := common.AppendUint64ToBufferBE(nil, s.sourceInfo.ID)
-	tableEndPrefix := common.AppendUint64ToBufferBE(nil, s.sourceInfo.ID+1)
+	tableStartPrefix := common.AppendUint64ToBufferBE(nil, tableID)
+	tableEndPrefix := common.AppendUint64ToBufferBE(nil, tableID+1)
 	return s.cluster.DeleteAllDataInRangeForAllShardsLocally(tableStartPrefix, tableEndPrefix)
 }
 
@@ -287,7 +301,8 @@ func (s *Source) stop() error {
 	return nil
 }
 
-func (s *Source) ingestMessages(messages []*kafka.Message, mp *MessageParser) error {
+func (s *Source) ingestMessages(messages []*kafka.Message, mp *MessageParser, eventTimes map[int32]time.Time,
+	numPartitions int) error {
 
 	start := time.Now()
 
@@ -296,8 +311,6 @@ func (s *Source) ingestMessages(messages []*kafka.Message, mp *MessageParser) er
 		return errors.WithStack(err)
 	}
 
-	// TODO where Source has no key - need to create one
-
 	// Partition the rows and send them to the appropriate shards
 	info := s.sourceInfo.TableInfo
 	pkCols := info.PrimaryKeyCols
@@ -313,6 +326,18 @@ func (s *Source) ingestMessages(messages []*kafka.Message, mp *MessageParser) er
 		s.globalRateLimiter.Limit()
 
 		row := rows.GetRow(i)
+		kMsg := messages[i]
+		// A message with a null value is a tombstone, which deletes the row with its key
+		tombstone := kMsg.Value == nil
+		if tombstone && info.AppendOnly {
+			// The rows of an append-only source are never deleted
+			continue
+		}
+		if tombstone && hasNullCol(&row, pkCols) {
+			log.Warnf("Tombstone at partition %d offset %d for source %s does not have the primary key and will be ignored",
+				kMsg.PartInfo.PartitionID, kMsg.PartInfo.Offset, s.sourceInfo.Name)
+			continue
+		}
 		key := make([]byte, 0, 8)
 		key, err := common.EncodeKeyCols(&row, pkCols, colTypes, key)
 		if err != nil {
@@ -330,7 +355,6 @@ func (s *Source) ingestMessages(messages []*kafka.Message, mp *MessageParser) er
 			forwardBatches[destShardID] = forwardBatch
 		}
 
-		kMsg := messages[i]
 		forwardKey := util.EncodeKeyForForwardIngest(tableID, uint64(kMsg.PartInfo.PartitionID),
 			uint64(kMsg.PartInfo.Offset), tableID)
 
@@ -341,7 +365,12 @@ func (s *Source) ingestMessages(messages []*kafka.Message, mp *MessageParser) er
 			return err
 		}
 
-		forwardBatch.AddPut(forwardKey, util.EncodePrevAndCurrentRow(nil, encodedRow))
+		if tombstone {
+			// The previous row only has the key, the table executor retracts the stored row
+			forwardBatch.AddPut(forwardKey, util.EncodePrevAndCurrentRow(encodedRow, nil))
+		} else {
+			forwardBatch.AddPut(forwardKey, util.EncodePrevAndCurrentRow(nil, encodedRow))
+		}
 
 		l := len(valueBuff)
 		totBatchSizeBytes += l
@@ -353,6 +382,13 @@ func (s *Source) ingestMessages(messages []*kafka.Message, mp *MessageParser) er
 		return err
 	}
 
+	if s.sourceInfo.TopicInfo.Watermark != nil {
+		if err := s.sendWatermarks(messages, rows, eventTimes, numPartitions); err != nil {
+			log.Errorf("failed to send watermarks %+v", err)
+			return err
+		}
+	}
+
 	ingestTimeNanos := time.Now().Sub(start).Nanoseconds()
 	s.ingestDurationHistogram.Observe(float64(ingestTimeNanos))
 	s.rowsIngestedCounter.Add(float64(rows.RowCount()))
@@ -362,6 +398,15 @@ func (s *Source) ingestMessages(messages []*kafka.Message, mp *MessageParser) er
 	return nil
 }
 
+func hasNullCol(row *common.Row, cols []int) bool {
+	for _, col := range cols {
+		if row.IsNull(col) {
+			return true
+		}
+	}
+	return false
+}
+
 func (s *Source) TableExecutor() *exec.TableExecutor {
 	return s.tableExecutor
 }
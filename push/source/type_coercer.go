@@ -98,6 +98,8 @@ func CoerceString(val interface{}) (string, error) {
 	switch v := val.(type) {
 	case string:
 		return v, nil
+	case []byte:
+		return string(v), nil
 	case int64, int32, uint64, int16, uint32, uint16, int:
 		return fmt.Sprintf("%d", v), nil
 	case float64, float32:
@@ -145,7 +147,11 @@ func CoerceTimestamp(val interface{}) (common.Timestamp, error) {
 	case time.Time:
 		return common.NewTimestampFromGoTime(v), nil
 	case string:
-		return common.NewTimestampFromString(v), nil
+		ts, err := common.ParseTimestamp(v)
+		if err != nil {
+			return common.Timestamp{}, errors.Errorf("string value %s cannot be coerced to timestamp %v", v, err)
+		}
+		return ts, nil
 	case float64:
 		return CoerceTimestamp(uint64(v))
 	case uint64:
@@ -161,6 +167,189 @@ func CoerceTimestamp(val interface{}) (common.Timestamp, error) {
 	}
 }
 
+func CoerceBool(val interface{}) (bool, error) {
+	switch v := val.(type) {
+	case bool:
+		return v, nil
+	case string:
+		r, err := strconv.ParseBool(v)
+		if err != nil {
+			return false, errors.Errorf("string value %s cannot be coerced to boolean %v", v, err)
+		}
+		return r, nil
+	case int64, int32, uint64, int16, uint32, uint16, int, float64, float32:
+		ival, err := CoerceInt64(v)
+		if err != nil {
+			return false, err
+		}
+		return ival != 0, nil
+	default:
+		return false, coerceFailedErr(v, "boolean")
+	}
+}
+
+func CoerceDate(val interface{}) (common.Timestamp, error) {
+	switch v := val.(type) {
+	case string:
+		date, err := common.ParseDate(v)
+		if err != nil {
+			return common.Timestamp{}, errors.Errorf("string value %s cannot be coerced to date %v", v, err)
+		}
+		return date, nil
+	default:
+		// Anything which can be a timestamp can be truncated to a date
+		ts, err := CoerceTimestamp(v)
+		if err != nil {
+			return common.Timestamp{}, coerceFailedErr(v, "date")
+		}
+		gt, err := ts.GoTime(time.UTC)
+		if err != nil {
+			return common.Timestamp{}, errors.WithStack(err)
+		}
+		return common.NewDateFromGoTime(gt), nil
+	}
+}
+
+func CoerceBytes(val interface{}) ([]byte, error) {
+	switch v := val.(type) {
+	case []byte:
+		return v, nil
+	case string:
+		return []byte(v), nil
+	default:
+		return nil, coerceFailedErr(v, "varbinary")
+	}
+}
+
+func CoerceJSON(val interface{}) (common.JSON, error) {
+	switch v := val.(type) {
+	case common.JSON:
+		return v, nil
+	case string:
+		j, err := common.ParseJSON(v)
+		if err != nil {
+			return common.JSON{}, errors.Errorf("string value %s cannot be coerced to json %v", v, err)
+		}
+		return j, nil
+	case []byte:
+		return CoerceJSON(string(v))
+	default:
+		goVal, err := toJSONGoValue(v)
+		if err != nil {
+			return common.JSON{}, err
+		}
+		return common.NewJSONFromGoValue(goVal)
+	}
+}
+
+// toJSONGoValue converts the value into the form expected by common.NewJSONFromGoValue, where numbers are int64,
+// uint64 or float64, objects are map[string]interface{} and arrays are []interface{}
+func toJSONGoValue(val interface{}) (interface{}, error) {
+	switch v := val.(type) {
+	case nil, bool, int64, uint64, float64, string, common.JSON:
+		return v, nil
+	case int32, int16, int:
+		return CoerceInt64(v)
+	case uint32, uint16:
+		return uint64(reflect.ValueOf(v).Uint()), nil
+	case float32:
+		return float64(v), nil
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			je, err := toJSONGoValue(e)
+			if err != nil {
+				return nil, err
+			}
+			m[k] = je
+		}
+		return m, nil
+	case []interface{}:
+		arr := make([]interface{}, len(v))
+		for i, e := range v {
+			je, err := toJSONGoValue(e)
+			if err != nil {
+				return nil, err
+			}
+			arr[i] = je
+		}
+		return arr, nil
+	default:
+		return nil, coerceFailedErr(v, "json")
+	}
+}
+
 func coerceFailedErr(v interface{}, t string) error {
 	return errors.Errorf("cannot coerce value %v, type %s to %s", v, reflect.TypeOf(v), t)
 }
+
+// AppendCoercedValue coerces the value to the type of the column and appends it to the column
+func AppendCoercedValue(rows *common.Rows, colIndex int, colType common.ColumnType, val interface{}) error {
+	switch colType.Type {
+	case common.TypeTinyInt, common.TypeInt, common.TypeBigInt:
+		ival, err := CoerceInt64(val)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		rows.AppendInt64ToColumn(colIndex, ival)
+	case common.TypeDouble:
+		fval, err := CoerceFloat64(val)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		rows.AppendFloat64ToColumn(colIndex, fval)
+	case common.TypeVarchar:
+		sval, err := CoerceString(val)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		rows.AppendStringToColumn(colIndex, sval)
+	case common.TypeDecimal:
+		dval, err := CoerceDecimal(val)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		rows.AppendDecimalToColumn(colIndex, *dval)
+	case common.TypeTimestamp:
+		tsVal, err := CoerceTimestamp(val)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		tsVal.SetFsp(colType.FSP)
+		if err := common.RoundTimestampToFSP(&tsVal, colType.FSP); err != nil {
+			return err
+		}
+		rows.AppendTimestampToColumn(colIndex, tsVal)
+	case common.TypeBoolean:
+		bval, err := CoerceBool(val)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		var ival int64
+		if bval {
+			ival = 1
+		}
+		rows.AppendInt64ToColumn(colIndex, ival)
+	case common.TypeDate:
+		dval, err := CoerceDate(val)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		rows.AppendTimestampToColumn(colIndex, dval)
+	case common.TypeVarbinary:
+		bval, err := CoerceBytes(val)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		rows.AppendBytesToColumn(colIndex, bval)
+	case common.TypeJSON:
+		jval, err := CoerceJSON(val)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		rows.AppendJSONToColumn(colIndex, jval)
+	default:
+		return errors.Errorf("unsupported col type %d", colType.Type)
+	}
+	return nil
+}
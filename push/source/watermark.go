@@ -0,0 +1,157 @@
+package source
+
+import (
+	"time"
+
+	"github.com/squareup/pranadb/cluster"
+	"github.com/squareup/pranadb/common"
+	"github.com/squareup/pranadb/errors"
+	"github.com/squareup/pranadb/kafka"
+	"github.com/squareup/pranadb/push/exec"
+	"github.com/squareup/pranadb/push/util"
+	"github.com/squareup/pranadb/table"
+)
+
+// WatermarkColTypes are the column types of the watermarks forwarded to the shards: the partition, its watermark, and
+// the number of partitions of the topic
+var WatermarkColTypes = []common.ColumnType{common.BigIntColumnType, common.NewTimestampColumnType(6),
+	common.BigIntColumnType}
+
+var watermarkRowsFactory = common.NewRowsFactory(WatermarkColTypes)
+
+// sendWatermarks advances the event times of the partitions of the messages, and sends the watermarks of the partitions
+// which have advanced to every shard, as each shard merges them into the watermark of the source. They are sent after
+// the rows, so that the rows are processed before the watermark they advanced. The rows must be those parsed from the
+// messages, one for each message in the same order, as the partition and offset of a row are those of its message.
+func (s *Source) sendWatermarks(messages []*kafka.Message, rows *common.Rows, eventTimes map[int32]time.Time,
+	numPartitions int) error {
+	if rows.RowCount() != len(messages) {
+		return errors.Errorf("cannot send watermarks for %d rows parsed from %d messages", rows.RowCount(), len(messages))
+	}
+	info := s.sourceInfo.TopicInfo.Watermark
+	// The offset of the message which last advanced the event time of each partition
+	offsets := make(map[int32]int64)
+	for i := 0; i < rows.RowCount(); i++ {
+		row := rows.GetRow(i)
+		if row.IsNull(info.TimeCol) {
+			continue
+		}
+		eventTime, err := row.GetTimestamp(info.TimeCol).GoTime(time.UTC)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		partInfo := messages[i].PartInfo
+		if eventTime.After(eventTimes[partInfo.PartitionID]) {
+			eventTimes[partInfo.PartitionID] = eventTime
+			offsets[partInfo.PartitionID] = partInfo.Offset
+		}
+	}
+	if len(offsets) == 0 {
+		return nil
+	}
+
+	forwardBatches := make(map[uint64]*cluster.WriteBatch)
+	for partID, offset := range offsets {
+		rows := watermarkRowsFactory.NewRows(1)
+		rows.AppendInt64ToColumn(0, int64(partID))
+		watermark := eventTimes[partID].Add(-info.MaxOutOfOrderness)
+		rows.AppendTimestampToColumn(1, common.NewTimestampFromGoTime(watermark))
+		rows.AppendInt64ToColumn(2, int64(numPartitions))
+		row := rows.GetRow(0)
+		encodedRow, err := common.EncodeRow(&row, WatermarkColTypes, nil)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		// The offset is the sequence used to detect duplicates, as for the rows
+		forwardKey := util.EncodeKeyForForwardIngest(info.TableID, uint64(partID), uint64(offset), info.TableID)
+		value := util.EncodePrevAndCurrentRow(nil, encodedRow)
+		for _, shardID := range s.cluster.GetAllShardIDs() {
+			forwardBatch, ok := forwardBatches[shardID]
+			if !ok {
+				forwardBatch = cluster.NewWriteBatch(shardID)
+				forwardBatches[shardID] = forwardBatch
+			}
+			forwardBatch.AddPut(forwardKey, value)
+		}
+	}
+	return util.SendForwardBatches(forwardBatches, s.cluster)
+}
+
+// WatermarkReceiver receives the watermarks of the partitions of a source on a shard
+type WatermarkReceiver struct {
+	source *Source
+}
+
+// WatermarkReceiver returns the remote consumer of the watermarks sent by the source
+func (s *Source) WatermarkReceiver() *WatermarkReceiver {
+	return &WatermarkReceiver{source: s}
+}
+
+// HandleRemoteRows stores the watermarks of the partitions, and if any of them has advanced passes the watermark of the
+// source, the least of them, to the consumers of the source. A partition which hasn't had any messages holds the
+// watermark back, so the source has no watermark until every partition of the topic has had messages.
+func (w *WatermarkReceiver) HandleRemoteRows(rowsBatch exec.RowsBatch, ctx *exec.ExecutionContext) error {
+	info := w.source.sourceInfo.TopicInfo.Watermark
+	shardID := ctx.WriteBatch.ShardID
+	prefix := table.EncodeTableKeyPrefix(info.TableID, shardID, 24)
+	pairs, err := w.source.cluster.LocalScan(prefix, table.EncodeTableKeyPrefix(info.TableID+1, shardID, 16), -1)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	watermarks := make(map[uint64]time.Time, len(pairs))
+	for _, pair := range pairs {
+		partID, _ := common.ReadUint64FromBufferBE(pair.Key, 16)
+		if watermarks[partID], err = decodeWatermark(pair.Value); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+
+	advanced := false
+	numPartitions := 0
+	for i := 0; i < rowsBatch.Len(); i++ {
+		row := rowsBatch.CurrentRow(i)
+		if n := int(row.GetInt64(2)); n > numPartitions {
+			numPartitions = n
+		}
+		partID := uint64(row.GetInt64(0))
+		watermark, err := row.GetTimestamp(1).GoTime(time.UTC)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		if stored, ok := watermarks[partID]; ok && !watermark.After(stored) {
+			continue
+		}
+		watermarks[partID] = watermark
+		value, err := common.KeyEncodeTimestamp(nil, common.NewTimestampFromGoTime(watermark))
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		ctx.WriteBatch.AddPut(common.AppendUint64ToBufferBE(append([]byte{}, prefix...), partID), value)
+		advanced = true
+	}
+	if !advanced {
+		return nil
+	}
+
+	var watermark time.Time
+	for partID := 0; partID < numPartitions; partID++ {
+		partWatermark, ok := watermarks[uint64(partID)]
+		if !ok {
+			return nil
+		}
+		if partID == 0 || partWatermark.Before(watermark) {
+			watermark = partWatermark
+		}
+	}
+	ctx.Watermark = watermark
+	tableExecutor := w.source.tableExecutor
+	return tableExecutor.HandleRows(exec.NewCurrentRowsBatch(tableExecutor.RowsFactory().NewRows(0)), ctx)
+}
+
+func decodeWatermark(value []byte) (time.Time, error) {
+	ts, _, err := common.ReadTimestampFromBufferBE(value, 0, 6)
+	if err != nil {
+		return time.Time{}, errors.WithStack(err)
+	}
+	return ts.GoTime(time.UTC)
+}
@@ -0,0 +1,70 @@
+package source
+
+import (
+	"testing"
+	"time"
+
+	"github.com/squareup/pranadb/cluster"
+	"github.com/squareup/pranadb/cluster/fake"
+	"github.com/squareup/pranadb/common"
+	"github.com/squareup/pranadb/kafka"
+	"github.com/squareup/pranadb/push/exec"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSendWatermarksRequiresRowForEachMessage(t *testing.T) {
+	colTypes := []common.ColumnType{common.BigIntColumnType, common.NewTimestampColumnType(6)}
+	s := &Source{sourceInfo: &common.SourceInfo{TopicInfo: &common.TopicInfo{
+		Watermark: &common.WatermarkInfo{TimeCol: 1},
+	}}}
+	rows := common.NewRows(colTypes, 1)
+	rows.AppendInt64ToColumn(0, 1)
+	rows.AppendTimestampToColumn(1, common.NewTimestampFromGoTime(time.Now()))
+	messages := []*kafka.Message{
+		{PartInfo: kafka.PartInfo{PartitionID: 0, Offset: 10}},
+		{PartInfo: kafka.PartInfo{PartitionID: 1, Offset: 20}},
+	}
+	eventTimes := make(map[int32]time.Time)
+	err := s.sendWatermarks(messages, rows, eventTimes, 2)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "1 rows parsed from 2 messages")
+	// No partition has been advanced by a row from another partition's message
+	require.Empty(t, eventTimes)
+}
+
+func TestWatermarkHeldBackByPartitionWithoutMessages(t *testing.T) {
+	store := fake.NewFakeCluster(0, 1)
+	tableInfo := &common.TableInfo{
+		ID:          common.UserTableIDBase,
+		Name:        "payments",
+		ColumnNames: []string{"payment_id", "ts"},
+		ColumnTypes: []common.ColumnType{common.BigIntColumnType, common.NewTimestampColumnType(6)},
+		AppendOnly:  true,
+	}
+	s := &Source{
+		sourceInfo: &common.SourceInfo{TableInfo: tableInfo, TopicInfo: &common.TopicInfo{
+			Watermark: &common.WatermarkInfo{TableID: common.UserTableIDBase + 1, TimeCol: 1},
+		}},
+		cluster:       store,
+		tableExecutor: exec.NewSourceTableExecutor(tableInfo, store),
+	}
+	receiver := s.WatermarkReceiver()
+	receive := func(partID int64, watermark time.Time) time.Time {
+		rows := watermarkRowsFactory.NewRows(1)
+		rows.AppendInt64ToColumn(0, partID)
+		rows.AppendTimestampToColumn(1, common.NewTimestampFromGoTime(watermark))
+		rows.AppendInt64ToColumn(2, 2)
+		ctx := exec.NewExecutionContext(cluster.NewWriteBatch(store.GetAllShardIDs()[0]), false)
+		require.NoError(t, receiver.HandleRemoteRows(exec.NewCurrentRowsBatch(rows), ctx))
+		require.NoError(t, store.WriteBatch(ctx.WriteBatch))
+		return ctx.Watermark
+	}
+
+	start := time.Date(2021, 6, 1, 10, 0, 0, 0, time.UTC)
+	// Partition 1 hasn't had any messages, so the source has no watermark
+	require.True(t, receive(0, start.Add(5*time.Minute)).IsZero())
+	require.True(t, receive(0, start.Add(10*time.Minute)).IsZero())
+	// Partition 1 starts producing behind partition 0
+	require.Equal(t, start.Add(time.Minute), receive(1, start.Add(time.Minute)))
+	require.Equal(t, start.Add(10*time.Minute), receive(1, start.Add(20*time.Minute)))
+}
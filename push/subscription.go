@@ -0,0 +1,310 @@
+package push
+
+import (
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/squareup/pranadb/common"
+	"github.com/squareup/pranadb/errors"
+	"github.com/squareup/pranadb/protos/squareup/cash/pranadb/v1/notifications"
+	"github.com/twinj/uuid"
+)
+
+// The maximum number of messages from the change feeds which are buffered for a subscription before the change feeds
+// have to wait
+const subscriptionMaxBufferedMessages = 100
+
+// Subscription receives the changes to a materialized view for a client connected to this node. When it starts, each
+// node sends the rows of a snapshot of the materialized view on its shards, followed by the changes committed after the
+// snapshot. Alternatively, a subscription can resume from the positions in a resume token, which each node still has
+// the changes after, in which case there is no snapshot.
+type Subscription struct {
+	ID               string
+	MVInfo           *common.MaterializedViewInfo
+	p                *Engine
+	rowsFactory      *common.RowsFactory
+	numShards        int
+	messagesCh       chan *notifications.ChangeFeedEvents
+	closeCh          chan struct{}
+	closeOnce        sync.Once
+	errLock          sync.Mutex
+	err              error
+	positions        map[uint64]*notifications.ChangeFeedPosition
+	snapshotComplete bool
+	heldMessages     []*notifications.ChangeFeedEvents
+	pendingEvents    []*SubscriptionEvent
+}
+
+// SubscriptionEvent is a row of the snapshot, the end of the snapshot, or a change to a row. For a change,
+// PreviousRow is nil for an insert and CurrentRow is nil for a delete.
+type SubscriptionEvent struct {
+	Snapshot         bool
+	SnapshotComplete bool
+	PreviousRow      *common.Row
+	CurrentRow       *common.Row
+	// ResumeToken can be used to resume the subscription after this event. It is nil until the snapshot is complete.
+	ResumeToken []byte
+}
+
+// Subscribe creates a subscription to the changes to a materialized view. If resumeToken is not nil the subscription
+// carries on after the event it was received with.
+func (p *Engine) Subscribe(schemaName string, mvName string, resumeToken []byte) (*Subscription, error) {
+	mvInfo, ok := p.meta.GetMaterializedView(schemaName, mvName)
+	if !ok {
+		return nil, errors.NewUnknownMaterializedViewError(schemaName, mvName)
+	}
+	shardIDs := p.cluster.GetAllShardIDs()
+	sub := &Subscription{
+		ID:          uuid.NewV4().String(),
+		MVInfo:      mvInfo,
+		p:           p,
+		rowsFactory: common.NewRowsFactory(mvInfo.TableInfo.ColumnTypes),
+		numShards:   len(shardIDs),
+		messagesCh:  make(chan *notifications.ChangeFeedEvents, subscriptionMaxBufferedMessages),
+		closeCh:     make(chan struct{}),
+		positions:   make(map[uint64]*notifications.ChangeFeedPosition, len(shardIDs)),
+	}
+	var resumePositions []*notifications.ChangeFeedPosition
+	if len(resumeToken) > 0 {
+		var err error
+		resumePositions, err = decodeResumeToken(resumeToken, mvInfo.TableInfo.ID, shardIDs)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		for _, pos := range resumePositions {
+			sub.positions[pos.ShardId] = pos
+		}
+		sub.snapshotComplete = true
+	}
+	// The subscription must be registered before the nodes start sending to it
+	p.subscriptions.Store(sub.ID, sub)
+	err := p.notifClient.BroadcastSync(&notifications.ChangeFeedSubscribe{
+		SubscriptionId:  sub.ID,
+		NodeId:          int64(p.cluster.GetNodeID()),
+		SchemaName:      schemaName,
+		MvName:          mvName,
+		ResumePositions: resumePositions,
+	})
+	if err != nil {
+		// Some of the nodes might have started sending
+		sub.Close()
+		var perr errors.PranaError
+		if errors.As(err, &perr) {
+			return nil, perr
+		}
+		if len(resumeToken) > 0 {
+			// Errors from other nodes only carry the message, so we recognise the ones where the node couldn't resume
+			// from its positions
+			for _, resumeErr := range []errors.PranaError{errors.NewResumeTokenExpiredError(), errors.NewInvalidResumeTokenError()} {
+				if err.Error() == resumeErr.Error() {
+					return nil, resumeErr
+				}
+			}
+		}
+		return nil, errors.WithStack(err)
+	}
+	return sub, nil
+}
+
+// Next returns the next event, waiting until there is one. It returns an error if the subscription fails or is closed.
+func (s *Subscription) Next() (*SubscriptionEvent, error) {
+	for len(s.pendingEvents) == 0 {
+		var msg *notifications.ChangeFeedEvents
+		select {
+		case msg = <-s.messagesCh:
+		case <-s.closeCh:
+			return nil, s.getError()
+		}
+		if err := s.handleMessage(msg); err != nil {
+			s.closeWithError(err, true)
+			return nil, err
+		}
+	}
+	event := s.pendingEvents[0]
+	s.pendingEvents[0] = nil
+	s.pendingEvents = s.pendingEvents[1:]
+	return event, nil
+}
+
+// Close closes the subscription and tells the nodes to stop sending to it
+func (s *Subscription) Close() {
+	s.closeWithError(errors.Error("subscription is closed"), true)
+}
+
+func (s *Subscription) closeWithError(err error, unsubscribe bool) {
+	s.closeOnce.Do(func() {
+		s.errLock.Lock()
+		s.err = err
+		s.errLock.Unlock()
+		close(s.closeCh)
+		s.p.subscriptions.Delete(s.ID)
+		if unsubscribe {
+			if err := s.p.notifClient.BroadcastOneway(&notifications.ChangeFeedUnsubscribe{SubscriptionId: s.ID}); err != nil {
+				log.Warnf("failed to unsubscribe %s %v", s.ID, err)
+			}
+		}
+	})
+}
+
+func (s *Subscription) getError() error {
+	s.errLock.Lock()
+	defer s.errLock.Unlock()
+	return s.err
+}
+
+func (s *Subscription) handleMessage(msg *notifications.ChangeFeedEvents) error {
+	if msg.Error != "" {
+		return errors.NewSubscriptionFailedError(msg.Error)
+	}
+	if len(msg.SnapshotRows) > 0 {
+		rows := s.rowsFactory.NewRows(len(msg.SnapshotRows))
+		for _, buff := range msg.SnapshotRows {
+			if err := common.DecodeRow(buff, s.MVInfo.TableInfo.ColumnTypes, rows); err != nil {
+				return errors.WithStack(err)
+			}
+		}
+		for i := 0; i < rows.RowCount(); i++ {
+			row := rows.GetRow(i)
+			s.pendingEvents = append(s.pendingEvents, &SubscriptionEvent{Snapshot: true, CurrentRow: &row})
+		}
+	}
+	if msg.SnapshotComplete {
+		s.positions[msg.ShardId] = &notifications.ChangeFeedPosition{
+			ShardId:  msg.ShardId,
+			Epoch:    msg.Epoch,
+			Sequence: msg.SnapshotSequence,
+		}
+		if len(s.positions) == s.numShards {
+			s.snapshotComplete = true
+			s.pendingEvents = append(s.pendingEvents, &SubscriptionEvent{
+				SnapshotComplete: true,
+				ResumeToken:      s.encodeResumeToken(),
+			})
+			// Now we can send the changes that arrived while the snapshot was being sent
+			held := s.heldMessages
+			s.heldMessages = nil
+			for _, heldMsg := range held {
+				if err := s.handleChanges(heldMsg); err != nil {
+					return errors.WithStack(err)
+				}
+			}
+		}
+	}
+	if len(msg.Changes) > 0 {
+		if !s.snapshotComplete {
+			s.heldMessages = append(s.heldMessages, msg)
+			return nil
+		}
+		return s.handleChanges(msg)
+	}
+	return nil
+}
+
+func (s *Subscription) handleChanges(msg *notifications.ChangeFeedEvents) error {
+	pos, ok := s.positions[msg.ShardId]
+	if !ok || pos.Epoch != msg.Epoch {
+		return errors.Errorf("unexpected changes for shard %d", msg.ShardId)
+	}
+	colTypes := s.MVInfo.TableInfo.ColumnTypes
+	for _, change := range msg.Changes {
+		if change.Sequence < pos.Sequence {
+			// Already sent
+			continue
+		}
+		event := &SubscriptionEvent{}
+		rows := s.rowsFactory.NewRows(2)
+		if change.PreviousRow != nil {
+			if err := common.DecodeRow(change.PreviousRow, colTypes, rows); err != nil {
+				return errors.WithStack(err)
+			}
+			row := rows.GetRow(rows.RowCount() - 1)
+			event.PreviousRow = &row
+		}
+		if change.CurrentRow != nil {
+			if err := common.DecodeRow(change.CurrentRow, colTypes, rows); err != nil {
+				return errors.WithStack(err)
+			}
+			row := rows.GetRow(rows.RowCount() - 1)
+			event.CurrentRow = &row
+		}
+		pos.Sequence = change.Sequence + 1
+		event.ResumeToken = s.encodeResumeToken()
+		s.pendingEvents = append(s.pendingEvents, event)
+	}
+	return nil
+}
+
+// handleChangeFeedEvents passes a message sent by a change feed to the subscription it is for
+func (p *Engine) handleChangeFeedEvents(msg *notifications.ChangeFeedEvents) error {
+	s, ok := p.subscriptions.Load(msg.SubscriptionId)
+	if !ok {
+		return errors.Errorf("no such subscription %s", msg.SubscriptionId)
+	}
+	sub, ok := s.(*Subscription)
+	if !ok {
+		panic("not a *Subscription")
+	}
+	select {
+	case sub.messagesCh <- msg:
+		return nil
+	case <-sub.closeCh:
+		return errors.Errorf("subscription %s is closed", msg.SubscriptionId)
+	}
+}
+
+// closeSubscriptions closes the subscriptions on this node to a materialized view, or all of them if mvID is -1
+func (p *Engine) closeSubscriptions(mvID int64, err error) {
+	p.subscriptions.Range(func(key, value interface{}) bool {
+		sub, ok := value.(*Subscription)
+		if !ok {
+			panic("not a *Subscription")
+		}
+		if mvID == -1 || sub.MVInfo.TableInfo.ID == uint64(mvID) {
+			sub.closeWithError(err, false)
+		}
+		return true
+	})
+}
+
+// The resume token contains the ID of the materialized view followed by the epoch and sequence of the next change for
+// each shard
+func (s *Subscription) encodeResumeToken() []byte {
+	buff := make([]byte, 0, 12+24*len(s.positions))
+	buff = common.AppendUint64ToBufferLE(buff, s.MVInfo.TableInfo.ID)
+	buff = common.AppendUint32ToBufferLE(buff, uint32(len(s.positions)))
+	for _, shardID := range s.p.cluster.GetAllShardIDs() {
+		pos := s.positions[shardID]
+		buff = common.AppendUint64ToBufferLE(buff, pos.ShardId)
+		buff = common.AppendUint64ToBufferLE(buff, pos.Epoch)
+		buff = common.AppendUint64ToBufferLE(buff, pos.Sequence)
+	}
+	return buff
+}
+
+func decodeResumeToken(buff []byte, mvID uint64, shardIDs []uint64) ([]*notifications.ChangeFeedPosition, error) {
+	if len(buff) < 12 {
+		return nil, errors.NewInvalidResumeTokenError()
+	}
+	tokenMVID, offset := common.ReadUint64FromBufferLE(buff, 0)
+	numPositions, offset := common.ReadUint32FromBufferLE(buff, offset)
+	if tokenMVID != mvID || int(numPositions) != len(shardIDs) || len(buff) != offset+24*int(numPositions) {
+		return nil, errors.NewInvalidResumeTokenError()
+	}
+	validShards := make(map[uint64]struct{}, len(shardIDs))
+	for _, shardID := range shardIDs {
+		validShards[shardID] = struct{}{}
+	}
+	positions := make([]*notifications.ChangeFeedPosition, numPositions)
+	for i := range positions {
+		pos := &notifications.ChangeFeedPosition{}
+		pos.ShardId, offset = common.ReadUint64FromBufferLE(buff, offset)
+		pos.Epoch, offset = common.ReadUint64FromBufferLE(buff, offset)
+		pos.Sequence, offset = common.ReadUint64FromBufferLE(buff, offset)
+		if _, ok := validShards[pos.ShardId]; !ok {
+			return nil, errors.NewInvalidResumeTokenError()
+		}
+		delete(validShards, pos.ShardId)
+		positions[i] = pos
+	}
+	return positions, nil
+}
@@ -0,0 +1,163 @@
+package push
+
+import (
+	"github.com/squareup/pranadb/cluster"
+	"github.com/squareup/pranadb/common"
+	"github.com/squareup/pranadb/errors"
+	"github.com/squareup/pranadb/push/exec"
+	"github.com/squareup/pranadb/push/util"
+	"github.com/squareup/pranadb/sharder"
+)
+
+// UserTable is a table whose rows are written by insert, update and delete statements. Inserted rows are forwarded to
+// the shards which own them, where its table executor stores them and passes the changes on to any consuming
+// materialized views, just like the rows of a source. Updates and deletes are made by the nodes which process the
+// shards, see ChangeRows.
+type UserTable struct {
+	pe            *Engine
+	info          *common.UserTableInfo
+	tableExecutor *exec.TableExecutor
+	sharder       *sharder.Sharder
+	cluster       cluster.Cluster
+}
+
+// CreateUserTable creates a user table in the push engine so it can receive forwarded rows
+func (p *Engine) CreateUserTable(tableInfo *common.UserTableInfo) (*UserTable, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if _, ok := p.userTables[tableInfo.ID]; ok {
+		return nil, errors.Errorf("table with id %d already exists", tableInfo.ID)
+	}
+	ut := &UserTable{
+		pe:            p,
+		info:          tableInfo,
+		tableExecutor: exec.NewTableExecutor(tableInfo.TableInfo, p.cluster),
+		sharder:       p.sharder,
+		cluster:       p.cluster,
+	}
+	colTypes := tableInfo.ColumnTypes
+	p.remoteConsumers.Store(tableInfo.ID, &RemoteConsumer{
+		RowsFactory: common.NewRowsFactory(colTypes),
+		ColTypes:    colTypes,
+		RowsHandler: ut.tableExecutor,
+	})
+	p.userTables[tableInfo.ID] = ut
+	return ut, nil
+}
+
+func (p *Engine) GetUserTable(tableID uint64) (*UserTable, error) {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+	ut, ok := p.userTables[tableID]
+	if !ok {
+		return nil, errors.Errorf("no such table %d", tableID)
+	}
+	return ut, nil
+}
+
+func (p *Engine) RemoveUserTable(tableInfo *common.UserTableInfo) (*UserTable, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	ut, ok := p.userTables[tableInfo.ID]
+	if !ok {
+		return nil, errors.Errorf("no such table %d", tableInfo.ID)
+	}
+	delete(p.userTables, tableInfo.ID)
+	p.remoteConsumers.Delete(tableInfo.ID)
+	return ut, nil
+}
+
+// Upsert writes the rows to the table, replacing any existing rows with the same primary keys
+func (u *UserTable) Upsert(rows *common.Rows) error {
+	info := u.info.TableInfo
+	forwardBatches := make(map[uint64]*cluster.WriteBatch)
+	for i := 0; i < rows.RowCount(); i++ {
+		row := rows.GetRow(i)
+		key, err := common.EncodeKeyCols(&row, info.PrimaryKeyCols, info.ColumnTypes, make([]byte, 0, 8))
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		destShardID, err := u.sharder.CalculateShard(sharder.ShardTypeHash, key)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		forwardBatch, ok := forwardBatches[destShardID]
+		if !ok {
+			forwardBatch = cluster.NewWriteBatch(destShardID)
+			forwardBatches[destShardID] = forwardBatch
+		}
+		encodedRow, err := common.EncodeRow(&row, info.ColumnTypes, make([]byte, 0, 32))
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		forwardBatch.AddPut(util.EncodeKeyForForwardWrite(info.ID), util.EncodePrevAndCurrentRow(nil, encodedRow))
+	}
+	return util.SendForwardBatches(forwardBatches, u.cluster)
+}
+
+// ChangeRows changes the rows of the table on the shards this node processes, for an update or delete statement.
+// changes returns the new rows, or the deleted rows, of a shard. It's called on the scheduler of the shard, so nothing
+// else writes to the shard between it reading the rows the statement matches and its changes being written, and the
+// statement can't overwrite a concurrent write. Any rows forwarded to the shard which haven't been handled yet, such as
+// those of an insert which has returned, are handled first, so the statement sees them. It returns the ids of the shards
+// it changed, so the caller can check that every shard has been changed once, even if shards have moved between nodes.
+func (u *UserTable) ChangeRows(changes func(shardID uint64) (exec.RowsBatch, error)) ([]uint64, error) {
+	schedulers, err := u.pe.GetLocalLeaderSchedulers()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	shardIDs := make([]uint64, 0, len(schedulers))
+	chs := make([]chan error, 0, len(schedulers))
+	for shardID, scheduler := range schedulers {
+		shardID := shardID
+		shardIDs = append(shardIDs, shardID)
+		chs = append(chs, scheduler.ScheduleAction(func() error {
+			if err := u.pe.HandleReceivedRows(shardID); err != nil {
+				return errors.WithStack(err)
+			}
+			rowsBatch, err := changes(shardID)
+			if err != nil {
+				return errors.WithStack(err)
+			}
+			// Statements are not retried, so duplicate detection is disabled
+			ctx := exec.NewExecutionContext(cluster.NewWriteBatch(shardID), false)
+			if err := u.tableExecutor.HandleRows(rowsBatch, ctx); err != nil {
+				return errors.WithStack(err)
+			}
+			if err := util.SendForwardBatches(ctx.RemoteBatches, u.cluster); err != nil {
+				return errors.WithStack(err)
+			}
+			return errors.WithStack(u.cluster.WriteBatch(ctx.WriteBatch))
+		}))
+	}
+	for _, ch := range chs {
+		if err := <-ch; err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+	return shardIDs, nil
+}
+
+// Drop deletes all the data in the table
+func (u *UserTable) Drop() error {
+	tableStartPrefix := common.AppendUint64ToBufferBE(nil, u.info.ID)
+	tableEndPrefix := common.AppendUint64ToBufferBE(nil, u.info.ID+1)
+	return u.cluster.DeleteAllDataInRangeForAllShardsLocally(tableStartPrefix, tableEndPrefix)
+}
+
+func (u *UserTable) AddConsumingExecutor(mvName string, executor exec.PushExecutor) {
+	u.tableExecutor.AddConsumingNode(mvName, executor)
+}
+
+func (u *UserTable) RemoveConsumingExecutor(mvName string) {
+	u.tableExecutor.RemoveConsumingNode(mvName)
+}
+
+func (u *UserTable) GetConsumingMVs() []string {
+	return u.tableExecutor.GetConsumingMvNames()
+}
+
+func (u *UserTable) TableExecutor() *exec.TableExecutor {
+	return u.tableExecutor
+}
@@ -59,6 +59,18 @@ func EncodeKeyForForwardAggregation(enableDupDetection bool, partialAggTableID u
 	return buff
 }
 
+// EncodeKeyForForwardWrite encodes the key for forwarding a row written to a user table by a statement. Statements are
+// not retried, so duplicate detection is disabled.
+func EncodeKeyForForwardWrite(tableID uint64) []byte {
+	buff := make([]byte, 0, 33)
+	buff = append(buff, 0)
+	// The dedup key isn't used but must be present
+	buff = append(buff, make([]byte, 24)...)
+	// And remote consumer id goes on the end
+	buff = common.AppendUint64ToBufferBE(buff, tableID)
+	return buff
+}
+
 func EncodePrevAndCurrentRow(prevValueBuff []byte, currValueBuff []byte) []byte {
 	lpvb := len(prevValueBuff)
 	lcvb := len(currValueBuff)
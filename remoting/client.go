@@ -16,6 +16,7 @@ type Client interface {
 	SendRequest(message ClusterMessage, timeout time.Duration) (ClusterMessage, error)
 	BroadcastOneway(notif ClusterMessage) error
 	BroadcastSync(notif ClusterMessage) error
+	BroadcastSyncWithResponses(notif ClusterMessage) ([]ClusterMessage, error)
 	Start() error
 	Stop() error
 	AvailabilityListener() AvailabilityListener
@@ -155,23 +156,35 @@ func (c *client) doSendRequest(messageBytes []byte, ri *responseInfo) bool {
 
 // BroadcastSync broadcasts a notification to all nodes and waits until all nodes have responded before returning
 func (c *client) BroadcastSync(notificationMessage ClusterMessage) error {
+	_, err := c.BroadcastSyncWithResponses(notificationMessage)
+	return err
+}
+
+// BroadcastSyncWithResponses is like BroadcastSync but also returns the response messages of the nodes. Nodes which
+// are unavailable are skipped, and nodes which respond without a message don't add one.
+func (c *client) BroadcastSyncWithResponses(notificationMessage ClusterMessage) ([]ClusterMessage, error) {
 	nf := c.createRequest(notificationMessage, true)
 	messageBytes, err := nf.serialize(nil)
 	if err != nil {
-		return errors.WithStack(err)
+		return nil, errors.WithStack(err)
 	}
 	respChan := make(chan error, 10000)
 	ri := &responseInfo{broadcastRespChan: respChan, conns: make(map[*clientConnection]struct{})}
 	c.responseChannels.Store(nf.sequence, ri)
 	if err := c.broadcast(messageBytes, ri); err != nil {
-		return errors.WithStack(err)
+		return nil, errors.WithStack(err)
 	}
 	err, k := <-respChan
 	if !k {
-		return errors.Error("channel was closed")
+		return nil, errors.Error("channel was closed")
 	}
 	c.responseChannels.Delete(nf.sequence)
-	return err
+	if err != nil {
+		return nil, err
+	}
+	ri.lock.Lock()
+	defer ri.lock.Unlock()
+	return ri.broadcastResps, nil
 }
 
 // BroadcastOneway broadcasts a notification to all members of the cluster, and does not wait for responses
@@ -356,6 +369,7 @@ type responseInfo struct {
 	lock              sync.Mutex
 	rpcRespChan       chan *ClusterResponse
 	broadcastRespChan chan error
+	broadcastResps    []ClusterMessage
 	conns             map[*clientConnection]struct{}
 	connCount         int32
 	rpc               bool
@@ -369,6 +383,12 @@ func (r *responseInfo) responseReceived(conn *clientConnection, resp *ClusterRes
 			// The server received the cluster message but sent back an error response
 			r.broadcastRespChan <- errors.Error(resp.errMsg)
 		} else {
+			if resp.responseMessage != nil {
+				// The response is added before the count is decremented, so it's there when the broadcast completes
+				r.lock.Lock()
+				r.broadcastResps = append(r.broadcastResps, resp.responseMessage)
+				r.lock.Unlock()
+			}
 			r.addToConnCount(-1)
 		}
 	}
@@ -20,6 +20,12 @@ const (
 	ClusterMessageClusterProposeResponse
 	ClusterMessageClusterReadResponse
 	ClusterMessageNotificationTestMessage
+	ClusterMessageChangeFeedSubscribe
+	ClusterMessageChangeFeedUnsubscribe
+	ClusterMessageChangeFeedEvents
+	ClusterMessageReloadAvroSchemas
+	ClusterMessageDMLStatement
+	ClusterMessageDMLStatementResponse
 )
 
 func TypeForClusterMessage(notification ClusterMessage) ClusterMessageType {
@@ -38,6 +44,18 @@ func TypeForClusterMessage(notification ClusterMessage) ClusterMessageType {
 		return ClusterMessageClusterReadResponse
 	case *notifications.NotificationTestMessage:
 		return ClusterMessageNotificationTestMessage
+	case *notifications.ChangeFeedSubscribe:
+		return ClusterMessageChangeFeedSubscribe
+	case *notifications.ChangeFeedUnsubscribe:
+		return ClusterMessageChangeFeedUnsubscribe
+	case *notifications.ChangeFeedEvents:
+		return ClusterMessageChangeFeedEvents
+	case *notifications.ReloadAvroSchemas:
+		return ClusterMessageReloadAvroSchemas
+	case *notifications.DMLStatementInfo:
+		return ClusterMessageDMLStatement
+	case *notifications.DMLStatementResponse:
+		return ClusterMessageDMLStatementResponse
 	default:
 		return ClusterMessageTypeUnknown
 	}
@@ -75,6 +93,18 @@ func DeserializeClusterMessage(data []byte) (ClusterMessage, error) {
 		msg = &notifications.ReloadProtobuf{}
 	case ClusterMessageNotificationTestMessage:
 		msg = &notifications.NotificationTestMessage{}
+	case ClusterMessageChangeFeedSubscribe:
+		msg = &notifications.ChangeFeedSubscribe{}
+	case ClusterMessageChangeFeedUnsubscribe:
+		msg = &notifications.ChangeFeedUnsubscribe{}
+	case ClusterMessageChangeFeedEvents:
+		msg = &notifications.ChangeFeedEvents{}
+	case ClusterMessageReloadAvroSchemas:
+		msg = &notifications.ReloadAvroSchemas{}
+	case ClusterMessageDMLStatement:
+		msg = &notifications.DMLStatementInfo{}
+	case ClusterMessageDMLStatementResponse:
+		msg = &notifications.DMLStatementResponse{}
 	default:
 		return nil, errors.Errorf("invalid notification type %d", nt)
 	}
@@ -49,6 +49,20 @@ func (f *FakeServer) BroadcastSync(notif ClusterMessage) error {
 	return f.BroadcastOneway(notif)
 }
 
+func (f *FakeServer) BroadcastSyncWithResponses(notif ClusterMessage) ([]ClusterMessage, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	listener, ok := f.messageHandlers[TypeForClusterMessage(notif)]
+	if !ok {
+		panic("no notification listener")
+	}
+	resp, err := listener.HandleMessage(notif)
+	if err != nil || resp == nil {
+		return nil, err
+	}
+	return []ClusterMessage{resp}, nil
+}
+
 func (f *FakeServer) ConnectionCount() int {
 	return 0
 }
@@ -357,6 +357,43 @@ func TestSyncBroadcastWithFailingNotif(t *testing.T) {
 	require.Equal(t, "some other error", err.Error())
 }
 
+func TestSyncBroadcastWithResponses(t *testing.T) {
+	numServers := 3
+
+	servers, listeners := startServers(t, numServers)
+	defer stopServers(t, servers...)
+	var listenAddresses []string
+	for _, server := range servers {
+		listenAddresses = append(listenAddresses, server.ListenAddress())
+	}
+
+	client := newClient(listenAddresses...)
+	err := client.Start()
+	require.NoError(t, err)
+	defer stopClient(t, client)
+
+	// A server which responds without a message doesn't add a response
+	for i := 0; i < numServers-1; i++ {
+		listeners[i].SetReturnVal(&notifications.DMLStatementResponse{ShardIds: []uint64{uint64(i)}})
+	}
+
+	resps, err := client.BroadcastSyncWithResponses(&notifications.NotificationTestMessage{SessionId: "requestMessage"})
+	require.NoError(t, err)
+	require.Equal(t, numServers-1, len(resps))
+	var shardIDs []uint64
+	for _, resp := range resps {
+		dmlResp, ok := resp.(*notifications.DMLStatementResponse)
+		require.True(t, ok)
+		shardIDs = append(shardIDs, dmlResp.ShardIds...)
+	}
+	require.ElementsMatch(t, []uint64{0, 1}, shardIDs)
+
+	listeners[1].SetReturnErrMsg("some error")
+	_, err = client.BroadcastSyncWithResponses(&notifications.NotificationTestMessage{SessionId: "requestMessage"})
+	require.Error(t, err)
+	require.Equal(t, "some error", err.Error())
+}
+
 func TestSendRequest(t *testing.T) {
 	t.Helper()
 
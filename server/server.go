@@ -1,6 +1,7 @@
 package server
 
 import (
+	"github.com/squareup/pranadb/avrolib"
 	"github.com/squareup/pranadb/cluster/fake"
 	"github.com/squareup/pranadb/failinject"
 	"github.com/squareup/pranadb/remoting"
@@ -60,10 +61,12 @@ func NewServer(config conf.Config) (*Server, error) {
 	}
 	metaController := meta.NewController(clus)
 	shardr := sharder.NewSharder(clus)
-	pullEngine := pull.NewPullEngine(clus, metaController, shardr)
+	pullEngine := pull.NewPullEngine(clus, metaController, shardr, &config)
 	clus.SetRemoteQueryExecutionCallback(pullEngine)
 	protoRegistry := protolib.NewProtoRegistry(metaController, clus, pullEngine, config.ProtobufDescriptorDir)
 	protoRegistry.SetNotifier(notifClient.BroadcastSync)
+	avroRegistry := avrolib.NewAvroRegistry(clus, pullEngine, config.AvroSchemaRegistryURL)
+	avroRegistry.SetNotifier(notifClient.BroadcastSync)
 	theMetrics := metrics.NewServer(config)
 	var failureInjector failinject.Injector
 	if config.EnableFailureInjector {
@@ -71,14 +74,22 @@ func NewServer(config conf.Config) (*Server, error) {
 	} else {
 		failureInjector = failinject.NewDummyInjector()
 	}
-	pushEngine := push.NewPushEngine(clus, shardr, metaController, &config, pullEngine, protoRegistry, failureInjector)
+	pushEngine := push.NewPushEngine(clus, shardr, metaController, &config, pullEngine, protoRegistry, avroRegistry,
+		failureInjector)
 	clus.RegisterShardListenerFactory(pushEngine)
+	pushEngine.SetNotifClient(notifClient)
 	commandExecutor := command.NewCommandExecutor(metaController, pushEngine, pullEngine, clus, notifClient,
 		protoRegistry, failureInjector)
 	remotingServer.RegisterMessageHandler(remoting.ClusterMessageDDLStatement, commandExecutor)
+	remotingServer.RegisterMessageHandler(remoting.ClusterMessageDMLStatement, commandExecutor)
 	remotingServer.RegisterMessageHandler(remoting.ClusterMessageReloadProtobuf, protoRegistry)
+	remotingServer.RegisterMessageHandler(remoting.ClusterMessageReloadAvroSchemas, avroRegistry)
+	changeFeedHandler := pushEngine.GetChangeFeedMessageHandler()
+	remotingServer.RegisterMessageHandler(remoting.ClusterMessageChangeFeedSubscribe, changeFeedHandler)
+	remotingServer.RegisterMessageHandler(remoting.ClusterMessageChangeFeedUnsubscribe, changeFeedHandler)
+	remotingServer.RegisterMessageHandler(remoting.ClusterMessageChangeFeedEvents, changeFeedHandler)
 	schemaLoader := schema.NewLoader(metaController, pushEngine, pullEngine)
-	apiServer := api.NewAPIServer(metaController, commandExecutor, protoRegistry, config)
+	apiServer := api.NewAPIServer(metaController, commandExecutor, pushEngine, protoRegistry, avroRegistry, config)
 
 	services := []service{
 		lifeCycleMgr,
@@ -90,6 +101,7 @@ func NewServer(config conf.Config) (*Server, error) {
 		pushEngine,
 		pullEngine,
 		protoRegistry,
+		avroRegistry,
 		schemaLoader,
 		theMetrics,
 		apiServer,
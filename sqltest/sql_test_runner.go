@@ -5,6 +5,7 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"math/rand"
 	"net/http"
@@ -16,13 +17,16 @@ import (
 	"sync"
 	"testing"
 	"time"
+	"unicode"
 
 	"github.com/golang/protobuf/proto"
+	"github.com/squareup/pranadb/avrolib"
 	"github.com/squareup/pranadb/client"
 	"github.com/squareup/pranadb/command/parser"
 	"github.com/squareup/pranadb/errors"
 	"github.com/squareup/pranadb/protolib"
 	"github.com/squareup/pranadb/protos/squareup/cash/pranadb/v1/service"
+	"google.golang.org/grpc"
 	"google.golang.org/protobuf/types/descriptorpb"
 
 	log "github.com/sirupsen/logrus"
@@ -45,6 +49,7 @@ const (
 	ExcludedTestPrefix = ""
 	TestClusterID      = 12345678
 	ProtoDescriptorDir = "../protos"
+	AvroSchemaDir      = "testdata/avro"
 )
 
 var (
@@ -70,6 +75,31 @@ type sqlTestsuite struct {
 	dataDir           string
 	lock              sync.Mutex
 	encoders          map[string]encoderFactory
+	schemaRegistry    *avrolib.FakeSchemaRegistry
+	avroSchemas       *avroSchemas
+}
+
+// avroSchemas holds the Avro schemas registered by the tests, with Prana or the schema registry, for the encoders to
+// encode data with
+type avroSchemas struct {
+	lock    sync.Mutex
+	schemas map[int32]*avrolib.Schema
+}
+
+func (a *avroSchemas) add(schema *avrolib.Schema) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	a.schemas[schema.ID] = schema
+}
+
+func (a *avroSchemas) FindSchemaByID(id int32) (*avrolib.Schema, error) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	schema, ok := a.schemas[id]
+	if !ok {
+		return nil, errors.Errorf("avro schema %d not registered", id)
+	}
+	return schema, nil
 }
 
 type encoderFactory func(options string) (kafka.MessageEncoder, error)
@@ -157,6 +187,7 @@ func (w *sqlTestsuite) setupPranaCluster() {
 			"127.0.0.1:63401",
 		}
 		cnf.ProtobufDescriptorDir = ProtoDescriptorDir
+		cnf.AvroSchemaRegistryURL = w.schemaRegistry.URL()
 		s, err := server.NewServer(*cnf)
 		if err != nil {
 			log.Fatal(err)
@@ -186,6 +217,7 @@ func (w *sqlTestsuite) setupPranaCluster() {
 			cnf.EnableAPIServer = true
 			cnf.APIServerListenAddresses = apiServerListenAddresses
 			cnf.ProtobufDescriptorDir = ProtoDescriptorDir
+			cnf.AvroSchemaRegistryURL = w.schemaRegistry.URL()
 			cnf.EnableFailureInjector = true
 			cnf.ScreenDragonLogSpam = true
 			cnf.DisableShardPlacementSanityCheck = true
@@ -206,8 +238,10 @@ func (w *sqlTestsuite) setup(fakeCluster bool, numNodes int, replicationFactor i
 	w.replicationFactor = replicationFactor
 	protoRegistry, err := protolib.NewDirBackedRegistry(ProtoDescriptorDir)
 	require.NoError(w.t, err)
-	w.registerEncoders(protoRegistry)
+	w.avroSchemas = &avroSchemas{schemas: map[int32]*avrolib.Schema{}}
+	w.registerEncoders(protoRegistry, w.avroSchemas)
 	w.fakeKafka = kafka.NewFakeKafka()
+	w.schemaRegistry = avrolib.NewFakeSchemaRegistry()
 
 	dataDir, err := ioutil.TempDir("", "sql-test")
 	if err != nil {
@@ -258,8 +292,9 @@ func (w *sqlTestsuite) setup(fakeCluster bool, numNodes int, replicationFactor i
 	}
 }
 
-func (w *sqlTestsuite) registerEncoders(registry protolib.Resolver) {
+func (w *sqlTestsuite) registerEncoders(registry protolib.Resolver, avroRegistry avrolib.Resolver) {
 	w.registerEncoder(&kafka.JSONKeyJSONValueEncoder{})
+	w.registerEncoder(&kafka.JSONKeyTombstoneEncoder{})
 	w.registerEncoder(&kafka.StringKeyTLJSONValueEncoder{})
 	w.registerEncoder(&kafka.Int64BEKeyTLJSONValueEncoder{})
 	w.registerEncoder(&kafka.Int32BEKeyTLJSONValueEncoder{})
@@ -268,7 +303,9 @@ func (w *sqlTestsuite) registerEncoders(registry protolib.Resolver) {
 	w.registerEncoder(&kafka.Float32BEKeyTLJSONValueEncoder{})
 	w.registerEncoder(&kafka.NestedJSONKeyNestedJSONValueEncoder{})
 	w.registerEncoder(&kafka.JSONHeadersEncoder{})
+	w.registerEncoder(&kafka.StringKeyCSVValueEncoder{})
 	w.registerEncoderFactory(kafka.NewStringKeyProtobufValueEncoderFactory(registry), &kafka.StringKeyProtobufValueEncoder{})
+	w.registerEncoderFactory(kafka.NewStringKeyAvroValueEncoderFactory(avroRegistry), &kafka.StringKeyAvroValueEncoder{})
 }
 
 func (w *sqlTestsuite) registerEncoder(encoder kafka.MessageEncoder) {
@@ -319,6 +356,7 @@ func (w *sqlTestsuite) startCluster() {
 
 func (w *sqlTestsuite) teardown() {
 	w.stopCluster()
+	w.schemaRegistry.Close()
 	if w.dataDir != "" {
 		err := os.RemoveAll(w.dataDir)
 		if err != nil {
@@ -340,6 +378,9 @@ type sqlTest struct {
 	cli           *client.Client
 	clientNodeID  int
 	currentSchema string
+	subscriptions map[string]*testSubscription
+	// The ids of the statements prepared with --prepare, by name
+	preparedStatements map[string]int64
 }
 
 func (st *sqlTest) run() {
@@ -385,6 +426,8 @@ func (st *sqlTest) runTestIteration(require *require.Assertions, commands []stri
 	st.prana = st.choosePrana()
 	st.output = &strings.Builder{}
 	st.cli = st.createCli(require)
+	st.subscriptions = make(map[string]*testSubscription)
+	st.preparedStatements = make(map[string]int64)
 	numIters := 1
 	for i, command := range commands {
 		st.output.WriteString(command + ";\n")
@@ -407,8 +450,24 @@ func (st *sqlTest) runTestIteration(require *require.Assertions, commands []stri
 			numIters = int(n)
 		} else if strings.HasPrefix(command, "--create topic") {
 			st.executeCreateTopic(require, command)
+		} else if strings.HasPrefix(command, "--consume topic") {
+			st.executeConsumeTopic(require, command)
 		} else if strings.HasPrefix(command, "--delete topic") {
 			st.executeDeleteTopic(require, command)
+		} else if strings.HasPrefix(command, "--subscribe") {
+			st.executeSubscribe(require, command)
+		} else if strings.HasPrefix(command, "--resubscribe") {
+			st.executeResubscribe(require, command)
+		} else if strings.HasPrefix(command, "--unsubscribe") {
+			st.executeUnsubscribe(require, command)
+		} else if strings.HasPrefix(command, "--prepare") {
+			st.executePrepare(require, command)
+		} else if strings.HasPrefix(command, "--execute") {
+			st.executePrepared(require, command)
+		} else if strings.HasPrefix(command, "--close prepared") {
+			st.executeClosePrepared(require, command)
+		} else if strings.HasPrefix(command, "--check subscription") {
+			st.executeCheckSubscription(require, command)
 		} else if strings.HasPrefix(command, "--restart cluster") {
 			st.executeRestartCluster(require)
 		} else if strings.HasPrefix(command, "--kafka fail") {
@@ -417,6 +476,8 @@ func (st *sqlTest) runTestIteration(require *require.Assertions, commands []stri
 			st.executeWaitForRows(require, command)
 		} else if strings.HasPrefix(command, "--wait for schedulers") {
 			st.waitForSchedulers(require)
+		} else if strings.HasPrefix(command, "--wait for processing") {
+			st.waitForProcessingToComplete(require)
 		} else if strings.HasPrefix(command, "--wait for committed") {
 			st.executeWaitForCommitted(require, command)
 		} else if strings.HasPrefix(command, "--enable commit offsets") {
@@ -425,6 +486,10 @@ func (st *sqlTest) runTestIteration(require *require.Assertions, commands []stri
 			st.executeDisableCommitOffsets(require, command)
 		} else if strings.HasPrefix(command, "--register protobuf") {
 			st.executeRegisterProtobufCommand(require, command)
+		} else if strings.HasPrefix(command, "--register avro") {
+			st.executeRegisterAvroCommand(require, command)
+		} else if strings.HasPrefix(command, "--register schema registry avro") {
+			st.executeRegisterSchemaRegistryAvroCommand(require, command)
 		} else if strings.HasPrefix(command, "--activate failpoint") {
 			st.executeActivateFailpoint(require, command)
 		} else if strings.HasPrefix(command, "--deactivate failpoint") {
@@ -453,6 +518,9 @@ func (st *sqlTest) runTestIteration(require *require.Assertions, commands []stri
 	fmt.Println("TEST OUTPUT=========================\n" + st.output.String())
 	fmt.Println("END TEST OUTPUT=====================")
 
+	for _, sub := range st.subscriptions {
+		sub.close()
+	}
 	st.closeClient(require)
 
 	for _, topic := range st.topics {
@@ -665,6 +733,10 @@ func (st *sqlTest) loadDataset(require *require.Assertions, fileName string, dsN
 				encoder = defaultEncoder
 			}
 			colTypes := sourceInfo.TableInfo.ColumnTypes
+			if sourceInfo.AppendOnly {
+				// The hidden key columns of an append-only source aren't in the data
+				colTypes = colTypes[:len(colTypes)-2]
+			}
 			if lp >= 4 {
 				colTypes, err = parseColumnTypes(parts[3])
 				require.NoError(err)
@@ -712,6 +784,25 @@ func (st *sqlTest) loadDataset(require *require.Assertions, fileName string, dsN
 					case common.TypeTimestamp:
 						val := common.NewTimestampFromString(part)
 						currDataSet.rows.AppendTimestampToColumn(i, val)
+					case common.TypeBoolean:
+						val, err := strconv.ParseBool(part)
+						require.NoError(err)
+						var ival int64
+						if val {
+							ival = 1
+						}
+						currDataSet.rows.AppendInt64ToColumn(i, ival)
+					case common.TypeDate:
+						val, err := common.ParseDate(part)
+						require.NoError(err)
+						currDataSet.rows.AppendTimestampToColumn(i, val)
+					case common.TypeVarbinary:
+						currDataSet.rows.AppendBytesToColumn(i, []byte(part))
+					case common.TypeJSON:
+						// The data file is comma separated, so JSON values use ';' in place of ','
+						val, err := common.ParseJSON(strings.ReplaceAll(part, ";", ","))
+						require.NoError(err)
+						currDataSet.rows.AppendJSONToColumn(i, val)
 					default:
 						require.Fail(fmt.Sprintf("unexpected data type %d", colType.Type))
 					}
@@ -793,6 +884,397 @@ func (st *sqlTest) executeDeleteTopic(require *require.Assertions, command strin
 	log.Infof("Deleted topic %s ", topicName)
 }
 
+// executeConsumeTopic waits for a sink to deliver all its messages then outputs the latest value of each key in the
+// topic, in key order. The intermediate values of a key depend on how rows were batched so we don't output them, and
+// keys whose latest message is a tombstone are omitted, as if the topic had been compacted.
+func (st *sqlTest) executeConsumeTopic(require *require.Assertions, command string) {
+	parts := strings.Split(command, " ")
+	require.True(len(parts) == 4, "Invalid consume topic, should be --consume topic topic_name sink_name")
+	topicName := parts[2]
+	sinkName := parts[3]
+	require.NotEmpty(st.currentSchema, "no schema selected")
+	sinkInfo, ok := st.testSuite.pranaCluster[0].GetMetaController().GetSink(st.currentSchema, sinkName)
+	require.True(ok, fmt.Sprintf("no such sink %s", sinkName))
+	st.waitForProcessingToComplete(require)
+	for _, prana := range st.testSuite.pranaCluster {
+		err := prana.GetPushEngine().WaitForSinkDelivery(sinkInfo.ID)
+		require.NoError(err)
+	}
+	topic, ok := st.testSuite.fakeKafka.GetTopic(topicName)
+	require.True(ok, fmt.Sprintf("no such topic %s", topicName))
+	latest := make(map[string][]byte)
+	for _, msg := range topic.GetMessages() {
+		if msg.Value == nil {
+			delete(latest, string(msg.Key))
+		} else {
+			latest[string(msg.Key)] = msg.Value
+		}
+	}
+	keys := make([]string, 0, len(latest))
+	for key := range latest {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		st.output.WriteString(fmt.Sprintf("%s|%s\n", printableKey(key), string(latest[key])))
+	}
+	st.output.WriteString(fmt.Sprintf("%d keys consumed\n", len(keys)))
+}
+
+// printableKey returns the key of a message as it is if it's printable, otherwise in hex, e.g. for int64be keys
+func printableKey(key string) string {
+	for _, r := range key {
+		if !unicode.IsPrint(r) {
+			return fmt.Sprintf("%x", key)
+		}
+	}
+	return key
+}
+
+// testSubscription keeps the rows of a materialized view as seen by a subscription, by applying the changes it receives
+type testSubscription struct {
+	name        string
+	mvName      string
+	cli         *client.Client
+	ctx         context.Context
+	cancel      context.CancelFunc
+	done        chan struct{}
+	lock        sync.Mutex
+	rows        map[string]int
+	resumeToken []byte
+	err         error
+}
+
+func (st *sqlTest) executeSubscribe(require *require.Assertions, command string) {
+	parts := strings.Split(command, " ")
+	require.Equal(3, len(parts), "Invalid subscribe, should be --subscribe subscription_name mv_name")
+	sub := &testSubscription{name: parts[1], mvName: parts[2]}
+	if err := st.startSubscription(require, sub); err != nil {
+		st.output.WriteString(fmt.Sprintf("Failed to subscribe: %s\n", subscriptionErrorMessage(err)))
+		return
+	}
+	st.subscriptions[sub.name] = sub
+}
+
+func (st *sqlTest) executeResubscribe(require *require.Assertions, command string) {
+	parts := strings.Split(command, " ")
+	require.Equal(2, len(parts), "Invalid resubscribe, should be --resubscribe subscription_name")
+	sub, ok := st.subscriptions[parts[1]]
+	require.True(ok, fmt.Sprintf("no such subscription %s", parts[1]))
+	// We close the subscription and carry on from the last change it received, probably on a different node
+	sub.close()
+	if err := st.startSubscription(require, sub); err != nil {
+		st.output.WriteString(fmt.Sprintf("Failed to resubscribe: %s\n", subscriptionErrorMessage(err)))
+		delete(st.subscriptions, sub.name)
+	}
+}
+
+func (st *sqlTest) executeUnsubscribe(require *require.Assertions, command string) {
+	parts := strings.Split(command, " ")
+	require.Equal(2, len(parts), "Invalid unsubscribe, should be --unsubscribe subscription_name")
+	sub, ok := st.subscriptions[parts[1]]
+	require.True(ok, fmt.Sprintf("no such subscription %s", parts[1]))
+	sub.close()
+	delete(st.subscriptions, sub.name)
+}
+
+func (st *sqlTest) startSubscription(require *require.Assertions, sub *testSubscription) error {
+	prana := st.choosePrana()
+	apiServerAddress := fmt.Sprintf("127.0.0.1:%d", apiServerListenAddressBase+prana.GetCluster().GetNodeID())
+	sub.cli = client.NewClient(apiServerAddress)
+	err := sub.cli.Start()
+	require.NoError(err)
+	resumed := sub.resumeToken != nil
+	if !resumed {
+		sub.rows = make(map[string]int)
+	}
+	sub.err = nil
+	sub.ctx, sub.cancel = context.WithCancel(context.Background())
+	stream, err := sub.cli.Subscribe(sub.ctx, &service.SubscribeRequest{
+		Schema:           st.currentSchema,
+		MaterializedView: sub.mvName,
+		ResumeToken:      sub.resumeToken,
+	})
+	if err == nil {
+		// The first event has the columns, or the error if the subscription couldn't be created
+		_, err = stream.Recv()
+	}
+	if err != nil {
+		sub.close()
+		return err
+	}
+	sub.done = make(chan struct{})
+	go sub.receive(stream, resumed)
+	return nil
+}
+
+func (s *testSubscription) receive(stream service.PranaDBService_SubscribeClient, resumed bool) {
+	defer close(s.done)
+	for {
+		event, err := stream.Recv()
+		s.lock.Lock()
+		if err != nil {
+			if s.ctx.Err() == nil {
+				s.err = err
+			}
+			s.lock.Unlock()
+			return
+		}
+		if event.ResumeToken != nil {
+			s.resumeToken = event.ResumeToken
+		}
+		if ev, ok := event.Event.(*service.ChangeEvent_Change); ok {
+			change := ev.Change
+			if change.Type == service.ChangeType_CHANGE_TYPE_SNAPSHOT && resumed {
+				s.err = errors.Error("received a snapshot row after resuming")
+			}
+			if change.PreviousRow != nil {
+				row := formatSubscriptionRow(change.PreviousRow)
+				if s.rows[row] == 0 {
+					s.err = errors.Errorf("received a change to row %s which isn't in the subscription", row)
+				} else if s.rows[row]--; s.rows[row] == 0 {
+					delete(s.rows, row)
+				}
+			}
+			if change.Row != nil {
+				s.rows[formatSubscriptionRow(change.Row)]++
+			}
+		}
+		s.lock.Unlock()
+	}
+}
+
+func (s *testSubscription) close() {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	if s.done != nil {
+		<-s.done
+		s.done = nil
+	}
+	if s.cli != nil {
+		if err := s.cli.Stop(); err != nil {
+			log.Warnf("failed to stop client %v", err)
+		}
+		s.cli = nil
+	}
+}
+
+func (s *testSubscription) getState() ([]string, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.err != nil {
+		return nil, s.err
+	}
+	var rows []string
+	for row, count := range s.rows {
+		for i := 0; i < count; i++ {
+			rows = append(rows, row)
+		}
+	}
+	sort.Strings(rows)
+	return rows, nil
+}
+
+func (st *sqlTest) executeCheckSubscription(require *require.Assertions, command string) {
+	parts := strings.Split(command, " ")
+	require.Equal(3, len(parts), "Invalid check subscription, should be --check subscription subscription_name")
+	sub, ok := st.subscriptions[parts[2]]
+	require.True(ok, fmt.Sprintf("no such subscription %s", parts[2]))
+	st.waitForProcessingToComplete(require)
+	// The changes reach the subscription asynchronously, so we wait until it has the same rows as the
+	// materialized view, or it fails
+	var rows, expected []string
+	var err error
+	ok, waitErr := commontest.WaitUntilWithError(func() (bool, error) {
+		rows, err = sub.getState()
+		if err != nil {
+			return true, nil
+		}
+		var queryErr error
+		expected, queryErr = st.queryRows(require, sub.mvName)
+		if queryErr != nil {
+			// The materialized view has gone - the subscription should fail
+			return false, nil //nolint:nilerr
+		}
+		return strings.Join(rows, "\n") == strings.Join(expected, "\n"), nil
+	}, 10*time.Second, 100*time.Millisecond)
+	require.NoError(waitErr)
+	if err != nil {
+		st.output.WriteString(fmt.Sprintf("Subscription %s failed: %s\n", sub.name, subscriptionErrorMessage(err)))
+		return
+	}
+	require.True(ok, fmt.Sprintf("subscription %s has rows %v, materialized view has rows %v", sub.name, rows, expected))
+	for _, row := range rows {
+		st.output.WriteString(row + "\n")
+	}
+	st.output.WriteString(fmt.Sprintf("%d rows in subscription %s\n", len(rows), sub.name))
+}
+
+// queryRows returns the visible rows of a table, formatted like the rows received by a subscription
+func (st *sqlTest) queryRows(require *require.Assertions, tableName string) ([]string, error) {
+	apiServerAddress := fmt.Sprintf("127.0.0.1:%d", apiServerListenAddressBase+st.clientNodeID)
+	conn, err := grpc.Dial(apiServerAddress, grpc.WithInsecure())
+	require.NoError(err)
+	defer func() {
+		if err := conn.Close(); err != nil {
+			log.Warnf("failed to close connection %v", err)
+		}
+	}()
+	stream, err := service.NewPranaDBServiceClient(conn).ExecuteSQLStatement(context.Background(), &service.ExecuteSQLStatementRequest{
+		Schema:    st.currentSchema,
+		Statement: fmt.Sprintf("select * from %s", tableName),
+		PageSize:  1000,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var rows []string
+	for {
+		resp, err := stream.Recv()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		if page, ok := resp.Result.(*service.ExecuteSQLStatementResponse_Page); ok {
+			for _, row := range page.Page.Rows {
+				rows = append(rows, formatSubscriptionRow(row))
+			}
+		}
+	}
+	sort.Strings(rows)
+	return rows, nil
+}
+
+// subscriptionErrorMessage returns the message of the Prana error received by a subscription, without the gRPC details
+func subscriptionErrorMessage(err error) string {
+	msg := err.Error()
+	if ind := strings.Index(msg, "PDB"); ind != -1 {
+		return msg[ind:]
+	}
+	return msg
+}
+
+func formatSubscriptionRow(row *service.Row) string {
+	sb := &strings.Builder{}
+	for i, colVal := range row.Values {
+		if i > 0 {
+			sb.WriteString("|")
+		}
+		switch val := colVal.Value.(type) {
+		case *service.ColValue_IsNull:
+			sb.WriteString("null")
+		case *service.ColValue_IntValue:
+			sb.WriteString(strconv.FormatInt(val.IntValue, 10))
+		case *service.ColValue_FloatValue:
+			sb.WriteString(strconv.FormatFloat(val.FloatValue, 'f', -1, 64))
+		case *service.ColValue_StringValue:
+			sb.WriteString(val.StringValue)
+		}
+	}
+	return sb.String()
+}
+
+func (st *sqlTest) executePrepare(require *require.Assertions, command string) {
+	parts := strings.SplitN(command, " ", 3)
+	require.Equal(3, len(parts), "Invalid prepare, should be --prepare statement_name query")
+	resp, err := st.cli.PrepareStatement(context.Background(), &service.PrepareStatementRequest{
+		Schema:    st.currentSchema,
+		Statement: parts[2],
+	})
+	if err != nil {
+		st.output.WriteString(fmt.Sprintf("Failed to prepare statement: %s\n", subscriptionErrorMessage(err)))
+		return
+	}
+	st.preparedStatements[parts[1]] = resp.PreparedStatementId
+	st.output.WriteString(fmt.Sprintf("Prepared statement with %d params\n", resp.NumParams))
+}
+
+// executePrepared executes a statement prepared with --prepare. Each arg is written as type:value, e.g. int:23, or
+// as null. Timestamps are written as microseconds past epoch.
+func (st *sqlTest) executePrepared(require *require.Assertions, command string) {
+	parts := strings.Split(command, " ")
+	require.GreaterOrEqual(len(parts), 2, "Invalid execute, should be --execute statement_name [type:value ...]")
+	// A statement which was never prepared gets an id which is unknown to the server
+	psID, ok := st.preparedStatements[parts[1]]
+	if !ok {
+		psID = -1
+	}
+	var args []*service.Arg
+	for _, sArg := range parts[2:] {
+		args = append(args, parseArg(require, sArg))
+	}
+	stream, err := st.cli.ExecutePreparedStatement(context.Background(), &service.ExecutePreparedStatementRequest{
+		PreparedStatementId: psID,
+		Args:                args,
+		PageSize:            clientPageSize,
+	})
+	numRows := 0
+	for err == nil {
+		var resp *service.ExecuteSQLStatementResponse
+		resp, err = stream.Recv()
+		if err != nil {
+			break
+		}
+		if page, ok := resp.Result.(*service.ExecuteSQLStatementResponse_Page); ok {
+			for _, row := range page.Page.Rows {
+				st.output.WriteString(formatSubscriptionRow(row) + "\n")
+				numRows++
+			}
+		}
+	}
+	if !errors.Is(err, io.EOF) {
+		st.output.WriteString(fmt.Sprintf("Failed to execute statement: %s\n", subscriptionErrorMessage(err)))
+		return
+	}
+	st.output.WriteString(fmt.Sprintf("%d rows returned\n", numRows))
+}
+
+func (st *sqlTest) executeClosePrepared(require *require.Assertions, command string) {
+	parts := strings.Split(command, " ")
+	require.Equal(3, len(parts), "Invalid close, should be --close prepared statement_name")
+	psID, ok := st.preparedStatements[parts[2]]
+	if !ok {
+		psID = -1
+	}
+	err := st.cli.ClosePreparedStatement(context.Background(), &service.ClosePreparedStatementRequest{PreparedStatementId: psID})
+	if err != nil {
+		st.output.WriteString(fmt.Sprintf("Failed to close statement: %s\n", subscriptionErrorMessage(err)))
+		return
+	}
+	st.output.WriteString("Closed prepared statement\n")
+}
+
+func parseArg(require *require.Assertions, sArg string) *service.Arg {
+	if sArg == "null" {
+		return &service.Arg{Value: &service.Arg_IsNull{IsNull: true}}
+	}
+	parts := strings.SplitN(sArg, ":", 2)
+	require.Equal(2, len(parts), fmt.Sprintf("Invalid arg %s, should be type:value", sArg))
+	switch parts[0] {
+	case "int":
+		v, err := strconv.ParseInt(parts[1], 10, 64)
+		require.NoError(err)
+		return &service.Arg{Value: &service.Arg_IntValue{IntValue: v}}
+	case "float":
+		v, err := strconv.ParseFloat(parts[1], 64)
+		require.NoError(err)
+		return &service.Arg{Value: &service.Arg_FloatValue{FloatValue: v}}
+	case "string":
+		return &service.Arg{Value: &service.Arg_StringValue{StringValue: parts[1]}}
+	case "decimal":
+		return &service.Arg{Value: &service.Arg_DecimalValue{DecimalValue: parts[1]}}
+	case "timestamp":
+		v, err := strconv.ParseInt(parts[1], 10, 64)
+		require.NoError(err)
+		return &service.Arg{Value: &service.Arg_TimestampValue{TimestampValue: v}}
+	default:
+		require.Fail(fmt.Sprintf("Invalid arg type %s", parts[0]))
+		return nil
+	}
+}
+
 func (st *sqlTest) executeRestartCluster(require *require.Assertions) {
 	st.closeClient(require)
 	st.testSuite.restartCluster()
@@ -905,6 +1387,37 @@ func (st *sqlTest) executeRegisterProtobufCommand(require *require.Assertions, c
 	require.NoError(err)
 }
 
+// executeRegisterAvroCommand registers an Avro schema with Prana, e.g. --register avro 1001 payment.avsc
+func (st *sqlTest) executeRegisterAvroCommand(require *require.Assertions, cmd string) {
+	schema := st.loadAvroSchema(require, cmd)
+	err := st.cli.RegisterAvroSchemas(context.Background(), &service.RegisterAvroSchemasRequest{
+		Schemas: []*service.AvroSchema{{Id: schema.ID, Schema: schema.String()}},
+	})
+	require.NoError(err)
+	st.testSuite.avroSchemas.add(schema)
+}
+
+// executeRegisterSchemaRegistryAvroCommand registers an Avro schema with the fake schema registry, for Prana to fetch,
+// e.g. --register schema registry avro 1 payment.avsc
+func (st *sqlTest) executeRegisterSchemaRegistryAvroCommand(require *require.Assertions, cmd string) {
+	schema := st.loadAvroSchema(require, cmd)
+	require.NoError(st.testSuite.schemaRegistry.RegisterSchema(schema.ID, schema.String()))
+	st.testSuite.avroSchemas.add(schema)
+}
+
+func (st *sqlTest) loadAvroSchema(require *require.Assertions, cmd string) *avrolib.Schema {
+	parts := strings.Split(cmd, " ")
+	require.True(len(parts) >= 3, fmt.Sprintf("invalid command %s", cmd))
+	fileName := parts[len(parts)-1]
+	id, err := strconv.ParseInt(parts[len(parts)-2], 10, 32)
+	require.NoError(err)
+	text, err := ioutil.ReadFile(filepath.Join(AvroSchemaDir, fileName))
+	require.NoError(err)
+	schema, err := avrolib.NewSchema(int32(id), string(text))
+	require.NoError(err)
+	return schema
+}
+
 func (st *sqlTest) executeActivateFailpoint(require *require.Assertions, cmd string) {
 	st.activateFailpoint(require, cmd, true)
 }
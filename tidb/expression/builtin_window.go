@@ -0,0 +1,268 @@
+/*
+ *  Copyright 2022 Square Inc.
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package expression
+
+import (
+	"strings"
+	"time"
+
+	"github.com/pingcap/parser/mysql"
+	"github.com/squareup/pranadb/errors"
+	"github.com/squareup/pranadb/tidb/sessionctx"
+	"github.com/squareup/pranadb/tidb/types"
+	"github.com/squareup/pranadb/tidb/util/chunk"
+)
+
+const (
+	// Tumble is TUMBLE(time, size value, size unit[, lateness value, lateness unit]), which returns the start of the
+	// tumbling window of the given size that the time falls in. The lateness is how long after the end of the window
+	// rows for it are still accepted, it doesn't affect the result.
+	Tumble = "tumble"
+	// TumbleEnd is TUMBLE_END(time, size value, size unit), which returns the end of the tumbling window
+	TumbleEnd = "tumble_end"
+	// Hop is HOP(time, size value, size unit, slide value, slide unit[, lateness value, lateness unit]). Hopping
+	// windows of the given size start every slide, so a time can fall in several of them. Grouped by, each row is
+	// aggregated into all of its windows, but evaluated on a row it returns the start of the latest one.
+	Hop = "hop"
+	// HopEnd is HOP_END(time, size value, size unit, slide value, slide unit), which returns the end of the latest
+	// hopping window the time falls in
+	HopEnd = "hop_end"
+	// Session is SESSION(time, gap value, gap unit[, lateness value, lateness unit]). A session window holds rows
+	// which are less than the gap apart, and ends the gap after its last row. Grouped by, each row is aggregated into
+	// its session, but evaluated on a row it returns the time, the start of a session of just that row. SESSION is a
+	// keyword so the function has another name, which calls of SESSION are rewritten to before parsing.
+	Session = "session_window"
+	// SessionEnd is SESSION_END(time, gap value, gap unit), which returns the time plus the gap
+	SessionEnd = "session_end"
+)
+
+type windowKind int
+
+const (
+	tumbleWindow windowKind = iota
+	hopWindow
+	sessionWindow
+)
+
+// numIntervals returns the number of intervals which define the windows, not counting the lateness
+func (k windowKind) numIntervals() int {
+	if k == hopWindow {
+		return 2
+	}
+	return 1
+}
+
+// IntervalDuration returns the duration of an interval, e.g. INTERVAL 5 MINUTE. Intervals of months or years don't have
+// a fixed duration so aren't supported.
+func IntervalDuration(value int64, unit string) (time.Duration, bool) {
+	var d time.Duration
+	switch strings.ToUpper(unit) {
+	case "MICROSECOND":
+		d = time.Microsecond
+	case "SECOND":
+		d = time.Second
+	case "MINUTE":
+		d = time.Minute
+	case "HOUR":
+		d = time.Hour
+	case "DAY":
+		d = 24 * time.Hour
+	case "WEEK":
+		d = 7 * 24 * time.Hour
+	default:
+		return 0, false
+	}
+	return time.Duration(value) * d, true
+}
+
+func windowSig(expr Expression, kind windowKind, end bool) (*builtinWindowSig, bool) {
+	sf, ok := expr.(*ScalarFunction)
+	if !ok {
+		return nil, false
+	}
+	sig, ok := sf.Function.(*builtinWindowSig)
+	if !ok || sig.kind != kind || sig.end != end {
+		return nil, false
+	}
+	return sig, true
+}
+
+// TumbleWindow returns the size and allowed lateness of the window if the expression is a call of TUMBLE
+func TumbleWindow(expr Expression) (time.Duration, time.Duration, bool) {
+	sig, ok := windowSig(expr, tumbleWindow, false)
+	if !ok {
+		return 0, 0, false
+	}
+	return sig.size, sig.lateness, true
+}
+
+// HopWindow returns the size, slide and allowed lateness of the windows if the expression is a call of HOP
+func HopWindow(expr Expression) (time.Duration, time.Duration, time.Duration, bool) {
+	sig, ok := windowSig(expr, hopWindow, false)
+	if !ok {
+		return 0, 0, 0, false
+	}
+	return sig.size, sig.slide, sig.lateness, true
+}
+
+// SessionWindow returns the gap and allowed lateness of the windows if the expression is a call of SESSION
+func SessionWindow(expr Expression) (time.Duration, time.Duration, bool) {
+	sig, ok := windowSig(expr, sessionWindow, false)
+	if !ok {
+		return 0, 0, false
+	}
+	return sig.size, sig.lateness, true
+}
+
+// IsWindow returns true if the expression is a call of TUMBLE, HOP or SESSION
+func IsWindow(expr Expression) bool {
+	sf, ok := expr.(*ScalarFunction)
+	if !ok {
+		return false
+	}
+	sig, ok := sf.Function.(*builtinWindowSig)
+	return ok && !sig.end
+}
+
+// IsWindowEnd returns true if the expression is a call of HOP_END or SESSION_END, whose value depends on the other rows
+// in the window
+func IsWindowEnd(expr Expression) bool {
+	_, isHopEnd := windowSig(expr, hopWindow, true)
+	_, isSessionEnd := windowSig(expr, sessionWindow, true)
+	return isHopEnd || isSessionEnd
+}
+
+type windowFunctionClass struct {
+	baseFunctionClass
+	kind windowKind
+	end  bool
+}
+
+func (c *windowFunctionClass) getFunction(ctx sessionctx.Context, args []Expression) (builtinFunc, error) {
+	if err := c.verifyArgs(args); err != nil {
+		return nil, err
+	}
+	if len(args)%2 == 0 {
+		return nil, errors.NewPranaErrorf(errors.InvalidStatement, "%s intervals require a value and a unit", c.funcName)
+	}
+	intervals := make([]time.Duration, (len(args)-1)/2)
+	for i := range intervals {
+		d, err := windowInterval(ctx, c.funcName, args[1+2*i], args[2+2*i])
+		if err != nil {
+			return nil, err
+		}
+		intervals[i] = d
+	}
+	for _, d := range intervals[:c.kind.numIntervals()] {
+		if d <= 0 {
+			return nil, errors.NewPranaErrorf(errors.InvalidStatement, "%s window intervals must be positive", c.funcName)
+		}
+	}
+	sig := &builtinWindowSig{kind: c.kind, size: intervals[0], end: c.end}
+	if c.kind == hopWindow {
+		sig.slide = intervals[1]
+	}
+	if len(intervals) > c.kind.numIntervals() {
+		sig.lateness = intervals[len(intervals)-1]
+		if sig.lateness < 0 {
+			return nil, errors.NewPranaErrorf(errors.InvalidStatement, "%s lateness must not be negative", c.funcName)
+		}
+	}
+	argTps := make([]types.EvalType, len(args))
+	argTps[0] = types.ETDatetime
+	for i := 1; i < len(args); i += 2 {
+		argTps[i], argTps[i+1] = types.ETInt, types.ETString
+	}
+	bf, err := newBaseBuiltinFuncWithTp(ctx, c.funcName, args, types.ETDatetime, argTps...)
+	if err != nil {
+		return nil, err
+	}
+	fsp := args[0].GetType().Decimal
+	if fsp < 0 || fsp > int(types.MaxFsp) {
+		fsp = int(types.MaxFsp)
+	}
+	bf.tp.Tp, bf.tp.Flen, bf.tp.Decimal = mysql.TypeDatetime, mysql.MaxDatetimeWidthNoFsp, fsp
+	if fsp > 0 {
+		bf.tp.Flen += 1 + fsp
+	}
+	sig.baseBuiltinFunc = bf
+	return sig, nil
+}
+
+// windowInterval returns the duration of an interval given by constant value and unit arguments
+func windowInterval(ctx sessionctx.Context, funcName string, valueArg Expression, unitArg Expression) (time.Duration, error) {
+	valueCon, ok := valueArg.(*Constant)
+	if !ok {
+		return 0, errors.NewPranaErrorf(errors.InvalidStatement, "%s interval must be a constant", funcName)
+	}
+	unitCon, ok := unitArg.(*Constant)
+	if !ok {
+		return 0, errors.NewPranaErrorf(errors.InvalidStatement, "%s interval unit must be a constant", funcName)
+	}
+	value, null, err := valueCon.EvalInt(ctx, chunk.Row{})
+	if err != nil || null {
+		return 0, errors.NewPranaErrorf(errors.InvalidStatement, "%s interval must be an integer", funcName)
+	}
+	unit, null, err := unitCon.EvalString(ctx, chunk.Row{})
+	if err != nil || null {
+		return 0, errors.NewPranaErrorf(errors.InvalidStatement, "%s interval unit must be a string", funcName)
+	}
+	d, ok := IntervalDuration(value, unit)
+	if !ok {
+		return 0, errors.NewPranaErrorf(errors.InvalidStatement, "%s interval unit %s is not supported", funcName, unit)
+	}
+	return d, nil
+}
+
+type builtinWindowSig struct {
+	baseBuiltinFunc
+	kind     windowKind
+	size     time.Duration // The gap of session windows
+	slide    time.Duration
+	lateness time.Duration
+	end      bool
+}
+
+func (b *builtinWindowSig) Clone() builtinFunc {
+	newSig := &builtinWindowSig{kind: b.kind, size: b.size, slide: b.slide, lateness: b.lateness, end: b.end}
+	newSig.cloneFrom(&b.baseBuiltinFunc)
+	return newSig
+}
+
+// evalTime evals the window functions on a single row. Windows are aligned to the zero time, so windows of a day or
+// less start at midnight, and week windows start on Monday.
+func (b *builtinWindowSig) evalTime(row chunk.Row) (types.Time, bool, error) {
+	t, isNull, err := b.args[0].EvalTime(b.ctx, row)
+	if isNull || err != nil {
+		return types.ZeroTime, true, handleInvalidTimeError(b.ctx, err)
+	}
+	gt, err := t.GoTime(time.UTC)
+	if err != nil {
+		return types.ZeroTime, true, handleInvalidTimeError(b.ctx, err)
+	}
+	start := gt
+	switch b.kind {
+	case tumbleWindow:
+		start = gt.Truncate(b.size)
+	case hopWindow:
+		start = gt.Truncate(b.slide)
+	}
+	if b.end {
+		start = start.Add(b.size)
+	}
+	return types.NewTime(types.FromGoTime(start), b.tp.Tp, int8(b.tp.Decimal)), false, nil
+}
@@ -63,6 +63,9 @@ var defaultImplementationMap = map[Operand][]ImplementationRule{
 	OperandUnionAll: {
 		&ImplUnionAll{},
 	},
+	OperandJoin: {
+		&ImplHashJoin{},
+	},
 }
 
 // ImplProjection implements LogicalProjection as PhysicalProjection.
@@ -96,8 +99,9 @@ type ImplTableScan struct {
 
 // Match implements ImplementationRule Match interface.
 func (r *ImplTableScan) Match(expr *GroupExpr, prop *property.PhysicalProperty) (matched bool) {
-	ts := expr.ExprNode.(*LogicalTableScan)
-	return prop.IsEmpty() || (len(prop.SortItems) == 1 && ts.HandleCols != nil && prop.SortItems[0].Col.Equal(nil, ts.HandleCols.GetCol(0)))
+	// The rows of a scan are spread over the shards, so a scan can't return them in key order. Any order is provided
+	// by a sort above the scan instead.
+	return prop.IsEmpty()
 }
 
 // OnImplement implements ImplementationRule OnImplement interface.
@@ -119,8 +123,8 @@ type ImplIndexScan struct {
 
 // Match implements ImplementationRule Match interface.
 func (r *ImplIndexScan) Match(expr *GroupExpr, prop *property.PhysicalProperty) (matched bool) {
-	is := expr.ExprNode.(*LogicalIndexScan)
-	return is.MatchIndexProp(prop)
+	// Like a table scan, an index scan can't return the rows of all the shards in index order
+	return prop.IsEmpty()
 }
 
 // OnImplement implements ImplementationRule OnImplement interface.
@@ -302,6 +306,27 @@ func (r *ImplUnionAll) OnImplement(expr *GroupExpr, reqProp *property.PhysicalPr
 	return []Implementation{NewUnionAllImpl(physicalUnion)}, nil
 }
 
+// ImplHashJoin implements LogicalJoin to PhysicalHashJoin.
+type ImplHashJoin struct {
+}
+
+// Match implements ImplementationRule Match interface.
+func (r *ImplHashJoin) Match(expr *GroupExpr, prop *property.PhysicalProperty) (matched bool) {
+	return prop.IsEmpty()
+}
+
+// OnImplement implements ImplementationRule OnImplement interface.
+func (r *ImplHashJoin) OnImplement(expr *GroupExpr, reqProp *property.PhysicalProperty) ([]Implementation, error) {
+	join := expr.ExprNode.(*LogicalJoin)
+	chReqProps := make([]*property.PhysicalProperty, len(expr.Children))
+	for i := range expr.Children {
+		chReqProps[i] = &property.PhysicalProperty{ExpectedCnt: math.MaxFloat64}
+	}
+	hashJoin := NewPhysicalHashJoin(join, expr.Group.Prop.Stats.ScaleByExpectCnt(reqProp.ExpectedCnt), chReqProps...)
+	hashJoin.SetSchema(expr.Group.Prop.Schema)
+	return []Implementation{NewHashJoinImpl(hashJoin)}, nil
+}
+
 // matchItems checks if this prop's columns can match by items totally.
 func matchItems(p *property.PhysicalProperty, items []*util.ByItems) bool {
 	if len(items) < len(p.SortItems) {
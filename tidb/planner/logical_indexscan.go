@@ -85,6 +85,7 @@ func (s *LogicalIndexScan) GetPhysicalIndexScan(schema *expression.Schema, stats
 		IdxCols:          s.IdxCols,
 		IdxColLens:       s.IdxColLens,
 		Ranges:           s.Ranges,
+		AccessCondition:  s.AccessConds,
 		dataSourceSchema: ds.schema,
 	}.Init(ds.ctx, ds.blockOffset)
 	is.stats = stats
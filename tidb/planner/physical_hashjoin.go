@@ -0,0 +1,119 @@
+//
+// This source code is a modified form of original source from the TiDB project, which has the following copyright header(s):
+//
+
+// Copyright 2016 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package planner
+
+import (
+	"github.com/squareup/pranadb/tidb/expression"
+	"github.com/squareup/pranadb/tidb/planner/property"
+	"github.com/squareup/pranadb/tidb/sessionctx"
+)
+
+var _ PhysicalPlan = &PhysicalHashJoin{}
+
+// PhysicalHashJoin represents hash join implementation of LogicalJoin.
+type PhysicalHashJoin struct {
+	physicalSchemaProducer
+
+	JoinType JoinType
+
+	LeftConditions  expression.CNFExprs
+	RightConditions expression.CNFExprs
+	OtherConditions expression.CNFExprs
+
+	LeftJoinKeys  []*expression.Column
+	RightJoinKeys []*expression.Column
+}
+
+// NewPhysicalHashJoin creates a new PhysicalHashJoin from LogicalJoin.
+func NewPhysicalHashJoin(p *LogicalJoin, newStats *property.StatsInfo, props ...*property.PhysicalProperty) *PhysicalHashJoin {
+	leftJoinKeys, rightJoinKeys, _, _ := p.GetJoinKeys()
+	hashJoin := PhysicalHashJoin{
+		JoinType:        p.JoinType,
+		LeftConditions:  p.LeftConditions,
+		RightConditions: p.RightConditions,
+		OtherConditions: p.OtherConditions,
+		LeftJoinKeys:    leftJoinKeys,
+		RightJoinKeys:   rightJoinKeys,
+	}.Init(p.ctx, newStats, p.blockOffset, props...)
+	return hashJoin
+}
+
+// Init initializes PhysicalHashJoin.
+func (p PhysicalHashJoin) Init(ctx sessionctx.Context, stats *property.StatsInfo, offset int, props ...*property.PhysicalProperty) *PhysicalHashJoin {
+	p.basePhysicalPlan = newBasePhysicalPlan(ctx, TypeHashJoin, &p, offset)
+	p.childrenReqProps = props
+	p.stats = stats
+	return &p
+}
+
+// ResolveIndices implements Plan interface.
+func (p *PhysicalHashJoin) ResolveIndices() (err error) {
+	err = p.physicalSchemaProducer.ResolveIndices()
+	if err != nil {
+		return err
+	}
+	lSchema := p.children[0].Schema()
+	rSchema := p.children[1].Schema()
+	for i, col := range p.LeftJoinKeys {
+		newKey, err := col.ResolveIndices(lSchema)
+		if err != nil {
+			return err
+		}
+		p.LeftJoinKeys[i] = newKey.(*expression.Column)
+	}
+	for i, col := range p.RightJoinKeys {
+		newKey, err := col.ResolveIndices(rSchema)
+		if err != nil {
+			return err
+		}
+		p.RightJoinKeys[i] = newKey.(*expression.Column)
+	}
+	for i, expr := range p.LeftConditions {
+		p.LeftConditions[i], err = expr.ResolveIndices(lSchema)
+		if err != nil {
+			return err
+		}
+	}
+	for i, expr := range p.RightConditions {
+		p.RightConditions[i], err = expr.ResolveIndices(rSchema)
+		if err != nil {
+			return err
+		}
+	}
+	mergedSchema := expression.MergeSchema(lSchema, rSchema)
+	for i, expr := range p.OtherConditions {
+		p.OtherConditions[i], err = expr.ResolveIndices(mergedSchema)
+		if err != nil {
+			return err
+		}
+	}
+	return
+}
+
+// GetCost computes cost of hash join operator itself.
+func (p *PhysicalHashJoin) GetCost(lCnt, rCnt float64) float64 {
+	sessVars := p.ctx.GetSessionVars()
+	// Both sides are hashed on the join key, so the cost is proportional to the total number of input rows
+	cpuCost := (lCnt + rCnt) * sessVars.CPUFactor
+	if len(p.OtherConditions) > 0 {
+		cpuCost += p.stats.RowCount * sessVars.CPUFactor * float64(len(p.OtherConditions))
+	}
+	memoryCost := (lCnt + rCnt) * sessVars.MemoryFactor
+	return cpuCost + memoryCost
+}
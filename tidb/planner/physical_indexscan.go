@@ -32,6 +32,9 @@ var _ PhysicalPlan = &PhysicalIndexScan{}
 type PhysicalIndexScan struct {
 	physicalSchemaProducer
 
+	// AccessCondition is used to calculate range.
+	AccessCondition []expression.Expression
+
 	Table      *model.TableInfo
 	Index      *model.IndexInfo
 	IdxCols    []*expression.Column
@@ -2110,6 +2110,13 @@ func (r *InjectProjectionBelowAgg) OnTransform(old *ExprIter) (newExprs []*Group
 			}
 			projSchemaCols = append(projSchemaCols, newArg)
 			newGroupByItems[i] = newArg
+			if expression.IsWindow(expr) {
+				// The aggregation needs the time column of a window to track event time
+				if timeCol, ok := expr.(*expression.ScalarFunction).GetArgs()[0].(*expression.Column); ok {
+					projExprs = append(projExprs, timeCol)
+					projSchemaCols = append(projSchemaCols, timeCol)
+				}
+			}
 		}
 	}
 